@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
@@ -25,6 +26,7 @@ import (
 	"github.com/matou-dao/backend/internal/keri"
 	"github.com/matou-dao/backend/internal/notifications"
 	bgSync "github.com/matou-dao/backend/internal/sync"
+	"github.com/matou-dao/backend/internal/trust"
 	matouTypes "github.com/matou-dao/backend/internal/types"
 )
 
@@ -78,15 +80,25 @@ func fetchAndSaveAnySyncConfig(configServerURL, targetPath string) error {
 }
 
 // eventBrokerAdapter adapts api.EventBroker to anysync.EventBroadcaster.
+// New chat messages received via P2P are targeted at the channel's members.
 type eventBrokerAdapter struct {
-	broker *api.EventBroker
+	broker       *api.EventBroker
+	spaceManager *anysync.SpaceManager
 }
 
 func (a *eventBrokerAdapter) Broadcast(event anysync.SSEEvent) {
-	a.broker.Broadcast(api.SSEEvent{
+	sse := api.SSEEvent{
 		Type: event.Type,
 		Data: event.Data,
-	})
+	}
+	if event.Type == "chat:message:new" && a.spaceManager != nil {
+		if data, ok := event.Data.(map[string]interface{}); ok {
+			channelID, _ := data["channelId"].(string)
+			senderAID, _ := data["senderAid"].(string)
+			sse.Recipients = api.ChannelMessageRecipients(context.Background(), a.spaceManager, channelID, senderAID)
+		}
+	}
+	a.broker.Broadcast(sse)
 }
 
 // chatPersisterAdapter adapts anystore.LocalStore to anysync.ChatPersister.
@@ -99,14 +111,16 @@ func (a *chatPersisterAdapter) PersistChatObject(ctx context.Context, p *anysync
 	switch p.Type {
 	case "ChatChannel":
 		var data struct {
-			Name         string   `json:"name"`
-			Description  string   `json:"description,omitempty"`
-			Icon         string   `json:"icon,omitempty"`
-			Photo        string   `json:"photo,omitempty"`
-			CreatedAt    string   `json:"createdAt"`
-			CreatedBy    string   `json:"createdBy"`
-			IsArchived   bool     `json:"isArchived,omitempty"`
-			AllowedRoles []string `json:"allowedRoles,omitempty"`
+			Name          string   `json:"name"`
+			Description   string   `json:"description,omitempty"`
+			Icon          string   `json:"icon,omitempty"`
+			Photo         string   `json:"photo,omitempty"`
+			CreatedAt     string   `json:"createdAt"`
+			CreatedBy     string   `json:"createdBy"`
+			IsArchived    bool     `json:"isArchived,omitempty"`
+			AllowedRoles  []string `json:"allowedRoles,omitempty"`
+			RetentionDays int      `json:"retentionDays,omitempty"`
+			MergedInto    string   `json:"mergedInto,omitempty"`
 		}
 		if err := json.Unmarshal(p.Data, &data); err != nil {
 			return err
@@ -115,7 +129,8 @@ func (a *chatPersisterAdapter) PersistChatObject(ctx context.Context, p *anysync
 			ID: p.ID, Name: data.Name, Description: data.Description,
 			Icon: data.Icon, Photo: data.Photo, CreatedAt: data.CreatedAt,
 			CreatedBy: data.CreatedBy, IsArchived: data.IsArchived,
-			AllowedRoles: data.AllowedRoles, Version: p.Version,
+			AllowedRoles: data.AllowedRoles, RetentionDays: data.RetentionDays,
+			MergedInto: data.MergedInto, Version: p.Version,
 		})
 
 	case "ChatMessage":
@@ -129,6 +144,8 @@ func (a *chatPersisterAdapter) PersistChatObject(ctx context.Context, p *anysync
 			SentAt      string          `json:"sentAt"`
 			EditedAt    string          `json:"editedAt,omitempty"`
 			DeletedAt   string          `json:"deletedAt,omitempty"`
+			Kind        string          `json:"kind,omitempty"`
+			Payload     json.RawMessage `json:"payload,omitempty"`
 		}
 		if err := json.Unmarshal(p.Data, &data); err != nil {
 			return err
@@ -138,22 +155,46 @@ func (a *chatPersisterAdapter) PersistChatObject(ctx context.Context, p *anysync
 			SenderName: data.SenderName, Content: data.Content,
 			Attachments: data.Attachments, ReplyTo: data.ReplyTo,
 			SentAt: data.SentAt, EditedAt: data.EditedAt,
-			DeletedAt: data.DeletedAt, Version: p.Version,
+			DeletedAt: data.DeletedAt, Kind: data.Kind, Payload: data.Payload,
+			Version: p.Version,
 		})
 
 	case "MessageReaction":
 		var data struct {
-			MessageID   string   `json:"messageId"`
-			Emoji       string   `json:"emoji"`
-			ReactorAIDs []string `json:"reactorAids"`
+			MessageID      string   `json:"messageId"`
+			Emoji          string   `json:"emoji"`
+			ReactorAIDs    []string `json:"reactorAids"`
+			FirstReactedAt string   `json:"firstReactedAt"`
+			RemovedAt      string   `json:"removedAt"`
 		}
 		if err := json.Unmarshal(p.Data, &data); err != nil {
 			return err
 		}
+		// An emptied reaction is a tombstone in the tree; don't keep it cached
+		if data.RemovedAt != "" || len(data.ReactorAIDs) == 0 {
+			return a.store.DeleteReaction(ctx, p.ID)
+		}
 		return a.store.UpsertReaction(ctx, &anystore.ChatReaction{
 			ID: p.ID, MessageID: data.MessageID, Emoji: data.Emoji,
-			ReactorAIDs: data.ReactorAIDs, Version: p.Version,
+			ReactorAIDs: data.ReactorAIDs, FirstReactedAt: data.FirstReactedAt,
+			Version: p.Version,
 		})
+
+	case "NoticeReaction":
+		var data anystore.NoticeReaction
+		if err := json.Unmarshal(p.Data, &data); err != nil {
+			return err
+		}
+		data.ID = p.ID
+		return a.store.UpsertNoticeReaction(ctx, &data)
+
+	case "NoticeRSVP":
+		var data anystore.NoticeRSVP
+		if err := json.Unmarshal(p.Data, &data); err != nil {
+			return err
+		}
+		data.ID = p.ID
+		return a.store.UpsertNoticeRSVP(ctx, &data)
 	}
 	return nil
 }
@@ -175,6 +216,51 @@ func (a *contribNotifierAdapter) Notify(n *api.ContribNotification) error {
 	})
 }
 
+// coordinatorCheckTimeout bounds the startup coordinator reachability check
+const coordinatorCheckTimeout = 5 * time.Second
+
+// exitWithConfigError prints a configuration error and exits non-zero
+func exitWithConfigError(err error) {
+	fmt.Fprintf(os.Stderr, "\nFailed to start: %v\n", err)
+	os.Exit(1)
+}
+
+// trustWeights converts trust config to trust score weights
+func trustWeights(c config.TrustConfig) trust.ScoreWeights {
+	return trust.ScoreWeights{
+		IncomingCredential:    c.Weights.IncomingCredential,
+		UniqueIssuer:          c.Weights.UniqueIssuer,
+		BidirectionalRelation: c.Weights.BidirectionalRelation,
+		DepthPenalty:          c.Weights.DepthPenalty,
+		OrgIssuedBonus:        c.Weights.OrgIssuedBonus,
+		DecayHalfLife:         time.Duration(c.DecayHalfLifeDays) * 24 * time.Hour,
+	}
+}
+
+// reconcileIdentitySpaceIDs brings the identity's stored space IDs in line
+// with the space manager's, which were restored from the authoritative
+// record in the local store.
+func reconcileIdentitySpaceIDs(userIdentity *identity.UserIdentity, spaceManager *anysync.SpaceManager) {
+	if userIdentity.GetAID() == "" {
+		return
+	}
+	if id := spaceManager.GetCommunitySpaceID(); id != "" && id != userIdentity.GetCommunitySpaceID() {
+		if err := userIdentity.SetOrgConfig(userIdentity.GetOrgAID(), id); err != nil {
+			log.Printf("Warning: failed to update identity community space ID: %v", err)
+		}
+	}
+	if id := spaceManager.GetCommunityReadOnlySpaceID(); id != "" && id != userIdentity.GetCommunityReadOnlySpaceID() {
+		if err := userIdentity.SetCommunityReadOnlySpaceID(id); err != nil {
+			log.Printf("Warning: failed to update identity readonly space ID: %v", err)
+		}
+	}
+	if id := spaceManager.GetAdminSpaceID(); id != "" && id != userIdentity.GetAdminSpaceID() {
+		if err := userIdentity.SetAdminSpaceID(id); err != nil {
+			log.Printf("Warning: failed to update identity admin space ID: %v", err)
+		}
+	}
+}
+
 func main() {
 	// Detect environment: "test" uses isolated data, configs, and ports
 	// "production" uses production configs (for Electron builds)
@@ -193,8 +279,18 @@ func main() {
 	fmt.Println("============================")
 	fmt.Println()
 
-	// Initialize data directory first (needed for org config)
-	dataDir := os.Getenv("MATOU_DATA_DIR")
+	// Load server configuration (SMTP, KERI URLs, etc.). MATOU_CONFIG_PATH
+	// optionally points at a YAML config file, which is watched for changes.
+	fmt.Println("Loading configuration...")
+	configPath := os.Getenv("MATOU_CONFIG_PATH")
+	cfg, err := config.Load(configPath, "")
+	if err != nil {
+		exitWithConfigError(err)
+	}
+
+	// Initialize data directory (needed for org config). server.dataDir or
+	// MATOU_DATA_DIR sets it explicitly.
+	dataDir := cfg.Server.DataDir
 	if dataDir == "" {
 		if isTest {
 			dataDir = "./data-test"
@@ -206,13 +302,6 @@ func main() {
 		log.Fatalf("Failed to create data directory: %v", err)
 	}
 
-	// Load server configuration (SMTP, KERI URLs, etc.)
-	fmt.Println("Loading configuration...")
-	cfg, err := config.Load("", "")
-	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
-	}
-
 	// Test mode uses port 9080 to avoid conflicting with dev server on 8080
 	if isTest {
 		cfg.Server.Port = 9080
@@ -323,12 +412,31 @@ func main() {
 
 	// If identity is persisted with mnemonic, derive peer key for SDK initialization
 	sdkOpts := &anysync.ClientOptions{
-		DataDir:     dataDir,
-		PeerKeyPath: dataDir + "/peer.key",
+		DataDir:         dataDir,
+		PeerKeyPath:     dataDir + "/peer.key",
+		PerIdentityDirs: cfg.AnySync.PerIdentityDirs,
+		Tuning: anysync.SyncTuning{
+			GCTTLSeconds:           cfg.AnySync.Tuning.GCTTLSeconds,
+			SyncPeriodSeconds:      cfg.AnySync.Tuning.SyncPeriodSeconds,
+			SendQueueSize:          cfg.AnySync.Tuning.SendQueueSize,
+			DialQueueWorkers:       cfg.AnySync.Tuning.DialQueueWorkers,
+			DialQueueSize:          cfg.AnySync.Tuning.DialQueueSize,
+			DialTimeoutSeconds:     cfg.AnySync.Tuning.DialTimeoutSeconds,
+			WriteTimeoutSeconds:    cfg.AnySync.Tuning.WriteTimeoutSeconds,
+			KeepAlivePeriodSeconds: cfg.AnySync.Tuning.KeepAlivePeriodSeconds,
+		},
 	}
-	if userIdentity.IsConfigured() {
-		sdkOpts.Mnemonic = userIdentity.GetMnemonic()
+	if mnemonic := userIdentity.GetMnemonic(); mnemonic != "" {
+		sdkOpts.Mnemonic = mnemonic
 		fmt.Println("  Using mnemonic-derived peer key from persisted identity")
+	} else if userIdentity.IsConfigured() {
+		// The mnemonic is passphrase-protected and locked: start with the
+		// identity's stored peer key until POST /api/v1/identity/unlock
+		// reinitializes the SDK with the derived one
+		if peerID := userIdentity.GetPeerID(); peerID != "" && cfg.AnySync.PerIdentityDirs {
+			sdkOpts.PeerKeyPath = filepath.Join(anysync.IdentityDataDir(dataDir, peerID), "peer.key")
+		}
+		fmt.Println("  Identity is locked; using stored peer key until it is unlocked")
 	}
 
 	sdkClient, err := anysync.NewSDKClient(anysyncConfigPath, sdkOpts)
@@ -341,6 +449,14 @@ func main() {
 	fmt.Printf("  any-sync client initialized\n")
 	fmt.Printf("   Network ID: %s\n", anysyncClient.GetNetworkID())
 	fmt.Printf("   Coordinator: %s\n", anysyncClient.GetCoordinatorURL())
+	if err := config.CheckCoordinator(anysyncClient.GetCoordinatorURL(), coordinatorCheckTimeout); err != nil {
+		// Production must start offline (local-first); elsewhere an unreachable
+		// network is a setup problem better reported now than at space creation
+		if !isProd {
+			exitWithConfigError(err)
+		}
+		fmt.Printf("  Warning: %v\n", err)
+	}
 	fmt.Printf("   Peer ID: %s\n", anysyncClient.GetPeerID())
 
 	// Validate any-sync network connectivity
@@ -379,8 +495,14 @@ func main() {
 	if err := store.EnsureChatIndexes(context.Background()); err != nil {
 		log.Fatalf("Failed to create chat indexes: %v", err)
 	}
+	if err := store.EnsureCredentialIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create credential indexes: %v", err)
+	}
+	if err := store.EnsureNoticeIndexes(context.Background()); err != nil {
+		log.Fatalf("Failed to create notice indexes: %v", err)
+	}
 
-	fmt.Printf("  Local storage initialized (with chat indexes)\n")
+	fmt.Printf("  Local storage initialized (with chat, credential and notice indexes)\n")
 	fmt.Printf("   Data directory: %s\n", dataDir)
 	fmt.Println()
 
@@ -414,6 +536,14 @@ func main() {
 	}, sdkClient.GetTreeManager())
 	spaceStore := anystore.NewSpaceStoreAdapter(store)
 
+	// Restore the persisted space IDs (authoritative over the identity's)
+	spaceManager.SetSpaceIDStore(spaceStore)
+	if err := spaceManager.RestoreSpaceIDs(context.Background()); err != nil {
+		log.Printf("Warning: failed to restore space IDs: %v", err)
+	}
+	reconcileIdentitySpaceIDs(userIdentity, spaceManager)
+	communitySpaceID = spaceManager.GetCommunitySpaceID()
+
 	fmt.Printf("  Space manager initialized\n")
 	fmt.Printf("   Community Space ID: %s\n", communitySpaceID)
 	fmt.Println()
@@ -427,9 +557,11 @@ func main() {
 	// Initialize KERI client (config-only, no KERIA connection needed)
 	fmt.Println("Initializing KERI client...")
 	keriClient, err := keri.NewClient(&keri.Config{
-		OrgAID:   orgConfigHandler.GetOrgAID(),
-		OrgAlias: orgConfigHandler.GetOrgName(), // Use name as alias
-		OrgName:  orgConfigHandler.GetOrgName(),
+		OrgAID:           orgConfigHandler.GetOrgAID(),
+		OrgAlias:         orgConfigHandler.GetOrgName(), // Use name as alias
+		OrgName:          orgConfigHandler.GetOrgName(),
+		WitnessThreshold: cfg.KERI.WitnessThreshold,
+		OOBIHosts:        cfg.KERI.OOBIHosts,
 	})
 	if err != nil {
 		log.Fatalf("Failed to create KERI client: %v", err)
@@ -447,15 +579,26 @@ func main() {
 	typeRegistry := matouTypes.NewRegistry()
 	typeRegistry.Bootstrap()
 	fmt.Printf("  Type registry initialized with %d types\n", len(typeRegistry.All()))
+	// Reject writes of unregistered object types. Data shapes aren't checked:
+	// handlers enforce their own limits (e.g. content.maxMessageBytes).
+	spaceManager.ObjectTreeManager().SetTypeRegistry(typeRegistry, false)
 	fmt.Println()
 
 	// Create event broker for SSE
 	eventBroker := api.NewEventBroker()
+	eventBroker.SetSubscriberBuffer(cfg.Server.SSEBufferSize)
+	if policy, err := api.ParseOverflowPolicy(cfg.Server.SSEOverflowPolicy); err != nil {
+		log.Printf("Warning: %v, using %s", err, api.OverflowDropOldest)
+	} else {
+		eventBroker.SetOverflowPolicy(policy)
+	}
+	eventBroker.SetMaxSubscribers(cfg.Server.SSEMaxClients, cfg.Server.SSEEvictIdle)
+	eventBroker.SetMaxSubscribersPerClient(cfg.Server.SSEMaxClientsPerAID)
 
 	// Create push-based listener for P2P chat changes (replaces polling)
 	chatListener := anysync.NewTreeUpdateListener(
 		&chatPersisterAdapter{store: store},
-		&eventBrokerAdapter{broker: eventBroker},
+		&eventBrokerAdapter{broker: eventBroker, spaceManager: spaceManager},
 	)
 	spaceManager.SetObjectTreeListener(chatListener)
 
@@ -491,19 +634,115 @@ func main() {
 	credHandler := api.NewCredentialsHandler(keriClient, store)
 	syncHandler := api.NewSyncHandler(keriClient, store, spaceManager, spaceStore, userIdentity)
 	trustHandler := api.NewTrustHandler(store, orgConfigHandler.GetOrgAID(), spaceManager)
+	syncHandler.SetTrustGraphUpdater(trustHandler)
+	syncHandler.SetEventBroker(eventBroker)
+	keriClient.SetKELCache(syncHandler)
 	healthHandler := api.NewHealthHandler(store, spaceStore, orgConfigHandler.GetOrgAID, orgConfigHandler.GetAdminAID)
+	healthHandler.SetSpaceManager(spaceManager)
+	coordinatorWatchdog := api.NewCoordinatorWatchdog(anysyncClient.Ping)
+	healthHandler.SetCoordinatorWatchdog(coordinatorWatchdog)
 	spacesHandler := api.NewSpacesHandler(spaceManager, store, userIdentity, spaceManager.FileManager())
+	// Allow the join sync wait to be tuned for slow networks
+	if secs := cfg.Invites.JoinSyncTimeoutSeconds; secs > 0 {
+		spacesHandler.SetJoinSyncTimeout(time.Duration(secs) * time.Second)
+	}
+	// Report a space as stalled after this long with unacknowledged changes
+	if secs := cfg.AnySync.SyncStallSeconds; secs > 0 {
+		spacesHandler.SetSyncStallThreshold(time.Duration(secs) * time.Second)
+	}
+	// Off once every client sends signed invites, to stop accepting the legacy
+	// unsigned form
+	spacesHandler.SetAllowUnsignedInvites(cfg.Invites.AllowUnsigned)
+	// Cap invite requests per admin per hour (0 disables the limit)
+	spacesHandler.SetInviteRateLimit(cfg.Invites.RateLimitPerHour, api.DefaultInviteRateWindow)
+	// Signed invites must verify against an admin's key in the org config;
+	// saving the config on an admin's node lists that admin's key
+	spacesHandler.SetInviteIssuerKeys(orgConfigHandler.GetAdminSigningKey)
+	orgConfigHandler.SetLocalSigningKey(spacesHandler.LocalInviteSigner)
 	emailSender := email.NewSender(cfg.SMTP)
 	invitesHandler := api.NewInvitesHandler(emailSender)
 	bookingHandler := api.NewBookingHandler(emailSender)
 	notificationsHandler := api.NewNotificationsHandler(emailSender)
 	identityHandler := api.NewIdentityHandler(userIdentity, sdkClient, spaceManager, spaceStore)
+	// Session tokens bind requests to a caller; MATOU_AUTH_MODE=token requires
+	// them on writes, the default "dev" mode acts as the local identity
+	authSecret, err := api.LoadOrCreateAuthSecret(dataDir)
+	if err != nil {
+		log.Fatalf("Failed to load auth secret: %v", err)
+	}
+	authenticator := api.NewAuthenticator(authSecret, os.Getenv("MATOU_AUTH_MODE"), userIdentity.GetAID)
+	fmt.Printf("  Auth mode: %s\n", authenticator.Mode())
+	identityHandler.SetAuthenticator(authenticator)
+	authHandler := api.NewAuthHandler(authenticator, userIdentity)
 	eventsHandler := api.NewEventsHandler(eventBroker)
+	eventsHandler.SetHeartbeatInterval(time.Duration(cfg.Server.SSEHeartbeatSeconds) * time.Second)
+	// Members are online while their event stream heartbeats; three missed
+	// heartbeats take them offline
+	presenceTracker := api.NewPresenceTracker(eventBroker, 3*time.Duration(cfg.Server.SSEHeartbeatSeconds)*time.Second)
+	eventsHandler.SetPresenceTracker(presenceTracker)
 	profilesHandler := api.NewProfilesHandler(spaceManager, userIdentity, typeRegistry, spaceManager.FileManager(), eventBroker)
 	noticesHandler := api.NewNoticesHandler(spaceManager, userIdentity, eventBroker)
+	noticesHandler.SetContentLimits(cfg.Content.MaxNoticeBodyBytes, cfg.Content.MaxCommentBytes)
+	noticesHandler.SetStore(store)
+	noticesHandler.SetFileStore(spaceManager.FileManager())
 	filesHandler := api.NewFilesHandler(spaceManager.FileManager(), spaceManager)
+	filesHandler.SetLimits(int64(cfg.Files.MaxSizeMB)<<20, cfg.Files.AllowedContentTypes)
+	filesHandler.SetThumbnailMaxDimension(cfg.Files.ThumbnailMaxDimension)
 	chatHandler := api.NewChatHandler(spaceManager, userIdentity, eventBroker, store, chatListener)
+	chatHandler.SetMaxMessageBytes(cfg.Content.MaxMessageBytes)
+	chatHandler.SetFileStore(spaceManager.FileManager())
 	commentCursorsHandler := api.NewCommentCursorsHandler(spaceManager, userIdentity)
+	activityHandler := api.NewActivityHandler(spaceManager, userIdentity)
+	activityHandler.SetStore(store)
+	adminHandler := api.NewAdminHandler(spaceManager)
+	quarantine := api.NewQuarantine()
+	chatHandler.SetQuarantine(quarantine)
+	noticesHandler.SetQuarantine(quarantine)
+	activityHandler.SetQuarantine(quarantine)
+	adminHandler.SetQuarantine(quarantine)
+	featureGate := api.NewFeatureGate(api.FeatureFlags{
+		Chat:    cfg.Features.Chat,
+		Notices: cfg.Features.Notices,
+		Trust:   cfg.Features.Trust,
+	}, spaceManager)
+	adminHandler.SetFeatureGate(featureGate)
+	activityHandler.SetFeatureGate(featureGate)
+	networkDebugHandler := api.NewNetworkDebugHandler(spaceManager)
+	networkDebugHandler.SetCoordinatorWatchdog(coordinatorWatchdog)
+	idempotencyGuard := api.NewIdempotencyGuard(store, api.DefaultIdempotencyTTL, userIdentity)
+	chatHandler.SetIdempotencyGuard(idempotencyGuard)
+	noticesHandler.SetIdempotencyGuard(idempotencyGuard)
+	// Failed message writes are kept and retried instead of lost
+	writeOutbox := api.NewWriteOutbox(store, spaceManager, eventBroker)
+	writeOutbox.SetTreeListener(chatListener)
+	chatHandler.SetWriteOutbox(writeOutbox)
+	healthHandler.SetWriteOutbox(writeOutbox)
+	spacesHandler.SetWriteOutbox(writeOutbox)
+	healthHandler.SetEventBroker(eventBroker)
+	// One display-name cache for chat senders, notice acks and member lists
+	profileResolver := api.NewProfileResolver(spaceManager)
+	chatHandler.SetProfileResolver(profileResolver)
+	noticesHandler.SetProfileResolver(profileResolver)
+	syncHandler.SetProfileResolver(profileResolver)
+	profilesHandler.SetProfileResolver(profileResolver)
+	activityHandler.SetProfileResolver(profileResolver)
+	syncHandler.SetProfileWriter(profilesHandler)
+
+	// Apply hot-reloadable settings now and whenever the config file changes
+	applyConfig := func(c *config.Config) {
+		api.SetAllowedOrigins(c.Server.CORSOrigins)
+		api.SetRequestLogging(c.Server.RequestLog)
+		trustHandler.SetWeights(trustWeights(c.Trust))
+	}
+	applyConfig(cfg)
+	if configPath != "" {
+		watcher, err := config.Watch(configPath, cfg, applyConfig)
+		if err != nil {
+			log.Printf("[Config] Hot reload disabled: %v\n", err)
+		} else {
+			defer watcher.Close()
+		}
+	}
 
 	// Initialize contributions system
 	fmt.Println("Initializing contributions system...")
@@ -537,6 +776,13 @@ func main() {
 	}
 	orgConfigHandler.AddOnUpdate(setAdminAIDsFromConfig)
 
+	// Keep the community read-only space read-only for members: only the org
+	// and its admins may write there. identityRoleLookup is left out because
+	// it makes the backend's own identity an admin on every backend.
+	spaceManager.ObjectTreeManager().SetWriteGuard(api.NewReadOnlySpaceGuard(
+		spaceManager, userIdentity, api.NewCompositeRoleLookup(profileRoleLookup, orgConfigRoleLookup),
+	))
+
 	proposalsHandler := api.NewProposalsHandler(contribService, spaceManager, contribNotifier)
 	projectsHandler := api.NewProjectsHandler(contribService, spaceManager, contribNotifier)
 	decisionPlansHandler := api.NewDecisionPlansHandler(contribService, spaceManager, contribNotifier)
@@ -554,8 +800,10 @@ func main() {
 	// Create HTTP server
 	mux := http.NewServeMux()
 
-	// Health check endpoint (with sync/trust status)
+	// Health check endpoints: readiness (with subsystem checks and sync/trust
+	// status) and a cheap liveness probe
 	mux.HandleFunc("/health", api.CORSHandler(healthHandler.HandleHealth))
+	mux.HandleFunc("/health/live", api.CORSHandler(healthHandler.HandleLiveness))
 
 	// Info endpoint
 	mux.HandleFunc("/info", api.CORSHandler(func(w http.ResponseWriter, r *http.Request) {
@@ -586,18 +834,23 @@ func main() {
 
 	// Register API routes
 	credHandler.RegisterRoutes(mux)
-	syncHandler.RegisterRoutes(mux)
+	syncHandler.RegisterRoutes(mux, roleLookup)
 	trustHandler.RegisterRoutes(mux)
-	spacesHandler.RegisterRoutes(mux)
+	spacesHandler.RegisterRoutes(mux, roleLookup)
 	invitesHandler.RegisterRoutes(mux)
 	bookingHandler.RegisterRoutes(mux)
 	identityHandler.RegisterRoutes(mux)
+	authHandler.RegisterRoutes(mux)
 	eventsHandler.RegisterRoutes(mux)
+	eventsHandler.RegisterDebugRoutes(mux, roleLookup)
+	presenceTracker.RegisterRoutes(mux)
 	profilesHandler.RegisterRoutes(mux)
-	noticesHandler.RegisterRoutes(mux)
+	noticesHandler.RegisterRoutes(mux, roleLookup)
 	filesHandler.RegisterRoutes(mux)
 	chatHandler.RegisterRoutes(mux)
+	chatHandler.RegisterAdminRoutes(mux, roleLookup)
 	commentCursorsHandler.Routes(mux)
+	activityHandler.RegisterRoutes(mux, roleLookup)
 	notificationsHandler.RegisterRoutes(mux)
 	proposalsHandler.RegisterRoutes(mux, roleLookup)
 	projectsHandler.RegisterRoutes(mux, roleLookup)
@@ -606,19 +859,26 @@ func main() {
 	milestonesHandler.RegisterRoutes(mux, roleLookup)
 	contributionsHandler.RegisterRoutes(mux, roleLookup)
 	orgConfigHandler.RegisterRoutes(mux)
+	adminHandler.RegisterRoutes(mux, roleLookup)
+	featureGate.RegisterRoutes(mux)
+	networkDebugHandler.RegisterRoutes(mux, roleLookup)
 
 	// Start server
 	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
 	fmt.Printf("Starting HTTP server on %s\n", addr)
 	fmt.Println()
 	fmt.Println("Endpoints:")
-	fmt.Println("  GET  /health                       - Health check")
+	fmt.Println("  GET  /health                       - Readiness check")
+	fmt.Println("  GET  /health/live                  - Liveness check")
 	fmt.Println("  GET  /info                         - System information")
+	fmt.Println("  GET  /api/v1/features              - Effective feature flags")
 	fmt.Println()
 	fmt.Println("  Identity (per-user mode):")
 	fmt.Println("  POST /api/v1/identity/set          - Set user identity (triggers SDK restart)")
 	fmt.Println("  GET  /api/v1/identity              - Get current identity status")
 	fmt.Println("  DELETE /api/v1/identity             - Clear identity (logout/reset)")
+	fmt.Println("  POST /api/v1/auth/session          - Issue a session token (mnemonic)")
+	fmt.Println("  GET  /api/v1/auth/session          - Show the authenticated caller")
 	fmt.Println()
 	fmt.Println("  Credentials:")
 	fmt.Println("  GET  /api/v1/org                   - Organization info for frontend")
@@ -627,6 +887,7 @@ func main() {
 	fmt.Println("  GET  /api/v1/credentials/{said}    - Get credential by SAID")
 	fmt.Println("  POST /api/v1/credentials/validate  - Validate credential structure")
 	fmt.Println("  GET  /api/v1/credentials/roles     - List available roles")
+	fmt.Println("  GET  /api/v1/roles                 - List available roles")
 	fmt.Println()
 	fmt.Println("  Sync:")
 	fmt.Println("  POST /api/v1/sync/credentials      - Sync credentials from KERIA")
@@ -648,6 +909,8 @@ func main() {
 	fmt.Println("  POST /api/v1/spaces/community/join           - Join community with invite key")
 	fmt.Println("  GET  /api/v1/spaces/community/verify-access  - Verify community access")
 	fmt.Println("  GET  /api/v1/spaces/sync-status              - Check space sync readiness")
+	fmt.Println("  GET  /api/v1/spaces/stats                    - Storage usage across all spaces (admin)")
+	fmt.Println("  GET  /api/v1/spaces/{id}/stats               - Storage usage for a community space (admin)")
 	fmt.Println()
 	fmt.Println("  Invites:")
 	fmt.Println("  POST /api/v1/invites/send-email       - Email invite code to user")
@@ -684,6 +947,7 @@ func main() {
 	fmt.Println()
 	fmt.Println("  Events:")
 	fmt.Println("  GET  /api/v1/events                   - SSE event stream")
+	fmt.Println("  GET  /api/v1/debug/events             - SSE subscriber backlog (admin)")
 	fmt.Println()
 	fmt.Println("  Chat:")
 	fmt.Println("  GET  /api/v1/chat/channels            - List chat channels")
@@ -755,8 +1019,39 @@ func main() {
 	syncWorker.Start()
 	defer syncWorker.Stop()
 
-	// Wrap with middleware: request logger → localhost guard (production) → CORS
-	handler := api.RequestLogger(api.LocalhostGuard(api.CORSMiddleware(mux)))
+	// Report notices whose acknowledgment due date has passed
+	noticesHandler.StartAckOverdueChecker(time.Minute)
+	defer noticesHandler.StopAckOverdueChecker()
+	noticesHandler.StartScheduledPublisher(time.Minute)
+	defer noticesHandler.StopScheduledPublisher()
+
+	// Expire messages in channels with a retention window
+	chatHandler.StartRetentionSweeper(time.Hour)
+	defer chatHandler.StopRetentionSweeper()
+
+	// Take members offline once their stream's heartbeats lapse
+	presenceTracker.StartSweeper(10 * time.Second)
+	defer presenceTracker.StopSweeper()
+
+	// Retry object-tree writes queued after a failure
+	writeOutbox.StartWorker(5 * time.Second)
+	defer writeOutbox.StopWorker()
+
+	// Sample sync counters and report spaces whose sync has stalled
+	spacesHandler.SetEventBroker(eventBroker)
+	spacesHandler.StartSyncMonitor(time.Minute)
+	defer spacesHandler.StopSyncMonitor()
+
+	// Switch to read-only while the coordinator is unreachable
+	probeInterval := api.DefaultCoordinatorProbeInterval
+	if secs := cfg.AnySync.CoordinatorProbeSeconds; secs > 0 {
+		probeInterval = time.Duration(secs) * time.Second
+	}
+	coordinatorWatchdog.Start(probeInterval)
+	defer coordinatorWatchdog.Stop()
+
+	// Wrap with middleware: request logger → localhost guard (production) → CORS → auth → read-only guard
+	handler := api.RequestLogger(api.LocalhostGuard(api.CORSMiddleware(api.AuthMiddleware(authenticator, api.ReadOnlyGuard(coordinatorWatchdog, api.FeatureGuard(featureGate, mux))))))
 	if err := http.ListenAndServe(addr, handler); err != nil {
 		log.Fatalf("Server failed: %v", err)
 	}
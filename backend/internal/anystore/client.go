@@ -6,6 +6,7 @@ package anystore
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -82,9 +83,14 @@ const (
 	CollectionKELCache         = "kel_cache"
 	CollectionSyncIndex        = "sync_index"
 	CollectionSpaces           = "spaces"
+	CollectionSpaceIDs         = "space_ids"
 	CollectionChatChannels     = "chat_channels"
 	CollectionChatMessages     = "chat_messages"
 	CollectionChatReactions    = "chat_reactions"
+	CollectionIdempotencyKeys  = "idempotency_keys"
+	CollectionWriteOutbox      = "write_outbox"
+	CollectionNoticeReactions  = "notice_reactions"
+	CollectionNoticeRSVPs      = "notice_rsvps"
 )
 
 // CredentialsCache returns the credentials cache collection.
@@ -114,24 +120,26 @@ func (s *LocalStore) SyncIndex(ctx context.Context) (anystore.Collection, error)
 
 // CachedCredential represents a cached ACDC credential.
 type CachedCredential struct {
-	ID         string    `json:"id"`         // SAID of the credential
-	IssuerAID  string    `json:"issuerAID"`  // Issuer's AID
-	SubjectAID string    `json:"subjectAID"` // Subject's AID
-	SchemaID   string    `json:"schemaID"`   // Schema identifier
-	Data       any       `json:"data"`       // Credential data
-	CachedAt   time.Time `json:"cachedAt"`   // When it was cached
-	ExpiresAt  time.Time `json:"expiresAt"`  // Cache expiration
-	Verified   bool      `json:"verified"`   // Whether signature was verified
+	ID         string    `json:"id"`                  // SAID of the credential
+	IssuerAID  string    `json:"issuerAID"`           // Issuer's AID
+	SubjectAID string    `json:"subjectAID"`          // Subject's AID
+	SchemaID   string    `json:"schemaID"`            // Schema identifier
+	Data       any       `json:"data"`                // Credential data
+	CachedAt   time.Time `json:"cachedAt"`            // When it was cached
+	ExpiresAt  time.Time `json:"expiresAt"`           // Cache expiration
+	Verified   bool      `json:"verified"`            // Whether signature was verified
+	Signature  string    `json:"signature,omitempty"` // Issuer's signature over the SAID, kept for re-verification
 }
 
 // TrustGraphNode represents a cached trust graph node.
 type TrustGraphNode struct {
-	AID         string    `json:"id"`          // AID (used as document ID)
-	DisplayName string    `json:"displayName"` // Display name
-	TrustScore  float64   `json:"trustScore"`  // Computed trust score
-	Connections []string  `json:"connections"`  // Connected AIDs
-	Depth       int       `json:"depth"`        // Depth from root
-	CachedAt    time.Time `json:"cachedAt"`     // When computed
+	AID         string    `json:"id"`                // AID (used as document ID)
+	DisplayName string    `json:"displayName"`       // Display name
+	TrustScore  float64   `json:"trustScore"`        // Computed trust score
+	Connections []string  `json:"connections"`       // Connected AIDs
+	Depth       int       `json:"depth"`             // Depth from root
+	CachedAt    time.Time `json:"cachedAt"`          // When computed
+	Version     string    `json:"version,omitempty"` // Fingerprint of the credential set it was computed from
 }
 
 // UserPreference represents a user preference setting.
@@ -213,6 +221,41 @@ func (s *LocalStore) GetTrustNode(ctx context.Context, aid string) (*TrustGraphN
 	return &node, nil
 }
 
+// ListTrustNodes returns all cached trust graph nodes.
+func (s *LocalStore) ListTrustNodes(ctx context.Context) ([]*TrustGraphNode, error) {
+	coll, err := s.TrustGraphCache(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trust graph collection: %w", err)
+	}
+
+	iter, err := coll.Find(nil).Iter(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trust nodes: %w", err)
+	}
+	defer iter.Close()
+
+	var nodes []*TrustGraphNode
+	for iter.Next() {
+		doc, err := iter.Doc()
+		if err != nil {
+			continue
+		}
+		var node TrustGraphNode
+		if err := json.Unmarshal([]byte(doc.Value().String()), &node); err != nil {
+			continue
+		}
+		nodes = append(nodes, &node)
+	}
+
+	return nodes, nil
+}
+
+// InvalidateTrustGraphCache drops all cached trust graph nodes so the next
+// trust query recomputes them.
+func (s *LocalStore) InvalidateTrustGraphCache(ctx context.Context) error {
+	return s.ClearCache(ctx, CollectionTrustGraphCache)
+}
+
 // SetPreference stores a user preference.
 func (s *LocalStore) SetPreference(ctx context.Context, key string, value any) error {
 	coll, err := s.UserPreferences(ctx)
@@ -406,6 +449,71 @@ func (s *LocalStore) UpdateSpaceLastSync(ctx context.Context, spaceID string) er
 	return s.SaveSpaceRecord(ctx, record)
 }
 
+// SpaceIDRecord records which space is configured for a space type, e.g.
+// the community space's ID under "community".
+type SpaceIDRecord struct {
+	ID        string    `json:"id"`        // Space type (used as document ID)
+	SpaceID   string    `json:"spaceId"`   // Configured space
+	UpdatedAt time.Time `json:"updatedAt"` // When it was last set
+}
+
+// SpaceIDs returns the space IDs collection.
+func (s *LocalStore) SpaceIDs(ctx context.Context) (anystore.Collection, error) {
+	return s.db.Collection(ctx, CollectionSpaceIDs)
+}
+
+// SaveSpaceID records spaceID as the space configured for spaceType. An
+// empty spaceID removes the record.
+func (s *LocalStore) SaveSpaceID(ctx context.Context, spaceType, spaceID string) error {
+	coll, err := s.SpaceIDs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get space IDs collection: %w", err)
+	}
+
+	if spaceID == "" {
+		if err := coll.DeleteId(ctx, spaceType); err != nil && !errors.Is(err, anystore.ErrDocNotFound) {
+			return fmt.Errorf("failed to clear %s space ID: %w", spaceType, err)
+		}
+		return nil
+	}
+
+	data, err := json.Marshal(&SpaceIDRecord{ID: spaceType, SpaceID: spaceID, UpdatedAt: time.Now().UTC()})
+	if err != nil {
+		return fmt.Errorf("failed to marshal space ID record: %w", err)
+	}
+	return coll.UpsertOne(ctx, anyenc.MustParseJson(string(data)))
+}
+
+// ListSpaceIDs returns the recorded space IDs keyed by space type.
+func (s *LocalStore) ListSpaceIDs(ctx context.Context) (map[string]string, error) {
+	coll, err := s.SpaceIDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get space IDs collection: %w", err)
+	}
+
+	iter, err := coll.Find(nil).Iter(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query space IDs: %w", err)
+	}
+	defer iter.Close()
+
+	ids := make(map[string]string)
+	for iter.Next() {
+		doc, err := iter.Doc()
+		if err != nil {
+			continue
+		}
+
+		var record SpaceIDRecord
+		if err := json.Unmarshal([]byte(doc.Value().String()), &record); err != nil {
+			continue
+		}
+		ids[record.ID] = record.SpaceID
+	}
+
+	return ids, nil
+}
+
 // GetAllCredentials retrieves all cached credentials from the store.
 func (s *LocalStore) GetAllCredentials(ctx context.Context) ([]*CachedCredential, error) {
 	coll, err := s.CredentialsCache(ctx)
@@ -436,14 +544,107 @@ func (s *LocalStore) GetAllCredentials(ctx context.Context) ([]*CachedCredential
 	return credentials, nil
 }
 
-// CountCredentials returns the count of cached credentials.
-func (s *LocalStore) CountCredentials(ctx context.Context) (int, error) {
+// CredentialQuery filters and pages ListCredentials. Empty fields match any
+// credential.
+type CredentialQuery struct {
+	IssuerAID  string
+	SubjectAID string
+	SchemaID   string
+	After      string // Only credentials with a SAID after this one
+	Limit      int    // Maximum to return; 0 means no limit
+}
+
+// ListCredentials retrieves cached credentials matching q in SAID order.
+// more reports whether further credentials match after the last one returned.
+func (s *LocalStore) ListCredentials(ctx context.Context, q CredentialQuery) (creds []*CachedCredential, more bool, err error) {
+	coll, err := s.CredentialsCache(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get credentials collection: %w", err)
+	}
+
+	filter := credentialFilter(q)
+	if q.After != "" {
+		filter["id"] = map[string]string{"$gt": q.After}
+	}
+	filterJSON, err := json.Marshal(filter)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal credential filter: %w", err)
+	}
+
+	query := coll.Find(anyenc.MustParseJson(string(filterJSON))).Sort("id")
+	if q.Limit > 0 {
+		// One extra to tell whether there is another page
+		query = query.Limit(uint(q.Limit + 1))
+	}
+	iter, err := query.Iter(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to query credentials: %w", err)
+	}
+	defer iter.Close()
+
+	for iter.Next() {
+		doc, err := iter.Doc()
+		if err != nil {
+			continue
+		}
+
+		var cred CachedCredential
+		if err := json.Unmarshal([]byte(doc.Value().String()), &cred); err != nil {
+			continue
+		}
+		creds = append(creds, &cred)
+	}
+
+	if q.Limit > 0 && len(creds) > q.Limit {
+		return creds[:q.Limit], true, nil
+	}
+	return creds, false, nil
+}
+
+// credentialFilter returns the issuer, subject and schema conditions of q.
+func credentialFilter(q CredentialQuery) map[string]any {
+	filter := map[string]any{}
+	if q.IssuerAID != "" {
+		filter["issuerAID"] = q.IssuerAID
+	}
+	if q.SubjectAID != "" {
+		filter["subjectAID"] = q.SubjectAID
+	}
+	if q.SchemaID != "" {
+		filter["schemaID"] = q.SchemaID
+	}
+	return filter
+}
+
+// EnsureCredentialIndexes creates indexes for filtering cached credentials
+// by issuer, subject and schema.
+func (s *LocalStore) EnsureCredentialIndexes(ctx context.Context) error {
+	coll, err := s.CredentialsCache(ctx)
+	if err != nil {
+		return fmt.Errorf("getting credentials collection: %w", err)
+	}
+	for _, field := range []string{"issuerAID", "subjectAID", "schemaID"} {
+		if err := coll.EnsureIndex(ctx, anystore.IndexInfo{Fields: []string{field, "id"}}); err != nil {
+			return fmt.Errorf("creating %s index: %w", field, err)
+		}
+	}
+	return nil
+}
+
+// CountCredentials returns the count of cached credentials matching q's
+// issuer, subject and schema. q.After and q.Limit are ignored, so the count
+// covers every page.
+func (s *LocalStore) CountCredentials(ctx context.Context, q CredentialQuery) (int, error) {
 	coll, err := s.CredentialsCache(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get credentials collection: %w", err)
 	}
 
-	count, err := coll.Count(ctx)
+	filterJSON, err := json.Marshal(credentialFilter(q))
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal credential filter: %w", err)
+	}
+	count, err := coll.Find(anyenc.MustParseJson(string(filterJSON))).Count(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count credentials: %w", err)
 	}
@@ -502,16 +703,18 @@ func (s *LocalStore) ListAllSpaces(ctx context.Context) ([]*SpaceRecord, error)
 
 // ChatChannel represents a chat channel cached in anystore.
 type ChatChannel struct {
-	ID           string   `json:"id"`
-	Name         string   `json:"name"`
-	Description  string   `json:"description,omitempty"`
-	Icon         string   `json:"icon,omitempty"`
-	Photo        string   `json:"photo,omitempty"`
-	CreatedAt    string   `json:"createdAt"`
-	CreatedBy    string   `json:"createdBy"`
-	IsArchived   bool     `json:"isArchived,omitempty"`
-	AllowedRoles []string `json:"allowedRoles,omitempty"`
-	Version      int      `json:"version"`
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	Description   string   `json:"description,omitempty"`
+	Icon          string   `json:"icon,omitempty"`
+	Photo         string   `json:"photo,omitempty"`
+	CreatedAt     string   `json:"createdAt"`
+	CreatedBy     string   `json:"createdBy"`
+	IsArchived    bool     `json:"isArchived,omitempty"`
+	AllowedRoles  []string `json:"allowedRoles,omitempty"`
+	RetentionDays int      `json:"retentionDays,omitempty"`
+	MergedInto    string   `json:"mergedInto,omitempty"`
+	Version       int      `json:"version"`
 }
 
 // ChatMessage represents a chat message cached in anystore.
@@ -526,16 +729,19 @@ type ChatMessage struct {
 	SentAt      string          `json:"sentAt"`
 	EditedAt    string          `json:"editedAt,omitempty"`
 	DeletedAt   string          `json:"deletedAt,omitempty"`
+	Kind        string          `json:"kind,omitempty"`
+	Payload     json.RawMessage `json:"payload,omitempty"`
 	Version     int             `json:"version"`
 }
 
 // ChatReaction represents reactions on a message cached in anystore.
 type ChatReaction struct {
-	ID          string   `json:"id"`
-	MessageID   string   `json:"messageId"`
-	Emoji       string   `json:"emoji"`
-	ReactorAIDs []string `json:"reactorAids"`
-	Version     int      `json:"version"`
+	ID             string   `json:"id"`
+	MessageID      string   `json:"messageId"`
+	Emoji          string   `json:"emoji"`
+	ReactorAIDs    []string `json:"reactorAids"`
+	FirstReactedAt string   `json:"firstReactedAt,omitempty"`
+	Version        int      `json:"version"`
 }
 
 // --- Chat Collection Accessors ---
@@ -569,6 +775,9 @@ func (s *LocalStore) EnsureChatIndexes(ctx context.Context) error {
 	if err := msgColl.EnsureIndex(ctx, anystore.IndexInfo{Fields: []string{"replyTo"}}); err != nil {
 		return fmt.Errorf("creating replyTo index: %w", err)
 	}
+	if err := msgColl.EnsureIndex(ctx, anystore.IndexInfo{Fields: []string{"sentAt"}}); err != nil {
+		return fmt.Errorf("creating sentAt index: %w", err)
+	}
 
 	rxnColl, err := s.ChatReactions(ctx)
 	if err != nil {
@@ -672,6 +881,46 @@ func (s *LocalStore) GetMessage(ctx context.Context, id string) (*ChatMessage, e
 	return &msg, nil
 }
 
+// GetMessagesByIDs retrieves chat messages by ID, keyed by message ID.
+// IDs with no stored message are absent from the result.
+func (s *LocalStore) GetMessagesByIDs(ctx context.Context, ids []string) (map[string]*ChatMessage, error) {
+	result := make(map[string]*ChatMessage)
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	coll, err := s.ChatMessages(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting chat messages collection: %w", err)
+	}
+
+	// Build $in filter for id
+	idsJSON, err := json.Marshal(ids)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling message IDs: %w", err)
+	}
+	filter := anyenc.MustParseJson(fmt.Sprintf(`{"id": {"$in": %s}}`, string(idsJSON)))
+
+	iter, err := coll.Find(filter).Iter(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("querying messages: %w", err)
+	}
+	defer iter.Close()
+
+	for iter.Next() {
+		doc, err := iter.Doc()
+		if err != nil {
+			continue
+		}
+		var msg ChatMessage
+		if err := json.Unmarshal([]byte(doc.Value().String()), &msg); err != nil {
+			continue
+		}
+		result[msg.ID] = &msg
+	}
+	return result, nil
+}
+
 // ListMessagesByChannel retrieves messages for a channel, sorted by sentAt descending.
 func (s *LocalStore) ListMessagesByChannel(ctx context.Context, channelID string, limit, offset int) ([]*ChatMessage, error) {
 	coll, err := s.ChatMessages(ctx)
@@ -709,6 +958,73 @@ func (s *LocalStore) ListMessagesByChannel(ctx context.Context, channelID string
 	return messages, nil
 }
 
+// ListMessagesSentSince retrieves messages in every channel sent at or after
+// the given RFC3339 timestamp, sorted by sentAt descending.
+func (s *LocalStore) ListMessagesSentSince(ctx context.Context, since string) ([]*ChatMessage, error) {
+	coll, err := s.ChatMessages(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting chat messages collection: %w", err)
+	}
+
+	filter := anyenc.MustParseJson(fmt.Sprintf(`{"sentAt": {"$gte": %q}}`, since))
+	iter, err := coll.Find(filter).Sort("-sentAt").Iter(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("querying messages: %w", err)
+	}
+	defer iter.Close()
+
+	var messages []*ChatMessage
+	for iter.Next() {
+		doc, err := iter.Doc()
+		if err != nil {
+			continue
+		}
+		var msg ChatMessage
+		if err := json.Unmarshal([]byte(doc.Value().String()), &msg); err != nil {
+			continue
+		}
+		messages = append(messages, &msg)
+	}
+	return messages, nil
+}
+
+// DeleteMessagesSentBefore removes a channel's messages sent before the given
+// RFC3339 timestamp from the cache and returns how many were removed. Their
+// history stays in the object tree.
+func (s *LocalStore) DeleteMessagesSentBefore(ctx context.Context, channelID, before string) (int, error) {
+	coll, err := s.ChatMessages(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("getting chat messages collection: %w", err)
+	}
+
+	filter := anyenc.MustParseJson(fmt.Sprintf(`{"channelId": %q, "sentAt": {"$lt": %q}}`, channelID, before))
+	iter, err := coll.Find(filter).Iter(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("querying messages: %w", err)
+	}
+
+	var ids []string
+	for iter.Next() {
+		doc, err := iter.Doc()
+		if err != nil {
+			continue
+		}
+		var msg ChatMessage
+		if err := json.Unmarshal([]byte(doc.Value().String()), &msg); err != nil {
+			continue
+		}
+		ids = append(ids, msg.ID)
+	}
+	iter.Close()
+
+	for _, id := range ids {
+		if err := coll.DeleteId(ctx, id); err != nil {
+			return 0, fmt.Errorf("deleting message %s: %w", id, err)
+		}
+	}
+	return len(ids), nil
+}
+
 // ListReplies retrieves replies to a parent message, sorted by sentAt ascending.
 func (s *LocalStore) ListReplies(ctx context.Context, parentMessageID string) ([]*ChatMessage, error) {
 	coll, err := s.ChatMessages(ctx)
@@ -753,6 +1069,19 @@ func (s *LocalStore) UpsertReaction(ctx context.Context, rxn *ChatReaction) erro
 	return coll.UpsertOne(ctx, anyenc.MustParseJson(string(data)))
 }
 
+// DeleteReaction removes a chat reaction, e.g. once its last reactor has
+// left. Deleting a reaction that isn't cached is not an error.
+func (s *LocalStore) DeleteReaction(ctx context.Context, id string) error {
+	coll, err := s.ChatReactions(ctx)
+	if err != nil {
+		return fmt.Errorf("getting chat reactions collection: %w", err)
+	}
+	if err := coll.DeleteId(ctx, id); err != nil && !errors.Is(err, anystore.ErrDocNotFound) {
+		return fmt.Errorf("deleting reaction %s: %w", id, err)
+	}
+	return nil
+}
+
 // GetReaction retrieves a chat reaction by ID.
 func (s *LocalStore) GetReaction(ctx context.Context, id string) (*ChatReaction, error) {
 	coll, err := s.ChatReactions(ctx)
@@ -837,3 +1166,311 @@ func (s *LocalStore) ListReactionsByMessages(ctx context.Context, messageIDs []s
 	}
 	return result, nil
 }
+
+// --- Notice Interactions ---
+
+// NoticeReaction is one member's reaction with one emoji to a notice, cached
+// in anystore so reactions can be counted without reading every tree.
+type NoticeReaction struct {
+	ID        string `json:"id"` // Reaction-{noticeId}-{userId}-{emoji}
+	NoticeID  string `json:"noticeId"`
+	UserID    string `json:"userId"`
+	Emoji     string `json:"emoji"`
+	Active    bool   `json:"active"`
+	CreatedAt string `json:"createdAt,omitempty"`
+}
+
+// NoticeRSVP is one member's RSVP to a notice, or to one occurrence of a
+// recurring event, cached in anystore.
+type NoticeRSVP struct {
+	ID             string `json:"id"` // RSVP-{noticeId}[@{occurrenceDate}]-{userId}
+	NoticeID       string `json:"noticeId"`
+	OccurrenceDate string `json:"occurrenceDate"` // empty for the notice as a whole
+	UserID         string `json:"userId"`
+	Status         string `json:"status"`
+	UpdatedAt      string `json:"updatedAt"`
+}
+
+// NoticeReactions returns the notice reactions collection.
+func (s *LocalStore) NoticeReactions(ctx context.Context) (anystore.Collection, error) {
+	return s.db.Collection(ctx, CollectionNoticeReactions)
+}
+
+// NoticeRSVPs returns the notice RSVPs collection.
+func (s *LocalStore) NoticeRSVPs(ctx context.Context) (anystore.Collection, error) {
+	return s.db.Collection(ctx, CollectionNoticeRSVPs)
+}
+
+// EnsureNoticeIndexes creates indexes for counting notice interactions.
+func (s *LocalStore) EnsureNoticeIndexes(ctx context.Context) error {
+	rxnColl, err := s.NoticeReactions(ctx)
+	if err != nil {
+		return fmt.Errorf("getting notice reactions collection: %w", err)
+	}
+	if err := rxnColl.EnsureIndex(ctx, anystore.IndexInfo{Fields: []string{"noticeId", "active"}}); err != nil {
+		return fmt.Errorf("creating noticeId+active index: %w", err)
+	}
+
+	rsvpColl, err := s.NoticeRSVPs(ctx)
+	if err != nil {
+		return fmt.Errorf("getting notice RSVPs collection: %w", err)
+	}
+	if err := rsvpColl.EnsureIndex(ctx, anystore.IndexInfo{Fields: []string{"noticeId", "occurrenceDate"}}); err != nil {
+		return fmt.Errorf("creating noticeId+occurrenceDate index: %w", err)
+	}
+	return nil
+}
+
+// UpsertNoticeReaction inserts or updates a notice reaction.
+func (s *LocalStore) UpsertNoticeReaction(ctx context.Context, rxn *NoticeReaction) error {
+	coll, err := s.NoticeReactions(ctx)
+	if err != nil {
+		return fmt.Errorf("getting notice reactions collection: %w", err)
+	}
+	data, err := json.Marshal(rxn)
+	if err != nil {
+		return fmt.Errorf("marshaling notice reaction: %w", err)
+	}
+	return coll.UpsertOne(ctx, anyenc.MustParseJson(string(data)))
+}
+
+// UpsertNoticeRSVP inserts or updates a notice RSVP. Two devices can write the
+// same RSVP concurrently, so an RSVP older than the cached one is ignored.
+func (s *LocalStore) UpsertNoticeRSVP(ctx context.Context, rsvp *NoticeRSVP) error {
+	coll, err := s.NoticeRSVPs(ctx)
+	if err != nil {
+		return fmt.Errorf("getting notice RSVPs collection: %w", err)
+	}
+	if doc, err := coll.FindId(ctx, rsvp.ID); err == nil && LaterTimestamp(doc.Value().GetString("updatedAt"), rsvp.UpdatedAt) {
+		return nil
+	}
+	data, err := json.Marshal(rsvp)
+	if err != nil {
+		return fmt.Errorf("marshaling notice RSVP: %w", err)
+	}
+	return coll.UpsertOne(ctx, anyenc.MustParseJson(string(data)))
+}
+
+// LaterTimestamp reports whether RFC3339 timestamp a is after b, comparing
+// the strings when either doesn't parse. Fractional seconds are accepted.
+func LaterTimestamp(a, b string) bool {
+	ta, errA := time.Parse(time.RFC3339, a)
+	tb, errB := time.Parse(time.RFC3339, b)
+	if errA != nil || errB != nil {
+		return a > b
+	}
+	return ta.After(tb)
+}
+
+// CountNoticeReactions counts the active reactions to a notice by emoji.
+// Only the emoji is read from each matching document.
+func (s *LocalStore) CountNoticeReactions(ctx context.Context, noticeID string) (map[string]int, error) {
+	coll, err := s.NoticeReactions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting notice reactions collection: %w", err)
+	}
+	filter := anyenc.MustParseJson(fmt.Sprintf(`{"noticeId": %q, "active": true}`, noticeID))
+	iter, err := coll.Find(filter).Iter(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("querying notice reactions: %w", err)
+	}
+	defer iter.Close()
+
+	counts := make(map[string]int)
+	for iter.Next() {
+		doc, err := iter.Doc()
+		if err != nil {
+			continue
+		}
+		counts[doc.Value().GetString("emoji")]++
+	}
+	return counts, nil
+}
+
+// CountNoticeRSVPs counts the RSVPs to a notice by status; occurrenceDate
+// selects one occurrence of a recurring event, or "" the notice as a whole.
+// Only the status is read from each matching document.
+func (s *LocalStore) CountNoticeRSVPs(ctx context.Context, noticeID, occurrenceDate string) (map[string]int, error) {
+	coll, err := s.NoticeRSVPs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting notice RSVPs collection: %w", err)
+	}
+	filter := anyenc.MustParseJson(fmt.Sprintf(`{"noticeId": %q, "occurrenceDate": %q}`, noticeID, occurrenceDate))
+	iter, err := coll.Find(filter).Iter(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("querying notice RSVPs: %w", err)
+	}
+	defer iter.Close()
+
+	counts := make(map[string]int)
+	for iter.Next() {
+		doc, err := iter.Doc()
+		if err != nil {
+			continue
+		}
+		counts[doc.Value().GetString("status")]++
+	}
+	return counts, nil
+}
+
+// --- Idempotency Keys ---
+
+// IdempotencyRecord is the stored result of a write request made with an
+// Idempotency-Key header, replayed when the same request is retried.
+type IdempotencyRecord struct {
+	ID          string    `json:"id"`          // Scoped key (see api.IdempotencyGuard)
+	RequestHash string    `json:"requestHash"` // Hash of the request body
+	StatusCode  int       `json:"statusCode"`
+	ContentType string    `json:"contentType,omitempty"`
+	Body        string    `json:"body"`
+	CreatedAt   time.Time `json:"createdAt"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
+
+// IdempotencyKeys returns the idempotency keys collection.
+func (s *LocalStore) IdempotencyKeys(ctx context.Context) (anystore.Collection, error) {
+	return s.db.Collection(ctx, CollectionIdempotencyKeys)
+}
+
+// SaveIdempotencyRecord inserts or replaces an idempotency record.
+func (s *LocalStore) SaveIdempotencyRecord(ctx context.Context, rec *IdempotencyRecord) error {
+	coll, err := s.IdempotencyKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("getting idempotency keys collection: %w", err)
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("marshaling idempotency record: %w", err)
+	}
+	return coll.UpsertOne(ctx, anyenc.MustParseJson(string(data)))
+}
+
+// GetIdempotencyRecord retrieves an idempotency record by its scoped key.
+// Expired records are returned as well; callers check ExpiresAt.
+func (s *LocalStore) GetIdempotencyRecord(ctx context.Context, id string) (*IdempotencyRecord, error) {
+	coll, err := s.IdempotencyKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting idempotency keys collection: %w", err)
+	}
+	doc, err := coll.FindId(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("idempotency record not found: %w", err)
+	}
+	var rec IdempotencyRecord
+	if err := json.Unmarshal([]byte(doc.Value().String()), &rec); err != nil {
+		return nil, fmt.Errorf("unmarshaling idempotency record: %w", err)
+	}
+	return &rec, nil
+}
+
+// PurgeExpiredIdempotencyRecords deletes records that expired before now and
+// returns how many were deleted.
+func (s *LocalStore) PurgeExpiredIdempotencyRecords(ctx context.Context, now time.Time) (int, error) {
+	coll, err := s.IdempotencyKeys(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("getting idempotency keys collection: %w", err)
+	}
+	iter, err := coll.Find(nil).Iter(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("querying idempotency records: %w", err)
+	}
+
+	var expired []string
+	for iter.Next() {
+		doc, err := iter.Doc()
+		if err != nil {
+			continue
+		}
+		var rec IdempotencyRecord
+		if err := json.Unmarshal([]byte(doc.Value().String()), &rec); err != nil {
+			continue
+		}
+		if rec.ExpiresAt.Before(now) {
+			expired = append(expired, rec.ID)
+		}
+	}
+	iter.Close()
+
+	for _, id := range expired {
+		if err := coll.DeleteId(ctx, id); err != nil {
+			return 0, fmt.Errorf("deleting idempotency record %s: %w", id, err)
+		}
+	}
+	return len(expired), nil
+}
+
+// --- Write Outbox ---
+
+// OutboxEntry is an object-tree write that failed and is queued for retry
+// (see api.WriteOutbox). Only a reference to the signing key is stored.
+// Payload and Event are kept as JSON text: stored as documents, their
+// integers would come back as floats.
+type OutboxEntry struct {
+	ID            string     `json:"id"` // Object ID of the payload
+	SpaceID       string     `json:"spaceId"`
+	Payload       string     `json:"payload"`         // JSON-encoded anysync.ObjectPayload
+	KeyRef        string     `json:"keyRef"`          // Space whose key set signs the write
+	Event         string     `json:"event,omitempty"` // Broadcast once the write lands
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"lastError,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	NextAttemptAt time.Time  `json:"nextAttemptAt"`
+	DeadAt        *time.Time `json:"deadAt,omitempty"` // Set once retries are exhausted
+}
+
+// WriteOutbox returns the write outbox collection.
+func (s *LocalStore) WriteOutbox(ctx context.Context) (anystore.Collection, error) {
+	return s.db.Collection(ctx, CollectionWriteOutbox)
+}
+
+// SaveOutboxEntry inserts or replaces an outbox entry.
+func (s *LocalStore) SaveOutboxEntry(ctx context.Context, entry *OutboxEntry) error {
+	coll, err := s.WriteOutbox(ctx)
+	if err != nil {
+		return fmt.Errorf("getting write outbox collection: %w", err)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("marshaling outbox entry: %w", err)
+	}
+	return coll.UpsertOne(ctx, anyenc.MustParseJson(string(data)))
+}
+
+// ListOutboxEntries retrieves every outbox entry, including dead-lettered ones.
+func (s *LocalStore) ListOutboxEntries(ctx context.Context) ([]*OutboxEntry, error) {
+	coll, err := s.WriteOutbox(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting write outbox collection: %w", err)
+	}
+	iter, err := coll.Find(nil).Iter(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("querying outbox entries: %w", err)
+	}
+	defer iter.Close()
+
+	var entries []*OutboxEntry
+	for iter.Next() {
+		doc, err := iter.Doc()
+		if err != nil {
+			continue
+		}
+		var entry OutboxEntry
+		if err := json.Unmarshal([]byte(doc.Value().String()), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, &entry)
+	}
+	return entries, nil
+}
+
+// DeleteOutboxEntry removes an outbox entry once its write has landed.
+func (s *LocalStore) DeleteOutboxEntry(ctx context.Context, id string) error {
+	coll, err := s.WriteOutbox(ctx)
+	if err != nil {
+		return fmt.Errorf("getting write outbox collection: %w", err)
+	}
+	if err := coll.DeleteId(ctx, id); err != nil && !errors.Is(err, anystore.ErrDocNotFound) {
+		return fmt.Errorf("deleting outbox entry %s: %w", id, err)
+	}
+	return nil
+}
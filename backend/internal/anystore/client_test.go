@@ -6,6 +6,9 @@ import (
 	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/matou-dao/backend/internal/anysync"
+	anysynctesting "github.com/matou-dao/backend/internal/anysync/testing"
 )
 
 func TestNewLocalStore(t *testing.T) {
@@ -309,6 +312,70 @@ func TestSpaceRecordCRUD(t *testing.T) {
 	}
 }
 
+func TestSpaceIDsRestoredAfterRestart(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &Config{DBPath: filepath.Join(tmpDir, "test.db"), AutoFlush: true}
+	ctx := context.Background()
+
+	store, err := NewLocalStore(cfg)
+	if err != nil {
+		t.Fatalf("failed to create local store: %v", err)
+	}
+	client := anysynctesting.NewMockAnySyncClient()
+	sm := anysync.NewSpaceManager(client, &anysync.SpaceManagerConfig{})
+	sm.SetSpaceIDStore(NewSpaceStoreAdapter(store))
+	sm.SetCommunitySpaceID("space-community-1")
+	sm.SetCommunityReadOnlySpaceID("space-readonly-1")
+	sm.SetAdminSpaceID("space-admin-1")
+	sm.SetAdminSpaceID("")
+	store.Close()
+
+	// Restart: a new store and space manager, configured with a stale community
+	// ID and a readonly ID the store has no record of
+	store, err = NewLocalStore(cfg)
+	if err != nil {
+		t.Fatalf("failed to reopen local store: %v", err)
+	}
+	defer store.Close()
+	sm = anysync.NewSpaceManager(client, &anysync.SpaceManagerConfig{
+		CommunitySpaceID: "space-community-stale",
+		AdminSpaceID:     "space-admin-2",
+	})
+	sm.SetSpaceIDStore(NewSpaceStoreAdapter(store))
+	if err := sm.RestoreSpaceIDs(ctx); err != nil {
+		t.Fatalf("restoring space IDs: %v", err)
+	}
+
+	if got := sm.GetCommunitySpaceID(); got != "space-community-1" {
+		t.Errorf("expected the persisted community space, got %q", got)
+	}
+	if got := sm.GetCommunityReadOnlySpaceID(); got != "space-readonly-1" {
+		t.Errorf("expected the persisted readonly space, got %q", got)
+	}
+	if got := sm.GetAdminSpaceID(); got != "space-admin-2" {
+		t.Errorf("expected the configured admin space after its record was cleared, got %q", got)
+	}
+
+	// The configured admin ID is now recorded too
+	ids, err := store.ListSpaceIDs(ctx)
+	if err != nil {
+		t.Fatalf("listing space IDs: %v", err)
+	}
+	want := map[string]string{
+		anysync.SpaceTypeCommunity:         "space-community-1",
+		anysync.SpaceTypeCommunityReadOnly: "space-readonly-1",
+		anysync.SpaceTypeAdmin:             "space-admin-2",
+	}
+	if len(ids) != len(want) {
+		t.Errorf("expected %v, got %v", want, ids)
+	}
+	for spaceType, id := range want {
+		if ids[spaceType] != id {
+			t.Errorf("%s: expected %q, got %q", spaceType, id, ids[spaceType])
+		}
+	}
+}
+
 func TestGetUserSpaceRecord(t *testing.T) {
 	store := setupTestStore(t)
 	defer store.Close()
@@ -454,6 +521,87 @@ func TestSpacesCollectionAccess(t *testing.T) {
 	}
 }
 
+func TestGetMessagesByIDs(t *testing.T) {
+	store := setupTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+
+	for _, id := range []string{"msg-1", "msg-2", "msg-3"} {
+		msg := &ChatMessage{
+			ID:        id,
+			ChannelID: "channel-1",
+			SenderAID: "EAID123",
+			Content:   "content of " + id,
+			SentAt:    time.Now().UTC().Format(time.RFC3339),
+			Version:   1,
+		}
+		if err := store.UpsertMessage(ctx, msg); err != nil {
+			t.Fatalf("failed to upsert message: %v", err)
+		}
+	}
+
+	messages, err := store.GetMessagesByIDs(ctx, []string{"msg-1", "msg-3", "msg-missing"})
+	if err != nil {
+		t.Fatalf("failed to get messages: %v", err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(messages))
+	}
+	if messages["msg-1"] == nil || messages["msg-1"].Content != "content of msg-1" {
+		t.Errorf("unexpected msg-1: %+v", messages["msg-1"])
+	}
+	if messages["msg-3"] == nil {
+		t.Error("expected msg-3 to be returned")
+	}
+	if _, ok := messages["msg-2"]; ok {
+		t.Error("expected msg-2 not to be returned")
+	}
+
+	empty, err := store.GetMessagesByIDs(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to get messages for no IDs: %v", err)
+	}
+	if len(empty) != 0 {
+		t.Errorf("expected no messages, got %d", len(empty))
+	}
+}
+
+func TestDeleteMessagesSentBefore(t *testing.T) {
+	store := setupTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	for _, m := range []struct {
+		id, channelID string
+		sentAt        time.Time
+	}{
+		{"old", "channel-1", now.AddDate(0, 0, -10)},
+		{"new", "channel-1", now},
+		{"other-channel", "channel-2", now.AddDate(0, 0, -10)},
+	} {
+		msg := &ChatMessage{ID: m.id, ChannelID: m.channelID, SentAt: m.sentAt.Format(time.RFC3339), Version: 1}
+		if err := store.UpsertMessage(ctx, msg); err != nil {
+			t.Fatalf("failed to upsert message: %v", err)
+		}
+	}
+
+	n, err := store.DeleteMessagesSentBefore(ctx, "channel-1", now.AddDate(0, 0, -7).Format(time.RFC3339))
+	if err != nil {
+		t.Fatalf("failed to delete messages: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 message deleted, got %d", n)
+	}
+
+	remaining, _ := store.GetMessagesByIDs(ctx, []string{"old", "new", "other-channel"})
+	if _, ok := remaining["old"]; ok || len(remaining) != 2 {
+		t.Errorf("expected only the old channel-1 message to be deleted, got %v", remaining)
+	}
+}
+
 // setupTestStore creates a temporary test store
 func setupTestStore(t *testing.T) *LocalStore {
 	t.Helper()
@@ -476,3 +624,120 @@ func setupTestStore(t *testing.T) *LocalStore {
 
 	return store
 }
+
+func TestIdempotencyRecords(t *testing.T) {
+	store := setupTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+
+	for _, rec := range []*IdempotencyRecord{
+		{ID: "key-live", RequestHash: "h1", StatusCode: 201, Body: `{"ok":true}`, CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+		{ID: "key-expired", RequestHash: "h2", StatusCode: 201, Body: `{}`, CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour)},
+	} {
+		if err := store.SaveIdempotencyRecord(ctx, rec); err != nil {
+			t.Fatalf("failed to save record: %v", err)
+		}
+	}
+
+	rec, err := store.GetIdempotencyRecord(ctx, "key-live")
+	if err != nil {
+		t.Fatalf("failed to get record: %v", err)
+	}
+	if rec.StatusCode != 201 || rec.Body != `{"ok":true}` || rec.RequestHash != "h1" {
+		t.Errorf("unexpected record: %+v", rec)
+	}
+
+	purged, err := store.PurgeExpiredIdempotencyRecords(ctx, now)
+	if err != nil {
+		t.Fatalf("failed to purge: %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("expected 1 purged record, got %d", purged)
+	}
+	if _, err := store.GetIdempotencyRecord(ctx, "key-expired"); err == nil {
+		t.Error("expected expired record to be purged")
+	}
+	if _, err := store.GetIdempotencyRecord(ctx, "key-live"); err != nil {
+		t.Errorf("expected live record to be kept: %v", err)
+	}
+}
+
+func TestOutboxEntries(t *testing.T) {
+	store := setupTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	// Payloads keep their integers exact through the store
+	payload := `{"id":"ChatMessage-1","type":"ChatMessage","timestamp":1791963851,"version":1}`
+	entry := &OutboxEntry{ID: "ChatMessage-1", SpaceID: "space-1", Payload: payload, KeyRef: "space-1", Attempts: 1, CreatedAt: now, NextAttemptAt: now}
+	if err := store.SaveOutboxEntry(ctx, entry); err != nil {
+		t.Fatalf("failed to save entry: %v", err)
+	}
+
+	entries, err := store.ListOutboxEntries(ctx)
+	if err != nil {
+		t.Fatalf("failed to list entries: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Payload != payload || entries[0].DeadAt != nil || !entries[0].NextAttemptAt.Equal(now) {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+
+	if err := store.DeleteOutboxEntry(ctx, "ChatMessage-1"); err != nil {
+		t.Fatalf("failed to delete entry: %v", err)
+	}
+	if err := store.DeleteOutboxEntry(ctx, "ChatMessage-1"); err != nil {
+		t.Errorf("deleting a missing entry should be a no-op: %v", err)
+	}
+	if entries, _ := store.ListOutboxEntries(ctx); len(entries) != 0 {
+		t.Errorf("expected no entries, got %d", len(entries))
+	}
+}
+
+func TestNoticeInteractionCounts(t *testing.T) {
+	store := setupTestStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	if err := store.EnsureNoticeIndexes(ctx); err != nil {
+		t.Fatalf("failed to create indexes: %v", err)
+	}
+
+	for _, rxn := range []*NoticeReaction{
+		{ID: "Reaction-n1-a-👍", NoticeID: "n1", UserID: "a", Emoji: "👍", Active: true},
+		{ID: "Reaction-n1-b-👍", NoticeID: "n1", UserID: "b", Emoji: "👍", Active: true},
+		{ID: "Reaction-n1-b-🎉", NoticeID: "n1", UserID: "b", Emoji: "🎉", Active: false},
+		{ID: "Reaction-n2-a-👍", NoticeID: "n2", UserID: "a", Emoji: "👍", Active: true},
+	} {
+		if err := store.UpsertNoticeReaction(ctx, rxn); err != nil {
+			t.Fatalf("failed to upsert reaction: %v", err)
+		}
+	}
+	counts, err := store.CountNoticeReactions(ctx, "n1")
+	if err != nil || len(counts) != 1 || counts["👍"] != 2 {
+		t.Errorf("expected 2 active 👍 on n1, got %v (%v)", counts, err)
+	}
+
+	for _, rsvp := range []*NoticeRSVP{
+		{ID: "RSVP-n1-a", NoticeID: "n1", UserID: "a", Status: "going", UpdatedAt: "2026-03-01T10:00:00Z"},
+		{ID: "RSVP-n1-b", NoticeID: "n1", UserID: "b", Status: "maybe", UpdatedAt: "2026-03-01T10:00:00Z"},
+		{ID: "RSVP-n1@2026-03-08T10:00:00Z-a", NoticeID: "n1", OccurrenceDate: "2026-03-08T10:00:00Z", UserID: "a", Status: "going", UpdatedAt: "2026-03-01T10:00:00Z"},
+		// A concurrent write that lost keeps the newer RSVP
+		{ID: "RSVP-n1-a", NoticeID: "n1", UserID: "a", Status: "not_going", UpdatedAt: "2026-03-01T09:00:00Z"},
+	} {
+		if err := store.UpsertNoticeRSVP(ctx, rsvp); err != nil {
+			t.Fatalf("failed to upsert RSVP: %v", err)
+		}
+	}
+	counts, err = store.CountNoticeRSVPs(ctx, "n1", "")
+	if err != nil || len(counts) != 2 || counts["going"] != 1 || counts["maybe"] != 1 {
+		t.Errorf("expected 1 going and 1 maybe on n1, got %v (%v)", counts, err)
+	}
+	counts, _ = store.CountNoticeRSVPs(ctx, "n1", "2026-03-08T10:00:00Z")
+	if len(counts) != 1 || counts["going"] != 1 {
+		t.Errorf("expected 1 going on the occurrence, got %v", counts)
+	}
+}
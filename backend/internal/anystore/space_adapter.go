@@ -80,5 +80,18 @@ func (a *SpaceStoreAdapter) ListAllSpaces(ctx context.Context) ([]*anysync.Space
 	return spaces, nil
 }
 
-// Ensure SpaceStoreAdapter implements anysync.SpaceStore
-var _ anysync.SpaceStore = (*SpaceStoreAdapter)(nil)
+// LoadSpaceIDs retrieves the persisted community, readonly and admin space IDs
+func (a *SpaceStoreAdapter) LoadSpaceIDs(ctx context.Context) (map[string]string, error) {
+	return a.store.ListSpaceIDs(ctx)
+}
+
+// SaveSpaceID persists the space ID configured for a space type
+func (a *SpaceStoreAdapter) SaveSpaceID(ctx context.Context, spaceType, spaceID string) error {
+	return a.store.SaveSpaceID(ctx, spaceType, spaceID)
+}
+
+// Ensure SpaceStoreAdapter implements anysync.SpaceStore and anysync.SpaceIDStore
+var (
+	_ anysync.SpaceStore   = (*SpaceStoreAdapter)(nil)
+	_ anysync.SpaceIDStore = (*SpaceStoreAdapter)(nil)
+)
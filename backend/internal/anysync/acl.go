@@ -6,9 +6,9 @@ package anysync
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
-	"strings"
 	"time"
 
 	"github.com/anyproto/any-sync/commonspace/acl/aclclient"
@@ -93,10 +93,9 @@ func (m *MatouACLManager) CreateOpenInvite(ctx context.Context, spaceID string,
 		// AddRecord internally re-acquires it after the network round-trip).
 		aclClient := space.AclClient()
 		if err := aclClient.AddRecord(ctx, result.InviteRec); err != nil {
-			// Match both the sentinel error (local validation) and the
-			// string message (DRPC error from consensus node).
-			errMsg := err.Error()
-			if strings.Contains(errMsg, "incorrect prev id") {
+			// Both the local validation error and the consensus node's
+			// DRPC error classify as a stale head.
+			if errors.Is(ClassifyError(err), ErrStalePrevID) {
 				lastErr = err
 				log.Printf("[ACL] CreateOpenInvite: stale prev id for space %s (attempt %d), will retry",
 					spaceID, attempt+1)
@@ -427,6 +427,9 @@ func (c *testACLClient) CreateSpace(_ context.Context, _ string, _ string, _ cry
 func (c *testACLClient) CreateSpaceWithKeys(_ context.Context, _ string, _ string, _ *SpaceKeySet) (*SpaceCreateResult, error) {
 	return nil, fmt.Errorf("not implemented")
 }
+func (c *testACLClient) DeriveSpaceWithKeys(_ context.Context, _ string, _ string, _ *SpaceKeySet) (*SpaceCreateResult, error) {
+	return nil, fmt.Errorf("not implemented")
+}
 func (c *testACLClient) DeriveSpace(_ context.Context, _ string, _ string, _ crypto.PrivKey) (*SpaceCreateResult, error) {
 	return nil, fmt.Errorf("not implemented")
 }
@@ -35,6 +35,15 @@ type ClientOptions struct {
 	Mnemonic string
 	// KeyIndex for mnemonic derivation (default 0)
 	KeyIndex uint32
+	// PerIdentityDirs keeps each peer identity's peer.key, space storage and
+	// space keys in its own directory under DataDir (see IdentityDataDir), so
+	// identities sharing DataDir, or switched between by Reinitialize, never
+	// read or overwrite each other's files. PeerKeyPath then only locates the
+	// random device key used before a mnemonic is set.
+	PerIdentityDirs bool
+	// Tuning overrides the any-sync sync period, stream queue sizes and
+	// network timeouts; zero fields use DefaultSyncTuning
+	Tuning SyncTuning
 }
 
 // SpaceCreateResult contains the result of space creation
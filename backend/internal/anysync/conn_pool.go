@@ -0,0 +1,150 @@
+package anysync
+
+import (
+	"context"
+	"sync"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/anyproto/any-sync/net/peer"
+	"github.com/anyproto/any-sync/net/pool"
+)
+
+// PoolStats reports connection reuse in the peer pool shared by the
+// coordinator, consensus, file and tree-node clients.
+type PoolStats struct {
+	ActiveConnections int   `json:"activeConnections"` // open connections handed out by the pool
+	Dials             int64 `json:"dials"`             // new connections established
+	DialFailures      int64 `json:"dialFailures"`      // failed attempts to establish a connection
+	Reuses            int64 `json:"reuses"`            // requests served by an existing connection
+	StaleEvictions    int64 `json:"staleEvictions"`    // connections found closed or dropped, then replaced
+}
+
+// managedPool wraps the any-sync peer pool and is registered under its
+// component name, so every client that looks the pool up (coordinator,
+// consensus, node, space service) shares it. Each Get first picks an existing
+// connection and checks it is still open before dialing, so repeated RPCs to
+// the same peer reuse one connection, and it keeps counters for PoolStats.
+type managedPool struct {
+	inner pool.Service
+
+	mu     sync.Mutex
+	peers  map[string]peer.Peer // peer ID → last connection handed out
+	dials  int64
+	fails  int64
+	reuses int64
+	stale  int64
+}
+
+var _ pool.Service = (*managedPool)(nil)
+
+// newManagedPool wraps inner, typically pool.New().
+func newManagedPool(inner pool.Service) *managedPool {
+	return &managedPool{
+		inner: inner,
+		peers: make(map[string]peer.Peer),
+	}
+}
+
+func (p *managedPool) Init(a *app.App) error { return p.inner.Init(a) }
+
+func (p *managedPool) Name() string { return pool.CName }
+
+func (p *managedPool) Run(ctx context.Context) error { return p.inner.Run(ctx) }
+
+func (p *managedPool) Close(ctx context.Context) error { return p.inner.Close(ctx) }
+
+// Get returns an open connection to id, dialing only if there isn't one.
+func (p *managedPool) Get(ctx context.Context, id string) (peer.Peer, error) {
+	if pr := p.pickHealthy(ctx, id); pr != nil {
+		return pr, nil
+	}
+	pr, err := p.inner.Get(ctx, id)
+	p.recordDial(id, pr, err)
+	return pr, err
+}
+
+// GetOneOf returns an open connection to any of peerIds, dialing one only if
+// none is connected.
+func (p *managedPool) GetOneOf(ctx context.Context, peerIds []string) (peer.Peer, error) {
+	for _, id := range peerIds {
+		if pr := p.pickHealthy(ctx, id); pr != nil {
+			return pr, nil
+		}
+	}
+	pr, err := p.inner.GetOneOf(ctx, peerIds)
+	id := ""
+	if pr != nil {
+		id = pr.Id()
+	}
+	p.recordDial(id, pr, err)
+	return pr, err
+}
+
+func (p *managedPool) AddPeer(ctx context.Context, pr peer.Peer) error {
+	return p.inner.AddPeer(ctx, pr)
+}
+
+func (p *managedPool) Pick(ctx context.Context, id string) (peer.Peer, error) {
+	return p.inner.Pick(ctx, id)
+}
+
+func (p *managedPool) Flush(ctx context.Context) error {
+	p.mu.Lock()
+	p.peers = make(map[string]peer.Peer)
+	p.mu.Unlock()
+	return p.inner.Flush(ctx)
+}
+
+// Stats returns the pool's counters and how many tracked connections are open.
+func (p *managedPool) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := PoolStats{Dials: p.dials, DialFailures: p.fails, Reuses: p.reuses, StaleEvictions: p.stale}
+	for id, pr := range p.peers {
+		if pr.IsClosed() {
+			delete(p.peers, id)
+			continue
+		}
+		stats.ActiveConnections++
+	}
+	return stats
+}
+
+// pickHealthy returns the existing connection to id if it is still open.
+func (p *managedPool) pickHealthy(ctx context.Context, id string) peer.Peer {
+	pr, err := p.inner.Pick(ctx, id)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err != nil || pr == nil {
+		if _, tracked := p.peers[id]; tracked {
+			// The pool has already dropped a connection we handed out
+			delete(p.peers, id)
+			p.stale++
+		}
+		return nil
+	}
+	if pr.IsClosed() {
+		delete(p.peers, id)
+		p.stale++
+		return nil
+	}
+	p.peers[id] = pr
+	p.reuses++
+	return pr
+}
+
+// recordDial updates the counters after asking the inner pool to connect.
+func (p *managedPool) recordDial(id string, pr peer.Peer, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err != nil {
+		p.fails++
+		return
+	}
+	p.dials++
+	if id != "" {
+		p.peers[id] = pr
+	}
+}
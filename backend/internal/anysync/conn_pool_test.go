@@ -0,0 +1,88 @@
+package anysync
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/anyproto/any-sync/net/peer/mock_peer"
+	"github.com/anyproto/any-sync/net/pool/mock_pool"
+	"go.uber.org/mock/gomock"
+)
+
+func TestManagedPool_ReusesConnection(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+	inner := mock_pool.NewMockService(ctrl)
+	coordinator := mock_peer.NewMockPeer(ctrl)
+	coordinator.EXPECT().IsClosed().Return(false).AnyTimes()
+
+	// Nothing is connected until the first call dials; after that the inner
+	// pool has the connection cached and Pick finds it.
+	connected := false
+	inner.EXPECT().Pick(gomock.Any(), "coordinator").DoAndReturn(func(context.Context, string) (*mock_peer.MockPeer, error) {
+		if !connected {
+			return nil, errors.New("not connected")
+		}
+		return coordinator, nil
+	}).AnyTimes()
+	inner.EXPECT().Get(gomock.Any(), "coordinator").DoAndReturn(func(context.Context, string) (*mock_peer.MockPeer, error) {
+		connected = true
+		return coordinator, nil
+	}).Times(1)
+
+	p := newManagedPool(inner)
+	for i := 0; i < 5; i++ {
+		pr, err := p.Get(ctx, "coordinator")
+		if err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+		if pr != coordinator {
+			t.Fatalf("call %d: got a different connection", i)
+		}
+	}
+
+	stats := p.Stats()
+	if stats.Dials != 1 || stats.Reuses != 4 || stats.ActiveConnections != 1 {
+		t.Errorf("expected 1 dial, 4 reuses and 1 active connection, got %+v", stats)
+	}
+}
+
+func TestManagedPool_RedialsClosedConnection(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+	inner := mock_pool.NewMockService(ctrl)
+
+	closed := mock_peer.NewMockPeer(ctrl)
+	closed.EXPECT().IsClosed().Return(true).AnyTimes()
+	fresh := mock_peer.NewMockPeer(ctrl)
+	fresh.EXPECT().IsClosed().Return(false).AnyTimes()
+
+	inner.EXPECT().Pick(gomock.Any(), "node").Return(closed, nil)
+	inner.EXPECT().Get(gomock.Any(), "node").Return(fresh, nil)
+
+	p := newManagedPool(inner)
+	p.peers["node"] = closed
+	pr, err := p.Get(ctx, "node")
+	if err != nil || pr != fresh {
+		t.Fatalf("expected a fresh connection, got %v, %v", pr, err)
+	}
+	if stats := p.Stats(); stats.StaleEvictions != 1 || stats.Dials != 1 {
+		t.Errorf("expected 1 stale eviction and 1 dial, got %+v", stats)
+	}
+}
+
+func TestManagedPool_CountsDialFailures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	inner := mock_pool.NewMockService(ctrl)
+	inner.EXPECT().Pick(gomock.Any(), gomock.Any()).Return(nil, errors.New("not connected")).AnyTimes()
+	inner.EXPECT().GetOneOf(gomock.Any(), []string{"a", "b"}).Return(nil, errors.New("unable to connect"))
+
+	p := newManagedPool(inner)
+	if _, err := p.GetOneOf(context.Background(), []string{"a", "b"}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if stats := p.Stats(); stats.DialFailures != 1 || stats.ActiveConnections != 0 {
+		t.Errorf("expected 1 dial failure and no connections, got %+v", stats)
+	}
+}
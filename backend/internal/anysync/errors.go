@@ -0,0 +1,102 @@
+package anysync
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"syscall"
+
+	"github.com/anyproto/any-sync/commonspace/object/acl/list"
+	"github.com/anyproto/any-sync/consensus/consensusproto/consensuserr"
+	"github.com/anyproto/any-sync/coordinator/coordinatorproto"
+)
+
+// Classes of coordinator and SDK errors, as reported by ClassifyError.
+var (
+	// ErrAlreadyExists means the record or object being added is already there.
+	ErrAlreadyExists = errors.New("already exists")
+	// ErrNotFound means the node answered but doesn't know the space, log or
+	// record asked about.
+	ErrNotFound = errors.New("not found")
+	// ErrUnreachable means no answer came back from the node.
+	ErrUnreachable = errors.New("coordinator unreachable")
+	// ErrStalePrevID means an ACL record was built on a head the consensus
+	// node has since moved past; rebuilding it on the new head may succeed.
+	ErrStalePrevID = errors.New("stale ACL head")
+)
+
+// classifiedError keeps the original error and its message while letting
+// errors.Is match the class.
+type classifiedError struct {
+	class error
+	err   error
+}
+
+func (e *classifiedError) Error() string   { return e.err.Error() }
+func (e *classifiedError) Unwrap() []error { return []error{e.class, e.err} }
+
+// ClassifyError maps an error from the coordinator, consensus node or SDK to
+// one of ErrAlreadyExists, ErrNotFound, ErrUnreachable or ErrStalePrevID, so
+// callers can use errors.Is instead of matching message text. The SDK's own
+// error values are checked first; errors that come back from the network as
+// plain text are matched against their messages here, and only here. Errors
+// of no known class are returned unchanged, and nil stays nil.
+func ClassifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if class := errorClass(err); class != nil {
+		if errors.Is(err, class) {
+			return err
+		}
+		return &classifiedError{class: class, err: err}
+	}
+	return err
+}
+
+func errorClass(err error) error {
+	for _, c := range typedErrorClasses {
+		for _, target := range c.errs {
+			if errors.Is(err, target) {
+				return c.class
+			}
+		}
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return ErrUnreachable
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, c := range errorMessageClasses {
+		for _, text := range c.texts {
+			if strings.Contains(msg, text) {
+				return c.class
+			}
+		}
+	}
+	return nil
+}
+
+var typedErrorClasses = []struct {
+	class error
+	errs  []error
+}{
+	{ErrStalePrevID, []error{list.ErrIncorrectRecordSequence}},
+	{ErrAlreadyExists, []error{consensuserr.ErrLogExists, coordinatorproto.ErrSubscribePeerAlreadySubscribed}},
+	{ErrNotFound, []error{coordinatorproto.ErrSpaceNotExists, consensuserr.ErrLogNotFound}},
+	{ErrUnreachable, []error{context.DeadlineExceeded, syscall.ECONNREFUSED, syscall.EHOSTUNREACH, syscall.ENETUNREACH}},
+}
+
+// errorMessageClasses are matched in order against the lower-cased message,
+// so the more specific texts come first.
+var errorMessageClasses = []struct {
+	class error
+	texts []string
+}{
+	{ErrStalePrevID, []string{"incorrect prev id"}},
+	{ErrAlreadyExists, []string{"already exists", "duplicate"}},
+	{ErrNotFound, []string{"not found", "not exists", "spacenotexists", "unknown"}},
+	{ErrUnreachable, []string{"connection refused", "no such host", "network is unreachable", "i/o timeout", "deadline exceeded"}},
+}
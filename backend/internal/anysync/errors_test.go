@@ -0,0 +1,65 @@
+package anysync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/anyproto/any-sync/commonspace/object/acl/list"
+	"github.com/anyproto/any-sync/consensus/consensusproto/consensuserr"
+	"github.com/anyproto/any-sync/coordinator/coordinatorproto"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error // nil: no class
+	}{
+		// Messages as they arrive over DRPC
+		{"acl record exists", errors.New("rpc error: ACL record already exists"), ErrAlreadyExists},
+		{"duplicate", errors.New("duplicate record"), ErrAlreadyExists},
+		{"space not exists", errors.New("drpc: space not exists"), ErrNotFound},
+		{"space not exists code", errors.New("coordinator: SpaceNotExists"), ErrNotFound},
+		{"log not found", errors.New("log not found"), ErrNotFound},
+		{"unknown space", errors.New("unknown space ping-test"), ErrNotFound},
+		{"stale prev id", errors.New("rpc error: incorrect prev id of a record"), ErrStalePrevID},
+		{"connection refused", errors.New("dial tcp 127.0.0.1:1004: connect: connection refused"), ErrUnreachable},
+		{"no such host", errors.New("dial tcp: lookup coordinator: no such host"), ErrUnreachable},
+		{"timeout", errors.New("read udp 127.0.0.1:1004: i/o timeout"), ErrUnreachable},
+
+		// SDK error values, wrapped or not
+		{"typed space not exists", fmt.Errorf("status check: %w", coordinatorproto.ErrSpaceNotExists), ErrNotFound},
+		{"typed log exists", consensuserr.ErrLogExists, ErrAlreadyExists},
+		{"typed prev id", fmt.Errorf("adding record: %w", list.ErrIncorrectRecordSequence), ErrStalePrevID},
+		{"deadline", fmt.Errorf("ping: %w", context.DeadlineExceeded), ErrUnreachable},
+		{"net error", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("refused")}, ErrUnreachable},
+
+		{"forbidden", coordinatorproto.ErrForbidden, nil},
+		{"other", errors.New("invalid payload"), nil},
+	}
+	classes := []error{ErrAlreadyExists, ErrNotFound, ErrUnreachable, ErrStalePrevID}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ClassifyError(tt.err)
+			if got.Error() != tt.err.Error() {
+				t.Errorf("message changed: %q, want %q", got.Error(), tt.err.Error())
+			}
+			if !errors.Is(got, tt.err) {
+				t.Errorf("classified error no longer wraps the original")
+			}
+			for _, class := range classes {
+				if is := errors.Is(got, class); is != (class == tt.want) {
+					t.Errorf("errors.Is(%q, %v) = %v", tt.err, class, is)
+				}
+			}
+		})
+	}
+
+	if ClassifyError(nil) != nil {
+		t.Error("expected nil to stay nil")
+	}
+}
@@ -30,6 +30,8 @@ type FileMeta struct {
 	Size        int64  `json:"size"`
 	UploadedBy  string `json:"uploadedBy"`
 	UploadedAt  int64  `json:"uploadedAt"`
+	// ThumbnailRef is the CID of a downscaled copy of an image file
+	ThumbnailRef string `json:"thumbnailRef,omitempty"`
 }
 
 // FileManager combines FileHandler + RemoteBlockStore + ObjectTreeManager
@@ -76,6 +78,12 @@ func (m *FileManager) RefreshTransport(p pool.Pool, nc nodeconf.Service) {
 //  5. FileMeta is written as an ObjectPayload into the community space's ObjectTree
 //  6. Returns the root CID string as the file reference
 func (m *FileManager) AddFile(ctx context.Context, spaceID string, reader io.Reader, contentType string, size int64, signingKey crypto.PrivKey) (string, error) {
+	return m.AddFileWithThumbnail(ctx, spaceID, reader, contentType, size, "", signingKey)
+}
+
+// AddFileWithThumbnail is AddFile for a file with an already-uploaded
+// thumbnail, whose ref is recorded in the file's metadata.
+func (m *FileManager) AddFileWithThumbnail(ctx context.Context, spaceID string, reader io.Reader, contentType string, size int64, thumbnailRef string, signingKey crypto.PrivKey) (string, error) {
 	fileId := uuid.New().String()
 
 	// Set spaceId and fileId on the blockstore directly — the IPFS DAG builder
@@ -98,10 +106,11 @@ func (m *FileManager) AddFile(ctx context.Context, spaceID string, reader io.Rea
 	// Write file metadata to the ObjectTree for P2P sync
 	cidStr := rootCID.String()
 	meta := &FileMeta{
-		CID:         cidStr,
-		ContentType: contentType,
-		Size:        size,
-		UploadedAt:  time.Now().Unix(),
+		CID:          cidStr,
+		ContentType:  contentType,
+		Size:         size,
+		UploadedAt:   time.Now().Unix(),
+		ThumbnailRef: thumbnailRef,
 	}
 	if signingKey != nil {
 		meta.UploadedBy = signingKey.GetPublic().Account()
@@ -22,6 +22,11 @@ type AnySyncClient interface {
 	// cryptographic key management.
 	CreateSpaceWithKeys(ctx context.Context, ownerAID string, spaceType string, keys *SpaceKeySet) (*SpaceCreateResult, error)
 
+	// DeriveSpaceWithKeys opens or creates the space whose ID is derived
+	// from the key set, so the same keys always yield the same space. It
+	// only creates the space once the network reports it missing.
+	DeriveSpaceWithKeys(ctx context.Context, ownerAID string, spaceType string, keys *SpaceKeySet) (*SpaceCreateResult, error)
+
 	// GetSpace returns an opened Space by ID. The space must have been
 	// previously created via CreateSpace or CreateSpaceWithKeys.
 	GetSpace(ctx context.Context, spaceID string) (commonspace.Space, error)
@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/anyproto/any-sync/commonspace/object/tree/objecttree"
@@ -18,62 +19,79 @@ import (
 
 // NoticePayload is the API-level representation of a notice.
 type NoticePayload struct {
-	ID               string          `json:"id"`
-	Type             string          `json:"type"`     // "event", "update", or "announcement"
-	Subtype          string          `json:"subtype,omitempty"`
-	Title            string          `json:"title"`
-	Summary          string          `json:"summary"`
-	Body             string          `json:"body,omitempty"`
-	Links            json.RawMessage `json:"links,omitempty"`
-	Images           json.RawMessage `json:"images,omitempty"`
-	Attachments      json.RawMessage `json:"attachments,omitempty"`
-	IssuerType       string          `json:"issuerType"`
-	IssuerID         string          `json:"issuerId"`
-	IssuerName       string          `json:"issuerDisplayName,omitempty"`
-	AudienceMode     string          `json:"audienceMode,omitempty"`
-	AudienceRoleIDs  json.RawMessage `json:"audienceRoleIds,omitempty"`
-	PublishAt        string          `json:"publishAt,omitempty"`
-	ActiveFrom       string          `json:"activeFrom,omitempty"`
-	ActiveUntil      string          `json:"activeUntil,omitempty"`
-	EventStart       string          `json:"eventStart,omitempty"`
-	EventEnd         string          `json:"eventEnd,omitempty"`
-	Timezone         string          `json:"timezone,omitempty"`
-	LocationMode     string          `json:"locationMode,omitempty"`
-	LocationText     string          `json:"locationText,omitempty"`
-	LocationURL      string          `json:"locationUrl,omitempty"`
-	RSVPEnabled      bool            `json:"rsvpEnabled,omitempty"`
-	RSVPRequired     bool            `json:"rsvpRequired,omitempty"`
-	RSVPCapacity     int             `json:"rsvpCapacity,omitempty"`
-	AckRequired      bool            `json:"ackRequired,omitempty"`
-	AckDueAt         string          `json:"ackDueAt,omitempty"`
-	Pinned           bool            `json:"pinned,omitempty"`
-	State            string          `json:"state"` // "draft", "published", "archived"
-	CreatedAt        string          `json:"createdAt"`
-	CreatedBy        string          `json:"createdBy"`
-	PublishedAt      string          `json:"publishedAt,omitempty"`
-	ArchivedAt       string          `json:"archivedAt,omitempty"`
-	AmendsNoticeID   string          `json:"amendsNoticeId,omitempty"`
-	TreeID           string          `json:"treeId,omitempty"`
+	ID              string            `json:"id"`
+	Type            string            `json:"type"` // "event", "update", or "announcement"
+	Subtype         string            `json:"subtype,omitempty"`
+	Title           string            `json:"title"`
+	Summary         string            `json:"summary"`
+	Body            string            `json:"body,omitempty"`
+	Links           json.RawMessage   `json:"links,omitempty"`
+	Images          json.RawMessage   `json:"images,omitempty"`
+	Attachments     json.RawMessage   `json:"attachments,omitempty"`
+	IssuerType      string            `json:"issuerType"`
+	IssuerID        string            `json:"issuerId"`
+	IssuerName      string            `json:"issuerDisplayName,omitempty"`
+	AudienceMode    string            `json:"audienceMode,omitempty"`
+	AudienceRoleIDs json.RawMessage   `json:"audienceRoleIds,omitempty"`
+	PublishAt       string            `json:"publishAt,omitempty"`
+	ActiveFrom      string            `json:"activeFrom,omitempty"`
+	ActiveUntil     string            `json:"activeUntil,omitempty"`
+	EventStart      string            `json:"eventStart,omitempty"`
+	EventEnd        string            `json:"eventEnd,omitempty"`
+	Timezone        string            `json:"timezone,omitempty"`
+	LocationMode    string            `json:"locationMode,omitempty"`
+	LocationText    string            `json:"locationText,omitempty"`
+	LocationURL     string            `json:"locationUrl,omitempty"`
+	RSVPEnabled     bool              `json:"rsvpEnabled,omitempty"`
+	RSVPRequired    bool              `json:"rsvpRequired,omitempty"`
+	RSVPCapacity    int               `json:"rsvpCapacity,omitempty"`
+	AckRequired     bool              `json:"ackRequired,omitempty"`
+	AckDueAt        string            `json:"ackDueAt,omitempty"`
+	Recurrence      *NoticeRecurrence `json:"recurrence,omitempty"`
+	OccurrenceDate  string            `json:"occurrenceDate,omitempty"` // set on expanded occurrences of a recurring event
+	Pinned          bool              `json:"pinned,omitempty"`
+	PinOrder        int               `json:"pinOrder,omitempty"`
+	State           string            `json:"state"` // "draft", "published", "archived"
+	CreatedAt       string            `json:"createdAt"`
+	CreatedBy       string            `json:"createdBy"`
+	PublishedAt     string            `json:"publishedAt,omitempty"`
+	ArchivedAt      string            `json:"archivedAt,omitempty"`
+	EditedAt        string            `json:"editedAt,omitempty"`
+	AmendsNoticeID  string            `json:"amendsNoticeId,omitempty"`
+	Version         int               `json:"version"`
+	TreeID          string            `json:"treeId,omitempty"`
+}
+
+// NoticeRecurrence describes how a recurring event repeats.
+// Either Count or Until bounds the series; neither means open-ended.
+type NoticeRecurrence struct {
+	Frequency string `json:"frequency"`          // "weekly" or "monthly"
+	Interval  int    `json:"interval,omitempty"` // repeat every N periods, defaults to 1
+	Count     int    `json:"count,omitempty"`    // total occurrences, including the first
+	Until     string `json:"until,omitempty"`    // RFC3339, last possible occurrence start
 }
 
 // NoticeAckPayload represents an acknowledgment of a notice.
 type NoticeAckPayload struct {
-	ID       string `json:"id"`
-	NoticeID string `json:"noticeId"`
-	UserID   string `json:"userId"`
-	AckAt    string `json:"ackAt"`
-	Method   string `json:"method"` // "open" or "explicit"
-	TreeID   string `json:"treeId,omitempty"`
+	ID             string `json:"id"`
+	NoticeID       string `json:"noticeId"`
+	OccurrenceDate string `json:"occurrenceDate,omitempty"`
+	UserID         string `json:"userId"`
+	AckAt          string `json:"ackAt"`
+	Method         string `json:"method"`               // "open" or "explicit" when self-acked, "admin" when recorded by an admin
+	RecordedBy     string `json:"recordedBy,omitempty"` // admin AID for "admin" acks
+	TreeID         string `json:"treeId,omitempty"`
 }
 
 // NoticeRSVPPayload represents an RSVP response to a notice.
 type NoticeRSVPPayload struct {
-	ID        string `json:"id"`
-	NoticeID  string `json:"noticeId"`
-	UserID    string `json:"userId"`
-	Status    string `json:"status"` // "going", "maybe", "not_going"
-	UpdatedAt string `json:"updatedAt"`
-	TreeID    string `json:"treeId,omitempty"`
+	ID             string `json:"id"`
+	NoticeID       string `json:"noticeId"`
+	OccurrenceDate string `json:"occurrenceDate,omitempty"`
+	UserID         string `json:"userId"`
+	Status         string `json:"status"` // "going", "maybe", "not_going", "waitlisted"
+	UpdatedAt      string `json:"updatedAt"`
+	TreeID         string `json:"treeId,omitempty"`
 }
 
 // NoticeSavePayload represents a saved/pinned notice bookmark.
@@ -86,15 +104,20 @@ type NoticeSavePayload struct {
 	TreeID   string `json:"treeId,omitempty"`
 }
 
-// NoticeCommentPayload represents a comment on a notice.
+// NoticeCommentPayload represents a comment on a notice. A reply carries the
+// object ID of the comment it replies to in ParentCommentID.
 type NoticeCommentPayload struct {
-	ID              string `json:"id"`
-	NoticeID        string `json:"noticeId"`
-	UserID          string `json:"userId"`
-	UserDisplayName string `json:"userDisplayName,omitempty"`
-	Text            string `json:"text"`
-	CreatedAt       string `json:"createdAt"`
-	TreeID          string `json:"treeId,omitempty"`
+	ID              string   `json:"id"`
+	NoticeID        string   `json:"noticeId"`
+	ParentCommentID string   `json:"parentCommentId,omitempty"`
+	UserID          string   `json:"userId"`
+	UserDisplayName string   `json:"userDisplayName,omitempty"`
+	Text            string   `json:"text"`
+	Mentions        []string `json:"mentions,omitempty"`
+	CreatedAt       string   `json:"createdAt"`
+	DeletedAt       string   `json:"deletedAt,omitempty"`
+	DeletedBy       string   `json:"deletedBy,omitempty"`
+	TreeID          string   `json:"treeId,omitempty"`
 }
 
 // NoticeReactionPayload represents an emoji reaction on a notice.
@@ -196,19 +219,34 @@ func (m *NoticeTreeManager) UpdateNoticeState(ctx context.Context, spaceID, noti
 	stateJSON, _ := json.Marshal(newState)
 	fields["state"] = stateJSON
 
+	var unset []string
 	switch newState {
 	case "published":
 		publishedAt, _ := json.Marshal(now)
 		fields["publishedAt"] = publishedAt
-		if _, ok := state.Fields["publishAt"]; !ok {
+		// Publishing ahead of a schedule moves publishAt up to now
+		var publishAt string
+		if v, ok := state.Fields["publishAt"]; ok {
+			json.Unmarshal(v, &publishAt)
+		}
+		if t, err := time.Parse(time.RFC3339, publishAt); err != nil || t.After(time.Now()) {
 			fields["publishAt"] = publishedAt
 		}
+		unset = append(unset, "archivedAt") // republishing
 	case "archived":
 		archivedAt, _ := json.Marshal(now)
 		fields["archivedAt"] = archivedAt
+	case "draft":
+		// Unpublishing drops the schedule, so the scheduled publisher doesn't
+		// put the notice straight back up
+		unset = append(unset, "publishedAt", "publishAt")
 	}
 
-	diff := DiffState(state, mergeFields(state.Fields, fields))
+	merged := mergeFields(state.Fields, fields)
+	for _, field := range unset {
+		delete(merged, field)
+	}
+	diff := DiffState(state, merged)
 	if diff == nil {
 		return nil // no changes
 	}
@@ -234,6 +272,55 @@ func (m *NoticeTreeManager) UpdateNoticeState(ctx context.Context, spaceID, noti
 	return nil
 }
 
+// UpdateNotice writes the edited content of an existing notice as a single
+// incremental change. Fields that are empty on the payload are unset.
+// notice.Version is the version the edit was made against; if the notice has
+// changed since, UpdateNotice returns ErrVersionConflict.
+func (m *NoticeTreeManager) UpdateNotice(ctx context.Context, spaceID string, notice *NoticePayload, signingKey crypto.PrivKey) error {
+	objectID := fmt.Sprintf("Notice-%s", notice.ID)
+
+	tree, err := m.treeManager.GetTreeForObject(ctx, spaceID, objectID)
+	if err != nil {
+		return fmt.Errorf("notice %s not found: %w", notice.ID, err)
+	}
+
+	tree.Lock()
+	defer tree.Unlock()
+
+	state, err := BuildState(tree, objectID, "Notice")
+	if err != nil {
+		return fmt.Errorf("building state for notice %s: %w", notice.ID, err)
+	}
+	if notice.Version > 0 && state.Version != notice.Version {
+		return fmt.Errorf("%w: notice %s is at version %d, edit was made against %d", ErrVersionConflict, notice.ID, state.Version, notice.Version)
+	}
+
+	diff := DiffState(state, noticeToFields(notice))
+	if diff == nil {
+		return nil // no changes
+	}
+
+	data, err := json.Marshal(diff)
+	if err != nil {
+		return fmt.Errorf("marshaling notice update: %w", err)
+	}
+
+	_, err = tree.AddContent(ctx, objecttree.SignableChangeContent{
+		Data:              data,
+		Key:               signingKey,
+		IsSnapshot:        false,
+		ShouldBeEncrypted: true,
+		Timestamp:         time.Now().Unix(),
+		DataType:          ObjectChangeType,
+	})
+	if err != nil {
+		return fmt.Errorf("adding notice update: %w", err)
+	}
+
+	log.Printf("[NoticeTree] Updated notice %s (%d field ops)", notice.ID, len(diff.Ops))
+	return nil
+}
+
 // ReadNotices reads all notices from a space.
 func (m *NoticeTreeManager) ReadNotices(ctx context.Context, spaceID string) ([]*NoticePayload, error) {
 	entries := m.treeManager.GetTreesByChangeType(spaceID, NoticeTreeType)
@@ -275,10 +362,12 @@ func (m *NoticeTreeManager) ReadNotice(ctx context.Context, spaceID, noticeID st
 	return m.readNoticeFromTree(tree, entry)
 }
 
-// CreateRSVP creates or updates an RSVP for a notice.
-// Uses objectID "RSVP-{noticeId}-{userId}" for last-write-wins semantics.
+// CreateRSVP creates or updates an RSVP for a notice, setting rsvp.ID.
+// Uses objectID "RSVP-{noticeId}-{userId}" for last-write-wins semantics;
+// RSVPs to one occurrence of a recurring event use "RSVP-{noticeId}@{occurrenceDate}-{userId}".
 func (m *NoticeTreeManager) CreateRSVP(ctx context.Context, spaceID string, rsvp *NoticeRSVPPayload, signingKey crypto.PrivKey) (string, error) {
-	objectID := fmt.Sprintf("RSVP-%s-%s", rsvp.NoticeID, rsvp.UserID)
+	objectID := fmt.Sprintf("RSVP-%s-%s", occurrenceKey(rsvp.NoticeID, rsvp.OccurrenceDate), rsvp.UserID)
+	rsvp.ID = objectID
 
 	// Check if RSVP tree already exists (update case)
 	existingTree, _ := m.treeManager.GetTreeForObject(ctx, spaceID, objectID)
@@ -319,8 +408,27 @@ func (m *NoticeTreeManager) CreateRSVP(ctx context.Context, spaceID string, rsvp
 	return treeID, nil
 }
 
-// ReadRSVPs reads all RSVPs for a specific notice.
+// ReadRSVPs reads all RSVPs for a specific notice, across all occurrences.
 func (m *NoticeTreeManager) ReadRSVPs(ctx context.Context, spaceID, noticeID string) ([]*NoticeRSVPPayload, error) {
+	return m.readRSVPsWhere(ctx, spaceID, func(rsvp *NoticeRSVPPayload) bool {
+		return rsvp.NoticeID == noticeID
+	})
+}
+
+// ReadUserRSVPs reads all RSVPs a user has made, across notices and occurrences.
+func (m *NoticeTreeManager) ReadUserRSVPs(ctx context.Context, spaceID, userID string) ([]*NoticeRSVPPayload, error) {
+	return m.readRSVPsWhere(ctx, spaceID, func(rsvp *NoticeRSVPPayload) bool {
+		return rsvp.UserID == userID
+	})
+}
+
+// ReadAllRSVPs reads every RSVP in a space.
+func (m *NoticeTreeManager) ReadAllRSVPs(ctx context.Context, spaceID string) ([]*NoticeRSVPPayload, error) {
+	return m.readRSVPsWhere(ctx, spaceID, func(*NoticeRSVPPayload) bool { return true })
+}
+
+// readRSVPsWhere reads the RSVPs in a space that keep accepts.
+func (m *NoticeTreeManager) readRSVPsWhere(ctx context.Context, spaceID string, keep func(*NoticeRSVPPayload) bool) ([]*NoticeRSVPPayload, error) {
 	entries := m.treeManager.GetTreesByChangeType(spaceID, InteractionTreeType)
 
 	var rsvps []*NoticeRSVPPayload
@@ -342,7 +450,7 @@ func (m *NoticeTreeManager) ReadRSVPs(ctx context.Context, spaceID, noticeID str
 		}
 
 		rsvp := stateToRSVP(state, entry.TreeID)
-		if rsvp.NoticeID == noticeID {
+		if keep(rsvp) {
 			rsvps = append(rsvps, rsvp)
 		}
 	}
@@ -350,9 +458,9 @@ func (m *NoticeTreeManager) ReadRSVPs(ctx context.Context, spaceID, noticeID str
 	return rsvps, nil
 }
 
-// CreateAck creates an acknowledgment for a notice.
+// CreateAck creates an acknowledgment for a notice (or one occurrence of it).
 func (m *NoticeTreeManager) CreateAck(ctx context.Context, spaceID string, ack *NoticeAckPayload, signingKey crypto.PrivKey) (string, error) {
-	objectID := fmt.Sprintf("Ack-%s-%s", ack.NoticeID, ack.UserID)
+	objectID := fmt.Sprintf("Ack-%s-%s", occurrenceKey(ack.NoticeID, ack.OccurrenceDate), ack.UserID)
 
 	// Check if already acked (idempotent)
 	if _, err := m.treeManager.GetTreeForObject(ctx, spaceID, objectID); err == nil {
@@ -390,7 +498,7 @@ func (m *NoticeTreeManager) CreateAck(ctx context.Context, spaceID string, ack *
 	return treeID, nil
 }
 
-// ReadAcks reads all acks for a specific notice.
+// ReadAcks reads all acks for a specific notice, across all occurrences.
 func (m *NoticeTreeManager) ReadAcks(ctx context.Context, spaceID, noticeID string) ([]*NoticeAckPayload, error) {
 	entries := m.treeManager.GetTreesByChangeType(spaceID, InteractionTreeType)
 
@@ -528,6 +636,18 @@ func (m *NoticeTreeManager) CreateComment(ctx context.Context, spaceID string, c
 
 // ReadComments reads all comments for a specific notice.
 func (m *NoticeTreeManager) ReadComments(ctx context.Context, spaceID, noticeID string) ([]*NoticeCommentPayload, error) {
+	return m.readCommentsWhere(ctx, spaceID, func(comment *NoticeCommentPayload) bool {
+		return comment.NoticeID == noticeID
+	})
+}
+
+// ReadAllComments reads every notice comment in a space.
+func (m *NoticeTreeManager) ReadAllComments(ctx context.Context, spaceID string) ([]*NoticeCommentPayload, error) {
+	return m.readCommentsWhere(ctx, spaceID, func(*NoticeCommentPayload) bool { return true })
+}
+
+// readCommentsWhere reads the notice comments in a space that keep accepts.
+func (m *NoticeTreeManager) readCommentsWhere(ctx context.Context, spaceID string, keep func(*NoticeCommentPayload) bool) ([]*NoticeCommentPayload, error) {
 	entries := m.treeManager.GetTreesByChangeType(spaceID, InteractionTreeType)
 
 	var comments []*NoticeCommentPayload
@@ -549,7 +669,7 @@ func (m *NoticeTreeManager) ReadComments(ctx context.Context, spaceID, noticeID
 		}
 
 		comment := stateToComment(state, entry.TreeID)
-		if comment.NoticeID == noticeID {
+		if keep(comment) {
 			comments = append(comments, comment)
 		}
 	}
@@ -557,10 +677,81 @@ func (m *NoticeTreeManager) ReadComments(ctx context.Context, spaceID, noticeID
 	return comments, nil
 }
 
-// CreateReaction creates or updates a reaction for a notice.
+// ReadComment reads a single comment on a notice. commentID may be either the
+// short comment ID returned on create or the full "Comment-{noticeId}-{id}" object ID.
+func (m *NoticeTreeManager) ReadComment(ctx context.Context, spaceID, noticeID, commentID string) (*NoticeCommentPayload, error) {
+	objectID := commentObjectID(noticeID, commentID)
+	tree, err := m.treeManager.GetTreeForObject(ctx, spaceID, objectID)
+	if err != nil {
+		return nil, fmt.Errorf("comment %s not found: %w", commentID, err)
+	}
+
+	tree.Lock()
+	state, err := BuildState(tree, objectID, "NoticeComment")
+	tree.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("building state for comment %s: %w", commentID, err)
+	}
+
+	return stateToComment(state, tree.Id()), nil
+}
+
+// DeleteComment soft-deletes a comment by setting deletedAt/deletedBy.
+// The comment tree is kept so the deletion replicates to peers.
+func (m *NoticeTreeManager) DeleteComment(ctx context.Context, spaceID, noticeID, commentID, deletedBy string, signingKey crypto.PrivKey) error {
+	objectID := commentObjectID(noticeID, commentID)
+
+	tree, err := m.treeManager.GetTreeForObject(ctx, spaceID, objectID)
+	if err != nil {
+		return fmt.Errorf("comment %s not found: %w", commentID, err)
+	}
+
+	tree.Lock()
+	defer tree.Unlock()
+
+	state, err := BuildState(tree, objectID, "NoticeComment")
+	if err != nil {
+		return fmt.Errorf("building state for comment %s: %w", commentID, err)
+	}
+	if _, ok := state.Fields["deletedAt"]; ok {
+		return nil // already deleted
+	}
+
+	fields := map[string]json.RawMessage{}
+	setField(fields, "deletedAt", time.Now().UTC().Format(time.RFC3339))
+	setField(fields, "deletedBy", deletedBy)
+
+	diff := DiffState(state, mergeFields(state.Fields, fields))
+	if diff == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(diff)
+	if err != nil {
+		return fmt.Errorf("marshaling comment deletion: %w", err)
+	}
+
+	_, err = tree.AddContent(ctx, objecttree.SignableChangeContent{
+		Data:              data,
+		Key:               signingKey,
+		IsSnapshot:        false,
+		ShouldBeEncrypted: true,
+		Timestamp:         time.Now().Unix(),
+		DataType:          ObjectChangeType,
+	})
+	if err != nil {
+		return fmt.Errorf("deleting comment: %w", err)
+	}
+
+	log.Printf("[NoticeTree] Deleted comment %s on notice %s by %s", commentID, noticeID, deletedBy)
+	return nil
+}
+
+// CreateReaction creates or updates a reaction for a notice, setting reaction.ID.
 // Uses objectID "Reaction-{noticeId}-{userId}-{emoji}" for last-write-wins semantics.
 func (m *NoticeTreeManager) CreateReaction(ctx context.Context, spaceID string, reaction *NoticeReactionPayload, signingKey crypto.PrivKey) (string, error) {
 	objectID := fmt.Sprintf("Reaction-%s-%s-%s", reaction.NoticeID, reaction.UserID, reaction.Emoji)
+	reaction.ID = objectID
 
 	// Check if reaction tree already exists (toggle case)
 	existingTree, _ := m.treeManager.GetTreeForObject(ctx, spaceID, objectID)
@@ -602,6 +793,18 @@ func (m *NoticeTreeManager) CreateReaction(ctx context.Context, spaceID string,
 
 // ReadReactions reads all reactions for a specific notice.
 func (m *NoticeTreeManager) ReadReactions(ctx context.Context, spaceID, noticeID string) ([]*NoticeReactionPayload, error) {
+	return m.readReactionsWhere(ctx, spaceID, func(reaction *NoticeReactionPayload) bool {
+		return reaction.NoticeID == noticeID
+	})
+}
+
+// ReadAllReactions reads every notice reaction in a space.
+func (m *NoticeTreeManager) ReadAllReactions(ctx context.Context, spaceID string) ([]*NoticeReactionPayload, error) {
+	return m.readReactionsWhere(ctx, spaceID, func(*NoticeReactionPayload) bool { return true })
+}
+
+// readReactionsWhere reads the notice reactions in a space that keep accepts.
+func (m *NoticeTreeManager) readReactionsWhere(ctx context.Context, spaceID string, keep func(*NoticeReactionPayload) bool) ([]*NoticeReactionPayload, error) {
 	entries := m.treeManager.GetTreesByChangeType(spaceID, InteractionTreeType)
 
 	var reactions []*NoticeReactionPayload
@@ -623,7 +826,7 @@ func (m *NoticeTreeManager) ReadReactions(ctx context.Context, spaceID, noticeID
 		}
 
 		reaction := stateToReaction(state, entry.TreeID)
-		if reaction.NoticeID == noticeID {
+		if keep(reaction) {
 			reactions = append(reactions, reaction)
 		}
 	}
@@ -631,8 +834,9 @@ func (m *NoticeTreeManager) ReadReactions(ctx context.Context, spaceID, noticeID
 	return reactions, nil
 }
 
-// UpdateNoticePinned toggles the pinned field on a notice tree.
-func (m *NoticeTreeManager) UpdateNoticePinned(ctx context.Context, spaceID, noticeID string, pinned bool, signingKey crypto.PrivKey) error {
+// UpdateNoticePinned sets the pinned and pinOrder fields on a notice tree.
+// Unpinning clears the order.
+func (m *NoticeTreeManager) UpdateNoticePinned(ctx context.Context, spaceID, noticeID string, pinned bool, pinOrder int, signingKey crypto.PrivKey) error {
 	objectID := fmt.Sprintf("Notice-%s", noticeID)
 
 	tree, err := m.treeManager.GetTreeForObject(ctx, spaceID, objectID)
@@ -651,6 +855,11 @@ func (m *NoticeTreeManager) UpdateNoticePinned(ctx context.Context, spaceID, not
 	fields := map[string]json.RawMessage{}
 	pinnedJSON, _ := json.Marshal(pinned)
 	fields["pinned"] = pinnedJSON
+	if !pinned {
+		pinOrder = 0
+	}
+	orderJSON, _ := json.Marshal(pinOrder)
+	fields["pinOrder"] = orderJSON
 
 	diff := DiffState(state, mergeFields(state.Fields, fields))
 	if diff == nil {
@@ -674,7 +883,7 @@ func (m *NoticeTreeManager) UpdateNoticePinned(ctx context.Context, spaceID, not
 		return fmt.Errorf("updating pinned: %w", err)
 	}
 
-	log.Printf("[NoticeTree] Updated notice %s pinned=%v", noticeID, pinned)
+	log.Printf("[NoticeTree] Updated notice %s pinned=%v order=%d", noticeID, pinned, pinOrder)
 	return nil
 }
 
@@ -799,6 +1008,16 @@ func (m *NoticeTreeManager) updateReaction(ctx context.Context, tree objecttree.
 	return tree.Id(), nil
 }
 
+// commentObjectID returns the object ID for a notice comment, accepting either
+// the short comment ID or an already-prefixed object ID.
+func commentObjectID(noticeID, commentID string) string {
+	prefix := fmt.Sprintf("Comment-%s-", noticeID)
+	if strings.HasPrefix(commentID, prefix) {
+		return commentID
+	}
+	return prefix + commentID
+}
+
 // mergeFields merges new fields into existing fields, returning a combined map.
 func mergeFields(existing, updates map[string]json.RawMessage) map[string]json.RawMessage {
 	merged := make(map[string]json.RawMessage, len(existing)+len(updates))
@@ -890,15 +1109,24 @@ func noticeToFields(n *NoticePayload) map[string]json.RawMessage {
 	if n.AckDueAt != "" {
 		setField(fields, "ackDueAt", n.AckDueAt)
 	}
+	if n.Recurrence != nil {
+		setField(fields, "recurrence", n.Recurrence)
+	}
 	if n.Pinned {
 		setField(fields, "pinned", true)
 	}
+	if n.PinOrder > 0 {
+		setField(fields, "pinOrder", n.PinOrder)
+	}
 	if n.PublishedAt != "" {
 		setField(fields, "publishedAt", n.PublishedAt)
 	}
 	if n.ArchivedAt != "" {
 		setField(fields, "archivedAt", n.ArchivedAt)
 	}
+	if n.EditedAt != "" {
+		setField(fields, "editedAt", n.EditedAt)
+	}
 	if n.AmendsNoticeID != "" {
 		setField(fields, "amendsNoticeId", n.AmendsNoticeID)
 	}
@@ -912,6 +1140,9 @@ func rsvpToFields(r *NoticeRSVPPayload) map[string]json.RawMessage {
 	setField(fields, "userId", r.UserID)
 	setField(fields, "status", r.Status)
 	setField(fields, "updatedAt", r.UpdatedAt)
+	if r.OccurrenceDate != "" {
+		setField(fields, "occurrenceDate", r.OccurrenceDate)
+	}
 	return fields
 }
 
@@ -921,6 +1152,12 @@ func ackToFields(a *NoticeAckPayload) map[string]json.RawMessage {
 	setField(fields, "userId", a.UserID)
 	setField(fields, "ackAt", a.AckAt)
 	setField(fields, "method", a.Method)
+	if a.OccurrenceDate != "" {
+		setField(fields, "occurrenceDate", a.OccurrenceDate)
+	}
+	if a.RecordedBy != "" {
+		setField(fields, "recordedBy", a.RecordedBy)
+	}
 	return fields
 }
 
@@ -940,7 +1177,13 @@ func commentToFields(c *NoticeCommentPayload) map[string]json.RawMessage {
 	if c.UserDisplayName != "" {
 		setField(fields, "userDisplayName", c.UserDisplayName)
 	}
+	if c.ParentCommentID != "" {
+		setField(fields, "parentCommentId", c.ParentCommentID)
+	}
 	setField(fields, "text", c.Text)
+	if len(c.Mentions) > 0 {
+		setField(fields, "mentions", c.Mentions)
+	}
 	setField(fields, "createdAt", c.CreatedAt)
 	return fields
 }
@@ -955,6 +1198,15 @@ func reactionToFields(r *NoticeReactionPayload) map[string]json.RawMessage {
 	return fields
 }
 
+// occurrenceKey identifies a notice, or a single occurrence of a recurring
+// notice when occurrenceDate is set, within interaction object IDs.
+func occurrenceKey(noticeID, occurrenceDate string) string {
+	if occurrenceDate == "" {
+		return noticeID
+	}
+	return noticeID + "@" + occurrenceDate
+}
+
 func setField(fields map[string]json.RawMessage, key string, value interface{}) {
 	b, err := json.Marshal(value)
 	if err == nil {
@@ -1009,13 +1261,22 @@ func stateToNotice(state *ObjectState, treeID string) (*NoticePayload, error) {
 	getIntField(state.Fields, "rsvpCapacity", &n.RSVPCapacity)
 	getBoolField(state.Fields, "ackRequired", &n.AckRequired)
 	getStringField(state.Fields, "ackDueAt", &n.AckDueAt)
+	if v, ok := state.Fields["recurrence"]; ok {
+		var rec NoticeRecurrence
+		if json.Unmarshal(v, &rec) == nil && rec.Frequency != "" {
+			n.Recurrence = &rec
+		}
+	}
 	getBoolField(state.Fields, "pinned", &n.Pinned)
+	getIntField(state.Fields, "pinOrder", &n.PinOrder)
 	getStringField(state.Fields, "state", &n.State)
 	getStringField(state.Fields, "createdAt", &n.CreatedAt)
 	getStringField(state.Fields, "createdBy", &n.CreatedBy)
 	getStringField(state.Fields, "publishedAt", &n.PublishedAt)
 	getStringField(state.Fields, "archivedAt", &n.ArchivedAt)
+	getStringField(state.Fields, "editedAt", &n.EditedAt)
 	getStringField(state.Fields, "amendsNoticeId", &n.AmendsNoticeID)
+	n.Version = state.Version
 
 	return n, nil
 }
@@ -1029,6 +1290,7 @@ func stateToRSVP(state *ObjectState, treeID string) *NoticeRSVPPayload {
 	getStringField(state.Fields, "userId", &r.UserID)
 	getStringField(state.Fields, "status", &r.Status)
 	getStringField(state.Fields, "updatedAt", &r.UpdatedAt)
+	getStringField(state.Fields, "occurrenceDate", &r.OccurrenceDate)
 	return r
 }
 
@@ -1041,6 +1303,8 @@ func stateToAck(state *ObjectState, treeID string) *NoticeAckPayload {
 	getStringField(state.Fields, "userId", &a.UserID)
 	getStringField(state.Fields, "ackAt", &a.AckAt)
 	getStringField(state.Fields, "method", &a.Method)
+	getStringField(state.Fields, "occurrenceDate", &a.OccurrenceDate)
+	getStringField(state.Fields, "recordedBy", &a.RecordedBy)
 	return a
 }
 
@@ -1062,10 +1326,16 @@ func stateToComment(state *ObjectState, treeID string) *NoticeCommentPayload {
 		TreeID: treeID,
 	}
 	getStringField(state.Fields, "noticeId", &c.NoticeID)
+	getStringField(state.Fields, "parentCommentId", &c.ParentCommentID)
 	getStringField(state.Fields, "userId", &c.UserID)
 	getStringField(state.Fields, "userDisplayName", &c.UserDisplayName)
 	getStringField(state.Fields, "text", &c.Text)
+	if v, ok := state.Fields["mentions"]; ok {
+		json.Unmarshal(v, &c.Mentions)
+	}
 	getStringField(state.Fields, "createdAt", &c.CreatedAt)
+	getStringField(state.Fields, "deletedAt", &c.DeletedAt)
+	getStringField(state.Fields, "deletedBy", &c.DeletedBy)
 	return c
 }
 
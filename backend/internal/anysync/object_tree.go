@@ -8,13 +8,16 @@ package anysync
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/anyproto/any-sync/commonspace/object/tree/objecttree"
 	"github.com/anyproto/any-sync/util/crypto"
+	"github.com/matou-dao/backend/internal/types"
 )
 
 // ObjectChangeType is the DataType used for generic object changes in ObjectTrees.
@@ -47,12 +50,30 @@ type ObjectPayload struct {
 	TreeID    string          `json:"treeId,omitempty"` // any-sync tree ID
 }
 
+// ErrVersionConflict is returned by AddObject and CompareAndAddObject when the
+// object was changed after the caller read it.
+var ErrVersionConflict = errors.New("object version conflict")
+
+// ErrReadOnlySpace is returned by writes that the write guard refuses,
+// because the caller may only read the space they target.
+var ErrReadOnlySpace = errors.New("space is read-only for this caller")
+
 // ObjectTreeManager manages generic object storage using tree-per-object model.
 // Each object gets its own ObjectTree via UnifiedTreeManager.
 type ObjectTreeManager struct {
 	client      AnySyncClient
 	keyManager  *PeerKeyManager
 	treeManager *UnifiedTreeManager
+
+	locksMu     sync.Mutex
+	objectLocks map[string]*objectLock // held by versioned writes, dropped when unused
+
+	// Optional: rejects writes of unregistered types (see SetTypeRegistry)
+	typeRegistry *types.Registry
+	validateData bool
+
+	// Optional: rejects writes the caller may not make (see SetWriteGuard)
+	writeGuard func(ctx context.Context, spaceID string) error
 }
 
 // NewObjectTreeManager creates a new ObjectTreeManager backed by UnifiedTreeManager.
@@ -64,12 +85,55 @@ func NewObjectTreeManager(client AnySyncClient, keyManager *PeerKeyManager, tree
 	}
 }
 
+// SetTypeRegistry makes AddObject and CompareAndAddObject reject objects
+// whose Type isn't in the registry, so a typo can't create objects no reader
+// queries for. With validateData, their data must also match the registered
+// TypeDefinition.
+func (m *ObjectTreeManager) SetTypeRegistry(registry *types.Registry, validateData bool) {
+	m.typeRegistry = registry
+	m.validateData = validateData
+}
+
+// SetWriteGuard installs a check run before every CreateObject, UpdateObject,
+// AddObject, CompareAndAddObject and UpsertFields. A non-nil error from guard
+// fails the write before anything is read or signed; guards should wrap
+// ErrReadOnlySpace so callers can tell a refused write from a failed one.
+func (m *ObjectTreeManager) SetWriteGuard(guard func(ctx context.Context, spaceID string) error) {
+	m.writeGuard = guard
+}
+
+// checkType checks payload against the type registry, if any.
+func (m *ObjectTreeManager) checkType(payload *ObjectPayload) error {
+	if m.typeRegistry == nil {
+		return nil
+	}
+	if err := m.typeRegistry.CheckObject(payload.Type, payload.Data, m.validateData); err != nil {
+		return fmt.Errorf("writing %s: %w", payload.ID, err)
+	}
+	return nil
+}
+
+// checkWrite runs the write guard, if any, for a write of objectID.
+func (m *ObjectTreeManager) checkWrite(ctx context.Context, spaceID, objectID string) error {
+	if m.writeGuard == nil {
+		return nil
+	}
+	if err := m.writeGuard(ctx, spaceID); err != nil {
+		return fmt.Errorf("writing %s: %w", objectID, err)
+	}
+	return nil
+}
+
 // CreateObject creates a new object with its own tree and initial field values.
 // Returns the tree ID and head ID.
 func (m *ObjectTreeManager) CreateObject(
 	ctx context.Context, spaceID, objectID, objectType, changeType string,
 	fields map[string]json.RawMessage, signingKey crypto.PrivKey,
 ) (treeID string, headID string, err error) {
+	if err := m.checkWrite(ctx, spaceID, objectID); err != nil {
+		return "", "", err
+	}
+
 	// Create a new tree for this object
 	tree, treeID, err := m.treeManager.CreateObjectTree(ctx, spaceID, objectID, objectType, changeType, signingKey)
 	if err != nil {
@@ -84,8 +148,6 @@ func (m *ObjectTreeManager) CreateObject(
 	}
 
 	tree.Lock()
-	defer tree.Unlock()
-
 	result, err := tree.AddContent(ctx, objecttree.SignableChangeContent{
 		Data:              data,
 		Key:               signingKey,
@@ -94,7 +156,13 @@ func (m *ObjectTreeManager) CreateObject(
 		Timestamp:         time.Now().Unix(),
 		DataType:          ObjectChangeType,
 	})
+	tree.Unlock()
 	if err != nil {
+		// Drop the empty tree, so a retry creates the object afresh instead
+		// of updating a tree that has no state
+		if delErr := m.treeManager.DeleteTree(ctx, spaceID, treeID); delErr != nil {
+			log.Printf("[ObjectTree] Failed to remove empty tree %s for %s: %v", treeID, objectID, delErr)
+		}
 		return "", "", fmt.Errorf("adding init content: %w", err)
 	}
 
@@ -114,6 +182,9 @@ func (m *ObjectTreeManager) UpdateObject(
 	ctx context.Context, spaceID, objectID string,
 	newFields map[string]json.RawMessage, signingKey crypto.PrivKey,
 ) (headID string, err error) {
+	if err := m.checkWrite(ctx, spaceID, objectID); err != nil {
+		return "", err
+	}
 	tree, err := m.treeManager.GetTreeForObject(ctx, spaceID, objectID)
 	if err != nil {
 		return "", fmt.Errorf("getting tree for object %s: %w", objectID, err)
@@ -187,6 +258,9 @@ func (m *ObjectTreeManager) UpdateObject(
 // UpdateObject's full-replace semantics — driven by DiffState emitting "unset"
 // ops for any current field not in newFields — would otherwise wipe data.
 func (m *ObjectTreeManager) UpsertFields(ctx context.Context, spaceID, objectID string, partial map[string]json.RawMessage, signingKey crypto.PrivKey) (string, error) {
+	if err := m.checkWrite(ctx, spaceID, objectID); err != nil {
+		return "", err
+	}
 	existing, err := m.ReadObject(ctx, spaceID, objectID)
 	if err != nil {
 		return "", fmt.Errorf("reading %s for upsert: %w", objectID, err)
@@ -204,7 +278,68 @@ func (m *ObjectTreeManager) UpsertFields(ctx context.Context, spaceID, objectID
 // AddObject adds an object using the legacy ObjectPayload format.
 // For new objects, it creates a tree. For existing objects, it updates.
 // This provides backward compatibility with existing API handlers.
+//
+// A payload with Version > 1 is an edit of version Version-1. If the object
+// has already reached Version, another writer got there first and AddObject
+// returns ErrVersionConflict rather than overwriting their change. Payloads
+// at version 0 or 1 are written unconditionally. With a type registry set,
+// objects of unregistered types fail with types.ErrUnknownType, and writes
+// refused by the write guard fail with ErrReadOnlySpace.
 func (m *ObjectTreeManager) AddObject(ctx context.Context, spaceID string, payload *ObjectPayload, signingKey crypto.PrivKey) (string, error) {
+	if err := m.checkWrite(ctx, spaceID, payload.ID); err != nil {
+		return "", err
+	}
+	if err := m.checkType(payload); err != nil {
+		return "", err
+	}
+	if payload.Version <= 1 {
+		return m.addObject(ctx, spaceID, payload, signingKey)
+	}
+
+	unlock := m.lockObject(payload.ID)
+	defer unlock()
+
+	if current, err := m.ReadObject(ctx, spaceID, payload.ID); err == nil && current.Version >= payload.Version {
+		return "", fmt.Errorf("%w: %s is already at version %d, cannot write version %d", ErrVersionConflict, payload.ID, current.Version, payload.Version)
+	}
+	return m.addObject(ctx, spaceID, payload, signingKey)
+}
+
+// objectLock is the write lock for one object, with the number of writers
+// holding or waiting on it.
+type objectLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lockObject serialises versioned writes to one object and returns the unlock
+// func. The lock is dropped once no writer holds or waits on it.
+func (m *ObjectTreeManager) lockObject(objectID string) func() {
+	m.locksMu.Lock()
+	if m.objectLocks == nil {
+		m.objectLocks = make(map[string]*objectLock)
+	}
+	lock := m.objectLocks[objectID]
+	if lock == nil {
+		lock = &objectLock{}
+		m.objectLocks[objectID] = lock
+	}
+	lock.refs++
+	m.locksMu.Unlock()
+
+	lock.mu.Lock()
+	return func() {
+		lock.mu.Unlock()
+		m.locksMu.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(m.objectLocks, objectID)
+		}
+		m.locksMu.Unlock()
+	}
+}
+
+func (m *ObjectTreeManager) addObject(ctx context.Context, spaceID string, payload *ObjectPayload, signingKey crypto.PrivKey) (string, error) {
 	fields, err := FieldsFromJSON(payload.Data)
 	if err != nil {
 		return "", fmt.Errorf("parsing object data: %w", err)
@@ -228,7 +363,7 @@ func (m *ObjectTreeManager) AddObject(ctx context.Context, spaceID string, paylo
 	// Determine the tree type based on object type
 	changeType := ProfileTreeType
 	switch payload.Type {
-	case "ChatChannel", "ChatMessage", "MessageReaction":
+	case "ChatChannel", "ChatMessage", "MessageReaction", "ChannelMembership":
 		changeType = ChatTreeType
 	}
 
@@ -237,6 +372,33 @@ func (m *ObjectTreeManager) AddObject(ctx context.Context, spaceID string, paylo
 	return headID, err
 }
 
+// CompareAndAddObject is AddObject with optimistic concurrency: the write only
+// happens if the object is still at expectedVersion (0 for an object that
+// doesn't exist yet). Otherwise it returns ErrVersionConflict, and the caller
+// should re-read the object and retry. Writes through this method are
+// serialised per object, so concurrent writers can't both create its tree.
+// Like AddObject, it rejects types the type registry doesn't know.
+func (m *ObjectTreeManager) CompareAndAddObject(ctx context.Context, spaceID string, payload *ObjectPayload, expectedVersion int, signingKey crypto.PrivKey) (string, error) {
+	if err := m.checkWrite(ctx, spaceID, payload.ID); err != nil {
+		return "", err
+	}
+	if err := m.checkType(payload); err != nil {
+		return "", err
+	}
+	unlock := m.lockObject(payload.ID)
+	defer unlock()
+
+	currentVersion := 0
+	if current, err := m.ReadObject(ctx, spaceID, payload.ID); err == nil {
+		currentVersion = current.Version
+	}
+	if currentVersion != expectedVersion {
+		return "", fmt.Errorf("%w: %s is at version %d, expected %d", ErrVersionConflict, payload.ID, currentVersion, expectedVersion)
+	}
+
+	return m.addObject(ctx, spaceID, payload, signingKey)
+}
+
 // ReadObject reads a single object by ID, returning its reconstructed state as ObjectPayload.
 func (m *ObjectTreeManager) ReadObject(ctx context.Context, spaceID, objectID string) (*ObjectPayload, error) {
 	tree, err := m.treeManager.GetTreeForObject(ctx, spaceID, objectID)
@@ -309,6 +471,90 @@ func (m *ObjectTreeManager) ReadObjectsByType(ctx context.Context, spaceID, type
 	return objects, nil
 }
 
+// ObjectQuery narrows ReadObjectsByTypePaged.
+type ObjectQuery struct {
+	// Field and Value keep only objects whose top-level string data field
+	// Field equals Value. Matching happens before the object is serialized,
+	// so non-matching objects cost no payload allocation.
+	Field string
+	Value string
+	// AfterID skips objects whose ID sorts at or before it; pass the previous
+	// page's NextCursor. Skipped objects' trees are never read.
+	AfterID string
+	// Limit caps the number of objects returned; 0 returns every match.
+	Limit int
+}
+
+// ObjectPage is one page of ReadObjectsByTypePaged results, ordered by object ID.
+type ObjectPage struct {
+	Objects    []*ObjectPayload
+	NextCursor string // AfterID for the next page; empty on the last page
+}
+
+// ReadObjectsByTypePaged reads objects of a type from a space, filtered and
+// paged by q. Unlike ReadObjectsByType it doesn't materialize every object of
+// the type: trees before the cursor are skipped, objects failing the field
+// filter are dropped before serialization, and reading stops once the page
+// is full.
+func (m *ObjectTreeManager) ReadObjectsByTypePaged(ctx context.Context, spaceID, typeName string, q ObjectQuery) (*ObjectPage, error) {
+	entries := m.treeManager.GetTreesByType(spaceID, typeName)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ObjectID < entries[j].ObjectID })
+
+	page := &ObjectPage{}
+	for _, entry := range entries {
+		if q.AfterID != "" && entry.ObjectID <= q.AfterID {
+			continue
+		}
+		state, err := m.buildEntryState(ctx, spaceID, entry)
+		if err != nil {
+			log.Printf("[ObjectTree] Warning: failed to build state for %s: %v", entry.ObjectID, err)
+			continue
+		}
+		if q.Field != "" && !stateFieldEquals(state, q.Field, q.Value) {
+			continue
+		}
+		if q.Limit > 0 && len(page.Objects) == q.Limit {
+			// One more match exists, so there is a next page
+			page.NextCursor = page.Objects[len(page.Objects)-1].ID
+			break
+		}
+		page.Objects = append(page.Objects, stateToPayload(state, entry.TreeID))
+	}
+	return page, nil
+}
+
+// buildEntryState builds the state of an indexed object, retrying with a
+// fresh tree when the cached one fails (see ReadObjectsByType).
+func (m *ObjectTreeManager) buildEntryState(ctx context.Context, spaceID string, entry ObjectIndexEntry) (*ObjectState, error) {
+	tree, err := m.treeManager.GetTree(ctx, spaceID, entry.TreeID)
+	if err != nil {
+		return nil, err
+	}
+
+	tree.Lock()
+	state, err := BuildState(tree, entry.ObjectID, entry.ObjectType)
+	tree.Unlock()
+	if err != nil {
+		freshTree, freshErr := m.treeManager.BuildFreshTree(ctx, spaceID, entry.TreeID)
+		if freshErr == nil {
+			freshTree.Lock()
+			state, err = BuildState(freshTree, entry.ObjectID, entry.ObjectType)
+			freshTree.Unlock()
+		}
+	}
+	return state, err
+}
+
+// stateFieldEquals reports whether a state's string field equals value.
+func stateFieldEquals(state *ObjectState, field, value string) bool {
+	raw, ok := state.Fields[field]
+	if !ok {
+		return false
+	}
+	var got string
+	return json.Unmarshal(raw, &got) == nil && got == value
+}
+
 // ReadObjects reads all profile objects from a space (all types).
 // This is used by sync-status and other callers that need all objects.
 func (m *ObjectTreeManager) ReadObjects(ctx context.Context, spaceID string) ([]*ObjectPayload, error) {
@@ -0,0 +1,30 @@
+package anysync
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestLockObject_DropsUnusedLocks(t *testing.T) {
+	m := &ObjectTreeManager{}
+
+	var wg sync.WaitGroup
+	counter := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := m.lockObject("obj-1")
+			counter++
+			unlock()
+		}()
+	}
+	wg.Wait()
+
+	if counter != 20 {
+		t.Errorf("expected 20 serialised writes, got %d", counter)
+	}
+	if len(m.objectLocks) != 0 {
+		t.Errorf("expected no locks left once every writer unlocked, got %d", len(m.objectLocks))
+	}
+}
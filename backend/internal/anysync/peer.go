@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"hash/fnv"
+	"log"
 	"os"
 	"path/filepath"
 
@@ -87,10 +88,14 @@ func GetOrCreatePeerKey(keyPath string) (crypto.PrivKey, error) {
 		return nil, fmt.Errorf("creating key directory: %w", err)
 	}
 
-	// Try to load existing key
+	// Try to load existing key, falling back to the backup of the key it
+	// replaced if it is unreadable
 	if data, err := os.ReadFile(keyPath); err == nil {
 		privKey, err := crypto.UnmarshalEd25519PrivateKeyProto(data)
 		if err != nil {
+			if backup, berr := recoverPeerKey(keyPath); berr == nil {
+				return backup, nil
+			}
 			return nil, fmt.Errorf("unmarshaling existing key: %w", err)
 		}
 		return privKey, nil
@@ -108,7 +113,7 @@ func GetOrCreatePeerKey(keyPath string) (crypto.PrivKey, error) {
 		return nil, fmt.Errorf("marshaling key: %w", err)
 	}
 
-	if err := os.WriteFile(keyPath, data, 0600); err != nil {
+	if err := writeFileAtomic(keyPath, data, 0600); err != nil {
 		return nil, fmt.Errorf("saving key: %w", err)
 	}
 
@@ -216,6 +221,143 @@ func ValidateMnemonic(mnemonic string) error {
 	return nil
 }
 
+// IdentityDataDir returns the directory holding one peer identity's files
+// under a shared base data directory: {baseDir}/identities/{peerID}.
+func IdentityDataDir(baseDir, peerID string) string {
+	return filepath.Join(baseDir, "identities", peerID)
+}
+
+// preservePeerKey copies the peer key at keyPath to peer-{peerID}.key beside
+// it before it is overwritten with next, if it belongs to another identity.
+// That identity's spaces stay readable by restoring the copy.
+func preservePeerKey(keyPath string, next crypto.PrivKey) error {
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil // nothing to replace
+	}
+	current, err := crypto.UnmarshalEd25519PrivateKeyProto(data)
+	if err != nil {
+		return fmt.Errorf("reading existing peer key %s: %w", keyPath, err)
+	}
+	peerID := current.GetPublic().PeerId()
+	if peerID == next.GetPublic().PeerId() {
+		return nil
+	}
+	backup := filepath.Join(filepath.Dir(keyPath), "peer-"+peerID+".key")
+	if err := writeFileAtomic(backup, data, 0600); err != nil {
+		return fmt.Errorf("preserving peer key of %s: %w", peerID, err)
+	}
+	log.Printf("[PeerKey] Replacing peer key of %s; previous key kept at %s", peerID, backup)
+	return nil
+}
+
+// peerKeyBackupSuffix names the copy of the previous peer key that
+// replacePeerKey keeps next to peer.key.
+const peerKeyBackupSuffix = ".bak"
+
+// renameFile moves a fully written temp file into place. Tests replace it to
+// simulate a crash mid-replacement.
+var renameFile = os.Rename
+
+// replacePeerKey atomically replaces the peer key at keyPath with data. The
+// first key it replaces is copied to {keyPath}.bak, which later replacements
+// leave alone, so reinitializing more than once doesn't overwrite the
+// original key's backup. The returned restore func puts back the key just
+// replaced so a reinit that fails later can roll back.
+func replacePeerKey(keyPath string, data []byte) (restore func() error, err error) {
+	previous, err := os.ReadFile(keyPath)
+	switch {
+	case err == nil:
+		backup := keyPath + peerKeyBackupSuffix
+		if _, statErr := os.Stat(backup); os.IsNotExist(statErr) {
+			if err := writeFileAtomic(backup, previous, 0600); err != nil {
+				return nil, fmt.Errorf("backing up peer key: %w", err)
+			}
+		}
+	case os.IsNotExist(err):
+		previous = nil
+	default:
+		return nil, fmt.Errorf("reading peer key: %w", err)
+	}
+
+	if err := writeFileAtomic(keyPath, data, 0600); err != nil {
+		return nil, fmt.Errorf("writing peer key: %w", err)
+	}
+	return func() error {
+		if previous == nil {
+			if err := os.Remove(keyPath); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("removing peer key: %w", err)
+			}
+			return nil
+		}
+		if err := writeFileAtomic(keyPath, previous, 0600); err != nil {
+			return fmt.Errorf("restoring peer key: %w", err)
+		}
+		return nil
+	}, nil
+}
+
+// recoverPeerKey restores the peer key at keyPath from its backup and returns
+// it. It is used when peer.key can't be parsed.
+func recoverPeerKey(keyPath string) (crypto.PrivKey, error) {
+	data, err := os.ReadFile(keyPath + peerKeyBackupSuffix)
+	if err != nil {
+		return nil, fmt.Errorf("reading peer key backup: %w", err)
+	}
+	privKey, err := crypto.UnmarshalEd25519PrivateKeyProto(data)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshaling peer key backup: %w", err)
+	}
+	if err := writeFileAtomic(keyPath, data, 0600); err != nil {
+		return nil, fmt.Errorf("restoring peer key: %w", err)
+	}
+	log.Printf("[PeerKey] %s was unreadable; restored %s from its backup", keyPath, privKey.GetPublic().PeerId())
+	return privKey, nil
+}
+
+// writeFileAtomic writes data to a temp file beside path, syncs it, and
+// renames it into place, then syncs the directory so the rename survives a
+// crash. Readers see either the old file or the new one, never a torn write.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := renameFile(tmpPath, path); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// syncDir flushes a directory's entries to disk.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
 // PersistUserPeerKey saves a user's peer private key for later use (e.g. JoinWithInvite).
 // The key is stored at {dataDir}/users/{userAID}/peer.key.
 func PersistUserPeerKey(dataDir, userAID string, key crypto.PrivKey) error {
@@ -414,3 +414,93 @@ func TestDeriveKeyForAID(t *testing.T) {
 		t.Error("expected same key for same AID")
 	}
 }
+
+func TestReplacePeerKey_InterruptedWriteKeepsOriginal(t *testing.T) {
+	keyPath := filepath.Join(t.TempDir(), "peer.key")
+	original, err := GetOrCreatePeerKey(keyPath)
+	if err != nil {
+		t.Fatalf("creating key: %v", err)
+	}
+	next, _, _ := crypto.GenerateRandomEd25519KeyPair()
+	nextData, _ := next.Marshall()
+
+	// Crash after the new key is written out but before it is renamed into place
+	renameFile = func(string, string) error { panic("simulated crash") }
+	func() {
+		defer func() { recover() }()
+		replacePeerKey(keyPath, nextData)
+	}()
+	renameFile = os.Rename
+
+	loaded, err := GetOrCreatePeerKey(keyPath)
+	if err != nil {
+		t.Fatalf("loading key after crash: %v", err)
+	}
+	if loaded.GetPublic().PeerId() != original.GetPublic().PeerId() {
+		t.Errorf("expected the original key, got %s", loaded.GetPublic().PeerId())
+	}
+}
+
+func TestGetOrCreatePeerKey_RecoversFromBackup(t *testing.T) {
+	keyPath := filepath.Join(t.TempDir(), "peer.key")
+	original, err := GetOrCreatePeerKey(keyPath)
+	if err != nil {
+		t.Fatalf("creating key: %v", err)
+	}
+	next, _, _ := crypto.GenerateRandomEd25519KeyPair()
+	nextData, _ := next.Marshall()
+	if _, err := replacePeerKey(keyPath, nextData); err != nil {
+		t.Fatalf("replacing key: %v", err)
+	}
+
+	// A torn peer.key falls back to the key it replaced
+	if err := os.WriteFile(keyPath, nextData[:len(nextData)/2], 0600); err != nil {
+		t.Fatalf("corrupting key: %v", err)
+	}
+	loaded, err := GetOrCreatePeerKey(keyPath)
+	if err != nil {
+		t.Fatalf("loading corrupt key: %v", err)
+	}
+	if loaded.GetPublic().PeerId() != original.GetPublic().PeerId() {
+		t.Errorf("expected the backed-up key, got %s", loaded.GetPublic().PeerId())
+	}
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		t.Fatalf("reading restored key: %v", err)
+	}
+	if _, err := crypto.UnmarshalEd25519PrivateKeyProto(data); err != nil {
+		t.Errorf("expected peer.key to be restored on disk: %v", err)
+	}
+}
+
+func TestReplacePeerKey_Restore(t *testing.T) {
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "peer.key")
+	original, err := GetOrCreatePeerKey(keyPath)
+	if err != nil {
+		t.Fatalf("creating key: %v", err)
+	}
+	next, _, _ := crypto.GenerateRandomEd25519KeyPair()
+	nextData, _ := next.Marshall()
+
+	restore, err := replacePeerKey(keyPath, nextData)
+	if err != nil {
+		t.Fatalf("replacing key: %v", err)
+	}
+	if loaded, _ := GetOrCreatePeerKey(keyPath); loaded.GetPublic().PeerId() != next.GetPublic().PeerId() {
+		t.Fatalf("expected the new key after replacement")
+	}
+	if err := restore(); err != nil {
+		t.Fatalf("restoring key: %v", err)
+	}
+	if loaded, _ := GetOrCreatePeerKey(keyPath); loaded.GetPublic().PeerId() != original.GetPublic().PeerId() {
+		t.Errorf("expected the original key after restore")
+	}
+
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if e.Name() != "peer.key" && e.Name() != "peer.key"+peerKeyBackupSuffix {
+			t.Errorf("unexpected leftover file %s", e.Name())
+		}
+	}
+}
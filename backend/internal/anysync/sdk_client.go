@@ -5,11 +5,11 @@ package anysync
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
-	"strings"
 	"sync"
 	"time"
 
@@ -44,6 +44,7 @@ import (
 	"github.com/anyproto/any-sync/util/syncqueues"
 	"github.com/anyproto/go-chash"
 	anystore "github.com/anyproto/any-store"
+	"golang.org/x/sync/singleflight"
 	"storj.io/drpc"
 )
 
@@ -54,10 +55,15 @@ type SDKClient struct {
 	config          *ClientConfig
 	spaceService    commonspace.SpaceService
 	coordinator     coordinatorclient.CoordinatorClient
+	connPool        *managedPool
 	storageProvider spacestorage.SpaceStorageProvider
 	peerKeyManager  *PeerKeyManager
 	utm             *UnifiedTreeManager // single UTM, persists across reinits
-	dataDir         string
+	dataDir         string              // current identity's files (see ClientOptions.PerIdentityDirs)
+	baseDir         string
+	keyPath         string
+	perIdentityDirs bool
+	tuning          SyncTuning
 	networkID       string
 	coordinatorURL  string
 	initialized     bool
@@ -87,17 +93,18 @@ func NewSDKClient(clientConfigPath string, opts *ClientOptions) (*SDKClient, err
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
 		return nil, fmt.Errorf("creating data directory: %w", err)
 	}
-	spacesDir := filepath.Join(dataDir, "spaces")
-	if err := os.MkdirAll(spacesDir, 0755); err != nil {
-		return nil, fmt.Errorf("creating spaces directory: %w", err)
-	}
 
 	client := &SDKClient{
-		config:         clientConfig,
-		networkID:      clientConfig.NetworkID,
-		coordinatorURL: coordinatorURL,
-		dataDir:        dataDir,
-		utm:            NewUnifiedTreeManager(),
+		config:          clientConfig,
+		networkID:       clientConfig.NetworkID,
+		coordinatorURL:  coordinatorURL,
+		dataDir:         dataDir,
+		baseDir:         dataDir,
+		perIdentityDirs: opts != nil && opts.PerIdentityDirs,
+		utm:             NewUnifiedTreeManager(),
+	}
+	if opts != nil {
+		client.tuning = opts.Tuning
 	}
 
 	// Initialize peer key manager
@@ -105,6 +112,7 @@ func NewSDKClient(clientConfigPath string, opts *ClientOptions) (*SDKClient, err
 	if opts != nil && opts.PeerKeyPath != "" {
 		keyPath = opts.PeerKeyPath
 	}
+	client.keyPath = keyPath
 
 	var mnemonic string
 	var keyIndex uint32
@@ -123,6 +131,13 @@ func NewSDKClient(clientConfigPath string, opts *ClientOptions) (*SDKClient, err
 	}
 	client.peerKeyManager = peerMgr
 
+	if client.perIdentityDirs {
+		client.dataDir = IdentityDataDir(dataDir, peerMgr.GetPeerID())
+	}
+	if err := os.MkdirAll(SpacesStorageDir(client.dataDir), 0755); err != nil {
+		return nil, fmt.Errorf("creating spaces directory: %w", err)
+	}
+
 	// Initialize the full SDK app
 	if err := client.initFullSDK(); err != nil {
 		return nil, fmt.Errorf("initializing SDK: %w", err)
@@ -144,13 +159,13 @@ func (c *SDKClient) initFullSDK() error {
 	accountSvc := &sdkAccountService{keys: accountKeys}
 
 	// 2. Create unified config provider
-	cfg := newSDKConfig(c.config)
+	cfg := newSDKConfig(c.config, c.tuning)
 
 	// 3. Create node configuration from client config
 	nodeConf := newSDKNodeConf(c.config)
 
 	// 4. Create storage provider
-	storageDir := filepath.Join(c.dataDir, "spaces")
+	storageDir := SpacesStorageDir(c.dataDir)
 	c.storageProvider = newSDKStorageProvider(storageDir)
 
 	// Register components in dependency order:
@@ -171,7 +186,8 @@ func (c *SDKClient) initFullSDK() error {
 	c.app.Register(quic.New())
 
 	// Layer 4: Networking and sync utilities
-	c.app.Register(pool.New())
+	c.connPool = newManagedPool(pool.New())
+	c.app.Register(c.connPool)
 	c.app.Register(peerservice.New())
 	c.app.Register(streampool.New())
 	c.app.Register(syncqueues.New())
@@ -313,7 +329,7 @@ func (c *SDKClient) CreateSpaceWithKeys(ctx context.Context, ownerAID string, sp
 		return nil, fmt.Errorf("persisting space keys: %w", err)
 	}
 
-	log.Printf("[any-sync SDK] Created space with keys: %s (type: %s) readKeyNil=%v", spaceID[:20]+"...", spaceType, keys.ReadKey == nil)
+	log.Printf("[any-sync SDK] Created space with keys: %s (type: %s) readKeyNil=%v", spaceID[:min(20, len(spaceID))]+"...", spaceType, keys.ReadKey == nil)
 
 	return &SpaceCreateResult{
 		SpaceID:   spaceID,
@@ -332,8 +348,11 @@ func (c *SDKClient) GetSpace(ctx context.Context, spaceID string) (commonspace.S
 	return resolver.GetSpace(ctx, spaceID)
 }
 
-// GetDataDir returns the data directory path
+// GetDataDir returns the data directory path for the current identity. With
+// PerIdentityDirs it changes when Reinitialize switches identity.
 func (c *SDKClient) GetDataDir() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.dataDir
 }
 
@@ -367,7 +386,7 @@ func (c *SDKClient) DeriveSpace(ctx context.Context, ownerAID string, spaceType
 		return nil, fmt.Errorf("deriving space: %w", err)
 	}
 
-	fmt.Printf("[any-sync SDK] Derived space: %s (type: %s)\n", spaceID[:20]+"...", spaceType)
+	fmt.Printf("[any-sync SDK] Derived space: %s (type: %s)\n", spaceID[:min(20, len(spaceID))]+"...", spaceType)
 
 	return &SpaceCreateResult{
 		SpaceID:   spaceID,
@@ -465,9 +484,7 @@ func (c *SDKClient) AddToACL(ctx context.Context, spaceID string, peerID string,
 	_, err = c.coordinator.AclAddRecord(ctx, spaceID, record)
 	if err != nil {
 		// Tolerate "already exists" errors gracefully
-		errStr := err.Error()
-		if strings.Contains(errStr, "already exists") ||
-			strings.Contains(errStr, "duplicate") {
+		if errors.Is(ClassifyError(err), ErrAlreadyExists) {
 			fmt.Printf("[any-sync SDK] AddToACL: peer %s already in ACL for space %s\n", peerID, spaceID)
 			return nil
 		}
@@ -523,7 +540,7 @@ func (c *SDKClient) SetAccountFileLimits(ctx context.Context, identity string, l
 		return fmt.Errorf("setting account file limits: %w", err)
 	}
 
-	fmt.Printf("[any-sync SDK] SetAccountFileLimits: identity=%s limit=%d bytes\n", identity[:20]+"...", limitBytes)
+	fmt.Printf("[any-sync SDK] SetAccountFileLimits: identity=%s limit=%d bytes\n", identity[:min(20, len(identity))]+"...", limitBytes)
 	return nil
 }
 
@@ -577,14 +594,10 @@ func (c *SDKClient) Ping() error {
 	_, err := c.coordinator.StatusCheck(ctx, "ping-test")
 	if err != nil {
 		// Any response from the coordinator (including "space not exists") means it's reachable
-		errStr := err.Error()
-		if strings.Contains(errStr, "not found") ||
-			strings.Contains(errStr, "not exists") ||
-			strings.Contains(errStr, "SpaceNotExists") ||
-			strings.Contains(errStr, "unknown") {
+		if errors.Is(ClassifyError(err), ErrNotFound) {
 			return nil
 		}
-		return fmt.Errorf("coordinator unreachable: %w", err)
+		return fmt.Errorf("%w: %w", ErrUnreachable, err)
 	}
 	return nil
 }
@@ -612,6 +625,17 @@ func (c *SDKClient) GetPool() pool.Pool {
 	return c.app.MustComponent(pool.CName).(pool.Pool)
 }
 
+// PoolStats returns connection pool counters for the metrics endpoint.
+// They restart from zero after Reinitialize, which replaces the pool.
+func (c *SDKClient) PoolStats() PoolStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.connPool == nil {
+		return PoolStats{}
+	}
+	return c.connPool.Stats()
+}
+
 // GetNodeConf returns the node configuration service for peer discovery.
 func (c *SDKClient) GetNodeConf() nodeconf.Service {
 	return c.app.MustComponent(nodeconf.CName).(nodeconf.Service)
@@ -639,17 +663,32 @@ func (c *SDKClient) GetSigningKey() crypto.PrivKey {
 	return nil
 }
 
-// Reinitialize shuts down all any-sync components, overwrites the peer key
-// with a mnemonic-derived key, and restarts the SDK with the new identity.
-// This is called by POST /api/v1/identity/set when the user's identity is
-// established (org setup, registration, or claim flow).
+// Reinitialize shuts down all any-sync components, writes a mnemonic-derived
+// peer key, and restarts the SDK with the new identity. With PerIdentityDirs
+// the key and all space files move to that identity's directory; otherwise the
+// shared peer.key is overwritten, and a different identity's key it replaces
+// is kept next to it (see preservePeerKey). peer.key is replaced atomically,
+// and if any step after the shutdown fails the previous key is restored and
+// the SDK is brought back up with it. This is called by
+// POST /api/v1/identity/set when the user's identity is established (org
+// setup, registration, or claim flow).
 func (c *SDKClient) Reinitialize(mnemonic string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	log.Println("[any-sync SDK] Reinitializing with mnemonic-derived peer key...")
 
-	// 1. Shut down the current app
+	// 1. Derive the new peer key from the mnemonic before anything is shut down
+	privKey, err := DeriveKeyFromMnemonic(mnemonic, 0)
+	if err != nil {
+		return fmt.Errorf("deriving key from mnemonic: %w", err)
+	}
+	keyData, err := privKey.Marshall()
+	if err != nil {
+		return fmt.Errorf("marshaling derived key: %w", err)
+	}
+
+	// 2. Shut down the current app
 	if c.app != nil {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
@@ -660,21 +699,44 @@ func (c *SDKClient) Reinitialize(mnemonic string) error {
 		c.initialized = false
 	}
 
-	// 2. Derive new peer key from mnemonic
-	privKey, err := DeriveKeyFromMnemonic(mnemonic, 0)
-	if err != nil {
-		return fmt.Errorf("deriving key from mnemonic: %w", err)
+	// A failure from here on puts the previous key and identity back and
+	// restarts the SDK with them
+	prevDataDir, prevKeyManager := c.dataDir, c.peerKeyManager
+	restoreKey := func() error { return nil }
+	rollback := func(cause error) error {
+		if err := restoreKey(); err != nil {
+			log.Printf("[any-sync SDK] Warning: rolling back peer.key: %v", err)
+		}
+		c.dataDir = prevDataDir
+		c.peerKeyManager = prevKeyManager
+		if prevKeyManager != nil {
+			if err := c.initFullSDK(); err != nil {
+				log.Printf("[any-sync SDK] Warning: restarting with previous peer key: %v", err)
+			} else {
+				c.initialized = true
+			}
+		}
+		return cause
+	}
+
+	// 3. Write the derived key: into the identity's own directory, or over
+	// the shared peer.key, keeping the key it replaces. The write is atomic
+	// and keeps peer.key.bak for rollback.
+	keyPath := c.keyPath
+	if c.perIdentityDirs {
+		c.dataDir = IdentityDataDir(c.baseDir, privKey.GetPublic().PeerId())
+		keyPath = filepath.Join(c.dataDir, "peer.key")
+		if err := os.MkdirAll(SpacesStorageDir(c.dataDir), 0755); err != nil {
+			return rollback(fmt.Errorf("creating identity directory: %w", err))
+		}
+	} else if err := preservePeerKey(keyPath, privKey); err != nil {
+		return rollback(err)
 	}
-
-	// 3. Overwrite {dataDir}/peer.key with the derived key
-	keyPath := filepath.Join(c.dataDir, "peer.key")
-	keyData, err := privKey.Marshall()
+	restore, err := replacePeerKey(keyPath, keyData)
 	if err != nil {
-		return fmt.Errorf("marshaling derived key: %w", err)
-	}
-	if err := os.WriteFile(keyPath, keyData, 0600); err != nil {
-		return fmt.Errorf("writing peer.key: %w", err)
+		return rollback(fmt.Errorf("writing peer.key: %w", err))
 	}
+	restoreKey = restore
 
 	// 4. Create new PeerKeyManager with the derived key
 	peerMgr, err := NewPeerKeyManager(&PeerKeyConfig{
@@ -683,13 +745,13 @@ func (c *SDKClient) Reinitialize(mnemonic string) error {
 		KeyIndex: 0,
 	})
 	if err != nil {
-		return fmt.Errorf("creating peer key manager: %w", err)
+		return rollback(fmt.Errorf("creating peer key manager: %w", err))
 	}
 	c.peerKeyManager = peerMgr
 
 	// 5. Restart the SDK
 	if err := c.initFullSDK(); err != nil {
-		return fmt.Errorf("reinitializing SDK: %w", err)
+		return rollback(fmt.Errorf("reinitializing SDK: %w", err))
 	}
 
 	c.initialized = true
@@ -739,10 +801,11 @@ func (s *sdkAccountService) Account() *accountdata.AccountKeys {
 // sdkConfig implements all config interfaces required by any-sync components
 type sdkConfig struct {
 	clientConfig *ClientConfig
+	tuning       SyncTuning
 }
 
-func newSDKConfig(cc *ClientConfig) *sdkConfig {
-	return &sdkConfig{clientConfig: cc}
+func newSDKConfig(cc *ClientConfig, tuning SyncTuning) *sdkConfig {
+	return &sdkConfig{clientConfig: cc, tuning: tuning.withDefaults()}
 }
 
 func (c *sdkConfig) Init(a *app.App) error { return nil }
@@ -751,8 +814,8 @@ func (c *sdkConfig) Name() string          { return "config" }
 // GetSpace implements config.ConfigGetter for commonspace
 func (c *sdkConfig) GetSpace() config.Config {
 	return config.Config{
-		GCTTL:                60,
-		SyncPeriod:           5,
+		GCTTL:                c.tuning.GCTTLSeconds,
+		SyncPeriod:           c.tuning.SyncPeriodSeconds,
 		KeepTreeDataInMemory: true,
 	}
 }
@@ -760,9 +823,9 @@ func (c *sdkConfig) GetSpace() config.Config {
 // GetStreamConfig implements streampool config
 func (c *sdkConfig) GetStreamConfig() streampool.StreamConfig {
 	return streampool.StreamConfig{
-		SendQueueSize:    100,
-		DialQueueWorkers: 4,
-		DialQueueSize:    100,
+		SendQueueSize:    c.tuning.SendQueueSize,
+		DialQueueWorkers: c.tuning.DialQueueWorkers,
+		DialQueueSize:    c.tuning.DialQueueSize,
 	}
 }
 
@@ -778,17 +841,17 @@ func (c *sdkConfig) GetDrpc() rpc.Config {
 // GetYamux implements yamux config
 func (c *sdkConfig) GetYamux() yamux.Config {
 	return yamux.Config{
-		DialTimeoutSec:     10,
-		WriteTimeoutSec:    10,
-		KeepAlivePeriodSec: 30,
+		DialTimeoutSec:     c.tuning.DialTimeoutSeconds,
+		WriteTimeoutSec:    c.tuning.WriteTimeoutSeconds,
+		KeepAlivePeriodSec: c.tuning.KeepAlivePeriodSeconds,
 	}
 }
 
 // GetQuic implements quic config
 func (c *sdkConfig) GetQuic() quic.Config {
 	return quic.Config{
-		DialTimeoutSec:  10,
-		WriteTimeoutSec: 10,
+		DialTimeoutSec:  c.tuning.DialTimeoutSeconds,
+		WriteTimeoutSec: c.tuning.WriteTimeoutSeconds,
 	}
 }
 
@@ -808,6 +871,9 @@ const spaceResolverCName = "matou.space.resolver"
 type sdkSpaceResolver struct {
 	a     *app.App
 	cache sync.Map // spaceId → commonspace.Space
+	// opening deduplicates concurrent opens of an uncached space, so they
+	// share one NewSpace+Init instead of each creating a Space.
+	opening singleflight.Group
 }
 
 func newSDKSpaceResolver() *sdkSpaceResolver { return &sdkSpaceResolver{} }
@@ -823,10 +889,27 @@ func (r *sdkSpaceResolver) spaceService() commonspace.SpaceService {
 	return r.a.MustComponent(commonspace.CName).(commonspace.SpaceService)
 }
 
+// GetSpace returns the cached space, opening it on first use. Callers that
+// arrive while the space is being opened wait for that open and share its
+// result, including its error; the open runs with the first caller's ctx.
 func (r *sdkSpaceResolver) GetSpace(ctx context.Context, spaceId string) (commonspace.Space, error) {
 	if val, ok := r.cache.Load(spaceId); ok {
 		return val.(commonspace.Space), nil
 	}
+	val, err, _ := r.opening.Do(spaceId, func() (interface{}, error) {
+		// Another open may have finished between the cache check and here
+		if val, ok := r.cache.Load(spaceId); ok {
+			return val, nil
+		}
+		return r.openSpace(ctx, spaceId)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return val.(commonspace.Space), nil
+}
+
+func (r *sdkSpaceResolver) openSpace(ctx context.Context, spaceId string) (commonspace.Space, error) {
 	// Resolve the UnifiedTreeManager from the parent app for space deps
 	utm := r.a.MustComponent("common.object.treemanager").(*UnifiedTreeManager)
 	sp, err := r.spaceService().NewSpace(ctx, spaceId, newSpaceDeps(spaceId, utm))
@@ -952,15 +1035,51 @@ func (n *sdkNodeConf) CHash() chash.CHash {
 	return nil
 }
 
+// ErrSpaceStorageCorrupt is returned when a space's data.db exists on disk
+// but any-store can't open it, e.g. it was truncated or overwritten with
+// something that isn't a database. Restoring the file from a backup or
+// removing it so the space resyncs from peers are the ways out.
+var ErrSpaceStorageCorrupt = errors.New("space database is corrupt")
+
 // sdkStorageProvider implements spacestorage.SpaceStorageProvider
 type sdkStorageProvider struct {
 	rootPath string
-	spaces   sync.Map
+	mu       sync.Mutex
+	spaces   map[string]*sdkSpaceEntry // space ID -> entry
+}
+
+// sdkSpaceEntry serialises opening and closing one space's database, so a
+// slow open of one space doesn't hold up access to the others.
+type sdkSpaceEntry struct {
+	mu   sync.Mutex
+	open *sdkOpenSpace // nil until opened, and again once closed
+}
+
+// sdkOpenSpace is an open space database and the number of handles to it
+// still in use. Each space app closes the handle it was given when the space
+// closes; the database is closed when the last one is.
+type sdkOpenSpace struct {
+	storage spacestorage.SpaceStorage
+	refs    int
+}
+
+// sdkSpaceHandle is one acquisition of an open space database.
+type sdkSpaceHandle struct {
+	spacestorage.SpaceStorage
+	release func()
+	once    sync.Once
+}
+
+// Close releases the handle. The database itself stays open while other
+// handles to it are in use.
+func (h *sdkSpaceHandle) Close(ctx context.Context) error {
+	h.once.Do(h.release)
+	return nil
 }
 
 func newSDKStorageProvider(rootPath string) *sdkStorageProvider {
 	os.MkdirAll(rootPath, 0755)
-	return &sdkStorageProvider{rootPath: rootPath}
+	return &sdkStorageProvider{rootPath: rootPath, spaces: make(map[string]*sdkSpaceEntry)}
 }
 
 func (p *sdkStorageProvider) Init(a *app.App) error           { return nil }
@@ -968,20 +1087,68 @@ func (p *sdkStorageProvider) Name() string                    { return spacestor
 func (p *sdkStorageProvider) Run(ctx context.Context) error   { return nil }
 func (p *sdkStorageProvider) Close(ctx context.Context) error { return nil }
 
+// entry returns the lock entry for a space, creating it on first use.
+func (p *sdkStorageProvider) entry(id string) *sdkSpaceEntry {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e, ok := p.spaces[id]
+	if !ok {
+		e = &sdkSpaceEntry{}
+		p.spaces[id] = e
+	}
+	return e
+}
+
 func (p *sdkStorageProvider) WaitSpaceStorage(ctx context.Context, id string) (spacestorage.SpaceStorage, error) {
-	if s, ok := p.spaces.Load(id); ok {
-		return s.(spacestorage.SpaceStorage), nil
+	e := p.entry(id)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if open := e.open; open != nil {
+		if _, err := open.storage.AnyStore().Stats(ctx); err == nil {
+			return p.acquire(e, open), nil
+		}
+		// The handle was closed or broke underneath us; stop handing it out
+		// and reopen once from disk rather than failing every access until a
+		// restart. Holders of the old handle close it when they're done.
+		log.Printf("[Storage] Database handle for space %s is stale, reopening", id)
+		e.open = nil
+	}
+
+	return p.openSpaceStorage(ctx, e, id)
+}
+
+// acquire hands out a new handle to an open database. Callers must hold e.mu.
+func (p *sdkStorageProvider) acquire(e *sdkSpaceEntry, open *sdkOpenSpace) spacestorage.SpaceStorage {
+	open.refs++
+	return &sdkSpaceHandle{
+		SpaceStorage: open.storage,
+		release: func() {
+			e.mu.Lock()
+			defer e.mu.Unlock()
+			open.refs--
+			if open.refs > 0 {
+				return
+			}
+			if e.open == open {
+				e.open = nil
+			}
+			open.storage.AnyStore().Close()
+		},
 	}
+}
 
-	// Try to reopen an existing space database from disk.
-	dbPath := filepath.Join(p.rootPath, id, "data.db")
+// openSpaceStorage opens an existing space database from disk. Callers must
+// hold e.mu.
+func (p *sdkStorageProvider) openSpaceStorage(ctx context.Context, e *sdkSpaceEntry, id string) (spacestorage.SpaceStorage, error) {
+	dbPath := filepath.Join(p.rootPath, id, spaceDBFile)
 	if _, err := os.Stat(dbPath); err != nil {
 		return nil, spacestorage.ErrSpaceStorageMissing
 	}
 
 	store, err := anystore.Open(ctx, dbPath, nil)
 	if err != nil {
-		return nil, fmt.Errorf("reopening space database %s: %w", id, err)
+		return nil, fmt.Errorf("%w: space %s: %w", ErrSpaceStorageCorrupt, id, err)
 	}
 
 	storage, err := spacestorage.New(ctx, id, store)
@@ -990,8 +1157,8 @@ func (p *sdkStorageProvider) WaitSpaceStorage(ctx context.Context, id string) (s
 		return nil, fmt.Errorf("loading space storage %s: %w", id, err)
 	}
 
-	p.spaces.Store(id, storage)
-	return storage, nil
+	e.open = &sdkOpenSpace{storage: storage}
+	return p.acquire(e, e.open), nil
 }
 
 func (p *sdkStorageProvider) SpaceStorage(id string) (spacestorage.SpaceStorage, error) {
@@ -1001,7 +1168,11 @@ func (p *sdkStorageProvider) SpaceStorage(id string) (spacestorage.SpaceStorage,
 func (p *sdkStorageProvider) CreateSpaceStorage(ctx context.Context, payload spacestorage.SpaceStorageCreatePayload) (spacestorage.SpaceStorage, error) {
 	spaceId := payload.SpaceHeaderWithId.Id
 
-	if _, ok := p.spaces.Load(spaceId); ok {
+	e := p.entry(spaceId)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.open != nil {
 		return nil, spacestorage.ErrSpaceStorageExists
 	}
 
@@ -1010,7 +1181,7 @@ func (p *sdkStorageProvider) CreateSpaceStorage(ctx context.Context, payload spa
 		return nil, fmt.Errorf("creating space directory: %w", err)
 	}
 
-	dbPath := filepath.Join(spacePath, "data.db")
+	dbPath := filepath.Join(spacePath, spaceDBFile)
 	store, err := anystore.Open(ctx, dbPath, nil)
 	if err != nil {
 		return nil, fmt.Errorf("creating anystore database: %w", err)
@@ -1022,15 +1193,12 @@ func (p *sdkStorageProvider) CreateSpaceStorage(ctx context.Context, payload spa
 		return nil, fmt.Errorf("creating space storage: %w", err)
 	}
 
-	p.spaces.Store(spaceId, storage)
-	return storage, nil
+	e.open = &sdkOpenSpace{storage: storage}
+	return p.acquire(e, e.open), nil
 }
 
 func (p *sdkStorageProvider) SpaceExists(id string) bool {
-	if _, ok := p.spaces.Load(id); ok {
-		return true
-	}
-	dbPath := filepath.Join(p.rootPath, id, "data.db")
+	dbPath := filepath.Join(p.rootPath, id, spaceDBFile)
 	_, err := os.Stat(dbPath)
 	return err == nil
 }
@@ -0,0 +1,317 @@
+package anysync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/anyproto/any-sync/app"
+	"github.com/anyproto/any-sync/commonspace"
+	"github.com/anyproto/any-sync/commonspace/mock_commonspace"
+	"github.com/anyproto/any-sync/util/crypto"
+	"go.uber.org/mock/gomock"
+)
+
+// countingSpaceService counts NewSpace calls, holding each open long enough
+// for concurrent callers to overlap it.
+type countingSpaceService struct {
+	commonspace.SpaceService
+	space *mock_commonspace.MockSpace
+	opens atomic.Int32
+}
+
+func (s *countingSpaceService) Init(a *app.App) error { return nil }
+func (s *countingSpaceService) Name() string          { return commonspace.CName }
+
+func (s *countingSpaceService) NewSpace(ctx context.Context, id string, deps commonspace.Deps) (commonspace.Space, error) {
+	s.opens.Add(1)
+	time.Sleep(50 * time.Millisecond)
+	return s.space, nil
+}
+
+func TestSpaceResolver_DeduplicatesConcurrentOpens(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	space := mock_commonspace.NewMockSpace(ctrl)
+	space.EXPECT().Init(gomock.Any()).Return(nil).Times(1)
+
+	spaces := &countingSpaceService{space: space}
+	a := new(app.App)
+	a.Register(spaces).Register(NewUnifiedTreeManager())
+	resolver := newSDKSpaceResolver()
+	resolver.Init(a)
+
+	const callers = 20
+	var wg sync.WaitGroup
+	results := make([]commonspace.Space, callers)
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = resolver.GetSpace(context.Background(), "space-1")
+		}(i)
+	}
+	wg.Wait()
+
+	if n := spaces.opens.Load(); n != 1 {
+		t.Errorf("expected NewSpace to be called once, got %d", n)
+	}
+	for i := 0; i < callers; i++ {
+		if errs[i] != nil {
+			t.Fatalf("caller %d: %v", i, errs[i])
+		}
+		if results[i] != space {
+			t.Errorf("caller %d got a different space instance", i)
+		}
+	}
+
+	// Later calls are served from the cache
+	if sp, err := resolver.GetSpace(context.Background(), "space-1"); err != nil || sp != space {
+		t.Errorf("cached GetSpace = %v, %v", sp, err)
+	}
+	if n := spaces.opens.Load(); n != 1 {
+		t.Errorf("expected the cached space to be reused, got %d opens", n)
+	}
+}
+
+const (
+	testMnemonicA = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	testMnemonicB = "legal winner thank year wave sausage worth useful legal winner thank yellow"
+)
+
+// offlineClientConfig writes a client config whose coordinator is never
+// dialled, enough for an SDKClient to start without a network.
+func offlineClientConfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "client.yml")
+	config := "id: test\nnetworkId: N-test\nnodes:\n  - peerId: 12D3KooWCoordinator\n    addresses: [\"127.0.0.1:1\"]\n    types: [coordinator]\n"
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatalf("writing client config: %v", err)
+	}
+	return path
+}
+
+func newOfflineSDKClient(t *testing.T, configPath string, opts *ClientOptions) *SDKClient {
+	t.Helper()
+	client, err := NewSDKClient(configPath, opts)
+	if err != nil {
+		t.Fatalf("creating SDK client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// peerIDAt returns the peer ID of the key stored at path.
+func peerIDAt(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+	key, err := crypto.UnmarshalEd25519PrivateKeyProto(data)
+	if err != nil {
+		t.Fatalf("parsing %s: %v", path, err)
+	}
+	return key.GetPublic().PeerId()
+}
+
+func TestSDKClient_SeparateDataDirsDontCollide(t *testing.T) {
+	configPath := offlineClientConfig(t)
+	dirA, dirB := t.TempDir(), t.TempDir()
+	a := newOfflineSDKClient(t, configPath, &ClientOptions{DataDir: dirA})
+	b := newOfflineSDKClient(t, configPath, &ClientOptions{DataDir: dirB})
+
+	if err := a.Reinitialize(testMnemonicA); err != nil {
+		t.Fatalf("reinitializing A: %v", err)
+	}
+	if err := b.Reinitialize(testMnemonicB); err != nil {
+		t.Fatalf("reinitializing B: %v", err)
+	}
+	if a.GetPeerID() == b.GetPeerID() {
+		t.Fatal("expected distinct peer IDs")
+	}
+	if got := peerIDAt(t, filepath.Join(dirA, "peer.key")); got != a.GetPeerID() {
+		t.Errorf("A's peer.key belongs to %s, want %s", got, a.GetPeerID())
+	}
+	if got := peerIDAt(t, filepath.Join(dirB, "peer.key")); got != b.GetPeerID() {
+		t.Errorf("B's peer.key belongs to %s, want %s", got, b.GetPeerID())
+	}
+
+	keys, err := GenerateSpaceKeySet()
+	if err != nil {
+		t.Fatalf("generating keys: %v", err)
+	}
+	if err := PersistSpaceKeySet(a.GetDataDir(), "space-a", keys); err != nil {
+		t.Fatalf("persisting keys: %v", err)
+	}
+	if _, err := LoadSpaceKeySet(b.GetDataDir(), "space-a"); err == nil {
+		t.Error("expected A's space keys to be invisible to B")
+	}
+	if SpacesStorageDir(a.GetDataDir()) == SpacesStorageDir(b.GetDataDir()) {
+		t.Error("expected separate space storage")
+	}
+}
+
+func TestSDKClient_PerIdentityDirs(t *testing.T) {
+	configPath := offlineClientConfig(t)
+	base := t.TempDir()
+	client := newOfflineSDKClient(t, configPath, &ClientOptions{DataDir: base, PerIdentityDirs: true})
+	deviceDir := client.GetDataDir()
+	if deviceDir != IdentityDataDir(base, client.GetPeerID()) {
+		t.Errorf("data dir = %s, want the device identity's directory", deviceDir)
+	}
+
+	if err := client.Reinitialize(testMnemonicA); err != nil {
+		t.Fatalf("reinitializing as A: %v", err)
+	}
+	peerA, dirA := client.GetPeerID(), client.GetDataDir()
+	keys, err := GenerateSpaceKeySet()
+	if err != nil {
+		t.Fatalf("generating keys: %v", err)
+	}
+	if err := PersistSpaceKeySet(dirA, "space-a", keys); err != nil {
+		t.Fatalf("persisting keys: %v", err)
+	}
+
+	if err := client.Reinitialize(testMnemonicB); err != nil {
+		t.Fatalf("reinitializing as B: %v", err)
+	}
+	peerB, dirB := client.GetPeerID(), client.GetDataDir()
+	if dirA != IdentityDataDir(base, peerA) || dirB != IdentityDataDir(base, peerB) || dirA == dirB {
+		t.Fatalf("unexpected identity dirs %s and %s", dirA, dirB)
+	}
+	if got := peerIDAt(t, filepath.Join(dirA, "peer.key")); got != peerA {
+		t.Errorf("A's peer.key was overwritten by %s", got)
+	}
+	if got := peerIDAt(t, filepath.Join(dirB, "peer.key")); got != peerB {
+		t.Errorf("B's peer.key belongs to %s", got)
+	}
+	if _, err := LoadSpaceKeySet(dirB, "space-a"); err == nil {
+		t.Error("expected A's space keys to be invisible to B")
+	}
+	if _, err := os.Stat(filepath.Join(base, "peer.key")); err != nil {
+		t.Errorf("expected the device key to stay at the base dir: %v", err)
+	}
+	if peerIDAt(t, filepath.Join(base, "peer.key")) == peerA {
+		t.Error("expected Reinitialize not to write over the device key")
+	}
+
+	// Switching back finds A's files where they were
+	if err := client.Reinitialize(testMnemonicA); err != nil {
+		t.Fatalf("reinitializing as A again: %v", err)
+	}
+	if _, err := LoadSpaceKeySet(client.GetDataDir(), "space-a"); err != nil {
+		t.Errorf("expected A's space keys after switching back: %v", err)
+	}
+}
+
+func TestSDKClient_ReinitializeKeepsReplacedPeerKey(t *testing.T) {
+	configPath := offlineClientConfig(t)
+	dir := t.TempDir()
+	client := newOfflineSDKClient(t, configPath, &ClientOptions{DataDir: dir})
+
+	if err := client.Reinitialize(testMnemonicA); err != nil {
+		t.Fatalf("reinitializing as A: %v", err)
+	}
+	peerA := client.GetPeerID()
+	if err := client.Reinitialize(testMnemonicB); err != nil {
+		t.Fatalf("reinitializing as B: %v", err)
+	}
+
+	if got := peerIDAt(t, filepath.Join(dir, "peer.key")); got != client.GetPeerID() {
+		t.Errorf("peer.key belongs to %s, want %s", got, client.GetPeerID())
+	}
+	if got := peerIDAt(t, filepath.Join(dir, "peer-"+peerA+".key")); got != peerA {
+		t.Errorf("expected A's replaced key to be kept, got %s", got)
+	}
+}
+
+func TestSDKClient_ReinitializeRestartsAfterEarlyFailure(t *testing.T) {
+	configPath := offlineClientConfig(t)
+	base := t.TempDir()
+	client := newOfflineSDKClient(t, configPath, &ClientOptions{DataDir: base, PerIdentityDirs: true})
+	devicePeer, deviceDir := client.GetPeerID(), client.GetDataDir()
+
+	// A file where A's identity directory would go fails the step right
+	// after the shutdown
+	privKey, err := DeriveKeyFromMnemonic(testMnemonicA, 0)
+	if err != nil {
+		t.Fatalf("deriving key: %v", err)
+	}
+	dirA := IdentityDataDir(base, privKey.GetPublic().PeerId())
+	if err := os.MkdirAll(filepath.Dir(dirA), 0755); err != nil {
+		t.Fatalf("creating identities dir: %v", err)
+	}
+	if err := os.WriteFile(dirA, []byte("not a directory"), 0600); err != nil {
+		t.Fatalf("blocking identity dir: %v", err)
+	}
+
+	if err := client.Reinitialize(testMnemonicA); err == nil {
+		t.Fatal("expected reinitializing to fail")
+	}
+	if !client.initialized || client.GetPeerID() != devicePeer || client.GetDataDir() != deviceDir {
+		t.Errorf("expected the device identity back up, got peer %s in %s (initialized %v)", client.GetPeerID(), client.GetDataDir(), client.initialized)
+	}
+}
+
+func TestSDKClient_ReinitializeKeepsFirstBackup(t *testing.T) {
+	configPath := offlineClientConfig(t)
+	dir := t.TempDir()
+	client := newOfflineSDKClient(t, configPath, &ClientOptions{DataDir: dir})
+	devicePeer := client.GetPeerID()
+
+	for _, mnemonic := range []string{testMnemonicA, testMnemonicB, testMnemonicA} {
+		if err := client.Reinitialize(mnemonic); err != nil {
+			t.Fatalf("reinitializing: %v", err)
+		}
+	}
+	if got := peerIDAt(t, filepath.Join(dir, "peer.key"+peerKeyBackupSuffix)); got != devicePeer {
+		t.Errorf("peer.key.bak belongs to %s, want the original key %s", got, devicePeer)
+	}
+}
+
+func TestSDKConfig_Tuning(t *testing.T) {
+	cfg := newSDKConfig(nil, SyncTuning{
+		GCTTLSeconds:           120,
+		SyncPeriodSeconds:      30,
+		SendQueueSize:          500,
+		DialQueueWorkers:       2,
+		DialQueueSize:          50,
+		DialTimeoutSeconds:     45,
+		WriteTimeoutSeconds:    20,
+		KeepAlivePeriodSeconds: 90,
+	})
+
+	if space := cfg.GetSpace(); space.GCTTL != 120 || space.SyncPeriod != 30 {
+		t.Errorf("GetSpace() = GCTTL %d, SyncPeriod %d; want 120, 30", space.GCTTL, space.SyncPeriod)
+	}
+	if stream := cfg.GetStreamConfig(); stream.SendQueueSize != 500 || stream.DialQueueWorkers != 2 || stream.DialQueueSize != 50 {
+		t.Errorf("GetStreamConfig() = %+v", stream)
+	}
+	if ym := cfg.GetYamux(); ym.DialTimeoutSec != 45 || ym.WriteTimeoutSec != 20 || ym.KeepAlivePeriodSec != 90 {
+		t.Errorf("GetYamux() = %+v", ym)
+	}
+	if q := cfg.GetQuic(); q.DialTimeoutSec != 45 || q.WriteTimeoutSec != 20 {
+		t.Errorf("GetQuic() = %+v", q)
+	}
+}
+
+func TestSDKConfig_TuningDefaults(t *testing.T) {
+	// Only the sync period is set; everything else keeps its default
+	cfg := newSDKConfig(nil, SyncTuning{SyncPeriodSeconds: 15})
+	d := DefaultSyncTuning()
+
+	if space := cfg.GetSpace(); space.GCTTL != d.GCTTLSeconds || space.SyncPeriod != 15 {
+		t.Errorf("GetSpace() = GCTTL %d, SyncPeriod %d; want %d, 15", space.GCTTL, space.SyncPeriod, d.GCTTLSeconds)
+	}
+	if stream := cfg.GetStreamConfig(); stream.SendQueueSize != d.SendQueueSize || stream.DialQueueWorkers != d.DialQueueWorkers || stream.DialQueueSize != d.DialQueueSize {
+		t.Errorf("GetStreamConfig() = %+v, want the defaults", stream)
+	}
+	if ym := cfg.GetYamux(); ym.DialTimeoutSec != d.DialTimeoutSeconds || ym.WriteTimeoutSec != d.WriteTimeoutSeconds || ym.KeepAlivePeriodSec != d.KeepAlivePeriodSeconds {
+		t.Errorf("GetYamux() = %+v, want the defaults", ym)
+	}
+}
@@ -0,0 +1,132 @@
+package anysync
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/anyproto/any-sync/commonspace/spacepayloads"
+	"github.com/anyproto/any-sync/commonspace/spacestorage"
+)
+
+// createTestSpaceStorage creates a real space database through the provider.
+func createTestSpaceStorage(t *testing.T, p *sdkStorageProvider) string {
+	t.Helper()
+	keys, err := GenerateSpaceKeySet()
+	if err != nil {
+		t.Fatalf("generating keys: %v", err)
+	}
+	payload, err := spacepayloads.StoragePayloadForSpaceCreate(spacepayloads.SpaceCreatePayload{
+		SigningKey:   keys.SigningKey,
+		MasterKey:    keys.MasterKey,
+		SpacePayload: []byte("test-owner"),
+		ReadKey:      keys.ReadKey,
+		MetadataKey:  keys.MetadataKey,
+		Metadata:     []byte("{}"),
+	})
+	if err != nil {
+		t.Fatalf("building space payload: %v", err)
+	}
+	storage, err := p.CreateSpaceStorage(context.Background(), payload)
+	if err != nil {
+		t.Fatalf("creating space storage: %v", err)
+	}
+	// As any-sync's CreateSpace does, release the handle once created
+	storage.Close(context.Background())
+	return payload.SpaceHeaderWithId.Id
+}
+
+func TestSDKStorageProvider_ReopensClosedHandle(t *testing.T) {
+	ctx := context.Background()
+	p := newSDKStorageProvider(t.TempDir())
+	spaceID := createTestSpaceStorage(t, p)
+
+	stale, err := p.WaitSpaceStorage(ctx, spaceID)
+	if err != nil {
+		t.Fatalf("WaitSpaceStorage: %v", err)
+	}
+	if err := stale.AnyStore().Close(); err != nil {
+		t.Fatalf("closing database: %v", err)
+	}
+
+	storage, err := p.WaitSpaceStorage(ctx, spaceID)
+	if err != nil {
+		t.Fatalf("expected the closed handle to be reopened, got %v", err)
+	}
+	if storage.AnyStore() == stale.AnyStore() {
+		t.Fatal("expected a fresh database handle after reopening")
+	}
+	if _, err := storage.AnyStore().Stats(ctx); err != nil {
+		t.Errorf("reopened database unusable: %v", err)
+	}
+	if storage.Id() != spaceID {
+		t.Errorf("reopened storage id = %s, want %s", storage.Id(), spaceID)
+	}
+
+	// A healthy database is shared rather than reopened
+	again, err := p.WaitSpaceStorage(ctx, spaceID)
+	if err != nil || again.AnyStore() != storage.AnyStore() {
+		t.Errorf("expected the open database, got %v (err %v)", again, err)
+	}
+}
+
+func TestSDKStorageProvider_ClosesAtLastRelease(t *testing.T) {
+	ctx := context.Background()
+	p := newSDKStorageProvider(t.TempDir())
+	spaceID := createTestSpaceStorage(t, p)
+
+	first, err := p.WaitSpaceStorage(ctx, spaceID)
+	if err != nil {
+		t.Fatalf("WaitSpaceStorage: %v", err)
+	}
+	second, err := p.WaitSpaceStorage(ctx, spaceID)
+	if err != nil {
+		t.Fatalf("WaitSpaceStorage: %v", err)
+	}
+	db := first.AnyStore()
+
+	// Releasing one handle twice counts once
+	first.Close(ctx)
+	first.Close(ctx)
+	if _, err := db.Stats(ctx); err != nil {
+		t.Fatalf("expected the database to stay open while handles remain, got %v", err)
+	}
+
+	second.Close(ctx)
+	if _, err := db.Stats(ctx); err == nil {
+		t.Fatal("expected the database to close with its last handle")
+	}
+
+	reopened, err := p.WaitSpaceStorage(ctx, spaceID)
+	if err != nil {
+		t.Fatalf("expected the space to reopen after closing, got %v", err)
+	}
+	defer reopened.Close(ctx)
+	if _, err := reopened.AnyStore().Stats(ctx); err != nil {
+		t.Errorf("reopened database unusable: %v", err)
+	}
+}
+
+func TestSDKStorageProvider_CorruptDatabase(t *testing.T) {
+	root := t.TempDir()
+	p := newSDKStorageProvider(root)
+
+	spaceID := "corrupt-space"
+	if err := os.MkdirAll(filepath.Join(root, spaceID), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, spaceID, spaceDBFile), []byte("definitely not sqlite, just some bytes padded out"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := p.WaitSpaceStorage(context.Background(), spaceID)
+	if !errors.Is(err, ErrSpaceStorageCorrupt) {
+		t.Fatalf("expected ErrSpaceStorageCorrupt, got %v", err)
+	}
+
+	if _, err := p.WaitSpaceStorage(context.Background(), "no-such-space"); !errors.Is(err, spacestorage.ErrSpaceStorageMissing) {
+		t.Errorf("expected ErrSpaceStorageMissing, got %v", err)
+	}
+}
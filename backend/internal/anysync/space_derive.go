@@ -0,0 +1,218 @@
+package anysync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/anyproto/any-sync/commonspace/object/acl/list"
+	"github.com/anyproto/any-sync/commonspace/object/acl/recordverifier"
+	"github.com/anyproto/any-sync/commonspace/object/tree/objecttree"
+	"github.com/anyproto/any-sync/commonspace/spacepayloads"
+	"github.com/anyproto/any-sync/commonspace/spacestorage"
+	"github.com/anyproto/any-sync/commonspace/spacesyncproto"
+	"github.com/anyproto/any-sync/consensus/consensusclient"
+	"github.com/anyproto/any-sync/consensus/consensusproto/consensuserr"
+	"github.com/anyproto/any-sync/util/cidutil"
+	"github.com/anyproto/any-sync/util/crypto"
+)
+
+// derivedSpaceRecoverTimeout bounds how long DeriveSpaceWithKeys waits for the
+// network to send, or report missing, a derived space that isn't stored
+// locally.
+const derivedSpaceRecoverTimeout = 5 * time.Second
+
+// derivedSpaceHeader returns the signed header of the space derived for
+// ownerAID and spaceType from signingKey, and the space ID it hashes to.
+// Unlike a created space's header it carries no random seed or timestamp, so
+// the same keys always give the same ID. The space type goes in the header
+// payload, since the coordinator rejects custom header space types and spaces
+// of several types share the owner's signing key.
+func derivedSpaceHeader(ownerAID, spaceType string, signingKey crypto.PrivKey) (*spacesyncproto.RawSpaceHeaderWithId, error) {
+	identity, err := signingKey.GetPublic().Marshall()
+	if err != nil {
+		return nil, fmt.Errorf("marshalling identity: %w", err)
+	}
+	repKey, err := ComputeReplicationKey(signingKey)
+	if err != nil {
+		return nil, fmt.Errorf("computing replication key: %w", err)
+	}
+
+	header := &spacesyncproto.SpaceHeader{
+		Identity:           identity,
+		SpaceType:          "", // coordinator rejects custom types; keep app-level type in the payload
+		SpaceHeaderPayload: []byte(ownerAID + "/" + spaceType),
+		ReplicationKey:     repKey,
+	}
+	marshalled, err := header.MarshalVT()
+	if err != nil {
+		return nil, fmt.Errorf("marshalling space header: %w", err)
+	}
+	signature, err := signingKey.Sign(marshalled)
+	if err != nil {
+		return nil, fmt.Errorf("signing space header: %w", err)
+	}
+	rawHeader, err := (&spacesyncproto.RawSpaceHeader{SpaceHeader: marshalled, Signature: signature}).MarshalVT()
+	if err != nil {
+		return nil, fmt.Errorf("marshalling raw space header: %w", err)
+	}
+	cid, err := cidutil.NewCidFromBytes(rawHeader)
+	if err != nil {
+		return nil, fmt.Errorf("computing space header CID: %w", err)
+	}
+	return &spacesyncproto.RawSpaceHeaderWithId{
+		RawHeader: rawHeader,
+		Id:        spacepayloads.NewSpaceId(cid, repKey),
+	}, nil
+}
+
+// DeriveSpaceIDForType returns the ID DeriveSpaceWithKeys gives the space of
+// spaceType owned by ownerAID, without creating it.
+func DeriveSpaceIDForType(ownerAID, spaceType string, keys *SpaceKeySet) (string, error) {
+	header, err := derivedSpaceHeader(ownerAID, spaceType, keys.SigningKey)
+	if err != nil {
+		return "", err
+	}
+	return header.Id, nil
+}
+
+// derivedSpaceStoragePayload builds the storage payload for a derived space.
+// The ACL root carries the key set's read and metadata keys, as a created
+// space's does, so the space can be shared through encrypted invites.
+func derivedSpaceStoragePayload(ownerAID, spaceType string, keys *SpaceKeySet) (spacestorage.SpaceStorageCreatePayload, error) {
+	header, err := derivedSpaceHeader(ownerAID, spaceType, keys.SigningKey)
+	if err != nil {
+		return spacestorage.SpaceStorageCreatePayload{}, err
+	}
+
+	metadata := []byte(fmt.Sprintf(`{"owner":"%s","type":"%s"}`, ownerAID, spaceType))
+	aclBuilder := list.NewAclRecordBuilder("", crypto.NewKeyStorage(), nil, recordverifier.NewValidateFull())
+	aclRoot, err := aclBuilder.BuildRoot(list.RootContent{
+		PrivKey:   keys.SigningKey,
+		MasterKey: keys.MasterKey,
+		SpaceId:   header.Id,
+		Change: list.ReadKeyChangePayload{
+			MetadataKey: keys.MetadataKey,
+			ReadKey:     keys.ReadKey,
+		},
+		Metadata: metadata,
+	})
+	if err != nil {
+		return spacestorage.SpaceStorageCreatePayload{}, fmt.Errorf("building ACL root: %w", err)
+	}
+
+	_, settingsRoot, err := objecttree.NewChangeBuilder(crypto.NewKeyStorage(), nil).BuildRoot(objecttree.InitialContent{
+		AclHeadId:  aclRoot.Id,
+		PrivKey:    keys.SigningKey,
+		SpaceId:    header.Id,
+		ChangeType: spacepayloads.SpaceReserved,
+	})
+	if err != nil {
+		return spacestorage.SpaceStorageCreatePayload{}, fmt.Errorf("building space settings: %w", err)
+	}
+
+	payload := spacestorage.SpaceStorageCreatePayload{
+		AclWithId:           aclRoot,
+		SpaceHeaderWithId:   header,
+		SpaceSettingsWithId: settingsRoot,
+	}
+	if err := spacepayloads.ValidateSpaceStorageCreatePayload(payload); err != nil {
+		return spacestorage.SpaceStorageCreatePayload{}, fmt.Errorf("validating derived space: %w", err)
+	}
+	return payload, nil
+}
+
+// DeriveSpaceWithKeys opens or creates the space of spaceType owned by
+// ownerAID whose ID is derived from the key set's signing key, so the same
+// keys always yield the same space. A space already stored locally or
+// available from the network is reused rather than created again, which
+// makes concurrent or repeated calls idempotent. Keys are persisted as with
+// CreateSpaceWithKeys.
+//
+// The ACL and settings roots of a derived space are signed with a timestamp
+// and random nonces, so two nodes deriving it independently would fork it.
+// It is therefore only created once the network has answered that the space
+// is missing and the consensus node has accepted its ACL log; if the network
+// can't be reached, or another node registered the log first, it fails.
+func (c *SDKClient) DeriveSpaceWithKeys(ctx context.Context, ownerAID string, spaceType string, keys *SpaceKeySet) (*SpaceCreateResult, error) {
+	payload, err := derivedSpaceStoragePayload(ownerAID, spaceType, keys)
+	if err != nil {
+		return nil, err
+	}
+	spaceID := payload.SpaceHeaderWithId.Id
+
+	// Probe without holding c.mu: fetching the space from the network can
+	// take up to derivedSpaceRecoverTimeout
+	c.mu.RLock()
+	if !c.initialized {
+		c.mu.RUnlock()
+		return nil, fmt.Errorf("client not initialized")
+	}
+	resolver := c.app.MustComponent(spaceResolverCName).(*sdkSpaceResolver)
+	existed := c.storageProvider.SpaceExists(spaceID)
+	c.mu.RUnlock()
+
+	if !existed {
+		recoverCtx, cancel := context.WithTimeout(ctx, derivedSpaceRecoverTimeout)
+		_, getErr := resolver.GetSpace(recoverCtx, spaceID)
+		cancel()
+		switch {
+		case getErr == nil:
+			existed = true
+		case !errors.Is(getErr, spacesyncproto.ErrSpaceMissing):
+			return nil, fmt.Errorf("checking the network for derived space %s: %w", spaceID, getErr)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.initialized {
+		return nil, fmt.Errorf("client not initialized")
+	}
+	resolver = c.app.MustComponent(spaceResolverCName).(*sdkSpaceResolver)
+
+	if !existed && !c.storageProvider.SpaceExists(spaceID) {
+		// Register the ACL log before storing the space, so a node that
+		// loses the race never keeps its fork
+		consClient := c.app.MustComponent(consensusclient.CName).(consensusclient.Service)
+		switch err := consClient.AddLog(ctx, payload.AclWithId.Id, payload.AclWithId); {
+		case errors.Is(err, consensuserr.ErrLogExists):
+			return nil, fmt.Errorf("derived space %s was registered by another node; retry to fetch it: %w", spaceID, err)
+		case err != nil:
+			return nil, fmt.Errorf("registering derived space ACL with the consensus node: %w", err)
+		}
+
+		store, err := c.storageProvider.CreateSpaceStorage(ctx, payload)
+		switch {
+		case errors.Is(err, spacestorage.ErrSpaceStorageExists):
+			existed = true
+		case err != nil:
+			return nil, fmt.Errorf("creating derived space: %w", err)
+		default:
+			store.Close(ctx)
+		}
+	} else {
+		existed = true
+	}
+
+	if _, err := resolver.GetSpace(ctx, spaceID); err != nil {
+		return nil, fmt.Errorf("opening derived space: %w", err)
+	}
+
+	if err := PersistSpaceKeySet(c.dataDir, spaceID, keys); err != nil {
+		return nil, fmt.Errorf("persisting space keys: %w", err)
+	}
+
+	log.Printf("[any-sync SDK] Derived space with keys: %s (type: %s, existed: %v)", spaceID[:min(20, len(spaceID))]+"...", spaceType, existed)
+
+	return &SpaceCreateResult{
+		SpaceID:   spaceID,
+		CreatedAt: time.Now().UTC(),
+		OwnerAID:  ownerAID,
+		SpaceType: spaceType,
+		Keys:      keys,
+	}, nil
+}
@@ -0,0 +1,67 @@
+package anysync
+
+import (
+	"testing"
+)
+
+func TestDeriveSpaceIDForType_Deterministic(t *testing.T) {
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+	keys1, err := DeriveSpaceKeySet(mnemonic, 1)
+	if err != nil {
+		t.Fatalf("DeriveSpaceKeySet failed: %v", err)
+	}
+	keys2, err := DeriveSpaceKeySet(mnemonic, 1)
+	if err != nil {
+		t.Fatalf("DeriveSpaceKeySet failed: %v", err)
+	}
+
+	id1, err := DeriveSpaceIDForType("EORG123", SpaceTypeCommunity, keys1)
+	if err != nil {
+		t.Fatalf("DeriveSpaceIDForType failed: %v", err)
+	}
+	id2, err := DeriveSpaceIDForType("EORG123", SpaceTypeCommunity, keys2)
+	if err != nil {
+		t.Fatalf("DeriveSpaceIDForType failed: %v", err)
+	}
+	if id1 == "" || id1 != id2 {
+		t.Errorf("expected the same space ID from the same mnemonic, got %q and %q", id1, id2)
+	}
+
+	// The community spaces share the signing key, so their IDs must still differ by type
+	roKeys, err := DeriveSpaceKeySet(mnemonic, 2)
+	if err != nil {
+		t.Fatalf("DeriveSpaceKeySet failed: %v", err)
+	}
+	roKeys.SigningKey = keys1.SigningKey
+	roID, err := DeriveSpaceIDForType("EORG123", SpaceTypeCommunityReadOnly, roKeys)
+	if err != nil {
+		t.Fatalf("DeriveSpaceIDForType failed: %v", err)
+	}
+	if roID == id1 {
+		t.Errorf("expected different space IDs per space type, both got %q", roID)
+	}
+}
+
+func TestDerivedSpaceStoragePayload(t *testing.T) {
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	keys, err := DeriveSpaceKeySet(mnemonic, 3)
+	if err != nil {
+		t.Fatalf("DeriveSpaceKeySet failed: %v", err)
+	}
+
+	payload, err := derivedSpaceStoragePayload("EORG123", SpaceTypeAdmin, keys)
+	if err != nil {
+		t.Fatalf("derivedSpaceStoragePayload failed: %v", err)
+	}
+	id, err := DeriveSpaceIDForType("EORG123", SpaceTypeAdmin, keys)
+	if err != nil {
+		t.Fatalf("DeriveSpaceIDForType failed: %v", err)
+	}
+	if payload.SpaceHeaderWithId.Id != id {
+		t.Errorf("payload space ID %q doesn't match derived ID %q", payload.SpaceHeaderWithId.Id, id)
+	}
+	if payload.AclWithId == nil || payload.SpaceSettingsWithId == nil {
+		t.Error("expected ACL and settings roots in the payload")
+	}
+}
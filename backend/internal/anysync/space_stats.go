@@ -0,0 +1,104 @@
+package anysync
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// spaceDBFile is the any-store database sdkStorageProvider keeps per space.
+const spaceDBFile = "data.db"
+
+// SpacesStorageDir returns the directory sdkStorageProvider keeps space
+// databases in, one subdirectory per space.
+func SpacesStorageDir(dataDir string) string {
+	return filepath.Join(dataDir, "spaces")
+}
+
+// SpaceStats reports a space's on-disk footprint and indexed objects.
+type SpaceStats struct {
+	SpaceID string `json:"spaceId"`
+	// Missing is set when the space has no storage directory on this node,
+	// e.g. it hasn't been joined or synced yet.
+	Missing bool `json:"missing,omitempty"`
+	// DBSizeBytes is the size of data.db; DiskSizeBytes also counts the
+	// database's write-ahead log and any other files in the directory.
+	DBSizeBytes   int64          `json:"dbSizeBytes"`
+	DiskSizeBytes int64          `json:"diskSizeBytes"`
+	LastModified  *time.Time     `json:"lastModified,omitempty"`
+	ObjectCount   int            `json:"objectCount"`
+	ObjectsByType map[string]int `json:"objectsByType"`
+}
+
+// SpaceStats reads the storage directory of spaceID and counts its indexed
+// objects by type. A space without a directory is reported as Missing
+// rather than as an error.
+func (m *SpaceManager) SpaceStats(spaceID string) (*SpaceStats, error) {
+	stats := &SpaceStats{SpaceID: spaceID, ObjectsByType: map[string]int{}}
+
+	for _, entry := range m.treeManager.GetTreesForSpace(spaceID) {
+		stats.ObjectsByType[entry.ObjectType]++
+		stats.ObjectCount++
+	}
+
+	dir := filepath.Join(SpacesStorageDir(m.client.GetDataDir()), spaceID)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		stats.Missing = true
+		return stats, nil
+	}
+
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		stats.DiskSizeBytes += info.Size()
+		if filepath.Base(path) == spaceDBFile {
+			stats.DBSizeBytes = info.Size()
+		}
+		if modTime := info.ModTime(); stats.LastModified == nil || modTime.After(*stats.LastModified) {
+			stats.LastModified = &modTime
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading storage for space %s: %w", spaceID, err)
+	}
+	return stats, nil
+}
+
+// AllSpaceStats returns SpaceStats for every space with a storage directory,
+// plus the configured and indexed spaces that don't have one, sorted by ID.
+func (m *SpaceManager) AllSpaceStats() ([]*SpaceStats, error) {
+	ids := map[string]bool{}
+	entries, err := os.ReadDir(SpacesStorageDir(m.client.GetDataDir()))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("listing space storage: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			ids[entry.Name()] = true
+		}
+	}
+	for _, id := range append(m.treeManager.KnownSpaceIDs(), m.communitySpaceID, m.communityReadOnlySpaceID, m.adminSpaceID) {
+		if id != "" {
+			ids[id] = true
+		}
+	}
+
+	all := make([]*SpaceStats, 0, len(ids))
+	for id := range ids {
+		stats, err := m.SpaceStats(id)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, stats)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].SpaceID < all[j].SpaceID })
+	return all, nil
+}
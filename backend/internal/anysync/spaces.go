@@ -8,6 +8,8 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"log"
+	"sync"
 	"time"
 )
 
@@ -42,6 +44,10 @@ type SpaceManager struct {
 	communityReadOnlySpaceID string
 	adminSpaceID             string
 	orgAID                   string
+
+	// Persisted record of the space IDs (see SetSpaceIDStore)
+	spaceIDStore SpaceIDStore
+	spaceIDMu    sync.Mutex
 }
 
 // SpaceManagerConfig holds configuration for SpaceManager
@@ -137,6 +143,17 @@ func (m *SpaceManager) FileManager() *FileManager {
 	return m.fileManager
 }
 
+// PoolStats returns the client's connection pool counters, or nil when the
+// client has no managed pool (e.g. mock clients in tests).
+func (m *SpaceManager) PoolStats() *PoolStats {
+	sdkClient, ok := m.client.(*SDKClient)
+	if !ok {
+		return nil
+	}
+	stats := sdkClient.PoolStats()
+	return &stats
+}
+
 // RefreshFileManager updates the FileManager's pool and nodeconf references
 // from the current client. Must be called after SDKClient.Reinitialize()
 // because reinit closes the old app (killing the pool) and creates a new one.
@@ -161,6 +178,7 @@ func (m *SpaceManager) GetCommunityReadOnlySpaceID() string {
 // SetCommunityReadOnlySpaceID sets the community read-only space ID.
 func (m *SpaceManager) SetCommunityReadOnlySpaceID(spaceID string) {
 	m.communityReadOnlySpaceID = spaceID
+	m.persistSpaceID(SpaceTypeCommunityReadOnly, spaceID)
 }
 
 // GetAdminSpaceID returns the admin space ID.
@@ -171,6 +189,7 @@ func (m *SpaceManager) GetAdminSpaceID() string {
 // SetAdminSpaceID sets the admin space ID.
 func (m *SpaceManager) SetAdminSpaceID(spaceID string) {
 	m.adminSpaceID = spaceID
+	m.persistSpaceID(SpaceTypeAdmin, spaceID)
 }
 
 // generatePrivateSpaceID generates a deterministic space ID for a user
@@ -279,6 +298,77 @@ func (m *SpaceManager) SetOrgAID(orgAID string) {
 // SetCommunitySpaceID sets the community space ID
 func (m *SpaceManager) SetCommunitySpaceID(spaceID string) {
 	m.communitySpaceID = spaceID
+	m.persistSpaceID(SpaceTypeCommunity, spaceID)
+}
+
+// SetSpaceIDStore records the community, readonly and admin space IDs in
+// store whenever they are set, so RestoreSpaceIDs can bring them back after a
+// restart.
+func (m *SpaceManager) SetSpaceIDStore(store SpaceIDStore) {
+	m.spaceIDStore = store
+}
+
+// SpaceIDs returns the configured community, readonly and admin space IDs
+// keyed by space type. Unset IDs are omitted.
+func (m *SpaceManager) SpaceIDs() map[string]string {
+	ids := make(map[string]string, 3)
+	for spaceType, id := range map[string]string{
+		SpaceTypeCommunity:         m.communitySpaceID,
+		SpaceTypeCommunityReadOnly: m.communityReadOnlySpaceID,
+		SpaceTypeAdmin:             m.adminSpaceID,
+	} {
+		if id != "" {
+			ids[spaceType] = id
+		}
+	}
+	return ids
+}
+
+// RestoreSpaceIDs loads the space IDs persisted in the space ID store. The
+// persisted record is authoritative: it replaces the IDs the manager was
+// configured with, and configured IDs it has no record of are recorded.
+func (m *SpaceManager) RestoreSpaceIDs(ctx context.Context) error {
+	if m.spaceIDStore == nil {
+		return fmt.Errorf("no space ID store configured")
+	}
+	persisted, err := m.spaceIDStore.LoadSpaceIDs(ctx)
+	if err != nil {
+		return fmt.Errorf("loading space IDs: %w", err)
+	}
+
+	targets := map[string]*string{
+		SpaceTypeCommunity:         &m.communitySpaceID,
+		SpaceTypeCommunityReadOnly: &m.communityReadOnlySpaceID,
+		SpaceTypeAdmin:             &m.adminSpaceID,
+	}
+	for spaceType, id := range targets {
+		stored := persisted[spaceType]
+		switch {
+		case stored != "":
+			if *id != "" && *id != stored {
+				log.Printf("[SpaceManager] Using persisted %s space %s over configured %s", spaceType, stored, *id)
+			}
+			*id = stored
+		case *id != "":
+			if err := m.spaceIDStore.SaveSpaceID(ctx, spaceType, *id); err != nil {
+				return fmt.Errorf("recording %s space ID: %w", spaceType, err)
+			}
+		}
+	}
+	return nil
+}
+
+// persistSpaceID records spaceID as the space of spaceType, if a space ID
+// store is configured. An empty spaceID clears the record.
+func (m *SpaceManager) persistSpaceID(spaceType, spaceID string) {
+	if m.spaceIDStore == nil {
+		return
+	}
+	m.spaceIDMu.Lock()
+	defer m.spaceIDMu.Unlock()
+	if err := m.spaceIDStore.SaveSpaceID(context.Background(), spaceType, spaceID); err != nil {
+		log.Printf("[SpaceManager] Failed to persist %s space ID: %v", spaceType, err)
+	}
 }
 
 // Credential represents a credential for routing purposes
@@ -407,3 +497,12 @@ type SpaceStore interface {
 	SaveSpace(ctx context.Context, space *Space) error
 	ListAllSpaces(ctx context.Context) ([]*Space, error)
 }
+
+// SpaceIDStore persists which space is the community, readonly and admin
+// space, keyed by space type.
+// This is implemented by anystore.SpaceStoreAdapter
+type SpaceIDStore interface {
+	LoadSpaceIDs(ctx context.Context) (map[string]string, error)
+	// SaveSpaceID records spaceID for spaceType; an empty spaceID clears it
+	SaveSpaceID(ctx context.Context, spaceType, spaceID string) error
+}
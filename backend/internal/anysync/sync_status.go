@@ -3,23 +3,49 @@
 package anysync
 
 import (
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/anyproto/any-sync/app"
 	"github.com/anyproto/any-sync/commonspace/syncstatus"
 )
 
+// syncHistorySize bounds the samples kept per space: an hour at the
+// default one-minute sampling interval.
+const syncHistorySize = 60
+
+// DefaultSyncStallThreshold is how long sync may make no progress, while
+// there are local changes no peer has acknowledged, before a space counts as
+// stalled.
+const DefaultSyncStallThreshold = 5 * time.Minute
+
+// SyncSample is a point-in-time reading of a space's sync counters.
+type SyncSample struct {
+	At            time.Time `json:"at"`
+	TreesChanged  int       `json:"treesChanged"`
+	TreesPending  int       `json:"treesPending"` // trees with local heads no peer has reported back
+	HeadsReceived int       `json:"headsReceived"`
+	HeadsApplied  int       `json:"headsApplied"`
+}
+
 // matouSyncStatus implements syncstatus.StatusUpdater with actual tracking.
 type matouSyncStatus struct {
 	mu       sync.RWMutex
 	changed  map[string][]string // treeId → latest heads (from local changes)
+	pending  map[string][]string // treeId → local heads no peer has reported having yet
 	received map[string]int      // treeId → receive count
 	applied  map[string]int      // treeId → apply count
+
+	history      []SyncSample // oldest first, at most syncHistorySize
+	lastProgress time.Time    // when sync last progressed between samples (see Record)
+	stalled      bool
 }
 
 func newMatouSyncStatus() *matouSyncStatus {
 	return &matouSyncStatus{
 		changed:  make(map[string][]string),
+		pending:  make(map[string][]string),
 		received: make(map[string]int),
 		applied:  make(map[string]int),
 	}
@@ -32,18 +58,36 @@ func (s *matouSyncStatus) HeadsChange(treeId string, heads []string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.changed[treeId] = heads
+	s.pending[treeId] = heads
 }
 
 func (s *matouSyncStatus) HeadsReceive(senderId, treeId string, heads []string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.received[treeId]++
+	s.acknowledge(treeId, heads)
 }
 
 func (s *matouSyncStatus) ObjectReceive(senderId, treeId string, heads []string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.received[treeId]++
+	s.acknowledge(treeId, heads)
+}
+
+// acknowledge clears treeId's pending local change once a peer reports heads
+// that include all of it. The caller holds s.mu.
+func (s *matouSyncStatus) acknowledge(treeId string, peerHeads []string) {
+	local, ok := s.pending[treeId]
+	if !ok {
+		return
+	}
+	for _, head := range local {
+		if !slices.Contains(peerHeads, head) {
+			return
+		}
+	}
+	delete(s.pending, treeId)
 }
 
 func (s *matouSyncStatus) HeadsApply(senderId, treeId string, heads []string, allAdded bool) {
@@ -65,3 +109,56 @@ func (s *matouSyncStatus) GetStatus() (changed, received, applied int) {
 	}
 	return
 }
+
+// Pending returns how many trees have local changes no peer has
+// acknowledged yet.
+func (s *matouSyncStatus) Pending() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.pending)
+}
+
+// Record takes a sample of the counters at now, adds it to the history and
+// re-evaluates whether sync has stalled: local changes pending
+// acknowledgement and no progress for longer than threshold. Progress is
+// HeadsApplied changing or the pending count falling; changes becoming
+// pending after none were starts the clock afresh. It reports the stalled
+// state and whether this sample changed it.
+func (s *matouSyncStatus) Record(now time.Time, threshold time.Duration) (stalled, transitioned bool) {
+	changed, received, applied := s.GetStatus()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pending := len(s.pending)
+	sample := SyncSample{At: now, TreesChanged: changed, TreesPending: pending, HeadsReceived: received, HeadsApplied: applied}
+	if n := len(s.history); n == 0 {
+		s.lastProgress = now
+	} else if last := s.history[n-1]; last.HeadsApplied != applied || pending < last.TreesPending || last.TreesPending == 0 {
+		s.lastProgress = now
+	}
+	s.history = append(s.history, sample)
+	if len(s.history) > syncHistorySize {
+		s.history = s.history[len(s.history)-syncHistorySize:]
+	}
+
+	stalled = pending > 0 && now.Sub(s.lastProgress) > threshold
+	transitioned = stalled != s.stalled
+	s.stalled = stalled
+	return stalled, transitioned
+}
+
+// History returns the recorded samples, oldest first.
+func (s *matouSyncStatus) History() []SyncSample {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]SyncSample, len(s.history))
+	copy(out, s.history)
+	return out
+}
+
+// Stalled reports whether the last Record found sync stalled.
+func (s *matouSyncStatus) Stalled() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.stalled
+}
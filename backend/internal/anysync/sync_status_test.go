@@ -2,6 +2,7 @@ package anysync
 
 import (
 	"testing"
+	"time"
 
 	"github.com/anyproto/any-sync/commonspace/syncstatus"
 )
@@ -103,3 +104,92 @@ func TestMatouSyncStatus_HeadsChangeOverwrite(t *testing.T) {
 		t.Errorf("expected 1 changed tree (overwritten), got %d", changed)
 	}
 }
+
+func TestMatouSyncStatus_StallDetection(t *testing.T) {
+	ss := newMatouSyncStatus()
+	threshold := time.Minute
+	start := time.Now()
+
+	// A local change the peer hasn't acknowledged, and no heads ever applied
+	ss.HeadsChange("tree-1", []string{"h1"})
+	ss.HeadsReceive("peer-1", "tree-1", []string{"h0"})
+	if stalled, transitioned := ss.Record(start, threshold); stalled || transitioned {
+		t.Fatalf("expected not stalled on the first sample, got stalled=%v transitioned=%v", stalled, transitioned)
+	}
+	if stalled, _ := ss.Record(start.Add(threshold), threshold); stalled {
+		t.Fatal("expected not stalled at the threshold")
+	}
+
+	stalled, transitioned := ss.Record(start.Add(threshold+time.Second), threshold)
+	if !stalled || !transitioned || !ss.Stalled() {
+		t.Fatalf("expected the stalled flag to flip, got stalled=%v transitioned=%v", stalled, transitioned)
+	}
+	if _, transitioned := ss.Record(start.Add(2*threshold), threshold); transitioned {
+		t.Error("expected no transition while still stalled")
+	}
+
+	// An applied head is progress
+	ss.HeadsApply("peer-1", "tree-1", []string{"h2"}, true)
+	stalled, transitioned = ss.Record(start.Add(2*threshold+time.Second), threshold)
+	if stalled || !transitioned || ss.Stalled() {
+		t.Fatalf("expected the stalled flag to clear, got stalled=%v transitioned=%v", stalled, transitioned)
+	}
+
+	if history := ss.History(); len(history) != 5 || history[4].HeadsApplied != 1 {
+		t.Errorf("expected 5 samples ending with 1 applied, got %+v", history)
+	}
+}
+
+func TestMatouSyncStatus_NoStallWithoutLocalChanges(t *testing.T) {
+	ss := newMatouSyncStatus()
+	start := time.Now()
+	ss.Record(start, time.Minute)
+	if stalled, _ := ss.Record(start.Add(time.Hour), time.Minute); stalled {
+		t.Error("expected an idle space with nothing to sync not to be stalled")
+	}
+}
+
+func TestMatouSyncStatus_NoStallOnceAcknowledged(t *testing.T) {
+	ss := newMatouSyncStatus()
+	start := time.Now()
+	ss.HeadsChange("tree-1", []string{"h1"})
+	ss.Record(start, time.Minute)
+	ss.HeadsReceive("peer-1", "tree-1", []string{"h0", "h1"})
+	if ss.Pending() != 0 {
+		t.Fatalf("expected the change to be acknowledged, got %d pending", ss.Pending())
+	}
+	if stalled, _ := ss.Record(start.Add(time.Hour), time.Minute); stalled {
+		t.Error("expected a space whose changes the peer holds not to be stalled")
+	}
+}
+
+func TestMatouSyncStatus_NoStallOnFreshEditAfterQuiet(t *testing.T) {
+	ss := newMatouSyncStatus()
+	start := time.Now()
+	ss.Record(start, time.Minute)
+	ss.Record(start.Add(time.Hour), time.Minute)
+
+	// The quiet hour isn't counted against the new change
+	ss.HeadsChange("tree-1", []string{"h1"})
+	if stalled, _ := ss.Record(start.Add(time.Hour+2*time.Minute), time.Minute); stalled {
+		t.Fatal("expected a fresh local edit not to stall straight away")
+	}
+	if stalled, _ := ss.Record(start.Add(time.Hour+4*time.Minute), time.Minute); !stalled {
+		t.Error("expected the edit to stall once unacknowledged past the threshold")
+	}
+}
+
+func TestMatouSyncStatus_HistoryBounded(t *testing.T) {
+	ss := newMatouSyncStatus()
+	start := time.Now()
+	for i := 0; i < syncHistorySize+10; i++ {
+		ss.Record(start.Add(time.Duration(i)*time.Minute), time.Hour)
+	}
+	history := ss.History()
+	if len(history) != syncHistorySize {
+		t.Fatalf("expected %d samples, got %d", syncHistorySize, len(history))
+	}
+	if !history[0].At.Equal(start.Add(10 * time.Minute)) {
+		t.Errorf("expected the oldest samples to be dropped, first is %v", history[0].At)
+	}
+}
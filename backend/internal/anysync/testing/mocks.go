@@ -318,6 +318,12 @@ func (m *MockAnySyncClient) CreateSpaceWithKeys(ctx context.Context, ownerAID st
 	return result, nil
 }
 
+// DeriveSpaceWithKeys implements AnySyncClient.DeriveSpaceWithKeys. Mock
+// space IDs are already deterministic, so it behaves like CreateSpaceWithKeys.
+func (m *MockAnySyncClient) DeriveSpaceWithKeys(ctx context.Context, ownerAID string, spaceType string, keys *anysync.SpaceKeySet) (*anysync.SpaceCreateResult, error) {
+	return m.CreateSpaceWithKeys(ctx, ownerAID, spaceType, keys)
+}
+
 // GetSpace implements AnySyncClient.GetSpace
 func (m *MockAnySyncClient) GetSpace(ctx context.Context, spaceID string) (commonspace.Space, error) {
 	m.mu.RLock()
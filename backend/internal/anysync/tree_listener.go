@@ -105,10 +105,13 @@ func (l *TreeUpdateListener) processChanges(tree objecttree.ObjectTree) error {
 		return nil
 	}
 
-	// Only process chat and contribution system types — profiles/credentials are handled elsewhere
+	// Only process chat, notice interaction and contribution system types —
+	// profiles/credentials are handled elsewhere
 	switch objectType {
 	case "ChatChannel", "ChatMessage", "MessageReaction":
 		// proceed
+	case "NoticeReaction", "NoticeRSVP":
+		// persisted for counting; notice handlers emit their own events
 	case TypeProject, TypeImplementationPlan, TypeContribution, TypeMilestone,
 		TypeProposal, TypeDecisionPlan, TypeGovernanceAction, TypeEndorsement,
 		"proposal_comment", "contribution_comment", "project_comment":
@@ -231,6 +234,7 @@ func (l *TreeUpdateListener) emitSSE(p *ObjectPayload, existed bool) {
 			SentAt     string `json:"sentAt"`
 			EditedAt   string `json:"editedAt,omitempty"`
 			DeletedAt  string `json:"deletedAt,omitempty"`
+			Kind       string `json:"kind,omitempty"`
 		}
 		json.Unmarshal(p.Data, &data)
 
@@ -243,6 +247,7 @@ func (l *TreeUpdateListener) emitSSE(p *ObjectPayload, existed bool) {
 					"senderAid":  data.SenderAID,
 					"senderName": data.SenderName,
 					"content":    data.Content,
+					"kind":       data.Kind,
 					"sentAt":     data.SentAt,
 					"source":     "p2p",
 				},
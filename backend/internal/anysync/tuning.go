@@ -0,0 +1,57 @@
+package anysync
+
+// SyncTuning holds the any-sync sync and network parameters the SDK client
+// hands to the any-sync components. Zero fields use the default.
+type SyncTuning struct {
+	// GCTTLSeconds is how long an unused space stays open before it is
+	// garbage collected
+	GCTTLSeconds int
+	// SyncPeriodSeconds is the interval between head syncs of each space
+	SyncPeriodSeconds int
+	// SendQueueSize is how many messages each peer stream can have queued
+	SendQueueSize int
+	// DialQueueWorkers is how many peers are dialed concurrently
+	DialQueueWorkers int
+	// DialQueueSize is how many dials can wait for a worker
+	DialQueueSize int
+	// DialTimeoutSeconds bounds connecting to a peer
+	DialTimeoutSeconds int
+	// WriteTimeoutSeconds bounds a write to a peer connection
+	WriteTimeoutSeconds int
+	// KeepAlivePeriodSeconds is the interval between yamux keep-alives
+	KeepAlivePeriodSeconds int
+}
+
+// DefaultSyncTuning returns the tuning used when none is configured.
+func DefaultSyncTuning() SyncTuning {
+	return SyncTuning{
+		GCTTLSeconds:           60,
+		SyncPeriodSeconds:      5,
+		SendQueueSize:          100,
+		DialQueueWorkers:       4,
+		DialQueueSize:          100,
+		DialTimeoutSeconds:     10,
+		WriteTimeoutSeconds:    10,
+		KeepAlivePeriodSeconds: 30,
+	}
+}
+
+// withDefaults returns t with its zero fields set to the defaults.
+func (t SyncTuning) withDefaults() SyncTuning {
+	d := DefaultSyncTuning()
+	for _, f := range []struct{ v, def *int }{
+		{&t.GCTTLSeconds, &d.GCTTLSeconds},
+		{&t.SyncPeriodSeconds, &d.SyncPeriodSeconds},
+		{&t.SendQueueSize, &d.SendQueueSize},
+		{&t.DialQueueWorkers, &d.DialQueueWorkers},
+		{&t.DialQueueSize, &d.DialQueueSize},
+		{&t.DialTimeoutSeconds, &d.DialTimeoutSeconds},
+		{&t.WriteTimeoutSeconds, &d.WriteTimeoutSeconds},
+		{&t.KeepAlivePeriodSeconds, &d.KeepAlivePeriodSeconds},
+	} {
+		if *f.v == 0 {
+			*f.v = *f.def
+		}
+	}
+	return t
+}
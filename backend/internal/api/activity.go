@@ -0,0 +1,519 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/matou-dao/backend/internal/anystore"
+	"github.com/matou-dao/backend/internal/anysync"
+	"github.com/matou-dao/backend/internal/contributions"
+	"github.com/matou-dao/backend/internal/identity"
+)
+
+// Activity feed limits. The feed never looks further back than
+// activityLookback, however early ?since= is.
+const (
+	activityLookback        = 30 * 24 * time.Hour
+	defaultActivityPageSize = 50
+	maxActivityPageSize     = 200
+)
+
+// Activity item types.
+const (
+	ActivityMessage = "message" // chat message posted
+	ActivityNotice  = "notice"  // notice published
+	ActivityMember  = "member"  // member joined the community
+	ActivityComment = "comment" // comment posted on a notice
+)
+
+var activityTypes = []string{ActivityMessage, ActivityNotice, ActivityMember, ActivityComment}
+
+// ActivityItem is one entry in the activity feed.
+type ActivityItem struct {
+	Type      string `json:"type"` // ActivityMessage, ActivityNotice, ActivityMember or ActivityComment
+	ID        string `json:"id"`   // message, notice, member AID or comment ID
+	At        string `json:"at"`   // RFC3339
+	ActorAID  string `json:"actorAid,omitempty"`
+	ActorName string `json:"actorName,omitempty"`
+	Title     string `json:"title,omitempty"`   // notice title or channel name
+	Summary   string `json:"summary,omitempty"` // message, notice summary or comment text
+	ChannelID string `json:"channelId,omitempty"`
+	NoticeID  string `json:"noticeId,omitempty"`
+
+	at time.Time
+}
+
+// key orders items that happened at the same time.
+func (a *ActivityItem) key() string {
+	return a.Type + ":" + a.ID
+}
+
+// ActivityHandler serves the community activity feed, merging recent chat
+// messages, published notices, new members and notice comments.
+type ActivityHandler struct {
+	spaceManager *anysync.SpaceManager
+	userIdentity *identity.UserIdentity
+	profiles     *ProfileResolver
+	store        *anystore.LocalStore // local chat index (see SetStore)
+	roleLookup   RoleLookup           // resolves the caller's roles (set in RegisterRoutes)
+	quarantine   *Quarantine          // records objects skipped on read (see SetQuarantine)
+	features     *FeatureGate         // see SetFeatureGate
+}
+
+// NewActivityHandler creates a new activity feed handler.
+func NewActivityHandler(spaceManager *anysync.SpaceManager, userIdentity *identity.UserIdentity) *ActivityHandler {
+	return &ActivityHandler{
+		spaceManager: spaceManager,
+		userIdentity: userIdentity,
+		profiles:     NewProfileResolver(spaceManager),
+	}
+}
+
+// SetProfileResolver shares a profile resolver (and its cache) with other
+// handlers. By default the handler uses its own.
+func (h *ActivityHandler) SetProfileResolver(p *ProfileResolver) {
+	h.profiles = p
+}
+
+// SetStore reads messages from the local chat index, which the tree listener
+// keeps current, instead of scanning the community space's message trees.
+func (h *ActivityHandler) SetStore(store *anystore.LocalStore) {
+	h.store = store
+}
+
+// SetQuarantine records objects skipped on read in q.
+func (h *ActivityHandler) SetQuarantine(q *Quarantine) {
+	h.quarantine = q
+}
+
+// SetFeatureGate leaves the activity of disabled features out of the feed:
+// messages without chat, notices and comments without notices.
+func (h *ActivityHandler) SetFeatureGate(gate *FeatureGate) {
+	h.features = gate
+}
+
+// RegisterRoutes registers the activity feed route. roleLookup resolves the
+// caller's roles for channels limited to AllowedRoles; without one, only open
+// channels' messages are listed.
+func (h *ActivityHandler) RegisterRoutes(mux *http.ServeMux, roleLookup RoleLookup) {
+	h.roleLookup = roleLookup
+	mux.HandleFunc("/api/v1/activity", CORSHandler(h.HandleListActivity))
+}
+
+// HandleListActivity handles GET /api/v1/activity — recent community
+// activity, newest first. Messages in channels limited to roles the caller
+// doesn't hold are left out, as are deleted or expired messages, unpublished
+// notices and comments on them.
+//
+// Query params:
+//   - types: comma-separated item types to include (default all)
+//   - since: RFC3339 time to start from, no earlier than activityLookback ago
+//   - limit: page size (default 50, max 200)
+//   - cursor: nextCursor from the previous page
+func (h *ActivityHandler) HandleListActivity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		return
+	}
+
+	communitySpaceID := h.spaceManager.GetCommunitySpaceID()
+	if communitySpaceID == "" {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "community space not configured",
+		})
+		return
+	}
+
+	limit, ok := parseListLimit(w, r, defaultActivityPageSize, maxActivityPageSize)
+	if !ok {
+		return
+	}
+	types := activityTypes
+	if t := r.URL.Query().Get("types"); t != "" {
+		types = strings.Split(t, ",")
+		for _, typ := range types {
+			if !slices.Contains(activityTypes, typ) {
+				writeJSON(w, http.StatusBadRequest, map[string]string{
+					"error": fmt.Sprintf("unknown activity type %q", typ),
+				})
+				return
+			}
+		}
+	}
+	if h.features != nil {
+		flags := h.features.Flags(r.Context())
+		types = slices.DeleteFunc(slices.Clone(types), func(typ string) bool {
+			return typ == ActivityMessage && !flags.Chat ||
+				(typ == ActivityNotice || typ == ActivityComment) && !flags.Notices
+		})
+	}
+	now := time.Now().UTC()
+	since := now.Add(-activityLookback)
+	if s := r.URL.Query().Get("since"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "since must be an RFC3339 time"})
+			return
+		}
+		if t.After(since) {
+			since = t
+		}
+	}
+	var after *ActivityItem
+	if c := r.URL.Query().Get("cursor"); c != "" {
+		var err error
+		if after, err = decodeActivityCursor(c); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid cursor"})
+			return
+		}
+	}
+
+	ctx := r.Context()
+	if h.store == nil {
+		// Pick up trees that arrived via sync since the index was last built
+		h.spaceManager.TreeManager().BuildSpaceIndex(ctx, communitySpaceID)
+	}
+
+	var items []*ActivityItem
+	if slices.Contains(types, ActivityMessage) {
+		messages, err := h.messageActivity(ctx, communitySpaceID, since, now)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": fmt.Sprintf("failed to read messages: %v", err),
+			})
+			return
+		}
+		items = append(items, messages...)
+	}
+	if slices.Contains(types, ActivityNotice) || slices.Contains(types, ActivityComment) {
+		notices, comments, err := h.noticeActivity(ctx, communitySpaceID)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": fmt.Sprintf("failed to read notices: %v", err),
+			})
+			return
+		}
+		if slices.Contains(types, ActivityNotice) {
+			items = append(items, notices...)
+		}
+		if slices.Contains(types, ActivityComment) {
+			items = append(items, comments...)
+		}
+	}
+	if slices.Contains(types, ActivityMember) {
+		items = append(items, h.memberActivity(ctx)...)
+	}
+
+	items = slices.DeleteFunc(items, func(a *ActivityItem) bool {
+		return a.at.Before(since) || a.at.After(now)
+	})
+	slices.SortFunc(items, compareActivity)
+
+	start := 0
+	if after != nil {
+		start, _ = slices.BinarySearchFunc(items, after, func(item, target *ActivityItem) int {
+			if compareActivity(item, target) <= 0 {
+				return -1
+			}
+			return 1
+		})
+	}
+	page := items[start:]
+	var nextCursor string
+	if len(page) > limit {
+		page = page[:limit]
+		nextCursor = encodeActivityCursor(page[len(page)-1])
+	}
+	h.fillActorNames(ctx, page)
+
+	writeListJSON(w, ListPage{Total: len(items), Limit: limit, NextCursor: nextCursor, HasMore: nextCursor != ""}, map[string]interface{}{
+		"items": page,
+		"count": len(page),
+		"since": since.Format(time.RFC3339),
+	})
+}
+
+// compareActivity orders items newest first, breaking ties by type and ID.
+func compareActivity(a, b *ActivityItem) int {
+	if c := b.at.Compare(a.at); c != 0 {
+		return c
+	}
+	return strings.Compare(b.key(), a.key())
+}
+
+// messageActivity returns the messages sent from since on that the caller can
+// read: not deleted, not past their channel's retention window and not in a
+// channel limited to roles the caller doesn't hold. They are read from the
+// local chat index when there is one, or else the tree.
+func (h *ActivityHandler) messageActivity(ctx context.Context, spaceID string, since, now time.Time) ([]*ActivityItem, error) {
+	var (
+		channels []*anystore.ChatChannel
+		messages []*anystore.ChatMessage
+		err      error
+	)
+	if h.store != nil {
+		if channels, err = h.store.ListChannels(ctx); err == nil {
+			messages, err = h.store.ListMessagesSentSince(ctx, since.Format(time.RFC3339))
+		}
+	}
+	if h.store == nil || err != nil {
+		if channels, messages, err = h.readMessageTrees(ctx, spaceID, since); err != nil {
+			return nil, err
+		}
+	}
+
+	roles := h.callerRoles(ctx)
+	readable := make(map[string]*anystore.ChatChannel)
+	for _, ch := range channels {
+		if holdsChannelRole(ch.AllowedRoles, roles) {
+			readable[ch.ID] = ch
+		}
+	}
+	var items []*ActivityItem
+	for _, msg := range messages {
+		// Messages in unknown channels are skipped too, since their
+		// channel's roles can't be checked
+		channel, ok := readable[msg.ChannelID]
+		if !ok || msg.DeletedAt != "" || messageExpired(msg.SentAt, retentionCutoff(channel.RetentionDays, now)) {
+			continue
+		}
+		sentAt, err := time.Parse(time.RFC3339, msg.SentAt)
+		if err != nil {
+			continue
+		}
+		items = append(items, &ActivityItem{
+			Type:      ActivityMessage,
+			ID:        msg.ID,
+			At:        msg.SentAt,
+			ActorAID:  msg.SenderAID,
+			ActorName: msg.SenderName,
+			Title:     channel.Name,
+			Summary:   msg.Content,
+			ChannelID: msg.ChannelID,
+			at:        sentAt,
+		})
+	}
+	return items, nil
+}
+
+// readMessageTrees reads the channels and the messages sent from since on from
+// the space's trees, for when there is no local chat index.
+func (h *ActivityHandler) readMessageTrees(ctx context.Context, spaceID string, since time.Time) ([]*anystore.ChatChannel, []*anystore.ChatMessage, error) {
+	objMgr := h.spaceManager.ObjectTreeManager()
+	channelObjects, err := objMgr.ReadObjectsByType(ctx, spaceID, "ChatChannel")
+	if err != nil {
+		return nil, nil, err
+	}
+	var channels []*anystore.ChatChannel
+	for _, obj := range deduplicateObjects(channelObjects) {
+		var data ChatChannelData
+		if err := decodeObjectData(h.quarantine, spaceID, obj, &data); err != nil {
+			continue
+		}
+		channels = append(channels, &anystore.ChatChannel{
+			ID:            obj.ID,
+			Name:          data.Name,
+			AllowedRoles:  data.AllowedRoles,
+			RetentionDays: data.RetentionDays,
+		})
+	}
+
+	messageObjects, err := objMgr.ReadObjectsByType(ctx, spaceID, "ChatMessage")
+	if err != nil {
+		return nil, nil, err
+	}
+	sinceStr := since.Format(time.RFC3339)
+	var messages []*anystore.ChatMessage
+	for _, obj := range deduplicateObjects(messageObjects) {
+		var data ChatMessageData
+		if err := decodeObjectData(h.quarantine, spaceID, obj, &data); err != nil || data.SentAt < sinceStr {
+			continue
+		}
+		messages = append(messages, &anystore.ChatMessage{
+			ID:         obj.ID,
+			ChannelID:  data.ChannelID,
+			SenderAID:  data.SenderAID,
+			SenderName: data.SenderName,
+			Content:    data.Content,
+			SentAt:     data.SentAt,
+			DeletedAt:  data.DeletedAt,
+		})
+	}
+	return channels, messages, nil
+}
+
+// callerRoles returns the caller's roles from the role lookup, or none if
+// there is no lookup or it fails.
+func (h *ActivityHandler) callerRoles(ctx context.Context) []contributions.Role {
+	aid := callerAID(ctx, h.userIdentity)
+	if h.roleLookup == nil || aid == "" {
+		return nil
+	}
+	roles, err := h.roleLookup.GetUserRoles(aid)
+	if err != nil {
+		log.Printf("[Activity] Role lookup failed for %s: %v", aid, err)
+		return nil
+	}
+	return roles
+}
+
+// noticeActivity returns the published notices and the comments on them
+// that aren't deleted.
+func (h *ActivityHandler) noticeActivity(ctx context.Context, spaceID string) (notices, comments []*ActivityItem, err error) {
+	noticeMgr := h.spaceManager.NoticeTreeManager()
+	allNotices, err := noticeMgr.ReadNotices(ctx, spaceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	titles := make(map[string]string)
+	for _, n := range allNotices {
+		if n.State != "published" {
+			continue
+		}
+		titles[n.ID] = n.Title
+		at := n.PublishedAt
+		if at == "" {
+			at = n.CreatedAt
+		}
+		t, err := time.Parse(time.RFC3339, at)
+		if err != nil {
+			continue
+		}
+		notices = append(notices, &ActivityItem{
+			Type:      ActivityNotice,
+			ID:        n.ID,
+			At:        at,
+			ActorAID:  n.IssuerID,
+			ActorName: n.IssuerName,
+			Title:     n.Title,
+			Summary:   n.Summary,
+			NoticeID:  n.ID,
+			at:        t,
+		})
+	}
+
+	allComments, err := noticeMgr.ReadAllComments(ctx, spaceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, c := range allComments {
+		title, ok := titles[c.NoticeID]
+		if !ok || c.DeletedAt != "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, c.CreatedAt)
+		if err != nil {
+			continue
+		}
+		comments = append(comments, &ActivityItem{
+			Type:      ActivityComment,
+			ID:        c.ID,
+			At:        c.CreatedAt,
+			ActorAID:  c.UserID,
+			ActorName: c.UserDisplayName,
+			Title:     title,
+			Summary:   c.Text,
+			NoticeID:  c.NoticeID,
+			at:        t,
+		})
+	}
+	return notices, comments, nil
+}
+
+// memberActivity returns the members who joined, from the memberSince of
+// their CommunityProfile in the read-only space. Removed members are left out.
+func (h *ActivityHandler) memberActivity(ctx context.Context) []*ActivityItem {
+	roSpaceID := h.spaceManager.GetCommunityReadOnlySpaceID()
+	if roSpaceID == "" {
+		return nil
+	}
+	objects, err := h.spaceManager.ObjectTreeManager().ReadObjectsByType(ctx, roSpaceID, "CommunityProfile")
+	if err != nil {
+		return nil
+	}
+	var items []*ActivityItem
+	for _, obj := range deduplicateObjects(objects) {
+		var profile struct {
+			UserAID     string `json:"userAID"`
+			MemberSince string `json:"memberSince"`
+			Status      string `json:"status"`
+		}
+		if err := decodeObjectData(h.quarantine, roSpaceID, obj, &profile); err != nil || profile.Status == "removed" {
+			continue
+		}
+		aid := profile.UserAID
+		if aid == "" {
+			aid = strings.TrimPrefix(obj.ID, "CommunityProfile-")
+		}
+		t, err := time.Parse(time.RFC3339, profile.MemberSince)
+		if err != nil || aid == "" {
+			continue
+		}
+		items = append(items, &ActivityItem{
+			Type:     ActivityMember,
+			ID:       aid,
+			At:       profile.MemberSince,
+			ActorAID: aid,
+			at:       t,
+		})
+	}
+	return items
+}
+
+// fillActorNames resolves display names for items that don't carry one.
+func (h *ActivityHandler) fillActorNames(ctx context.Context, items []*ActivityItem) {
+	var aids []string
+	for _, item := range items {
+		if item.ActorName == "" && item.ActorAID != "" && !slices.Contains(aids, item.ActorAID) {
+			aids = append(aids, item.ActorAID)
+		}
+	}
+	if len(aids) == 0 {
+		return
+	}
+	profiles := h.profiles.ResolveMany(ctx, aids)
+	for _, item := range items {
+		if item.ActorName == "" {
+			item.ActorName = profiles[item.ActorAID].DisplayName
+		}
+	}
+}
+
+type activityCursor struct {
+	At  string `json:"a"`
+	Key string `json:"k"`
+}
+
+// encodeActivityCursor returns an opaque cursor pointing just after item.
+func encodeActivityCursor(item *ActivityItem) string {
+	data, _ := json.Marshal(activityCursor{At: item.at.Format(time.RFC3339Nano), Key: item.key()})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeActivityCursor parses a cursor produced by encodeActivityCursor.
+func decodeActivityCursor(cursor string) (*ActivityItem, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+	var c activityCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	at, err := time.Parse(time.RFC3339Nano, c.At)
+	if err != nil {
+		return nil, err
+	}
+	typ, id, ok := strings.Cut(c.Key, ":")
+	if !ok {
+		return nil, fmt.Errorf("cursor has no key")
+	}
+	return &ActivityItem{Type: typ, ID: id, at: at}, nil
+}
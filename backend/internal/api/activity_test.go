@@ -0,0 +1,304 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matou-dao/backend/internal/anystore"
+	"github.com/matou-dao/backend/internal/anysync"
+)
+
+// seedActivity writes an object of typeName with data to spaceID, as a write
+// handler or a synced peer would.
+func seedActivity(t *testing.T, env *chatTestEnv, spaceID, typeName, id string, data map[string]interface{}) {
+	t.Helper()
+	client := env.spaceManager.GetClient()
+	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), spaceID, client.GetSigningKey())
+	if err != nil {
+		t.Fatalf("loading keys: %v", err)
+	}
+	if _, err := env.spaceManager.ObjectTreeManager().AddObject(context.Background(), spaceID, profileObject(t, typeName, id, 1, data), keys.SigningKey); err != nil {
+		t.Fatalf("seeding %s: %v", id, err)
+	}
+}
+
+// setupActivityTestEnv seeds one item of each type at known times into a
+// chat test env: a member joining, messages in an open and a steward-only
+// channel, a published notice and a draft, and a comment on each notice.
+func setupActivityTestEnv(t *testing.T) (*chatTestEnv, map[string]time.Time) {
+	env, at, _ := setupActivityTestEnvWithRoles(t)
+	return env, at
+}
+
+// setupActivityTestEnvWithRoles is setupActivityTestEnv, also returning the
+// role lookup the activity handler was registered with.
+func setupActivityTestEnvWithRoles(t *testing.T) (*chatTestEnv, map[string]time.Time, RoleLookup) {
+	t.Helper()
+	env := setupChatTestEnv(t)
+	t.Cleanup(env.cleanup)
+	const steward, member = "ETEST_CHAT_USER01", "ETEST_CHAT_MEMBER"
+	roles := setChatRoles(env, map[string]string{steward: "Community Steward", member: "Member"})
+
+	now := time.Now().UTC().Truncate(time.Second)
+	at := map[string]time.Time{
+		"member":    now.Add(-4 * time.Hour),
+		"open":      now.Add(-3 * time.Hour),
+		"notice":    now.Add(-2 * time.Hour),
+		"gated":     now.Add(-1 * time.Hour),
+		"comment":   now.Add(-30 * time.Minute),
+		"old":       now.Add(-40 * 24 * time.Hour),
+		"draft":     now.Add(-20 * time.Minute),
+		"draftNote": now.Add(-10 * time.Minute),
+	}
+	ts := func(key string) string { return at[key].Format(time.RFC3339) }
+
+	communitySpaceID := env.spaceManager.GetCommunitySpaceID()
+	roSpaceID := env.spaceManager.GetCommunityReadOnlySpaceID()
+	seedActivity(t, env, roSpaceID, "CommunityProfile", "CommunityProfile-"+member, map[string]interface{}{
+		"userAID": member, "role": "Member", "memberSince": ts("member"),
+	})
+
+	openChannelID := createTestChannel(t, env, "open")
+	w := createChannelWithRoles(env, `["Community Steward"]`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create gated channel: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&created)
+	gatedChannelID := created["channelId"].(string)
+
+	for id, msg := range map[string]struct{ channel, at string }{
+		"msg-open":  {openChannelID, "open"},
+		"msg-gated": {gatedChannelID, "gated"},
+		"msg-old":   {openChannelID, "old"},
+	} {
+		seedActivity(t, env, communitySpaceID, "ChatMessage", id, map[string]interface{}{
+			"channelId": msg.channel, "senderAid": steward, "senderName": "Steward",
+			"content": id, "sentAt": ts(msg.at),
+		})
+	}
+
+	noticeMgr := env.spaceManager.NoticeTreeManager()
+	client := env.spaceManager.GetClient()
+	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), communitySpaceID, client.GetSigningKey())
+	if err != nil {
+		t.Fatalf("loading keys: %v", err)
+	}
+	ctx := context.Background()
+	for _, n := range []*anysync.NoticePayload{
+		{ID: "notice-pub", Type: "update", Title: "Published", State: "published", CreatedAt: ts("notice"), PublishedAt: ts("notice"), IssuerID: steward},
+		{ID: "notice-draft", Type: "update", Title: "Draft", State: "draft", CreatedAt: ts("draftNote"), IssuerID: steward},
+	} {
+		if _, err := noticeMgr.CreateNotice(ctx, communitySpaceID, n, keys.SigningKey); err != nil {
+			t.Fatalf("creating notice: %v", err)
+		}
+	}
+	for _, c := range []*anysync.NoticeCommentPayload{
+		{ID: "comment-pub", NoticeID: "notice-pub", UserID: member, Text: "nice", CreatedAt: ts("comment")},
+		{ID: "comment-draft", NoticeID: "notice-draft", UserID: steward, Text: "wip", CreatedAt: ts("draft")},
+	} {
+		if _, err := noticeMgr.CreateComment(ctx, communitySpaceID, c, keys.SigningKey); err != nil {
+			t.Fatalf("creating comment: %v", err)
+		}
+	}
+
+	NewActivityHandler(env.spaceManager, env.userIdentity).RegisterRoutes(env.mux, roles)
+	return env, at, roles
+}
+
+type activityTestResponse struct {
+	Items      []ActivityItem `json:"items"`
+	Total      int            `json:"total"`
+	NextCursor string         `json:"nextCursor"`
+	HasMore    bool           `json:"hasMore"`
+}
+
+func getActivity(t *testing.T, env *chatTestEnv, query string) activityTestResponse {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/activity"+query, nil)
+	w := httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp activityTestResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	return resp
+}
+
+func activityIDs(items []ActivityItem) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	return ids
+}
+
+func TestActivity_NewestFirst(t *testing.T) {
+	env, at := setupActivityTestEnv(t)
+
+	resp := getActivity(t, env, "")
+	want := []string{"Comment-notice-pub-comment-pub", "msg-gated", "notice-pub", "msg-open", "ETEST_CHAT_MEMBER"}
+	got := activityIDs(resp.Items)
+	if len(got) != len(want) {
+		t.Fatalf("expected items %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected items %v, got %v", want, got)
+		}
+	}
+	if resp.Items[0].Type != ActivityComment || resp.Items[0].Title != "Published" {
+		t.Errorf("expected a comment on the published notice first, got %+v", resp.Items[0])
+	}
+	if resp.Items[2].Type != ActivityNotice || resp.Items[2].At != at["notice"].Format(time.RFC3339) {
+		t.Errorf("expected the notice at its publish time, got %+v", resp.Items[2])
+	}
+	if resp.Items[4].Type != ActivityMember {
+		t.Errorf("expected the member join last, got %+v", resp.Items[4])
+	}
+}
+
+func TestActivity_ExcludesRoleGatedMessages(t *testing.T) {
+	env, _ := setupActivityTestEnv(t)
+	env.userIdentity.SetIdentity("ETEST_CHAT_MEMBER", "member-mnemonic")
+
+	resp := getActivity(t, env, "?types=message")
+	got := activityIDs(resp.Items)
+	if len(got) != 1 || got[0] != "msg-open" {
+		t.Errorf("expected only the open channel's message for a member, got %v", got)
+	}
+}
+
+func TestActivity_Pagination(t *testing.T) {
+	env, _ := setupActivityTestEnv(t)
+
+	var got []string
+	query := "?limit=2"
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatal("pagination did not terminate")
+		}
+		resp := getActivity(t, env, query)
+		if resp.Total != 5 {
+			t.Errorf("expected total 5, got %d", resp.Total)
+		}
+		got = append(got, activityIDs(resp.Items)...)
+		if !resp.HasMore {
+			break
+		}
+		query = "?limit=2&cursor=" + resp.NextCursor
+	}
+	want := []string{"Comment-notice-pub-comment-pub", "msg-gated", "notice-pub", "msg-open", "ETEST_CHAT_MEMBER"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v across pages, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v across pages, got %v", want, got)
+		}
+	}
+}
+
+func TestActivity_Since(t *testing.T) {
+	env, at := setupActivityTestEnv(t)
+
+	resp := getActivity(t, env, "?since="+at["notice"].Format(time.RFC3339))
+	if got := activityIDs(resp.Items); len(got) != 3 {
+		t.Errorf("expected the 3 items since the notice, got %v", got)
+	}
+
+	// An earlier since is clamped to the lookback window
+	resp = getActivity(t, env, "?types=message&since="+at["old"].Add(-time.Hour).Format(time.RFC3339))
+	for _, item := range resp.Items {
+		if item.ID == "msg-old" {
+			t.Error("expected a message older than the lookback window to be left out")
+		}
+	}
+}
+
+func TestActivity_InvalidParams(t *testing.T) {
+	env, _ := setupActivityTestEnv(t)
+
+	for _, query := range []string{"?types=bogus", "?since=yesterday", "?cursor=!!", "?limit=0"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/activity"+query, nil)
+		w := httptest.NewRecorder()
+		env.mux.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestActivity_SkipsDisabledFeatures(t *testing.T) {
+	env, _, roles := setupActivityTestEnvWithRoles(t)
+	handler := NewActivityHandler(env.spaceManager, env.userIdentity)
+	handler.SetFeatureGate(NewFeatureGate(FeatureFlags{Chat: false, Notices: true, Trust: true}, nil))
+	env.mux = http.NewServeMux()
+	handler.RegisterRoutes(env.mux, roles)
+
+	resp := getActivity(t, env, "")
+	for _, item := range resp.Items {
+		if item.Type == ActivityMessage {
+			t.Errorf("expected no messages with chat disabled, got %+v", item)
+		}
+	}
+	if len(resp.Items) != 3 {
+		t.Errorf("expected the notice, comment and member join, got %v", activityIDs(resp.Items))
+	}
+}
+
+func TestActivity_ReadsMessagesFromStore(t *testing.T) {
+	env, at, roles := setupActivityTestEnvWithRoles(t)
+	store, err := anystore.NewLocalStore(anystore.DefaultConfig(t.TempDir()))
+	if err != nil {
+		t.Fatalf("creating store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	ctx := context.Background()
+	if err := store.EnsureChatIndexes(ctx); err != nil {
+		t.Fatalf("creating indexes: %v", err)
+	}
+	ts := func(key string) string { return at[key].Format(time.RFC3339) }
+	for _, ch := range []*anystore.ChatChannel{
+		{ID: "ch-open", Name: "open"},
+		{ID: "ch-gated", Name: "gated", AllowedRoles: []string{"Community Steward"}},
+	} {
+		if err := store.UpsertChannel(ctx, ch); err != nil {
+			t.Fatalf("upserting channel: %v", err)
+		}
+	}
+	for _, msg := range []*anystore.ChatMessage{
+		{ID: "idx-open", ChannelID: "ch-open", SenderAID: "ETEST_CHAT_USER01", Content: "hi", SentAt: ts("open")},
+		{ID: "idx-gated", ChannelID: "ch-gated", SenderAID: "ETEST_CHAT_USER01", Content: "hush", SentAt: ts("gated")},
+		{ID: "idx-old", ChannelID: "ch-open", SenderAID: "ETEST_CHAT_USER01", Content: "old", SentAt: ts("old")},
+		{ID: "idx-deleted", ChannelID: "ch-open", SenderAID: "ETEST_CHAT_USER01", SentAt: ts("open"), DeletedAt: ts("gated")},
+	} {
+		if err := store.UpsertMessage(ctx, msg); err != nil {
+			t.Fatalf("upserting message: %v", err)
+		}
+	}
+
+	handler := NewActivityHandler(env.spaceManager, env.userIdentity)
+	handler.SetStore(store)
+	env.mux = http.NewServeMux()
+	handler.RegisterRoutes(env.mux, roles)
+
+	// The index is read instead of the trees, which hold msg-open and msg-gated
+	got := activityIDs(getActivity(t, env, "?types=message").Items)
+	if len(got) != 2 || got[0] != "idx-gated" || got[1] != "idx-open" {
+		t.Errorf("expected the indexed messages in the window, got %v", got)
+	}
+
+	env.userIdentity.SetIdentity("ETEST_CHAT_MEMBER", "member-mnemonic")
+	got = activityIDs(getActivity(t, env, "?types=message").Items)
+	if len(got) != 1 || got[0] != "idx-open" {
+		t.Errorf("expected only the open channel's message for a member, got %v", got)
+	}
+}
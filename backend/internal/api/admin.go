@@ -0,0 +1,305 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/matou-dao/backend/internal/anysync"
+	"github.com/matou-dao/backend/internal/contributions"
+)
+
+// AdminSettingsObjectType is the ObjectPayload.Type of the org-level settings
+// object. It is written only to the admin space.
+const AdminSettingsObjectType = "AdminSettings"
+
+// Moderation policies for community content. The policy is stored with the
+// settings for clients to show and act on; the backend doesn't enforce it yet.
+const (
+	// ModerationPolicyOpen lets members post freely; admins moderate after the fact.
+	ModerationPolicyOpen = "open"
+	// ModerationPolicyReview holds reported content until an admin reviews it.
+	ModerationPolicyReview = "review"
+	// ModerationPolicyRestricted lets only stewards and admins post in shared spaces.
+	ModerationPolicyRestricted = "restricted"
+)
+
+var validModerationPolicies = map[string]bool{
+	ModerationPolicyOpen:       true,
+	ModerationPolicyReview:     true,
+	ModerationPolicyRestricted: true,
+}
+
+// AdminSettingsData holds the org-level settings managed by community admins.
+type AdminSettingsData struct {
+	// DefaultChannelRoles are the allowedRoles given to new channels that
+	// don't set their own (see ChatHandler.HandleCreateChannel), with the
+	// canonical spelling of canonicalAllowedRoles. Empty means new channels
+	// are open to all members.
+	DefaultChannelRoles []string `json:"defaultChannelRoles"`
+	// ModerationPolicy is one of the ModerationPolicy constants. It is only
+	// stored; see the constants.
+	ModerationPolicy string          `json:"moderationPolicy"`
+	FeatureFlags     map[string]bool `json:"featureFlags"`
+	UpdatedAt        string          `json:"updatedAt"`
+	UpdatedBy        string          `json:"updatedBy"`
+}
+
+// UpdateAdminSettingsRequest is the request body for updating admin settings.
+// Omitted fields are left unchanged; featureFlags are merged into the
+// existing flags.
+type UpdateAdminSettingsRequest struct {
+	DefaultChannelRoles *[]string       `json:"defaultChannelRoles,omitempty"`
+	ModerationPolicy    *string         `json:"moderationPolicy,omitempty"`
+	FeatureFlags        map[string]bool `json:"featureFlags,omitempty"`
+}
+
+// AdminHandler handles admin space endpoints.
+type AdminHandler struct {
+	spaceManager *anysync.SpaceManager
+	quarantine   *Quarantine  // see SetQuarantine
+	features     *FeatureGate // see SetFeatureGate
+}
+
+// NewAdminHandler creates a new admin handler.
+func NewAdminHandler(spaceManager *anysync.SpaceManager) *AdminHandler {
+	return &AdminHandler{spaceManager: spaceManager}
+}
+
+// SetFeatureGate refreshes gate's flags whenever the settings are updated.
+func (h *AdminHandler) SetFeatureGate(gate *FeatureGate) {
+	h.features = gate
+}
+
+// RegisterRoutes registers admin routes on the mux. Every route requires the
+// caller to be a community admin.
+func (h *AdminHandler) RegisterRoutes(mux *http.ServeMux, roleLookup RoleLookup) {
+	mux.HandleFunc("/api/v1/admin/settings", RBACMiddleware(roleLookup, requireCommunityAdmin(h.handleSettings)))
+	mux.HandleFunc("/api/v1/admin/invites", RBACMiddleware(roleLookup, requireCommunityAdmin(h.HandleListInvites)))
+	mux.HandleFunc("/api/v1/admin/moderation-log", RBACMiddleware(roleLookup, requireCommunityAdmin(h.HandleModerationLog)))
+	mux.HandleFunc("/api/v1/admin/quarantine", RBACMiddleware(roleLookup, requireCommunityAdmin(h.HandleQuarantine)))
+}
+
+// handleSettings routes /api/v1/admin/settings requests.
+func (h *AdminHandler) handleSettings(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.HandleGetSettings(w, r)
+	case http.MethodPost:
+		h.HandleUpdateSettings(w, r)
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+	}
+}
+
+// requireCommunityAdmin rejects callers that don't hold a community admin role
+// (Operations Steward or Founding Member), as resolved from their
+// CommunityProfile by RBACMiddleware.
+func requireCommunityAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !isCommunityAdmin(GetUserRoles(r)) {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "community admin role required"})
+			return
+		}
+		next(w, r)
+	}
+}
+
+// isCommunityAdmin reports whether roles include a community admin role.
+func isCommunityAdmin(roles []contributions.Role) bool {
+	return contributions.HasRole(roles, contributions.RoleOperationsSteward) ||
+		contributions.HasRole(roles, contributions.RoleFoundingMember)
+}
+
+// HandleGetSettings handles GET /api/v1/admin/settings — get the org settings.
+// An admin space without a settings object returns the defaults.
+func (h *AdminHandler) HandleGetSettings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		return
+	}
+
+	adminSpaceID := h.spaceManager.GetAdminSpaceID()
+	if adminSpaceID == "" {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "admin space not configured",
+		})
+		return
+	}
+
+	settings, _, err := readAdminSettings(r.Context(), h.spaceManager, adminSpaceID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("failed to read admin settings: %v", err),
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, settings)
+}
+
+// HandleUpdateSettings handles POST /api/v1/admin/settings — update the org settings.
+func (h *AdminHandler) HandleUpdateSettings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		return
+	}
+
+	var req UpdateAdminSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("invalid request: %v", err),
+		})
+		return
+	}
+	if req.ModerationPolicy != nil && !validModerationPolicies[*req.ModerationPolicy] {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("invalid moderation policy %q (expected open, review or restricted)", *req.ModerationPolicy),
+		})
+		return
+	}
+	if req.DefaultChannelRoles != nil {
+		roles, err := canonicalAllowedRoles(*req.DefaultChannelRoles)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": fmt.Sprintf("invalid default channel roles: %v", err),
+			})
+			return
+		}
+		req.DefaultChannelRoles = &roles
+	}
+
+	adminSpaceID := h.spaceManager.GetAdminSpaceID()
+	if adminSpaceID == "" {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "admin space not configured",
+		})
+		return
+	}
+
+	ctx := r.Context()
+	settings, version, err := readAdminSettings(ctx, h.spaceManager, adminSpaceID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("failed to read admin settings: %v", err),
+		})
+		return
+	}
+
+	if req.DefaultChannelRoles != nil {
+		settings.DefaultChannelRoles = *req.DefaultChannelRoles
+	}
+	if req.ModerationPolicy != nil {
+		settings.ModerationPolicy = *req.ModerationPolicy
+	}
+	for flag, enabled := range req.FeatureFlags {
+		settings.FeatureFlags[flag] = enabled
+	}
+	settings.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	settings.UpdatedBy = GetUserAID(r)
+
+	if err := saveAdminSettings(ctx, h.spaceManager, adminSpaceID, settings, version); err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("failed to save admin settings: %v", err),
+		})
+		return
+	}
+
+	if h.features != nil {
+		h.features.Invalidate()
+	}
+	log.Printf("[Admin] Settings updated by %s", settings.UpdatedBy)
+	writeJSON(w, http.StatusOK, settings)
+}
+
+// adminSettingsID returns the object ID of the settings object in an admin space.
+func adminSettingsID(adminSpaceID string) string {
+	return fmt.Sprintf("AdminSettings-%s", adminSpaceID)
+}
+
+// defaultAdminSettings returns the settings a new admin space starts with.
+func defaultAdminSettings() *AdminSettingsData {
+	return &AdminSettingsData{
+		DefaultChannelRoles: []string{},
+		ModerationPolicy:    ModerationPolicyOpen,
+		FeatureFlags:        map[string]bool{},
+	}
+}
+
+// readAdminSettings returns the settings stored in the admin space and their
+// object version, or the defaults at version 0 if none have been written.
+func readAdminSettings(ctx context.Context, spaceManager *anysync.SpaceManager, adminSpaceID string) (*AdminSettingsData, int, error) {
+	// Build space index to discover trees
+	spaceManager.TreeManager().BuildSpaceIndex(ctx, adminSpaceID)
+
+	settings := defaultAdminSettings()
+	obj, err := spaceManager.ObjectTreeManager().ReadLatestByID(ctx, adminSpaceID, adminSettingsID(adminSpaceID))
+	if err != nil {
+		// Not found is ok, use the defaults
+		return settings, 0, nil
+	}
+	if err := json.Unmarshal(obj.Data, settings); err != nil {
+		return nil, 0, fmt.Errorf("invalid admin settings data: %w", err)
+	}
+	if settings.DefaultChannelRoles == nil {
+		settings.DefaultChannelRoles = []string{}
+	}
+	if settings.FeatureFlags == nil {
+		settings.FeatureFlags = map[string]bool{}
+	}
+	return settings, obj.Version, nil
+}
+
+// saveAdminSettings writes settings to the admin space as the version after existingVersion.
+func saveAdminSettings(ctx context.Context, spaceManager *anysync.SpaceManager, adminSpaceID string, settings *AdminSettingsData, existingVersion int) error {
+	client := spaceManager.GetClient()
+	if client == nil {
+		return fmt.Errorf("any-sync client not available")
+	}
+	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), adminSpaceID, client.GetSigningKey())
+	if err != nil {
+		return fmt.Errorf("loading space keys: %w", err)
+	}
+
+	dataBytes, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("marshaling admin settings: %w", err)
+	}
+
+	ownerKey := ""
+	if keys.SigningKey != nil {
+		pubKeyBytes, _ := keys.SigningKey.GetPublic().Marshall()
+		if pubKeyBytes != nil {
+			ownerKey = fmt.Sprintf("%x", pubKeyBytes)
+		}
+	}
+
+	payload := &anysync.ObjectPayload{
+		ID:        adminSettingsID(adminSpaceID),
+		Type:      AdminSettingsObjectType,
+		OwnerKey:  ownerKey,
+		Data:      dataBytes,
+		Timestamp: time.Now().Unix(),
+		Version:   existingVersion + 1,
+	}
+	_, err = spaceManager.ObjectTreeManager().AddObject(ctx, adminSpaceID, payload, keys.SigningKey)
+	return err
+}
+
+// seedAdminSettings writes the default settings to a newly created admin
+// space, unless it already has settings.
+func seedAdminSettings(ctx context.Context, spaceManager *anysync.SpaceManager, adminSpaceID, createdBy string) error {
+	settings, version, err := readAdminSettings(ctx, spaceManager, adminSpaceID)
+	if err != nil {
+		return err
+	}
+	if version > 0 {
+		return nil
+	}
+	settings.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	settings.UpdatedBy = createdBy
+	return saveAdminSettings(ctx, spaceManager, adminSpaceID, settings, 0)
+}
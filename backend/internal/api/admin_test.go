@@ -0,0 +1,173 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/anyproto/any-sync/commonspace/object/tree/objecttree"
+	"github.com/matou-dao/backend/internal/anysync"
+	"github.com/matou-dao/backend/internal/contributions"
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	adminTestAdminAID  = "ETEST_ADMIN_FOUNDER"
+	adminTestMemberAID = "ETEST_ADMIN_MEMBER"
+)
+
+// setupAdminTestEnv builds an AdminHandler with an admin space backed by
+// in-memory mock trees. adminTestAdminAID resolves to a Founding Member and
+// adminTestMemberAID to a plain Member.
+func setupAdminTestEnv(t *testing.T) (*AdminHandler, *http.ServeMux, string) {
+	t.Helper()
+
+	tmpDir, err := os.MkdirTemp("", "admin_test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(tmpDir) })
+
+	adminSpaceID := "space-admin-settings-test"
+	keys, err := anysync.GenerateSpaceKeySet()
+	if err != nil {
+		t.Fatalf("generating keys: %v", err)
+	}
+	if err := anysync.PersistSpaceKeySet(tmpDir, adminSpaceID, keys); err != nil {
+		t.Fatalf("persisting keys: %v", err)
+	}
+
+	anysyncClient := &mockAnySyncClientForChat{
+		mockAnySyncClientForIntegration: mockAnySyncClientForIntegration{
+			spaces: make(map[string]*anysync.SpaceCreateResult),
+		},
+		dataDir: tmpDir,
+	}
+	spaceManager := anysync.NewSpaceManager(anysyncClient, &anysync.SpaceManagerConfig{
+		AdminSpaceID: adminSpaceID,
+		OrgAID:       "EOrg_AdminTest",
+	})
+
+	ctrl := gomock.NewController(t)
+	treeSeq := 0
+	spaceManager.TreeManager().SetTestTreeFactory(adminSpaceID, func(objectID string) objecttree.ObjectTree {
+		treeSeq++
+		tree := setupStatefulMock(ctrl, &statefulMockTree{})
+		tree.EXPECT().Id().Return(fmt.Sprintf("tree-admin-%d-%s", treeSeq, objectID)).AnyTimes()
+		tree.EXPECT().Header().Return(nil).AnyTimes()
+		return tree
+	})
+
+	handler := NewAdminHandler(spaceManager)
+	roleLookup := &mockRoleLookup{roles: map[string][]contributions.Role{
+		adminTestAdminAID:  contributions.MapKERIRole("Founding Member"),
+		adminTestMemberAID: contributions.MapKERIRole("Member"),
+	}}
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux, roleLookup)
+
+	return handler, mux, adminSpaceID
+}
+
+func doAdminRequest(mux *http.ServeMux, method, body, callerAID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/api/v1/admin/settings", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if callerAID != "" {
+		req.Header.Set("X-User-AID", callerAID)
+	}
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	return w
+}
+
+func TestAdmin_Settings_ReadWrite(t *testing.T) {
+	handler, mux, adminSpaceID := setupAdminTestEnv(t)
+
+	w := doAdminRequest(mux, http.MethodGet, "", adminTestAdminAID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("get settings: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var settings AdminSettingsData
+	json.NewDecoder(w.Body).Decode(&settings)
+	if settings.ModerationPolicy != ModerationPolicyOpen || len(settings.DefaultChannelRoles) != 0 {
+		t.Errorf("expected default settings, got %+v", settings)
+	}
+
+	w = doAdminRequest(mux, http.MethodPost, `{"defaultChannelRoles":["founding member"],"featureFlags":{"polls":true}}`, adminTestAdminAID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update settings: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	w = doAdminRequest(mux, http.MethodPost, `{"moderationPolicy":"review","featureFlags":{"threads":false}}`, adminTestAdminAID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update settings: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = doAdminRequest(mux, http.MethodGet, "", adminTestAdminAID)
+	settings = AdminSettingsData{}
+	json.NewDecoder(w.Body).Decode(&settings)
+	if settings.ModerationPolicy != ModerationPolicyReview {
+		t.Errorf("expected moderation policy review, got %q", settings.ModerationPolicy)
+	}
+	if len(settings.DefaultChannelRoles) != 1 || settings.DefaultChannelRoles[0] != "Founding Member" {
+		t.Errorf("expected canonical default channel roles to be kept, got %v", settings.DefaultChannelRoles)
+	}
+	if !settings.FeatureFlags["polls"] || settings.FeatureFlags["threads"] || len(settings.FeatureFlags) != 2 {
+		t.Errorf("expected feature flags to be merged, got %v", settings.FeatureFlags)
+	}
+	if settings.UpdatedBy != adminTestAdminAID {
+		t.Errorf("expected updatedBy %s, got %q", adminTestAdminAID, settings.UpdatedBy)
+	}
+
+	_, version, err := readAdminSettings(context.Background(), handler.spaceManager, adminSpaceID)
+	if err != nil || version != 2 {
+		t.Errorf("expected settings at version 2, got %d (err %v)", version, err)
+	}
+
+	w = doAdminRequest(mux, http.MethodPost, `{"moderationPolicy":"anything goes"}`, adminTestAdminAID)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid policy: expected 400, got %d", w.Code)
+	}
+	w = doAdminRequest(mux, http.MethodPost, `{"defaultChannelRoles":["Founding Membr"]}`, adminTestAdminAID)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("unknown default channel role: expected 400, got %d", w.Code)
+	}
+}
+
+func TestAdmin_Settings_NonAdminForbidden(t *testing.T) {
+	_, mux, _ := setupAdminTestEnv(t)
+
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		if w := doAdminRequest(mux, method, `{"moderationPolicy":"restricted"}`, adminTestMemberAID); w.Code != http.StatusForbidden {
+			t.Errorf("%s as member: expected 403, got %d: %s", method, w.Code, w.Body.String())
+		}
+		if w := doAdminRequest(mux, method, `{}`, ""); w.Code != http.StatusUnauthorized {
+			t.Errorf("%s without AID: expected 401, got %d", method, w.Code)
+		}
+	}
+}
+
+func TestSeedAdminSettings(t *testing.T) {
+	handler, _, adminSpaceID := setupAdminTestEnv(t)
+	ctx := context.Background()
+
+	if err := seedAdminSettings(ctx, handler.spaceManager, adminSpaceID, adminTestAdminAID); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	// Seeding again must not overwrite existing settings
+	if err := seedAdminSettings(ctx, handler.spaceManager, adminSpaceID, "EOTHER"); err != nil {
+		t.Fatalf("second seed: %v", err)
+	}
+
+	settings, version, err := readAdminSettings(ctx, handler.spaceManager, adminSpaceID)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if version != 1 || settings.UpdatedBy != adminTestAdminAID || settings.ModerationPolicy != ModerationPolicyOpen {
+		t.Errorf("expected seeded defaults at version 1, got version %d: %+v", version, settings)
+	}
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/ipfs/go-cid"
+	"github.com/matou-dao/backend/internal/anysync"
+)
+
+// SetFileStore lets message sends check attachment references against the
+// uploaded files' metadata. Without one, messages with attachments are
+// rejected since no upload could have produced them.
+func (h *ChatHandler) SetFileStore(fileManager *anysync.FileManager) {
+	if fileManager != nil {
+		h.files = fileManager
+	}
+}
+
+// validateAttachments checks each ref names a file uploaded to spaceID with
+// the claimed size and content type, and returns the refs normalized from
+// the stored metadata. On failure it writes the error response and returns
+// false.
+func (h *ChatHandler) validateAttachments(ctx context.Context, w http.ResponseWriter, spaceID string, refs []AttachmentRef) ([]AttachmentRef, bool) {
+	if len(refs) == 0 {
+		return nil, true
+	}
+	if h.files == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "file storage not available (filenode not configured)",
+		})
+		return nil, false
+	}
+
+	normalized := make([]AttachmentRef, 0, len(refs))
+	for i, ref := range refs {
+		meta, err := attachmentMeta(ctx, h.files, spaceID, ref)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": fmt.Sprintf("attachments[%d]: %v", i, err),
+			})
+			return nil, false
+		}
+		normalized = append(normalized, AttachmentRef{
+			FileRef:      meta.CID,
+			FileName:     attachmentFileName(ref.FileName, meta.CID),
+			ContentType:  meta.ContentType,
+			Size:         meta.Size,
+			ThumbnailRef: meta.ThumbnailRef,
+		})
+	}
+	return normalized, true
+}
+
+// attachmentMeta resolves ref's file in files and checks it matches what ref
+// claims.
+func attachmentMeta(ctx context.Context, files fileStore, spaceID string, ref AttachmentRef) (*anysync.FileMeta, error) {
+	if _, err := cid.Decode(ref.FileRef); err != nil {
+		return nil, fmt.Errorf("invalid fileRef %q (not a valid CID)", ref.FileRef)
+	}
+	meta, err := files.GetFileMeta(ctx, spaceID, ref.FileRef)
+	if err != nil {
+		return nil, fmt.Errorf("file %s not found", ref.FileRef)
+	}
+	if ref.Size != meta.Size {
+		return nil, fmt.Errorf("size %d does not match the stored file (%d bytes)", ref.Size, meta.Size)
+	}
+	if ref.ContentType != "" && !sameMediaType(ref.ContentType, meta.ContentType) {
+		return nil, fmt.Errorf("content type %s does not match the stored file (%s)", ref.ContentType, meta.ContentType)
+	}
+	return meta, nil
+}
+
+// sameMediaType compares content types ignoring case and parameters.
+func sameMediaType(a, b string) bool {
+	mediaA, _, errA := mime.ParseMediaType(a)
+	mediaB, _, errB := mime.ParseMediaType(b)
+	if errA != nil || errB != nil {
+		return strings.EqualFold(a, b)
+	}
+	return mediaA == mediaB
+}
+
+// attachmentFileName strips any directory from a client-supplied file name,
+// falling back to the file's ref when nothing is left.
+func attachmentFileName(name, fileRef string) string {
+	name = path.Base(strings.ReplaceAll(strings.TrimSpace(name), `\`, "/"))
+	if name == "." || name == "/" {
+		return fileRef
+	}
+	return name
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// setupAttachmentTestEnv returns a chat env whose file store holds one
+// uploaded PNG, and the ref the upload returned.
+func setupAttachmentTestEnv(t *testing.T) (*chatTestEnv, AttachmentRef) {
+	t.Helper()
+	env := setupChatTestEnv(t)
+	t.Cleanup(env.cleanup)
+	files := newMockFileStore()
+	env.chatHandler.files = files
+
+	data := []byte("\x89PNG\r\n\x1a\nnot really an image")
+	fileRef, err := files.AddFile(context.Background(), env.spaceManager.GetCommunitySpaceID(), bytes.NewReader(data), "image/png", int64(len(data)), nil)
+	if err != nil {
+		t.Fatalf("adding file: %v", err)
+	}
+	return env, AttachmentRef{FileRef: fileRef, FileName: "photo.png", ContentType: "image/png", Size: int64(len(data))}
+}
+
+func sendWithAttachment(t *testing.T, env *chatTestEnv, channelID string, ref AttachmentRef) *httptest.ResponseRecorder {
+	t.Helper()
+	body, _ := json.Marshal(SendMessageRequest{Content: "see attached", Attachments: []AttachmentRef{ref}})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/chat/channels/"+channelID+"/messages", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	return w
+}
+
+func TestSendMessage_ValidAttachment(t *testing.T) {
+	env, ref := setupAttachmentTestEnv(t)
+	channelID := createTestChannel(t, env, "attachments")
+
+	// Stored as the file's metadata describes it, with the client's path stripped
+	ref.FileName = `C:\Users\me\photo.png`
+	ref.ContentType = "IMAGE/PNG"
+	w := sendWithAttachment(t, env, channelID, ref)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/chat/channels/"+channelID+"/messages", nil)
+	w = httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	var resp struct {
+		Messages []MessageResponse `json:"messages"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if len(resp.Messages) != 1 {
+		t.Fatalf("expected 1 message, got %s", w.Body.String())
+	}
+	msg := resp.Messages[0]
+	if len(msg.Attachments) != 1 {
+		t.Fatalf("expected 1 attachment, got %+v", msg.Attachments)
+	}
+	if got := msg.Attachments[0]; got.FileRef != ref.FileRef || got.FileName != "photo.png" || got.ContentType != "image/png" || got.Size != ref.Size {
+		t.Errorf("unexpected stored attachment %+v", got)
+	}
+}
+
+func TestSendMessage_NonexistentAttachment(t *testing.T) {
+	env, ref := setupAttachmentTestEnv(t)
+	channelID := createTestChannel(t, env, "attachments")
+
+	ref.FileRef = "bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku"
+	w := sendWithAttachment(t, env, channelID, ref)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "not found") {
+		t.Errorf("expected 400 for a missing file, got %d: %s", w.Code, w.Body.String())
+	}
+
+	ref.FileRef = "not-a-cid"
+	if w := sendWithAttachment(t, env, channelID, ref); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid ref, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestSendMessage_AttachmentMismatch(t *testing.T) {
+	env, ref := setupAttachmentTestEnv(t)
+	channelID := createTestChannel(t, env, "attachments")
+
+	oversized := ref
+	oversized.Size = 10 << 30
+	if w := sendWithAttachment(t, env, channelID, oversized); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "size") {
+		t.Errorf("expected 400 for a size mismatch, got %d: %s", w.Code, w.Body.String())
+	}
+
+	retyped := ref
+	retyped.ContentType = "application/pdf"
+	if w := sendWithAttachment(t, env, channelID, retyped); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a content type mismatch, got %d: %s", w.Code, w.Body.String())
+	}
+
+	env.chatHandler.files = nil
+	if w := sendWithAttachment(t, env, channelID, ref); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 without a file store, got %d: %s", w.Code, w.Body.String())
+	}
+}
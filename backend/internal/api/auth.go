@@ -0,0 +1,398 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/matou-dao/backend/internal/identity"
+)
+
+// Auth modes, selected by MATOU_AUTH_MODE.
+const (
+	// AuthModeDev trusts every request to act as the node's local identity,
+	// as the single-user desktop app always has. Tokens are still honoured.
+	AuthModeDev = "dev"
+	// AuthModeToken requires a valid session token on write requests and
+	// binds the caller's AID to the token rather than the local identity.
+	AuthModeToken = "token"
+)
+
+// DefaultSessionTTL is how long an issued session token stays valid.
+const DefaultSessionTTL = 30 * 24 * time.Hour
+
+// authSecretFile holds the HMAC key for session tokens, under the data dir.
+const authSecretFile = "auth.secret"
+
+var (
+	errTokenMalformed = errors.New("malformed session token")
+	errTokenSignature = errors.New("invalid session token signature")
+	errTokenExpired   = errors.New("session token expired")
+)
+
+// authExemptPaths accept writes without a session token in token mode, since
+// they are how a caller proves control of an identity and gets a token.
+var authExemptPaths = []string{
+	"/api/v1/auth/session",
+}
+
+// identitySetPath sets or replaces the node's identity. In token mode it
+// needs a session token, except on first run: before any identity is
+// configured there is nothing to get a token for, so a loopback caller may
+// set one without.
+const identitySetPath = "/api/v1/identity/set"
+
+// ctxAuth holds the *authState set by AuthMiddleware.
+const ctxAuth contextKey = "auth"
+
+// authState is the result of authenticating a request.
+type authState struct {
+	aid      string // the AID the request acts as; empty if anonymous
+	enforced bool   // true in token mode, where aid came from a verified token
+}
+
+// sessionClaims is the signed payload of a session token.
+type sessionClaims struct {
+	AID       string `json:"aid"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Authenticator issues and verifies HMAC-signed session tokens.
+type Authenticator struct {
+	secret   []byte
+	mode     string
+	ttl      time.Duration
+	localAID func() string
+}
+
+// NewAuthenticator creates an authenticator. localAID returns the node's
+// current identity, which requests act as in dev mode.
+func NewAuthenticator(secret []byte, mode string, localAID func() string) *Authenticator {
+	if mode != AuthModeToken {
+		mode = AuthModeDev
+	}
+	return &Authenticator{
+		secret:   secret,
+		mode:     mode,
+		ttl:      DefaultSessionTTL,
+		localAID: localAID,
+	}
+}
+
+// Mode returns AuthModeDev or AuthModeToken.
+func (a *Authenticator) Mode() string {
+	return a.mode
+}
+
+// SetSessionTTL overrides how long issued tokens stay valid.
+func (a *Authenticator) SetSessionTTL(ttl time.Duration) {
+	a.ttl = ttl
+}
+
+// LoadOrCreateAuthSecret reads the session signing key from
+// {dataDir}/auth.secret, generating it on first run. Keeping it on disk
+// means tokens survive a restart.
+func LoadOrCreateAuthSecret(dataDir string) ([]byte, error) {
+	path := filepath.Join(dataDir, authSecretFile)
+	if data, err := os.ReadFile(path); err == nil {
+		secret, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(secret) < 32 {
+			return nil, fmt.Errorf("invalid auth secret in %s", path)
+		}
+		return secret, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("reading auth secret: %w", err)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("generating auth secret: %w", err)
+	}
+	if err := os.MkdirAll(dataDir, 0700); err != nil {
+		return nil, fmt.Errorf("creating data dir: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(secret)), 0600); err != nil {
+		return nil, fmt.Errorf("writing auth secret: %w", err)
+	}
+	return secret, nil
+}
+
+// IssueToken returns a session token for aid and when it expires.
+func (a *Authenticator) IssueToken(aid string, now time.Time) (string, time.Time, error) {
+	if aid == "" {
+		return "", time.Time{}, fmt.Errorf("aid is required")
+	}
+	expiresAt := now.Add(a.ttl).UTC()
+	payload, err := json.Marshal(sessionClaims{AID: aid, ExpiresAt: expiresAt.Unix()})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + a.sign(encoded), expiresAt, nil
+}
+
+// VerifyToken checks a token's signature and expiry and returns its AID.
+func (a *Authenticator) VerifyToken(token string, now time.Time) (string, error) {
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok || encoded == "" || sig == "" {
+		return "", errTokenMalformed
+	}
+	if !hmac.Equal([]byte(sig), []byte(a.sign(encoded))) {
+		return "", errTokenSignature
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", errTokenMalformed
+	}
+	var claims sessionClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.AID == "" {
+		return "", errTokenMalformed
+	}
+	if now.Unix() >= claims.ExpiresAt {
+		return "", errTokenExpired
+	}
+	return claims.AID, nil
+}
+
+func (a *Authenticator) sign(encoded string) string {
+	mac := hmac.New(sha256.New, a.secret)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// AuthMiddleware authenticates each request from its "Authorization: Bearer"
+// session token and stores the caller's AID in the request context, where
+// handlers read it with callerAID. An invalid token is always rejected. In
+// token mode, write requests without a token are rejected too, and reads
+// proceed anonymously; in dev mode, requests without a token act as the
+// local identity.
+func AuthMiddleware(auth *Authenticator, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		state := &authState{enforced: auth.mode == AuthModeToken}
+		if token, ok := bearerToken(r); ok {
+			aid, err := auth.VerifyToken(token, time.Now())
+			if err != nil {
+				writeJSON(w, http.StatusUnauthorized, map[string]string{"error": err.Error()})
+				return
+			}
+			state.aid = aid
+		} else if state.enforced {
+			if isWriteMethod(r.Method) && !isAuthExempt(r.URL.Path) && !auth.isFirstRunSetup(r) {
+				writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "authentication required"})
+				return
+			}
+		} else if auth.localAID != nil {
+			state.aid = auth.localAID()
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ctxAuth, state)))
+	})
+}
+
+// bearerToken returns the token from an "Authorization: Bearer" header, or
+// for the event stream, which EventSource opens without custom headers, from
+// ?access_token=.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		if r.Method == http.MethodGet && r.URL.Path == eventsPath {
+			if token := r.URL.Query().Get("access_token"); token != "" {
+				return token, true
+			}
+		}
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+func isWriteMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+func isAuthExempt(path string) bool {
+	return slices.Contains(authExemptPaths, path) || strings.HasPrefix(path, "/health")
+}
+
+// isFirstRunSetup reports whether r sets the first identity of an
+// unconfigured node from the local machine.
+func (a *Authenticator) isFirstRunSetup(r *http.Request) bool {
+	return r.URL.Path == identitySetPath && a.localAID != nil && a.localAID() == "" && isLoopbackRequest(r)
+}
+
+// callerAID returns the AID a request acts as: the one AuthMiddleware bound to
+// the request, or the local identity when the request didn't pass through it
+// (background work and handler unit tests).
+func callerAID(ctx context.Context, userIdentity *identity.UserIdentity) string {
+	if state, ok := ctx.Value(ctxAuth).(*authState); ok {
+		return state.aid
+	}
+	if userIdentity == nil {
+		return ""
+	}
+	return userIdentity.GetAID()
+}
+
+// requestedAID resolves the AID a write names in its body against the caller.
+// In token mode the authenticated AID always wins, so naming another AID
+// can't act as them; otherwise the named AID is used if set.
+func requestedAID(r *http.Request, named string, userIdentity *identity.UserIdentity) string {
+	if aid, ok := authenticatedAID(r); ok {
+		return aid
+	}
+	if named != "" {
+		return named
+	}
+	return callerAID(r.Context(), userIdentity)
+}
+
+// authenticatedAID returns the AID proven by a session token in token mode.
+// ok is false in dev mode or outside AuthMiddleware, where the caller's claimed
+// AID (the X-User-AID header) is trusted as before.
+func authenticatedAID(r *http.Request) (aid string, ok bool) {
+	state, found := r.Context().Value(ctxAuth).(*authState)
+	if !found || !state.enforced {
+		return "", false
+	}
+	return state.aid, true
+}
+
+// AuthHandler issues session tokens for the node's local identity.
+type AuthHandler struct {
+	auth         *Authenticator
+	userIdentity *identity.UserIdentity
+}
+
+// NewAuthHandler creates a new auth handler.
+func NewAuthHandler(auth *Authenticator, userIdentity *identity.UserIdentity) *AuthHandler {
+	return &AuthHandler{
+		auth:         auth,
+		userIdentity: userIdentity,
+	}
+}
+
+// CreateSessionRequest is the request body for POST /api/v1/auth/session.
+type CreateSessionRequest struct {
+	AID      string `json:"aid"`
+	Mnemonic string `json:"mnemonic"`
+}
+
+// SessionResponse carries a newly issued session token.
+type SessionResponse struct {
+	Success   bool   `json:"success"`
+	AID       string `json:"aid,omitempty"`
+	Token     string `json:"token,omitempty"`
+	ExpiresAt string `json:"expiresAt,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// HandleCreateSession handles POST /api/v1/auth/session.
+// Issues a session token to a caller that proves control of the local
+// identity by presenting its mnemonic. The phrase is never logged.
+func (h *AuthHandler) HandleCreateSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, SessionResponse{Error: "Method not allowed"})
+		return
+	}
+
+	var req CreateSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		// Don't include the decode error, it may quote the request body
+		writeJSON(w, http.StatusBadRequest, SessionResponse{Error: "invalid request body"})
+		return
+	}
+	if req.AID == "" || req.Mnemonic == "" {
+		writeJSON(w, http.StatusBadRequest, SessionResponse{Error: "aid and mnemonic are required"})
+		return
+	}
+
+	if !h.userIdentity.IsConfigured() {
+		writeJSON(w, http.StatusConflict, SessionResponse{
+			Error: "identity not configured — call POST /api/v1/identity/set first",
+		})
+		return
+	}
+	if h.userIdentity.IsLocked() {
+		writeJSON(w, http.StatusLocked, SessionResponse{Error: errIdentityLocked})
+		return
+	}
+
+	matched := false
+	if req.AID == h.userIdentity.GetAID() {
+		var err error
+		matched, err = mnemonicKeysEqual(strings.Join(strings.Fields(req.Mnemonic), " "), h.userIdentity.GetMnemonic())
+		if err != nil {
+			matched = false
+		}
+	}
+	if !matched {
+		log.Printf("[Auth] Session refused for aid=%s", req.AID[:min(16, len(req.AID))])
+		writeJSON(w, http.StatusUnauthorized, SessionResponse{Error: "invalid credentials"})
+		return
+	}
+
+	h.writeSession(w, req.AID)
+}
+
+// writeSession issues a token for aid and writes it as a SessionResponse.
+func (h *AuthHandler) writeSession(w http.ResponseWriter, aid string) {
+	token, expiresAt, err := h.auth.IssueToken(aid, time.Now())
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, SessionResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, SessionResponse{
+		Success:   true,
+		AID:       aid,
+		Token:     token,
+		ExpiresAt: expiresAt.Format(time.RFC3339),
+	})
+}
+
+// HandleGetSession handles GET /api/v1/auth/session — report who the
+// request is authenticated as.
+func (h *AuthHandler) HandleGetSession(w http.ResponseWriter, r *http.Request) {
+	aid := callerAID(r.Context(), h.userIdentity)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"mode":          h.auth.Mode(),
+		"aid":           aid,
+		"authenticated": aid != "",
+	})
+}
+
+// handleSession routes session requests by method.
+func (h *AuthHandler) handleSession(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.HandleGetSession(w, r)
+	case http.MethodPost:
+		h.HandleCreateSession(w, r)
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+	}
+}
+
+// RegisterRoutes registers auth routes on the mux.
+func (h *AuthHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/api/v1/auth/session", h.handleSession)
+}
@@ -0,0 +1,273 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matou-dao/backend/internal/contributions"
+	"github.com/matou-dao/backend/internal/identity"
+)
+
+func newTestAuthenticator(t *testing.T, mode string) *Authenticator {
+	t.Helper()
+	secret, err := LoadOrCreateAuthSecret(t.TempDir())
+	if err != nil {
+		t.Fatalf("creating auth secret: %v", err)
+	}
+	return NewAuthenticator(secret, mode, func() string { return "ELOCAL" })
+}
+
+// callerEcho responds with the AID the request acts as.
+func callerEcho(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"aid": callerAID(r.Context(), nil)})
+}
+
+func serveAuth(auth *Authenticator, handler http.HandlerFunc, method, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	AuthMiddleware(auth, handler).ServeHTTP(w, req)
+	return w
+}
+
+func TestAuthenticator_VerifyToken(t *testing.T) {
+	auth := newTestAuthenticator(t, AuthModeToken)
+	now := time.Now()
+
+	token, expiresAt, err := auth.IssueToken("EALICE", now)
+	if err != nil {
+		t.Fatalf("issuing token: %v", err)
+	}
+	if !expiresAt.After(now) {
+		t.Errorf("expected expiry after now, got %v", expiresAt)
+	}
+	if aid, err := auth.VerifyToken(token, now); err != nil || aid != "EALICE" {
+		t.Errorf("expected EALICE, got %q, %v", aid, err)
+	}
+
+	if _, err := auth.VerifyToken(token, expiresAt.Add(time.Second)); !errors.Is(err, errTokenExpired) {
+		t.Errorf("expected expired, got %v", err)
+	}
+	if _, err := auth.VerifyToken(token+"x", now); !errors.Is(err, errTokenSignature) {
+		t.Errorf("expected bad signature, got %v", err)
+	}
+	if _, err := auth.VerifyToken("not-a-token", now); !errors.Is(err, errTokenMalformed) {
+		t.Errorf("expected malformed, got %v", err)
+	}
+
+	// A token from another node's secret doesn't verify
+	other := newTestAuthenticator(t, AuthModeToken)
+	if _, err := other.VerifyToken(token, now); !errors.Is(err, errTokenSignature) {
+		t.Errorf("expected bad signature with another secret, got %v", err)
+	}
+}
+
+func TestLoadOrCreateAuthSecret_Persists(t *testing.T) {
+	dir := t.TempDir()
+	first, err := LoadOrCreateAuthSecret(dir)
+	if err != nil {
+		t.Fatalf("creating secret: %v", err)
+	}
+	second, err := LoadOrCreateAuthSecret(dir)
+	if err != nil {
+		t.Fatalf("loading secret: %v", err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Error("expected the secret to survive a restart")
+	}
+}
+
+func TestAuthMiddleware_ValidTokenResolvesAID(t *testing.T) {
+	auth := newTestAuthenticator(t, AuthModeToken)
+	token, _, _ := auth.IssueToken("EALICE", time.Now())
+
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		w := serveAuth(auth, callerEcho, method, "/api/v1/chat/channels", token)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", method, w.Code, w.Body.String())
+		}
+		var resp map[string]string
+		json.NewDecoder(w.Body).Decode(&resp)
+		if resp["aid"] != "EALICE" {
+			t.Errorf("%s: expected the token's AID, got %q", method, resp["aid"])
+		}
+	}
+}
+
+func TestAuthMiddleware_RejectsUnauthenticatedWrites(t *testing.T) {
+	auth := newTestAuthenticator(t, AuthModeToken)
+
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodDelete} {
+		if w := serveAuth(auth, callerEcho, method, "/api/v1/chat/channels", ""); w.Code != http.StatusUnauthorized {
+			t.Errorf("%s without a token: expected 401, got %d", method, w.Code)
+		}
+	}
+	if w := serveAuth(auth, callerEcho, http.MethodPost, "/api/v1/chat/channels", "forged.token"); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for an invalid token, got %d", w.Code)
+	}
+
+	// Reads are anonymous rather than acting as the local identity
+	w := serveAuth(auth, callerEcho, http.MethodGet, "/api/v1/chat/channels", "")
+	var resp map[string]string
+	json.NewDecoder(w.Body).Decode(&resp)
+	if w.Code != http.StatusOK || resp["aid"] != "" {
+		t.Errorf("expected an anonymous read, got %d %v", w.Code, resp)
+	}
+
+	// Getting a token doesn't need one
+	if w := serveAuth(auth, callerEcho, http.MethodPost, "/api/v1/auth/session", ""); w.Code != http.StatusOK {
+		t.Errorf("expected the session endpoint to be exempt, got %d", w.Code)
+	}
+}
+
+func TestAuthMiddleware_IdentitySetNeedsTokenOnceConfigured(t *testing.T) {
+	secret, err := LoadOrCreateAuthSecret(t.TempDir())
+	if err != nil {
+		t.Fatalf("creating auth secret: %v", err)
+	}
+	localAID := ""
+	auth := NewAuthenticator(secret, AuthModeToken, func() string { return localAID })
+	setIdentity := func(remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodPost, identitySetPath, nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		AuthMiddleware(auth, http.HandlerFunc(callerEcho)).ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// First run: only a loopback caller may set the identity without a token
+	if code := setIdentity("127.0.0.1:40000"); code != http.StatusOK {
+		t.Errorf("first run from loopback: expected 200, got %d", code)
+	}
+	if code := setIdentity("192.0.2.1:40000"); code != http.StatusUnauthorized {
+		t.Errorf("first run from a remote address: expected 401, got %d", code)
+	}
+
+	// Once configured, replacing the identity needs a session token
+	localAID = "ELOCAL"
+	if code := setIdentity("127.0.0.1:40000"); code != http.StatusUnauthorized {
+		t.Errorf("configured node without a token: expected 401, got %d", code)
+	}
+}
+
+func TestAuthMiddleware_DevBypass(t *testing.T) {
+	auth := newTestAuthenticator(t, "")
+	if auth.Mode() != AuthModeDev {
+		t.Fatalf("expected dev mode by default, got %s", auth.Mode())
+	}
+
+	w := serveAuth(auth, callerEcho, http.MethodPost, "/api/v1/chat/channels", "")
+	var resp map[string]string
+	json.NewDecoder(w.Body).Decode(&resp)
+	if w.Code != http.StatusOK || resp["aid"] != "ELOCAL" {
+		t.Errorf("expected the write to act as the local identity, got %d %v", w.Code, resp)
+	}
+}
+
+func TestAuthMiddleware_TokenOverridesRBACHeader(t *testing.T) {
+	auth := newTestAuthenticator(t, AuthModeToken)
+	token, _, _ := auth.IssueToken("EALICE", time.Now())
+	lookup := &mockRoleLookup{roles: map[string][]contributions.Role{
+		"EADMIN": {contributions.RoleFoundingMember},
+	}}
+
+	var gotAID string
+	var gotRoles []contributions.Role
+	handler := RBACMiddleware(lookup, func(w http.ResponseWriter, r *http.Request) {
+		gotAID, gotRoles = GetUserAID(r), GetUserRoles(r)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/proposals", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-User-AID", "EADMIN")
+	AuthMiddleware(auth, handler).ServeHTTP(httptest.NewRecorder(), req)
+
+	if gotAID != "EALICE" || len(gotRoles) != 0 {
+		t.Errorf("expected the token's AID without the header's roles, got %s %v", gotAID, gotRoles)
+	}
+}
+
+func TestChat_SendMessageActsAsTokenCaller(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+	channelID := createTestChannel(t, env, "authed")
+
+	auth := newTestAuthenticator(t, AuthModeToken)
+	handler := AuthMiddleware(auth, env.mux)
+	send := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/chat/channels/"+channelID+"/messages", bytes.NewBufferString(`{"content":"hi"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send(""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without a token, got %d", w.Code)
+	}
+
+	token, _, _ := auth.IssueToken("ETEST_CHAT_USER02", time.Now())
+	w := send(token)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected the message to send, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		MessageID string `json:"messageId"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+
+	obj, err := env.spaceManager.ObjectTreeManager().ReadLatestByID(context.Background(), env.spaceManager.GetCommunitySpaceID(), resp.MessageID)
+	if err != nil {
+		t.Fatalf("reading message %q: %v", resp.MessageID, err)
+	}
+	var data ChatMessageData
+	json.Unmarshal(obj.Data, &data)
+	if data.SenderAID != "ETEST_CHAT_USER02" {
+		t.Errorf("expected the message sent as the token's AID, got %s", data.SenderAID)
+	}
+}
+
+func TestAuthHandler_CreateSession(t *testing.T) {
+	userIdentity := identity.New(t.TempDir())
+	if err := userIdentity.SetIdentity("EUSER123", testBackupMnemonic); err != nil {
+		t.Fatalf("setting identity: %v", err)
+	}
+	auth := newTestAuthenticator(t, AuthModeToken)
+	handler := NewAuthHandler(auth, userIdentity)
+
+	post := func(body string) (*httptest.ResponseRecorder, SessionResponse) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/session", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		handler.HandleCreateSession(w, req)
+		var resp SessionResponse
+		json.NewDecoder(bytes.NewReader(w.Body.Bytes())).Decode(&resp)
+		return w, resp
+	}
+
+	w, resp := post(`{"aid":"EUSER123","mnemonic":"` + testBackupMnemonic + `"}`)
+	if w.Code != http.StatusOK || resp.Token == "" {
+		t.Fatalf("expected a token, got %d: %s", w.Code, w.Body.String())
+	}
+	if aid, err := auth.VerifyToken(resp.Token, time.Now()); err != nil || aid != "EUSER123" {
+		t.Errorf("expected a token for EUSER123, got %q, %v", aid, err)
+	}
+
+	wrong := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	if w, _ := post(`{"aid":"EUSER123","mnemonic":"` + wrong + `"}`); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for the wrong mnemonic, got %d", w.Code)
+	}
+	if w, _ := post(`{"aid":"EOTHER","mnemonic":"` + testBackupMnemonic + `"}`); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for another AID, got %d", w.Code)
+	}
+}
@@ -0,0 +1,216 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/anyproto/any-sync/util/crypto"
+	"github.com/ipfs/go-cid"
+	"github.com/matou-dao/backend/internal/anysync"
+)
+
+// defaultMaxAvatarSize caps avatar and logo images. Profiles reference them
+// by fileRef and every member list loads them, so they stay small.
+const defaultMaxAvatarSize = 2 << 20 // 2 MB
+
+// errAvatarTooLarge is returned by validateAvatarImage for an image over the
+// size limit.
+var errAvatarTooLarge = errors.New("avatar image is too large")
+
+// validateAvatarImage checks that data is a PNG, JPEG or GIF image of at most
+// maxSize bytes and returns its media type, taken from the decoded format
+// rather than any declared type.
+func validateAvatarImage(data []byte, maxSize int64) (string, error) {
+	if len(data) == 0 {
+		return "", fmt.Errorf("avatar image is empty")
+	}
+	if int64(len(data)) > maxSize {
+		return "", fmt.Errorf("%w: %d bytes exceeds the %d byte limit", errAvatarTooLarge, len(data), maxSize)
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("avatar is not a supported image: %w", err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return "", fmt.Errorf("avatar image has no pixels")
+	}
+	return "image/" + format, nil
+}
+
+// decodeBase64Avatar decodes and validates an inline avatar, as sent during
+// onboarding before there is a space to upload to. A declared mimeType must
+// be an image type, but the stored type comes from the image itself.
+func decodeBase64Avatar(base64Data, mimeType string, maxSize int64) ([]byte, string, error) {
+	if mimeType != "" && !strings.HasPrefix(mimeType, "image/") {
+		return nil, "", fmt.Errorf("only image files are accepted, got %s", mimeType)
+	}
+	// Reject oversize data before decoding it
+	if int64(base64.StdEncoding.DecodedLen(len(base64Data))) > maxSize+2 {
+		return nil, "", fmt.Errorf("%w: exceeds the %d byte limit", errAvatarTooLarge, maxSize)
+	}
+	data, err := base64.StdEncoding.DecodeString(base64Data)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode base64 data: %w", err)
+	}
+	contentType, err := validateAvatarImage(data, maxSize)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, contentType, nil
+}
+
+// storeAvatar uploads a validated avatar image to the filenode and returns its
+// fileRef. It's the fallback for inline avatars (decodeBase64Avatar) sent when
+// the normal upload couldn't run because the community space didn't exist
+// yet, e.g. during onboarding. Retries with backoff because the filenode peer
+// pool may not be connected yet after an SDK reinit (which happens during
+// identity setup just before this).
+func storeAvatar(ctx context.Context, fileManager *anysync.FileManager, spaceID string, signingKey crypto.PrivKey, data []byte, contentType string) (string, error) {
+	if fileManager == nil {
+		return "", fmt.Errorf("file manager not available")
+	}
+	if spaceID == "" {
+		return "", fmt.Errorf("space ID is required")
+	}
+
+	const maxAttempts = 5
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		fileRef, err := fileManager.AddFile(
+			ctx,
+			spaceID,
+			bytes.NewReader(data),
+			contentType,
+			int64(len(data)),
+			signingKey,
+		)
+		if err == nil {
+			return fileRef, nil
+		}
+		lastErr = err
+		if attempt < maxAttempts {
+			delay := time.Duration(attempt) * 2 * time.Second
+			fmt.Printf("[storeAvatar] attempt %d/%d failed: %v — retrying in %v\n", attempt, maxAttempts, err, delay)
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+		}
+	}
+
+	return "", fmt.Errorf("failed to upload avatar after %d attempts: %w", maxAttempts, lastErr)
+}
+
+// checkAvatarRef checks that ref can be stored as an avatar or logo: empty,
+// unchanged from previous (which keeps URLs stored before avatars had to be
+// uploaded), or the fileRef of an image in files under spaceID. New URLs and
+// inline data are refused. files is nil while there is nowhere to look the
+// file up, e.g. before the community space exists; then only the CID is
+// checked.
+func checkAvatarRef(ctx context.Context, files fileStore, spaceID, ref, previous string) error {
+	if ref == "" || ref == previous {
+		return nil
+	}
+	if files == nil {
+		if _, err := cid.Decode(ref); err != nil {
+			return fmt.Errorf("invalid fileRef %q (not a valid CID)", ref)
+		}
+		return nil
+	}
+	_, err := imageFileMeta(ctx, files, spaceID, ref)
+	return err
+}
+
+// avatarFiles returns fileManager as the fileStore checkAvatarRef looks refs
+// up in, or nil if there is no file manager.
+func avatarFiles(fileManager *anysync.FileManager) fileStore {
+	if fileManager == nil {
+		return nil
+	}
+	return fileManager
+}
+
+// imageFileMeta resolves ref's file in files and checks it is an image.
+func imageFileMeta(ctx context.Context, files fileStore, spaceID, ref string) (*anysync.FileMeta, error) {
+	if _, err := cid.Decode(ref); err != nil {
+		return nil, fmt.Errorf("invalid fileRef %q (not a valid CID)", ref)
+	}
+	meta, err := files.GetFileMeta(ctx, spaceID, ref)
+	if err != nil {
+		return nil, fmt.Errorf("file %s not found", ref)
+	}
+	if !strings.HasPrefix(meta.ContentType, "image/") {
+		return nil, fmt.Errorf("file %s is %s, not an image", ref, meta.ContentType)
+	}
+	return meta, nil
+}
+
+// avatarErrorStatus is the HTTP status for an avatar that failed validation.
+func avatarErrorStatus(err error) int {
+	if errors.Is(err, errAvatarTooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// HandleAvatarUpload handles POST /api/v1/files/avatar
+// Accepts a multipart image upload of at most defaultMaxAvatarSize, checks it
+// decodes as an image, and stores it on the filenode. The returned fileRef is
+// what profiles store in their avatar (or logo) field.
+func (h *FilesHandler) HandleAvatarUpload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		return
+	}
+
+	if h.files == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "file storage not available (filenode not configured)",
+		})
+		return
+	}
+
+	data, header, ok := readUploadedFile(w, r, h.avatarSize)
+	if !ok {
+		return
+	}
+	contentType, err := validateAvatarImage(data, h.avatarSize)
+	if err != nil {
+		writeJSON(w, avatarErrorStatus(err), map[string]string{"error": err.Error()})
+		return
+	}
+
+	spaceID := h.spaceManager.GetCommunitySpaceID()
+	if spaceID == "" {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "community space not configured",
+		})
+		return
+	}
+	signingKey := h.spaceManager.GetClient().GetSigningKey()
+
+	thumbnailRef := h.uploadThumbnail(r.Context(), spaceID, contentType, data, signingKey)
+	fileRef, err := h.files.AddFileWithThumbnail(r.Context(), spaceID, bytes.NewReader(data), contentType, int64(len(data)), thumbnailRef, signingKey)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("failed to upload avatar: %v", err),
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, AttachmentRef{
+		FileRef:      fileRef,
+		FileName:     header.Filename,
+		ContentType:  contentType,
+		Size:         int64(len(data)),
+		ThumbnailRef: thumbnailRef,
+	})
+}
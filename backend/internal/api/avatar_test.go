@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func postAvatar(t *testing.T, mux *http.ServeMux, filename, contentType string, data []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	req := multipartUpload(t, filename, contentType, data)
+	req.URL.Path = "/api/v1/files/avatar"
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	return w
+}
+
+func TestAvatarUpload_StoresImageAsFileRef(t *testing.T) {
+	handler, store := setupFilesTestHandler(t)
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	// Declared as a generic type; the stored type comes from the image
+	data := testPNG(t, 64, 64)
+	w := postAvatar(t, mux, "me.png", "application/octet-stream", data)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var ref AttachmentRef
+	json.NewDecoder(w.Body).Decode(&ref)
+	if ref.ContentType != "image/png" || ref.Size != int64(len(data)) {
+		t.Errorf("unexpected ref: %+v", ref)
+	}
+	if string(store.files[ref.FileRef]) != string(data) || store.contentTypes[ref.FileRef] != "image/png" {
+		t.Errorf("avatar not stored under its fileRef %s", ref.FileRef)
+	}
+
+	// The ref is what a profile accepts as its avatar; anything else is
+	// refused unless the profile already stores it
+	ctx := context.Background()
+	textRef, _ := store.AddFile(ctx, "test-community-space", strings.NewReader("hello"), "text/plain", 5, nil)
+	unknownRef, _ := newMockFileStore().AddFile(ctx, "test-community-space", strings.NewReader("elsewhere"), "image/png", 9, nil)
+	legacyURL := "https://example.com/me.png"
+	inline := "data:image/png;base64," + base64.StdEncoding.EncodeToString(data)
+	for _, tt := range []struct {
+		name, ref, previous string
+		ok                  bool
+	}{
+		{"uploaded image", ref.FileRef, "", true},
+		{"empty", "", legacyURL, true},
+		{"unchanged legacy url", legacyURL, legacyURL, true},
+		{"new url", legacyURL, "", false},
+		{"inline data", inline, "", false},
+		{"not an image", textRef, "", false},
+		{"not uploaded", unknownRef, "", false},
+	} {
+		if err := checkAvatarRef(ctx, store, "test-community-space", tt.ref, tt.previous); (err == nil) != tt.ok {
+			t.Errorf("%s: expected ok=%v, got %v", tt.name, tt.ok, err)
+		}
+	}
+}
+
+func TestAvatarUpload_RejectsOversizeAndNonImages(t *testing.T) {
+	handler, store := setupFilesTestHandler(t)
+	handler.avatarSize = 1024
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	big := testPNG(t, 256, 256)
+	if len(big) <= 1024 {
+		t.Fatalf("test image is only %d bytes", len(big))
+	}
+	if w := postAvatar(t, mux, "big.png", "image/png", big); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("oversize: expected 413, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := postAvatar(t, mux, "avatar.png", "image/png", []byte("<svg onload=alert(1)>")); w.Code != http.StatusBadRequest {
+		t.Errorf("non-image: expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(store.files) != 0 {
+		t.Errorf("expected nothing stored, got %d files", len(store.files))
+	}
+
+	// The inline onboarding path applies the same checks
+	encoded := base64.StdEncoding.EncodeToString(big)
+	if _, _, err := decodeBase64Avatar(encoded, "image/png", 1024); !errors.Is(err, errAvatarTooLarge) {
+		t.Errorf("inline oversize: expected errAvatarTooLarge, got %v", err)
+	}
+	if _, contentType, err := decodeBase64Avatar(encoded, "", defaultMaxAvatarSize); err != nil || contentType != "image/png" {
+		t.Errorf("inline avatar: got %q, %v", contentType, err)
+	}
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"context"
+	"time"
+)
+
+// backgroundLoop runs a task on a ticker until stopped. The zero value is
+// ready to start; stop is a no-op if the loop never started.
+type backgroundLoop struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// start runs tick every interval on a new goroutine. The context passed to
+// tick is cancelled by stop.
+func (l *backgroundLoop) start(interval time.Duration, tick func(ctx context.Context, now time.Time)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	l.cancel = cancel
+	l.done = make(chan struct{})
+
+	go func() {
+		defer close(l.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				tick(ctx, now)
+			}
+		}
+	}()
+}
+
+// stop cancels the loop and waits for a tick in progress to return.
+func (l *backgroundLoop) stop() {
+	if l.cancel != nil {
+		l.cancel()
+	}
+	if l.done != nil {
+		<-l.done
+	}
+}
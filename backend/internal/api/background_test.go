@@ -0,0 +1,45 @@
+package api
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBackgroundLoop_TicksUntilStopped(t *testing.T) {
+	var never backgroundLoop
+	never.stop() // a loop that never started stops without blocking
+
+	var loop backgroundLoop
+	var ticks atomic.Int32
+	cancelled := make(chan struct{})
+	loop.start(5*time.Millisecond, func(ctx context.Context, _ time.Time) {
+		if ticks.Add(1) == 1 {
+			go func() {
+				<-ctx.Done()
+				close(cancelled)
+			}()
+		}
+	})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for ticks.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if ticks.Load() < 2 {
+		t.Fatalf("expected at least 2 ticks, got %d", ticks.Load())
+	}
+
+	loop.stop()
+	select {
+	case <-cancelled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected stop to cancel the tick context")
+	}
+	after := ticks.Load()
+	time.Sleep(20 * time.Millisecond)
+	if got := ticks.Load(); got != after {
+		t.Errorf("expected no ticks after stop, got %d more", got-after)
+	}
+}
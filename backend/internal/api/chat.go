@@ -3,15 +3,22 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/matou-dao/backend/internal/anysync"
 	"github.com/matou-dao/backend/internal/anystore"
+	"github.com/matou-dao/backend/internal/contributions"
 	"github.com/matou-dao/backend/internal/identity"
+	"github.com/matou-dao/backend/internal/keri"
+	"github.com/matou-dao/backend/pkg/client"
 )
 
 // ChatHandler handles chat channel and message HTTP requests.
@@ -21,6 +28,24 @@ type ChatHandler struct {
 	eventBroker  *EventBroker
 	store        *anystore.LocalStore
 	chatListener *anysync.TreeUpdateListener
+	commands     *CommandRegistry
+	idempotency  *IdempotencyGuard
+	profiles     *ProfileResolver
+	outbox       *WriteOutbox
+	files        fileStore   // resolves attachment refs (see SetFileStore)
+	quarantine   *Quarantine // records objects skipped on read (see SetQuarantine)
+
+	maxMessageBytes int // 0 uses DefaultMaxMessageBytes
+
+	// roleLookup resolves the caller's roles for channel AllowedRoles; set by
+	// RegisterAdminRoutes
+	roleLookup RoleLookup
+
+	// exportChannel serves channel exports; set by RegisterAdminRoutes,
+	// which wraps HandleExportChannel in the admin role check
+	exportChannel http.HandlerFunc
+
+	loop backgroundLoop // retention sweeper (see StartRetentionSweeper)
 }
 
 // NewChatHandler creates a new chat handler.
@@ -31,12 +56,68 @@ func NewChatHandler(
 	store *anystore.LocalStore,
 	chatListener *anysync.TreeUpdateListener,
 ) *ChatHandler {
+	if store == nil {
+		log.Printf("[Chat] Running without the local chat index; reads fall back to object trees")
+	}
 	return &ChatHandler{
 		spaceManager: spaceManager,
 		userIdentity: userIdentity,
 		eventBroker:  eventBroker,
 		store:        store,
 		chatListener: chatListener,
+		commands:     NewCommandRegistry(),
+		profiles:     NewProfileResolver(spaceManager),
+	}
+}
+
+// SetProfileResolver shares a profile resolver (and its cache) with other
+// handlers. By default the handler uses its own.
+func (h *ChatHandler) SetProfileResolver(p *ProfileResolver) {
+	h.profiles = p
+}
+
+// SetIdempotencyGuard enables Idempotency-Key handling on channel and message creation.
+func (h *ChatHandler) SetIdempotencyGuard(g *IdempotencyGuard) {
+	h.idempotency = g
+}
+
+// SetWriteOutbox queues messages whose tree write fails for retry, instead
+// of failing the send.
+func (h *ChatHandler) SetWriteOutbox(o *WriteOutbox) {
+	h.outbox = o
+}
+
+// SetMaxMessageBytes sets the largest message content accepted, in UTF-8
+// bytes. Zero uses DefaultMaxMessageBytes.
+func (h *ChatHandler) SetMaxMessageBytes(n int) {
+	h.maxMessageBytes = n
+}
+
+// RegisterCommand adds a slash command (see CommandRegistry.Register).
+func (h *ChatHandler) RegisterCommand(name string, handler CommandHandler) error {
+	return h.commands.Register(name, handler)
+}
+
+// writeTreeError reports a failed object tree read or write. Refused writes
+// are 403 and version conflicts 409. A tree node that can't be reached is
+// transient: without the local chat index there is nothing else to serve
+// from, so it is reported as 503 with status "degraded". Anything else is a
+// plain 500.
+func (h *ChatHandler) writeTreeError(w http.ResponseWriter, action string, err error) {
+	msg := fmt.Sprintf("%s: %v", action, err)
+	switch {
+	case errors.Is(err, anysync.ErrReadOnlySpace):
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": msg})
+	case errors.Is(err, anysync.ErrVersionConflict):
+		writeJSON(w, http.StatusConflict, map[string]string{"error": msg})
+	case h.store == nil && errors.Is(anysync.ClassifyError(err), anysync.ErrUnreachable):
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error":  msg,
+			"status": HealthStatusDegraded,
+			"detail": "local chat index unavailable",
+		})
+	default:
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": msg})
 	}
 }
 
@@ -52,6 +133,12 @@ type ChatChannelData struct {
 	CreatedBy    string   `json:"createdBy"`
 	IsArchived   bool     `json:"isArchived,omitempty"`
 	AllowedRoles []string `json:"allowedRoles,omitempty"`
+	// RetentionDays expires messages this many days after they were sent;
+	// 0 keeps them forever (see chat_retention.go)
+	RetentionDays int `json:"retentionDays,omitempty"`
+	// MergedInto is set when the channel was archived by merging it into
+	// another channel (see chat_merge.go)
+	MergedInto string `json:"mergedInto,omitempty"`
 }
 
 // ChatMessageData represents a chat message stored in the community space.
@@ -65,21 +152,20 @@ type ChatMessageData struct {
 	SentAt      string          `json:"sentAt"`
 	EditedAt    string          `json:"editedAt,omitempty"`
 	DeletedAt   string          `json:"deletedAt,omitempty"`
-}
-
-// AttachmentRef represents a file attachment reference.
-type AttachmentRef struct {
-	FileRef     string `json:"fileRef"`
-	FileName    string `json:"fileName"`
-	ContentType string `json:"contentType"`
-	Size        int64  `json:"size"`
+	// Kind and Payload are set by slash commands (see CommandRegistry)
+	Kind    string          `json:"kind,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
 }
 
 // MessageReactionData represents reactions on a message.
 type MessageReactionData struct {
-	MessageID   string   `json:"messageId"`
-	Emoji       string   `json:"emoji"`
-	ReactorAIDs []string `json:"reactorAids"`
+	MessageID      string   `json:"messageId"`
+	Emoji          string   `json:"emoji"`
+	ReactorAIDs    []string `json:"reactorAids"`
+	FirstReactedAt string   `json:"firstReactedAt,omitempty"` // when the current run of reactors began
+	// RemovedAt tombstones the reaction once its last reactor has left, so
+	// readers skip it instead of showing a zero count. Cleared on re-react.
+	RemovedAt string `json:"removedAt,omitempty"`
 }
 
 // ReadCursorsData stores per-channel read cursor timestamps.
@@ -89,87 +175,39 @@ type ReadCursorsData struct {
 
 // --- Request/Response Types ---
 
-// CreateChannelRequest is the request body for creating a channel.
-type CreateChannelRequest struct {
-	Name         string   `json:"name"`
-	Description  string   `json:"description,omitempty"`
-	Icon         string   `json:"icon,omitempty"`
-	Photo        string   `json:"photo,omitempty"`
-	AllowedRoles []string `json:"allowedRoles,omitempty"`
-}
-
-// UpdateChannelRequest is the request body for updating a channel.
-type UpdateChannelRequest struct {
-	Name         *string   `json:"name,omitempty"`
-	Description  *string   `json:"description,omitempty"`
-	Icon         *string   `json:"icon,omitempty"`
-	Photo        *string   `json:"photo,omitempty"`
-	AllowedRoles *[]string `json:"allowedRoles,omitempty"`
-}
-
-// SendMessageRequest is the request body for sending a message.
-type SendMessageRequest struct {
-	Content     string          `json:"content"`
-	Attachments []AttachmentRef `json:"attachments,omitempty"`
-	ReplyTo     string          `json:"replyTo,omitempty"`
-}
-
-// EditMessageRequest is the request body for editing a message.
-type EditMessageRequest struct {
-	Content string `json:"content"`
-}
-
-// AddReactionRequest is the request body for adding a reaction.
-type AddReactionRequest struct {
-	Emoji string `json:"emoji"`
-}
-
-// UpdateReadCursorRequest is the request body for updating a read cursor.
-type UpdateReadCursorRequest struct {
-	ChannelID  string `json:"channelId"`
-	LastReadAt string `json:"lastReadAt"`
-}
-
-// ChannelResponse is the response for a single channel.
-type ChannelResponse struct {
-	ID           string   `json:"id"`
-	Name         string   `json:"name"`
-	Description  string   `json:"description,omitempty"`
-	Icon         string   `json:"icon,omitempty"`
-	Photo        string   `json:"photo,omitempty"`
-	CreatedAt    string   `json:"createdAt"`
-	CreatedBy    string   `json:"createdBy"`
-	IsArchived   bool     `json:"isArchived,omitempty"`
-	AllowedRoles []string `json:"allowedRoles,omitempty"`
-}
-
-// MessageResponse is the response for a single message.
-type MessageResponse struct {
-	ID          string              `json:"id"`
-	ChannelID   string              `json:"channelId"`
-	SenderAID   string              `json:"senderAid"`
-	SenderName  string              `json:"senderName"`
-	Content     string              `json:"content"`
-	Attachments []AttachmentRef     `json:"attachments,omitempty"`
-	ReplyTo     string              `json:"replyTo,omitempty"`
-	SentAt      string              `json:"sentAt"`
-	EditedAt    string              `json:"editedAt,omitempty"`
-	DeletedAt   string              `json:"deletedAt,omitempty"`
-	Reactions   []ReactionAggregate `json:"reactions,omitempty"`
-	Version     int                 `json:"version"`
-}
+// The chat request and response bodies are defined in pkg/client, so Go
+// API clients share them with the handlers.
+type (
+	AttachmentRef           = client.AttachmentRef
+	CreateChannelRequest    = client.CreateChannelRequest
+	UpdateChannelRequest    = client.UpdateChannelRequest
+	SendMessageRequest      = client.SendMessageRequest
+	EditMessageRequest      = client.EditMessageRequest
+	AddReactionRequest      = client.AddReactionRequest
+	UpdateReadCursorRequest = client.UpdateReadCursorRequest
+	ChannelResponse         = client.ChannelResponse
+	MessageResponse         = client.MessageResponse
+	ReactionAggregate       = client.ReactionAggregate
+)
 
-// ReactionAggregate is an aggregated view of reactions for a message.
-type ReactionAggregate struct {
-	Emoji       string   `json:"emoji"`
-	Count       int      `json:"count"`
-	ReactorAIDs []string `json:"reactorAids"`
-	HasReacted  bool     `json:"hasReacted"`
+// redactIfDeleted turns a soft-deleted message into a tombstone: its content,
+// attachments, payload and reactions are dropped, keeping the sender and
+// deletedAt so clients can render "message deleted".
+func redactIfDeleted(m *MessageResponse) {
+	if m.DeletedAt == "" {
+		return
+	}
+	m.Content = ""
+	m.Attachments = nil
+	m.Payload = nil
+	m.Reactions = nil
 }
 
 // --- Channel Handlers ---
 
-// HandleListChannels handles GET /api/v1/chat/channels — list all channels.
+// HandleListChannels handles GET /api/v1/chat/channels — list all channels,
+// oldest first, paginated via ?limit=N&offset=M; without a limit all are
+// returned.
 func (h *ChatHandler) HandleListChannels(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
@@ -193,13 +231,30 @@ func (h *ChatHandler) HandleListChannels(w http.ResponseWriter, r *http.Request)
 	objMgr := h.spaceManager.ObjectTreeManager()
 	objects, err := objMgr.ReadObjectsByType(ctx, communitySpaceID, "ChatChannel")
 	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": fmt.Sprintf("failed to read channels: %v", err),
+		h.writeTreeError(w, "failed to read channels", err)
+		return
+	}
+
+	// ?membership=joined|available filters on whether the user has joined
+	membership := r.URL.Query().Get("membership")
+	if membership != "" && membership != "joined" && membership != "available" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "membership must be \"joined\" or \"available\"",
 		})
 		return
 	}
+	limit, ok := parseListLimit(w, r, 0, maxListPageSize)
+	if !ok {
+		return
+	}
+	offset, ok := parseListOffset(w, r)
+	if !ok {
+		return
+	}
+	currentAID := callerAID(r.Context(), h.userIdentity)
+	members := ChannelMembers(ctx, h.spaceManager, communitySpaceID)
 
-	userRole := h.getUserRole()
+	roles := h.callerRoles(ctx)
 	type channelEntry struct {
 		obj  *anysync.ObjectPayload
 		data ChatChannelData
@@ -207,7 +262,7 @@ func (h *ChatHandler) HandleListChannels(w http.ResponseWriter, r *http.Request)
 	latestByID := make(map[string]*channelEntry)
 	for _, obj := range objects {
 		var data ChatChannelData
-		if err := json.Unmarshal(obj.Data, &data); err != nil {
+		if err := decodeObjectData(h.quarantine, communitySpaceID, obj, &data); err != nil {
 			continue
 		}
 		if existing, ok := latestByID[obj.ID]; !ok || obj.Version > existing.obj.Version {
@@ -217,26 +272,41 @@ func (h *ChatHandler) HandleListChannels(w http.ResponseWriter, r *http.Request)
 
 	channels := make([]ChannelResponse, 0, len(latestByID))
 	for _, entry := range latestByID {
-		if len(entry.data.AllowedRoles) > 0 && !containsRole(entry.data.AllowedRoles, userRole) {
+		if !holdsChannelRole(entry.data.AllowedRoles, roles) {
 			continue
 		}
 		if entry.data.IsArchived && r.URL.Query().Get("includeArchived") != "true" {
 			continue
 		}
+		joined := slices.Contains(members[entry.obj.ID], currentAID)
+		if (membership == "joined" && !joined) || (membership == "available" && joined) {
+			continue
+		}
 		channels = append(channels, ChannelResponse{
-			ID:           entry.obj.ID,
-			Name:         entry.data.Name,
-			Description:  entry.data.Description,
-			Icon:         entry.data.Icon,
-			Photo:        entry.data.Photo,
-			CreatedAt:    entry.data.CreatedAt,
-			CreatedBy:    entry.data.CreatedBy,
-			IsArchived:   entry.data.IsArchived,
-			AllowedRoles: entry.data.AllowedRoles,
-		})
-	}
+			ID:            entry.obj.ID,
+			Name:          entry.data.Name,
+			Description:   entry.data.Description,
+			Icon:          entry.data.Icon,
+			Photo:         entry.data.Photo,
+			CreatedAt:     entry.data.CreatedAt,
+			CreatedBy:     entry.data.CreatedBy,
+			IsArchived:    entry.data.IsArchived,
+			AllowedRoles:  entry.data.AllowedRoles,
+			RetentionDays: entry.data.RetentionDays,
+			MergedInto:    entry.data.MergedInto,
+			Joined:        joined,
+			Version:       entry.obj.Version,
+		})
+	}
+	slices.SortFunc(channels, func(a, b ChannelResponse) int {
+		if c := strings.Compare(a.CreatedAt, b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
+	channels, page := offsetPage(channels, offset, limit)
+	writeListJSON(w, page, map[string]interface{}{
 		"channels": channels,
 		"count":    len(channels),
 	})
@@ -269,21 +339,24 @@ func (h *ChatHandler) HandleGetChannel(w http.ResponseWriter, r *http.Request) {
 	if h.store != nil {
 		ch, err := h.store.GetChannel(ctx, channelID)
 		if err == nil {
-			userRole := h.getUserRole()
-			if len(ch.AllowedRoles) > 0 && !containsRole(ch.AllowedRoles, userRole) {
+			if !holdsChannelRole(ch.AllowedRoles, h.callerRoles(ctx)) {
 				writeJSON(w, http.StatusForbidden, map[string]string{"error": "access denied"})
 				return
 			}
 			writeJSON(w, http.StatusOK, ChannelResponse{
-				ID:           ch.ID,
-				Name:         ch.Name,
-				Description:  ch.Description,
-				Icon:         ch.Icon,
-				Photo:        ch.Photo,
-				CreatedAt:    ch.CreatedAt,
-				CreatedBy:    ch.CreatedBy,
-				IsArchived:   ch.IsArchived,
-				AllowedRoles: ch.AllowedRoles,
+				ID:            ch.ID,
+				Name:          ch.Name,
+				Description:   ch.Description,
+				Icon:          ch.Icon,
+				Photo:         ch.Photo,
+				CreatedAt:     ch.CreatedAt,
+				CreatedBy:     ch.CreatedBy,
+				IsArchived:    ch.IsArchived,
+				AllowedRoles:  ch.AllowedRoles,
+				RetentionDays: ch.RetentionDays,
+				MergedInto:    ch.MergedInto,
+				Joined:        h.isChannelMember(ctx, communitySpaceID, ch.ID),
+				Version:       ch.Version,
 			})
 			return
 		}
@@ -307,26 +380,31 @@ func (h *ChatHandler) HandleGetChannel(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userRole := h.getUserRole()
-	if len(data.AllowedRoles) > 0 && !containsRole(data.AllowedRoles, userRole) {
+	if !holdsChannelRole(data.AllowedRoles, h.callerRoles(ctx)) {
 		writeJSON(w, http.StatusForbidden, map[string]string{"error": "access denied"})
 		return
 	}
 
 	writeJSON(w, http.StatusOK, ChannelResponse{
-		ID:           obj.ID,
-		Name:         data.Name,
-		Description:  data.Description,
-		Icon:         data.Icon,
-		Photo:        data.Photo,
-		CreatedAt:    data.CreatedAt,
-		CreatedBy:    data.CreatedBy,
-		IsArchived:   data.IsArchived,
-		AllowedRoles: data.AllowedRoles,
+		ID:            obj.ID,
+		Name:          data.Name,
+		Description:   data.Description,
+		Icon:          data.Icon,
+		Photo:         data.Photo,
+		CreatedAt:     data.CreatedAt,
+		CreatedBy:     data.CreatedBy,
+		IsArchived:    data.IsArchived,
+		AllowedRoles:  data.AllowedRoles,
+		RetentionDays: data.RetentionDays,
+		MergedInto:    data.MergedInto,
+		Joined:        h.isChannelMember(ctx, communitySpaceID, obj.ID),
+		Version:       obj.Version,
 	})
 }
 
 // HandleCreateChannel handles POST /api/v1/chat/channels — create a new channel.
+// A request without allowedRoles gets the admin settings' DefaultChannelRoles;
+// an explicit empty list leaves the channel open.
 func (h *ChatHandler) HandleCreateChannel(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
@@ -345,6 +423,19 @@ func (h *ChatHandler) HandleCreateChannel(w http.ResponseWriter, r *http.Request
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "name is required"})
 		return
 	}
+	if req.RetentionDays < 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "retentionDays must not be negative"})
+		return
+	}
+	if req.AllowedRoles == nil {
+		req.AllowedRoles = h.defaultChannelRoles(r.Context())
+	}
+	allowedRoles, err := canonicalAllowedRoles(req.AllowedRoles)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	req.AllowedRoles = allowedRoles
 
 	communitySpaceID := h.spaceManager.GetCommunitySpaceID()
 	if communitySpaceID == "" {
@@ -354,20 +445,18 @@ func (h *ChatHandler) HandleCreateChannel(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	aid := ""
-	if h.userIdentity != nil {
-		aid = h.userIdentity.GetAID()
-	}
+	aid := callerAID(r.Context(), h.userIdentity)
 
 	now := time.Now().UTC().Format(time.RFC3339)
 	channelData := ChatChannelData{
-		Name:         req.Name,
-		Description:  req.Description,
-		Icon:         req.Icon,
-		Photo:        req.Photo,
-		CreatedAt:    now,
-		CreatedBy:    aid,
-		AllowedRoles: req.AllowedRoles,
+		Name:          req.Name,
+		Description:   req.Description,
+		Icon:          req.Icon,
+		Photo:         req.Photo,
+		CreatedAt:     now,
+		CreatedBy:     aid,
+		AllowedRoles:  req.AllowedRoles,
+		RetentionDays: req.RetentionDays,
 	}
 
 	dataBytes, err := json.Marshal(channelData)
@@ -395,7 +484,7 @@ func (h *ChatHandler) HandleCreateChannel(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	objectID := fmt.Sprintf("ChatChannel-%d", time.Now().UnixNano())
+	objectID := newObjectID("ChatChannel", aid)
 	ownerKey := ""
 	if keys.SigningKey != nil {
 		pubKeyBytes, _ := keys.SigningKey.GetPublic().Marshall()
@@ -428,6 +517,14 @@ func (h *ChatHandler) HandleCreateChannel(w http.ResponseWriter, r *http.Request
 		h.chatListener.RegisterObject(payload)
 	}
 
+	// The creator joins their own channel
+	if aid != "" {
+		membership := ChannelMembershipData{ChannelID: objectID, MemberAID: aid, JoinedAt: now}
+		if err := h.saveChannelMembership(ctx, communitySpaceID, membership, 1); err != nil {
+			log.Printf("[Chat] Failed to join created channel %s: %v", objectID, err)
+		}
+	}
+
 	// Broadcast channel creation event
 	h.eventBroker.Broadcast(SSEEvent{
 		Type: "chat:channel:new",
@@ -464,6 +561,18 @@ func (h *ChatHandler) HandleUpdateChannel(w http.ResponseWriter, r *http.Request
 		})
 		return
 	}
+	if req.RetentionDays != nil && *req.RetentionDays < 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "retentionDays must not be negative"})
+		return
+	}
+	if req.AllowedRoles != nil {
+		allowedRoles, err := canonicalAllowedRoles(*req.AllowedRoles)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		req.AllowedRoles = &allowedRoles
+	}
 
 	communitySpaceID := h.spaceManager.GetCommunitySpaceID()
 	if communitySpaceID == "" {
@@ -485,6 +594,12 @@ func (h *ChatHandler) HandleUpdateChannel(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	if req.Version != nil && *req.Version != existing.Version {
+		err := fmt.Errorf("%w: %s is at version %d, edit was made against %d", anysync.ErrVersionConflict, channelID, existing.Version, *req.Version)
+		writeVersionConflict(w, err, existing)
+		return
+	}
+
 	var data ChatChannelData
 	if err := json.Unmarshal(existing.Data, &data); err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{
@@ -509,6 +624,9 @@ func (h *ChatHandler) HandleUpdateChannel(w http.ResponseWriter, r *http.Request
 	if req.AllowedRoles != nil {
 		data.AllowedRoles = *req.AllowedRoles
 	}
+	if req.RetentionDays != nil {
+		data.RetentionDays = *req.RetentionDays
+	}
 
 	dataBytes, err := json.Marshal(data)
 	if err != nil {
@@ -546,6 +664,10 @@ func (h *ChatHandler) HandleUpdateChannel(w http.ResponseWriter, r *http.Request
 	}
 
 	headID, err := objMgr.AddObject(ctx, communitySpaceID, payload, keys.SigningKey)
+	if errors.Is(err, anysync.ErrVersionConflict) {
+		writeVersionConflict(w, err, h.currentObject(ctx, communitySpaceID, channelID))
+		return
+	}
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{
 			"error": fmt.Sprintf("failed to update channel: %v", err),
@@ -574,6 +696,16 @@ func (h *ChatHandler) HandleUpdateChannel(w http.ResponseWriter, r *http.Request
 	})
 }
 
+// currentObject reads an object's latest state for a version-conflict
+// response, or returns nil if it can't be read.
+func (h *ChatHandler) currentObject(ctx context.Context, spaceID, objectID string) *anysync.ObjectPayload {
+	obj, err := h.spaceManager.ObjectTreeManager().ReadLatestByID(ctx, spaceID, objectID)
+	if err != nil {
+		return nil
+	}
+	return obj
+}
+
 // HandleArchiveChannel handles DELETE /api/v1/chat/channels/{id} — archive a channel.
 func (h *ChatHandler) HandleArchiveChannel(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
@@ -586,6 +718,10 @@ func (h *ChatHandler) HandleArchiveChannel(w http.ResponseWriter, r *http.Reques
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "channel ID is required"})
 		return
 	}
+	reason, ok := moderationReason(w, r)
+	if !ok {
+		return
+	}
 
 	communitySpaceID := h.spaceManager.GetCommunitySpaceID()
 	if communitySpaceID == "" {
@@ -654,6 +790,10 @@ func (h *ChatHandler) HandleArchiveChannel(w http.ResponseWriter, r *http.Reques
 	}
 
 	_, err = objMgr.AddObject(ctx, communitySpaceID, payload, keys.SigningKey)
+	if errors.Is(err, anysync.ErrVersionConflict) {
+		writeVersionConflict(w, err, h.currentObject(ctx, communitySpaceID, channelID))
+		return
+	}
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{
 			"error": fmt.Sprintf("failed to archive channel: %v", err),
@@ -665,6 +805,14 @@ func (h *ChatHandler) HandleArchiveChannel(w http.ResponseWriter, r *http.Reques
 		h.chatListener.RegisterObject(payload)
 	}
 
+	logModeration(ctx, h.spaceManager, ModerationEvent{
+		ActorAID:   callerAID(ctx, h.userIdentity),
+		Action:     ModerationActionArchive,
+		TargetType: "ChatChannel",
+		TargetID:   channelID,
+		Reason:     reason,
+	})
+
 	// Broadcast channel update event
 	h.eventBroker.Broadcast(SSEEvent{
 		Type: "chat:channel:update",
@@ -753,6 +901,9 @@ func (h *ChatHandler) HandleSendMessage(w http.ResponseWriter, r *http.Request)
 		})
 		return
 	}
+	if !checkContentSize(w, "content", req.Content, h.maxMessageBytes, DefaultMaxMessageBytes) {
+		return
+	}
 
 	communitySpaceID := h.spaceManager.GetCommunitySpaceID()
 	if communitySpaceID == "" {
@@ -761,12 +912,36 @@ func (h *ChatHandler) HandleSendMessage(w http.ResponseWriter, r *http.Request)
 		})
 		return
 	}
+	if !h.requireChannelRole(r.Context(), w, communitySpaceID, channelID) {
+		return
+	}
+	attachments, ok := h.validateAttachments(r.Context(), w, communitySpaceID, req.Attachments)
+	if !ok {
+		return
+	}
 
-	aid := ""
+	aid := callerAID(r.Context(), h.userIdentity)
 	senderName := "Anonymous"
-	if h.userIdentity != nil {
-		aid = h.userIdentity.GetAID()
-		senderName = h.getSenderName(aid)
+	if aid != "" {
+		senderName = h.profiles.DisplayName(r.Context(), aid)
+	}
+
+	// Run slash commands ("/me waves"); anything else is sent as typed
+	command, err := h.commands.Apply(r.Context(), req.Content, channelID, aid)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if command == nil {
+		// The command ran but has nothing to send
+		writeJSON(w, http.StatusOK, map[string]interface{}{"success": true})
+		return
+	}
+	if command.Content == "" && len(command.Payload) == 0 && len(req.Attachments) == 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "content or attachments required",
+		})
+		return
 	}
 
 	now := time.Now().UTC().Format(time.RFC3339)
@@ -774,10 +949,12 @@ func (h *ChatHandler) HandleSendMessage(w http.ResponseWriter, r *http.Request)
 		ChannelID:   channelID,
 		SenderAID:   aid,
 		SenderName:  senderName,
-		Content:     req.Content,
-		Attachments: req.Attachments,
+		Content:     command.Content,
+		Attachments: attachments,
 		ReplyTo:     req.ReplyTo,
 		SentAt:      now,
+		Kind:        command.Kind,
+		Payload:     command.Payload,
 	}
 
 	dataBytes, err := json.Marshal(messageData)
@@ -805,7 +982,7 @@ func (h *ChatHandler) HandleSendMessage(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	objectID := fmt.Sprintf("ChatMessage-%s-%d-%s", channelID, time.Now().UnixNano(), aid[:8])
+	objectID := newObjectID("ChatMessage-"+channelID, aid)
 	ownerKey := ""
 	if keys.SigningKey != nil {
 		pubKeyBytes, _ := keys.SigningKey.GetPublic().Marshall()
@@ -826,10 +1003,38 @@ func (h *ChatHandler) HandleSendMessage(w http.ResponseWriter, r *http.Request)
 	ctx := r.Context()
 	objMgr := h.spaceManager.ObjectTreeManager()
 
+	event := SSEEvent{
+		Type:       "chat:message:new",
+		Recipients: ChannelMessageRecipients(ctx, h.spaceManager, channelID, aid),
+		Data: map[string]interface{}{
+			"messageId":  objectID,
+			"channelId":  channelID,
+			"senderAid":  aid,
+			"senderName": senderName,
+			"content":    command.Content,
+			"kind":       command.Kind,
+			"sentAt":     now,
+		},
+	}
+
 	headID, err := objMgr.AddObject(ctx, communitySpaceID, payload, keys.SigningKey)
 	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": fmt.Sprintf("failed to send message: %v", err),
+		// Queue the message for retry; members are notified once it lands
+		if h.outbox == nil {
+			h.writeTreeError(w, "failed to send message", err)
+			return
+		}
+		if qErr := h.outbox.Enqueue(ctx, communitySpaceID, payload, &event, err); qErr != nil {
+			log.Printf("[Chat] Failed to queue message %s: %v", objectID, qErr)
+			h.writeTreeError(w, "failed to send message", err)
+			return
+		}
+		h.clearSentDraft(ctx, channelID)
+		writeJSON(w, http.StatusAccepted, map[string]interface{}{
+			"success":   true,
+			"messageId": objectID,
+			"sentAt":    now,
+			"queued":    true,
 		})
 		return
 	}
@@ -837,19 +1042,10 @@ func (h *ChatHandler) HandleSendMessage(w http.ResponseWriter, r *http.Request)
 	if h.chatListener != nil {
 		h.chatListener.RegisterObject(payload)
 	}
+	h.clearSentDraft(ctx, channelID)
 
-	// Broadcast message event
-	h.eventBroker.Broadcast(SSEEvent{
-		Type: "chat:message:new",
-		Data: map[string]interface{}{
-			"messageId":  objectID,
-			"channelId":  channelID,
-			"senderAid":  aid,
-			"senderName": senderName,
-			"content":    req.Content,
-			"sentAt":     now,
-		},
-	})
+	// Notify the channel's members
+	h.eventBroker.Broadcast(event)
 
 	writeJSON(w, http.StatusCreated, map[string]interface{}{
 		"success":   true,
@@ -859,6 +1055,17 @@ func (h *ChatHandler) HandleSendMessage(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+// clearSentDraft clears the caller's draft for a channel once its message has
+// been sent.
+func (h *ChatHandler) clearSentDraft(ctx context.Context, channelID string) {
+	if h.userIdentity == nil {
+		return
+	}
+	if err := h.clearDraft(ctx, channelID); err != nil {
+		log.Printf("[Chat] Failed to clear draft for channel %s: %v", channelID, err)
+	}
+}
+
 // HandleEditMessage handles PUT /api/v1/chat/messages/{id} — edit a message.
 func (h *ChatHandler) HandleEditMessage(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPut {
@@ -884,6 +1091,9 @@ func (h *ChatHandler) HandleEditMessage(w http.ResponseWriter, r *http.Request)
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "content is required"})
 		return
 	}
+	if !checkContentSize(w, "content", req.Content, h.maxMessageBytes, DefaultMaxMessageBytes) {
+		return
+	}
 
 	communitySpaceID := h.spaceManager.GetCommunitySpaceID()
 	if communitySpaceID == "" {
@@ -915,6 +1125,8 @@ func (h *ChatHandler) HandleEditMessage(w http.ResponseWriter, r *http.Request)
 				SentAt:      msg.SentAt,
 				EditedAt:    msg.EditedAt,
 				DeletedAt:   msg.DeletedAt,
+				Kind:        msg.Kind,
+				Payload:     msg.Payload,
 			}
 			if len(msg.Attachments) > 0 {
 				json.Unmarshal(msg.Attachments, &data.Attachments)
@@ -945,14 +1157,14 @@ func (h *ChatHandler) HandleEditMessage(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Check ownership
-	currentAID := ""
-	if h.userIdentity != nil {
-		currentAID = h.userIdentity.GetAID()
-	}
+	currentAID := callerAID(r.Context(), h.userIdentity)
 	if senderAID != currentAID {
 		writeJSON(w, http.StatusForbidden, map[string]string{"error": "can only edit own messages"})
 		return
 	}
+	if !h.requireChannelRole(ctx, w, communitySpaceID, channelID) {
+		return
+	}
 
 	// Update content
 	data.Content = req.Content
@@ -995,6 +1207,10 @@ func (h *ChatHandler) HandleEditMessage(w http.ResponseWriter, r *http.Request)
 
 	objMgr := h.spaceManager.ObjectTreeManager()
 	headID, err := objMgr.AddObject(ctx, communitySpaceID, payload, keys.SigningKey)
+	if errors.Is(err, anysync.ErrVersionConflict) {
+		writeVersionConflict(w, err, h.currentObject(ctx, communitySpaceID, messageID))
+		return
+	}
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{
 			"error": fmt.Sprintf("failed to edit message: %v", err),
@@ -1068,6 +1284,8 @@ func (h *ChatHandler) HandleDeleteMessage(w http.ResponseWriter, r *http.Request
 				SentAt:      msg.SentAt,
 				EditedAt:    msg.EditedAt,
 				DeletedAt:   msg.DeletedAt,
+				Kind:        msg.Kind,
+				Payload:     msg.Payload,
 			}
 			if len(msg.Attachments) > 0 {
 				json.Unmarshal(msg.Attachments, &data.Attachments)
@@ -1095,10 +1313,7 @@ func (h *ChatHandler) HandleDeleteMessage(w http.ResponseWriter, r *http.Request
 	}
 
 	// Check ownership
-	currentAID := ""
-	if h.userIdentity != nil {
-		currentAID = h.userIdentity.GetAID()
-	}
+	currentAID := callerAID(r.Context(), h.userIdentity)
 	if data.SenderAID != currentAID {
 		writeJSON(w, http.StatusForbidden, map[string]string{"error": "can only delete own messages"})
 		return
@@ -1144,6 +1359,10 @@ func (h *ChatHandler) HandleDeleteMessage(w http.ResponseWriter, r *http.Request
 
 	objMgr := h.spaceManager.ObjectTreeManager()
 	_, err = objMgr.AddObject(ctx, communitySpaceID, payload, keys.SigningKey)
+	if errors.Is(err, anysync.ErrVersionConflict) {
+		writeVersionConflict(w, err, h.currentObject(ctx, communitySpaceID, messageID))
+		return
+	}
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{
 			"error": fmt.Sprintf("failed to delete message: %v", err),
@@ -1202,16 +1421,18 @@ func (h *ChatHandler) HandleGetThread(w http.ResponseWriter, r *http.Request) {
 	if h.store != nil {
 		replies, err := h.store.ListReplies(ctx, parentMessageID)
 		if err == nil {
+			cutoffs := h.channelRetentionCutoffs(ctx, communitySpaceID, time.Now())
+			replies = slices.DeleteFunc(replies, func(m *anystore.ChatMessage) bool {
+				return messageExpired(m.SentAt, cutoffs[m.ChannelID])
+			})
+
 			messageIDs := make([]string, len(replies))
 			for i, m := range replies {
 				messageIDs[i] = m.ID
 			}
 			reactionsMap, _ := h.store.ListReactionsByMessages(ctx, messageIDs)
 
-			currentAID := ""
-			if h.userIdentity != nil {
-				currentAID = h.userIdentity.GetAID()
-			}
+			currentAID := callerAID(r.Context(), h.userIdentity)
 
 			result := make([]MessageResponse, 0, len(replies))
 			for _, m := range replies {
@@ -1223,7 +1444,7 @@ func (h *ChatHandler) HandleGetThread(w http.ResponseWriter, r *http.Request) {
 					json.Unmarshal(m.Attachments, &attachments)
 				}
 
-				result = append(result, MessageResponse{
+				msg := MessageResponse{
 					ID:          m.ID,
 					ChannelID:   m.ChannelID,
 					SenderAID:   m.SenderAID,
@@ -1234,9 +1455,13 @@ func (h *ChatHandler) HandleGetThread(w http.ResponseWriter, r *http.Request) {
 					SentAt:      m.SentAt,
 					EditedAt:    m.EditedAt,
 					DeletedAt:   m.DeletedAt,
+					Kind:        m.Kind,
+					Payload:     m.Payload,
 					Reactions:   aggregated,
 					Version:     m.Version,
-				})
+				}
+				redactIfDeleted(&msg)
+				result = append(result, msg)
 			}
 
 			writeJSON(w, http.StatusOK, map[string]interface{}{
@@ -1252,34 +1477,49 @@ func (h *ChatHandler) HandleGetThread(w http.ResponseWriter, r *http.Request) {
 	h.handleGetThreadFallback(w, r, parentMessageID, communitySpaceID)
 }
 
-// --- Reaction Handlers ---
+// maxBatchMessageIDs is the most message IDs HandleBatchMessages accepts per request
+const maxBatchMessageIDs = 100
 
-// HandleAddReaction handles POST /api/v1/chat/messages/{id}/reactions — add a reaction.
-func (h *ChatHandler) HandleAddReaction(w http.ResponseWriter, r *http.Request) {
+// BatchMessagesRequest is the request body for fetching messages by ID.
+type BatchMessagesRequest struct {
+	MessageIDs []string `json:"messageIds"`
+}
+
+// HandleBatchMessages handles POST /api/v1/chat/messages/batch — fetch messages by ID,
+// e.g. the messages quoted by replies. Messages are returned in request order; deleted
+// messages are tombstones (deletedAt set, content omitted) and unknown IDs, messages in
+// channels the caller can't read, or messages past their channel's retention window, are
+// listed under "missing".
+func (h *ChatHandler) HandleBatchMessages(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
 		return
 	}
 
-	// Extract message ID from path
-	path := strings.TrimPrefix(r.URL.Path, "/api/v1/chat/messages/")
-	parts := strings.Split(path, "/")
-	if len(parts) < 2 || parts[1] != "reactions" {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid path"})
+	var req BatchMessagesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
 		return
 	}
-	messageID := parts[0]
 
-	var req AddReactionRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{
-			"error": fmt.Sprintf("invalid request: %v", err),
-		})
+	// Drop empty and duplicate IDs, keeping request order
+	seen := make(map[string]bool)
+	ids := make([]string, 0, len(req.MessageIDs))
+	for _, id := range req.MessageIDs {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "messageIds is required"})
 		return
 	}
-
-	if req.Emoji == "" {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "emoji is required"})
+	if len(ids) > maxBatchMessageIDs {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("at most %d messageIds per request", maxBatchMessageIDs),
+		})
 		return
 	}
 
@@ -1291,94 +1531,213 @@ func (h *ChatHandler) HandleAddReaction(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	currentAID := ""
-	if h.userIdentity != nil {
-		currentAID = h.userIdentity.GetAID()
+	ctx := r.Context()
+	found := h.lookupMessages(ctx, communitySpaceID, ids, callerAID(ctx, h.userIdentity))
+
+	var unnamed []string
+	for _, msg := range found {
+		if msg.SenderName == "" {
+			unnamed = append(unnamed, msg.SenderAID)
+		}
 	}
+	names := h.profiles.ResolveMany(ctx, unnamed)
 
-	ctx := r.Context()
+	cutoffs := h.channelRetentionCutoffs(ctx, communitySpaceID, time.Now())
+	messages := make([]MessageResponse, 0, len(found))
+	missing := make([]string, 0)
+	for _, id := range ids {
+		msg, ok := found[id]
+		if !ok || messageExpired(msg.SentAt, cutoffs[msg.ChannelID]) {
+			missing = append(missing, id)
+			continue
+		}
+		if msg.SenderName == "" {
+			msg.SenderName = names[msg.SenderAID].DisplayName
+		}
+		redactIfDeleted(&msg)
+		messages = append(messages, msg)
+	}
 
-	reactionID := fmt.Sprintf("MessageReaction-%s-%s", messageID, req.Emoji)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"messages": messages,
+		"missing":  missing,
+		"count":    len(messages),
+	})
+}
 
-	var reactionData MessageReactionData
-	existingVersion := 0
+// lookupMessages reads the messages with the given IDs from anystore, then
+// the tree for any it doesn't have (e.g. messages received via P2P sync),
+// with reactions aggregated for currentAID. Unknown IDs are left out, as are
+// messages in channels the caller can't read or whose channel can't be read
+// to check. Sender names, retention and tombstones are left to the caller.
+func (h *ChatHandler) lookupMessages(ctx context.Context, communitySpaceID string, ids []string, currentAID string) map[string]MessageResponse {
+	found := make(map[string]MessageResponse, len(ids))
 
-	// Try anystore first, then tree fallback
+	// Read from anystore if available
 	if h.store != nil {
-		rxn, err := h.store.GetReaction(ctx, reactionID)
+		stored, err := h.store.GetMessagesByIDs(ctx, ids)
 		if err == nil {
-			reactionData = MessageReactionData{
-				MessageID:   rxn.MessageID,
-				Emoji:       rxn.Emoji,
-				ReactorAIDs: rxn.ReactorAIDs,
+			storedIDs := make([]string, 0, len(stored))
+			for id := range stored {
+				storedIDs = append(storedIDs, id)
 			}
-			existingVersion = rxn.Version
+			reactionsMap, _ := h.store.ListReactionsByMessages(ctx, storedIDs)
 
-			for _, aid := range reactionData.ReactorAIDs {
-				if aid == currentAID {
-					writeJSON(w, http.StatusConflict, map[string]string{
-						"error": "already reacted with this emoji",
-					})
-					return
+			for id, m := range stored {
+				var attachments []AttachmentRef
+				if len(m.Attachments) > 0 {
+					json.Unmarshal(m.Attachments, &attachments)
 				}
-			}
-			reactionData.ReactorAIDs = append(reactionData.ReactorAIDs, currentAID)
-		} else {
-			reactionData = MessageReactionData{
-				MessageID:   messageID,
-				Emoji:       req.Emoji,
-				ReactorAIDs: []string{currentAID},
-			}
-		}
-	} else {
-		// Fallback: tree scan
-		objMgr := h.spaceManager.ObjectTreeManager()
-		existing, err := objMgr.ReadLatestByID(ctx, communitySpaceID, reactionID)
-		if err == nil {
-			if err := json.Unmarshal(existing.Data, &reactionData); err != nil {
-				writeJSON(w, http.StatusInternalServerError, map[string]string{
-					"error": fmt.Sprintf("invalid reaction data: %v", err),
-				})
-				return
-			}
-			existingVersion = existing.Version
 
-			for _, aid := range reactionData.ReactorAIDs {
-				if aid == currentAID {
-					writeJSON(w, http.StatusConflict, map[string]string{
-						"error": "already reacted with this emoji",
-					})
-					return
+				found[id] = MessageResponse{
+					ID:          m.ID,
+					ChannelID:   m.ChannelID,
+					SenderAID:   m.SenderAID,
+					SenderName:  m.SenderName,
+					Content:     m.Content,
+					Attachments: attachments,
+					ReplyTo:     m.ReplyTo,
+					SentAt:      m.SentAt,
+					EditedAt:    m.EditedAt,
+					DeletedAt:   m.DeletedAt,
+					Kind:        m.Kind,
+					Payload:     m.Payload,
+					Reactions:   aggregateStoreReactions(reactionsMap[id], currentAID),
+					Version:     m.Version,
 				}
 			}
-			reactionData.ReactorAIDs = append(reactionData.ReactorAIDs, currentAID)
-		} else {
-			reactionData = MessageReactionData{
-				MessageID:   messageID,
-				Emoji:       req.Emoji,
-				ReactorAIDs: []string{currentAID},
+		}
+	}
+
+	// Fallback: read from the tree anything anystore doesn't have (e.g. messages
+	// received via P2P sync)
+	objMgr := h.spaceManager.ObjectTreeManager()
+	var entries []*messageEntry
+	for _, id := range ids {
+		if _, ok := found[id]; ok {
+			continue
+		}
+		obj, err := objMgr.ReadLatestByID(ctx, communitySpaceID, id)
+		if err != nil || obj.Type != "ChatMessage" {
+			continue
+		}
+		var data ChatMessageData
+		if err := decodeObjectData(h.quarantine, communitySpaceID, obj, &data); err != nil {
+			continue
+		}
+		entries = append(entries, &messageEntry{obj: obj, data: data})
+	}
+	if len(entries) > 0 {
+		reactions := h.loadReactionsForMessages(ctx, objMgr, communitySpaceID, entries)
+		for _, m := range entries {
+			found[m.obj.ID] = MessageResponse{
+				ID:          m.obj.ID,
+				ChannelID:   m.data.ChannelID,
+				SenderAID:   m.data.SenderAID,
+				SenderName:  m.data.SenderName,
+				Content:     m.data.Content,
+				Attachments: m.data.Attachments,
+				ReplyTo:     m.data.ReplyTo,
+				SentAt:      m.data.SentAt,
+				EditedAt:    m.data.EditedAt,
+				DeletedAt:   m.data.DeletedAt,
+				Kind:        m.data.Kind,
+				Payload:     m.data.Payload,
+				Reactions:   aggregateReactions(reactions[m.obj.ID], currentAID),
+				Version:     m.obj.Version,
 			}
 		}
 	}
 
-	dataBytes, err := json.Marshal(reactionData)
-	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": fmt.Sprintf("failed to marshal reaction data: %v", err),
-		})
+	roles := h.callerRoles(ctx)
+	readable := make(map[string]bool)
+	for id, msg := range found {
+		canRead, checked := readable[msg.ChannelID]
+		if !checked {
+			allowedRoles, ok := h.channelAllowedRoles(ctx, communitySpaceID, msg.ChannelID)
+			canRead = ok && holdsChannelRole(allowedRoles, roles)
+			readable[msg.ChannelID] = canRead
+		}
+		if !canRead {
+			delete(found, id)
+		}
+	}
+	return found
+}
+
+// HandleGetMessage handles GET /api/v1/chat/messages/{id} — fetch one message,
+// e.g. for a permalink or a notification deep-link, from any channel the
+// caller can read. A deleted message is returned as its tombstone (deletedAt
+// set, content omitted); unknown messages, ones in channels the caller can't
+// read, or ones past their channel's retention window, are 404.
+func (h *ChatHandler) HandleGetMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
 		return
 	}
 
-	// Get signing key
-	client := h.spaceManager.GetClient()
-	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), communitySpaceID, client.GetSigningKey())
-	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": fmt.Sprintf("failed to load space keys: %v", err),
+	messageID := strings.TrimPrefix(r.URL.Path, "/api/v1/chat/messages/")
+	if messageID == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "message ID is required"})
+		return
+	}
+
+	communitySpaceID := h.spaceManager.GetCommunitySpaceID()
+	if communitySpaceID == "" {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "community space not configured",
 		})
 		return
 	}
 
+	ctx := r.Context()
+	msg, ok := h.lookupMessages(ctx, communitySpaceID, []string{messageID}, callerAID(ctx, h.userIdentity))[messageID]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "message not found"})
+		return
+	}
+	cutoffs := h.channelRetentionCutoffs(ctx, communitySpaceID, time.Now())
+	if messageExpired(msg.SentAt, cutoffs[msg.ChannelID]) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "message not found"})
+		return
+	}
+
+	if msg.SenderName == "" {
+		msg.SenderName = h.profiles.DisplayName(ctx, msg.SenderAID)
+	}
+	redactIfDeleted(&msg)
+	writeJSON(w, http.StatusOK, msg)
+}
+
+// --- Reaction Handlers ---
+
+// maxReactionWriteAttempts bounds how often updateReaction retries after
+// losing a race with another write to the same reaction
+const maxReactionWriteAttempts = 5
+
+var (
+	errAlreadyReacted   = errors.New("already reacted with this emoji")
+	errReactionNotFound = errors.New("reaction not found")
+	errNotReacted       = errors.New("you haven't reacted with this emoji")
+)
+
+// updateReaction applies mutate to the reactors of messageID's emoji reaction
+// and writes the result. The write is conditional on the version that was
+// read, so when another add or remove gets in first the reaction is re-read
+// and mutate applied again, rather than one write overwriting the other. An
+// error from mutate is returned as is, without writing.
+func (h *ChatHandler) updateReaction(
+	ctx context.Context, communitySpaceID, messageID, emoji string,
+	mutate func(*MessageReactionData) error,
+) (*MessageReactionData, *anysync.ObjectPayload, error) {
+	client := h.spaceManager.GetClient()
+	if client == nil {
+		return nil, nil, fmt.Errorf("any-sync client not available")
+	}
+	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), communitySpaceID, client.GetSigningKey())
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading space keys: %w", err)
+	}
 	ownerKey := ""
 	if keys.SigningKey != nil {
 		pubKeyBytes, _ := keys.SigningKey.GetPublic().Marshall()
@@ -1387,24 +1746,130 @@ func (h *ChatHandler) HandleAddReaction(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	payload := &anysync.ObjectPayload{
-		ID:        reactionID,
-		Type:      "MessageReaction",
-		OwnerKey:  ownerKey,
-		Data:      dataBytes,
-		Timestamp: time.Now().Unix(),
-		Version:   existingVersion + 1,
+	reactionID := reactionObjectID(messageID, emoji)
+	objMgr := h.spaceManager.ObjectTreeManager()
+
+	// Reactions created before IDs were hashed keep their original ID
+	if _, err := objMgr.ReadLatestByID(ctx, communitySpaceID, reactionID); err != nil {
+		legacyID := legacyReactionObjectID(messageID, emoji)
+		if _, err := objMgr.ReadLatestByID(ctx, communitySpaceID, legacyID); err == nil {
+			reactionID = legacyID
+		}
 	}
 
-	objMgr := h.spaceManager.ObjectTreeManager()
-	_, err = objMgr.AddObject(ctx, communitySpaceID, payload, keys.SigningKey)
-	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": fmt.Sprintf("failed to add reaction: %v", err),
+	for attempt := 1; ; attempt++ {
+		// Always read the tree: the anystore cache can lag behind it
+		reactionData := MessageReactionData{MessageID: messageID, Emoji: emoji}
+		existingVersion := 0
+		if existing, err := objMgr.ReadLatestByID(ctx, communitySpaceID, reactionID); err == nil {
+			if err := json.Unmarshal(existing.Data, &reactionData); err != nil {
+				return nil, nil, fmt.Errorf("invalid reaction data: %w", err)
+			}
+			existingVersion = existing.Version
+		}
+
+		if err := mutate(&reactionData); err != nil {
+			return nil, nil, err
+		}
+		switch {
+		case len(reactionData.ReactorAIDs) == 0:
+			reactionData.FirstReactedAt = ""
+			reactionData.RemovedAt = time.Now().UTC().Format(time.RFC3339)
+		case reactionData.FirstReactedAt == "":
+			reactionData.FirstReactedAt = time.Now().UTC().Format(time.RFC3339)
+			reactionData.RemovedAt = ""
+		}
+
+		dataBytes, err := json.Marshal(reactionData)
+		if err != nil {
+			return nil, nil, fmt.Errorf("marshaling reaction data: %w", err)
+		}
+		payload := &anysync.ObjectPayload{
+			ID:        reactionID,
+			Type:      "MessageReaction",
+			OwnerKey:  ownerKey,
+			Data:      dataBytes,
+			Timestamp: time.Now().Unix(),
+			Version:   existingVersion + 1,
+		}
+
+		_, err = objMgr.CompareAndAddObject(ctx, communitySpaceID, payload, existingVersion, keys.SigningKey)
+		if err == nil {
+			return &reactionData, payload, nil
+		}
+		if !errors.Is(err, anysync.ErrVersionConflict) || attempt == maxReactionWriteAttempts {
+			return nil, nil, err
+		}
+		log.Printf("[Chat] Reaction %s changed while updating (attempt %d), retrying", reactionID, attempt)
+	}
+}
+
+// HandleAddReaction handles POST /api/v1/chat/messages/{id}/reactions — add a reaction.
+func (h *ChatHandler) HandleAddReaction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		return
+	}
+
+	// Extract message ID from path
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/chat/messages/")
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 || parts[1] != "reactions" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid path"})
+		return
+	}
+	messageID := parts[0]
+
+	var req AddReactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("invalid request: %v", err),
+		})
+		return
+	}
+
+	if req.Emoji == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "emoji is required"})
+		return
+	}
+
+	communitySpaceID := h.spaceManager.GetCommunitySpaceID()
+	if communitySpaceID == "" {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "community space not configured",
 		})
 		return
 	}
 
+	currentAID := callerAID(r.Context(), h.userIdentity)
+
+	// A message that can't be read can't be checked against its channel's roles
+	ctx := r.Context()
+	channelID := h.messageChannelID(ctx, communitySpaceID, messageID)
+	if channelID == "" {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "message not found"})
+		return
+	}
+	if !h.requireChannelRole(ctx, w, communitySpaceID, channelID) {
+		return
+	}
+
+	reactionData, payload, err := h.updateReaction(ctx, communitySpaceID, messageID, req.Emoji, func(data *MessageReactionData) error {
+		if slices.Contains(data.ReactorAIDs, currentAID) {
+			return errAlreadyReacted
+		}
+		data.ReactorAIDs = append(data.ReactorAIDs, currentAID)
+		return nil
+	})
+	if errors.Is(err, errAlreadyReacted) {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.writeTreeError(w, "failed to add reaction", err)
+		return
+	}
+
 	if h.chatListener != nil {
 		h.chatListener.RegisterObject(payload)
 	}
@@ -1435,15 +1900,24 @@ func (h *ChatHandler) HandleRemoveReaction(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Extract message ID and emoji from path
-	path := strings.TrimPrefix(r.URL.Path, "/api/v1/chat/messages/")
+	// Extract message ID and emoji from the escaped path, so an encoded "/"
+	// in the emoji segment doesn't split it
+	path := strings.TrimPrefix(r.URL.EscapedPath(), "/api/v1/chat/messages/")
 	parts := strings.Split(path, "/")
-	if len(parts) < 3 || parts[1] != "reactions" {
+	if len(parts) != 3 || parts[1] != "reactions" {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid path"})
 		return
 	}
-	messageID := parts[0]
-	emoji := parts[2]
+	messageID, err := url.PathUnescape(parts[0])
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid path"})
+		return
+	}
+	emoji, err := url.PathUnescape(parts[2])
+	if err != nil || emoji == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid emoji"})
+		return
+	}
 
 	communitySpaceID := h.spaceManager.GetCommunitySpaceID()
 	if communitySpaceID == "" {
@@ -1453,110 +1927,27 @@ func (h *ChatHandler) HandleRemoveReaction(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	currentAID := ""
-	if h.userIdentity != nil {
-		currentAID = h.userIdentity.GetAID()
-	}
+	currentAID := callerAID(r.Context(), h.userIdentity)
 
 	ctx := r.Context()
 
-	reactionID := fmt.Sprintf("MessageReaction-%s-%s", messageID, emoji)
-
-	var reactionData MessageReactionData
-	existingVersion := 0
-
-	// Try anystore first
-	if h.store != nil {
-		rxn, err := h.store.GetReaction(ctx, reactionID)
-		if err == nil {
-			reactionData = MessageReactionData{
-				MessageID:   rxn.MessageID,
-				Emoji:       rxn.Emoji,
-				ReactorAIDs: rxn.ReactorAIDs,
-			}
-			existingVersion = rxn.Version
-		} else {
-			writeJSON(w, http.StatusNotFound, map[string]string{"error": "reaction not found"})
-			return
-		}
-	} else {
-		// Fallback: tree scan
-		objMgr := h.spaceManager.ObjectTreeManager()
-		existing, err := objMgr.ReadLatestByID(ctx, communitySpaceID, reactionID)
-		if err != nil {
-			writeJSON(w, http.StatusNotFound, map[string]string{"error": "reaction not found"})
-			return
-		}
-		if err := json.Unmarshal(existing.Data, &reactionData); err != nil {
-			writeJSON(w, http.StatusInternalServerError, map[string]string{
-				"error": fmt.Sprintf("invalid reaction data: %v", err),
-			})
-			return
+	reactionData, payload, err := h.updateReaction(ctx, communitySpaceID, messageID, emoji, func(data *MessageReactionData) error {
+		if len(data.ReactorAIDs) == 0 {
+			return errReactionNotFound
 		}
-		existingVersion = existing.Version
-	}
-
-	// Remove user from reactors
-	found := false
-	newReactors := make([]string, 0, len(reactionData.ReactorAIDs))
-	for _, aid := range reactionData.ReactorAIDs {
-		if aid == currentAID {
-			found = true
-		} else {
-			newReactors = append(newReactors, aid)
+		i := slices.Index(data.ReactorAIDs, currentAID)
+		if i < 0 {
+			return errNotReacted
 		}
-	}
-
-	if !found {
-		writeJSON(w, http.StatusNotFound, map[string]string{
-			"error": "you haven't reacted with this emoji",
-		})
-		return
-	}
-
-	reactionData.ReactorAIDs = newReactors
-
-	dataBytes, err := json.Marshal(reactionData)
-	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": fmt.Sprintf("failed to marshal reaction data: %v", err),
-		})
+		data.ReactorAIDs = slices.Delete(data.ReactorAIDs, i, i+1)
+		return nil
+	})
+	if errors.Is(err, errReactionNotFound) || errors.Is(err, errNotReacted) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
 		return
 	}
-
-	// Get signing key
-	client := h.spaceManager.GetClient()
-	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), communitySpaceID, client.GetSigningKey())
 	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": fmt.Sprintf("failed to load space keys: %v", err),
-		})
-		return
-	}
-
-	ownerKey := ""
-	if keys.SigningKey != nil {
-		pubKeyBytes, _ := keys.SigningKey.GetPublic().Marshall()
-		if pubKeyBytes != nil {
-			ownerKey = fmt.Sprintf("%x", pubKeyBytes)
-		}
-	}
-
-	payload := &anysync.ObjectPayload{
-		ID:        reactionID,
-		Type:      "MessageReaction",
-		OwnerKey:  ownerKey,
-		Data:      dataBytes,
-		Timestamp: time.Now().Unix(),
-		Version:   existingVersion + 1,
-	}
-
-	objMgr := h.spaceManager.ObjectTreeManager()
-	_, err = objMgr.AddObject(ctx, communitySpaceID, payload, keys.SigningKey)
-	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": fmt.Sprintf("failed to remove reaction: %v", err),
-		})
+		h.writeTreeError(w, "failed to remove reaction", err)
 		return
 	}
 
@@ -1601,7 +1992,7 @@ func (h *ChatHandler) HandleGetReadCursors(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	userAID := h.userIdentity.GetAID()
+	userAID := callerAID(r.Context(), h.userIdentity)
 	if userAID == "" {
 		writeJSON(w, http.StatusOK, map[string]interface{}{
 			"cursors": map[string]string{},
@@ -1676,7 +2067,7 @@ func (h *ChatHandler) HandleUpdateReadCursor(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	userAID := h.userIdentity.GetAID()
+	userAID := callerAID(r.Context(), h.userIdentity)
 	if userAID == "" {
 		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
 			"error": "user identity not configured",
@@ -1773,44 +2164,122 @@ func (h *ChatHandler) HandleUpdateReadCursor(w http.ResponseWriter, r *http.Requ
 
 // --- Helper Functions ---
 
-func (h *ChatHandler) getUserRole() string {
-	// TODO: Look up the user's CommunityProfile to get their role
-	// For now, return empty (treats as "member")
-	return ""
+// callerRoles returns the caller's roles from the role lookup, or nil if
+// there is no lookup or it fails.
+func (h *ChatHandler) callerRoles(ctx context.Context) []contributions.Role {
+	aid := callerAID(ctx, h.userIdentity)
+	if h.roleLookup == nil || aid == "" {
+		return nil
+	}
+	roles, err := h.roleLookup.GetUserRoles(aid)
+	if err != nil {
+		log.Printf("[Chat] Role lookup failed for %s: %v", aid, err)
+		return nil
+	}
+	return roles
 }
 
-func (h *ChatHandler) getSenderName(aid string) string {
-	// Look up the user's SharedProfile in the community space
-	communitySpaceID := h.spaceManager.GetCommunitySpaceID()
-	if communitySpaceID != "" {
-		objectID := fmt.Sprintf("SharedProfile-%s", aid)
-		obj, err := h.spaceManager.ObjectTreeManager().ReadObject(context.Background(), communitySpaceID, objectID)
-		if err == nil && obj != nil {
-			var fields map[string]json.RawMessage
-			if json.Unmarshal(obj.Data, &fields) == nil {
-				if raw, ok := fields["displayName"]; ok {
-					var name string
-					if json.Unmarshal(raw, &name) == nil && name != "" {
-						return name
-					}
-				}
-			}
+// holdsChannelRole reports whether roles admit the caller to a channel
+// limited to allowedRoles (org role names, see canonicalAllowedRoles): they
+// must hold every role one of the allowed roles grants (see
+// contributions.MapKERIRole). An empty list admits everyone; a role the org
+// doesn't issue admits no one.
+func holdsChannelRole(allowedRoles []string, roles []contributions.Role) bool {
+	if len(allowedRoles) == 0 {
+		return true
+	}
+	for _, allowed := range allowedRoles {
+		canonical, err := canonicalAllowedRoles([]string{allowed})
+		if err != nil {
+			continue
+		}
+		if !slices.ContainsFunc(contributions.MapKERIRole(canonical[0]), func(granted contributions.Role) bool {
+			return !contributions.HasRole(roles, granted)
+		}) {
+			return true
 		}
 	}
-	// Fallback to truncated AID
-	if len(aid) > 12 {
-		return aid[:12] + "..."
+	return false
+}
+
+// requireChannelRole writes a 403 and returns false if channelID is limited
+// to AllowedRoles the caller doesn't hold (see holdsChannelRole). A channel
+// that can't be read (e.g. not synced yet) can't be checked, so it is
+// refused with a 404.
+func (h *ChatHandler) requireChannelRole(ctx context.Context, w http.ResponseWriter, spaceID, channelID string) bool {
+	allowedRoles, ok := h.channelAllowedRoles(ctx, spaceID, channelID)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "channel not found"})
+		return false
+	}
+	if !holdsChannelRole(allowedRoles, h.callerRoles(ctx)) {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "access denied"})
+		return false
 	}
-	return aid
+	return true
 }
 
-func containsRole(allowedRoles []string, userRole string) bool {
-	for _, role := range allowedRoles {
-		if strings.EqualFold(role, userRole) {
-			return true
+// channelAllowedRoles returns channelID's AllowedRoles from the local index,
+// or else the tree. ok is false if the channel can't be read.
+func (h *ChatHandler) channelAllowedRoles(ctx context.Context, spaceID, channelID string) (allowedRoles []string, ok bool) {
+	if ch, err := h.storeChannel(ctx, channelID); err == nil {
+		return ch.AllowedRoles, true
+	}
+	if _, data, err := h.readChannel(ctx, spaceID, channelID); err == nil {
+		return data.AllowedRoles, true
+	}
+	return nil, false
+}
+
+// storeChannel reads a channel from the local index, if there is one.
+func (h *ChatHandler) storeChannel(ctx context.Context, channelID string) (*anystore.ChatChannel, error) {
+	if h.store == nil {
+		return nil, errors.New("no chat index")
+	}
+	return h.store.GetChannel(ctx, channelID)
+}
+
+// messageChannelID returns the channel a message was posted in, or "" if the
+// message can't be read.
+func (h *ChatHandler) messageChannelID(ctx context.Context, spaceID, messageID string) string {
+	if h.store != nil {
+		if msg, err := h.store.GetMessage(ctx, messageID); err == nil {
+			return msg.ChannelID
 		}
 	}
-	return false
+	obj, err := h.spaceManager.ObjectTreeManager().ReadLatestByID(ctx, spaceID, messageID)
+	if err != nil {
+		return ""
+	}
+	var data ChatMessageData
+	if err := decodeObjectData(h.quarantine, spaceID, obj, &data); err != nil {
+		return ""
+	}
+	return data.ChannelID
+}
+
+// canonicalAllowedRoles checks a channel's AllowedRoles against the roles the
+// org issues (keri.ValidRoles), so a typo can't lock everyone out of the
+// channel. Matching is case-insensitive; the roles are returned with their
+// canonical spelling. An empty list leaves the channel open.
+func canonicalAllowedRoles(roles []string) ([]string, error) {
+	if len(roles) == 0 {
+		return roles, nil
+	}
+	validRoles := keri.ValidRoles()
+	canonical := make([]string, 0, len(roles))
+	for _, role := range roles {
+		i := slices.IndexFunc(validRoles, func(valid string) bool {
+			return strings.EqualFold(valid, strings.TrimSpace(role))
+		})
+		if i < 0 {
+			return nil, fmt.Errorf("unknown role %q in allowedRoles", role)
+		}
+		if !slices.Contains(canonical, validRoles[i]) {
+			canonical = append(canonical, validRoles[i])
+		}
+	}
+	return canonical, nil
 }
 
 type messageEntry struct {
@@ -1849,10 +2318,10 @@ func (h *ChatHandler) loadReactionsForMessages(
 	// Parse and group by message
 	for _, obj := range reactionMap {
 		var data MessageReactionData
-		if err := json.Unmarshal(obj.Data, &data); err != nil {
+		if err := decodeObjectData(h.quarantine, spaceID, obj, &data); err != nil {
 			continue
 		}
-		if !messageIDs[data.MessageID] {
+		if !messageIDs[data.MessageID] || data.RemovedAt != "" {
 			continue
 		}
 		result[data.MessageID] = append(result[data.MessageID], data)
@@ -1868,22 +2337,10 @@ func aggregateReactions(reactions []MessageReactionData, currentAID string) []Re
 
 	result := make([]ReactionAggregate, 0, len(reactions))
 	for _, r := range reactions {
-		hasReacted := false
-		for _, aid := range r.ReactorAIDs {
-			if aid == currentAID {
-				hasReacted = true
-				break
-			}
-		}
-
-		result = append(result, ReactionAggregate{
-			Emoji:       r.Emoji,
-			Count:       len(r.ReactorAIDs),
-			ReactorAIDs: r.ReactorAIDs,
-			HasReacted:  hasReacted,
-		})
+		result = addReactionAggregate(result, r.Emoji, r.ReactorAIDs, r.FirstReactedAt, currentAID)
 	}
 
+	sortReactionAggregates(result)
 	return result
 }
 
@@ -1895,23 +2352,66 @@ func aggregateStoreReactions(reactions []*anystore.ChatReaction, currentAID stri
 
 	result := make([]ReactionAggregate, 0, len(reactions))
 	for _, r := range reactions {
-		hasReacted := false
-		for _, aid := range r.ReactorAIDs {
-			if aid == currentAID {
-				hasReacted = true
-				break
-			}
+		result = addReactionAggregate(result, r.Emoji, r.ReactorAIDs, r.FirstReactedAt, currentAID)
+	}
+	sortReactionAggregates(result)
+	return result
+}
+
+// addReactionAggregate adds one reaction object to the pills in result. A
+// message can have two objects for the same emoji, one under its legacy ID and
+// one under the hashed ID (see reactionObjectID) when peers wrote both before
+// syncing; they are merged into one pill with the union of their reactors and
+// the earlier FirstReactedAt. Reactions without reactors (emptied before
+// tombstoning was introduced) are skipped.
+func addReactionAggregate(result []ReactionAggregate, emoji string, reactorAIDs []string, firstReactedAt, currentAID string) []ReactionAggregate {
+	if len(reactorAIDs) == 0 {
+		return result
+	}
+	i := slices.IndexFunc(result, func(a ReactionAggregate) bool { return a.Emoji == emoji })
+	if i < 0 {
+		result = append(result, ReactionAggregate{Emoji: emoji, FirstReactedAt: firstReactedAt})
+		i = len(result) - 1
+	} else if firstReactedAt != "" && (result[i].FirstReactedAt == "" || firstReactedAt < result[i].FirstReactedAt) {
+		result[i].FirstReactedAt = firstReactedAt
+	}
+
+	pill := &result[i]
+	for _, aid := range reactorAIDs {
+		if slices.Contains(pill.ReactorAIDs, aid) {
+			continue
+		}
+		pill.ReactorAIDs = append(pill.ReactorAIDs, aid)
+		if aid == currentAID {
+			pill.HasReacted = true
 		}
-		result = append(result, ReactionAggregate{
-			Emoji:       r.Emoji,
-			Count:       len(r.ReactorAIDs),
-			ReactorAIDs: r.ReactorAIDs,
-			HasReacted:  hasReacted,
-		})
 	}
+	pill.Count = len(pill.ReactorAIDs)
 	return result
 }
 
+// sortReactionAggregates orders reactions so pills render in a stable order:
+// most reactors first, then the emoji reacted with earliest, then by emoji.
+// Reactions without a FirstReactedAt (written before it was tracked) sort
+// after those with one.
+func sortReactionAggregates(reactions []ReactionAggregate) {
+	slices.SortFunc(reactions, func(a, b ReactionAggregate) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		if a.FirstReactedAt != b.FirstReactedAt {
+			switch {
+			case a.FirstReactedAt == "":
+				return 1
+			case b.FirstReactedAt == "":
+				return -1
+			}
+			return strings.Compare(a.FirstReactedAt, b.FirstReactedAt)
+		}
+		return strings.Compare(a.Emoji, b.Emoji)
+	})
+}
+
 // handleListMessagesFallback handles ListMessages via tree scan.
 // Rebuilds the space index first to pick up any P2P-received trees.
 func (h *ChatHandler) handleListMessagesFallback(w http.ResponseWriter, r *http.Request, channelID, communitySpaceID string, limit int) {
@@ -1921,21 +2421,26 @@ func (h *ChatHandler) handleListMessagesFallback(w http.ResponseWriter, r *http.
 	// Rebuild index to discover P2P-received trees not yet indexed
 	h.spaceManager.TreeManager().BuildSpaceIndex(ctx, communitySpaceID)
 
-	objects, err := objMgr.ReadObjectsByType(ctx, communitySpaceID, "ChatMessage")
+	page, err := objMgr.ReadObjectsByTypePaged(ctx, communitySpaceID, "ChatMessage", anysync.ObjectQuery{
+		Field: "channelId",
+		Value: channelID,
+	})
 	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": fmt.Sprintf("failed to read messages: %v", err),
-		})
+		h.writeTreeError(w, "failed to read messages", err)
 		return
 	}
+	objects := page.Objects
+
+	// Messages past the channel's retention window are hidden
+	cutoff := h.channelRetentionCutoffs(ctx, communitySpaceID, time.Now())[channelID]
 
 	messageMap := make(map[string]*messageEntry)
 	for _, obj := range objects {
 		var data ChatMessageData
-		if err := json.Unmarshal(obj.Data, &data); err != nil {
+		if err := decodeObjectData(h.quarantine, communitySpaceID, obj, &data); err != nil {
 			continue
 		}
-		if data.ChannelID != channelID {
+		if data.ChannelID != channelID || messageExpired(data.SentAt, cutoff) {
 			continue
 		}
 		if existing, ok := messageMap[obj.ID]; !ok || obj.Version > existing.obj.Version {
@@ -1976,17 +2481,14 @@ func (h *ChatHandler) handleListMessagesFallback(w http.ResponseWriter, r *http.
 
 	reactions := h.loadReactionsForMessages(ctx, objMgr, communitySpaceID, messages[startIdx:endIdx])
 
-	currentAID := ""
-	if h.userIdentity != nil {
-		currentAID = h.userIdentity.GetAID()
-	}
+	currentAID := callerAID(r.Context(), h.userIdentity)
 
 	result := make([]MessageResponse, 0, endIdx-startIdx)
 	for _, m := range messages[startIdx:endIdx] {
 		msgReactions := reactions[m.obj.ID]
 		aggregated := aggregateReactions(msgReactions, currentAID)
 
-		result = append(result, MessageResponse{
+		msg := MessageResponse{
 			ID:          m.obj.ID,
 			ChannelID:   m.data.ChannelID,
 			SenderAID:   m.data.SenderAID,
@@ -1997,9 +2499,13 @@ func (h *ChatHandler) handleListMessagesFallback(w http.ResponseWriter, r *http.
 			SentAt:      m.data.SentAt,
 			EditedAt:    m.data.EditedAt,
 			DeletedAt:   m.data.DeletedAt,
+			Kind:        m.data.Kind,
+			Payload:     m.data.Payload,
 			Reactions:   aggregated,
 			Version:     m.obj.Version,
-		})
+		}
+		redactIfDeleted(&msg)
+		result = append(result, msg)
 	}
 
 	var nextCursor string
@@ -2021,21 +2527,25 @@ func (h *ChatHandler) handleGetThreadFallback(w http.ResponseWriter, r *http.Req
 	ctx := r.Context()
 	objMgr := h.spaceManager.ObjectTreeManager()
 
-	objects, err := objMgr.ReadObjectsByType(ctx, communitySpaceID, "ChatMessage")
+	page, err := objMgr.ReadObjectsByTypePaged(ctx, communitySpaceID, "ChatMessage", anysync.ObjectQuery{
+		Field: "replyTo",
+		Value: parentMessageID,
+	})
 	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": fmt.Sprintf("failed to read messages: %v", err),
-		})
+		h.writeTreeError(w, "failed to read messages", err)
 		return
 	}
+	objects := page.Objects
+
+	cutoffs := h.channelRetentionCutoffs(ctx, communitySpaceID, time.Now())
 
 	messageMap := make(map[string]*messageEntry)
 	for _, obj := range objects {
 		var data ChatMessageData
-		if err := json.Unmarshal(obj.Data, &data); err != nil {
+		if err := decodeObjectData(h.quarantine, communitySpaceID, obj, &data); err != nil {
 			continue
 		}
-		if data.ReplyTo != parentMessageID {
+		if data.ReplyTo != parentMessageID || messageExpired(data.SentAt, cutoffs[data.ChannelID]) {
 			continue
 		}
 		if existing, ok := messageMap[obj.ID]; !ok || obj.Version > existing.obj.Version {
@@ -2059,17 +2569,14 @@ func (h *ChatHandler) handleGetThreadFallback(w http.ResponseWriter, r *http.Req
 
 	reactions := h.loadReactionsForMessages(ctx, objMgr, communitySpaceID, replies)
 
-	currentAID := ""
-	if h.userIdentity != nil {
-		currentAID = h.userIdentity.GetAID()
-	}
+	currentAID := callerAID(r.Context(), h.userIdentity)
 
 	result := make([]MessageResponse, 0, len(replies))
 	for _, m := range replies {
 		msgReactions := reactions[m.obj.ID]
 		aggregated := aggregateReactions(msgReactions, currentAID)
 
-		result = append(result, MessageResponse{
+		msg := MessageResponse{
 			ID:          m.obj.ID,
 			ChannelID:   m.data.ChannelID,
 			SenderAID:   m.data.SenderAID,
@@ -2080,9 +2587,13 @@ func (h *ChatHandler) handleGetThreadFallback(w http.ResponseWriter, r *http.Req
 			SentAt:      m.data.SentAt,
 			EditedAt:    m.data.EditedAt,
 			DeletedAt:   m.data.DeletedAt,
+			Kind:        m.data.Kind,
+			Payload:     m.data.Payload,
 			Reactions:   aggregated,
 			Version:     m.obj.Version,
-		})
+		}
+		redactIfDeleted(&msg)
+		result = append(result, msg)
 	}
 
 	writeJSON(w, http.StatusOK, map[string]interface{}{
@@ -2092,6 +2603,27 @@ func (h *ChatHandler) handleGetThreadFallback(w http.ResponseWriter, r *http.Req
 	})
 }
 
+// defaultChannelRoles returns the DefaultChannelRoles from the admin settings,
+// or none if the admin space isn't configured on this node or its settings
+// can't be read.
+func (h *ChatHandler) defaultChannelRoles(ctx context.Context) []string {
+	adminSpaceID := h.spaceManager.GetAdminSpaceID()
+	if adminSpaceID == "" {
+		return nil
+	}
+	settings, _, err := readAdminSettings(ctx, h.spaceManager, adminSpaceID)
+	if err != nil {
+		log.Printf("[Chat] Failed to read default channel roles: %v", err)
+		return nil
+	}
+	// Settings written before the roles were validated may not resolve
+	if _, err := canonicalAllowedRoles(settings.DefaultChannelRoles); err != nil {
+		log.Printf("[Chat] Ignoring default channel roles: %v", err)
+		return nil
+	}
+	return settings.DefaultChannelRoles
+}
+
 // RegisterRoutes registers chat routes on the mux.
 func (h *ChatHandler) RegisterRoutes(mux *http.ServeMux) {
 	// Channel routes
@@ -2111,7 +2643,7 @@ func (h *ChatHandler) handleChannels(w http.ResponseWriter, r *http.Request) {
 	case http.MethodGet:
 		h.HandleListChannels(w, r)
 	case http.MethodPost:
-		h.HandleCreateChannel(w, r)
+		h.idempotency.Wrap(h.HandleCreateChannel)(w, r)
 	case http.MethodOptions:
 		w.WriteHeader(http.StatusOK)
 	default:
@@ -2141,13 +2673,53 @@ func (h *ChatHandler) handleChannelByID(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if len(parts) == 2 && parts[1] == "draft" {
+		// /api/v1/chat/channels/{id}/draft
+		switch r.Method {
+		case http.MethodGet:
+			h.HandleGetDraft(w, r)
+		case http.MethodPut:
+			h.HandleSaveDraft(w, r)
+		case http.MethodOptions:
+			w.WriteHeader(http.StatusOK)
+		default:
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		}
+		return
+	}
+
+	if len(parts) == 2 && parts[1] == "membership" {
+		// /api/v1/chat/channels/{id}/membership
+		switch r.Method {
+		case http.MethodPost:
+			h.HandleJoinChannel(w, r)
+		case http.MethodDelete:
+			h.HandleLeaveChannel(w, r)
+		case http.MethodOptions:
+			w.WriteHeader(http.StatusOK)
+		default:
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		}
+		return
+	}
+
+	if len(parts) == 2 && parts[1] == "export" {
+		// /api/v1/chat/channels/{id}/export
+		if h.exportChannel == nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+			return
+		}
+		h.exportChannel(w, r)
+		return
+	}
+
 	if len(parts) >= 2 && parts[1] == "messages" {
 		// /api/v1/chat/channels/{id}/messages
 		switch r.Method {
 		case http.MethodGet:
 			h.HandleListMessages(w, r)
 		case http.MethodPost:
-			h.HandleSendMessage(w, r)
+			h.idempotency.Wrap(h.HandleSendMessage)(w, r)
 		case http.MethodOptions:
 			w.WriteHeader(http.StatusOK)
 		default:
@@ -2161,12 +2733,28 @@ func (h *ChatHandler) handleChannelByID(w http.ResponseWriter, r *http.Request)
 
 // handleMessages routes /api/v1/chat/messages/{id} and nested routes.
 func (h *ChatHandler) handleMessages(w http.ResponseWriter, r *http.Request) {
-	path := strings.TrimPrefix(r.URL.Path, "/api/v1/chat/messages/")
+	// Split the escaped path: the emoji segment may contain an encoded "/"
+	path := strings.TrimPrefix(r.URL.EscapedPath(), "/api/v1/chat/messages/")
 	parts := strings.Split(path, "/")
 
+	if len(parts) == 1 && parts[0] == "batch" {
+		// /api/v1/chat/messages/batch
+		switch r.Method {
+		case http.MethodPost:
+			h.HandleBatchMessages(w, r)
+		case http.MethodOptions:
+			w.WriteHeader(http.StatusOK)
+		default:
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		}
+		return
+	}
+
 	if len(parts) == 1 {
 		// /api/v1/chat/messages/{id}
 		switch r.Method {
+		case http.MethodGet:
+			h.HandleGetMessage(w, r)
 		case http.MethodPut:
 			h.HandleEditMessage(w, r)
 		case http.MethodDelete:
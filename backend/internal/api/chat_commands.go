@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// MessageKindAction is the ChatMessageData.Kind of "/me" messages, which
+// clients render as an action by the sender ("* Alice waves").
+const MessageKindAction = "action"
+
+// commandNamePattern is what a registered command may be called
+var commandNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// ChatCommand is a parsed slash command, e.g. "/me waves" has Name "me" and
+// Args "waves".
+type ChatCommand struct {
+	Name      string
+	Args      string
+	ChannelID string
+	SenderAID string
+}
+
+// CommandResult is the message a command turns into. Kind and Payload let a
+// command send a structured message (e.g. a poll) instead of plain text.
+type CommandResult struct {
+	Content string
+	Kind    string
+	Payload json.RawMessage
+}
+
+// CommandHandler runs a slash command. An error is reported to the sender and
+// nothing is sent, so it should say how to use the command. A nil result
+// with no error means the command handled itself and sends no message.
+type CommandHandler func(ctx context.Context, cmd ChatCommand) (*CommandResult, error)
+
+// CommandRegistry holds the slash commands available in chat.
+type CommandRegistry struct {
+	mu       sync.RWMutex
+	handlers map[string]CommandHandler
+}
+
+// NewCommandRegistry creates a registry with the built-in commands (/me and /shrug).
+func NewCommandRegistry() *CommandRegistry {
+	r := &CommandRegistry{handlers: make(map[string]CommandHandler)}
+	r.Register("me", meCommand)
+	r.Register("shrug", shrugCommand)
+	return r
+}
+
+// Register adds a command, replacing any existing command with the same name.
+// Names are lowercase letters, digits, '-' and '_', starting with a letter.
+func (r *CommandRegistry) Register(name string, handler CommandHandler) error {
+	if !commandNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid command name %q", name)
+	}
+	if handler == nil {
+		return fmt.Errorf("command %q has no handler", name)
+	}
+	r.mu.Lock()
+	r.handlers[name] = handler
+	r.mu.Unlock()
+	return nil
+}
+
+// Apply runs the command in content, if it starts with a registered one.
+// Content that isn't a command (including unknown commands such as a path
+// like "/usr/bin") is returned unchanged as a plain message; a leading "//"
+// sends the rest literally, for text that would otherwise run a command.
+// A nil result means the command sends no message.
+func (r *CommandRegistry) Apply(ctx context.Context, content, channelID, senderAID string) (*CommandResult, error) {
+	plain := &CommandResult{Content: content}
+	if strings.HasPrefix(content, "//") {
+		plain.Content = content[1:]
+		return plain, nil
+	}
+	if !strings.HasPrefix(content, "/") {
+		return plain, nil
+	}
+
+	name, args, _ := strings.Cut(content[1:], " ")
+	r.mu.RLock()
+	handler, ok := r.handlers[strings.ToLower(name)]
+	r.mu.RUnlock()
+	if !ok {
+		return plain, nil
+	}
+
+	return handler(ctx, ChatCommand{
+		Name:      strings.ToLower(name),
+		Args:      strings.TrimSpace(args),
+		ChannelID: channelID,
+		SenderAID: senderAID,
+	})
+}
+
+// meCommand sends "/me <action>" as an action message.
+func meCommand(_ context.Context, cmd ChatCommand) (*CommandResult, error) {
+	if cmd.Args == "" {
+		return nil, fmt.Errorf("usage: /me <action>, e.g. /me waves")
+	}
+	return &CommandResult{Content: cmd.Args, Kind: MessageKindAction}, nil
+}
+
+// shrugCommand appends a shrug to the message.
+func shrugCommand(_ context.Context, cmd ChatCommand) (*CommandResult, error) {
+	const shrug = `¯\_(ツ)_/¯`
+	if cmd.Args == "" {
+		return &CommandResult{Content: shrug}, nil
+	}
+	return &CommandResult{Content: cmd.Args + " " + shrug}, nil
+}
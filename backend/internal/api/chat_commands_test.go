@@ -0,0 +1,155 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCommandRegistry_BuiltIns(t *testing.T) {
+	registry := NewCommandRegistry()
+	ctx := context.Background()
+
+	tests := []struct {
+		content     string
+		wantContent string
+		wantKind    string
+	}{
+		{"/me waves", "waves", MessageKindAction},
+		{"/ME waves", "waves", MessageKindAction},
+		{"/shrug", `¯\_(ツ)_/¯`, ""},
+		{"/shrug no idea", `no idea ¯\_(ツ)_/¯`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			result, err := registry.Apply(ctx, tt.content, "channel-1", "EALICE")
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			if result.Content != tt.wantContent || result.Kind != tt.wantKind {
+				t.Errorf("got content %q kind %q, want %q kind %q", result.Content, result.Kind, tt.wantContent, tt.wantKind)
+			}
+		})
+	}
+
+	if _, err := registry.Apply(ctx, "/me", "channel-1", "EALICE"); err == nil {
+		t.Error("expected /me without an action to fail")
+	}
+}
+
+func TestCommandRegistry_Passthrough(t *testing.T) {
+	registry := NewCommandRegistry()
+
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"hello", "hello"},
+		{"/unknown command", "/unknown command"},
+		{"/usr/bin is a path", "/usr/bin is a path"},
+		{"//me is literal", "/me is literal"},
+		{"/", "/"},
+	}
+	for _, tt := range tests {
+		result, err := registry.Apply(context.Background(), tt.content, "channel-1", "EALICE")
+		if err != nil {
+			t.Fatalf("Apply(%q): %v", tt.content, err)
+		}
+		if result.Content != tt.want || result.Kind != "" {
+			t.Errorf("Apply(%q) = %q kind %q, want %q as a plain message", tt.content, result.Content, result.Kind, tt.want)
+		}
+	}
+}
+
+func TestCommandRegistry_Register(t *testing.T) {
+	registry := NewCommandRegistry()
+
+	err := registry.Register("poll", func(_ context.Context, cmd ChatCommand) (*CommandResult, error) {
+		payload, _ := json.Marshal(map[string]string{"question": cmd.Args, "channelId": cmd.ChannelID})
+		return &CommandResult{Content: cmd.Args, Kind: "poll", Payload: payload}, nil
+	})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	result, err := registry.Apply(context.Background(), "/poll Lunch?", "channel-1", "EALICE")
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if result.Kind != "poll" || string(result.Payload) != `{"channelId":"channel-1","question":"Lunch?"}` {
+		t.Errorf("unexpected result: kind %q payload %s", result.Kind, result.Payload)
+	}
+
+	for _, name := range []string{"", "Poll", "has space", "/poll", "1st"} {
+		if err := registry.Register(name, meCommand); err == nil {
+			t.Errorf("expected Register(%q) to fail", name)
+		}
+	}
+	if err := registry.Register("nil", nil); err == nil {
+		t.Error("expected Register with a nil handler to fail")
+	}
+}
+
+func TestChat_SendMessage_SlashCommands(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	channelID := createTestChannel(t, env, "commands")
+
+	send := func(content string) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"content":%q}`, content)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/chat/channels/"+channelID+"/messages", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		env.mux.ServeHTTP(w, req)
+		return w
+	}
+
+	env.chatHandler.commands.Register("quiet", func(context.Context, ChatCommand) (*CommandResult, error) {
+		return nil, nil
+	})
+	if w := send("/quiet"); w.Code != http.StatusOK {
+		t.Fatalf("send /quiet: expected 200 with no message, got %d: %s", w.Code, w.Body.String())
+	}
+
+	for _, content := range []string{"/me waves", "/nope not a command"} {
+		if w := send(content); w.Code != http.StatusCreated {
+			t.Fatalf("send %q: expected 201, got %d: %s", content, w.Code, w.Body.String())
+		}
+	}
+	w := send("/me")
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("send /me: expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+	var errResp map[string]string
+	json.NewDecoder(w.Body).Decode(&errResp)
+	if errResp["error"] == "" {
+		t.Error("expected a usage error for /me")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/chat/channels/"+channelID+"/messages", nil)
+	listW := httptest.NewRecorder()
+	env.mux.ServeHTTP(listW, req)
+	var resp struct {
+		Messages []MessageResponse `json:"messages"`
+	}
+	json.NewDecoder(listW.Body).Decode(&resp)
+
+	byContent := make(map[string]MessageResponse)
+	for _, m := range resp.Messages {
+		byContent[m.Content] = m
+	}
+	if len(byContent) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(resp.Messages))
+	}
+	if m, ok := byContent["waves"]; !ok || m.Kind != MessageKindAction {
+		t.Errorf("expected /me to send an action message 'waves', got %+v", byContent)
+	}
+	if m, ok := byContent["/nope not a command"]; !ok || m.Kind != "" {
+		t.Errorf("expected unknown command to be sent verbatim, got %+v", byContent)
+	}
+}
@@ -0,0 +1,212 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/matou-dao/backend/internal/anysync"
+)
+
+// ChatDraftObjectType is the ObjectPayload.Type of message drafts. Drafts are
+// written only to the user's private space, so they never sync to the community.
+const ChatDraftObjectType = "ChatDraft"
+
+// ChatDraftData is the unsent message a user is composing in a channel.
+// There is one draft object per channel; each save replaces its content.
+type ChatDraftData struct {
+	ChannelID string `json:"channelId"`
+	Content   string `json:"content"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// SaveDraftRequest is the request body for saving a draft.
+type SaveDraftRequest struct {
+	Content string `json:"content"`
+}
+
+// chatDraftID returns the object ID of aid's draft in a channel.
+func chatDraftID(channelID, aid string) string {
+	return fmt.Sprintf("ChatDraft-%s-%s", channelID, aid)
+}
+
+// draftChannelID extracts the channel ID from /api/v1/chat/channels/{id}/draft.
+func draftChannelID(r *http.Request) string {
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/chat/channels/")
+	parts := strings.Split(path, "/")
+	if len(parts) != 2 || parts[1] != "draft" {
+		return ""
+	}
+	return parts[0]
+}
+
+// HandleGetDraft handles GET /api/v1/chat/channels/{id}/draft — get the user's draft.
+// A channel without a draft returns empty content.
+func (h *ChatHandler) HandleGetDraft(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		return
+	}
+
+	channelID := draftChannelID(r)
+	if channelID == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "channel ID is required"})
+		return
+	}
+
+	draft := ChatDraftData{ChannelID: channelID}
+	privateSpaceID := h.userIdentity.GetPrivateSpaceID()
+	userAID := callerAID(r.Context(), h.userIdentity)
+	if privateSpaceID == "" || userAID == "" {
+		// User hasn't set identity yet, so there can't be a draft
+		writeJSON(w, http.StatusOK, draft)
+		return
+	}
+
+	ctx := r.Context()
+
+	// Build space index to discover trees
+	h.spaceManager.TreeManager().BuildSpaceIndex(ctx, privateSpaceID)
+
+	obj, err := h.spaceManager.ObjectTreeManager().ReadLatestByID(ctx, privateSpaceID, chatDraftID(channelID, userAID))
+	if err != nil {
+		// Not found is ok, return an empty draft
+		writeJSON(w, http.StatusOK, draft)
+		return
+	}
+	if err := json.Unmarshal(obj.Data, &draft); err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("invalid draft data: %v", err),
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, draft)
+}
+
+// HandleSaveDraft handles PUT /api/v1/chat/channels/{id}/draft — save the user's draft.
+// Saving empty content clears the draft.
+func (h *ChatHandler) HandleSaveDraft(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		return
+	}
+
+	channelID := draftChannelID(r)
+	if channelID == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "channel ID is required"})
+		return
+	}
+
+	var req SaveDraftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("invalid request: %v", err),
+		})
+		return
+	}
+
+	if h.userIdentity.GetPrivateSpaceID() == "" {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "private space not configured",
+		})
+		return
+	}
+	if callerAID(r.Context(), h.userIdentity) == "" {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "user identity not configured",
+		})
+		return
+	}
+
+	draft, err := h.saveDraft(r.Context(), channelID, req.Content)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("failed to save draft: %v", err),
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"channelId": draft.ChannelID,
+		"updatedAt": draft.UpdatedAt,
+	})
+}
+
+// saveDraft writes content as the user's draft in a channel, replacing any
+// earlier draft.
+func (h *ChatHandler) saveDraft(ctx context.Context, channelID, content string) (*ChatDraftData, error) {
+	privateSpaceID := h.userIdentity.GetPrivateSpaceID()
+	objectID := chatDraftID(channelID, callerAID(ctx, h.userIdentity))
+
+	client := h.spaceManager.GetClient()
+	if client == nil {
+		return nil, fmt.Errorf("any-sync client not available")
+	}
+	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), privateSpaceID, client.GetSigningKey())
+	if err != nil {
+		return nil, fmt.Errorf("loading space keys: %w", err)
+	}
+
+	objMgr := h.spaceManager.ObjectTreeManager()
+	existingVersion := 0
+	if existing, err := objMgr.ReadLatestByID(ctx, privateSpaceID, objectID); err == nil {
+		existingVersion = existing.Version
+	}
+
+	draft := &ChatDraftData{
+		ChannelID: channelID,
+		Content:   content,
+		UpdatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	dataBytes, err := json.Marshal(draft)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling draft: %w", err)
+	}
+
+	ownerKey := ""
+	if keys.SigningKey != nil {
+		pubKeyBytes, _ := keys.SigningKey.GetPublic().Marshall()
+		if pubKeyBytes != nil {
+			ownerKey = fmt.Sprintf("%x", pubKeyBytes)
+		}
+	}
+
+	payload := &anysync.ObjectPayload{
+		ID:        objectID,
+		Type:      ChatDraftObjectType,
+		OwnerKey:  ownerKey,
+		Data:      dataBytes,
+		Timestamp: time.Now().Unix(),
+		Version:   existingVersion + 1,
+	}
+	if _, err := objMgr.AddObject(ctx, privateSpaceID, payload, keys.SigningKey); err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+// clearDraft empties the user's draft in a channel, if there is one.
+func (h *ChatHandler) clearDraft(ctx context.Context, channelID string) error {
+	privateSpaceID := h.userIdentity.GetPrivateSpaceID()
+	userAID := callerAID(ctx, h.userIdentity)
+	if privateSpaceID == "" || userAID == "" {
+		return nil
+	}
+
+	obj, err := h.spaceManager.ObjectTreeManager().ReadLatestByID(ctx, privateSpaceID, chatDraftID(channelID, userAID))
+	if err != nil {
+		return nil
+	}
+	var draft ChatDraftData
+	if json.Unmarshal(obj.Data, &draft) == nil && draft.Content == "" {
+		return nil
+	}
+
+	_, err = h.saveDraft(ctx, channelID, "")
+	return err
+}
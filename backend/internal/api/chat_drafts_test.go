@@ -0,0 +1,140 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/anyproto/any-sync/commonspace/object/tree/objecttree"
+	"github.com/matou-dao/backend/internal/anysync"
+	"go.uber.org/mock/gomock"
+)
+
+// setupDraftsPrivateSpace gives the chat test user a private space and returns its ID.
+func setupDraftsPrivateSpace(t *testing.T, env *chatTestEnv) string {
+	t.Helper()
+
+	privateSpaceID := "space-private-drafts-test"
+	if err := env.userIdentity.SetPrivateSpaceID(privateSpaceID); err != nil {
+		t.Fatalf("failed to set private space ID: %v", err)
+	}
+
+	privateKeys, err := anysync.GenerateSpaceKeySet()
+	if err != nil {
+		t.Fatalf("generating private keys: %v", err)
+	}
+	if err := anysync.PersistSpaceKeySet(env.tmpDir, privateSpaceID, privateKeys); err != nil {
+		t.Fatalf("persisting private keys: %v", err)
+	}
+
+	ctrl := gomock.NewController(t)
+	treeSeq := 0
+	env.spaceManager.TreeManager().SetTestTreeFactory(privateSpaceID, func(objectID string) objecttree.ObjectTree {
+		treeSeq++
+		tree := setupStatefulMock(ctrl, &statefulMockTree{})
+		tree.EXPECT().Id().Return(fmt.Sprintf("tree-private-%d-%s", treeSeq, objectID)).AnyTimes()
+		tree.EXPECT().Header().Return(nil).AnyTimes()
+		return tree
+	})
+
+	return privateSpaceID
+}
+
+func getDraft(t *testing.T, env *chatTestEnv, channelID string) ChatDraftData {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/chat/channels/"+channelID+"/draft", nil)
+	w := httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("get draft: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var draft ChatDraftData
+	json.NewDecoder(w.Body).Decode(&draft)
+	return draft
+}
+
+func putDraft(t *testing.T, env *chatTestEnv, channelID, content string) {
+	t.Helper()
+	body := fmt.Sprintf(`{"content":%q}`, content)
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/chat/channels/"+channelID+"/draft", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("save draft: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestChat_Draft_SaveAndGet(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+	privateSpaceID := setupDraftsPrivateSpace(t, env)
+
+	channelID := createTestChannel(t, env, "drafts")
+
+	if draft := getDraft(t, env, channelID); draft.Content != "" {
+		t.Errorf("expected no draft, got %q", draft.Content)
+	}
+
+	putDraft(t, env, channelID, "first thoughts")
+	putDraft(t, env, channelID, "second thoughts")
+
+	draft := getDraft(t, env, channelID)
+	if draft.Content != "second thoughts" {
+		t.Errorf("expected latest draft 'second thoughts', got %q", draft.Content)
+	}
+	if draft.ChannelID != channelID || draft.UpdatedAt == "" {
+		t.Errorf("unexpected draft: %+v", draft)
+	}
+
+	// Drafts live only in the private space
+	objMgr := env.spaceManager.ObjectTreeManager()
+	if drafts, _ := objMgr.ReadObjectsByType(context.Background(), privateSpaceID, ChatDraftObjectType); len(drafts) == 0 {
+		t.Error("expected draft in the private space")
+	}
+	if drafts, _ := objMgr.ReadObjectsByType(context.Background(), env.spaceManager.GetCommunitySpaceID(), ChatDraftObjectType); len(drafts) != 0 {
+		t.Errorf("expected no drafts in the community space, got %d", len(drafts))
+	}
+}
+
+func TestChat_Draft_ClearedOnSend(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+	setupDraftsPrivateSpace(t, env)
+
+	channelID := createTestChannel(t, env, "drafts-send")
+	otherChannelID := createTestChannel(t, env, "drafts-other")
+
+	putDraft(t, env, channelID, "about to send")
+	putDraft(t, env, otherChannelID, "keep me")
+
+	sendTestMessage(t, env, channelID, "about to send")
+
+	if draft := getDraft(t, env, channelID); draft.Content != "" {
+		t.Errorf("expected draft to be cleared after send, got %q", draft.Content)
+	}
+	if draft := getDraft(t, env, otherChannelID); draft.Content != "keep me" {
+		t.Errorf("expected other channel's draft to be kept, got %q", draft.Content)
+	}
+}
+
+func TestChat_Draft_NoPrivateSpace(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/chat/channels/channel1/draft", bytes.NewBufferString(`{"content":"hi"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d: %s", w.Code, w.Body.String())
+	}
+	if draft := getDraft(t, env, "channel1"); draft.Content != "" {
+		t.Errorf("expected empty draft, got %q", draft.Content)
+	}
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// exportFlushEvery is how many exported messages are written between flushes
+const exportFlushEvery = 100
+
+// HandleExportChannel handles GET /api/v1/chat/channels/{id}/export — stream
+// every message in the channel as NDJSON, oldest first. Messages are read
+// from the object trees rather than the chat index, so the export covers
+// everything this node has replicated. Edited and deleted messages are
+// included with their editedAt/deletedAt metadata (deleted ones as
+// tombstones), with reactions inline. The optional from and to query
+// parameters (RFC3339) limit the export to messages sent in [from, to).
+// Registered for community admins by RegisterAdminRoutes.
+func (h *ChatHandler) HandleExportChannel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/chat/channels/")
+	parts := strings.Split(path, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] != "export" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid path"})
+		return
+	}
+	channelID := parts[0]
+
+	var from, to time.Time
+	for name, dst := range map[string]*time.Time{"from": &from, "to": &to} {
+		value := r.URL.Query().Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": fmt.Sprintf("invalid %s: must be an RFC3339 timestamp", name),
+			})
+			return
+		}
+		*dst = parsed
+	}
+
+	communitySpaceID := h.spaceManager.GetCommunitySpaceID()
+	if communitySpaceID == "" {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "community space not configured",
+		})
+		return
+	}
+
+	ctx := r.Context()
+	objMgr := h.spaceManager.ObjectTreeManager()
+	h.spaceManager.TreeManager().BuildSpaceIndex(ctx, communitySpaceID)
+
+	if _, err := objMgr.ReadLatestByID(ctx, communitySpaceID, channelID); err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "channel not found"})
+		return
+	}
+
+	objects, err := objMgr.ReadObjectsByType(ctx, communitySpaceID, "ChatMessage")
+	if err != nil {
+		h.writeTreeError(w, "failed to read messages", err)
+		return
+	}
+
+	messageMap := make(map[string]*messageEntry)
+	for _, obj := range objects {
+		var data ChatMessageData
+		if err := json.Unmarshal(obj.Data, &data); err != nil {
+			continue
+		}
+		if existing, ok := messageMap[obj.ID]; !ok || obj.Version > existing.obj.Version {
+			messageMap[obj.ID] = &messageEntry{obj: obj, data: data}
+		}
+	}
+
+	// Filter on the latest version, so a message moved out of the channel
+	// (see chat_merge.go) isn't exported from an older version
+	messages := make([]*messageEntry, 0, len(messageMap))
+	for _, m := range messageMap {
+		if m.data.ChannelID != channelID || !sentInRange(m.data.SentAt, from, to) {
+			continue
+		}
+		messages = append(messages, m)
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		if messages[i].data.SentAt != messages[j].data.SentAt {
+			return messages[i].data.SentAt < messages[j].data.SentAt
+		}
+		return messages[i].obj.ID < messages[j].obj.ID
+	})
+
+	reactions := h.loadReactionsForMessages(ctx, objMgr, communitySpaceID, messages)
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "channel-"+channelID+".ndjson"))
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+
+	enc := json.NewEncoder(w)
+	for i, m := range messages {
+		msg := MessageResponse{
+			ID:          m.obj.ID,
+			ChannelID:   m.data.ChannelID,
+			SenderAID:   m.data.SenderAID,
+			SenderName:  m.data.SenderName,
+			Content:     m.data.Content,
+			Attachments: m.data.Attachments,
+			ReplyTo:     m.data.ReplyTo,
+			SentAt:      m.data.SentAt,
+			EditedAt:    m.data.EditedAt,
+			DeletedAt:   m.data.DeletedAt,
+			Kind:        m.data.Kind,
+			Payload:     m.data.Payload,
+			Reactions:   aggregateReactions(reactions[m.obj.ID], ""),
+			Version:     m.obj.Version,
+		}
+		redactIfDeleted(&msg)
+		if err := enc.Encode(msg); err != nil {
+			log.Printf("[Chat] Export of channel %s stopped after %d messages: %v", channelID, i, err)
+			return
+		}
+		if flusher != nil && (i+1)%exportFlushEvery == 0 {
+			flusher.Flush()
+		}
+	}
+	if flusher != nil {
+		flusher.Flush()
+	}
+}
+
+// sentInRange reports whether sentAt falls in [from, to). A zero bound is
+// open; a timestamp that doesn't parse is only in an unbounded range.
+func sentInRange(sentAt string, from, to time.Time) bool {
+	if from.IsZero() && to.IsZero() {
+		return true
+	}
+	sent, err := time.Parse(time.RFC3339, sentAt)
+	if err != nil {
+		return false
+	}
+	return (from.IsZero() || !sent.Before(from)) && (to.IsZero() || sent.Before(to))
+}
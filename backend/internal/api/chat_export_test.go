@@ -0,0 +1,142 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matou-dao/backend/internal/anysync"
+)
+
+func exportTestChannel(env *chatTestEnv, aid, channelID, query string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/chat/channels/"+channelID+"/export"+query, nil)
+	req.Header.Set("X-User-AID", aid)
+	w := httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	return w
+}
+
+func decodeExport(t *testing.T, body *bytes.Buffer) []MessageResponse {
+	t.Helper()
+	var messages []MessageResponse
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		var msg MessageResponse
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			t.Fatalf("line %d is not a message: %v", len(messages)+1, err)
+		}
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+func TestChat_ExportChannel(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+	registerTestMergeRoutes(env)
+	ctx := context.Background()
+
+	channelID := createTestChannel(t, env, "archive")
+	otherID := createTestChannel(t, env, "elsewhere")
+	sendTestMessage(t, env, otherID, "not part of the export")
+
+	// Seed more messages than a page holds, with IDs that sort against their
+	// send order so the export has to order by sentAt
+	const total = 150
+	communitySpaceID := env.spaceManager.GetCommunitySpaceID()
+	client := env.spaceManager.GetClient()
+	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), communitySpaceID, client.GetSigningKey())
+	if err != nil {
+		t.Fatalf("loading keys: %v", err)
+	}
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ids := make([]string, total)
+	for i := 0; i < total; i++ {
+		ids[i] = fmt.Sprintf("ChatMessage-%s-%03d", channelID, total-i)
+		data, _ := json.Marshal(ChatMessageData{
+			ChannelID:  channelID,
+			SenderAID:  env.userIdentity.GetAID(),
+			SenderName: "Tester",
+			Content:    fmt.Sprintf("message %d", i),
+			SentAt:     base.Add(time.Duration(i) * time.Minute).Format(time.RFC3339),
+		})
+		payload := &anysync.ObjectPayload{ID: ids[i], Type: "ChatMessage", Data: data, Version: 1}
+		if _, err := env.spaceManager.ObjectTreeManager().AddObject(ctx, communitySpaceID, payload, keys.SigningKey); err != nil {
+			t.Fatalf("seeding message %d: %v", i, err)
+		}
+	}
+
+	do := func(method, path, body string) {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		env.mux.ServeHTTP(w, req)
+		if w.Code != http.StatusOK && w.Code != http.StatusCreated {
+			t.Fatalf("%s %s: got %d: %s", method, path, w.Code, w.Body.String())
+		}
+	}
+	do(http.MethodPut, "/api/v1/chat/messages/"+ids[10], `{"content":"edited"}`)
+	do(http.MethodDelete, "/api/v1/chat/messages/"+ids[20], "")
+	do(http.MethodPost, "/api/v1/chat/messages/"+ids[30]+"/reactions", `{"emoji":"👍"}`)
+
+	w := exportTestChannel(env, "EAdmin", channelID, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("expected NDJSON content type, got %q", ct)
+	}
+	messages := decodeExport(t, w.Body)
+	if len(messages) != total {
+		t.Fatalf("expected %d lines, got %d", total, len(messages))
+	}
+	for i, msg := range messages {
+		if msg.ID != ids[i] {
+			t.Fatalf("line %d: expected %s, got %s", i+1, ids[i], msg.ID)
+		}
+	}
+	if edited := messages[10]; edited.Content != "edited" || edited.EditedAt == "" || edited.Version != 2 {
+		t.Errorf("expected the edit with its metadata, got %+v", edited)
+	}
+	if deleted := messages[20]; deleted.DeletedAt == "" || deleted.Content != "" {
+		t.Errorf("expected a tombstone for the deleted message, got %+v", deleted)
+	}
+	if reacted := messages[30]; len(reacted.Reactions) != 1 || reacted.Reactions[0].Emoji != "👍" {
+		t.Errorf("expected the reaction inline, got %+v", reacted.Reactions)
+	}
+
+	t.Run("date range", func(t *testing.T) {
+		from := base.Add(100 * time.Minute).Format(time.RFC3339)
+		to := base.Add(110 * time.Minute).Format(time.RFC3339)
+		w := exportTestChannel(env, "EAdmin", channelID, "?from="+from+"&to="+to)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		messages := decodeExport(t, w.Body)
+		if len(messages) != 10 || messages[0].ID != ids[100] || messages[9].ID != ids[109] {
+			t.Errorf("expected messages 100-109, got %d lines", len(messages))
+		}
+
+		if w := exportTestChannel(env, "EAdmin", channelID, "?from=yesterday"); w.Code != http.StatusBadRequest {
+			t.Errorf("invalid from: expected 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("admins only", func(t *testing.T) {
+		if w := exportTestChannel(env, "EMember", channelID, ""); w.Code != http.StatusForbidden {
+			t.Errorf("expected 403, got %d", w.Code)
+		}
+	})
+
+	t.Run("unknown channel", func(t *testing.T) {
+		if w := exportTestChannel(env, "EAdmin", "ChatChannel-missing", ""); w.Code != http.StatusNotFound {
+			t.Errorf("expected 404, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,252 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/matou-dao/backend/internal/anysync"
+)
+
+// ChannelMembershipObjectType is the ObjectPayload.Type of channel memberships.
+const ChannelMembershipObjectType = "ChannelMembership"
+
+// ChannelMembershipData records that a member has joined a chat channel.
+// Leaving keeps the object and sets LeftAt, so rejoining bumps its version.
+type ChannelMembershipData struct {
+	ChannelID string `json:"channelId"`
+	MemberAID string `json:"memberAid"`
+	JoinedAt  string `json:"joinedAt"`
+	LeftAt    string `json:"leftAt,omitempty"`
+}
+
+// channelMembershipID returns the object ID of aid's membership of a channel.
+func channelMembershipID(channelID, aid string) string {
+	return fmt.Sprintf("ChannelMembership-%s-%s", channelID, aid)
+}
+
+// ChannelMembers returns the AIDs of the current members of every channel in
+// the space, keyed by channel ID.
+func ChannelMembers(ctx context.Context, spaceManager *anysync.SpaceManager, spaceID string) map[string][]string {
+	result := make(map[string][]string)
+
+	objects, err := spaceManager.ObjectTreeManager().ReadObjectsByType(ctx, spaceID, ChannelMembershipObjectType)
+	if err != nil {
+		return result
+	}
+
+	latest := make(map[string]*anysync.ObjectPayload)
+	for _, obj := range objects {
+		if existing, ok := latest[obj.ID]; !ok || obj.Version > existing.Version {
+			latest[obj.ID] = obj
+		}
+	}
+
+	for _, obj := range latest {
+		var data ChannelMembershipData
+		if err := json.Unmarshal(obj.Data, &data); err != nil {
+			continue
+		}
+		if data.LeftAt != "" {
+			continue
+		}
+		result[data.ChannelID] = append(result[data.ChannelID], data.MemberAID)
+	}
+	return result
+}
+
+// ChannelMessageRecipients returns the SSE recipients for a new message in a
+// channel: its members plus the sender. Including the sender also keeps the
+// list non-empty, so a message in a channel nobody has joined isn't
+// broadcast to every client.
+func ChannelMessageRecipients(ctx context.Context, spaceManager *anysync.SpaceManager, channelID, senderAID string) []string {
+	communitySpaceID := spaceManager.GetCommunitySpaceID()
+	if communitySpaceID == "" {
+		return []string{senderAID}
+	}
+
+	recipients := ChannelMembers(ctx, spaceManager, communitySpaceID)[channelID]
+	for _, aid := range recipients {
+		if aid == senderAID {
+			return recipients
+		}
+	}
+	return append(recipients, senderAID)
+}
+
+// isChannelMember reports whether the current user has joined a channel.
+func (h *ChatHandler) isChannelMember(ctx context.Context, spaceID, channelID string) bool {
+	if h.userIdentity == nil {
+		return false
+	}
+	obj, err := h.spaceManager.ObjectTreeManager().ReadLatestByID(ctx, spaceID, channelMembershipID(channelID, callerAID(ctx, h.userIdentity)))
+	if err != nil {
+		return false
+	}
+	var data ChannelMembershipData
+	return json.Unmarshal(obj.Data, &data) == nil && data.LeftAt == ""
+}
+
+// HandleJoinChannel handles POST /api/v1/chat/channels/{id}/membership — join a channel.
+func (h *ChatHandler) HandleJoinChannel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		return
+	}
+	h.setChannelMembership(w, r, true)
+}
+
+// HandleLeaveChannel handles DELETE /api/v1/chat/channels/{id}/membership — leave a channel.
+func (h *ChatHandler) HandleLeaveChannel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		return
+	}
+	h.setChannelMembership(w, r, false)
+}
+
+// setChannelMembership joins or leaves the channel in the request path for
+// the current user.
+func (h *ChatHandler) setChannelMembership(w http.ResponseWriter, r *http.Request, join bool) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/chat/channels/")
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] != "membership" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid path"})
+		return
+	}
+	channelID := parts[0]
+
+	communitySpaceID := h.spaceManager.GetCommunitySpaceID()
+	if communitySpaceID == "" {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "community space not configured",
+		})
+		return
+	}
+
+	currentAID := callerAID(r.Context(), h.userIdentity)
+	if currentAID == "" {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "identity not configured"})
+		return
+	}
+
+	ctx := r.Context()
+	objMgr := h.spaceManager.ObjectTreeManager()
+
+	// The channel must exist and be readable by the user
+	channelObj, err := objMgr.ReadLatestByID(ctx, communitySpaceID, channelID)
+	if err != nil || channelObj.Type != "ChatChannel" {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "channel not found"})
+		return
+	}
+	var channel ChatChannelData
+	if err := json.Unmarshal(channelObj.Data, &channel); err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("invalid channel data: %v", err),
+		})
+		return
+	}
+	if join && !holdsChannelRole(channel.AllowedRoles, h.callerRoles(ctx)) {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "access denied"})
+		return
+	}
+
+	membershipID := channelMembershipID(channelID, currentAID)
+	var data ChannelMembershipData
+	existingVersion := 0
+	isMember := false
+	if existing, err := objMgr.ReadLatestByID(ctx, communitySpaceID, membershipID); err == nil {
+		if err := json.Unmarshal(existing.Data, &data); err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": fmt.Sprintf("invalid membership data: %v", err),
+			})
+			return
+		}
+		existingVersion = existing.Version
+		isMember = data.LeftAt == ""
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	if join {
+		if isMember {
+			writeJSON(w, http.StatusConflict, map[string]string{"error": "already a member of this channel"})
+			return
+		}
+		data = ChannelMembershipData{
+			ChannelID: channelID,
+			MemberAID: currentAID,
+			JoinedAt:  now,
+		}
+	} else {
+		if !isMember {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "not a member of this channel"})
+			return
+		}
+		data.LeftAt = now
+	}
+
+	if err := h.saveChannelMembership(ctx, communitySpaceID, data, existingVersion+1); err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("failed to update membership: %v", err),
+		})
+		return
+	}
+
+	eventType := "chat:channel:join"
+	if !join {
+		eventType = "chat:channel:leave"
+	}
+	h.eventBroker.Broadcast(SSEEvent{
+		Type: eventType,
+		Data: map[string]interface{}{
+			"channelId": channelID,
+			"memberAid": currentAID,
+		},
+		Recipients: []string{currentAID},
+	})
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"channelId": channelID,
+		"joined":    join,
+	})
+}
+
+// saveChannelMembership writes a version of a membership to the space's ObjectTree.
+func (h *ChatHandler) saveChannelMembership(ctx context.Context, spaceID string, data ChannelMembershipData, version int) error {
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("marshaling membership data: %w", err)
+	}
+
+	client := h.spaceManager.GetClient()
+	if client == nil {
+		return fmt.Errorf("any-sync client not available")
+	}
+	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), spaceID, client.GetSigningKey())
+	if err != nil {
+		return fmt.Errorf("loading space keys: %w", err)
+	}
+
+	ownerKey := ""
+	if keys.SigningKey != nil {
+		pubKeyBytes, _ := keys.SigningKey.GetPublic().Marshall()
+		if pubKeyBytes != nil {
+			ownerKey = fmt.Sprintf("%x", pubKeyBytes)
+		}
+	}
+
+	payload := &anysync.ObjectPayload{
+		ID:        channelMembershipID(data.ChannelID, data.MemberAID),
+		Type:      ChannelMembershipObjectType,
+		OwnerKey:  ownerKey,
+		Data:      dataBytes,
+		Timestamp: time.Now().Unix(),
+		Version:   version,
+	}
+	_, err = h.spaceManager.ObjectTreeManager().AddObject(ctx, spaceID, payload, keys.SigningKey)
+	return err
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// setMembership joins (POST) or leaves (DELETE) a channel and returns the status code.
+func setMembership(t *testing.T, env *chatTestEnv, method, channelID string) int {
+	t.Helper()
+	req := httptest.NewRequest(method, "/api/v1/chat/channels/"+channelID+"/membership", nil)
+	w := httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	return w.Code
+}
+
+// listChannelsByMembership lists channels with the given ?membership= filter.
+func listChannelsByMembership(t *testing.T, env *chatTestEnv, membership string) []ChannelResponse {
+	t.Helper()
+	url := "/api/v1/chat/channels"
+	if membership != "" {
+		url += "?membership=" + membership
+	}
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	w := httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("list channels: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Channels []ChannelResponse `json:"channels"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	return resp.Channels
+}
+
+func channelIDs(channels []ChannelResponse) map[string]bool {
+	ids := make(map[string]bool, len(channels))
+	for _, ch := range channels {
+		ids[ch.ID] = ch.Joined
+	}
+	return ids
+}
+
+func TestChat_JoinLeaveChannel(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	channelID := createTestChannel(t, env, "membership")
+
+	// The creator joins their channel
+	if joined, ok := channelIDs(listChannelsByMembership(t, env, "joined"))[channelID]; !ok || !joined {
+		t.Fatal("expected creator to have joined the channel")
+	}
+	if code := setMembership(t, env, http.MethodPost, channelID); code != http.StatusConflict {
+		t.Errorf("join while member: expected 409, got %d", code)
+	}
+
+	// Leave
+	if code := setMembership(t, env, http.MethodDelete, channelID); code != http.StatusOK {
+		t.Fatalf("leave: expected 200, got %d", code)
+	}
+	if _, ok := channelIDs(listChannelsByMembership(t, env, "joined"))[channelID]; ok {
+		t.Error("expected channel not to be listed as joined after leaving")
+	}
+	if joined, ok := channelIDs(listChannelsByMembership(t, env, "available"))[channelID]; !ok || joined {
+		t.Error("expected channel to be listed as available after leaving")
+	}
+	if code := setMembership(t, env, http.MethodDelete, channelID); code != http.StatusNotFound {
+		t.Errorf("leave while not a member: expected 404, got %d", code)
+	}
+
+	// Rejoin
+	if code := setMembership(t, env, http.MethodPost, channelID); code != http.StatusOK {
+		t.Fatalf("rejoin: expected 200, got %d", code)
+	}
+	if joined, ok := channelIDs(listChannelsByMembership(t, env, ""))[channelID]; !ok || !joined {
+		t.Error("expected channel to be listed as joined after rejoining")
+	}
+}
+
+func TestChat_JoinChannel_NotFound(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	if code := setMembership(t, env, http.MethodPost, "ChatChannel-missing"); code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", code)
+	}
+}
+
+func TestChat_ListChannels_InvalidMembershipFilter(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/chat/channels?membership=all", nil)
+	w := httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestChat_MessageEventsTargetMembers(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	channelID := createTestChannel(t, env, "targeted")
+
+	// Another member joins (as if synced from their device)
+	membership := ChannelMembershipData{ChannelID: channelID, MemberAID: "EMEMBER", JoinedAt: time.Now().UTC().Format(time.RFC3339)}
+	if err := env.chatHandler.saveChannelMembership(context.Background(), env.spaceManager.GetCommunitySpaceID(), membership, 1); err != nil {
+		t.Fatalf("saving membership: %v", err)
+	}
+
+	member := env.eventBroker.SubscribeAs("EMEMBER")
+	defer env.eventBroker.Unsubscribe(member)
+	outsider := env.eventBroker.SubscribeAs("EOUTSIDER")
+	defer env.eventBroker.Unsubscribe(outsider)
+	anonymous := env.eventBroker.Subscribe()
+	defer env.eventBroker.Unsubscribe(anonymous)
+
+	sendTestMessage(t, env, channelID, "members only")
+
+	select {
+	case event := <-member:
+		if event.Type != "chat:message:new" {
+			t.Errorf("expected chat:message:new, got %s", event.Type)
+		}
+	default:
+		t.Error("expected member to receive the message event")
+	}
+	for name, ch := range map[string]chan SSEEvent{"outsider": outsider, "anonymous": anonymous} {
+		select {
+		case event := <-ch:
+			t.Errorf("expected %s to receive nothing, got %s", name, event.Type)
+		default:
+		}
+	}
+
+	// A sender who isn't a member is still a recipient of their own message
+	if code := setMembership(t, env, http.MethodDelete, channelID); code != http.StatusOK {
+		t.Fatalf("leave: expected 200, got %d", code)
+	}
+	recipients := ChannelMessageRecipients(context.Background(), env.spaceManager, channelID, "ESENDER")
+	if len(recipients) != 2 || recipients[0] != "EMEMBER" || recipients[1] != "ESENDER" {
+		t.Errorf("expected recipients [EMEMBER ESENDER], got %v", recipients)
+	}
+}
@@ -0,0 +1,402 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/matou-dao/backend/internal/anysync"
+)
+
+// ChannelMergeObjectType is the ObjectPayload.Type of channel merge records.
+const ChannelMergeObjectType = "ChatChannelMerge"
+
+// maxMergeWriteAttempts bounds how often a message move is retried after
+// losing a race with an edit or reaction to the same message
+const maxMergeWriteAttempts = 3
+
+// ChannelMergeData records a merge of one channel into another, so admins can
+// see which duplicate was folded into which channel, by whom and when.
+type ChannelMergeData struct {
+	SourceID      string `json:"sourceId"`
+	SourceName    string `json:"sourceName"`
+	TargetID      string `json:"targetId"`
+	TargetName    string `json:"targetName"`
+	MessagesMoved int    `json:"messagesMoved"`
+	MembersAdded  int    `json:"membersAdded"`
+	MergedBy      string `json:"mergedBy"`
+	MergedAt      string `json:"mergedAt"`
+}
+
+// MergeChannelsRequest is the request body for merging two channels.
+type MergeChannelsRequest struct {
+	SourceID string `json:"sourceId"`
+	TargetID string `json:"targetId"`
+}
+
+// DuplicateChannelGroup is a set of active channels sharing a name, e.g. two
+// "General" channels created on either side of a network partition.
+type DuplicateChannelGroup struct {
+	Name              string            `json:"name"`
+	Channels          []ChannelResponse `json:"channels"`
+	SuggestedTargetID string            `json:"suggestedTargetId"` // the oldest channel
+}
+
+// RegisterAdminRoutes registers chat routes that require a community admin.
+func (h *ChatHandler) RegisterAdminRoutes(mux *http.ServeMux, roleLookup RoleLookup) {
+	h.roleLookup = roleLookup
+	mux.HandleFunc("/api/v1/chat/channels/duplicates", RBACMiddleware(roleLookup, requireCommunityAdmin(h.HandleListDuplicateChannels)))
+	mux.HandleFunc("/api/v1/chat/channels/merge", RBACMiddleware(roleLookup, requireCommunityAdmin(h.HandleMergeChannels)))
+	// Served under /api/v1/chat/channels/{id}/export by handleChannelByID
+	h.exportChannel = RBACMiddleware(roleLookup, requireCommunityAdmin(h.HandleExportChannel))
+}
+
+// HandleListDuplicateChannels handles GET /api/v1/chat/channels/duplicates —
+// list groups of active channels with the same name (compared case-insensitively).
+func (h *ChatHandler) HandleListDuplicateChannels(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		return
+	}
+
+	communitySpaceID := h.spaceManager.GetCommunitySpaceID()
+	if communitySpaceID == "" {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "community space not configured",
+		})
+		return
+	}
+
+	objects, err := h.spaceManager.ObjectTreeManager().ReadObjectsByType(r.Context(), communitySpaceID, "ChatChannel")
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("failed to read channels: %v", err),
+		})
+		return
+	}
+
+	groups := findDuplicateChannels(objects)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"duplicates": groups,
+		"count":      len(groups),
+	})
+}
+
+// findDuplicateChannels groups the latest version of each active channel by
+// name and returns the groups with more than one channel, oldest first.
+func findDuplicateChannels(objects []*anysync.ObjectPayload) []DuplicateChannelGroup {
+	latest := make(map[string]*anysync.ObjectPayload)
+	for _, obj := range objects {
+		if existing, ok := latest[obj.ID]; !ok || obj.Version > existing.Version {
+			latest[obj.ID] = obj
+		}
+	}
+
+	byName := make(map[string][]ChannelResponse)
+	for _, obj := range latest {
+		var data ChatChannelData
+		if err := json.Unmarshal(obj.Data, &data); err != nil || data.IsArchived {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(data.Name))
+		byName[key] = append(byName[key], ChannelResponse{
+			ID:          obj.ID,
+			Name:        data.Name,
+			Description: data.Description,
+			CreatedAt:   data.CreatedAt,
+			CreatedBy:   data.CreatedBy,
+		})
+	}
+
+	groups := make([]DuplicateChannelGroup, 0)
+	for _, channels := range byName {
+		if len(channels) < 2 {
+			continue
+		}
+		sort.Slice(channels, func(i, j int) bool {
+			if channels[i].CreatedAt != channels[j].CreatedAt {
+				return channels[i].CreatedAt < channels[j].CreatedAt
+			}
+			return channels[i].ID < channels[j].ID
+		})
+		groups = append(groups, DuplicateChannelGroup{
+			Name:              channels[0].Name,
+			Channels:          channels,
+			SuggestedTargetID: channels[0].ID,
+		})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	return groups
+}
+
+// rolesWithin reports whether a channel limited to target admits only roles a
+// channel limited to source admits. An empty list admits every role.
+func rolesWithin(target, source []string) bool {
+	if len(source) == 0 {
+		return true
+	}
+	if len(target) == 0 {
+		return false
+	}
+	for _, role := range target {
+		if !slices.ContainsFunc(source, func(allowed string) bool { return strings.EqualFold(allowed, role) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// HandleMergeChannels handles POST /api/v1/chat/channels/merge — merge a
+// duplicate channel into another. The source's messages move to the target,
+// its members join the target, and the source is archived with MergedInto
+// set. A ChatChannelMerge object records the operation. The target must not
+// be open to roles the source isn't (see rolesWithin), so merging can't
+// expose a restricted channel's messages.
+func (h *ChatHandler) HandleMergeChannels(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		return
+	}
+
+	var req MergeChannelsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("invalid request: %v", err),
+		})
+		return
+	}
+	if req.SourceID == "" || req.TargetID == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "sourceId and targetId are required"})
+		return
+	}
+	if req.SourceID == req.TargetID {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "cannot merge a channel into itself"})
+		return
+	}
+
+	communitySpaceID := h.spaceManager.GetCommunitySpaceID()
+	if communitySpaceID == "" {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "community space not configured",
+		})
+		return
+	}
+
+	ctx := r.Context()
+	source, sourceData, err := h.readChannel(ctx, communitySpaceID, req.SourceID)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("source channel: %v", err)})
+		return
+	}
+	_, targetData, err := h.readChannel(ctx, communitySpaceID, req.TargetID)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("target channel: %v", err)})
+		return
+	}
+	if sourceData.IsArchived {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "source channel is already archived"})
+		return
+	}
+	if targetData.IsArchived {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "target channel is archived"})
+		return
+	}
+	if !rolesWithin(targetData.AllowedRoles, sourceData.AllowedRoles) {
+		writeJSON(w, http.StatusConflict, map[string]string{
+			"error": "target channel is open to roles the source channel is not",
+		})
+		return
+	}
+
+	mergedBy := GetUserAID(r)
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	moved, err := h.moveChannelMessages(ctx, communitySpaceID, req.SourceID, req.TargetID)
+	if err != nil {
+		// Messages moved so far stay moved; retrying the merge moves the rest
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("failed to move messages (%d moved): %v", moved, err),
+		})
+		return
+	}
+	added := h.copyChannelMembers(ctx, communitySpaceID, req.SourceID, req.TargetID, now)
+
+	sourceData.IsArchived = true
+	sourceData.MergedInto = req.TargetID
+	if _, err := h.writeChatObject(ctx, communitySpaceID, req.SourceID, "ChatChannel", sourceData, source.Version+1); err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("failed to archive source channel: %v", err),
+		})
+		return
+	}
+
+	mergeID := newObjectID(ChannelMergeObjectType, mergedBy)
+	record := ChannelMergeData{
+		SourceID:      req.SourceID,
+		SourceName:    sourceData.Name,
+		TargetID:      req.TargetID,
+		TargetName:    targetData.Name,
+		MessagesMoved: moved,
+		MembersAdded:  added,
+		MergedBy:      mergedBy,
+		MergedAt:      now,
+	}
+	if _, err := h.writeChatObject(ctx, communitySpaceID, mergeID, ChannelMergeObjectType, record, 1); err != nil {
+		log.Printf("[Chat] Failed to record merge of %s into %s: %v", req.SourceID, req.TargetID, err)
+	}
+	log.Printf("[Chat] %s merged channel %s into %s (%d messages, %d members)", mergedBy, req.SourceID, req.TargetID, moved, added)
+
+	h.eventBroker.Broadcast(SSEEvent{
+		Type: "chat:channel:merge",
+		Data: map[string]interface{}{
+			"sourceId":      req.SourceID,
+			"targetId":      req.TargetID,
+			"messagesMoved": moved,
+		},
+	})
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success":       true,
+		"mergeId":       mergeID,
+		"sourceId":      req.SourceID,
+		"targetId":      req.TargetID,
+		"messagesMoved": moved,
+		"membersAdded":  added,
+	})
+}
+
+// readChannel reads the latest version of a channel in the community space.
+func (h *ChatHandler) readChannel(ctx context.Context, spaceID, channelID string) (*anysync.ObjectPayload, ChatChannelData, error) {
+	var data ChatChannelData
+	obj, err := h.spaceManager.ObjectTreeManager().ReadLatestByID(ctx, spaceID, channelID)
+	if err != nil {
+		return nil, data, fmt.Errorf("not found: %w", err)
+	}
+	if obj.Type != "ChatChannel" {
+		return nil, data, fmt.Errorf("%s is not a channel", channelID)
+	}
+	if err := json.Unmarshal(obj.Data, &data); err != nil {
+		return nil, data, fmt.Errorf("invalid channel data: %w", err)
+	}
+	return obj, data, nil
+}
+
+// moveChannelMessages reassigns every message in the source channel to the
+// target and returns how many moved. Each write is conditional on the version
+// read, so a concurrent edit is re-read rather than overwritten.
+func (h *ChatHandler) moveChannelMessages(ctx context.Context, spaceID, sourceID, targetID string) (int, error) {
+	objMgr := h.spaceManager.ObjectTreeManager()
+	page, err := objMgr.ReadObjectsByTypePaged(ctx, spaceID, "ChatMessage", anysync.ObjectQuery{
+		Field: "channelId",
+		Value: sourceID,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	moved := 0
+	for _, obj := range page.Objects {
+		if err := h.moveMessage(ctx, spaceID, obj, sourceID, targetID); err != nil {
+			return moved, fmt.Errorf("message %s: %w", obj.ID, err)
+		}
+		moved++
+	}
+	return moved, nil
+}
+
+// moveMessage sets a message's channelId to targetID.
+func (h *ChatHandler) moveMessage(ctx context.Context, spaceID string, obj *anysync.ObjectPayload, sourceID, targetID string) error {
+	objMgr := h.spaceManager.ObjectTreeManager()
+	for attempt := 0; attempt < maxMergeWriteAttempts; attempt++ {
+		var data ChatMessageData
+		if err := json.Unmarshal(obj.Data, &data); err != nil {
+			return fmt.Errorf("invalid message data: %w", err)
+		}
+		if data.ChannelID != sourceID {
+			return nil
+		}
+		data.ChannelID = targetID
+
+		_, err := h.writeChatObjectIfVersion(ctx, spaceID, obj.ID, "ChatMessage", data, obj.Version)
+		if !errors.Is(err, anysync.ErrVersionConflict) {
+			return err
+		}
+		if obj, err = objMgr.ReadLatestByID(ctx, spaceID, obj.ID); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("gave up after %d conflicting writes", maxMergeWriteAttempts)
+}
+
+// copyChannelMembers joins the source channel's members to the target and
+// returns how many joined. Members who have left the target aren't rejoined.
+func (h *ChatHandler) copyChannelMembers(ctx context.Context, spaceID, sourceID, targetID, now string) int {
+	objMgr := h.spaceManager.ObjectTreeManager()
+	added := 0
+	for _, aid := range ChannelMembers(ctx, h.spaceManager, spaceID)[sourceID] {
+		if _, err := objMgr.ReadLatestByID(ctx, spaceID, channelMembershipID(targetID, aid)); err == nil {
+			continue
+		}
+		membership := ChannelMembershipData{ChannelID: targetID, MemberAID: aid, JoinedAt: now}
+		if err := h.saveChannelMembership(ctx, spaceID, membership, 1); err != nil {
+			log.Printf("[Chat] Failed to add %s to merged channel %s: %v", aid, targetID, err)
+			continue
+		}
+		added++
+	}
+	return added
+}
+
+// writeChatObject writes a version of a chat object to the space's ObjectTree.
+func (h *ChatHandler) writeChatObject(ctx context.Context, spaceID, id, objectType string, data interface{}, version int) (*anysync.ObjectPayload, error) {
+	return h.writeChatObjectIfVersion(ctx, spaceID, id, objectType, data, version-1)
+}
+
+// writeChatObjectIfVersion writes the next version of a chat object, failing
+// with anysync.ErrVersionConflict unless the stored version is expectedVersion.
+func (h *ChatHandler) writeChatObjectIfVersion(ctx context.Context, spaceID, id, objectType string, data interface{}, expectedVersion int) (*anysync.ObjectPayload, error) {
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling %s data: %w", objectType, err)
+	}
+
+	client := h.spaceManager.GetClient()
+	if client == nil {
+		return nil, fmt.Errorf("any-sync client not available")
+	}
+	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), spaceID, client.GetSigningKey())
+	if err != nil {
+		return nil, fmt.Errorf("loading space keys: %w", err)
+	}
+
+	ownerKey := ""
+	if keys.SigningKey != nil {
+		pubKeyBytes, _ := keys.SigningKey.GetPublic().Marshall()
+		if pubKeyBytes != nil {
+			ownerKey = fmt.Sprintf("%x", pubKeyBytes)
+		}
+	}
+
+	payload := &anysync.ObjectPayload{
+		ID:        id,
+		Type:      objectType,
+		OwnerKey:  ownerKey,
+		Data:      dataBytes,
+		Timestamp: time.Now().Unix(),
+		Version:   expectedVersion + 1,
+	}
+	if _, err := h.spaceManager.ObjectTreeManager().CompareAndAddObject(ctx, spaceID, payload, expectedVersion, keys.SigningKey); err != nil {
+		return nil, err
+	}
+	if h.chatListener != nil {
+		h.chatListener.RegisterObject(payload)
+	}
+	return payload, nil
+}
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/matou-dao/backend/internal/contributions"
+)
+
+func registerTestMergeRoutes(env *chatTestEnv) {
+	env.chatHandler.RegisterAdminRoutes(env.mux, &mockRoleLookup{roles: map[string][]contributions.Role{
+		"EAdmin": {contributions.RoleFoundingMember},
+	}})
+}
+
+func mergeTestChannels(env *chatTestEnv, aid, sourceID, targetID string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(MergeChannelsRequest{SourceID: sourceID, TargetID: targetID})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/chat/channels/merge", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-AID", aid)
+	w := httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	return w
+}
+
+func listTestMessageIDs(t *testing.T, env *chatTestEnv, channelID string) []string {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/chat/channels/"+channelID+"/messages", nil)
+	w := httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	var resp struct {
+		Messages []MessageResponse `json:"messages"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	var ids []string
+	for _, m := range resp.Messages {
+		ids = append(ids, m.ID)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func TestChat_MergeChannels(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+	registerTestMergeRoutes(env)
+
+	targetID := createTestChannel(t, env, "General")
+	sourceID := createTestChannel(t, env, "general")
+	kept := sendTestMessage(t, env, targetID, "already here")
+	movedA := sendTestMessage(t, env, sourceID, "from the other side")
+	movedB := sendTestMessage(t, env, sourceID, "of the partition")
+
+	ctx := context.Background()
+	spaceID := env.spaceManager.GetCommunitySpaceID()
+	membership := ChannelMembershipData{ChannelID: sourceID, MemberAID: "EOther", JoinedAt: time.Now().UTC().Format(time.RFC3339)}
+	if err := env.chatHandler.saveChannelMembership(ctx, spaceID, membership, 1); err != nil {
+		t.Fatalf("saving membership: %v", err)
+	}
+
+	w := mergeTestChannels(env, "EAdmin", sourceID, targetID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		MessagesMoved int    `json:"messagesMoved"`
+		MembersAdded  int    `json:"membersAdded"`
+		MergeID       string `json:"mergeId"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.MessagesMoved != 2 || resp.MembersAdded != 1 {
+		t.Errorf("expected 2 messages and 1 member moved, got %+v", resp)
+	}
+
+	want := []string{kept, movedA, movedB}
+	slices.Sort(want)
+	if got := listTestMessageIDs(t, env, targetID); !slices.Equal(got, want) {
+		t.Errorf("target messages: got %v, want %v", got, want)
+	}
+	if got := listTestMessageIDs(t, env, sourceID); len(got) != 0 {
+		t.Errorf("expected no messages left in the source, got %v", got)
+	}
+
+	_, source, err := env.chatHandler.readChannel(ctx, spaceID, sourceID)
+	if err != nil {
+		t.Fatalf("reading source: %v", err)
+	}
+	if !source.IsArchived || source.MergedInto != targetID {
+		t.Errorf("expected the source archived and merged into %s, got %+v", targetID, source)
+	}
+	if !slices.Contains(ChannelMembers(ctx, env.spaceManager, spaceID)[targetID], "EOther") {
+		t.Error("expected the source's members to join the target")
+	}
+
+	obj, err := env.spaceManager.ObjectTreeManager().ReadLatestByID(ctx, spaceID, resp.MergeID)
+	if err != nil {
+		t.Fatalf("reading merge record: %v", err)
+	}
+	var record ChannelMergeData
+	json.Unmarshal(obj.Data, &record)
+	if obj.Type != ChannelMergeObjectType || record.SourceID != sourceID || record.TargetID != targetID ||
+		record.MergedBy != "EAdmin" || record.MessagesMoved != 2 {
+		t.Errorf("unexpected merge record %s: %+v", obj.Type, record)
+	}
+
+	// The archived source can't be merged again
+	if w := mergeTestChannels(env, "EAdmin", sourceID, targetID); w.Code != http.StatusConflict {
+		t.Errorf("expected 409 merging an archived channel, got %d", w.Code)
+	}
+}
+
+func TestChat_MergeChannelsRequiresAdmin(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+	registerTestMergeRoutes(env)
+
+	targetID := createTestChannel(t, env, "one")
+	sourceID := createTestChannel(t, env, "two")
+	if w := mergeTestChannels(env, "EMember", sourceID, targetID); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403, got %d", w.Code)
+	}
+	if w := mergeTestChannels(env, "EAdmin", sourceID, sourceID); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 merging a channel into itself, got %d", w.Code)
+	}
+	if w := mergeTestChannels(env, "EAdmin", "ChatChannel-missing", targetID); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for a missing source, got %d", w.Code)
+	}
+}
+
+func TestChat_MergeChannelsKeepsRoleLimits(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+	registerTestMergeRoutes(env)
+
+	createWithRoles := func(roles string) string {
+		w := createChannelWithRoles(env, roles)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body.String())
+		}
+		var created map[string]interface{}
+		json.NewDecoder(w.Body).Decode(&created)
+		return created["channelId"].(string)
+	}
+	sourceID := createWithRoles(`["Community Steward"]`)
+	openID := createTestChannel(t, env, "restricted")
+	wideID := createWithRoles(`["Community Steward","Member"]`)
+	sameID := createWithRoles(`["community steward"]`)
+
+	for _, targetID := range []string{openID, wideID} {
+		if w := mergeTestChannels(env, "EAdmin", sourceID, targetID); w.Code != http.StatusConflict {
+			t.Errorf("merge into a wider channel: expected 409, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+	if w := mergeTestChannels(env, "EAdmin", sourceID, sameID); w.Code != http.StatusOK {
+		t.Errorf("merge into an equally limited channel: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestChat_ListDuplicateChannels(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+	registerTestMergeRoutes(env)
+
+	first := createTestChannel(t, env, "Random")
+	second := createTestChannel(t, env, " random ")
+	createTestChannel(t, env, "unique")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/chat/channels/duplicates", nil)
+	req.Header.Set("X-User-AID", "EAdmin")
+	w := httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Duplicates []DuplicateChannelGroup `json:"duplicates"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if len(resp.Duplicates) != 1 || len(resp.Duplicates[0].Channels) != 2 {
+		t.Fatalf("expected one group of two channels, got %+v", resp.Duplicates)
+	}
+	ids := []string{resp.Duplicates[0].Channels[0].ID, resp.Duplicates[0].Channels[1].ID}
+	if !slices.Contains(ids, first) || !slices.Contains(ids, second) {
+		t.Errorf("expected %s and %s, got %v", first, second, ids)
+	}
+}
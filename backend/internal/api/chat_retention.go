@@ -0,0 +1,199 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/matou-dao/backend/internal/anysync"
+)
+
+// retentionPruneGrace is how long an expired message stays in the local
+// anystore index after its retention window ends, giving the soft-delete
+// time to reach peers before the cached copy is dropped.
+const retentionPruneGrace = 24 * time.Hour
+
+// retentionCutoff returns the time before which messages in a channel with
+// the given retention are expired, or the zero time if they never expire.
+func retentionCutoff(retentionDays int, now time.Time) time.Time {
+	if retentionDays <= 0 {
+		return time.Time{}
+	}
+	return now.AddDate(0, 0, -retentionDays)
+}
+
+// messageExpired reports whether a message sent at sentAt is older than cutoff.
+// A zero cutoff or an unparseable timestamp never expires.
+func messageExpired(sentAt string, cutoff time.Time) bool {
+	if cutoff.IsZero() {
+		return false
+	}
+	sent, err := time.Parse(time.RFC3339, sentAt)
+	return err == nil && sent.Before(cutoff)
+}
+
+// channelRetentionCutoffs returns the retention cutoff of every channel in the
+// space that has a retention window, keyed by channel ID.
+func (h *ChatHandler) channelRetentionCutoffs(ctx context.Context, communitySpaceID string, now time.Time) map[string]time.Time {
+	cutoffs := make(map[string]time.Time)
+	objects, err := h.spaceManager.ObjectTreeManager().ReadObjectsByType(ctx, communitySpaceID, "ChatChannel")
+	if err != nil {
+		return cutoffs
+	}
+
+	versions := make(map[string]int)
+	for _, obj := range objects {
+		if obj.Version <= versions[obj.ID] {
+			continue
+		}
+		var data ChatChannelData
+		if err := json.Unmarshal(obj.Data, &data); err != nil {
+			continue
+		}
+		versions[obj.ID] = obj.Version
+		if cutoff := retentionCutoff(data.RetentionDays, now); !cutoff.IsZero() {
+			cutoffs[obj.ID] = cutoff
+		} else {
+			delete(cutoffs, obj.ID)
+		}
+	}
+	return cutoffs
+}
+
+// StartRetentionSweeper begins a background loop that expires messages in
+// channels with a retention window (see SweepExpiredMessages).
+func (h *ChatHandler) StartRetentionSweeper(interval time.Duration) {
+	h.loop.start(interval, func(ctx context.Context, now time.Time) {
+		h.SweepExpiredMessages(ctx, now)
+	})
+	log.Printf("[Chat] Started retention sweeper (interval=%s)", interval)
+}
+
+// StopRetentionSweeper stops the loop started by StartRetentionSweeper.
+func (h *ChatHandler) StopRetentionSweeper() {
+	h.loop.stop()
+}
+
+// SweepExpiredMessages soft-deletes this user's messages that are older than
+// their channel's retention window, and prunes expired messages from the local
+// anystore index once retentionPruneGrace has also passed. Each peer only
+// deletes messages it sent, so peers don't race to write the same tombstone;
+// reads hide expired messages regardless. The object tree keeps the history.
+func (h *ChatHandler) SweepExpiredMessages(ctx context.Context, now time.Time) {
+	communitySpaceID := h.spaceManager.GetCommunitySpaceID()
+	if communitySpaceID == "" {
+		return
+	}
+	cutoffs := h.channelRetentionCutoffs(ctx, communitySpaceID, now)
+	if len(cutoffs) == 0 {
+		return
+	}
+
+	currentAID := ""
+	if h.userIdentity != nil {
+		currentAID = h.userIdentity.GetAID()
+	}
+
+	if currentAID != "" {
+		objMgr := h.spaceManager.ObjectTreeManager()
+		objects, err := objMgr.ReadObjectsByType(ctx, communitySpaceID, "ChatMessage")
+		if err != nil {
+			log.Printf("[Chat] Retention sweep failed to read messages: %v", err)
+			return
+		}
+
+		latest := make(map[string]*messageEntry)
+		for _, obj := range objects {
+			var data ChatMessageData
+			if err := json.Unmarshal(obj.Data, &data); err != nil {
+				continue
+			}
+			if existing, ok := latest[obj.ID]; !ok || obj.Version > existing.obj.Version {
+				latest[obj.ID] = &messageEntry{obj: obj, data: data}
+			}
+		}
+
+		for _, m := range latest {
+			cutoff, ok := cutoffs[m.data.ChannelID]
+			if !ok || m.data.DeletedAt != "" || m.data.SenderAID != currentAID || !messageExpired(m.data.SentAt, cutoff) {
+				continue
+			}
+			if err := h.expireMessage(ctx, communitySpaceID, m, now); err != nil {
+				log.Printf("[Chat] Retention sweep failed to expire message %s: %v", m.obj.ID, err)
+			}
+		}
+	}
+
+	if h.store != nil {
+		for channelID, cutoff := range cutoffs {
+			before := cutoff.Add(-retentionPruneGrace).UTC().Format(time.RFC3339)
+			n, err := h.store.DeleteMessagesSentBefore(ctx, channelID, before)
+			if err != nil {
+				log.Printf("[Chat] Retention sweep failed to prune channel %s: %v", channelID, err)
+			} else if n > 0 {
+				log.Printf("[Chat] Pruned %d expired messages from channel %s", n, channelID)
+			}
+		}
+	}
+}
+
+// expireMessage writes a soft-delete for an expired message. The write is
+// conditional on the version that was read, so an edit that lands first is
+// picked up by the next sweep instead of being overwritten.
+func (h *ChatHandler) expireMessage(ctx context.Context, communitySpaceID string, m *messageEntry, now time.Time) error {
+	client := h.spaceManager.GetClient()
+	if client == nil {
+		return errors.New("any-sync client not available")
+	}
+	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), communitySpaceID, client.GetSigningKey())
+	if err != nil {
+		return err
+	}
+
+	ownerKey := ""
+	if keys.SigningKey != nil {
+		pubKeyBytes, _ := keys.SigningKey.GetPublic().Marshall()
+		if pubKeyBytes != nil {
+			ownerKey = fmt.Sprintf("%x", pubKeyBytes)
+		}
+	}
+
+	data := m.data
+	data.DeletedAt = now.UTC().Format(time.RFC3339)
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	payload := &anysync.ObjectPayload{
+		ID:        m.obj.ID,
+		Type:      "ChatMessage",
+		OwnerKey:  ownerKey,
+		Data:      dataBytes,
+		Timestamp: now.Unix(),
+		Version:   m.obj.Version + 1,
+	}
+	objMgr := h.spaceManager.ObjectTreeManager()
+	if _, err := objMgr.CompareAndAddObject(ctx, communitySpaceID, payload, m.obj.Version, keys.SigningKey); err != nil {
+		if errors.Is(err, anysync.ErrVersionConflict) {
+			return nil
+		}
+		return err
+	}
+
+	if h.chatListener != nil {
+		h.chatListener.RegisterObject(payload)
+	}
+	h.eventBroker.Broadcast(SSEEvent{
+		Type: "chat:message:delete",
+		Data: map[string]interface{}{
+			"messageId": m.obj.ID,
+			"channelId": data.ChannelID,
+			"deletedAt": data.DeletedAt,
+		},
+	})
+	return nil
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matou-dao/backend/internal/anysync"
+)
+
+// addTestMessageSentAt writes a message with the given sentAt straight to the
+// object tree, bypassing HandleSendMessage which always stamps the current time.
+func addTestMessageSentAt(t *testing.T, env *chatTestEnv, channelID, id, content string, sentAt time.Time) {
+	t.Helper()
+	spaceID := env.spaceManager.GetCommunitySpaceID()
+	client := env.spaceManager.GetClient()
+	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), spaceID, client.GetSigningKey())
+	if err != nil {
+		t.Fatalf("loading space keys: %v", err)
+	}
+	data, _ := json.Marshal(ChatMessageData{
+		ChannelID: channelID,
+		SenderAID: env.userIdentity.GetAID(),
+		Content:   content,
+		SentAt:    sentAt.UTC().Format(time.RFC3339),
+	})
+	payload := &anysync.ObjectPayload{ID: id, Type: "ChatMessage", Data: data, Timestamp: sentAt.Unix(), Version: 1}
+	if _, err := env.spaceManager.ObjectTreeManager().AddObject(context.Background(), spaceID, payload, keys.SigningKey); err != nil {
+		t.Fatalf("adding message: %v", err)
+	}
+}
+
+func setTestChannelRetention(t *testing.T, env *chatTestEnv, channelID, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/chat/channels/"+channelID, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	return w
+}
+
+func TestChat_RetentionExcludesExpiredMessages(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	channelID := createTestChannel(t, env, "ephemeral")
+	otherID := createTestChannel(t, env, "forever")
+	if w := setTestChannelRetention(t, env, channelID, `{"retentionDays":7}`); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 setting retention, got %d: %s", w.Code, w.Body.String())
+	}
+
+	old := time.Now().AddDate(0, 0, -10)
+	addTestMessageSentAt(t, env, channelID, "ChatMessage-old", "expired", old)
+	addTestMessageSentAt(t, env, otherID, "ChatMessage-old-kept", "kept", old)
+	freshID := sendTestMessage(t, env, channelID, "fresh")
+
+	list := func(channelID string) []MessageResponse {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/chat/channels/"+channelID+"/messages", nil)
+		w := httptest.NewRecorder()
+		env.mux.ServeHTTP(w, req)
+		var resp struct {
+			Messages []MessageResponse `json:"messages"`
+		}
+		json.NewDecoder(w.Body).Decode(&resp)
+		return resp.Messages
+	}
+
+	if msgs := list(channelID); len(msgs) != 1 || msgs[0].ID != freshID {
+		t.Errorf("expected only the fresh message, got %+v", msgs)
+	}
+	if msgs := list(otherID); len(msgs) != 1 || msgs[0].ID != "ChatMessage-old-kept" {
+		t.Errorf("expected the old message in a channel without retention, got %+v", msgs)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/chat/messages/batch", bytes.NewBufferString(`{"messageIds":["ChatMessage-old"]}`))
+	w := httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	var batch struct {
+		Missing []string `json:"missing"`
+	}
+	json.NewDecoder(w.Body).Decode(&batch)
+	if len(batch.Missing) != 1 {
+		t.Errorf("expected the expired message to be missing from batch, got %s", w.Body.String())
+	}
+
+	// Removing the retention window brings the history back
+	if w := setTestChannelRetention(t, env, channelID, `{"retentionDays":0}`); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 clearing retention, got %d", w.Code)
+	}
+	if msgs := list(channelID); len(msgs) != 2 {
+		t.Errorf("expected 2 messages without retention, got %d", len(msgs))
+	}
+}
+
+func TestChat_RetentionSweepSoftDeletes(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	channelID := createTestChannel(t, env, "sweep")
+	setTestChannelRetention(t, env, channelID, `{"retentionDays":1}`)
+	addTestMessageSentAt(t, env, channelID, "ChatMessage-sweep-old", "expired", time.Now().Add(-48*time.Hour))
+	freshID := sendTestMessage(t, env, channelID, "fresh")
+
+	env.chatHandler.SweepExpiredMessages(context.Background(), time.Now())
+
+	objMgr := env.spaceManager.ObjectTreeManager()
+	spaceID := env.spaceManager.GetCommunitySpaceID()
+	for id, wantDeleted := range map[string]bool{"ChatMessage-sweep-old": true, freshID: false} {
+		obj, err := objMgr.ReadLatestByID(context.Background(), spaceID, id)
+		if err != nil {
+			t.Fatalf("reading %s: %v", id, err)
+		}
+		var data ChatMessageData
+		json.Unmarshal(obj.Data, &data)
+		if (data.DeletedAt != "") != wantDeleted {
+			t.Errorf("%s: expected deleted=%v, got deletedAt=%q", id, wantDeleted, data.DeletedAt)
+		}
+		if wantDeleted && data.Content != "expired" {
+			t.Errorf("expected the tree to keep the expired message's history, got %q", data.Content)
+		}
+	}
+}
+
+func TestChat_RetentionRejectsNegative(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	channelID := createTestChannel(t, env, "negative")
+	if w := setTestChannelRetention(t, env, channelID, `{"retentionDays":-1}`); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
@@ -2,19 +2,27 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"slices"
+	"strings"
 	"sync"
 	"testing"
 
 	"github.com/anyproto/any-sync/commonspace/object/tree/objecttree"
 	"github.com/anyproto/any-sync/commonspace/object/tree/objecttree/mock_objecttree"
 	"github.com/anyproto/any-sync/util/crypto"
+	"github.com/matou-dao/backend/internal/anystore"
 	"github.com/matou-dao/backend/internal/anysync"
+	"github.com/matou-dao/backend/internal/contributions"
 	"github.com/matou-dao/backend/internal/identity"
+	"github.com/matou-dao/backend/internal/types"
 	"go.uber.org/mock/gomock"
 )
 
@@ -102,7 +110,7 @@ func setupStatefulMock(ctrl *gomock.Controller, state *statefulMockTree) *mock_o
 	return mockTree
 }
 
-func setupChatTestEnv(t *testing.T) *chatTestEnv {
+func setupChatTestEnv(t testing.TB) *chatTestEnv {
 	t.Helper()
 
 	ctrl := gomock.NewController(t)
@@ -245,6 +253,186 @@ func TestChat_CreateChannel_MissingName(t *testing.T) {
 	}
 }
 
+func createChannelWithRoles(env *chatTestEnv, roles string) *httptest.ResponseRecorder {
+	body := `{"name":"restricted","allowedRoles":` + roles + `}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/chat/channels", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	return w
+}
+
+func readChannelData(t *testing.T, env *chatTestEnv, channelID string) ChatChannelData {
+	t.Helper()
+	obj, err := env.spaceManager.ObjectTreeManager().ReadLatestByID(context.Background(), env.spaceManager.GetCommunitySpaceID(), channelID)
+	if err != nil {
+		t.Fatalf("reading channel %s: %v", channelID, err)
+	}
+	var data ChatChannelData
+	json.Unmarshal(obj.Data, &data)
+	return data
+}
+
+func TestChat_CreateChannel_ValidAllowedRoles(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	w := createChannelWithRoles(env, `["Community Steward","founding member"]`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&resp)
+
+	// Roles are stored with their canonical spelling
+	data := readChannelData(t, env, resp["channelId"].(string))
+	if !slices.Equal(data.AllowedRoles, []string{"Community Steward", "Founding Member"}) {
+		t.Errorf("expected canonical roles, got %v", data.AllowedRoles)
+	}
+}
+
+func TestChat_CreateChannel_DefaultChannelRoles(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+	// The community space stands in for the admin space, which has no
+	// test tree factory of its own here
+	ctx := context.Background()
+	adminSpaceID := env.spaceManager.GetCommunitySpaceID()
+	env.spaceManager.SetAdminSpaceID(adminSpaceID)
+	settings := defaultAdminSettings()
+	settings.DefaultChannelRoles = []string{"Community Steward"}
+	if err := saveAdminSettings(ctx, env.spaceManager, adminSpaceID, settings, 0); err != nil {
+		t.Fatalf("saving settings: %v", err)
+	}
+
+	// Without allowedRoles the defaults apply; an empty list keeps it open
+	data := readChannelData(t, env, createTestChannel(t, env, "defaulted"))
+	if !slices.Equal(data.AllowedRoles, []string{"Community Steward"}) {
+		t.Errorf("expected the default channel roles, got %v", data.AllowedRoles)
+	}
+	w := createChannelWithRoles(env, `[]`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if data := readChannelData(t, env, resp["channelId"].(string)); len(data.AllowedRoles) != 0 {
+		t.Errorf("expected an explicitly open channel, got %v", data.AllowedRoles)
+	}
+}
+
+func TestChat_AllowedRoles_RejectsUnknownRole(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	if w := createChannelWithRoles(env, `["Member","Admn"]`); w.Code != http.StatusBadRequest {
+		t.Errorf("create: expected 400 for an unknown role, got %d: %s", w.Code, w.Body.String())
+	}
+
+	channelID := createTestChannel(t, env, "open")
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/chat/channels/"+channelID, bytes.NewBufferString(`{"allowedRoles":["Admn"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("update: expected 400 for an unknown role, got %d: %s", w.Code, w.Body.String())
+	}
+	if data := readChannelData(t, env, channelID); len(data.AllowedRoles) != 0 {
+		t.Errorf("expected the channel to stay open, got %v", data.AllowedRoles)
+	}
+}
+
+func TestChat_CreateChannel_EmptyAllowedRolesIsOpen(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	w := createChannelWithRoles(env, `[]`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&resp)
+	channelID := resp["channelId"].(string)
+
+	getW := httptest.NewRecorder()
+	env.mux.ServeHTTP(getW, httptest.NewRequest(http.MethodGet, "/api/v1/chat/channels/"+channelID, nil))
+	if getW.Code != http.StatusOK {
+		t.Errorf("expected an open channel to be readable, got %d: %s", getW.Code, getW.Body.String())
+	}
+}
+
+// setChatRoles gives each AID the roles its org role grants, as the profile
+// role lookup does, and returns the lookup so a test can change them.
+func setChatRoles(env *chatTestEnv, orgRoles map[string]string) *mockRoleLookup {
+	lookup := &mockRoleLookup{roles: map[string][]contributions.Role{}}
+	for aid, role := range orgRoles {
+		lookup.roles[aid] = contributions.MapKERIRole(role)
+	}
+	env.chatHandler.roleLookup = lookup
+	return lookup
+}
+
+func TestChat_AllowedRoles_GatesPosting(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+	const steward, member = "ETEST_CHAT_USER01", "ETEST_CHAT_MEMBER"
+	roles := setChatRoles(env, map[string]string{steward: "Community Steward", member: "Member"})
+
+	w := createChannelWithRoles(env, `["Community Steward"]`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&created)
+	channelID := created["channelId"].(string)
+	openChannelID := createTestChannel(t, env, "open")
+
+	do := func(method, path, body string) int {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		env.mux.ServeHTTP(w, req)
+		return w.Code
+	}
+	messageID := sendTestMessage(t, env, channelID, "stewards only")
+
+	env.userIdentity.SetIdentity(member, "member-mnemonic")
+	if code := do(http.MethodPost, "/api/v1/chat/channels/"+channelID+"/messages", `{"content":"let me in"}`); code != http.StatusForbidden {
+		t.Errorf("member send to gated channel: expected 403, got %d", code)
+	}
+	if code := do(http.MethodPost, "/api/v1/chat/messages/"+messageID+"/reactions", `{"emoji":"👍"}`); code != http.StatusForbidden {
+		t.Errorf("member reaction in gated channel: expected 403, got %d", code)
+	}
+	if code := do(http.MethodPost, "/api/v1/chat/channels/"+openChannelID+"/messages", `{"content":"hello"}`); code != http.StatusCreated {
+		t.Errorf("member send to open channel: expected 201, got %d", code)
+	}
+
+	// A steward who loses the role can no longer edit what they posted there
+	env.userIdentity.SetIdentity(steward, "test-mnemonic")
+	roles.roles[steward] = contributions.MapKERIRole("Member")
+	if code := do(http.MethodPut, "/api/v1/chat/messages/"+messageID, `{"content":"edited"}`); code != http.StatusForbidden {
+		t.Errorf("edit after losing role: expected 403, got %d", code)
+	}
+}
+
+func TestChat_AllowedRoles_UnresolvableFailsClosed(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	for path, body := range map[string]string{
+		"/api/v1/chat/channels/no-such-channel/messages":  `{"content":"hello"}`,
+		"/api/v1/chat/messages/no-such-message/reactions": `{"emoji":"👍"}`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		env.mux.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s: expected 404, got %d: %s", path, w.Code, w.Body.String())
+		}
+	}
+}
+
 func TestChat_ListChannels(t *testing.T) {
 	env := setupChatTestEnv(t)
 	defer env.cleanup()
@@ -364,6 +552,72 @@ func TestChat_UpdateChannel(t *testing.T) {
 	}
 }
 
+func TestChat_UpdateChannel_ConcurrentEditConflict(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	channelID := createTestChannel(t, env, "contested")
+
+	// Both editors load the same version of the channel
+	getW := httptest.NewRecorder()
+	env.mux.ServeHTTP(getW, httptest.NewRequest(http.MethodGet, "/api/v1/chat/channels/"+channelID, nil))
+	var loaded ChannelResponse
+	json.NewDecoder(getW.Body).Decode(&loaded)
+	if loaded.Version == 0 {
+		t.Fatalf("expected the channel to report its version: %+v", loaded)
+	}
+
+	names := []string{"editor-a", "editor-b"}
+	codes := make([]int, len(names))
+	bodies := make([]string, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			body := fmt.Sprintf(`{"name":"%s","version":%d}`, name, loaded.Version)
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/chat/channels/"+channelID, bytes.NewBufferString(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			env.mux.ServeHTTP(w, req)
+			codes[i], bodies[i] = w.Code, w.Body.String()
+		}(i, name)
+	}
+	wg.Wait()
+
+	winner, loser := 0, 1
+	if codes[0] != http.StatusOK {
+		winner, loser = 1, 0
+	}
+	if codes[winner] != http.StatusOK || codes[loser] != http.StatusConflict {
+		t.Fatalf("expected one 200 and one 409, got %v: %v", codes, bodies)
+	}
+
+	// The loser gets the winner's state back to merge against
+	var conflict struct {
+		Error   string                `json:"error"`
+		Current anysync.ObjectPayload `json:"current"`
+	}
+	json.Unmarshal([]byte(bodies[loser]), &conflict)
+	var current ChatChannelData
+	json.Unmarshal(conflict.Current.Data, &current)
+	if current.Name != names[winner] || conflict.Current.Version != loaded.Version+1 {
+		t.Errorf("expected the winner's state at version %d, got %q at %d", loaded.Version+1, current.Name, conflict.Current.Version)
+	}
+
+	// A direct write of the version the winner already wrote is rejected too
+	payload := &anysync.ObjectPayload{
+		ID:      channelID,
+		Type:    "ChatChannel",
+		Data:    json.RawMessage(`{"name":"` + names[loser] + `"}`),
+		Version: loaded.Version + 1,
+	}
+	_, err := env.spaceManager.ObjectTreeManager().AddObject(context.Background(), env.spaceManager.GetCommunitySpaceID(), payload, nil)
+	if !errors.Is(err, anysync.ErrVersionConflict) {
+		t.Errorf("expected ErrVersionConflict writing an existing version, got %v", err)
+	}
+}
+
 func TestChat_ArchiveChannel(t *testing.T) {
 	env := setupChatTestEnv(t)
 	defer env.cleanup()
@@ -641,6 +895,195 @@ func TestChat_MessageThread(t *testing.T) {
 	}
 }
 
+func TestChat_BatchMessages(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	channelID := createTestChannel(t, env, "msg-batch")
+	keptID := sendTestMessage(t, env, channelID, "Still here")
+	deletedID := sendTestMessage(t, env, channelID, "Soon gone")
+
+	reactReq := httptest.NewRequest(http.MethodPost, "/api/v1/chat/messages/"+keptID+"/reactions", bytes.NewBufferString(`{"emoji":"👍"}`))
+	reactReq.Header.Set("Content-Type", "application/json")
+	env.mux.ServeHTTP(httptest.NewRecorder(), reactReq)
+
+	deleteReq := httptest.NewRequest(http.MethodDelete, "/api/v1/chat/messages/"+deletedID, nil)
+	deleteW := httptest.NewRecorder()
+	env.mux.ServeHTTP(deleteW, deleteReq)
+	if deleteW.Code != http.StatusOK {
+		t.Fatalf("failed to delete message: %d %s", deleteW.Code, deleteW.Body.String())
+	}
+
+	body := fmt.Sprintf(`{"messageIds":["%s","missing-message","%s","%s"]}`, deletedID, keptID, deletedID)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/chat/messages/batch", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Messages []MessageResponse `json:"messages"`
+		Missing  []string          `json:"missing"`
+		Count    int               `json:"count"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+
+	if resp.Count != 2 || len(resp.Messages) != 2 {
+		t.Fatalf("expected 2 messages, got count=%d len=%d", resp.Count, len(resp.Messages))
+	}
+
+	// Request order is kept and duplicates dropped
+	tombstone, kept := resp.Messages[0], resp.Messages[1]
+	if tombstone.ID != deletedID || kept.ID != keptID {
+		t.Fatalf("expected [%s %s], got [%s %s]", deletedID, keptID, tombstone.ID, kept.ID)
+	}
+	if tombstone.DeletedAt == "" {
+		t.Error("expected deleted message to have deletedAt")
+	}
+	if tombstone.Content != "" {
+		t.Errorf("expected deleted message content to be omitted, got %q", tombstone.Content)
+	}
+	if kept.Content != "Still here" {
+		t.Errorf("expected content 'Still here', got %q", kept.Content)
+	}
+	if kept.SenderName == "" {
+		t.Error("expected sender name to be resolved")
+	}
+	if len(kept.Reactions) != 1 || kept.Reactions[0].Emoji != "👍" {
+		t.Errorf("expected one 👍 reaction, got %+v", kept.Reactions)
+	}
+
+	if len(resp.Missing) != 1 || resp.Missing[0] != "missing-message" {
+		t.Errorf("expected missing [missing-message], got %v", resp.Missing)
+	}
+}
+
+// getTestMessage fetches one message through GET /api/v1/chat/messages/{id}.
+func getTestMessage(env *chatTestEnv, messageID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/chat/messages/"+messageID, nil)
+	w := httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	return w
+}
+
+func TestChat_GetMessage(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	channelID := createTestChannel(t, env, "msg-get")
+	messageID := sendTestMessage(t, env, channelID, "Permalink me")
+
+	reactReq := httptest.NewRequest(http.MethodPost, "/api/v1/chat/messages/"+messageID+"/reactions", bytes.NewBufferString(`{"emoji":"👍"}`))
+	reactReq.Header.Set("Content-Type", "application/json")
+	env.mux.ServeHTTP(httptest.NewRecorder(), reactReq)
+
+	w := getTestMessage(env, messageID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var msg MessageResponse
+	json.NewDecoder(w.Body).Decode(&msg)
+	if msg.ID != messageID || msg.ChannelID != channelID || msg.Content != "Permalink me" {
+		t.Errorf("unexpected message %+v", msg)
+	}
+	if msg.SenderName == "" {
+		t.Error("expected sender name to be resolved")
+	}
+	if len(msg.Reactions) != 1 || msg.Reactions[0].Emoji != "👍" || !msg.Reactions[0].HasReacted {
+		t.Errorf("expected the caller's 👍 reaction, got %+v", msg.Reactions)
+	}
+
+	if w := getTestMessage(env, "missing-message"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown message: expected 404, got %d", w.Code)
+	}
+}
+
+func TestChat_GetMessage_Deleted(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	channelID := createTestChannel(t, env, "msg-get-deleted")
+	messageID := sendTestMessage(t, env, channelID, "Soon gone")
+
+	deleteReq := httptest.NewRequest(http.MethodDelete, "/api/v1/chat/messages/"+messageID, nil)
+	deleteW := httptest.NewRecorder()
+	env.mux.ServeHTTP(deleteW, deleteReq)
+	if deleteW.Code != http.StatusOK {
+		t.Fatalf("failed to delete message: %d %s", deleteW.Code, deleteW.Body.String())
+	}
+
+	w := getTestMessage(env, messageID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 with the tombstone, got %d: %s", w.Code, w.Body.String())
+	}
+	var msg MessageResponse
+	json.NewDecoder(w.Body).Decode(&msg)
+	if msg.ID != messageID || msg.DeletedAt == "" {
+		t.Errorf("expected a tombstone with deletedAt, got %+v", msg)
+	}
+	if msg.Content != "" {
+		t.Errorf("expected deleted message content to be omitted, got %q", msg.Content)
+	}
+}
+
+func TestChat_GetMessage_RoleGated(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+	const steward, member = "ETEST_CHAT_USER01", "ETEST_CHAT_MEMBER"
+	setChatRoles(env, map[string]string{steward: "Community Steward", member: "Member"})
+
+	w := createChannelWithRoles(env, `["Community Steward"]`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&created)
+	messageID := sendTestMessage(t, env, created["channelId"].(string), "stewards only")
+
+	if w := getTestMessage(env, messageID); w.Code != http.StatusOK {
+		t.Errorf("steward: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	env.userIdentity.SetIdentity(member, "member-mnemonic")
+	w = getTestMessage(env, messageID)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("member: expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "stewards only") {
+		t.Error("expected the gated message's content to be withheld")
+	}
+
+	// The batch lookup withholds it too
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/chat/messages/batch", strings.NewReader(`{"messageIds":["`+messageID+`"]}`))
+	w = httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	var batch struct {
+		Messages []MessageResponse `json:"messages"`
+		Missing  []string          `json:"missing"`
+	}
+	json.NewDecoder(w.Body).Decode(&batch)
+	if len(batch.Messages) != 0 || len(batch.Missing) != 1 || batch.Missing[0] != messageID {
+		t.Errorf("batch: expected the gated message listed as missing, got %+v", batch)
+	}
+}
+
+func TestChat_BatchMessages_NoIDs(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/chat/messages/batch", bytes.NewBufferString(`{"messageIds":[]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
 // --- Reaction Tests ---
 
 func TestChat_AddReaction(t *testing.T) {
@@ -742,14 +1185,326 @@ func TestChat_RemoveReaction(t *testing.T) {
 	}
 }
 
+func TestChat_RemoveSoleReactorTombstonesReaction(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	channelID := createTestChannel(t, env, "react-sole")
+	messageID := sendTestMessage(t, env, channelID, "Fleeting")
+
+	react := func(method, path, body string) {
+		t.Helper()
+		req := httptest.NewRequest(method, "/api/v1/chat/messages/"+messageID+"/reactions"+path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		env.mux.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s reaction%s: %d %s", method, path, w.Code, w.Body.String())
+		}
+	}
+	fetchReactions := func() []ReactionAggregate {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/chat/messages/batch", bytes.NewBufferString(`{"messageIds":["`+messageID+`"]}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		env.mux.ServeHTTP(w, req)
+		var resp struct {
+			Messages []MessageResponse `json:"messages"`
+		}
+		json.NewDecoder(w.Body).Decode(&resp)
+		if len(resp.Messages) != 1 {
+			t.Fatalf("expected the message back, got %s", w.Body.String())
+		}
+		return resp.Messages[0].Reactions
+	}
+
+	react(http.MethodPost, "", `{"emoji":"🔥"}`)
+	react(http.MethodPost, "", `{"emoji":"👍"}`)
+	react(http.MethodDelete, "/🔥", "")
+
+	if got := fetchReactions(); len(got) != 1 || got[0].Emoji != "👍" || got[0].Count != 1 {
+		t.Errorf("expected only the 👍 reaction, got %+v", got)
+	}
+
+	obj, err := env.spaceManager.ObjectTreeManager().ReadLatestByID(context.Background(), env.spaceManager.GetCommunitySpaceID(), reactionObjectID(messageID, "🔥"))
+	if err != nil {
+		t.Fatalf("reading reaction: %v", err)
+	}
+	var data MessageReactionData
+	json.Unmarshal(obj.Data, &data)
+	if data.RemovedAt == "" || len(data.ReactorAIDs) != 0 {
+		t.Errorf("expected an emptied reaction to be tombstoned, got %+v", data)
+	}
+
+	// Reacting again brings the emoji back
+	react(http.MethodPost, "", `{"emoji":"🔥"}`)
+	if got := fetchReactions(); len(got) != 2 {
+		t.Errorf("expected 🔥 to return after re-reacting, got %+v", got)
+	}
+
+	// Zero-count reactions cached before tombstoning are skipped as well
+	legacy := []*anystore.ChatReaction{{Emoji: "👀"}, {Emoji: "👍", ReactorAIDs: []string{"EA"}}}
+	if got := aggregateStoreReactions(legacy, "EA"); len(got) != 1 || got[0].Emoji != "👍" {
+		t.Errorf("expected zero-count reactions to be skipped, got %+v", got)
+	}
+}
+
+func TestAggregateReactions_MergesLegacyAndHashedIDs(t *testing.T) {
+	// The same emoji stored under its legacy and its hashed reaction ID
+	legacy := MessageReactionData{MessageID: "msg", Emoji: "👍", ReactorAIDs: []string{"EA", "EB"}, FirstReactedAt: "2026-01-02T00:00:00Z"}
+	hashed := MessageReactionData{MessageID: "msg", Emoji: "👍", ReactorAIDs: []string{"EB", "EC"}, FirstReactedAt: "2026-01-01T00:00:00Z"}
+	other := MessageReactionData{MessageID: "msg", Emoji: "🔥", ReactorAIDs: []string{"ED"}}
+
+	got := aggregateReactions([]MessageReactionData{legacy, other, hashed}, "EC")
+	if len(got) != 2 {
+		t.Fatalf("expected one pill per emoji, got %+v", got)
+	}
+	pill := got[0]
+	if pill.Emoji != "👍" || pill.Count != 3 || !pill.HasReacted || pill.FirstReactedAt != hashed.FirstReactedAt {
+		t.Errorf("unexpected merged pill %+v", pill)
+	}
+	if fmt.Sprint(pill.ReactorAIDs) != "[EA EB EC]" {
+		t.Errorf("reactors = %v, want [EA EB EC]", pill.ReactorAIDs)
+	}
+
+	stored := aggregateStoreReactions([]*anystore.ChatReaction{
+		{ID: legacyReactionObjectID("msg", "👍"), Emoji: "👍", ReactorAIDs: legacy.ReactorAIDs},
+		{ID: reactionObjectID("msg", "👍"), Emoji: "👍", ReactorAIDs: hashed.ReactorAIDs},
+	}, "EA")
+	if len(stored) != 1 || stored[0].Count != 3 || !stored[0].HasReacted {
+		t.Errorf("expected the stored reactions merged into one pill, got %+v", stored)
+	}
+}
+
+func TestChat_MultiCodepointEmojiReaction(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	channelID := createTestChannel(t, env, "react-family")
+	messageID := sendTestMessage(t, env, channelID, "Family photo")
+	base := "/api/v1/chat/messages/" + messageID + "/reactions"
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		env.mux.ServeHTTP(w, req)
+		return w
+	}
+	reactions := func() []ReactionAggregate {
+		t.Helper()
+		w := do(http.MethodPost, "/api/v1/chat/messages/batch", `{"messageIds":["`+messageID+`"]}`)
+		var resp struct {
+			Messages []MessageResponse `json:"messages"`
+		}
+		json.NewDecoder(w.Body).Decode(&resp)
+		if len(resp.Messages) != 1 {
+			t.Fatalf("expected the message back, got %s", w.Body.String())
+		}
+		return resp.Messages[0].Reactions
+	}
+
+	// A ZWJ sequence, and a value that only survives routing if the escaped "/" is kept
+	for _, emoji := range []string{"👨‍👩‍👧‍👦", "🏳️‍🌈", "a/b"} {
+		if w := do(http.MethodPost, base, `{"emoji":"`+emoji+`"}`); w.Code != http.StatusOK {
+			t.Fatalf("adding %q: %d %s", emoji, w.Code, w.Body.String())
+		}
+		if got := reactions(); len(got) != 1 || got[0].Emoji != emoji {
+			t.Fatalf("expected the %q reaction, got %+v", emoji, got)
+		}
+		if w := do(http.MethodDelete, base+"/"+url.PathEscape(emoji), ""); w.Code != http.StatusOK {
+			t.Fatalf("removing %q: %d %s", emoji, w.Code, w.Body.String())
+		}
+		if got := reactions(); len(got) != 0 {
+			t.Errorf("expected %q to be removed, got %+v", emoji, got)
+		}
+	}
+
+	if id := reactionObjectID(messageID, "👨‍👩‍👧‍👦"); id != reactionObjectID(messageID, "👨‍👩‍👧‍👦") || strings.ContainsAny(strings.TrimPrefix(id, "MessageReaction-"+messageID+"-"), "/%👨") {
+		t.Errorf("expected a deterministic, path-safe reaction ID, got %q", id)
+	}
+}
+
+func TestChat_LegacyReactionIDStillUpdated(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	channelID := createTestChannel(t, env, "react-legacy")
+	messageID := sendTestMessage(t, env, channelID, "Old reaction")
+	communitySpaceID := env.spaceManager.GetCommunitySpaceID()
+
+	// A reaction written before IDs were hashed
+	legacyID := legacyReactionObjectID(messageID, "👍")
+	if _, err := env.spaceManager.ObjectTreeManager().AddObject(context.Background(), communitySpaceID, &anysync.ObjectPayload{
+		ID:      legacyID,
+		Type:    "MessageReaction",
+		Data:    json.RawMessage(`{"messageId":"` + messageID + `","emoji":"👍","reactorAids":["EOTHER"]}`),
+		Version: 1,
+	}, nil); err != nil {
+		t.Fatalf("seeding legacy reaction: %v", err)
+	}
+
+	data, payload, err := env.chatHandler.updateReaction(context.Background(), communitySpaceID, messageID, "👍", func(data *MessageReactionData) error {
+		data.ReactorAIDs = append(data.ReactorAIDs, "ENEW")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("updating reaction: %v", err)
+	}
+	if payload.ID != legacyID || payload.Version != 2 || len(data.ReactorAIDs) != 2 {
+		t.Errorf("expected the legacy reaction to be updated in place, got %s v%d %v", payload.ID, payload.Version, data.ReactorAIDs)
+	}
+}
+
+func TestChat_ConcurrentReactions(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	channelID := createTestChannel(t, env, "react-concurrent")
+	messageID := sendTestMessage(t, env, channelID, "Everyone react")
+	communitySpaceID := env.spaceManager.GetCommunitySpaceID()
+
+	const reactors = 25
+	var wg sync.WaitGroup
+	errs := make(chan error, reactors)
+	for i := 0; i < reactors; i++ {
+		wg.Add(1)
+		go func(aid string) {
+			defer wg.Done()
+			_, _, err := env.chatHandler.updateReaction(context.Background(), communitySpaceID, messageID, "🎉", func(data *MessageReactionData) error {
+				data.ReactorAIDs = append(data.ReactorAIDs, aid)
+				return nil
+			})
+			if err != nil {
+				errs <- fmt.Errorf("%s: %w", aid, err)
+			}
+		}(fmt.Sprintf("EREACTOR%02d", i))
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("reaction failed: %v", err)
+	}
+
+	obj, err := env.spaceManager.ObjectTreeManager().ReadLatestByID(context.Background(), communitySpaceID, reactionObjectID(messageID, "🎉"))
+	if err != nil {
+		t.Fatalf("reading reaction: %v", err)
+	}
+	var data MessageReactionData
+	json.Unmarshal(obj.Data, &data)
+	for i := 0; i < reactors; i++ {
+		if aid := fmt.Sprintf("EREACTOR%02d", i); !slices.Contains(data.ReactorAIDs, aid) {
+			t.Errorf("reactor %s missing from %v", aid, data.ReactorAIDs)
+		}
+	}
+	if len(data.ReactorAIDs) != reactors {
+		t.Errorf("expected %d reactors, got %d", reactors, len(data.ReactorAIDs))
+	}
+}
+
+func TestAggregateReactions_StableOrdering(t *testing.T) {
+	reactions := []MessageReactionData{
+		{Emoji: "👀", ReactorAIDs: []string{"EA"}, FirstReactedAt: "2026-01-01T10:00:05Z"},
+		{Emoji: "🎉", ReactorAIDs: []string{"EA", "EB"}, FirstReactedAt: "2026-01-01T10:00:09Z"},
+		{Emoji: "👍", ReactorAIDs: []string{"EA"}, FirstReactedAt: "2026-01-01T10:00:01Z"},
+		{Emoji: "🔥", ReactorAIDs: []string{"EB"}, FirstReactedAt: "2026-01-01T10:00:01Z"},
+		{Emoji: "❤️", ReactorAIDs: []string{"EC"}},
+	}
+	// Count first, then earliest reaction, then emoji; untimed reactions last
+	want := []string{"🎉", "👍", "🔥", "👀", "❤️"}
+	emojis := func(aggs []ReactionAggregate) []string {
+		out := make([]string, len(aggs))
+		for i, a := range aggs {
+			out[i] = a.Emoji
+		}
+		return out
+	}
+
+	storeReactions := make([]*anystore.ChatReaction, len(reactions))
+	for i, r := range reactions {
+		storeReactions[i] = &anystore.ChatReaction{Emoji: r.Emoji, ReactorAIDs: r.ReactorAIDs, FirstReactedAt: r.FirstReactedAt}
+	}
+
+	// Simulate map-iteration order by rotating the input on each call
+	for i := range reactions {
+		rotated := append(slices.Clone(reactions[i:]), reactions[:i]...)
+		if got := emojis(aggregateReactions(rotated, "EA")); !slices.Equal(got, want) {
+			t.Errorf("aggregateReactions call %d: got %v, want %v", i, got, want)
+		}
+		rotatedStore := append(slices.Clone(storeReactions[i:]), storeReactions[:i]...)
+		if got := emojis(aggregateStoreReactions(rotatedStore, "EA")); !slices.Equal(got, want) {
+			t.Errorf("aggregateStoreReactions call %d: got %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestChat_ReactionTracksFirstReactedAt(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	channelID := createTestChannel(t, env, "react-first")
+	messageID := sendTestMessage(t, env, channelID, "First")
+	communitySpaceID := env.spaceManager.GetCommunitySpaceID()
+	ctx := context.Background()
+
+	set := func(aids ...string) *MessageReactionData {
+		data, _, err := env.chatHandler.updateReaction(ctx, communitySpaceID, messageID, "👍", func(data *MessageReactionData) error {
+			data.ReactorAIDs = aids
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("updating reaction: %v", err)
+		}
+		return data
+	}
+
+	first := set("EA").FirstReactedAt
+	if first == "" {
+		t.Fatal("expected the first reaction to record FirstReactedAt")
+	}
+	if got := set("EA", "EB").FirstReactedAt; got != first {
+		t.Errorf("expected FirstReactedAt to stay %s as reactors join, got %s", first, got)
+	}
+	if got := set().FirstReactedAt; got != "" {
+		t.Errorf("expected FirstReactedAt to clear with no reactors, got %s", got)
+	}
+}
+
+func TestChat_ReactionStaleVersionConflict(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	channelID := createTestChannel(t, env, "react-conflict")
+	messageID := sendTestMessage(t, env, channelID, "Conflict")
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/chat/messages/"+messageID+"/reactions", bytes.NewBufferString(`{"emoji":"👀"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("add reaction failed: %d %s", w.Code, w.Body.String())
+	}
+
+	// A write based on a version that has since moved on is rejected
+	payload := &anysync.ObjectPayload{
+		ID:   reactionObjectID(messageID, "👀"),
+		Type: "MessageReaction",
+		Data: json.RawMessage(`{"messageId":"` + messageID + `","emoji":"👀","reactorAids":[]}`),
+	}
+	_, err := env.spaceManager.ObjectTreeManager().CompareAndAddObject(context.Background(), env.spaceManager.GetCommunitySpaceID(), payload, 0, nil)
+	if !errors.Is(err, anysync.ErrVersionConflict) {
+		t.Errorf("expected ErrVersionConflict for a stale version, got %v", err)
+	}
+}
+
 // --- SSE Event Tests ---
 
 func TestChat_SSEEvents(t *testing.T) {
 	env := setupChatTestEnv(t)
 	defer env.cleanup()
 
-	// Subscribe to events
-	ch := env.eventBroker.Subscribe()
+	// Subscribe to events as the user (message events go to channel members)
+	ch := env.eventBroker.SubscribeAs(env.userIdentity.GetAID())
 	defer env.eventBroker.Unsubscribe(ch)
 
 	// Create a channel — should broadcast "chat:channel:new"
@@ -1084,3 +1839,240 @@ func TestChat_ReadCursors_MissingFields(t *testing.T) {
 
 // Ensure unused import for crypto doesn't cause build failure.
 var _ = crypto.GenerateRandomEd25519KeyPair
+
+func TestChat_DeletedMessageRedacted(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	channelID := createTestChannel(t, env, "redaction")
+	parentID := sendTestMessage(t, env, channelID, "parent")
+
+	replyBody := fmt.Sprintf(`{"content":"secret reply","replyTo":%q}`, parentID)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/chat/channels/"+channelID+"/messages", bytes.NewBufferString(replyBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("failed to send reply: %d %s", w.Code, w.Body.String())
+	}
+	var sent map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&sent)
+	replyID := sent["messageId"].(string)
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/v1/chat/messages/"+replyID, nil)
+	w = httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("failed to delete reply: %d %s", w.Code, w.Body.String())
+	}
+
+	checkTombstone := func(where string, messages []MessageResponse) {
+		t.Helper()
+		for _, m := range messages {
+			if m.ID != replyID {
+				continue
+			}
+			if m.Content != "" || len(m.Attachments) != 0 {
+				t.Errorf("%s: expected deleted message to be redacted, got content %q", where, m.Content)
+			}
+			if m.DeletedAt == "" || m.SenderAID != env.userIdentity.GetAID() {
+				t.Errorf("%s: expected tombstone metadata, got %+v", where, m)
+			}
+			return
+		}
+		t.Errorf("%s: deleted message %s not returned", where, replyID)
+	}
+
+	// List
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/chat/channels/"+channelID+"/messages", nil)
+	w = httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	var listResp struct {
+		Messages []MessageResponse `json:"messages"`
+	}
+	json.NewDecoder(w.Body).Decode(&listResp)
+	checkTombstone("list", listResp.Messages)
+
+	// Thread
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/chat/messages/"+parentID+"/thread", nil)
+	w = httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	var threadResp struct {
+		Replies []MessageResponse `json:"replies"`
+	}
+	json.NewDecoder(w.Body).Decode(&threadResp)
+	checkTombstone("thread", threadResp.Replies)
+
+	// Get by ID
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/chat/messages/batch", bytes.NewBufferString(fmt.Sprintf(`{"messageIds":[%q]}`, replyID)))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	var batchResp struct {
+		Messages []MessageResponse `json:"messages"`
+	}
+	json.NewDecoder(w.Body).Decode(&batchResp)
+	checkTombstone("batch", batchResp.Messages)
+}
+
+func TestChat_DeletedMessageRedacted_Store(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	store, err := anystore.NewLocalStore(anystore.DefaultConfig(t.TempDir()))
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer store.Close()
+	env.chatHandler.store = store
+
+	ctx := context.Background()
+	if err := store.UpsertChannel(ctx, &anystore.ChatChannel{ID: "channel-1", Name: "general"}); err != nil {
+		t.Fatalf("failed to upsert channel: %v", err)
+	}
+	for _, msg := range []*anystore.ChatMessage{
+		{ID: "msg-parent", ChannelID: "channel-1", SenderAID: "EALICE", Content: "parent", SentAt: "2026-01-01T00:00:00Z", Version: 1},
+		{ID: "msg-deleted", ChannelID: "channel-1", SenderAID: "EALICE", Content: "secret", ReplyTo: "msg-parent",
+			Attachments: json.RawMessage(`[{"fileRef":"file-1","fileName":"secret.png","contentType":"image/png","size":10}]`),
+			SentAt:      "2026-01-01T00:01:00Z", DeletedAt: "2026-01-01T00:02:00Z", Version: 2},
+	} {
+		if err := store.UpsertMessage(ctx, msg); err != nil {
+			t.Fatalf("failed to upsert message: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/chat/messages/msg-parent/thread", nil)
+	w := httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	var threadResp struct {
+		Replies []MessageResponse `json:"replies"`
+	}
+	json.NewDecoder(w.Body).Decode(&threadResp)
+	if len(threadResp.Replies) != 1 {
+		t.Fatalf("expected 1 reply, got %d", len(threadResp.Replies))
+	}
+	reply := threadResp.Replies[0]
+	if reply.Content != "" || len(reply.Attachments) != 0 || reply.DeletedAt == "" || reply.SenderAID != "EALICE" {
+		t.Errorf("expected redacted tombstone, got %+v", reply)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/chat/messages/batch", bytes.NewBufferString(`{"messageIds":["msg-deleted"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	var batchResp struct {
+		Messages []MessageResponse `json:"messages"`
+	}
+	json.NewDecoder(w.Body).Decode(&batchResp)
+	if len(batchResp.Messages) != 1 || batchResp.Messages[0].Content != "" || len(batchResp.Messages[0].Attachments) != 0 {
+		t.Errorf("expected redacted tombstone from batch, got %+v", batchResp.Messages)
+	}
+}
+
+func TestChat_NilStoreEndToEnd(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+	if env.chatHandler.store != nil {
+		t.Fatal("expected the test handler to run without a local store")
+	}
+
+	do := func(method, path, body string, wantCode int) map[string]interface{} {
+		t.Helper()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		env.mux.ServeHTTP(w, req)
+		if w.Code != wantCode {
+			t.Fatalf("%s %s: expected %d, got %d: %s", method, path, wantCode, w.Code, w.Body.String())
+		}
+		var resp map[string]interface{}
+		json.NewDecoder(w.Body).Decode(&resp)
+		return resp
+	}
+
+	channelID := createTestChannel(t, env, "no-index")
+	if resp := do(http.MethodGet, "/api/v1/chat/channels", "", http.StatusOK); resp["count"] != float64(1) {
+		t.Errorf("expected 1 channel, got %v", resp)
+	}
+	if resp := do(http.MethodGet, "/api/v1/chat/channels/"+channelID, "", http.StatusOK); resp["name"] != "no-index" {
+		t.Errorf("expected the channel back, got %v", resp)
+	}
+
+	messageID := sendTestMessage(t, env, channelID, "Without an index")
+	do(http.MethodPost, "/api/v1/chat/channels/"+channelID+"/messages", `{"content":"A reply","replyTo":"`+messageID+`"}`, http.StatusCreated)
+	do(http.MethodPost, "/api/v1/chat/messages/"+messageID+"/reactions", `{"emoji":"👍"}`, http.StatusOK)
+
+	resp := do(http.MethodGet, "/api/v1/chat/channels/"+channelID+"/messages", "", http.StatusOK)
+	messages, _ := resp["messages"].([]interface{})
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %v", resp)
+	}
+	if resp := do(http.MethodGet, "/api/v1/chat/messages/"+messageID+"/thread", "", http.StatusOK); resp["count"] != float64(1) {
+		t.Errorf("expected 1 reply in the thread, got %v", resp)
+	}
+
+	batch := do(http.MethodPost, "/api/v1/chat/messages/batch", `{"messageIds":["`+messageID+`"]}`, http.StatusOK)
+	found, _ := batch["messages"].([]interface{})
+	if len(found) != 1 {
+		t.Fatalf("expected the message from the batch endpoint, got %v", batch)
+	}
+	reactions, _ := found[0].(map[string]interface{})["reactions"].([]interface{})
+	if len(reactions) != 1 {
+		t.Errorf("expected the reaction from the tree, got %v", found[0])
+	}
+
+	// Unreachable tree nodes without the index are reported as degraded;
+	// other failures keep their own status
+	unreachable := fmt.Errorf("reading tree: %w", context.DeadlineExceeded)
+	w := httptest.NewRecorder()
+	env.chatHandler.writeTreeError(w, "failed to read messages", unreachable)
+	var degraded map[string]string
+	json.NewDecoder(w.Body).Decode(&degraded)
+	if w.Code != http.StatusServiceUnavailable || degraded["status"] != HealthStatusDegraded || degraded["error"] != "failed to read messages: "+unreachable.Error() {
+		t.Errorf("unexpected degraded response %d %v", w.Code, degraded)
+	}
+	for _, tc := range []struct {
+		err  error
+		want int
+	}{
+		{errors.New("invalid change payload"), http.StatusInternalServerError},
+		{fmt.Errorf("writing msg: %w", anysync.ErrReadOnlySpace), http.StatusForbidden},
+		{fmt.Errorf("%w: msg is at version 3", anysync.ErrVersionConflict), http.StatusConflict},
+	} {
+		w := httptest.NewRecorder()
+		env.chatHandler.writeTreeError(w, "failed to send message", tc.err)
+		if w.Code != tc.want {
+			t.Errorf("%v: expected %d, got %d", tc.err, tc.want, w.Code)
+		}
+	}
+}
+
+func TestChat_TypeRegistryRejectsUnknownTypes(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+	registry := types.NewRegistry()
+	registry.Bootstrap()
+	env.spaceManager.ObjectTreeManager().SetTypeRegistry(registry, false)
+
+	// Channels, memberships and messages are all known types
+	channelID := createTestChannel(t, env, "typed")
+	sendTestMessage(t, env, channelID, "registered")
+
+	ctx := context.Background()
+	spaceID := env.spaceManager.GetCommunitySpaceID()
+	client := env.spaceManager.GetClient()
+	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), spaceID, client.GetSigningKey())
+	if err != nil {
+		t.Fatalf("loading keys: %v", err)
+	}
+	typo := &anysync.ObjectPayload{ID: "ChatMesage-typo", Type: "ChatMesage", Data: json.RawMessage(`{"channelId":"` + channelID + `"}`), Version: 1}
+	if _, err := env.spaceManager.ObjectTreeManager().AddObject(ctx, spaceID, typo, keys.SigningKey); !errors.Is(err, types.ErrUnknownType) {
+		t.Fatalf("expected ErrUnknownType, got %v", err)
+	}
+	if _, err := env.spaceManager.ObjectTreeManager().CompareAndAddObject(ctx, spaceID, typo, 0, keys.SigningKey); !errors.Is(err, types.ErrUnknownType) {
+		t.Fatalf("CompareAndAddObject: expected ErrUnknownType, got %v", err)
+	}
+	if _, err := env.spaceManager.ObjectTreeManager().ReadLatestByID(ctx, spaceID, typo.ID); err == nil {
+		t.Error("the rejected object must not be written")
+	}
+}
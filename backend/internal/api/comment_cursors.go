@@ -56,7 +56,7 @@ func (h *CommentCursorsHandler) route(w http.ResponseWriter, r *http.Request) {
 
 func (h *CommentCursorsHandler) handleGet(w http.ResponseWriter, r *http.Request) {
 	privateSpaceID := h.userIdentity.GetPrivateSpaceID()
-	userAID := h.userIdentity.GetAID()
+	userAID := callerAID(r.Context(), h.userIdentity)
 	if privateSpaceID == "" || userAID == "" {
 		writeJSON(w, http.StatusOK, map[string]interface{}{"cursors": map[string]int{}})
 		return
@@ -105,7 +105,7 @@ func (h *CommentCursorsHandler) handlePut(w http.ResponseWriter, r *http.Request
 	}
 
 	privateSpaceID := h.userIdentity.GetPrivateSpaceID()
-	userAID := h.userIdentity.GetAID()
+	userAID := callerAID(r.Context(), h.userIdentity)
 	if privateSpaceID == "" || userAID == "" {
 		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
 			"error": "user identity not configured",
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Default size limits for user-written text, in UTF-8 bytes. Every write is
+// replicated to each peer's object tree, so oversized text slows sync for the
+// whole community.
+const (
+	DefaultMaxMessageBytes    = 16 << 10 // chat message content
+	DefaultMaxNoticeBodyBytes = 64 << 10 // notice body
+	DefaultMaxCommentBytes    = 2000     // notice comment text
+)
+
+// checkContentSize writes a 413 and returns false if text is longer than
+// limit bytes; a limit of zero or less uses defaultLimit. len counts UTF-8
+// bytes, which is what lands in the tree.
+func checkContentSize(w http.ResponseWriter, field, text string, limit, defaultLimit int) bool {
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	if len(text) <= limit {
+		return true
+	}
+	writeJSON(w, http.StatusRequestEntityTooLarge, map[string]string{
+		"error": fmt.Sprintf("%s must be %d bytes or less, got %d", field, limit, len(text)),
+	})
+	return false
+}
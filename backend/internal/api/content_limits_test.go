@@ -0,0 +1,96 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Both strings are five runes; with a limit of 8 only the first fits, since
+// "é" is two bytes in UTF-8.
+const (
+	atLimitText   = "éééab" // 8 bytes
+	overLimitText = "ééééa" // 9 bytes
+)
+
+func TestContentLimits_ChatMessage(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+	env.chatHandler.SetMaxMessageBytes(8)
+
+	channelID := createTestChannel(t, env, "limits")
+	send := func(content string) int {
+		body, _ := json.Marshal(SendMessageRequest{Content: content})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/chat/channels/"+channelID+"/messages", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		env.mux.ServeHTTP(w, req)
+		return w.Code
+	}
+	if code := send(atLimitText); code != http.StatusCreated {
+		t.Errorf("send at limit: expected 201, got %d", code)
+	}
+	if code := send(overLimitText); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("send over limit: expected 413, got %d", code)
+	}
+
+	messageID := sendTestMessage(t, env, channelID, "short")
+	edit := func(content string) int {
+		body, _ := json.Marshal(EditMessageRequest{Content: content})
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/chat/messages/"+messageID, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		env.mux.ServeHTTP(w, req)
+		return w.Code
+	}
+	if code := edit(overLimitText); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("edit over limit: expected 413, got %d", code)
+	}
+	if code := edit(atLimitText); code != http.StatusOK {
+		t.Errorf("edit at limit: expected 200, got %d", code)
+	}
+}
+
+func TestContentLimits_NoticeBody(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+	env.handler.SetContentLimits(8, 0)
+
+	notice := func(body string) map[string]interface{} {
+		return map[string]interface{}{"type": "announcement", "title": "Limits", "summary": "Body limits", "body": body}
+	}
+	noticeID := createTestNotice(t, env, notice(atLimitText))
+	if w := env.do(t, http.MethodPost, "/api/v1/notices", notice(overLimitText)); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("create over limit: expected 413, got %d: %s", w.Code, w.Body.String())
+	}
+
+	path := "/api/v1/notices/" + noticeID
+	if w := env.do(t, http.MethodPut, path, map[string]string{"body": overLimitText}); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("update over limit: expected 413, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := env.do(t, http.MethodPut, path, map[string]string{"body": atLimitText}); w.Code != http.StatusOK {
+		t.Errorf("update at limit: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestContentLimits_Comment(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+	noticeID := createTestNotice(t, env, map[string]interface{}{"type": "announcement", "title": "Limits", "summary": "Comment limits"})
+	path := "/api/v1/notices/" + noticeID + "/comments"
+
+	// The default limit applies until one is configured
+	if w := env.do(t, http.MethodPost, path, map[string]string{"text": string(bytes.Repeat([]byte("a"), DefaultMaxCommentBytes+1))}); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("default limit: expected 413, got %d: %s", w.Code, w.Body.String())
+	}
+
+	env.handler.SetContentLimits(0, 8)
+	if w := env.do(t, http.MethodPost, path, map[string]string{"text": atLimitText}); w.Code != http.StatusOK {
+		t.Errorf("comment at limit: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := env.do(t, http.MethodPost, path, map[string]string{"text": overLimitText}); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("comment over limit: expected 413, got %d: %s", w.Code, w.Body.String())
+	}
+}
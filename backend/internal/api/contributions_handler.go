@@ -448,9 +448,12 @@ func (h *ContributionsHandler) HandleRegister(w http.ResponseWriter, r *http.Req
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
 		return
 	}
-	// Resolve user ID: body field → X-User-AID header
+	// Resolve user ID: session token → body field → X-User-AID header
+	if aid, ok := authenticatedAID(r); ok {
+		req.UserID = aid
+	}
 	if req.UserID == "" {
-		req.UserID = r.Header.Get("X-User-AID")
+		req.UserID = requestUserAID(r)
 	}
 	if req.UserID == "" {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "user_id is required"})
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Node modes reported in /health. A node is read-only while the coordinator
+// is unreachable: reads and local writes (messages, notices, profiles) are
+// served from the local store and trees and sync once it reconnects, while
+// operations that need the coordinator are rejected (see coordinatorRoutes).
+const (
+	ModeReadWrite = "read-write"
+	ModeReadOnly  = "read-only"
+)
+
+// coordinatorRoutes are the write endpoints that can't complete without the
+// coordinator: space creation, ACL changes (invites and joins) and file
+// uploads.
+var coordinatorRoutes = []string{
+	"/api/v1/spaces/community",
+	"/api/v1/spaces/community/invite",
+	"/api/v1/spaces/community/join",
+	"/api/v1/spaces/community-readonly/invite",
+	"/api/v1/spaces/private",
+	"/api/v1/files",
+	"/api/v1/files/upload",
+	"/api/v1/files/avatar",
+}
+
+// DefaultCoordinatorProbeInterval is how often the watchdog pings the
+// coordinator unless configured otherwise.
+const DefaultCoordinatorProbeInterval = 15 * time.Second
+
+// coordinatorFailureThreshold is how many consecutive failed pings it takes
+// to switch to read-only, so a single dropped ping doesn't flap the mode.
+const coordinatorFailureThreshold = 2
+
+// CoordinatorStatus is the watchdog's view of the coordinator, as reported
+// in /health.
+type CoordinatorStatus struct {
+	Mode      string     `json:"mode"`
+	Reachable bool       `json:"reachable"`
+	Since     *time.Time `json:"since,omitempty"`     // when the current mode started
+	LastCheck *time.Time `json:"lastCheck,omitempty"` // when the coordinator was last pinged
+	Error     string     `json:"error,omitempty"`     // last ping error while offline
+}
+
+// CoordinatorWatchdog pings the any-sync coordinator in the background and
+// tracks whether the node should run read-only (see ReadOnlyGuard).
+type CoordinatorWatchdog struct {
+	ping func() error
+
+	mu        sync.RWMutex
+	offline   bool
+	failures  int
+	since     time.Time
+	lastCheck time.Time
+	lastErr   string
+
+	loop backgroundLoop // ping loop (see Start)
+}
+
+// NewCoordinatorWatchdog creates a watchdog that checks reachability with
+// ping, e.g. AnySyncClient.Ping. The coordinator is assumed reachable until a
+// check says otherwise.
+func NewCoordinatorWatchdog(ping func() error) *CoordinatorWatchdog {
+	return &CoordinatorWatchdog{ping: ping, since: time.Now()}
+}
+
+// Start begins a background loop that checks the coordinator every interval.
+func (wd *CoordinatorWatchdog) Start(interval time.Duration) {
+	wd.loop.start(interval, func(_ context.Context, now time.Time) {
+		wd.Check(now)
+	})
+	log.Printf("[Coordinator] Started watchdog (interval=%s)", interval)
+}
+
+// Stop stops the loop started by Start.
+func (wd *CoordinatorWatchdog) Stop() {
+	wd.loop.stop()
+}
+
+// Check pings the coordinator once and updates the mode, logging when the
+// node goes read-only or back to read-write. Returns whether it is offline.
+func (wd *CoordinatorWatchdog) Check(now time.Time) bool {
+	err := wd.ping()
+
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.lastCheck = now
+	if err == nil {
+		if wd.offline {
+			log.Printf("[Coordinator] Reachable again after %s; leaving read-only mode", now.Sub(wd.since).Round(time.Second))
+			wd.offline = false
+			wd.since = now
+		}
+		wd.failures = 0
+		wd.lastErr = ""
+		return false
+	}
+
+	wd.failures++
+	wd.lastErr = err.Error()
+	if !wd.offline && wd.failures >= coordinatorFailureThreshold {
+		log.Printf("[Coordinator] Unreachable (%v); rejecting space, invite and upload writes until it reconnects", err)
+		wd.offline = true
+		wd.since = now
+	}
+	return wd.offline
+}
+
+// Offline reports whether the node is in read-only mode.
+func (wd *CoordinatorWatchdog) Offline() bool {
+	wd.mu.RLock()
+	defer wd.mu.RUnlock()
+	return wd.offline
+}
+
+// Status returns the current mode and the result of the last check.
+func (wd *CoordinatorWatchdog) Status() CoordinatorStatus {
+	wd.mu.RLock()
+	defer wd.mu.RUnlock()
+	status := CoordinatorStatus{Mode: ModeReadWrite, Reachable: !wd.offline}
+	if wd.offline {
+		status.Mode = ModeReadOnly
+		status.Error = wd.lastErr
+	}
+	if !wd.since.IsZero() {
+		since := wd.since
+		status.Since = &since
+	}
+	if !wd.lastCheck.IsZero() {
+		lastCheck := wd.lastCheck
+		status.LastCheck = &lastCheck
+	}
+	return status
+}
+
+// ReadOnlyGuard rejects writes to coordinatorRoutes with 503 while the
+// watchdog reports the coordinator unreachable. Other requests pass through
+// and are served from local state.
+func ReadOnlyGuard(wd *CoordinatorWatchdog, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if wd != nil && wd.Offline() && isWriteMethod(r.Method) && slices.Contains(coordinatorRoutes, r.URL.Path) {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+				"error":  "coordinator unreachable: this operation is unavailable until it reconnects",
+				"reason": "offline",
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCoordinatorWatchdog_ReadOnlyMode(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+	channelID := createTestChannel(t, env, "offline-reads")
+	sendTestMessage(t, env, channelID, "Cached before the outage")
+
+	var down atomic.Bool
+	wd := NewCoordinatorWatchdog(func() error {
+		if down.Load() {
+			return errors.New("coordinator unreachable: connection refused")
+		}
+		return nil
+	})
+	guarded := ReadOnlyGuard(wd, env.mux)
+
+	healthHandler, _, _, cleanup := setupHealthTestHandler(t)
+	defer cleanup()
+	healthHandler.SetCoordinatorWatchdog(wd)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		guarded.ServeHTTP(w, req)
+		return w
+	}
+	health := func() (int, HealthResponse) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		healthHandler.HandleHealth(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+		var resp HealthResponse
+		json.NewDecoder(w.Body).Decode(&resp)
+		return w.Code, resp
+	}
+
+	if _, resp := health(); resp.Mode != ModeReadWrite || resp.Coordinator == nil || !resp.Coordinator.Reachable {
+		t.Errorf("expected read-write mode while reachable, got %+v", resp)
+	}
+
+	// One failed ping isn't enough to switch modes
+	down.Store(true)
+	now := time.Now()
+	if wd.Check(now) {
+		t.Fatal("expected a single failed ping to leave the node read-write")
+	}
+	if !wd.Check(now.Add(time.Second)) {
+		t.Fatal("expected repeated failed pings to switch to read-only")
+	}
+
+	t.Run("reads are served locally", func(t *testing.T) {
+		if w := do(http.MethodGet, "/api/v1/chat/channels", ""); w.Code != http.StatusOK {
+			t.Errorf("listing channels: expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		w := do(http.MethodGet, "/api/v1/chat/channels/"+channelID+"/messages", "")
+		var resp struct {
+			Messages []MessageResponse `json:"messages"`
+		}
+		json.NewDecoder(w.Body).Decode(&resp)
+		if w.Code != http.StatusOK || len(resp.Messages) != 1 {
+			t.Errorf("listing messages: expected the cached message, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("local writes are accepted", func(t *testing.T) {
+		if w := do(http.MethodPost, "/api/v1/chat/channels/"+channelID+"/messages", `{"content":"hi"}`); w.Code != http.StatusCreated {
+			t.Errorf("sending a message: expected 201, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("coordinator operations are rejected as offline", func(t *testing.T) {
+		for _, req := range []struct{ method, path, body string }{
+			{http.MethodPost, "/api/v1/spaces/community", `{}`},
+			{http.MethodPost, "/api/v1/spaces/community/invite", `{}`},
+			{http.MethodPost, "/api/v1/files", ``},
+		} {
+			w := do(req.method, req.path, req.body)
+			var resp map[string]string
+			json.NewDecoder(w.Body).Decode(&resp)
+			if w.Code != http.StatusServiceUnavailable || resp["reason"] != "offline" {
+				t.Errorf("%s %s: expected 503 offline, got %d %v", req.method, req.path, w.Code, resp)
+			}
+		}
+	})
+
+	t.Run("health reports read-only", func(t *testing.T) {
+		code, resp := health()
+		if code != http.StatusServiceUnavailable || resp.Status != HealthStatusDegraded || resp.Mode != ModeReadOnly {
+			t.Errorf("expected a degraded read-only health response, got %d %+v", code, resp)
+		}
+		if resp.Coordinator == nil || resp.Coordinator.Reachable || resp.Coordinator.Error == "" {
+			t.Errorf("expected the coordinator error in the health response, got %+v", resp.Coordinator)
+		}
+	})
+
+	// Reconnecting restores writes
+	down.Store(false)
+	if wd.Check(now.Add(2 * time.Second)) {
+		t.Fatal("expected a successful ping to leave read-only mode")
+	}
+	if w := do(http.MethodPost, "/api/v1/chat/channels", `{"name":"after-outage"}`); w.Code != http.StatusCreated {
+		t.Errorf("expected writes to work again, got %d: %s", w.Code, w.Body.String())
+	}
+}
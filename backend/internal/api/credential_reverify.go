@@ -0,0 +1,171 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/matou-dao/backend/internal/anystore"
+	"github.com/matou-dao/backend/internal/keri"
+)
+
+// EventCredentialVerified is broadcast when re-verification promotes a cached
+// credential to verified.
+const EventCredentialVerified = "credential:verified"
+
+// ReverifyRequest is the optional body of POST /api/v1/credentials/reverify.
+type ReverifyRequest struct {
+	// IssuerAID limits the pass to one issuer's credentials
+	IssuerAID string `json:"issuerAid,omitempty"`
+}
+
+// ReverifyResponse reports the outcome of a re-verification pass.
+type ReverifyResponse struct {
+	Checked  int      `json:"checked"`
+	Promoted []string `json:"promoted"`
+}
+
+// SetEventBroker enables "credential:verified" SSE events.
+func (h *SyncHandler) SetEventBroker(b *EventBroker) {
+	h.eventBroker = b
+}
+
+// HandleReverify handles POST /api/v1/credentials/reverify. It re-checks
+// cached credentials that aren't verified yet and promotes those that now
+// verify, e.g. once their issuer's KEL has been resolved. Community admins
+// only; issuers are checked against the KEL cache, never resolved from
+// caller-supplied OOBIs.
+func (h *SyncHandler) HandleReverify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		return
+	}
+
+	var req ReverifyRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid request: %v", err)})
+			return
+		}
+	}
+
+	checked, promoted, err := h.Reverify(r.Context(), req.IssuerAID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	resp := ReverifyResponse{Checked: checked, Promoted: []string{}}
+	for _, c := range promoted {
+		resp.Promoted = append(resp.Promoted, c.ID)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// Reverify re-checks the cached credentials that aren't verified, limited to
+// issuerAID's when it is set, the same way a sync checks them. Those that now
+// verify are stored as verified, applied to the trust graph and announced
+// with a "credential:verified" event. Unsigned credentials are never
+// promoted, and a verified credential is never demoted here. Returns how many credentials were checked and those promoted.
+func (h *SyncHandler) Reverify(ctx context.Context, issuerAID string) (int, []*anystore.CachedCredential, error) {
+	unverified, err := h.unverifiedCredentials(ctx, issuerAID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	issuers := make(map[string]bool)
+	var promoted []*anystore.CachedCredential
+	for _, cached := range unverified {
+		if cached.Signature == "" {
+			continue
+		}
+		cred := &keri.Credential{
+			SAID:      cached.ID,
+			Issuer:    cached.IssuerAID,
+			Recipient: cached.SubjectAID,
+			Schema:    cached.SchemaID,
+			Signature: cached.Signature,
+		}
+		if !h.issuerVerifiable(ctx, cred, nil, issuers) {
+			continue
+		}
+
+		cached.Verified = true
+		if err := h.store.StoreCredential(ctx, cached); err != nil {
+			log.Printf("[Sync] Failed to store re-verified credential %s: %v", cached.ID, err)
+			continue
+		}
+		promoted = append(promoted, cached)
+	}
+
+	if len(promoted) > 0 {
+		log.Printf("[Sync] Re-verification promoted %d of %d credentials", len(promoted), len(unverified))
+	}
+	h.applyStoredCredentials(ctx, promoted)
+	if h.eventBroker != nil {
+		for _, c := range promoted {
+			h.eventBroker.Broadcast(SSEEvent{
+				Type: EventCredentialVerified,
+				Data: map[string]string{
+					"said":      c.ID,
+					"issuer":    c.IssuerAID,
+					"recipient": c.SubjectAID,
+					"schema":    c.SchemaID,
+				},
+			})
+		}
+	}
+	return len(unverified), promoted, nil
+}
+
+// reverifyIssuer runs a re-verification pass for one issuer's credentials
+// after new KEL data for it arrived. Failures are logged.
+func (h *SyncHandler) reverifyIssuer(ctx context.Context, issuerAID string) {
+	if _, _, err := h.Reverify(ctx, issuerAID); err != nil {
+		log.Printf("[Sync] Re-verifying credentials of %s: %v", issuerAID, err)
+	}
+}
+
+// reverifyResolvedIssuers re-verifies credentials cached earlier from the
+// issuers whose KEL a sync found or resolved, since one may have just been
+// resolved from its OOBI.
+func (h *SyncHandler) reverifyResolvedIssuers(ctx context.Context, issuers map[string]bool) {
+	for aid, available := range issuers {
+		if available {
+			h.reverifyIssuer(ctx, aid)
+		}
+	}
+}
+
+// unverifiedCredentials returns the cached credentials not yet verified,
+// limited to issuerAID's when it is set.
+func (h *SyncHandler) unverifiedCredentials(ctx context.Context, issuerAID string) ([]*anystore.CachedCredential, error) {
+	coll, err := h.store.CredentialsCache(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials collection: %w", err)
+	}
+	query := `{"verified": false}`
+	if issuerAID != "" {
+		query = fmt.Sprintf(`{"verified": false, "issuerAID": %q}`, issuerAID)
+	}
+	iter, err := coll.Find(anystore.MustParseJSON(query)).Iter(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query credentials: %w", err)
+	}
+	defer iter.Close()
+
+	var creds []*anystore.CachedCredential
+	for iter.Next() {
+		doc, err := iter.Doc()
+		if err != nil {
+			continue
+		}
+		var cred anystore.CachedCredential
+		if err := json.Unmarshal([]byte(doc.Value().String()), &cred); err != nil {
+			continue
+		}
+		creds = append(creds, &cred)
+	}
+	return creds, nil
+}
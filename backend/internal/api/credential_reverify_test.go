@@ -0,0 +1,151 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matou-dao/backend/internal/anystore"
+	"github.com/matou-dao/backend/internal/contributions"
+)
+
+// recordingTrustUpdater records the credentials applied to the trust graph.
+type recordingTrustUpdater struct {
+	applied []*anystore.CachedCredential
+}
+
+func (r *recordingTrustUpdater) ApplyCredentials(ctx context.Context, creds []*anystore.CachedCredential) {
+	r.applied = append(r.applied, creds...)
+}
+
+func TestHandleSyncKEL_ReverifiesIssuerCredentials(t *testing.T) {
+	handler, store, cleanup := setupSyncTestHandler(t)
+	defer cleanup()
+	ctx := context.Background()
+	broker := NewEventBroker()
+	handler.SetEventBroker(broker)
+	events := broker.Subscribe()
+
+	issuer, icp := newTestKEL(5)
+	postExternalCredential(t, handler, "ESAID301", issuer.aid, nil)
+	cred, err := store.GetCredential(ctx, "ESAID301")
+	if err != nil {
+		t.Fatalf("failed to get credential: %v", err)
+	}
+	if cred.Verified {
+		t.Fatal("expected credential from unknown issuer not to be verified")
+	}
+
+	// The issuer's KEL arrives later
+	if w, _ := postKELFor(t, handler, issuer.aid, icp); w.Code != http.StatusOK {
+		t.Fatalf("KEL sync failed: %s", w.Body.String())
+	}
+
+	cred, err = store.GetCredential(ctx, "ESAID301")
+	if err != nil {
+		t.Fatalf("failed to get credential: %v", err)
+	}
+	if !cred.Verified {
+		t.Error("expected credential to be verified once its issuer's KEL is cached")
+	}
+	select {
+	case ev := <-events:
+		data := ev.Data.(map[string]string)
+		if ev.Type != EventCredentialVerified || data["said"] != "ESAID301" {
+			t.Errorf("unexpected event %s %v", ev.Type, ev.Data)
+		}
+	default:
+		t.Error("expected a credential:verified event")
+	}
+}
+
+func TestHandleReverify_PromotesOnceKELCached(t *testing.T) {
+	handler, store, cleanup := setupSyncTestHandler(t)
+	defer cleanup()
+	ctx := context.Background()
+	trust := &recordingTrustUpdater{}
+	handler.SetTrustGraphUpdater(trust)
+
+	issuer, icp := newTestKEL(5)
+	postExternalCredential(t, handler, "ESAID301", issuer.aid, nil)
+	postExternalCredential(t, handler, "ESAID302", "EEXTERNAL002", nil)
+	// An unsigned credential from the same issuer is never promoted
+	if err := store.StoreCredential(ctx, &anystore.CachedCredential{
+		ID: "ESAID303", IssuerAID: issuer.aid, SubjectAID: "EUSER123", SchemaID: "EMatouMembershipSchemaV1",
+	}); err != nil {
+		t.Fatalf("failed to store credential: %v", err)
+	}
+	trust.applied = nil
+
+	if err := handler.CacheKEL(ctx, issuer.aid, keyEvents(icp)); err != nil {
+		t.Fatalf("failed to cache KEL: %v", err)
+	}
+
+	reverify := func(req ReverifyRequest) ReverifyResponse {
+		t.Helper()
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		handler.HandleReverify(w, httptest.NewRequest(http.MethodPost, "/api/v1/credentials/reverify", bytes.NewReader(body)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("reverify failed: %d %s", w.Code, w.Body.String())
+		}
+		var resp ReverifyResponse
+		json.NewDecoder(w.Body).Decode(&resp)
+		return resp
+	}
+
+	resp := reverify(ReverifyRequest{})
+	if resp.Checked != 3 || len(resp.Promoted) != 1 || resp.Promoted[0] != "ESAID301" {
+		t.Fatalf("expected ESAID301 promoted of 3 checked, got %+v", resp)
+	}
+	if cred, _ := store.GetCredential(ctx, "ESAID301"); cred == nil || !cred.Verified {
+		t.Error("expected ESAID301 to be stored as verified")
+	}
+	for _, said := range []string{"ESAID302", "ESAID303"} {
+		if cred, _ := store.GetCredential(ctx, said); cred == nil || cred.Verified {
+			t.Errorf("expected %s to stay unverified", said)
+		}
+	}
+	if len(trust.applied) != 1 || trust.applied[0].ID != "ESAID301" {
+		t.Errorf("expected the promoted credential applied to the trust graph, got %v", trust.applied)
+	}
+
+	// Verified credentials aren't checked again
+	if resp := reverify(ReverifyRequest{IssuerAID: "EEXTERNAL002"}); resp.Checked != 1 || len(resp.Promoted) != 0 {
+		t.Errorf("expected only ESAID302 rechecked, got %+v", resp)
+	}
+}
+
+func TestHandleReverify_RequiresCommunityAdmin(t *testing.T) {
+	handler, _, cleanup := setupSyncTestHandler(t)
+	defer cleanup()
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux, &mockRoleLookup{roles: map[string][]contributions.Role{
+		"EADMIN": {contributions.RoleOperationsSteward},
+		"EUSER":  {contributions.RoleMember},
+	}})
+
+	for aid, want := range map[string]int{"EADMIN": http.StatusOK, "EUSER": http.StatusForbidden} {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/credentials/reverify", nil)
+		req.Header.Set("X-User-AID", aid)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Errorf("%s: expected %d, got %d: %s", aid, want, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestHandleReverify_MethodNotAllowed(t *testing.T) {
+	handler, _, cleanup := setupSyncTestHandler(t)
+	defer cleanup()
+
+	w := httptest.NewRecorder()
+	handler.HandleReverify(w, httptest.NewRequest(http.MethodGet, "/api/v1/credentials/reverify", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", w.Code)
+	}
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -62,9 +63,16 @@ type CredentialResponse struct {
 // ListResponse represents a list of credentials response
 type ListResponse struct {
 	Credentials []keri.Credential `json:"credentials"`
-	Total       int               `json:"total"`
+	Count       int               `json:"count"` // Credentials in this page
+	ListPage                      // Total counts all cached credentials, ignoring filters
 }
 
+// Page sizes for GET /api/v1/credentials
+const (
+	defaultCredentialsPageSize = 50
+	maxCredentialsPageSize     = 200
+)
+
 // RolesResponse lists available roles
 type RolesResponse struct {
 	Roles []RoleInfo `json:"roles"`
@@ -114,6 +122,7 @@ func (h *CredentialsHandler) HandleStore(w http.ResponseWriter, r *http.Request)
 		Data:       req.Credential.Data,
 		CachedAt:   time.Now().UTC(),
 		Verified:   h.keriClient.IsOrgIssued(&req.Credential),
+		Signature:  req.Credential.Signature,
 	}
 
 	if err := h.store.StoreCredential(ctx, cachedCred); err != nil {
@@ -224,7 +233,8 @@ func (h *CredentialsHandler) HandleValidate(w http.ResponseWriter, r *http.Reque
 	})
 }
 
-// HandleRoles handles GET /api/v1/credentials/roles - List available roles
+// HandleRoles handles GET /api/v1/credentials/roles and GET /api/v1/roles -
+// List available roles
 func (h *CredentialsHandler) HandleRoles(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{
@@ -266,6 +276,9 @@ func (h *CredentialsHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/api/v1/credentials/", h.handleCredentialByID)
 	mux.HandleFunc("/api/v1/credentials/validate", h.HandleValidate)
 	mux.HandleFunc("/api/v1/credentials/roles", h.HandleRoles)
+
+	// Canonical role list, e.g. for channel AllowedRoles pickers
+	mux.HandleFunc("/api/v1/roles", h.HandleRoles)
 }
 
 // handleCredentials routes to Store (POST) or List (GET)
@@ -292,18 +305,46 @@ func (h *CredentialsHandler) handleCredentialByID(w http.ResponseWriter, r *http
 	h.HandleGet(w, r)
 }
 
-// handleList handles GET /api/v1/credentials - List all credentials
+// handleList handles GET /api/v1/credentials - List cached credentials.
+// Supports ?issuer=, ?subject= and ?schema= filters and cursor pagination via
+// ?limit=N&cursor=<nextCursor>; credentials are returned in SAID order. The
+// total number of cached credentials is also sent as X-Total-Count.
 func (h *CredentialsHandler) handleList(w http.ResponseWriter, r *http.Request) {
 	ctx := context.Background()
 
-	// Query all credentials from anystore cache
-	cachedCreds, err := h.store.GetAllCredentials(ctx)
+	query := r.URL.Query()
+	q := anystore.CredentialQuery{
+		IssuerAID:  query.Get("issuer"),
+		SubjectAID: query.Get("subject"),
+		SchemaID:   query.Get("schema"),
+	}
+	var ok bool
+	if q.Limit, ok = parseListLimit(w, r, defaultCredentialsPageSize, maxCredentialsPageSize); !ok {
+		return
+	}
+	if c := query.Get("cursor"); c != "" {
+		after, err := base64.RawURLEncoding.DecodeString(c)
+		if err != nil || len(after) == 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid cursor"})
+			return
+		}
+		q.After = string(after)
+	}
+
+	cachedCreds, more, err := h.store.ListCredentials(ctx, q)
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{
 			"error": fmt.Sprintf("failed to query credentials: %v", err),
 		})
 		return
 	}
+	total, err := h.store.CountCredentials(ctx, q)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("failed to count credentials: %v", err),
+		})
+		return
+	}
 
 	// Convert cached credentials to keri.Credential format
 	credentials := make([]keri.Credential, 0, len(cachedCreds))
@@ -323,10 +364,15 @@ func (h *CredentialsHandler) handleList(w http.ResponseWriter, r *http.Request)
 		credentials = append(credentials, cred)
 	}
 
-	writeJSON(w, http.StatusOK, ListResponse{
+	resp := ListResponse{
 		Credentials: credentials,
-		Total:       len(credentials),
-	})
+		Count:       len(credentials),
+		ListPage:    ListPage{Total: total, Limit: q.Limit, HasMore: more},
+	}
+	if more {
+		resp.NextCursor = base64.RawURLEncoding.EncodeToString([]byte(cachedCreds[len(cachedCreds)-1].ID))
+	}
+	writeListJSON(w, resp.ListPage, resp)
 }
 
 // writeJSON writes a JSON response
@@ -335,3 +381,12 @@ func writeJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(data)
 }
+
+// writeVersionConflict writes a 409 for an anysync.ErrVersionConflict, along
+// with the object's current state so the client can merge its change and retry.
+func writeVersionConflict(w http.ResponseWriter, err error, current interface{}) {
+	writeJSON(w, http.StatusConflict, map[string]interface{}{
+		"error":   err.Error(),
+		"current": current,
+	})
+}
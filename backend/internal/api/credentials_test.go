@@ -2,10 +2,15 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"slices"
+	"sort"
+	"strconv"
 	"testing"
 
 	"github.com/matou-dao/backend/internal/anystore"
@@ -328,6 +333,125 @@ func TestHandleList(t *testing.T) {
 	}
 }
 
+func TestHandleList_PaginationAndFilters(t *testing.T) {
+	handler, cleanup := setupTestHandler(t)
+	defer cleanup()
+	ctx := context.Background()
+	if err := handler.store.EnsureCredentialIndexes(ctx); err != nil {
+		t.Fatalf("creating credential indexes: %v", err)
+	}
+
+	// 7 membership credentials and 3 steward credentials from two issuers
+	for i := 0; i < 10; i++ {
+		schema, issuer := "EMembershipSchema", "EIssuerA"
+		if i%3 == 2 {
+			schema = "EStewardSchema"
+		}
+		if i%2 == 1 {
+			issuer = "EIssuerB"
+		}
+		if err := handler.store.StoreCredential(ctx, &anystore.CachedCredential{
+			ID:         fmt.Sprintf("ESAID%02d", i),
+			IssuerAID:  issuer,
+			SubjectAID: fmt.Sprintf("ESubject%02d", i),
+			SchemaID:   schema,
+			Data:       map[string]string{"role": "Member"},
+		}); err != nil {
+			t.Fatalf("storing credential %d: %v", i, err)
+		}
+	}
+
+	// Totals count every credential matching the filters, across pages
+	list := func(query string, wantTotal int) ListResponse {
+		t.Helper()
+		w := httptest.NewRecorder()
+		handler.handleList(w, httptest.NewRequest(http.MethodGet, "/api/v1/credentials"+query, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: %d %s", query, w.Code, w.Body.String())
+		}
+		if got := w.Header().Get("X-Total-Count"); got != strconv.Itoa(wantTotal) {
+			t.Errorf("GET %s: X-Total-Count = %q, want %d", query, got, wantTotal)
+		}
+		var resp ListResponse
+		json.NewDecoder(w.Body).Decode(&resp)
+		return resp
+	}
+	pageThrough := func(query string, wantTotal int) []string {
+		t.Helper()
+		var saids []string
+		cursor := ""
+		for pages := 0; pages < 20; pages++ {
+			q := query
+			if cursor != "" {
+				q += "&cursor=" + cursor
+			}
+			resp := list(q, wantTotal)
+			if resp.Count != len(resp.Credentials) || resp.Total != wantTotal {
+				t.Errorf("GET %s: count=%d total=%d for %d credentials", q, resp.Count, resp.Total, len(resp.Credentials))
+			}
+			for _, c := range resp.Credentials {
+				saids = append(saids, c.SAID)
+			}
+			if resp.NextCursor == "" {
+				return saids
+			}
+			cursor = resp.NextCursor
+		}
+		t.Fatalf("GET %s: pagination did not terminate", query)
+		return nil
+	}
+
+	t.Run("pages through everything in SAID order", func(t *testing.T) {
+		first := list("?limit=4", 10)
+		if len(first.Credentials) != 4 || first.NextCursor == "" {
+			t.Fatalf("expected a first page of 4 with a cursor, got %+v", first)
+		}
+		saids := pageThrough("?limit=4", 10)
+		if len(saids) != 10 || !sort.StringsAreSorted(saids) {
+			t.Errorf("expected 10 credentials in order, got %v", saids)
+		}
+	})
+
+	t.Run("filters by schema", func(t *testing.T) {
+		saids := pageThrough("?schema=EStewardSchema&limit=2", 3)
+		if want := []string{"ESAID02", "ESAID05", "ESAID08"}; !slices.Equal(saids, want) {
+			t.Errorf("expected %v, got %v", want, saids)
+		}
+		if resp := list("?schema=EStewardSchema&issuer=EIssuerB", 1); len(resp.Credentials) != 1 || resp.Credentials[0].SAID != "ESAID05" {
+			t.Errorf("expected only ESAID05 for steward credentials from EIssuerB, got %+v", resp.Credentials)
+		}
+		if resp := list("?subject=ESubject07", 1); len(resp.Credentials) != 1 || resp.Credentials[0].Recipient != "ESubject07" {
+			t.Errorf("expected the subject's credential, got %+v", resp.Credentials)
+		}
+	})
+
+	t.Run("cursor is stable across inserts", func(t *testing.T) {
+		first := list("?limit=3", 10)
+		// A credential sorting before the cursor doesn't shift the next page
+		if err := handler.store.StoreCredential(ctx, &anystore.CachedCredential{ID: "ESAID00a", SchemaID: "EMembershipSchema"}); err != nil {
+			t.Fatalf("storing credential: %v", err)
+		}
+		defer handler.store.ClearCache(ctx, anystore.CollectionCredentialsCache)
+		w := httptest.NewRecorder()
+		handler.handleList(w, httptest.NewRequest(http.MethodGet, "/api/v1/credentials?limit=3&cursor="+first.NextCursor, nil))
+		var second ListResponse
+		json.NewDecoder(w.Body).Decode(&second)
+		if len(second.Credentials) != 3 || second.Credentials[0].SAID != "ESAID03" {
+			t.Errorf("expected the next page to start at ESAID03, got %+v", second.Credentials)
+		}
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		for _, query := range []string{"?limit=0", "?limit=abc", "?cursor=%25%25"} {
+			w := httptest.NewRecorder()
+			handler.handleList(w, httptest.NewRequest(http.MethodGet, "/api/v1/credentials"+query, nil))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("GET %s: expected 400, got %d", query, w.Code)
+			}
+		}
+	})
+}
+
 func TestRegisterRoutes(t *testing.T) {
 	handler, cleanup := setupTestHandler(t)
 	defer cleanup()
@@ -342,6 +466,7 @@ func TestRegisterRoutes(t *testing.T) {
 	}{
 		{http.MethodGet, "/api/v1/org"},
 		{http.MethodGet, "/api/v1/credentials/roles"},
+		{http.MethodGet, "/api/v1/roles"},
 		{http.MethodPost, "/api/v1/credentials"},
 		{http.MethodPost, "/api/v1/credentials/validate"},
 	}
@@ -148,7 +148,7 @@ func (h *DecisionPlansHandler) HandleTransition(w http.ResponseWriter, r *http.R
 
 	// Sign-off: community admin role OR the plan's assigned proposal steward.
 	if contributions.DecisionPlanStatus(req.Status) == contributions.DecisionPlanSignedOff {
-		aid := r.Header.Get("X-User-AID")
+		aid := requestUserAID(r)
 		if aid == "" {
 			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "X-User-AID header required"})
 			return
@@ -245,7 +245,7 @@ func (h *DecisionPlansHandler) HandleCompleteAction(w http.ResponseWriter, r *ht
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
 		return
 	}
-	userAID := r.Header.Get("X-User-AID")
+	userAID := requestUserAID(r)
 	spaceID := resolveCommunitySpaceID(r, h.spaceManager)
 	action, err := h.service.CompleteGovernanceAction(r.Context(), spaceID, actionID, contributions.OutcomeType(req.Outcome), req.CompletionNotes, req.CompletionFiles, req.CompletionLinks, userAID, req.VoterName)
 	if err != nil {
@@ -266,7 +266,7 @@ func (h *DecisionPlansHandler) HandleArchiveAction(w http.ResponseWriter, r *htt
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
 		return
 	}
-	userAID := r.Header.Get("X-User-AID")
+	userAID := requestUserAID(r)
 	spaceID := resolveCommunitySpaceID(r, h.spaceManager)
 	action, err := h.service.ArchiveGovernanceAction(r.Context(), spaceID, actionID, req.CompletionNotes, req.CompletionFiles, req.CompletionLinks, userAID)
 	if err != nil {
@@ -287,7 +287,7 @@ func (h *DecisionPlansHandler) HandleCastVote(w http.ResponseWriter, r *http.Req
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
 		return
 	}
-	userAID := r.Header.Get("X-User-AID")
+	userAID := requestUserAID(r)
 	spaceID := resolveCommunitySpaceID(r, h.spaceManager)
 	action, err := h.service.CastVote(r.Context(), spaceID, actionID, userAID, req.VoterName, contributions.OutcomeType(req.Decision), req.Comment)
 	if err != nil {
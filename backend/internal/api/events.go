@@ -2,59 +2,376 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"net"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
 
+// eventBufferSize is the number of recent events the broker keeps for
+// Last-Event-ID replay. A client that reconnects after more than this many
+// events were broadcast cannot be fully caught up: it receives an
+// "events:gap" event followed by whatever is still buffered, and should
+// refetch any state it derives from events.
+const eventBufferSize = 256
+
+// eventsPath serves the SSE stream.
+const eventsPath = "/api/v1/events"
+
+// defaultHeartbeatInterval is how often an idle SSE stream sends a
+// keep-alive comment so proxies don't drop the connection.
+const defaultHeartbeatInterval = 30 * time.Second
+
+// streamRetryAfter is the Retry-After sent with a stream refused at the
+// broker's subscriber cap.
+const streamRetryAfter = 5 * time.Second
+
+// defaultSubscriberBuffer is how many undelivered events each subscriber
+// channel holds before the overflow policy applies.
+const defaultSubscriberBuffer = 16
+
+// OverflowPolicy decides what Broadcast does when a subscriber's buffer is
+// full, so one stuck client never holds up delivery to the others.
+type OverflowPolicy string
+
+const (
+	// OverflowDropOldest discards the subscriber's oldest buffered event to
+	// make room. Its stream then sends an "events:resync" hint so the client
+	// refetches the state it derives from events.
+	OverflowDropOldest OverflowPolicy = "drop-oldest"
+	// OverflowDisconnect closes the subscriber's channel, ending its stream.
+	// The client reconnects with Last-Event-ID and is replayed what it missed.
+	OverflowDisconnect OverflowPolicy = "disconnect"
+)
+
+// ParseOverflowPolicy parses a configured overflow policy; "" is drop-oldest.
+func ParseOverflowPolicy(s string) (OverflowPolicy, error) {
+	switch OverflowPolicy(s) {
+	case "", OverflowDropOldest:
+		return OverflowDropOldest, nil
+	case OverflowDisconnect:
+		return OverflowDisconnect, nil
+	}
+	return "", fmt.Errorf("unknown SSE overflow policy %q (want %q or %q)", s, OverflowDropOldest, OverflowDisconnect)
+}
+
+// ErrTooManySubscribers is returned by SubscribeStream when the broker is
+// at its subscriber cap and eviction is off.
+var ErrTooManySubscribers = errors.New("too many event stream subscribers")
+
+// ErrTooManyClientStreams is returned by SubscribeStream when the caller
+// already has as many streams open as one client may.
+var ErrTooManyClientStreams = errors.New("too many event streams open for this client")
+
+// evictGracePeriod is how long a new stream is exempt from idle eviction, so
+// a stream that just connected isn't evicted before it could receive anything.
+const evictGracePeriod = time.Minute
+
 // SSEEvent represents a server-sent event.
+// Recipients optionally restricts delivery to subscribers registered with
+// one of the given AIDs; an empty list broadcasts to every client.
+// ID is assigned by the broker and increases monotonically.
 type SSEEvent struct {
-	Type string      `json:"type"`
-	Data interface{} `json:"data"`
+	ID         uint64      `json:"-"`
+	Type       string      `json:"type"`
+	Data       interface{} `json:"data"`
+	Recipients []string    `json:"-"`
 }
 
 // EventBroker manages SSE connections and event broadcasting.
 type EventBroker struct {
 	mu      sync.RWMutex
-	clients map[chan SSEEvent]struct{}
+	clients map[chan SSEEvent]*subscriber
+	lastID  uint64
+	history [eventBufferSize]SSEEvent // ring buffer, event N stored at N % eventBufferSize
+
+	bufferSize   int
+	policy       OverflowPolicy
+	dropped      uint64 // events dropped across all subscribers, including gone ones
+	disconnected uint64 // subscribers disconnected for being too slow
+
+	maxClients  int                        // 0 is unlimited
+	maxPerOwner int                        // streams per AID or remote host, 0 is unlimited
+	evictIdle   bool                       // make room at the cap by evicting the longest-idle subscriber
+	evicted     map[chan SSEEvent]struct{} // closed by eviction, until unsubscribed
+	rejected    uint64                     // streams refused at either cap
+	evictions   uint64                     // subscribers evicted to make room
+}
+
+// subscriber is a connected client's registration.
+type subscriber struct {
+	aid         string    // "" if anonymous
+	owner       string    // AID or remote host counted against maxPerOwner, "" if uncapped
+	dropped     uint64    // events discarded because its buffer was full
+	connectedAt time.Time // when it subscribed
+	lastSent    time.Time // last event queued to it, or when it subscribed
 }
 
 // NewEventBroker creates a new event broker.
 func NewEventBroker() *EventBroker {
 	return &EventBroker{
-		clients: make(map[chan SSEEvent]struct{}),
+		clients:    make(map[chan SSEEvent]*subscriber),
+		bufferSize: defaultSubscriberBuffer,
+		policy:     OverflowDropOldest,
+		evicted:    make(map[chan SSEEvent]struct{}),
 	}
 }
 
-// Subscribe adds a new client channel.
-func (b *EventBroker) Subscribe() chan SSEEvent {
-	ch := make(chan SSEEvent, 16)
+// SetSubscriberBuffer sets how many events each new subscriber can have
+// waiting before the overflow policy applies. Non-positive values keep the
+// default.
+func (b *EventBroker) SetSubscriberBuffer(n int) {
+	if n <= 0 {
+		return
+	}
 	b.mu.Lock()
-	b.clients[ch] = struct{}{}
+	b.bufferSize = n
 	b.mu.Unlock()
-	return ch
 }
 
-// Unsubscribe removes a client channel.
-func (b *EventBroker) Unsubscribe(ch chan SSEEvent) {
+// SetOverflowPolicy sets what happens when a subscriber's buffer is full.
+func (b *EventBroker) SetOverflowPolicy(p OverflowPolicy) {
 	b.mu.Lock()
-	delete(b.clients, ch)
+	b.policy = p
 	b.mu.Unlock()
-	close(ch)
 }
 
-// Broadcast sends an event to all connected clients.
-func (b *EventBroker) Broadcast(event SSEEvent) {
+// SetMaxSubscribers caps how many subscribers SubscribeStream admits; 0
+// removes the cap. At the cap a new stream is refused, or with evictIdle
+// the subscriber that has gone longest without an event is disconnected
+// to make room. Streams younger than evictGracePeriod are never evicted.
+func (b *EventBroker) SetMaxSubscribers(n int, evictIdle bool) {
+	b.mu.Lock()
+	b.maxClients = max(n, 0)
+	b.evictIdle = evictIdle
+	b.mu.Unlock()
+}
+
+// SetMaxSubscribersPerClient caps how many streams SubscribeStream admits
+// for one owner (an AID, or a remote host for anonymous streams), so a
+// single client can't take every slot under SetMaxSubscribers; 0 removes
+// the cap.
+func (b *EventBroker) SetMaxSubscribersPerClient(n int) {
+	b.mu.Lock()
+	b.maxPerOwner = max(n, 0)
+	b.mu.Unlock()
+}
+
+// Subscribe adds a new anonymous client channel. Anonymous clients receive
+// only untargeted events.
+func (b *EventBroker) Subscribe() chan SSEEvent {
+	return b.SubscribeAs("")
+}
+
+// SubscribeAs adds a new client channel registered to the given AID, so it
+// also receives events targeted at that AID.
+func (b *EventBroker) SubscribeAs(aid string) chan SSEEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.addLocked(aid)
+}
+
+// SubscribeSince subscribes like SubscribeAs and atomically returns the
+// buffered events after lastID that the subscriber would have received.
+// gap is true when events after lastID have already left the buffer (or
+// lastID is from before a broker restart), so the replay is incomplete.
+func (b *EventBroker) SubscribeSince(aid string, lastID uint64) (ch chan SSEEvent, missed []SSEEvent, gap bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.subscribeSinceLocked(aid, lastID)
+}
+
+// SubscribeStream subscribes a client stream within the subscriber caps
+// (see SetMaxSubscribers and SetMaxSubscribersPerClient), like
+// SubscribeSince when resume is set and SubscribeAs otherwise. owner is
+// the AID or remote host the per-client cap counts the stream against. It
+// returns ErrTooManyClientStreams when owner is at its cap, and
+// ErrTooManySubscribers when the broker is full and no subscriber can be
+// evicted. SubscribeAs and SubscribeSince always succeed, but their
+// subscribers count towards the broker cap.
+func (b *EventBroker) SubscribeStream(aid, owner string, lastID uint64, resume bool) (ch chan SSEEvent, missed []SSEEvent, gap bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.maxPerOwner > 0 && owner != "" {
+		open := 0
+		for _, sub := range b.clients {
+			if sub.owner == owner {
+				open++
+			}
+		}
+		if open >= b.maxPerOwner {
+			b.rejected++
+			return nil, nil, false, ErrTooManyClientStreams
+		}
+	}
+	if b.maxClients > 0 && len(b.clients) >= b.maxClients {
+		if !b.evictIdle || !b.evictIdlestLocked() {
+			b.rejected++
+			return nil, nil, false, ErrTooManySubscribers
+		}
+	}
+	if !resume {
+		ch = b.addLocked(aid)
+	} else {
+		ch, missed, gap = b.subscribeSinceLocked(aid, lastID)
+	}
+	b.clients[ch].owner = owner
+	return ch, missed, gap, nil
+}
+
+// addLocked registers a new subscriber channel. b.mu must be held.
+func (b *EventBroker) addLocked(aid string) chan SSEEvent {
+	ch := make(chan SSEEvent, b.bufferSize)
+	now := time.Now()
+	b.clients[ch] = &subscriber{aid: aid, connectedAt: now, lastSent: now}
+	return ch
+}
+
+// evictIdlestLocked disconnects the subscriber that has gone longest
+// without an event, skipping those connected within evictGracePeriod, and
+// reports whether there was one. b.mu must be held.
+func (b *EventBroker) evictIdlestLocked() bool {
+	var idlest chan SSEEvent
+	var since time.Time
+	settled := time.Now().Add(-evictGracePeriod)
+	for ch, sub := range b.clients {
+		if sub.connectedAt.After(settled) {
+			continue
+		}
+		if idlest == nil || sub.lastSent.Before(since) {
+			idlest, since = ch, sub.lastSent
+		}
+	}
+	if idlest == nil {
+		return false
+	}
+	log.Printf("[Events] Evicting subscriber %q, idle since %s, to admit a new stream", b.clients[idlest].aid, since.Format(time.RFC3339))
+	delete(b.clients, idlest)
+	close(idlest)
+	b.evicted[idlest] = struct{}{}
+	b.evictions++
+	return true
+}
+
+// Evicted reports whether ch was closed to make room for a new stream
+// rather than for falling behind.
+func (b *EventBroker) Evicted(ch chan SSEEvent) bool {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
-	for ch := range b.clients {
+	_, ok := b.evicted[ch]
+	return ok
+}
+
+// subscribeSinceLocked implements SubscribeSince. b.mu must be held.
+func (b *EventBroker) subscribeSinceLocked(aid string, lastID uint64) (ch chan SSEEvent, missed []SSEEvent, gap bool) {
+	ch = b.addLocked(aid)
+
+	if lastID > b.lastID {
+		return ch, nil, true
+	}
+	oldest := uint64(1)
+	if b.lastID > eventBufferSize {
+		oldest = b.lastID - eventBufferSize + 1
+	}
+	from := lastID + 1
+	if from < oldest {
+		gap = true
+		from = oldest
+	}
+	for id := from; id <= b.lastID; id++ {
+		event := b.history[id%eventBufferSize]
+		if deliverableTo(event, aid) {
+			missed = append(missed, event)
+		}
+	}
+	return ch, missed, gap
+}
+
+// Unsubscribe removes a client channel. The channel may already have been
+// closed by the disconnect overflow policy or by eviction.
+func (b *EventBroker) Unsubscribe(ch chan SSEEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.evicted, ch)
+	if _, ok := b.clients[ch]; ok {
+		delete(b.clients, ch)
+		close(ch)
+	}
+}
+
+// Broadcast sends an event to all connected clients, or only to clients
+// registered with one of event.Recipients when it is set. The event is
+// assigned the next ID and kept in the replay buffer. Broadcast never
+// blocks: a subscriber whose buffer is full is handled by the overflow
+// policy.
+func (b *EventBroker) Broadcast(event SSEEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lastID++
+	event.ID = b.lastID
+	b.history[event.ID%eventBufferSize] = event
+
+	now := time.Now()
+	for ch, sub := range b.clients {
+		if !deliverableTo(event, sub.aid) {
+			continue
+		}
+		sub.lastSent = now
 		select {
 		case ch <- event:
+			continue
+		default:
+		}
+
+		if b.policy == OverflowDisconnect {
+			log.Printf("[Events] Disconnecting slow subscriber %q: %d events undelivered", sub.aid, len(ch))
+			delete(b.clients, ch)
+			close(ch)
+			b.disconnected++
+			continue
+		}
+		// Drop-oldest: the subscriber's reader may drain concurrently, so
+		// make room and retry once; sends only happen under b.mu.
+		select {
+		case <-ch:
+			sub.dropped++
+			b.dropped++
+			if sub.dropped == 1 {
+				log.Printf("[Events] Subscriber %q is falling behind, dropping its oldest events", sub.aid)
+			}
 		default:
-			// Client is slow, skip
 		}
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Dropped returns how many events have been discarded from ch's buffer
+// under the drop-oldest policy.
+func (b *EventBroker) Dropped(ch chan SSEEvent) uint64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if sub, ok := b.clients[ch]; ok {
+		return sub.dropped
+	}
+	return 0
+}
+
+// deliverableTo reports whether event should be sent to a subscriber
+// registered with aid.
+func deliverableTo(event SSEEvent, aid string) bool {
+	if len(event.Recipients) == 0 {
+		return true
 	}
+	return aid != "" && slices.Contains(event.Recipients, aid)
 }
 
 // ClientCount returns the number of connected SSE clients.
@@ -64,17 +381,105 @@ func (b *EventBroker) ClientCount() int {
 	return len(b.clients)
 }
 
+// SubscriberStats reports one connected subscriber's backlog.
+type SubscriberStats struct {
+	AID      string `json:"aid,omitempty"`
+	Buffered int    `json:"buffered"` // events waiting to be written
+	Dropped  uint64 `json:"dropped"`  // events discarded by drop-oldest
+}
+
+// EventBrokerStats reports backpressure on the SSE broker, aggregated over
+// all subscribers.
+type EventBrokerStats struct {
+	Clients      int            `json:"clients"`
+	Policy       OverflowPolicy `json:"policy"`
+	Dropped      uint64         `json:"dropped"`      // total, including disconnected subscribers
+	Disconnected uint64         `json:"disconnected"` // subscribers disconnected for being too slow
+	MaxClients   int            `json:"maxClients,omitempty"`
+	MaxPerClient int            `json:"maxPerClient,omitempty"`
+	Rejected     uint64         `json:"rejected"` // streams refused at MaxClients or the per-client cap
+	Evicted      uint64         `json:"evicted"`  // idle subscribers disconnected to admit a new stream
+}
+
+// Stats returns the broker's overflow counters.
+func (b *EventBroker) Stats() EventBrokerStats {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return EventBrokerStats{
+		Clients:      len(b.clients),
+		Policy:       b.policy,
+		Dropped:      b.dropped,
+		Disconnected: b.disconnected,
+		MaxClients:   b.maxClients,
+		MaxPerClient: b.maxPerOwner,
+		Rejected:     b.rejected,
+		Evicted:      b.evictions,
+	}
+}
+
+// Subscribers returns each connected subscriber's backlog, most dropped
+// events first.
+func (b *EventBroker) Subscribers() []SubscriberStats {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	subs := make([]SubscriberStats, 0, len(b.clients))
+	for ch, sub := range b.clients {
+		subs = append(subs, SubscriberStats{AID: sub.aid, Buffered: len(ch), Dropped: sub.dropped})
+	}
+	slices.SortFunc(subs, func(x, y SubscriberStats) int {
+		if x.Dropped != y.Dropped {
+			if x.Dropped > y.Dropped {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(x.AID, y.AID)
+	})
+	return subs
+}
+
+// EventsDiagnostics is the response for GET /api/v1/debug/events.
+type EventsDiagnostics struct {
+	EventBrokerStats
+	Subscribers []SubscriberStats `json:"subscribers"`
+}
+
 // EventsHandler handles the SSE endpoint.
 type EventsHandler struct {
-	broker *EventBroker
+	broker    *EventBroker
+	heartbeat time.Duration
+	presence  *PresenceTracker
 }
 
 // NewEventsHandler creates a new events handler.
 func NewEventsHandler(broker *EventBroker) *EventsHandler {
-	return &EventsHandler{broker: broker}
+	return &EventsHandler{broker: broker, heartbeat: defaultHeartbeatInterval}
+}
+
+// SetHeartbeatInterval sets how often idle streams send a keep-alive
+// comment. Non-positive values keep the default.
+func (h *EventsHandler) SetHeartbeatInterval(d time.Duration) {
+	if d > 0 {
+		h.heartbeat = d
+	}
+}
+
+// SetPresenceTracker marks clients that register an AID as online while
+// their stream is open (see PresenceTracker).
+func (h *EventsHandler) SetPresenceTracker(p *PresenceTracker) {
+	h.presence = p
 }
 
 // HandleEvents handles GET /api/v1/events (SSE stream).
+// The stream receives the events targeted at the caller's authenticated AID:
+// the session token's (passed as ?access_token=, since EventSource cannot set
+// headers) or, in dev mode, the local identity's. A reconnecting client can
+// send Last-Event-ID (or ?lastEventId=) to replay buffered events it missed.
+// With a presence tracker, the AID is marked online on connect and on every
+// heartbeat, and offline once its last stream closes. A client (its AID, or
+// its remote host if anonymous) already at the per-client stream cap is
+// refused with 429. At the broker's subscriber cap the stream is refused with
+// 503, unless the broker evicts an idle subscriber to make room.
 func (h *EventsHandler) HandleEvents(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{
@@ -91,46 +496,139 @@ func (h *EventsHandler) HandleEvents(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	aid := callerAID(r.Context(), nil)
+
+	owner := aid
+	if owner == "" {
+		owner, _, _ = net.SplitHostPort(r.RemoteAddr)
+	}
+
+	lastID, resume := lastEventID(r)
+	ch, missed, gap, err := h.broker.SubscribeStream(aid, owner, lastID, resume)
+	if err != nil {
+		status := http.StatusServiceUnavailable
+		if errors.Is(err, ErrTooManyClientStreams) {
+			status = http.StatusTooManyRequests
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(int(streamRetryAfter.Seconds())))
+		writeJSON(w, status, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+	defer h.broker.Unsubscribe(ch)
+
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
 	w.Header().Set("X-Accel-Buffering", "no")
-
-	ch := h.broker.Subscribe()
-	defer h.broker.Unsubscribe(ch)
+	h.presence.Connect(aid, time.Now())
+	defer func() { h.presence.Disconnect(aid, time.Now()) }()
 
 	// Send initial connection event
 	data, _ := json.Marshal(map[string]string{"status": "connected"})
 	fmt.Fprintf(w, "event: connected\ndata: %s\n\n", data)
+
+	// Replay anything missed while disconnected
+	if gap {
+		data, _ := json.Marshal(map[string]string{"reason": "replay buffer exceeded"})
+		fmt.Fprintf(w, "event: events:gap\ndata: %s\n\n", data)
+	}
+	for _, event := range missed {
+		writeSSEEvent(w, event)
+	}
 	flusher.Flush()
 
 	// Keepalive ticker
-	ticker := time.NewTicker(30 * time.Second)
+	ticker := time.NewTicker(h.heartbeat)
 	defer ticker.Stop()
 
 	ctx := r.Context()
+	var dropped uint64
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case event, ok := <-ch:
 			if !ok {
+				// Disconnected for falling behind or evicted; the browser
+				// reconnects with Last-Event-ID and is replayed what it missed.
+				reason := "disconnected: client too slow"
+				if h.broker.Evicted(ch) {
+					reason = "disconnected: evicted while idle"
+				}
+				writeResyncHint(w, reason, 0)
+				flusher.Flush()
 				return
 			}
-			data, err := json.Marshal(event.Data)
-			if err != nil {
-				continue
+			if n := h.broker.Dropped(ch); n > dropped {
+				writeResyncHint(w, "events dropped: client too slow", n-dropped)
+				dropped = n
 			}
-			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+			writeSSEEvent(w, event)
 			flusher.Flush()
 		case <-ticker.C:
-			fmt.Fprintf(w, ": keepalive\n\n")
+			fmt.Fprintf(w, ": keep-alive\n\n")
 			flusher.Flush()
+			h.presence.Touch(aid, time.Now())
 		}
 	}
 }
 
+// writeSSEEvent writes event in SSE wire format, including its ID so the
+// browser reports it back as Last-Event-ID on reconnect.
+func writeSSEEvent(w http.ResponseWriter, event SSEEvent) {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+}
+
+// writeResyncHint tells the client it missed events and should refetch any
+// state it derives from them.
+func writeResyncHint(w http.ResponseWriter, reason string, dropped uint64) {
+	data, _ := json.Marshal(map[string]interface{}{"reason": reason, "dropped": dropped})
+	fmt.Fprintf(w, "event: events:resync\ndata: %s\n\n", data)
+}
+
+// lastEventID reads the resume point from the Last-Event-ID header, falling
+// back to the lastEventId query param for clients that reconnect manually.
+func lastEventID(r *http.Request) (uint64, bool) {
+	v := r.Header.Get("Last-Event-ID")
+	if v == "" {
+		v = r.URL.Query().Get("lastEventId")
+	}
+	if v == "" {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
+
 // RegisterRoutes registers the events route.
 func (h *EventsHandler) RegisterRoutes(mux *http.ServeMux) {
-	mux.HandleFunc("/api/v1/events", h.HandleEvents)
+	mux.HandleFunc(eventsPath, h.HandleEvents)
+}
+
+// RegisterDebugRoutes registers the per-subscriber diagnostics route; it
+// requires a community admin, as subscribers are listed by AID.
+func (h *EventsHandler) RegisterDebugRoutes(mux *http.ServeMux, roleLookup RoleLookup) {
+	mux.HandleFunc("/api/v1/debug/events", RBACMiddleware(roleLookup, requireCommunityAdmin(h.HandleEventsDebug)))
+}
+
+// HandleEventsDebug handles GET /api/v1/debug/events — the broker's overflow
+// counters plus each connected subscriber's AID and backlog.
+func (h *EventsHandler) HandleEventsDebug(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		return
+	}
+	writeJSON(w, http.StatusOK, EventsDiagnostics{
+		EventBrokerStats: h.broker.Stats(),
+		Subscribers:      h.broker.Subscribers(),
+	})
 }
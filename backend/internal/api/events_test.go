@@ -0,0 +1,528 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matou-dao/backend/internal/contributions"
+)
+
+// receive returns the next event on ch, or false if none arrives promptly.
+func receive(ch chan SSEEvent) (SSEEvent, bool) {
+	select {
+	case e := <-ch:
+		return e, true
+	case <-time.After(100 * time.Millisecond):
+		return SSEEvent{}, false
+	}
+}
+
+func TestEventBroker_TargetedDelivery(t *testing.T) {
+	broker := NewEventBroker()
+	alice := broker.SubscribeAs("EALICE")
+	bob := broker.SubscribeAs("EBOB")
+	anon := broker.Subscribe()
+	defer broker.Unsubscribe(alice)
+	defer broker.Unsubscribe(bob)
+	defer broker.Unsubscribe(anon)
+
+	broker.Broadcast(SSEEvent{Type: "chat:mention", Data: "hi", Recipients: []string{"EALICE"}})
+
+	if e, ok := receive(alice); !ok || e.Type != "chat:mention" {
+		t.Errorf("intended recipient did not receive the targeted event")
+	}
+	if _, ok := receive(bob); ok {
+		t.Error("targeted event leaked to another subscriber")
+	}
+	if _, ok := receive(anon); ok {
+		t.Error("targeted event leaked to an anonymous subscriber")
+	}
+}
+
+func TestEventBroker_UntargetedReachesAll(t *testing.T) {
+	broker := NewEventBroker()
+	alice := broker.SubscribeAs("EALICE")
+	anon := broker.Subscribe()
+	defer broker.Unsubscribe(alice)
+	defer broker.Unsubscribe(anon)
+
+	broker.Broadcast(SSEEvent{Type: "notice_created", Data: "x"})
+
+	for name, ch := range map[string]chan SSEEvent{"alice": alice, "anonymous": anon} {
+		if _, ok := receive(ch); !ok {
+			t.Errorf("%s did not receive the broadcast event", name)
+		}
+	}
+}
+
+func TestEventBroker_MultipleRecipients(t *testing.T) {
+	broker := NewEventBroker()
+	alice := broker.SubscribeAs("EALICE")
+	bob := broker.SubscribeAs("EBOB")
+	carol := broker.SubscribeAs("ECAROL")
+	defer broker.Unsubscribe(alice)
+	defer broker.Unsubscribe(bob)
+	defer broker.Unsubscribe(carol)
+
+	broker.Broadcast(SSEEvent{Type: "chat:message:new", Recipients: []string{"EALICE", "EBOB"}})
+
+	if _, ok := receive(alice); !ok {
+		t.Error("alice should receive the event")
+	}
+	if _, ok := receive(bob); !ok {
+		t.Error("bob should receive the event")
+	}
+	if _, ok := receive(carol); ok {
+		t.Error("carol should not receive the event")
+	}
+}
+
+func TestHandleEvents_RegistersAID(t *testing.T) {
+	broker := NewEventBroker()
+	mux := http.NewServeMux()
+	NewEventsHandler(broker).RegisterRoutes(mux)
+	auth := newTestAuthenticator(t, AuthModeToken)
+	srv := httptest.NewServer(AuthMiddleware(auth, mux))
+	defer srv.Close()
+
+	// The stream acts as the token's AID; a claimed ?aid= is ignored
+	token, _, _ := auth.IssueToken("EALICE", time.Now())
+	resp, err := http.Get(srv.URL + "/api/v1/events?aid=EBOB&access_token=" + token)
+	if err != nil {
+		t.Fatalf("connecting to SSE stream: %v", err)
+	}
+	defer resp.Body.Close()
+
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func() string {
+		t.Helper()
+		return readSSE(t, reader).event
+	}
+
+	if got := readEvent(); got != "connected" {
+		t.Fatalf("first event = %q, want connected", got)
+	}
+
+	broker.Broadcast(SSEEvent{Type: "for_bob", Data: "x", Recipients: []string{"EBOB"}})
+	broker.Broadcast(SSEEvent{Type: "for_alice", Data: "x", Recipients: []string{"EALICE"}})
+
+	if got := readEvent(); got != "for_alice" {
+		t.Errorf("next event = %q, want for_alice (event for bob must be skipped)", got)
+	}
+}
+
+// sseFrame is one parsed SSE message or comment.
+type sseFrame struct {
+	id, event, data, comment string
+}
+
+// readSSE reads the next SSE frame (up to a blank line) from reader.
+func readSSE(t *testing.T, reader *bufio.Reader) sseFrame {
+	t.Helper()
+	var f sseFrame
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading SSE stream: %v", err)
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "":
+			if f != (sseFrame{}) {
+				return f
+			}
+		case strings.HasPrefix(line, ": "):
+			f.comment = strings.TrimPrefix(line, ": ")
+		case strings.HasPrefix(line, "id: "):
+			f.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			f.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			f.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+// openSSE connects to the events endpoint with an optional Last-Event-ID.
+func openSSE(t *testing.T, url, lastEventID string) (*http.Response, *bufio.Reader) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, url+"/api/v1/events", nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("connecting to SSE stream: %v", err)
+	}
+	reader := bufio.NewReader(resp.Body)
+	if f := readSSE(t, reader); f.event != "connected" {
+		t.Fatalf("first event = %q, want connected", f.event)
+	}
+	return resp, reader
+}
+
+func TestHandleEvents_ReplayAfterReconnect(t *testing.T) {
+	broker := NewEventBroker()
+	mux := http.NewServeMux()
+	NewEventsHandler(broker).RegisterRoutes(mux)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	resp, reader := openSSE(t, srv.URL, "")
+	broker.Broadcast(SSEEvent{Type: "first", Data: "1"})
+	broker.Broadcast(SSEEvent{Type: "second", Data: "2"})
+	readSSE(t, reader)
+	last := readSSE(t, reader)
+	if last.event != "second" || last.id != "2" {
+		t.Fatalf("got %+v, want second with id 2", last)
+	}
+	resp.Body.Close()
+
+	// Events broadcast while the client is disconnected
+	broker.Broadcast(SSEEvent{Type: "third", Data: "3"})
+	broker.Broadcast(SSEEvent{Type: "fourth", Data: "4"})
+
+	resp, reader = openSSE(t, srv.URL, last.id)
+	defer resp.Body.Close()
+	for _, want := range []sseFrame{{id: "3", event: "third", data: `"3"`}, {id: "4", event: "fourth", data: `"4"`}} {
+		if got := readSSE(t, reader); got != want {
+			t.Errorf("replayed %+v, want %+v", got, want)
+		}
+	}
+
+	// Live delivery continues after replay
+	broker.Broadcast(SSEEvent{Type: "fifth", Data: "5"})
+	if got := readSSE(t, reader); got.event != "fifth" || got.id != "5" {
+		t.Errorf("got %+v, want fifth with id 5", got)
+	}
+}
+
+func TestHandleEvents_ReplayGap(t *testing.T) {
+	broker := NewEventBroker()
+	mux := http.NewServeMux()
+	NewEventsHandler(broker).RegisterRoutes(mux)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	for i := 0; i < eventBufferSize+10; i++ {
+		broker.Broadcast(SSEEvent{Type: "tick", Data: i})
+	}
+
+	resp, reader := openSSE(t, srv.URL, "5")
+	defer resp.Body.Close()
+	if got := readSSE(t, reader); got.event != "events:gap" {
+		t.Fatalf("got %+v, want events:gap", got)
+	}
+	if got := readSSE(t, reader); got.id != "11" {
+		t.Errorf("first replayed id = %q, want 11 (oldest buffered)", got.id)
+	}
+}
+
+func TestEventBroker_SubscribeSince(t *testing.T) {
+	broker := NewEventBroker()
+	broker.Broadcast(SSEEvent{Type: "public"})
+	broker.Broadcast(SSEEvent{Type: "for_bob", Recipients: []string{"EBOB"}})
+	broker.Broadcast(SSEEvent{Type: "for_alice", Recipients: []string{"EALICE"}})
+
+	ch, missed, gap := broker.SubscribeSince("EALICE", 0)
+	defer broker.Unsubscribe(ch)
+	if gap {
+		t.Error("unexpected gap when all events are buffered")
+	}
+	if len(missed) != 2 || missed[0].Type != "public" || missed[1].Type != "for_alice" {
+		t.Errorf("missed = %+v, want public and for_alice", missed)
+	}
+
+	ch2, _, gap := broker.SubscribeSince("EALICE", 99)
+	defer broker.Unsubscribe(ch2)
+	if !gap {
+		t.Error("expected gap for an ID newer than the broker has issued")
+	}
+}
+
+func TestHandleEvents_Heartbeat(t *testing.T) {
+	broker := NewEventBroker()
+	handler := NewEventsHandler(broker)
+	handler.SetHeartbeatInterval(20 * time.Millisecond)
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	resp, reader := openSSE(t, srv.URL, "")
+	defer resp.Body.Close()
+	if got := readSSE(t, reader); got.comment != "keep-alive" {
+		t.Errorf("got %+v, want keep-alive comment", got)
+	}
+}
+
+func TestEventBroker_SlowSubscriberDropOldest(t *testing.T) {
+	broker := NewEventBroker()
+	broker.SetSubscriberBuffer(4)
+	slow := broker.SubscribeAs("ESLOW")
+	fast := broker.SubscribeAs("EFAST")
+	defer broker.Unsubscribe(slow)
+	defer broker.Unsubscribe(fast)
+
+	// fast drains as events arrive; slow never reads
+	for i := 0; i < 10; i++ {
+		broker.Broadcast(SSEEvent{Type: "chat:message", Data: i})
+		if e, ok := receive(fast); !ok || e.Data != i {
+			t.Fatalf("fast subscriber missed event %d, got %+v", i, e)
+		}
+	}
+
+	if got := broker.Dropped(slow); got != 6 {
+		t.Errorf("expected 6 events dropped for the slow subscriber, got %d", got)
+	}
+	if got := broker.Dropped(fast); got != 0 {
+		t.Errorf("expected no drops for the fast subscriber, got %d", got)
+	}
+	// The slow subscriber keeps the newest events
+	for want := 6; want < 10; want++ {
+		if e, ok := receive(slow); !ok || e.Data != want {
+			t.Fatalf("expected buffered event %d, got %+v (ok=%v)", want, e, ok)
+		}
+	}
+
+	stats := broker.Stats()
+	if stats.Dropped != 6 || stats.Disconnected != 0 || broker.Subscribers()[0].AID != "ESLOW" {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestEventBroker_SlowSubscriberDisconnect(t *testing.T) {
+	broker := NewEventBroker()
+	broker.SetSubscriberBuffer(2)
+	broker.SetOverflowPolicy(OverflowDisconnect)
+	slow := broker.SubscribeAs("ESLOW")
+	fast := broker.SubscribeAs("EFAST")
+	defer broker.Unsubscribe(slow)
+	defer broker.Unsubscribe(fast)
+
+	for i := 0; i < 5; i++ {
+		broker.Broadcast(SSEEvent{Type: "chat:message", Data: i})
+		if _, ok := receive(fast); !ok {
+			t.Fatalf("fast subscriber missed event %d", i)
+		}
+	}
+
+	// The slow subscriber gets what was buffered, then its channel closes
+	for want := 0; want < 2; want++ {
+		if e := <-slow; e.Data != want {
+			t.Fatalf("expected buffered event %d, got %+v", want, e)
+		}
+	}
+	if _, open := <-slow; open {
+		t.Error("expected the slow subscriber's channel to be closed")
+	}
+	if stats := broker.Stats(); stats.Clients != 1 || stats.Disconnected != 1 {
+		t.Errorf("expected only the fast subscriber left after one disconnect, got %+v", stats)
+	}
+}
+
+func TestHandleEvents_ResyncHintAfterDrops(t *testing.T) {
+	broker := NewEventBroker()
+	broker.SetSubscriberBuffer(2)
+	srv := httptest.NewServer(http.HandlerFunc(NewEventsHandler(broker).HandleEvents))
+	defer srv.Close()
+
+	resp, reader := openSSE(t, srv.URL, "")
+	defer resp.Body.Close()
+
+	// Stand in for drops the broker made while the stream was behind
+	broker.mu.Lock()
+	for _, sub := range broker.clients {
+		sub.dropped = 3
+	}
+	broker.mu.Unlock()
+	broker.Broadcast(SSEEvent{Type: "chat:message", Data: "next"})
+
+	if frame := readSSE(t, reader); frame.event != "events:resync" || !strings.Contains(frame.data, `"dropped":3`) {
+		t.Fatalf("expected a resync hint for 3 dropped events, got %+v", frame)
+	}
+	if frame := readSSE(t, reader); frame.event != "chat:message" {
+		t.Fatalf("expected the event after the hint, got %+v", frame)
+	}
+}
+
+func TestHandleEvents_RejectsOverCap(t *testing.T) {
+	broker := NewEventBroker()
+	broker.SetMaxSubscribers(3, false)
+	srv := httptest.NewServer(http.HandlerFunc(NewEventsHandler(broker).HandleEvents))
+	defer srv.Close()
+
+	var open []*http.Response
+	for i := 0; i < 3; i++ {
+		resp, _ := openSSE(t, srv.URL, "")
+		open = append(open, resp)
+	}
+	defer func() {
+		for _, resp := range open {
+			resp.Body.Close()
+		}
+	}()
+
+	resp, err := http.Get(srv.URL + "/api/v1/events")
+	if err != nil {
+		t.Fatalf("connecting to SSE stream: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 past the cap, got %d", resp.StatusCode)
+	}
+	if resp.Header.Get("Retry-After") == "" {
+		t.Error("expected a Retry-After header")
+	}
+	if stats := broker.Stats(); stats.Clients != 3 || stats.Rejected != 1 {
+		t.Errorf("expected 3 clients and 1 rejection, got %+v", stats)
+	}
+
+	// Closing a stream frees its slot
+	open[0].Body.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for broker.ClientCount() == 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	resp, _ = openSSE(t, srv.URL, "")
+	open[0] = resp
+}
+
+func TestEventBroker_EvictsIdlestAtCap(t *testing.T) {
+	broker := NewEventBroker()
+	broker.SetMaxSubscribers(2, true)
+	active := broker.SubscribeAs("EACTIVE")
+	idle := broker.SubscribeAs("EIDLE")
+	defer broker.Unsubscribe(active)
+	defer broker.Unsubscribe(idle)
+
+	broker.mu.Lock()
+	for _, sub := range broker.clients {
+		sub.connectedAt = sub.connectedAt.Add(-evictGracePeriod)
+	}
+	broker.mu.Unlock()
+	broker.Broadcast(SSEEvent{Type: "for_active", Recipients: []string{"EACTIVE"}})
+
+	ch, _, _, err := broker.SubscribeStream("ENEW", "ENEW", 0, false)
+	if err != nil {
+		t.Fatalf("expected a stream to be admitted by eviction, got %v", err)
+	}
+	defer broker.Unsubscribe(ch)
+
+	if _, open := <-idle; open {
+		t.Error("expected the idle subscriber's channel to be closed")
+	}
+	if !broker.Evicted(idle) || broker.Evicted(active) {
+		t.Error("expected only the idle subscriber to be evicted")
+	}
+	if stats := broker.Stats(); stats.Clients != 2 || stats.Evicted != 1 || stats.Rejected != 0 {
+		t.Errorf("expected 2 clients after one eviction, got %+v", stats)
+	}
+}
+
+func TestEventBroker_SparesNewStreamsFromEviction(t *testing.T) {
+	broker := NewEventBroker()
+	broker.SetMaxSubscribers(1, true)
+	fresh := broker.SubscribeAs("EFRESH")
+	defer broker.Unsubscribe(fresh)
+
+	if _, _, _, err := broker.SubscribeStream("ENEW", "ENEW", 0, false); !errors.Is(err, ErrTooManySubscribers) {
+		t.Fatalf("expected a stream inside its grace period not to be evicted, got %v", err)
+	}
+	if broker.Evicted(fresh) {
+		t.Error("expected the fresh subscriber to stay connected")
+	}
+}
+
+func TestEventBroker_PerClientCap(t *testing.T) {
+	broker := NewEventBroker()
+	broker.SetMaxSubscribersPerClient(2)
+	for i := 0; i < 2; i++ {
+		ch, _, _, err := broker.SubscribeStream("EALICE", "EALICE", 0, false)
+		if err != nil {
+			t.Fatalf("stream %d: %v", i, err)
+		}
+		defer broker.Unsubscribe(ch)
+	}
+
+	if _, _, _, err := broker.SubscribeStream("EALICE", "EALICE", 0, false); !errors.Is(err, ErrTooManyClientStreams) {
+		t.Fatalf("expected the third stream for one AID to be refused, got %v", err)
+	}
+	ch, _, _, err := broker.SubscribeStream("EBOB", "EBOB", 0, false)
+	if err != nil {
+		t.Fatalf("expected another AID to be admitted, got %v", err)
+	}
+	defer broker.Unsubscribe(ch)
+	if stats := broker.Stats(); stats.Clients != 3 || stats.Rejected != 1 {
+		t.Errorf("expected 3 clients and 1 rejection, got %+v", stats)
+	}
+}
+
+func TestHandleEvents_RejectsOverPerClientCap(t *testing.T) {
+	broker := NewEventBroker()
+	broker.SetMaxSubscribersPerClient(1)
+	srv := httptest.NewServer(http.HandlerFunc(NewEventsHandler(broker).HandleEvents))
+	defer srv.Close()
+
+	open, _ := openSSE(t, srv.URL, "")
+	defer open.Body.Close()
+
+	// Anonymous streams are counted per remote host
+	resp, err := http.Get(srv.URL + "/api/v1/events")
+	if err != nil {
+		t.Fatalf("connecting to SSE stream: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 past the per-client cap, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandleEventsDebug_AdminOnly(t *testing.T) {
+	broker := NewEventBroker()
+	ch := broker.SubscribeAs("ESUBSCRIBER")
+	defer broker.Unsubscribe(ch)
+	broker.Broadcast(SSEEvent{Type: "chat:message", Data: "queued"})
+
+	roleLookup := &mockRoleLookup{roles: map[string][]contributions.Role{
+		adminTestAdminAID:  contributions.MapKERIRole("Founding Member"),
+		adminTestMemberAID: contributions.MapKERIRole("Member"),
+	}}
+	mux := http.NewServeMux()
+	NewEventsHandler(broker).RegisterDebugRoutes(mux, roleLookup)
+
+	get := func(aid string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/debug/events", nil)
+		req.Header.Set("X-User-AID", aid)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get(adminTestMemberAID); w.Code != http.StatusForbidden {
+		t.Fatalf("member: expected 403, got %d: %s", w.Code, w.Body.String())
+	}
+	w := get(adminTestAdminAID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("admin: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp EventsDiagnostics
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if resp.Clients != 1 || len(resp.Subscribers) != 1 || resp.Subscribers[0].AID != "ESUBSCRIBER" || resp.Subscribers[0].Buffered != 1 {
+		t.Errorf("unexpected diagnostics %+v", resp)
+	}
+
+	// /health only carries the aggregate counters
+	body, _ := json.Marshal(broker.Stats())
+	if strings.Contains(string(body), "ESUBSCRIBER") {
+		t.Errorf("expected no subscriber AIDs in the aggregate stats, got %s", body)
+	}
+}
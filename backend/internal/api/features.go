@@ -0,0 +1,152 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/matou-dao/backend/internal/anysync"
+)
+
+// Feature names, as used in admin settings featureFlags and disabled-route errors.
+const (
+	FeatureChat    = "chat"
+	FeatureNotices = "notices"
+	FeatureTrust   = "trust"
+)
+
+// featureRoutes maps the path prefixes served by each feature.
+var featureRoutes = []struct {
+	prefix  string
+	feature string
+}{
+	{"/api/v1/chat", FeatureChat},
+	{"/api/v1/notices", FeatureNotices},
+	{"/api/v1/comment-cursors", FeatureNotices},
+	{"/api/v1/trust", FeatureTrust},
+}
+
+// featureCacheTTL is how long the effective flags are reused before the
+// admin settings are read again.
+const featureCacheTTL = 30 * time.Second
+
+// FeatureFlags reports which optional features a deployment serves.
+type FeatureFlags struct {
+	Chat    bool `json:"chat"`
+	Notices bool `json:"notices"`
+	Trust   bool `json:"trust"`
+}
+
+// enabled reports whether the named feature is on. Unknown names are on.
+func (f FeatureFlags) enabled(feature string) bool {
+	switch feature {
+	case FeatureChat:
+		return f.Chat
+	case FeatureNotices:
+		return f.Notices
+	case FeatureTrust:
+		return f.Trust
+	}
+	return true
+}
+
+// FeatureGate resolves the effective feature flags: a feature is on when the
+// config enables it and the admin space settings don't turn it off. An admin
+// can disable a feature for the community but not enable one the deployment
+// has disabled.
+type FeatureGate struct {
+	config       FeatureFlags
+	spaceManager *anysync.SpaceManager
+
+	mu       sync.Mutex
+	cached   FeatureFlags
+	cachedAt time.Time
+}
+
+// NewFeatureGate creates a gate over the configured flags. spaceManager may
+// be nil, in which case only the config applies.
+func NewFeatureGate(config FeatureFlags, spaceManager *anysync.SpaceManager) *FeatureGate {
+	return &FeatureGate{config: config, spaceManager: spaceManager}
+}
+
+// Flags returns the effective flags, reading the admin settings at most once
+// per featureCacheTTL. If they can't be read the config applies.
+func (g *FeatureGate) Flags(ctx context.Context) FeatureFlags {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.cachedAt.IsZero() && time.Since(g.cachedAt) < featureCacheTTL {
+		return g.cached
+	}
+
+	flags := g.config
+	if g.spaceManager != nil {
+		if adminSpaceID := g.spaceManager.GetAdminSpaceID(); adminSpaceID != "" {
+			if settings, _, err := readAdminSettings(ctx, g.spaceManager, adminSpaceID); err == nil {
+				for _, f := range []struct {
+					name string
+					on   *bool
+				}{
+					{FeatureChat, &flags.Chat},
+					{FeatureNotices, &flags.Notices},
+					{FeatureTrust, &flags.Trust},
+				} {
+					if on, set := settings.FeatureFlags[f.name]; set && !on {
+						*f.on = false
+					}
+				}
+			}
+		}
+	}
+	g.cached, g.cachedAt = flags, time.Now()
+	return flags
+}
+
+// Invalidate drops the cached flags, e.g. after the admin settings change.
+func (g *FeatureGate) Invalidate() {
+	g.mu.Lock()
+	g.cachedAt = time.Time{}
+	g.mu.Unlock()
+}
+
+// HandleFeatures handles GET /api/v1/features — the effective feature flags,
+// so clients can hide what the deployment doesn't serve.
+func (g *FeatureGate) HandleFeatures(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		return
+	}
+	writeJSON(w, http.StatusOK, g.Flags(r.Context()))
+}
+
+// RegisterRoutes registers the features route.
+func (g *FeatureGate) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/api/v1/features", CORSHandler(g.HandleFeatures))
+}
+
+// FeatureGuard answers 404 for the routes of disabled features, as if they
+// weren't served. A nil gate serves everything.
+func FeatureGuard(g *FeatureGate, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if g != nil {
+			if feature := routeFeature(r.URL.Path); feature != "" && !g.Flags(r.Context()).enabled(feature) {
+				writeJSON(w, http.StatusNotFound, map[string]string{
+					"error": "feature disabled: " + feature,
+				})
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// routeFeature returns the feature serving path, or "" if it isn't optional.
+func routeFeature(path string) string {
+	for _, route := range featureRoutes {
+		if path == route.prefix || strings.HasPrefix(path, route.prefix+"/") {
+			return route.feature
+		}
+	}
+	return ""
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFeatureGuard_DisabledRoutesNotServed(t *testing.T) {
+	gate := NewFeatureGate(FeatureFlags{Chat: false, Notices: true, Trust: true}, nil)
+	mux := http.NewServeMux()
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	for _, path := range []string{"/api/v1/chat/channels", "/api/v1/chat/messages/", "/api/v1/notices", "/api/v1/trust/summary", "/api/v1/chatbot"} {
+		mux.HandleFunc(path, ok)
+	}
+	gate.RegisterRoutes(mux)
+	handler := FeatureGuard(gate, mux)
+
+	for path, want := range map[string]int{
+		"/api/v1/chat/channels":      http.StatusNotFound,
+		"/api/v1/chat/messages/msg1": http.StatusNotFound,
+		"/api/v1/notices":            http.StatusOK,
+		"/api/v1/trust/summary":      http.StatusOK,
+		"/api/v1/chatbot":            http.StatusOK, // not under /api/v1/chat/
+		"/api/v1/features":           http.StatusOK,
+	} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != want {
+			t.Errorf("%s: expected %d, got %d: %s", path, want, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestHandleFeatures_ReflectsConfig(t *testing.T) {
+	want := FeatureFlags{Chat: true, Notices: false, Trust: true}
+	mux := http.NewServeMux()
+	NewFeatureGate(want, nil).RegisterRoutes(mux)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/features", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var got FeatureFlags
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if got != want {
+		t.Errorf("features = %+v, want %+v", got, want)
+	}
+}
+
+func TestFeatureGate_AdminSettingsDisable(t *testing.T) {
+	handler, mux, _ := setupAdminTestEnv(t)
+	gate := NewFeatureGate(FeatureFlags{Chat: true, Notices: true, Trust: false}, handler.spaceManager)
+	handler.SetFeatureGate(gate)
+	ctx := context.Background()
+
+	if got := gate.Flags(ctx); got != (FeatureFlags{Chat: true, Notices: true}) {
+		t.Fatalf("expected the configured flags before any settings, got %+v", got)
+	}
+
+	// Admins can turn a feature off, but not one the deployment disabled back on
+	w := doAdminRequest(mux, http.MethodPost, `{"featureFlags":{"notices":false,"trust":true}}`, adminTestAdminAID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update settings: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := gate.Flags(ctx); got != (FeatureFlags{Chat: true}) {
+		t.Errorf("expected only chat on after the settings update, got %+v", got)
+	}
+}
@@ -3,9 +3,12 @@ package api
 import (
 	"bytes"
 	"context"
-	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"strings"
 	"time"
@@ -17,69 +20,109 @@ import (
 
 const maxFileSize = 20 << 20 // 20 MB
 
+// fileStore stores and retrieves file bytes; implemented by anysync.FileManager
+type fileStore interface {
+	AddFile(ctx context.Context, spaceID string, reader io.Reader, contentType string, size int64, signingKey crypto.PrivKey) (string, error)
+	AddFileWithThumbnail(ctx context.Context, spaceID string, reader io.Reader, contentType string, size int64, thumbnailRef string, signingKey crypto.PrivKey) (string, error)
+	GetFile(ctx context.Context, spaceID string, fileRef string) (io.ReadSeekCloser, string, error)
+	GetFileMeta(ctx context.Context, spaceID string, fileRef string) (*anysync.FileMeta, error)
+}
+
 // FilesHandler handles file upload and download using the any-sync filenode.
 // Files are chunked into IPFS UnixFS DAG blocks, pushed to the filenode via
 // dRPC, and metadata is persisted in the community space's ObjectTree for P2P sync.
 type FilesHandler struct {
-	fileManager  *anysync.FileManager
+	files        fileStore
 	spaceManager *anysync.SpaceManager
+	maxSize      int64
+	allowedTypes []string
+	thumbnailDim int
+	avatarSize   int64 // largest avatar accepted by HandleAvatarUpload
 }
 
 // NewFilesHandler creates a new files handler backed by the filenode.
 func NewFilesHandler(fileManager *anysync.FileManager, spaceManager *anysync.SpaceManager) *FilesHandler {
-	return &FilesHandler{
-		fileManager:  fileManager,
+	h := &FilesHandler{
 		spaceManager: spaceManager,
+		maxSize:      maxFileSize,
+		thumbnailDim: defaultThumbnailMaxDimension,
+		avatarSize:   defaultMaxAvatarSize,
+	}
+	if fileManager != nil {
+		h.files = fileManager
+	}
+	return h
+}
+
+// SetLimits sets the maximum upload size in bytes and the allowed content
+// types. Types may end in "/*" to allow a whole family (e.g. "image/*"); an
+// empty list allows any type.
+func (h *FilesHandler) SetLimits(maxSize int64, allowedTypes []string) {
+	if maxSize > 0 {
+		h.maxSize = maxSize
 	}
+	h.allowedTypes = allowedTypes
 }
 
-// HandleUpload handles POST /api/v1/files/upload
-// Accepts multipart file upload (any type, max 20MB).
-// Returns a fileRef (CID string) that can be stored in profile objects.
+// SetThumbnailMaxDimension sets the largest width or height of thumbnails
+// generated for uploaded images. Zero disables thumbnails.
+func (h *FilesHandler) SetThumbnailMaxDimension(maxDim int) {
+	h.thumbnailDim = maxDim
+}
+
+// contentTypeAllowed reports whether mediaType matches the allowed types
+func (h *FilesHandler) contentTypeAllowed(mediaType string) bool {
+	if len(h.allowedTypes) == 0 {
+		return true
+	}
+	for _, allowed := range h.allowedTypes {
+		if family, ok := strings.CutSuffix(allowed, "/*"); ok {
+			if strings.HasPrefix(mediaType, family+"/") {
+				return true
+			}
+		} else if mediaType == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// uploadContentType returns the media type for an upload: the declared type if
+// it parses, otherwise (or for a generic application/octet-stream) the type
+// sniffed from the content.
+func uploadContentType(declared string, data []byte) string {
+	if mediaType, _, err := mime.ParseMediaType(declared); err == nil && mediaType != "application/octet-stream" {
+		return mediaType
+	}
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	return mediaType
+}
+
+// HandleUpload handles POST /api/v1/files (and the older /api/v1/files/upload)
+// Accepts multipart file upload within the configured size and content type limits.
+// Returns an AttachmentRef whose fileRef (CID string) can be stored in objects.
 func (h *FilesHandler) HandleUpload(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
 		return
 	}
 
-	if h.fileManager == nil {
+	if h.files == nil {
 		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
 			"error": "file storage not available (filenode not configured)",
 		})
 		return
 	}
 
-	r.Body = http.MaxBytesReader(w, r.Body, maxFileSize+1024) // extra for form overhead
-
-	if err := r.ParseMultipartForm(maxFileSize); err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{
-			"error": fmt.Sprintf("file too large or invalid form: %v", err),
-		})
+	data, header, ok := readUploadedFile(w, r, h.maxSize)
+	if !ok {
 		return
 	}
 
-	file, header, err := r.FormFile("file")
-	if err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{
-			"error": fmt.Sprintf("missing file field: %v", err),
-		})
-		return
-	}
-	defer file.Close()
-
-	contentType := header.Header.Get("Content-Type")
-
-	// Read file content (need to know size for metadata)
-	data, err := io.ReadAll(io.LimitReader(file, maxFileSize+1))
-	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": fmt.Sprintf("failed to read file: %v", err),
-		})
-		return
-	}
-	if len(data) > maxFileSize {
-		writeJSON(w, http.StatusBadRequest, map[string]string{
-			"error": "file exceeds 20MB limit",
+	contentType := uploadContentType(header.Header.Get("Content-Type"), data)
+	if !h.contentTypeAllowed(contentType) {
+		writeJSON(w, http.StatusUnsupportedMediaType, map[string]string{
+			"error": fmt.Sprintf("content type %s is not allowed", contentType),
 		})
 		return
 	}
@@ -96,13 +139,17 @@ func (h *FilesHandler) HandleUpload(w http.ResponseWriter, r *http.Request) {
 	// Load signing key for the space
 	signingKey := h.spaceManager.GetClient().GetSigningKey()
 
+	// Upload a thumbnail first so the original's metadata can reference it
+	thumbnailRef := h.uploadThumbnail(r.Context(), spaceID, contentType, data, signingKey)
+
 	// Upload to filenode
-	fileRef, err := h.fileManager.AddFile(
+	fileRef, err := h.files.AddFileWithThumbnail(
 		r.Context(),
 		spaceID,
 		bytes.NewReader(data),
 		contentType,
 		int64(len(data)),
+		thumbnailRef,
 		signingKey,
 	)
 	if err != nil {
@@ -112,22 +159,99 @@ func (h *FilesHandler) HandleUpload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]string{
-		"fileRef":     fileRef,
-		"contentType": contentType,
-		"size":        fmt.Sprintf("%d", len(data)),
+	writeJSON(w, http.StatusOK, AttachmentRef{
+		FileRef:      fileRef,
+		FileName:     header.Filename,
+		ContentType:  contentType,
+		Size:         int64(len(data)),
+		ThumbnailRef: thumbnailRef,
 	})
 }
 
-// HandleDownload handles GET /api/v1/files/{ref}
-// Returns the file bytes with appropriate Content-Type.
+// readUploadedFile reads the multipart "file" field of r, which must be
+// non-empty and at most maxSize bytes. On failure it writes the error response
+// and returns false.
+func readUploadedFile(w http.ResponseWriter, r *http.Request, maxSize int64) ([]byte, *multipart.FileHeader, bool) {
+	tooLarge := fmt.Sprintf("file exceeds %d byte limit", maxSize)
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+1024) // extra for form overhead
+
+	if err := r.ParseMultipartForm(maxSize); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			writeJSON(w, http.StatusRequestEntityTooLarge, map[string]string{"error": tooLarge})
+			return nil, nil, false
+		}
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("invalid form: %v", err),
+		})
+		return nil, nil, false
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("missing file field: %v", err),
+		})
+		return nil, nil, false
+	}
+	defer file.Close()
+
+	// Read file content (need to know size for metadata)
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("failed to read file: %v", err),
+		})
+		return nil, nil, false
+	}
+	if int64(len(data)) > maxSize {
+		writeJSON(w, http.StatusRequestEntityTooLarge, map[string]string{"error": tooLarge})
+		return nil, nil, false
+	}
+	if len(data) == 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "file is empty"})
+		return nil, nil, false
+	}
+	return data, header, true
+}
+
+// uploadThumbnail stores a thumbnail for an image upload and returns its ref.
+// It returns "" for non-images, images already small enough, and failures,
+// which are logged: a missing thumbnail just means clients load the original.
+func (h *FilesHandler) uploadThumbnail(ctx context.Context, spaceID, contentType string, data []byte, signingKey crypto.PrivKey) string {
+	if h.thumbnailDim <= 0 || !strings.HasPrefix(contentType, "image/") {
+		return ""
+	}
+	thumb, thumbType, err := makeThumbnail(data, h.thumbnailDim)
+	if err != nil {
+		log.Printf("[Files] No thumbnail for %s upload: %v", contentType, err)
+		return ""
+	}
+	if thumb == nil {
+		return ""
+	}
+	ref, err := h.files.AddFile(ctx, spaceID, bytes.NewReader(thumb), thumbType, int64(len(thumb)), signingKey)
+	if err != nil {
+		log.Printf("[Files] Failed to upload thumbnail: %v", err)
+		return ""
+	}
+	return ref
+}
+
+// HandleDownload handles GET and HEAD /api/v1/files/{ref}
+// Streams the file bytes with appropriate Content-Type. Range requests get
+// 206 Partial Content (416 when unsatisfiable), for media players and
+// resumable downloads. Files are content-addressed, so the served CID is a
+// strong ETag, which If-Range and If-None-Match are checked against.
+// With ?thumb=1 it streams the file's thumbnail instead, or the file itself
+// if it has none.
 func (h *FilesHandler) HandleDownload(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
 		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
 		return
 	}
 
-	if h.fileManager == nil {
+	if h.files == nil {
 		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
 			"error": "file storage not available (filenode not configured)",
 		})
@@ -157,8 +281,14 @@ func (h *FilesHandler) HandleDownload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if r.URL.Query().Get("thumb") == "1" {
+		if meta, err := h.files.GetFileMeta(r.Context(), spaceID, ref); err == nil && meta.ThumbnailRef != "" {
+			ref = meta.ThumbnailRef
+		}
+	}
+
 	// Fetch from filenode
-	reader, contentType, err := h.fileManager.GetFile(r.Context(), spaceID, ref)
+	reader, contentType, err := h.files.GetFile(r.Context(), spaceID, ref)
 	if err != nil {
 		writeJSON(w, http.StatusNotFound, map[string]string{
 			"error": fmt.Sprintf("file not found: %v", err),
@@ -168,72 +298,16 @@ func (h *FilesHandler) HandleDownload(w http.ResponseWriter, r *http.Request) {
 	defer reader.Close()
 
 	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
 	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
-	w.WriteHeader(http.StatusOK)
-	io.Copy(w, reader)
-}
-
-// uploadBase64Avatar decodes base64-encoded image data and uploads it to the
-// filenode. Returns the content-addressed fileRef (CID) on success.
-// This is used as a fallback when the normal file upload couldn't run because
-// the community space didn't exist yet (e.g. during onboarding).
-// Retries with backoff because the filenode peer pool may not be connected yet
-// after an SDK reinit (which happens during identity setup just before this).
-func uploadBase64Avatar(ctx context.Context, fileManager *anysync.FileManager, spaceID string, signingKey crypto.PrivKey, base64Data string, mimeType string) (string, error) {
-	if fileManager == nil {
-		return "", fmt.Errorf("file manager not available")
-	}
-	if spaceID == "" {
-		return "", fmt.Errorf("space ID is required")
-	}
-
-	if mimeType == "" {
-		mimeType = "image/png"
-	}
-	if !strings.HasPrefix(mimeType, "image/") {
-		return "", fmt.Errorf("only image files are accepted, got %s", mimeType)
-	}
-
-	data, err := base64.StdEncoding.DecodeString(base64Data)
-	if err != nil {
-		return "", fmt.Errorf("failed to decode base64 data: %w", err)
-	}
-
-	if len(data) > maxFileSize {
-		return "", fmt.Errorf("decoded image exceeds %d byte limit", maxFileSize)
-	}
-
-	const maxAttempts = 5
-	var lastErr error
-	for attempt := 1; attempt <= maxAttempts; attempt++ {
-		fileRef, err := fileManager.AddFile(
-			ctx,
-			spaceID,
-			bytes.NewReader(data),
-			mimeType,
-			int64(len(data)),
-			signingKey,
-		)
-		if err == nil {
-			return fileRef, nil
-		}
-		lastErr = err
-		if attempt < maxAttempts {
-			delay := time.Duration(attempt) * 2 * time.Second
-			fmt.Printf("[uploadBase64Avatar] attempt %d/%d failed: %v — retrying in %v\n", attempt, maxAttempts, err, delay)
-			select {
-			case <-time.After(delay):
-			case <-ctx.Done():
-				return "", ctx.Err()
-			}
-		}
-	}
-
-	return "", fmt.Errorf("failed to upload avatar after %d attempts: %w", maxAttempts, lastErr)
+	w.Header().Set("ETag", `"`+ref+`"`)
+	http.ServeContent(w, r, "", time.Time{}, reader)
 }
 
 // RegisterRoutes registers file routes on the mux.
 func (h *FilesHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/api/v1/files", h.HandleUpload)
 	mux.HandleFunc("/api/v1/files/upload", h.HandleUpload)
+	mux.HandleFunc("/api/v1/files/avatar", h.HandleAvatarUpload)
 	mux.HandleFunc("/api/v1/files/", h.HandleDownload)
 }
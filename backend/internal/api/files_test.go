@@ -2,13 +2,21 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
+	"image"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/textproto"
 	"testing"
 
+	"github.com/anyproto/any-sync/util/crypto"
+	"github.com/ipfs/go-cid"
 	"github.com/matou-dao/backend/internal/anysync"
+	"github.com/multiformats/go-multihash"
 )
 
 func TestFilesHandler_Upload_NilFileManager(t *testing.T) {
@@ -191,3 +199,314 @@ func TestFilesHandler_RegisterRoutes(t *testing.T) {
 		t.Error("download route not registered")
 	}
 }
+
+// mockFileStore is an in-memory filenode: files are addressed by the CID of
+// their raw bytes.
+type mockFileStore struct {
+	files        map[string][]byte
+	contentTypes map[string]string
+	thumbnails   map[string]string
+}
+
+func newMockFileStore() *mockFileStore {
+	return &mockFileStore{files: map[string][]byte{}, contentTypes: map[string]string{}, thumbnails: map[string]string{}}
+}
+
+func (m *mockFileStore) AddFile(ctx context.Context, spaceID string, reader io.Reader, contentType string, size int64, signingKey crypto.PrivKey) (string, error) {
+	return m.AddFileWithThumbnail(ctx, spaceID, reader, contentType, size, "", signingKey)
+}
+
+func (m *mockFileStore) AddFileWithThumbnail(ctx context.Context, spaceID string, reader io.Reader, contentType string, size int64, thumbnailRef string, signingKey crypto.PrivKey) (string, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	hash, err := multihash.Sum(data, multihash.SHA2_256, -1)
+	if err != nil {
+		return "", err
+	}
+	ref := cid.NewCidV1(cid.Raw, hash).String()
+	m.files[ref] = data
+	m.contentTypes[ref] = contentType
+	m.thumbnails[ref] = thumbnailRef
+	return ref, nil
+}
+
+func (m *mockFileStore) GetFileMeta(ctx context.Context, spaceID string, fileRef string) (*anysync.FileMeta, error) {
+	data, ok := m.files[fileRef]
+	if !ok {
+		return nil, fmt.Errorf("file %s not found", fileRef)
+	}
+	return &anysync.FileMeta{
+		CID:          fileRef,
+		ContentType:  m.contentTypes[fileRef],
+		Size:         int64(len(data)),
+		ThumbnailRef: m.thumbnails[fileRef],
+	}, nil
+}
+
+func (m *mockFileStore) GetFile(ctx context.Context, spaceID string, fileRef string) (io.ReadSeekCloser, string, error) {
+	data, ok := m.files[fileRef]
+	if !ok {
+		return nil, "", fmt.Errorf("file %s not found", fileRef)
+	}
+	return nopReadSeekCloser{bytes.NewReader(data)}, m.contentTypes[fileRef], nil
+}
+
+type nopReadSeekCloser struct{ io.ReadSeeker }
+
+func (nopReadSeekCloser) Close() error { return nil }
+
+// setupFilesTestHandler returns a handler backed by a mock file store with a
+// community space configured.
+func setupFilesTestHandler(t *testing.T) (*FilesHandler, *mockFileStore) {
+	t.Helper()
+	sm := anysync.NewSpaceManager(newMockClient(), &anysync.SpaceManagerConfig{
+		CommunitySpaceID: "test-community-space",
+	})
+	store := newMockFileStore()
+	handler := NewFilesHandler(nil, sm)
+	handler.files = store
+	return handler, store
+}
+
+// multipartUpload builds a POST /api/v1/files request carrying one file.
+func multipartUpload(t *testing.T, filename, contentType string, data []byte) *http.Request {
+	t.Helper()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	partHeader := textproto.MIMEHeader{}
+	partHeader.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, filename))
+	if contentType != "" {
+		partHeader.Set("Content-Type", contentType)
+	}
+	part, err := writer.CreatePart(partHeader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(data)
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/files", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestFilesHandler_UploadDownloadRoundTrip(t *testing.T) {
+	handler, _ := setupFilesTestHandler(t)
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+	data := []byte("%PDF-1.4 meeting minutes")
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, multipartUpload(t, "minutes.pdf", "application/pdf", data))
+	if w.Code != http.StatusOK {
+		t.Fatalf("upload: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var ref AttachmentRef
+	if err := json.NewDecoder(w.Body).Decode(&ref); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cid.Decode(ref.FileRef); err != nil {
+		t.Errorf("fileRef %q is not a CID: %v", ref.FileRef, err)
+	}
+	if ref.FileName != "minutes.pdf" || ref.ContentType != "application/pdf" || ref.Size != int64(len(data)) {
+		t.Errorf("unexpected attachment ref: %+v", ref)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/files/"+ref.FileRef, nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("download: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/pdf" {
+		t.Errorf("Content-Type = %q, want application/pdf", got)
+	}
+	if !bytes.Equal(w.Body.Bytes(), data) {
+		t.Errorf("downloaded %q, want %q", w.Body.Bytes(), data)
+	}
+}
+
+func TestFilesHandler_Upload_SniffsMissingContentType(t *testing.T) {
+	handler, store := setupFilesTestHandler(t)
+
+	w := httptest.NewRecorder()
+	handler.HandleUpload(w, multipartUpload(t, "notes.txt", "", []byte("plain text notes")))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var ref AttachmentRef
+	json.NewDecoder(w.Body).Decode(&ref)
+	if ref.ContentType != "text/plain" || store.contentTypes[ref.FileRef] != "text/plain" {
+		t.Errorf("content type = %q, want text/plain", ref.ContentType)
+	}
+}
+
+func TestFilesHandler_Upload_Oversize(t *testing.T) {
+	handler, store := setupFilesTestHandler(t)
+	handler.SetLimits(16, nil)
+
+	w := httptest.NewRecorder()
+	handler.HandleUpload(w, multipartUpload(t, "big.bin", "application/octet-stream", bytes.Repeat([]byte("x"), 64)))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(store.files) != 0 {
+		t.Error("expected nothing to be stored")
+	}
+}
+
+func TestFilesHandler_Upload_ContentTypeNotAllowed(t *testing.T) {
+	handler, store := setupFilesTestHandler(t)
+	handler.SetLimits(0, []string{"image/*", "application/pdf"})
+
+	w := httptest.NewRecorder()
+	handler.HandleUpload(w, multipartUpload(t, "script.html", "text/html", []byte("<html></html>")))
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("expected 415, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(store.files) != 0 {
+		t.Error("expected nothing to be stored")
+	}
+
+	w = httptest.NewRecorder()
+	handler.HandleUpload(w, multipartUpload(t, "photo.png", "image/png", []byte("\x89PNG\r\n\x1a\n")))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200 for an allowed family, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// uploadAndFetchThumb uploads a file then downloads it with ?thumb=1.
+func uploadAndFetchThumb(t *testing.T, handler *FilesHandler, filename, contentType string, data []byte) (AttachmentRef, *httptest.ResponseRecorder) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	handler.HandleUpload(w, multipartUpload(t, filename, contentType, data))
+	if w.Code != http.StatusOK {
+		t.Fatalf("upload: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var ref AttachmentRef
+	if err := json.NewDecoder(w.Body).Decode(&ref); err != nil {
+		t.Fatal(err)
+	}
+
+	w = httptest.NewRecorder()
+	handler.HandleDownload(w, httptest.NewRequest(http.MethodGet, "/api/v1/files/"+ref.FileRef+"?thumb=1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("download: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	return ref, w
+}
+
+func TestFilesHandler_ImageThumbnail(t *testing.T) {
+	handler, _ := setupFilesTestHandler(t)
+	handler.SetThumbnailMaxDimension(64)
+
+	ref, w := uploadAndFetchThumb(t, handler, "photo.jpg", "image/jpeg", testJPEG(t, 400, 200))
+	if ref.ThumbnailRef == "" || ref.ThumbnailRef == ref.FileRef {
+		t.Fatalf("expected a separate thumbnailRef, got %+v", ref)
+	}
+	if got := w.Header().Get("Content-Type"); got != "image/jpeg" {
+		t.Errorf("thumbnail Content-Type = %q, want image/jpeg", got)
+	}
+	cfg, _, err := image.DecodeConfig(w.Body)
+	if err != nil {
+		t.Fatalf("decoding thumbnail: %v", err)
+	}
+	if cfg.Width != 64 || cfg.Height != 32 {
+		t.Errorf("thumbnail is %dx%d, want 64x32", cfg.Width, cfg.Height)
+	}
+}
+
+func TestFilesHandler_NonImagePassthrough(t *testing.T) {
+	handler, _ := setupFilesTestHandler(t)
+	data := []byte("quarterly report")
+
+	ref, w := uploadAndFetchThumb(t, handler, "report.txt", "text/plain", data)
+	if ref.ThumbnailRef != "" {
+		t.Errorf("expected no thumbnail for a text file, got %q", ref.ThumbnailRef)
+	}
+	if !bytes.Equal(w.Body.Bytes(), data) {
+		t.Errorf("?thumb=1 returned %q, want the original file", w.Body.Bytes())
+	}
+}
+
+// downloadWithHeaders fetches ref with the given request headers.
+func downloadWithHeaders(handler *FilesHandler, method, ref string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/api/v1/files/"+ref, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	handler.HandleDownload(w, req)
+	return w
+}
+
+func TestFilesHandler_Download_Range(t *testing.T) {
+	handler, store := setupFilesTestHandler(t)
+	data := []byte("0123456789abcdefghij")
+	ref, err := store.AddFile(context.Background(), "test-community-space", bytes.NewReader(data), "audio/ogg", int64(len(data)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	etag := `"` + ref + `"`
+
+	// Full GET advertises range support and a strong ETag
+	w := downloadWithHeaders(handler, http.MethodGet, ref, nil)
+	if w.Code != http.StatusOK || !bytes.Equal(w.Body.Bytes(), data) {
+		t.Fatalf("full GET: got %d %q", w.Code, w.Body.Bytes())
+	}
+	if got := w.Header().Get("Accept-Ranges"); got != "bytes" {
+		t.Errorf("Accept-Ranges = %q, want bytes", got)
+	}
+	if got := w.Header().Get("ETag"); got != etag {
+		t.Errorf("ETag = %q, want %q", got, etag)
+	}
+
+	// Byte range
+	w = downloadWithHeaders(handler, http.MethodGet, ref, map[string]string{"Range": "bytes=5-9"})
+	if w.Code != http.StatusPartialContent {
+		t.Fatalf("range GET: expected 206, got %d", w.Code)
+	}
+	if got := w.Header().Get("Content-Range"); got != "bytes 5-9/20" {
+		t.Errorf("Content-Range = %q, want bytes 5-9/20", got)
+	}
+	if w.Body.String() != "56789" || w.Header().Get("Content-Length") != "5" {
+		t.Errorf("range body = %q (length %s), want 56789", w.Body.String(), w.Header().Get("Content-Length"))
+	}
+	if got := w.Header().Get("Content-Type"); got != "audio/ogg" {
+		t.Errorf("Content-Type = %q, want audio/ogg", got)
+	}
+
+	// Suffix range, as players use to read trailing metadata
+	w = downloadWithHeaders(handler, http.MethodGet, ref, map[string]string{"Range": "bytes=-4"})
+	if w.Code != http.StatusPartialContent || w.Body.String() != "ghij" {
+		t.Errorf("suffix range: got %d %q", w.Code, w.Body.String())
+	}
+
+	// Unsatisfiable range
+	w = downloadWithHeaders(handler, http.MethodGet, ref, map[string]string{"Range": "bytes=50-60"})
+	if w.Code != http.StatusRequestedRangeNotSatisfiable {
+		t.Fatalf("unsatisfiable range: expected 416, got %d", w.Code)
+	}
+	if got := w.Header().Get("Content-Range"); got != "bytes */20" {
+		t.Errorf("416 Content-Range = %q, want bytes */20", got)
+	}
+
+	// If-Range: a matching ETag resumes, a stale one gets the whole file
+	w = downloadWithHeaders(handler, http.MethodGet, ref, map[string]string{"Range": "bytes=10-", "If-Range": etag})
+	if w.Code != http.StatusPartialContent || w.Body.String() != "abcdefghij" {
+		t.Errorf("If-Range match: got %d %q", w.Code, w.Body.String())
+	}
+	w = downloadWithHeaders(handler, http.MethodGet, ref, map[string]string{"Range": "bytes=10-", "If-Range": `"bafkreistale"`})
+	if w.Code != http.StatusOK || !bytes.Equal(w.Body.Bytes(), data) {
+		t.Errorf("If-Range mismatch: expected the full file, got %d %q", w.Code, w.Body.Bytes())
+	}
+
+	// HEAD reports the size without a body
+	w = downloadWithHeaders(handler, http.MethodHead, ref, nil)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Length") != "20" || w.Body.Len() != 0 {
+		t.Errorf("HEAD: got %d length %s body %d bytes", w.Code, w.Header().Get("Content-Length"), w.Body.Len())
+	}
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/matou-dao/backend/internal/anysync"
+	"github.com/matou-dao/backend/pkg/client"
+)
+
+func TestGoClient_Chat(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+	srv := httptest.NewServer(env.mux)
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := client.New(srv.URL + "/")
+	c.SetOrigin("http://localhost:9000")
+
+	created, err := c.CreateChannel(ctx, client.CreateChannelRequest{Name: "general", Description: "General chat"})
+	if err != nil {
+		t.Fatalf("CreateChannel: %v", err)
+	}
+	if !created.Success || created.ChannelID == "" {
+		t.Fatalf("unexpected create response %+v", created)
+	}
+
+	page, err := c.ListChannels(ctx, client.ListChannelsOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("ListChannels: %v", err)
+	}
+	if page.Count != 1 || page.Total != 1 || page.Limit != 10 || page.Channels[0].ID != created.ChannelID || page.Channels[0].Name != "general" {
+		t.Errorf("unexpected channels page %+v", page)
+	}
+
+	sent, err := c.SendMessage(ctx, created.ChannelID, client.SendMessageRequest{Content: "kia ora"})
+	if err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	if sent.MessageID == "" || sent.SentAt == "" {
+		t.Errorf("unexpected send response %+v", sent)
+	}
+	messages, err := c.ListMessages(ctx, created.ChannelID, client.ListMessagesOptions{Limit: 5})
+	if err != nil {
+		t.Fatalf("ListMessages: %v", err)
+	}
+	if messages.Count != 1 || messages.Messages[0].ID != sent.MessageID || messages.Messages[0].Content != "kia ora" {
+		t.Errorf("unexpected messages page %+v", messages)
+	}
+
+	// Handler errors come back as *client.Error with the server's message
+	_, err = c.CreateChannel(ctx, client.CreateChannelRequest{})
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || apiErr.Message == "" {
+		t.Errorf("expected a 400 *client.Error, got %v", err)
+	}
+}
+
+func TestGoClient_ListNotices(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+	srv := httptest.NewServer(env.mux)
+	defer srv.Close()
+
+	id := createTestNotice(t, env, map[string]interface{}{
+		"type": "update", "title": "Working bee", "summary": "Saturday", "state": "published",
+	})
+	createTestNotice(t, env, map[string]interface{}{
+		"type": "event", "title": "Hui", "summary": "Monthly", "state": "published",
+		"eventStart": "2099-01-01T10:00:00Z",
+	})
+
+	c := client.New(srv.URL)
+	c.SetUserAID(noticeTestAdminAID)
+	page, err := c.ListNotices(context.Background(), client.ListNoticesOptions{Type: "update", Limit: 10})
+	if err != nil {
+		t.Fatalf("ListNotices: %v", err)
+	}
+	if page.Count != 1 || page.Total != 1 || page.Notices[0].ID != id || page.Notices[0].Title != "Working bee" || page.Notices[0].State != "published" {
+		t.Errorf("unexpected notices page %+v", page)
+	}
+
+	if _, err := c.ListNotices(context.Background(), client.ListNoticesOptions{Cursor: "not-a-cursor"}); err == nil {
+		t.Error("expected an error for an invalid cursor")
+	}
+}
+
+// client.Notice mirrors the stored notice type; a field added to one must be
+// added to the other.
+func TestGoClient_NoticeMatchesPayload(t *testing.T) {
+	tags := func(v any) map[string]string {
+		typ := reflect.TypeOf(v)
+		m := make(map[string]string, typ.NumField())
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			m[f.Name] = f.Tag.Get("json")
+		}
+		return m
+	}
+	if got, want := tags(client.Notice{}), tags(anysync.NoticePayload{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("client.Notice fields %v, anysync.NoticePayload fields %v", got, want)
+	}
+	if got, want := tags(client.NoticeRecurrence{}), tags(anysync.NoticeRecurrence{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("client.NoticeRecurrence fields %v, anysync.NoticeRecurrence fields %v", got, want)
+	}
+}
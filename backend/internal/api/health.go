@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -10,12 +11,26 @@ import (
 	"github.com/matou-dao/backend/internal/trust"
 )
 
+// Health statuses reported for the service and for each subsystem.
+const (
+	HealthStatusHealthy  = "healthy"
+	HealthStatusDegraded = "degraded"
+)
+
+// healthProbeKey is the preference the readiness check writes and reads back
+// to prove the local store is writable.
+const healthProbeKey = "health:probe"
+
 // HealthHandler handles health check related HTTP requests
 type HealthHandler struct {
-	store       *anystore.LocalStore
-	spaceStore  anysync.SpaceStore
-	getOrgAID   func() string
-	getAdminAID func() string
+	store        *anystore.LocalStore
+	spaceStore   anysync.SpaceStore
+	spaceManager *anysync.SpaceManager
+	watchdog     *CoordinatorWatchdog
+	outbox       *WriteOutbox
+	events       *EventBroker
+	getOrgAID    func() string
+	getAdminAID  func() string
 }
 
 // NewHealthHandler creates a new health handler.
@@ -35,13 +50,45 @@ func NewHealthHandler(
 	}
 }
 
+// SetSpaceManager enables the any-sync connectivity and space key checks.
+func (h *HealthHandler) SetSpaceManager(sm *anysync.SpaceManager) {
+	h.spaceManager = sm
+}
+
+// SetCoordinatorWatchdog reports the node's read-only mode and the
+// coordinator's reachability in /health.
+func (h *HealthHandler) SetCoordinatorWatchdog(wd *CoordinatorWatchdog) {
+	h.watchdog = wd
+}
+
+// SetWriteOutbox reports the depth of the write outbox in /health.
+func (h *HealthHandler) SetWriteOutbox(o *WriteOutbox) {
+	h.outbox = o
+}
+
+// SetEventBroker reports aggregate SSE backpressure in /health; the
+// per-subscriber list is at /api/v1/debug/events.
+func (h *HealthHandler) SetEventBroker(b *EventBroker) {
+	h.events = b
+}
+
 // HealthResponse represents the health check response
 type HealthResponse struct {
-	Status       string       `json:"status"`
-	Organization string       `json:"organization"`
-	Admin        string       `json:"admin"`
-	Sync         *SyncStatus  `json:"sync,omitempty"`
-	Trust        *TrustStatus `json:"trust,omitempty"`
+	Status       string                     `json:"status"`
+	Organization string                     `json:"organization"`
+	Admin        string                     `json:"admin"`
+	Mode         string                     `json:"mode,omitempty"`
+	Coordinator  *CoordinatorStatus         `json:"coordinator,omitempty"`
+	Checks       map[string]SubsystemHealth `json:"checks"`
+	Sync         *SyncStatus                `json:"sync,omitempty"`
+	Trust        *TrustStatus               `json:"trust,omitempty"`
+	Events       *EventBrokerStats          `json:"events,omitempty"`
+}
+
+// SubsystemHealth is the result of one readiness check
+type SubsystemHealth struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
 }
 
 // SyncStatus represents sync-related statistics
@@ -49,6 +96,8 @@ type SyncStatus struct {
 	CredentialsCached int `json:"credentialsCached"`
 	SpacesCreated     int `json:"spacesCreated"`
 	KELEventsStored   int `json:"kelEventsStored"`
+	// Outbox counts object-tree writes queued for retry (see WriteOutbox)
+	Outbox *OutboxDepth `json:"outbox,omitempty"`
 }
 
 // TrustStatus represents trust graph statistics
@@ -58,7 +107,10 @@ type TrustStatus struct {
 	AverageScore float64 `json:"averageScore"`
 }
 
-// HandleHealth handles GET /health
+// HandleHealth handles GET /health — readiness. It checks the coordinator is
+// reachable, the local store is writable and the configured spaces have keys,
+// and returns 503 with the failing checks if any subsystem is degraded or
+// the node is read-only because the coordinator is unreachable.
 func (h *HealthHandler) HandleHealth(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{
@@ -72,9 +124,23 @@ func (h *HealthHandler) HandleHealth(w http.ResponseWriter, r *http.Request) {
 
 	// Basic health response (read live values at request time)
 	response := HealthResponse{
-		Status:       "healthy",
+		Status:       HealthStatusHealthy,
 		Organization: h.getOrgAID(),
 		Admin:        h.getAdminAID(),
+		Checks:       h.runChecks(ctx),
+	}
+	for _, check := range response.Checks {
+		if check.Status != HealthStatusHealthy {
+			response.Status = HealthStatusDegraded
+		}
+	}
+	if h.watchdog != nil {
+		coordinator := h.watchdog.Status()
+		response.Mode = coordinator.Mode
+		response.Coordinator = &coordinator
+		if coordinator.Mode == ModeReadOnly {
+			response.Status = HealthStatusDegraded
+		}
 	}
 
 	// Get sync status (best-effort, don't block health check)
@@ -89,7 +155,98 @@ func (h *HealthHandler) HandleHealth(w http.ResponseWriter, r *http.Request) {
 		response.Trust = trustStatus
 	}
 
-	writeJSON(w, http.StatusOK, response)
+	if h.events != nil {
+		events := h.events.Stats()
+		response.Events = &events
+	}
+
+	status := http.StatusOK
+	if response.Status != HealthStatusHealthy {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, response)
+}
+
+// HandleLiveness handles GET /health/live — reports the process is serving
+// requests without touching any subsystem.
+func (h *HealthHandler) HandleLiveness(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{
+			"error": "Method not allowed",
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "alive"})
+}
+
+// runChecks runs the readiness checks, keyed by subsystem. The any-sync
+// checks are skipped until a space manager is set.
+func (h *HealthHandler) runChecks(ctx context.Context) map[string]SubsystemHealth {
+	checks := map[string]SubsystemHealth{
+		"store": subsystemHealth(h.checkStore(ctx)),
+	}
+	if h.spaceManager != nil {
+		checks["anysync"] = subsystemHealth(h.checkAnySync())
+		checks["spaceKeys"] = subsystemHealth(h.checkSpaceKeys())
+	}
+	return checks
+}
+
+func subsystemHealth(err error) SubsystemHealth {
+	if err != nil {
+		return SubsystemHealth{Status: HealthStatusDegraded, Error: err.Error()}
+	}
+	return SubsystemHealth{Status: HealthStatusHealthy}
+}
+
+// checkStore writes a probe preference and reads it back.
+func (h *HealthHandler) checkStore(ctx context.Context) error {
+	if h.store == nil {
+		return fmt.Errorf("store not configured")
+	}
+	probe := time.Now().UTC().Format(time.RFC3339Nano)
+	if err := h.store.SetPreference(ctx, healthProbeKey, probe); err != nil {
+		return fmt.Errorf("write failed: %w", err)
+	}
+	value, err := h.store.GetPreference(ctx, healthProbeKey)
+	if err != nil {
+		return fmt.Errorf("read failed: %w", err)
+	}
+	if value != probe {
+		return fmt.Errorf("read back %v, wrote %s", value, probe)
+	}
+	return nil
+}
+
+// checkAnySync pings the coordinator.
+func (h *HealthHandler) checkAnySync() error {
+	client := h.spaceManager.GetClient()
+	if client == nil {
+		return fmt.Errorf("any-sync client not available")
+	}
+	return client.Ping()
+}
+
+// checkSpaceKeys verifies the configured community spaces have key sets on
+// disk; without them the node can't sign writes to those spaces.
+func (h *HealthHandler) checkSpaceKeys() error {
+	client := h.spaceManager.GetClient()
+	if client == nil {
+		return fmt.Errorf("any-sync client not available")
+	}
+	spaces := []struct{ name, id string }{
+		{"community", h.spaceManager.GetCommunitySpaceID()},
+		{"community readonly", h.spaceManager.GetCommunityReadOnlySpaceID()},
+	}
+	for _, space := range spaces {
+		if space.id == "" {
+			continue // not configured yet (before org setup)
+		}
+		if _, err := anysync.LoadSpaceKeySet(client.GetDataDir(), space.id); err != nil {
+			return fmt.Errorf("%s space %s: %w", space.name, space.id, err)
+		}
+	}
+	return nil
 }
 
 // getSyncStatus retrieves sync statistics from the store
@@ -97,7 +254,7 @@ func (h *HealthHandler) getSyncStatus(ctx context.Context) *SyncStatus {
 	status := &SyncStatus{}
 
 	// Count credentials
-	credCount, err := h.store.CountCredentials(ctx)
+	credCount, err := h.store.CountCredentials(ctx, anystore.CredentialQuery{})
 	if err == nil {
 		status.CredentialsCached = credCount
 	}
@@ -116,6 +273,12 @@ func (h *HealthHandler) getSyncStatus(ctx context.Context) *SyncStatus {
 		status.KELEventsStored = kelCount
 	}
 
+	if h.outbox != nil {
+		if depth, err := h.outbox.Depth(ctx); err == nil {
+			status.Outbox = &depth
+		}
+	}
+
 	return status
 }
 
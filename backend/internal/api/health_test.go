@@ -3,9 +3,11 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -248,6 +250,136 @@ func TestHandleHealth_MethodNotAllowed(t *testing.T) {
 	}
 }
 
+// pingFailClient is a chat test client whose coordinator is unreachable.
+type pingFailClient struct {
+	mockAnySyncClientForChat
+}
+
+func (m *pingFailClient) Ping() error { return errors.New("coordinator unreachable") }
+
+func decodeHealth(t *testing.T, w *httptest.ResponseRecorder) HealthResponse {
+	t.Helper()
+	var resp HealthResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return resp
+}
+
+func TestHandleHealth_AllSubsystemsHealthy(t *testing.T) {
+	handler, _, _, cleanup := setupHealthTestHandler(t)
+	defer cleanup()
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+	handler.SetSpaceManager(env.spaceManager)
+
+	w := httptest.NewRecorder()
+	handler.HandleHealth(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	resp := decodeHealth(t, w)
+	if resp.Status != HealthStatusHealthy {
+		t.Errorf("expected status healthy, got %s", resp.Status)
+	}
+	for _, name := range []string{"store", "anysync", "spaceKeys"} {
+		if check, ok := resp.Checks[name]; !ok || check.Status != HealthStatusHealthy {
+			t.Errorf("expected %s healthy, got %+v", name, check)
+		}
+	}
+}
+
+func TestHandleHealth_DegradedSubsystem(t *testing.T) {
+	tests := []struct {
+		name      string
+		subsystem string
+		breakIt   func(h *HealthHandler, store *anystore.LocalStore, env *chatTestEnv)
+	}{
+		{
+			name:      "coordinator unreachable",
+			subsystem: "anysync",
+			breakIt: func(h *HealthHandler, _ *anystore.LocalStore, env *chatTestEnv) {
+				client := &pingFailClient{mockAnySyncClientForChat{dataDir: env.spaceManager.GetClient().GetDataDir()}}
+				h.SetSpaceManager(anysync.NewSpaceManager(client, &anysync.SpaceManagerConfig{
+					CommunitySpaceID:         env.spaceManager.GetCommunitySpaceID(),
+					CommunityReadOnlySpaceID: env.spaceManager.GetCommunityReadOnlySpaceID(),
+				}))
+			},
+		},
+		{
+			name:      "missing space keys",
+			subsystem: "spaceKeys",
+			breakIt: func(_ *HealthHandler, _ *anystore.LocalStore, env *chatTestEnv) {
+				keyPath := filepath.Join(env.spaceManager.GetClient().GetDataDir(), "keys", env.spaceManager.GetCommunitySpaceID()+".keys")
+				if err := os.Remove(keyPath); err != nil {
+					t.Fatalf("removing key file: %v", err)
+				}
+			},
+		},
+		{
+			name:      "store closed",
+			subsystem: "store",
+			breakIt: func(_ *HealthHandler, store *anystore.LocalStore, _ *chatTestEnv) {
+				store.Close()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, store, _, cleanup := setupHealthTestHandler(t)
+			defer cleanup()
+			env := setupChatTestEnv(t)
+			defer env.cleanup()
+			handler.SetSpaceManager(env.spaceManager)
+			tt.breakIt(handler, store, env)
+
+			w := httptest.NewRecorder()
+			handler.HandleHealth(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+			if w.Code != http.StatusServiceUnavailable {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusServiceUnavailable, w.Code, w.Body.String())
+			}
+
+			resp := decodeHealth(t, w)
+			if resp.Status != HealthStatusDegraded {
+				t.Errorf("expected status degraded, got %s", resp.Status)
+			}
+			for name, check := range resp.Checks {
+				wantStatus := HealthStatusHealthy
+				if name == tt.subsystem {
+					wantStatus = HealthStatusDegraded
+				}
+				if check.Status != wantStatus {
+					t.Errorf("expected %s %s, got %+v", name, wantStatus, check)
+				}
+			}
+			if resp.Checks[tt.subsystem].Error == "" {
+				t.Errorf("expected an error for %s", tt.subsystem)
+			}
+		})
+	}
+}
+
+func TestHandleLiveness(t *testing.T) {
+	handler, store, _, cleanup := setupHealthTestHandler(t)
+	defer cleanup()
+	// Liveness doesn't depend on any subsystem
+	store.Close()
+
+	w := httptest.NewRecorder()
+	handler.HandleLiveness(w, httptest.NewRequest(http.MethodGet, "/health/live", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	handler.HandleLiveness(w, httptest.NewRequest(http.MethodPost, "/health/live", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status %d, got %d", http.StatusMethodNotAllowed, w.Code)
+	}
+}
+
 func TestHandleHealth_ContentType(t *testing.T) {
 	handler, _, _, cleanup := setupHealthTestHandler(t)
 	defer cleanup()
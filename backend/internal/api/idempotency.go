@@ -0,0 +1,222 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/matou-dao/backend/internal/anystore"
+	"github.com/matou-dao/backend/internal/identity"
+)
+
+// IdempotencyKeyHeader is the request header clients set to make a create
+// request safe to retry.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// DefaultIdempotencyTTL is how long a key's result is kept for replay.
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// maxIdempotencyKeyLength caps the header value (UUIDs are 36 characters).
+const maxIdempotencyKeyLength = 255
+
+// idempotencyPurgeInterval is the minimum time between purges of expired records.
+const idempotencyPurgeInterval = time.Hour
+
+// IdempotencyStore persists idempotency records. *anystore.LocalStore
+// implements it.
+type IdempotencyStore interface {
+	GetIdempotencyRecord(ctx context.Context, id string) (*anystore.IdempotencyRecord, error)
+	SaveIdempotencyRecord(ctx context.Context, rec *anystore.IdempotencyRecord) error
+	PurgeExpiredIdempotencyRecords(ctx context.Context, now time.Time) (int, error)
+}
+
+// IdempotencyGuard makes create endpoints safe to retry. A request with an
+// Idempotency-Key header runs once; retries with the same key and body get
+// the original response back instead of creating a second object. Requests
+// without the header are not affected.
+//
+// Only successful (2xx) responses are kept, so a retry after an error runs
+// the request again. Keys are scoped to the caller (see callerAID), method
+// and path.
+type IdempotencyGuard struct {
+	store        IdempotencyStore
+	ttl          time.Duration
+	userIdentity *identity.UserIdentity
+
+	mu        sync.Mutex
+	inFlight  map[string]bool
+	lastPurge time.Time
+}
+
+// NewIdempotencyGuard creates a guard that keeps results in store for ttl.
+// userIdentity is the caller for requests that didn't pass through
+// AuthMiddleware; it may be nil.
+func NewIdempotencyGuard(store IdempotencyStore, ttl time.Duration, userIdentity *identity.UserIdentity) *IdempotencyGuard {
+	return &IdempotencyGuard{
+		store:        store,
+		ttl:          ttl,
+		userIdentity: userIdentity,
+		inFlight:     make(map[string]bool),
+		lastPurge:    time.Now(),
+	}
+}
+
+// Wrap returns next with idempotency key handling. A nil guard returns next
+// unchanged, so handlers can opt in before a guard is configured.
+func (g *IdempotencyGuard) Wrap(next http.HandlerFunc) http.HandlerFunc {
+	if g == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			next(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Idempotency-Key is too long"})
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBodySize))
+		if err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				writeJSON(w, http.StatusRequestEntityTooLarge, map[string]string{"error": "request body too large"})
+				return
+			}
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "failed to read request body"})
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := r.Context()
+		recordID := g.recordID(r, key)
+		bodyHash := sha256.Sum256(body)
+		requestHash := hex.EncodeToString(bodyHash[:])
+
+		// Reserve the key before looking it up, so a concurrent retry can't
+		// miss the record and run the request a second time
+		if !g.begin(recordID) {
+			writeJSON(w, http.StatusConflict, map[string]string{
+				"error": "a request with this Idempotency-Key is still in progress",
+			})
+			return
+		}
+		defer g.end(recordID)
+
+		rec, err := g.store.GetIdempotencyRecord(ctx, recordID)
+		if err == nil && time.Now().Before(rec.ExpiresAt) {
+			if rec.RequestHash != requestHash {
+				writeJSON(w, http.StatusUnprocessableEntity, map[string]string{
+					"error": "Idempotency-Key was already used for a different request",
+				})
+				return
+			}
+			replayIdempotentResponse(w, rec)
+			return
+		}
+
+		rw := &recordingResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		next(rw, r)
+
+		if rw.status < 200 || rw.status >= 300 {
+			return
+		}
+		now := time.Now()
+		rec = &anystore.IdempotencyRecord{
+			ID:          recordID,
+			RequestHash: requestHash,
+			StatusCode:  rw.status,
+			ContentType: rw.Header().Get("Content-Type"),
+			Body:        rw.body.String(),
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(g.ttl),
+		}
+		if err := g.store.SaveIdempotencyRecord(ctx, rec); err != nil {
+			log.Printf("[Idempotency] Warning: failed to save result for %s %s: %v", r.Method, r.URL.Path, err)
+		}
+		g.purgeExpired(ctx, now)
+	}
+}
+
+// begin marks a key as in flight, returning false if it already is.
+func (g *IdempotencyGuard) begin(recordID string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.inFlight[recordID] {
+		return false
+	}
+	g.inFlight[recordID] = true
+	return true
+}
+
+// end clears a key's in-flight mark.
+func (g *IdempotencyGuard) end(recordID string) {
+	g.mu.Lock()
+	delete(g.inFlight, recordID)
+	g.mu.Unlock()
+}
+
+// purgeExpired deletes expired records, at most once per idempotencyPurgeInterval.
+func (g *IdempotencyGuard) purgeExpired(ctx context.Context, now time.Time) {
+	g.mu.Lock()
+	due := now.Sub(g.lastPurge) >= idempotencyPurgeInterval
+	if due {
+		g.lastPurge = now
+	}
+	g.mu.Unlock()
+	if !due {
+		return
+	}
+	if n, err := g.store.PurgeExpiredIdempotencyRecords(ctx, now); err != nil {
+		log.Printf("[Idempotency] Warning: failed to purge expired keys: %v", err)
+	} else if n > 0 {
+		log.Printf("[Idempotency] Purged %d expired keys", n)
+	}
+}
+
+// recordID scopes a key to the caller, method and path.
+func (g *IdempotencyGuard) recordID(r *http.Request, key string) string {
+	sum := sha256.Sum256([]byte(callerAID(r.Context(), g.userIdentity) + "\n" + r.Method + "\n" + r.URL.Path + "\n" + key))
+	return hex.EncodeToString(sum[:])
+}
+
+// replayIdempotentResponse writes a stored response.
+func replayIdempotentResponse(w http.ResponseWriter, rec *anystore.IdempotencyRecord) {
+	if rec.ContentType != "" {
+		w.Header().Set("Content-Type", rec.ContentType)
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(rec.StatusCode)
+	io.WriteString(w, rec.Body)
+}
+
+// recordingResponseWriter passes a response through while keeping a copy.
+type recordingResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rw *recordingResponseWriter) WriteHeader(status int) {
+	if !rw.wroteHeader {
+		rw.status = status
+		rw.wroteHeader = true
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *recordingResponseWriter) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
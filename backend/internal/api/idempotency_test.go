@@ -0,0 +1,235 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/matou-dao/backend/internal/anystore"
+)
+
+// newTestIdempotencyGuard returns a guard backed by a temporary anystore.
+func newTestIdempotencyGuard(t *testing.T) *IdempotencyGuard {
+	t.Helper()
+	store, err := anystore.NewLocalStore(anystore.DefaultConfig(t.TempDir()))
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return NewIdempotencyGuard(store, DefaultIdempotencyTTL, nil)
+}
+
+func postWithKey(mux *http.ServeMux, path, body, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyGuard_ReplaysResponse(t *testing.T) {
+	guard := newTestIdempotencyGuard(t)
+
+	var calls atomic.Int32
+	handler := guard.Wrap(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		writeJSON(w, http.StatusCreated, map[string]interface{}{"call": n})
+	})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/things", handler)
+
+	first := postWithKey(mux, "/things", `{"name":"a"}`, "key-1")
+	second := postWithKey(mux, "/things", `{"name":"a"}`, "key-1")
+	if calls.Load() != 1 {
+		t.Fatalf("expected handler to run once, ran %d times", calls.Load())
+	}
+	if second.Code != first.Code || second.Body.String() != first.Body.String() {
+		t.Errorf("expected replay %d %s, got %d %s", first.Code, first.Body.String(), second.Code, second.Body.String())
+	}
+	if second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("expected replayed response to be marked")
+	}
+
+	if w := postWithKey(mux, "/things", `{"name":"b"}`, "key-1"); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("reused key with a different body: expected 422, got %d", w.Code)
+	}
+
+	postWithKey(mux, "/things", `{"name":"a"}`, "key-2")
+	postWithKey(mux, "/things", `{"name":"a"}`, "")
+	postWithKey(mux, "/things", `{"name":"a"}`, "")
+	if calls.Load() != 4 {
+		t.Errorf("expected new key and keyless requests to run, got %d calls", calls.Load())
+	}
+}
+
+func TestIdempotencyGuard_ErrorsNotStored(t *testing.T) {
+	guard := newTestIdempotencyGuard(t)
+
+	var calls atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/things", guard.Wrap(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "try again"})
+			return
+		}
+		writeJSON(w, http.StatusCreated, map[string]string{"ok": "yes"})
+	}))
+
+	postWithKey(mux, "/things", `{}`, "key-1")
+	if w := postWithKey(mux, "/things", `{}`, "key-1"); w.Code != http.StatusCreated {
+		t.Errorf("expected retry after an error to run again, got %d", w.Code)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected 2 calls, got %d", calls.Load())
+	}
+}
+
+func TestChat_SendMessage_IdempotencyKey(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+	env.chatHandler.SetIdempotencyGuard(newTestIdempotencyGuard(t))
+
+	first := postWithKey(env.mux, "/api/v1/chat/channels", `{"name":"retries"}`, "create-channel-1")
+	second := postWithKey(env.mux, "/api/v1/chat/channels", `{"name":"retries"}`, "create-channel-1")
+	if first.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Fatalf("expected identical channel responses, got %d %s and %d %s", first.Code, first.Body.String(), second.Code, second.Body.String())
+	}
+	var created map[string]interface{}
+	json.NewDecoder(first.Body).Decode(&created)
+	channelID, _ := created["channelId"].(string)
+
+	path := "/api/v1/chat/channels/" + channelID + "/messages"
+	first = postWithKey(env.mux, path, `{"content":"only once"}`, "send-1")
+	second = postWithKey(env.mux, path, `{"content":"only once"}`, "send-1")
+	if first.Code != http.StatusCreated || second.Code != first.Code || second.Body.String() != first.Body.String() {
+		t.Fatalf("expected identical message responses, got %d %s and %d %s", first.Code, first.Body.String(), second.Code, second.Body.String())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	w := httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	var resp struct {
+		Messages []MessageResponse `json:"messages"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if len(resp.Messages) != 1 {
+		t.Errorf("expected 1 message, got %d", len(resp.Messages))
+	}
+
+	var channels int
+	objects, _ := env.spaceManager.ObjectTreeManager().ReadObjectsByType(context.Background(), env.spaceManager.GetCommunitySpaceID(), "ChatChannel")
+	for _, obj := range objects {
+		var ch ChatChannelData
+		if json.Unmarshal(obj.Data, &ch) == nil && ch.Name == "retries" {
+			channels++
+		}
+	}
+	if channels != 1 {
+		t.Errorf("expected 1 channel named retries, got %d objects", channels)
+	}
+}
+
+func TestNotices_CreateNotice_IdempotencyKey(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+	env.handler.SetIdempotencyGuard(newTestIdempotencyGuard(t))
+
+	body := `{"type":"announcement","title":"Retried","summary":"Sent twice"}`
+	first := postWithKey(env.mux, "/api/v1/notices", body, "notice-1")
+	second := postWithKey(env.mux, "/api/v1/notices", body, "notice-1")
+	if first.Code != http.StatusOK || second.Code != first.Code || second.Body.String() != first.Body.String() {
+		t.Fatalf("expected identical responses, got %d %s and %d %s", first.Code, first.Body.String(), second.Code, second.Body.String())
+	}
+
+	notices, err := env.spaceManager.NoticeTreeManager().ReadNotices(context.Background(), env.spaceManager.GetCommunitySpaceID())
+	if err != nil {
+		t.Fatalf("reading notices: %v", err)
+	}
+	if len(notices) != 1 {
+		t.Errorf("expected 1 notice, got %d", len(notices))
+	}
+}
+
+func TestIdempotencyGuard_ConcurrentRetryRunsOnce(t *testing.T) {
+	guard := newTestIdempotencyGuard(t)
+
+	var calls atomic.Int32
+	started, release := make(chan struct{}), make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/things", guard.Wrap(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		close(started)
+		<-release
+		writeJSON(w, http.StatusCreated, map[string]string{"ok": "yes"})
+	}))
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- postWithKey(mux, "/things", `{}`, "key-1") }()
+	<-started
+	if w := postWithKey(mux, "/things", `{}`, "key-1"); w.Code != http.StatusConflict {
+		t.Errorf("retry while in flight: expected 409, got %d", w.Code)
+	}
+	close(release)
+	if w := <-done; w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", w.Code)
+	}
+
+	if w := postWithKey(mux, "/things", `{}`, "key-1"); w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("expected retry after completion to be replayed")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected handler to run once, ran %d times", calls.Load())
+	}
+}
+
+func TestIdempotencyGuard_BodyTooLarge(t *testing.T) {
+	guard := newTestIdempotencyGuard(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/things", guard.Wrap(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("handler should not run for an oversized body")
+	}))
+
+	body := `"` + strings.Repeat("a", maxRequestBodySize) + `"`
+	if w := postWithKey(mux, "/things", body, "key-1"); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413, got %d", w.Code)
+	}
+}
+
+func TestIdempotencyGuard_ScopedToCaller(t *testing.T) {
+	guard := newTestIdempotencyGuard(t)
+
+	var calls atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/things", guard.Wrap(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		writeJSON(w, http.StatusCreated, map[string]string{"ok": "yes"})
+	}))
+
+	// The X-User-AID header is only a claim; it doesn't change the caller
+	for _, aid := range []string{"EUSER001", "EUSER002"} {
+		req := httptest.NewRequest(http.MethodPost, "/things", bytes.NewBufferString(`{}`))
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		req.Header.Set("X-User-AID", aid)
+		mux.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected one run for the same caller, got %d", calls.Load())
+	}
+
+	// A different authenticated caller gets its own scope
+	req := httptest.NewRequest(http.MethodPost, "/things", bytes.NewBufferString(`{}`))
+	req.Header.Set(IdempotencyKeyHeader, "key-1")
+	req = req.WithContext(context.WithValue(req.Context(), ctxAuth, &authState{aid: "EUSER003"}))
+	mux.ServeHTTP(httptest.NewRecorder(), req)
+	if calls.Load() != 2 {
+		t.Errorf("expected another caller's key to run, got %d calls", calls.Load())
+	}
+}
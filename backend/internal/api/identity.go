@@ -2,10 +2,13 @@ package api
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/matou-dao/backend/internal/anysync"
@@ -19,6 +22,7 @@ type IdentityHandler struct {
 	sdkClient    *anysync.SDKClient
 	spaceManager *anysync.SpaceManager
 	spaceStore   anysync.SpaceStore
+	auth         *Authenticator
 }
 
 // NewIdentityHandler creates a new identity handler.
@@ -36,6 +40,11 @@ func NewIdentityHandler(
 	}
 }
 
+// SetAuthenticator enables issuing a session token when an identity is set.
+func (h *IdentityHandler) SetAuthenticator(auth *Authenticator) {
+	h.auth = auth
+}
+
 // SetIdentityRequest is the request body for POST /api/v1/identity/set.
 type SetIdentityRequest struct {
 	AID              string `json:"aid"`
@@ -46,14 +55,23 @@ type SetIdentityRequest struct {
 	AdminSpaceID     string `json:"adminSpaceId,omitempty"`
 	CredentialSAID   string `json:"credentialSaid,omitempty"`
 	Mode             string `json:"mode,omitempty"`
+	// Passphrase, if set, encrypts the mnemonic at rest
+	Passphrase string `json:"passphrase,omitempty"`
 }
 
+// minPassphraseLength is the shortest passphrase accepted for encrypting
+// the mnemonic.
+const minPassphraseLength = 8
+
 // SetIdentityResponse is the response for POST /api/v1/identity/set.
 type SetIdentityResponse struct {
 	Success        bool   `json:"success"`
 	PeerID         string `json:"peerId,omitempty"`
 	PrivateSpaceID string `json:"privateSpaceId,omitempty"`
-	Error          string `json:"error,omitempty"`
+	// SessionToken authenticates later requests as the new identity
+	SessionToken     string `json:"sessionToken,omitempty"`
+	SessionExpiresAt string `json:"sessionExpiresAt,omitempty"`
+	Error            string `json:"error,omitempty"`
 }
 
 // GetIdentityResponse is the response for GET /api/v1/identity.
@@ -66,6 +84,9 @@ type GetIdentityResponse struct {
 	CommunityReadOnlySpaceID string `json:"communityReadOnlySpaceId,omitempty"`
 	AdminSpaceID             string `json:"adminSpaceId,omitempty"`
 	PrivateSpaceID           string `json:"privateSpaceId,omitempty"`
+	BackupVerified           bool   `json:"backupVerified"`
+	Encrypted                bool   `json:"encrypted"`
+	Locked                   bool   `json:"locked"`
 }
 
 // HandleSetIdentity handles POST /api/v1/identity/set.
@@ -74,7 +95,7 @@ type GetIdentityResponse struct {
 //  2. Derives peer key from mnemonic and reinitializes the SDK client
 //  3. Updates org config (orgAID, communitySpaceID) if provided
 //  4. Auto-creates the user's private space
-//  5. Returns the new peer ID and private space ID
+//  5. Returns the new peer ID, private space ID and a session token
 func (h *IdentityHandler) HandleSetIdentity(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		writeJSON(w, http.StatusMethodNotAllowed, SetIdentityResponse{
@@ -106,8 +127,15 @@ func (h *IdentityHandler) HandleSetIdentity(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// 1. Persist identity to disk
-	if err := h.userIdentity.SetIdentity(req.AID, req.Mnemonic); err != nil {
+	if req.Passphrase != "" && len(req.Passphrase) < minPassphraseLength {
+		writeJSON(w, http.StatusBadRequest, SetIdentityResponse{
+			Error: fmt.Sprintf("passphrase must be at least %d characters", minPassphraseLength),
+		})
+		return
+	}
+
+	// 1. Persist identity to disk (mnemonic encrypted if a passphrase is given)
+	if err := h.userIdentity.SetIdentityWithPassphrase(req.AID, req.Mnemonic, req.Passphrase); err != nil {
 		writeJSON(w, http.StatusInternalServerError, SetIdentityResponse{
 			Error: fmt.Sprintf("failed to persist identity: %v", err),
 		})
@@ -115,26 +143,14 @@ func (h *IdentityHandler) HandleSetIdentity(w http.ResponseWriter, r *http.Reque
 	}
 
 	// 2. Derive peer key from mnemonic and reinitialize SDK client
-	if err := h.sdkClient.Reinitialize(req.Mnemonic); err != nil {
+	newPeerID, err := h.reinitializeSDK(req.Mnemonic)
+	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, SetIdentityResponse{
 			Error: fmt.Sprintf("failed to reinitialize SDK: %v", err),
 		})
 		return
 	}
 
-	// Refresh the FileManager's pool/nodeconf references — the old pool died
-	// when Reinitialize closed the previous app.
-	h.spaceManager.RefreshFileManager()
-
-	// Clear cached tree instances — old trees hold stale peer keys and ACL state
-	// from the previous SDK session.
-	h.spaceManager.TreeManager().ClearTreeCache()
-
-	newPeerID := h.sdkClient.GetPeerID()
-	if err := h.userIdentity.SetPeerID(newPeerID); err != nil {
-		log.Printf("Warning: failed to persist peer ID: %v\n", err)
-	}
-
 	log.Printf("[Identity] Set identity: aid=%s, orgAid=%s, communitySpace=%s, readOnlySpace=%s, adminSpace=%s",
 		req.AID[:min(16, len(req.AID))], req.OrgAID, req.CommunitySpaceID, req.ReadOnlySpaceID, req.AdminSpaceID)
 
@@ -332,11 +348,44 @@ func (h *IdentityHandler) HandleSetIdentity(w http.ResponseWriter, r *http.Reque
 		}
 	}
 
-	writeJSON(w, http.StatusOK, SetIdentityResponse{
+	resp := SetIdentityResponse{
 		Success:        true,
 		PeerID:         newPeerID,
 		PrivateSpaceID: privateSpaceID,
-	})
+	}
+	if h.auth != nil {
+		// The caller proved control of the AID by supplying its mnemonic
+		token, expiresAt, err := h.auth.IssueToken(req.AID, time.Now())
+		if err != nil {
+			log.Printf("[Identity] Warning: failed to issue session token: %v\n", err)
+		} else {
+			resp.SessionToken = token
+			resp.SessionExpiresAt = expiresAt.Format(time.RFC3339)
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// reinitializeSDK derives the peer key from mnemonic, restarts the SDK client
+// with it and persists the new peer ID for the current identity.
+func (h *IdentityHandler) reinitializeSDK(mnemonic string) (string, error) {
+	if err := h.sdkClient.Reinitialize(mnemonic); err != nil {
+		return "", err
+	}
+
+	// Refresh the FileManager's pool/nodeconf references — the old pool died
+	// when Reinitialize closed the previous app.
+	h.spaceManager.RefreshFileManager()
+
+	// Clear cached tree instances — old trees hold stale peer keys and ACL state
+	// from the previous SDK session.
+	h.spaceManager.TreeManager().ClearTreeCache()
+
+	peerID := h.sdkClient.GetPeerID()
+	if err := h.userIdentity.SetPeerID(peerID); err != nil {
+		log.Printf("Warning: failed to persist peer ID: %v\n", err)
+	}
+	return peerID, nil
 }
 
 // seedPrivateSpace writes the PrivateProfile type definition and an initial
@@ -418,6 +467,9 @@ func (h *IdentityHandler) HandleGetIdentity(w http.ResponseWriter, r *http.Reque
 		CommunityReadOnlySpaceID: h.userIdentity.GetCommunityReadOnlySpaceID(),
 		AdminSpaceID:             h.userIdentity.GetAdminSpaceID(),
 		PrivateSpaceID:           h.userIdentity.GetPrivateSpaceID(),
+		BackupVerified:           h.userIdentity.IsBackupVerified(),
+		Encrypted:                h.userIdentity.IsEncrypted(),
+		Locked:                   h.userIdentity.IsLocked(),
 	})
 }
 
@@ -442,6 +494,368 @@ func (h *IdentityHandler) HandleDeleteIdentity(w http.ResponseWriter, r *http.Re
 	})
 }
 
+// SwitchIdentityRequest is the request body for POST /api/v1/identity/switch.
+type SwitchIdentityRequest struct {
+	AID string `json:"aid"`
+}
+
+// SwitchIdentityResponse is the response for POST /api/v1/identity/switch.
+type SwitchIdentityResponse struct {
+	Success        bool   `json:"success"`
+	AID            string `json:"aid,omitempty"`
+	PeerID         string `json:"peerId,omitempty"`
+	PrivateSpaceID string `json:"privateSpaceId,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// ListIdentitiesResponse is the response for GET /api/v1/identity/list.
+type ListIdentitiesResponse struct {
+	Current    string          `json:"current,omitempty"`
+	Identities []identity.Info `json:"identities"`
+}
+
+// HandleSwitchIdentity handles POST /api/v1/identity/switch.
+// Makes a stored identity current, re-derives the peer key from its mnemonic
+// and reinitializes the SDK client, then applies its runtime config to the
+// SpaceManager.
+func (h *IdentityHandler) HandleSwitchIdentity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, SwitchIdentityResponse{
+			Error: "Method not allowed",
+		})
+		return
+	}
+
+	var req SwitchIdentityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, SwitchIdentityResponse{
+			Error: fmt.Sprintf("invalid request: %v", err),
+		})
+		return
+	}
+	if req.AID == "" {
+		writeJSON(w, http.StatusBadRequest, SwitchIdentityResponse{
+			Error: "aid is required",
+		})
+		return
+	}
+
+	previous := h.userIdentity.GetAID()
+	if err := h.userIdentity.Switch(req.AID); err != nil {
+		writeJSON(w, http.StatusNotFound, SwitchIdentityResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	if h.userIdentity.IsLocked() {
+		if previous != "" {
+			h.userIdentity.Switch(previous)
+		}
+		writeJSON(w, http.StatusLocked, SwitchIdentityResponse{
+			Error: errIdentityLocked,
+		})
+		return
+	}
+
+	peerID, err := h.reinitializeSDK(h.userIdentity.GetMnemonic())
+	if err != nil {
+		// Keep the previous identity selected so it matches the running SDK
+		if previous != "" {
+			h.userIdentity.Switch(previous)
+		}
+		writeJSON(w, http.StatusInternalServerError, SwitchIdentityResponse{
+			Error: fmt.Sprintf("failed to reinitialize SDK: %v", err),
+		})
+		return
+	}
+
+	// Apply the selected identity's runtime config
+	if id := h.userIdentity.GetCommunitySpaceID(); id != "" {
+		h.spaceManager.SetCommunitySpaceID(id)
+	}
+	if aid := h.userIdentity.GetOrgAID(); aid != "" {
+		h.spaceManager.SetOrgAID(aid)
+	}
+	if id := h.userIdentity.GetCommunityReadOnlySpaceID(); id != "" {
+		h.spaceManager.SetCommunityReadOnlySpaceID(id)
+	}
+	if id := h.userIdentity.GetAdminSpaceID(); id != "" {
+		h.spaceManager.SetAdminSpaceID(id)
+	}
+
+	log.Printf("[Identity] Switched identity: aid=%s", req.AID[:min(16, len(req.AID))])
+
+	writeJSON(w, http.StatusOK, SwitchIdentityResponse{
+		Success:        true,
+		AID:            req.AID,
+		PeerID:         peerID,
+		PrivateSpaceID: h.userIdentity.GetPrivateSpaceID(),
+	})
+}
+
+// HandleListIdentities handles GET /api/v1/identity/list.
+// Returns the stored identities (without mnemonics) and the current one.
+func (h *IdentityHandler) HandleListIdentities(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{
+			"error": "Method not allowed",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ListIdentitiesResponse{
+		Current:    h.userIdentity.GetAID(),
+		Identities: h.userIdentity.List(),
+	})
+}
+
+// minBackupWords is the number of positioned words a partial mnemonic
+// backup check must include.
+const minBackupWords = 3
+
+// VerifyMnemonicRequest is the request body for POST /api/v1/identity/verify-mnemonic.
+// Either the full mnemonic or a subset of its words by position is given.
+type VerifyMnemonicRequest struct {
+	Mnemonic string           `json:"mnemonic,omitempty"`
+	Words    []PositionedWord `json:"words,omitempty"`
+}
+
+// PositionedWord is a mnemonic word at a 1-based position.
+type PositionedWord struct {
+	Position int    `json:"position"`
+	Word     string `json:"word"`
+}
+
+// VerifyMnemonicResponse is the response for POST /api/v1/identity/verify-mnemonic.
+type VerifyMnemonicResponse struct {
+	Success  bool   `json:"success"`
+	Verified bool   `json:"verified"`
+	Error    string `json:"error,omitempty"`
+}
+
+// HandleVerifyMnemonic handles POST /api/v1/identity/verify-mnemonic.
+// Checks a candidate mnemonic (or positioned words from it) against the
+// current identity's stored mnemonic and marks the backup verified on a
+// match. The phrase is never logged or echoed back.
+func (h *IdentityHandler) HandleVerifyMnemonic(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, VerifyMnemonicResponse{
+			Error: "Method not allowed",
+		})
+		return
+	}
+
+	var req VerifyMnemonicRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		// Don't include the decode error, it may quote the request body
+		writeJSON(w, http.StatusBadRequest, VerifyMnemonicResponse{
+			Error: "invalid request body",
+		})
+		return
+	}
+
+	if !h.userIdentity.IsConfigured() {
+		writeJSON(w, http.StatusConflict, VerifyMnemonicResponse{
+			Error: "identity not configured — call POST /api/v1/identity/set first",
+		})
+		return
+	}
+	if h.userIdentity.IsLocked() {
+		writeJSON(w, http.StatusLocked, VerifyMnemonicResponse{
+			Error: errIdentityLocked,
+		})
+		return
+	}
+	stored := h.userIdentity.GetMnemonic()
+
+	var matched bool
+	switch {
+	case req.Mnemonic != "" && len(req.Words) == 0:
+		candidate := strings.Join(strings.Fields(req.Mnemonic), " ")
+		if err := anysync.ValidateMnemonic(candidate); err != nil {
+			writeJSON(w, http.StatusBadRequest, VerifyMnemonicResponse{
+				Error: "invalid mnemonic",
+			})
+			return
+		}
+		var err error
+		matched, err = mnemonicKeysEqual(candidate, stored)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, VerifyMnemonicResponse{
+				Error: "failed to derive keys",
+			})
+			return
+		}
+	case req.Mnemonic == "" && len(req.Words) > 0:
+		var err error
+		matched, err = mnemonicWordsMatch(req.Words, stored)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, VerifyMnemonicResponse{
+				Error: err.Error(),
+			})
+			return
+		}
+	default:
+		writeJSON(w, http.StatusBadRequest, VerifyMnemonicResponse{
+			Error: "either mnemonic or words is required",
+		})
+		return
+	}
+
+	aid := h.userIdentity.GetAID()
+	if matched {
+		if err := h.userIdentity.SetBackupVerified(); err != nil {
+			log.Printf("Warning: failed to persist backup verification: %v\n", err)
+		}
+		log.Printf("[Identity] Mnemonic backup verified: aid=%s", aid[:min(16, len(aid))])
+	} else {
+		log.Printf("[Identity] Mnemonic backup verification failed: aid=%s", aid[:min(16, len(aid))])
+	}
+
+	writeJSON(w, http.StatusOK, VerifyMnemonicResponse{
+		Success:  true,
+		Verified: matched,
+	})
+}
+
+// mnemonicKeysEqual reports whether two mnemonics derive the same peer key,
+// comparing the keys in constant time.
+func mnemonicKeysEqual(candidate, stored string) (bool, error) {
+	candidateKey, err := anysync.DeriveKeyFromMnemonic(candidate, 0)
+	if err != nil {
+		return false, err
+	}
+	storedKey, err := anysync.DeriveKeyFromMnemonic(stored, 0)
+	if err != nil {
+		return false, err
+	}
+	a, err := candidateKey.Marshall()
+	if err != nil {
+		return false, err
+	}
+	b, err := storedKey.Marshall()
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(a, b) == 1, nil
+}
+
+// mnemonicWordsMatch reports whether every positioned word matches the
+// stored mnemonic, comparing all words in constant time. Returns an error if
+// the words are too few, repeat a position, or fall outside the mnemonic.
+func mnemonicWordsMatch(words []PositionedWord, stored string) (bool, error) {
+	storedWords := strings.Fields(stored)
+	if len(words) < minBackupWords {
+		return false, fmt.Errorf("at least %d words are required", minBackupWords)
+	}
+
+	seen := make(map[int]bool, len(words))
+	match := 1
+	for _, w := range words {
+		if w.Position < 1 || w.Position > len(storedWords) {
+			return false, fmt.Errorf("word position %d out of range 1-%d", w.Position, len(storedWords))
+		}
+		if seen[w.Position] {
+			return false, fmt.Errorf("word position %d repeated", w.Position)
+		}
+		seen[w.Position] = true
+		candidate := strings.ToLower(strings.TrimSpace(w.Word))
+		match &= subtle.ConstantTimeCompare([]byte(candidate), []byte(storedWords[w.Position-1]))
+	}
+	return match == 1, nil
+}
+
+// errIdentityLocked is returned with 423 Locked by endpoints that need the
+// mnemonic while it is passphrase-protected and not unlocked.
+const errIdentityLocked = "identity is locked — call POST /api/v1/identity/unlock first"
+
+// UnlockIdentityRequest is the request body for POST /api/v1/identity/unlock.
+type UnlockIdentityRequest struct {
+	Passphrase string `json:"passphrase"`
+}
+
+// HandleUnlockIdentity handles POST /api/v1/identity/unlock.
+// Decrypts the current identity's passphrase-protected mnemonic into memory
+// and reinitializes the SDK client with the peer key derived from it.
+func (h *IdentityHandler) HandleUnlockIdentity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{
+			"error": "Method not allowed",
+		})
+		return
+	}
+
+	var req UnlockIdentityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "invalid request body",
+		})
+		return
+	}
+	if req.Passphrase == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "passphrase is required",
+		})
+		return
+	}
+
+	if !h.userIdentity.IsConfigured() {
+		writeJSON(w, http.StatusConflict, map[string]string{
+			"error": "identity not configured — call POST /api/v1/identity/set first",
+		})
+		return
+	}
+	if !h.userIdentity.IsEncrypted() {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "identity is not passphrase-protected",
+		})
+		return
+	}
+
+	if err := h.userIdentity.Unlock(req.Passphrase); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, identity.ErrWrongPassphrase) {
+			status = http.StatusUnauthorized
+		}
+		writeJSON(w, status, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// The SDK started without the mnemonic; restart it with the derived key
+	resp := map[string]string{"status": "identity unlocked"}
+	if h.sdkClient != nil {
+		peerID, err := h.reinitializeSDK(h.userIdentity.GetMnemonic())
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": fmt.Sprintf("failed to reinitialize SDK: %v", err),
+			})
+			return
+		}
+		resp["peerId"] = peerID
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// HandleLockIdentity handles POST /api/v1/identity/lock.
+// Discards decrypted mnemonics from memory.
+func (h *IdentityHandler) HandleLockIdentity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{
+			"error": "Method not allowed",
+		})
+		return
+	}
+
+	h.userIdentity.Lock()
+	writeJSON(w, http.StatusOK, map[string]string{
+		"status": "identity locked",
+	})
+}
+
 // handleIdentity routes identity requests by method.
 func (h *IdentityHandler) handleIdentity(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
@@ -459,5 +873,10 @@ func (h *IdentityHandler) handleIdentity(w http.ResponseWriter, r *http.Request)
 // RegisterRoutes registers identity routes on the mux.
 func (h *IdentityHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/api/v1/identity/set", h.HandleSetIdentity)
+	mux.HandleFunc("/api/v1/identity/switch", h.HandleSwitchIdentity)
+	mux.HandleFunc("/api/v1/identity/list", h.HandleListIdentities)
+	mux.HandleFunc("/api/v1/identity/verify-mnemonic", h.HandleVerifyMnemonic)
+	mux.HandleFunc("/api/v1/identity/unlock", h.HandleUnlockIdentity)
+	mux.HandleFunc("/api/v1/identity/lock", h.HandleLockIdentity)
 	mux.HandleFunc("/api/v1/identity", h.handleIdentity)
 }
@@ -0,0 +1,291 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matou-dao/backend/internal/identity"
+)
+
+// setupIdentityTestHandler returns a handler with two stored identities, the
+// org identity current. The SDK client isn't set, so only requests that fail
+// before reinitializing can be exercised.
+func setupIdentityTestHandler(t *testing.T) (*IdentityHandler, *identity.UserIdentity) {
+	t.Helper()
+	userIdentity := identity.New(t.TempDir())
+	userIdentity.SetIdentity("EPERSONAL", "personal-mnemonic")
+	userIdentity.SetPrivateSpaceID("space-personal")
+	userIdentity.SetIdentity("EORGIDENTITY", "org-mnemonic")
+	userIdentity.SetPrivateSpaceID("space-org")
+	return NewIdentityHandler(userIdentity, nil, nil, nil), userIdentity
+}
+
+func TestHandleListIdentities(t *testing.T) {
+	handler, _ := setupIdentityTestHandler(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/identity/list", nil)
+	w := httptest.NewRecorder()
+	handler.HandleListIdentities(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var resp ListIdentitiesResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Current != "EORGIDENTITY" {
+		t.Errorf("current = %q, want EORGIDENTITY", resp.Current)
+	}
+	if len(resp.Identities) != 2 {
+		t.Fatalf("expected 2 identities, got %d", len(resp.Identities))
+	}
+	if bytes.Contains(w.Body.Bytes(), []byte("mnemonic")) {
+		t.Error("expected mnemonics not to be listed")
+	}
+}
+
+func TestHandleSwitchIdentity_UnknownAID(t *testing.T) {
+	handler, userIdentity := setupIdentityTestHandler(t)
+
+	body := bytes.NewBufferString(`{"aid": "EUNKNOWN"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/identity/switch", body)
+	w := httptest.NewRecorder()
+	handler.HandleSwitchIdentity(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+	if got := userIdentity.GetAID(); got != "EORGIDENTITY" {
+		t.Errorf("current identity changed to %q", got)
+	}
+}
+
+func TestHandleSwitchIdentity_MissingAID(t *testing.T) {
+	handler, _ := setupIdentityTestHandler(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/identity/switch", bytes.NewBufferString(`{}`))
+	w := httptest.NewRecorder()
+	handler.HandleSwitchIdentity(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestHandleSwitchIdentity_MethodNotAllowed(t *testing.T) {
+	handler, _ := setupIdentityTestHandler(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/identity/switch", nil)
+	w := httptest.NewRecorder()
+	handler.HandleSwitchIdentity(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status %d, got %d", http.StatusMethodNotAllowed, w.Code)
+	}
+}
+
+// testBackupMnemonic is a valid BIP39 test vector.
+const testBackupMnemonic = "legal winner thank year wave sausage worth useful legal winner thank yellow"
+
+// postVerifyMnemonic sends a backup verification request for the current identity.
+func postVerifyMnemonic(t *testing.T, handler *IdentityHandler, body string) (*httptest.ResponseRecorder, VerifyMnemonicResponse) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/identity/verify-mnemonic", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	handler.HandleVerifyMnemonic(w, req)
+
+	var resp VerifyMnemonicResponse
+	if err := json.NewDecoder(bytes.NewReader(w.Body.Bytes())).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return w, resp
+}
+
+// setupMnemonicTestHandler returns a handler whose current identity has
+// testBackupMnemonic.
+func setupMnemonicTestHandler(t *testing.T) (*IdentityHandler, *identity.UserIdentity) {
+	t.Helper()
+	userIdentity := identity.New(t.TempDir())
+	userIdentity.SetIdentity("EUSER123", testBackupMnemonic)
+	return NewIdentityHandler(userIdentity, nil, nil, nil), userIdentity
+}
+
+func TestHandleVerifyMnemonic_ExactMatch(t *testing.T) {
+	handler, userIdentity := setupMnemonicTestHandler(t)
+
+	w, resp := postVerifyMnemonic(t, handler, `{"mnemonic": "  legal winner thank year wave sausage worth useful legal winner thank yellow "}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if !resp.Success || !resp.Verified {
+		t.Errorf("expected verified, got %+v", resp)
+	}
+	if bytes.Contains(w.Body.Bytes(), []byte("winner")) {
+		t.Error("expected mnemonic not to be echoed")
+	}
+
+	// The flag is exposed via GET /api/v1/identity
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/identity", nil)
+	rec := httptest.NewRecorder()
+	handler.HandleGetIdentity(rec, req)
+	var info GetIdentityResponse
+	if err := json.NewDecoder(rec.Body).Decode(&info); err != nil {
+		t.Fatalf("failed to decode identity: %v", err)
+	}
+	if !info.BackupVerified || !userIdentity.IsBackupVerified() {
+		t.Error("expected backupVerified after a matching mnemonic")
+	}
+}
+
+func TestHandleVerifyMnemonic_PositionedWords(t *testing.T) {
+	handler, userIdentity := setupMnemonicTestHandler(t)
+
+	_, resp := postVerifyMnemonic(t, handler, `{"words": [
+		{"position": 2, "word": "winner"},
+		{"position": 7, "word": "Worth"},
+		{"position": 12, "word": "yellow"}
+	]}`)
+	if !resp.Verified {
+		t.Errorf("expected positioned words to verify, got %+v", resp)
+	}
+	if !userIdentity.IsBackupVerified() {
+		t.Error("expected backupVerified after matching words")
+	}
+}
+
+func TestHandleVerifyMnemonic_WrongWord(t *testing.T) {
+	handler, userIdentity := setupMnemonicTestHandler(t)
+
+	// A different valid mnemonic
+	w, resp := postVerifyMnemonic(t, handler, `{"mnemonic": "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if resp.Verified {
+		t.Error("expected a different mnemonic not to verify")
+	}
+
+	_, resp = postVerifyMnemonic(t, handler, `{"words": [
+		{"position": 1, "word": "legal"},
+		{"position": 2, "word": "winner"},
+		{"position": 3, "word": "thanks"}
+	]}`)
+	if resp.Verified {
+		t.Error("expected a wrong positioned word not to verify")
+	}
+	if userIdentity.IsBackupVerified() {
+		t.Error("expected backupVerified to stay unset")
+	}
+}
+
+func TestHandleVerifyMnemonic_ValidationFailure(t *testing.T) {
+	handler, _ := setupMnemonicTestHandler(t)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"bad checksum", `{"mnemonic": "legal winner thank year wave sausage worth useful legal winner thank thank"}`},
+		{"not words", `{"mnemonic": "not a real mnemonic"}`},
+		{"too few words", `{"words": [{"position": 1, "word": "legal"}]}`},
+		{"position out of range", `{"words": [{"position": 1, "word": "legal"}, {"position": 2, "word": "winner"}, {"position": 13, "word": "yellow"}]}`},
+		{"repeated position", `{"words": [{"position": 1, "word": "legal"}, {"position": 1, "word": "legal"}, {"position": 2, "word": "winner"}]}`},
+		{"empty", `{}`},
+		{"both", `{"mnemonic": "legal", "words": [{"position": 1, "word": "legal"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, resp := postVerifyMnemonic(t, handler, tt.body)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+			if resp.Verified {
+				t.Error("expected not verified")
+			}
+		})
+	}
+}
+
+func TestHandleVerifyMnemonic_NotConfigured(t *testing.T) {
+	handler := NewIdentityHandler(identity.New(t.TempDir()), nil, nil, nil)
+
+	w, _ := postVerifyMnemonic(t, handler, `{"mnemonic": "`+testBackupMnemonic+`"}`)
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected status %d, got %d", http.StatusConflict, w.Code)
+	}
+}
+
+// setupLockedTestHandler returns a handler whose current identity has
+// testBackupMnemonic encrypted under passphrase and is locked.
+func setupLockedTestHandler(t *testing.T, passphrase string) (*IdentityHandler, *identity.UserIdentity) {
+	t.Helper()
+	userIdentity := identity.New(t.TempDir())
+	if err := userIdentity.SetIdentityWithPassphrase("EUSER123", testBackupMnemonic, passphrase); err != nil {
+		t.Fatalf("SetIdentityWithPassphrase: %v", err)
+	}
+	userIdentity.Lock()
+	return NewIdentityHandler(userIdentity, nil, nil, nil), userIdentity
+}
+
+func postUnlock(handler *IdentityHandler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/identity/unlock", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	handler.HandleUnlockIdentity(w, req)
+	return w
+}
+
+func TestHandleUnlockIdentity(t *testing.T) {
+	handler, userIdentity := setupLockedTestHandler(t, "correct horse")
+
+	w := postUnlock(handler, `{"passphrase": "correct horse"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if userIdentity.IsLocked() || userIdentity.GetMnemonic() != testBackupMnemonic {
+		t.Error("expected identity to be unlocked")
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/identity/lock", nil)
+	w = httptest.NewRecorder()
+	handler.HandleLockIdentity(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if !userIdentity.IsLocked() {
+		t.Error("expected identity to be locked again")
+	}
+}
+
+func TestHandleUnlockIdentity_WrongPassphrase(t *testing.T) {
+	handler, userIdentity := setupLockedTestHandler(t, "correct horse")
+
+	w := postUnlock(handler, `{"passphrase": "wrong horse"}`)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+	if !userIdentity.IsLocked() {
+		t.Error("expected identity to stay locked")
+	}
+}
+
+func TestHandleUnlockIdentity_NotProtected(t *testing.T) {
+	handler, _ := setupMnemonicTestHandler(t)
+
+	w := postUnlock(handler, `{"passphrase": "correct horse"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestHandleVerifyMnemonic_Locked(t *testing.T) {
+	handler, _ := setupLockedTestHandler(t, "correct horse")
+
+	w, _ := postVerifyMnemonic(t, handler, `{"mnemonic": "`+testBackupMnemonic+`"}`)
+	if w.Code != http.StatusLocked {
+		t.Errorf("expected status %d, got %d", http.StatusLocked, w.Code)
+	}
+}
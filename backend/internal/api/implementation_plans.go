@@ -146,7 +146,7 @@ func (h *ImplementationPlansHandler) HandleSignOff(w http.ResponseWriter, r *htt
 	userID := GetUserAID(r)
 	if userID == "" {
 		// Fall back to reading X-User-AID header directly (no RBAC middleware on this route)
-		userID = r.Header.Get("X-User-AID")
+		userID = requestUserAID(r)
 	}
 	if userID == "" {
 		// Allow caller to pass user_id in body when running without RBAC middleware
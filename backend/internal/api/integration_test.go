@@ -81,6 +81,10 @@ func (m *mockAnySyncClientForIntegration) CreateSpaceWithKeys(ctx context.Contex
 	return m.CreateSpace(ctx, ownerAID, spaceType, nil)
 }
 
+func (m *mockAnySyncClientForIntegration) DeriveSpaceWithKeys(ctx context.Context, ownerAID string, spaceType string, keys *anysync.SpaceKeySet) (*anysync.SpaceCreateResult, error) {
+	return m.CreateSpace(ctx, ownerAID, spaceType, nil)
+}
+
 func (m *mockAnySyncClientForIntegration) GetSpace(ctx context.Context, spaceID string) (commonspace.Space, error) {
 	if m.space != nil {
 		return m.space, nil
@@ -157,9 +161,9 @@ func setupIntegrationEnv(t *testing.T) *IntegrationTestEnv {
 	// Create mux and register routes
 	mux := http.NewServeMux()
 	credHandler.RegisterRoutes(mux)
-	syncHandler.RegisterRoutes(mux)
+	syncHandler.RegisterRoutes(mux, nil)
 	trustHandler.RegisterRoutes(mux)
-	spacesHandler.RegisterRoutes(mux)
+	spacesHandler.RegisterRoutes(mux, nil)
 
 	cleanup := func() {
 		store.Close()
@@ -237,8 +241,8 @@ func TestIntegration_CredentialSyncToAnystore(t *testing.T) {
 	if cached.SchemaID != "EMatouMembershipSchemaV1" {
 		t.Errorf("expected schema EMatouMembershipSchemaV1, got %s", cached.SchemaID)
 	}
-	if !cached.Verified {
-		t.Error("expected credential to be verified (org-issued)")
+	if cached.Verified {
+		t.Error("expected unsigned credential not to be verified")
 	}
 
 	// Step 3: Verify credential is retrievable via credentials endpoint
@@ -679,25 +683,10 @@ func TestIntegration_FullSyncFlow(t *testing.T) {
 	}
 
 	// Step 2: Sync KEL
-	kelSyncBody := `{
-		"userAid": "EUSER001",
-		"kel": [
-			{
-				"type": "icp",
-				"sequence": 0,
-				"digest": "EDIGEST001",
-				"data": {"keys": ["key1", "key2"]},
-				"timestamp": "2026-01-20T00:00:00Z"
-			},
-			{
-				"type": "ixn",
-				"sequence": 1,
-				"digest": "EDIGEST002",
-				"data": {"anchor": "ESAID001"},
-				"timestamp": "2026-01-20T01:00:00Z"
-			}
-		]
-	}`
+	kel, icp := newTestKEL(1)
+	ixn := kel.interact("ESAID001")
+	kelSyncJSON, _ := json.Marshal(SyncKELRequest{UserAID: kel.aid, KEL: []KELEvent{icp, ixn}})
+	kelSyncBody := string(kelSyncJSON)
 	kelSyncReq := httptest.NewRequest(http.MethodPost, "/api/v1/sync/kel", bytes.NewBufferString(kelSyncBody))
 	kelSyncReq.Header.Set("Content-Type", "application/json")
 	kelSyncW := httptest.NewRecorder()
@@ -0,0 +1,216 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/anyproto/any-sync/util/crypto"
+	"github.com/matou-dao/backend/internal/anysync"
+)
+
+// InviteAuditObjectType is the ObjectPayload.Type of invite audit records.
+// They are written only to the admin space.
+const InviteAuditObjectType = "InviteAudit"
+
+// Permissions recorded on invite audit records.
+const (
+	InvitePermissionWriter = "writer"
+	InvitePermissionReader = "reader"
+)
+
+// Default per-admin invite rate limit: at most DefaultInviteRateLimit invite
+// requests per DefaultInviteRateWindow.
+const (
+	DefaultInviteRateLimit  = 20
+	DefaultInviteRateWindow = time.Hour
+)
+
+// InviteAuditEntry records who minted an invite key, for which space and when.
+type InviteAuditEntry struct {
+	InviteID   string `json:"inviteId"` // derived from the invite's public key
+	InviterAID string `json:"inviterAid"`
+	SpaceID    string `json:"spaceId"`
+	Permission string `json:"permission"` // writer or reader
+	CreatedAt  string `json:"createdAt"`  // RFC3339
+}
+
+// InviteAuditResponse is the response for GET /api/v1/admin/invites.
+type InviteAuditResponse struct {
+	Invites []InviteAuditEntry `json:"invites"` // newest first
+	Count   int                `json:"count"`
+}
+
+// inviteIDFor derives a stable ID for an invite from its public key, so the
+// audit record can be matched to the ACL invite record without storing the key.
+func inviteIDFor(inviteKey crypto.PrivKey) (string, error) {
+	pubKey, err := inviteKey.GetPublic().Marshall()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(pubKey)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// recordInviteAudit writes an audit record for a newly created invite to the
+// admin space. Nodes without the admin space configured only log the invite.
+// If the tree write fails the record is queued in outbox for retry; an error
+// means it was neither written nor queued, and the invite shouldn't be handed
+// out unaudited.
+func recordInviteAudit(ctx context.Context, spaceManager *anysync.SpaceManager, outbox *WriteOutbox, inviteKey crypto.PrivKey, inviterAID, spaceID, permission string) error {
+	inviteID, err := inviteIDFor(inviteKey)
+	if err != nil {
+		return fmt.Errorf("deriving invite ID: %w", err)
+	}
+	entry := InviteAuditEntry{
+		InviteID:   inviteID,
+		InviterAID: inviterAID,
+		SpaceID:    spaceID,
+		Permission: permission,
+		CreatedAt:  time.Now().UTC().Format(time.RFC3339),
+	}
+	log.Printf("[Invite] %s created %s invite %s for space %s", inviterAID, permission, inviteID, spaceID)
+
+	adminSpaceID := spaceManager.GetAdminSpaceID()
+	if adminSpaceID == "" {
+		return nil
+	}
+	client := spaceManager.GetClient()
+	if client == nil {
+		return fmt.Errorf("any-sync client not available")
+	}
+	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), adminSpaceID, client.GetSigningKey())
+	if err != nil {
+		return fmt.Errorf("loading space keys: %w", err)
+	}
+	dataBytes, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("marshaling invite audit: %w", err)
+	}
+	payload := &anysync.ObjectPayload{
+		ID:        "InviteAudit-" + inviteID,
+		Type:      InviteAuditObjectType,
+		Data:      dataBytes,
+		Timestamp: time.Now().Unix(),
+		Version:   1,
+	}
+	if _, err := spaceManager.ObjectTreeManager().AddObject(ctx, adminSpaceID, payload, keys.SigningKey); err != nil {
+		if qErr := outbox.Enqueue(ctx, adminSpaceID, payload, nil, err); qErr != nil {
+			return fmt.Errorf("writing invite audit: %w (not queued: %v)", err, qErr)
+		}
+	}
+	return nil
+}
+
+// listInviteAudit returns the invite audit records in the admin space, newest first.
+func listInviteAudit(ctx context.Context, spaceManager *anysync.SpaceManager, adminSpaceID string) ([]InviteAuditEntry, error) {
+	spaceManager.TreeManager().BuildSpaceIndex(ctx, adminSpaceID)
+
+	objects, err := spaceManager.ObjectTreeManager().ReadObjectsByType(ctx, adminSpaceID, InviteAuditObjectType)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]InviteAuditEntry, 0, len(objects))
+	for _, obj := range deduplicateObjects(objects) {
+		var entry InviteAuditEntry
+		if err := json.Unmarshal(obj.Data, &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].CreatedAt != entries[j].CreatedAt {
+			return entries[i].CreatedAt > entries[j].CreatedAt
+		}
+		return entries[i].InviteID < entries[j].InviteID
+	})
+	return entries, nil
+}
+
+// HandleListInvites handles GET /api/v1/admin/invites — list the invite audit log.
+func (h *AdminHandler) HandleListInvites(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		return
+	}
+
+	adminSpaceID := h.spaceManager.GetAdminSpaceID()
+	if adminSpaceID == "" {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "admin space not configured",
+		})
+		return
+	}
+
+	entries, err := listInviteAudit(r.Context(), h.spaceManager, adminSpaceID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("failed to read invite audit: %v", err),
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, InviteAuditResponse{Invites: entries, Count: len(entries)})
+}
+
+// inviteRateLimiter caps how many invite requests each inviter can make in a
+// sliding window. A limit of zero or less disables it.
+type inviteRateLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	issued map[string][]time.Time // per inviter AID, oldest first
+}
+
+func newInviteRateLimiter(limit int, window time.Duration) *inviteRateLimiter {
+	return &inviteRateLimiter{limit: limit, window: window, issued: make(map[string][]time.Time)}
+}
+
+// allow records a request by aid at now if it is within the limit. Otherwise
+// it returns false and how long until the oldest request leaves the window.
+func (l *inviteRateLimiter) allow(aid string, now time.Time) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.limit <= 0 {
+		return true, 0
+	}
+
+	cutoff := now.Add(-l.window)
+	recent := l.issued[aid]
+	for len(recent) > 0 && !recent[0].After(cutoff) {
+		recent = recent[1:]
+	}
+	if len(recent) >= l.limit {
+		l.issued[aid] = recent
+		return false, recent[0].Sub(cutoff)
+	}
+	l.issued[aid] = append(recent, now)
+	return true, 0
+}
+
+// checkInviteRateLimit applies the invite rate limit to the caller, writing a
+// 429 with Retry-After and returning false when it is exceeded.
+func (h *SpacesHandler) checkInviteRateLimit(w http.ResponseWriter, r *http.Request) bool {
+	inviterAID := callerAID(r.Context(), h.userIdentity)
+	ok, retryAfter := h.inviteLimiter.allow(inviterAID, time.Now())
+	if ok {
+		return true
+	}
+	log.Printf("[Invite] Rate limit reached for %s", inviterAID)
+	w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Round(time.Second).Seconds())))
+	writeJSON(w, http.StatusTooManyRequests, InviteResponse{
+		Success: false,
+		Error:   fmt.Sprintf("invite rate limit reached, try again in %s", retryAfter.Round(time.Second)),
+	})
+	return false
+}
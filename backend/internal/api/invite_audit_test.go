@@ -0,0 +1,180 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/anyproto/any-sync/commonspace/object/tree/objecttree"
+	"github.com/anyproto/any-sync/util/crypto"
+	"github.com/matou-dao/backend/internal/anystore"
+	"go.uber.org/mock/gomock"
+)
+
+func TestInviteAudit_RecordAndList(t *testing.T) {
+	handler, mux, adminSpaceID := setupAdminTestEnv(t)
+	ctx := context.Background()
+
+	writerKey, _, _ := crypto.GenerateRandomEd25519KeyPair()
+	readerKey, _, _ := crypto.GenerateRandomEd25519KeyPair()
+	if err := recordInviteAudit(ctx, handler.spaceManager, nil, writerKey, adminTestAdminAID, "space-community", InvitePermissionWriter); err != nil {
+		t.Fatalf("recording writer invite: %v", err)
+	}
+	if err := recordInviteAudit(ctx, handler.spaceManager, nil, readerKey, adminTestAdminAID, "space-readonly", InvitePermissionReader); err != nil {
+		t.Fatalf("recording reader invite: %v", err)
+	}
+
+	list := func(callerAID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/invites", nil)
+		req.Header.Set("X-User-AID", callerAID)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	w := list(adminTestAdminAID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp InviteAuditResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Count != 2 || len(resp.Invites) != 2 {
+		t.Fatalf("expected 2 audit records, got %+v", resp)
+	}
+
+	byPermission := map[string]InviteAuditEntry{}
+	for _, entry := range resp.Invites {
+		byPermission[entry.Permission] = entry
+	}
+	writerID, _ := inviteIDFor(writerKey)
+	if entry := byPermission[InvitePermissionWriter]; entry.InviteID != writerID || entry.SpaceID != "space-community" || entry.InviterAID != adminTestAdminAID || entry.CreatedAt == "" {
+		t.Errorf("unexpected writer record %+v (want inviteId %s)", entry, writerID)
+	}
+	readerID, _ := inviteIDFor(readerKey)
+	if entry := byPermission[InvitePermissionReader]; entry.InviteID != readerID || entry.SpaceID != "space-readonly" {
+		t.Errorf("unexpected reader record %+v (want inviteId %s)", entry, readerID)
+	}
+
+	if w := list(adminTestMemberAID); w.Code != http.StatusForbidden {
+		t.Errorf("non-admin: expected 403, got %d", w.Code)
+	}
+
+	handler.spaceManager.SetAdminSpaceID("")
+	defer handler.spaceManager.SetAdminSpaceID(adminSpaceID)
+	if w := list(adminTestAdminAID); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("no admin space: expected 503, got %d", w.Code)
+	}
+}
+
+func TestInviteAudit_QueuesFailedWrite(t *testing.T) {
+	handler, _, adminSpaceID := setupAdminTestEnv(t)
+	ctx := context.Background()
+
+	var down atomic.Bool
+	down.Store(true)
+	ctrl := gomock.NewController(t)
+	treeSeq := 0
+	handler.spaceManager.TreeManager().SetTestTreeFactory(adminSpaceID, func(objectID string) objecttree.ObjectTree {
+		treeSeq++
+		tree := setupStatefulMock(ctrl, &statefulMockTree{})
+		tree.EXPECT().Id().Return(fmt.Sprintf("flaky-tree-%d-%s", treeSeq, objectID)).AnyTimes()
+		tree.EXPECT().Header().Return(nil).AnyTimes()
+		return &flakyTree{ObjectTree: tree, down: &down}
+	})
+	inviteKey, _, _ := crypto.GenerateRandomEd25519KeyPair()
+
+	// Without an outbox the invite can't be audited, so it fails
+	if err := recordInviteAudit(ctx, handler.spaceManager, nil, inviteKey, adminTestAdminAID, "space-community", InvitePermissionWriter); err == nil {
+		t.Fatal("expected an error when the audit record can't be written or queued")
+	}
+
+	store, err := anystore.NewLocalStore(anystore.DefaultConfig(t.TempDir()))
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer store.Close()
+	outbox := NewWriteOutbox(store, handler.spaceManager, nil)
+	if err := recordInviteAudit(ctx, handler.spaceManager, outbox, inviteKey, adminTestAdminAID, "space-community", InvitePermissionWriter); err != nil {
+		t.Fatalf("expected the audit record to be queued, got %v", err)
+	}
+	if depth, _ := outbox.Depth(ctx); depth.Pending != 1 {
+		t.Fatalf("pending = %d, want 1", depth.Pending)
+	}
+
+	down.Store(false)
+	if written := outbox.RetryDue(ctx, time.Now().Add(time.Hour)); written != 1 {
+		t.Fatalf("expected the queued record written, got %d", written)
+	}
+	entries, err := listInviteAudit(ctx, handler.spaceManager, adminSpaceID)
+	if err != nil {
+		t.Fatalf("listing invite audit: %v", err)
+	}
+	inviteID, _ := inviteIDFor(inviteKey)
+	if len(entries) != 1 || entries[0].InviteID != inviteID {
+		t.Errorf("expected the retried audit record, got %+v", entries)
+	}
+}
+
+func TestHandleInvite_RateLimited(t *testing.T) {
+	handler, mockClient, _ := setupTestSpacesHandler(t)
+	handler.SetInviteRateLimit(2, time.Hour)
+
+	invite := func() *httptest.ResponseRecorder {
+		body, _ := json.Marshal(InviteRequest{
+			RecipientAID:   "EUSER123456789",
+			CredentialSAID: "ESAID123456789",
+			Schema:         "EMatouMembershipSchemaV1",
+		})
+		w := httptest.NewRecorder()
+		handler.HandleInvite(w, httptest.NewRequest(http.MethodPost, "/api/v1/spaces/community/invite", bytes.NewBuffer(body)))
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		// The mock space expects a single invite, so each request gets its own
+		mockClient.space = setupMockSpaceForInvite(t)
+		if w := invite(); w.Code != http.StatusOK {
+			t.Fatalf("invite %d: expected 200, got %d: %s", i+1, w.Code, w.Body.String())
+		}
+	}
+	w := invite()
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 once the limit is reached, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("expected a Retry-After header")
+	}
+	var resp InviteResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Success || resp.InviteKey != "" {
+		t.Errorf("expected no invite key when rate limited, got %+v", resp)
+	}
+}
+
+func TestInviteRateLimiter_Window(t *testing.T) {
+	limiter := newInviteRateLimiter(2, time.Hour)
+	start := time.Now()
+
+	if ok, _ := limiter.allow("EADMIN_A", start); !ok {
+		t.Fatal("first request should be allowed")
+	}
+	if ok, _ := limiter.allow("EADMIN_A", start.Add(10*time.Minute)); !ok {
+		t.Fatal("second request should be allowed")
+	}
+	ok, retryAfter := limiter.allow("EADMIN_A", start.Add(20*time.Minute))
+	if ok || retryAfter != 40*time.Minute {
+		t.Errorf("expected the third request refused for 40m, got ok=%v retryAfter=%s", ok, retryAfter)
+	}
+	if ok, _ := limiter.allow("EADMIN_B", start.Add(20*time.Minute)); !ok {
+		t.Error("limits should be per inviter")
+	}
+	if ok, _ := limiter.allow("EADMIN_A", start.Add(time.Hour+time.Second)); !ok {
+		t.Error("expected a request allowed once the oldest left the window")
+	}
+}
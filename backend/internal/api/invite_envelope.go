@@ -0,0 +1,136 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/anyproto/any-sync/util/crypto"
+)
+
+// inviteEnvelopeTTL is how long a signed invite stays valid. It covers the
+// time between an admin approving a member and the member's client picking
+// up the grant and joining.
+const inviteEnvelopeTTL = 7 * 24 * time.Hour
+
+var (
+	errInviteMalformed = errors.New("malformed invite")
+	errInviteSignature = errors.New("invite signature does not verify")
+	errInviteExpired   = errors.New("invite has expired")
+	errInviteIssuer    = errors.New("invite issuer is not a community admin")
+)
+
+// InviteEnvelope packages everything a member needs to join the community,
+// signed by the issuing admin's peer key so the space IDs and invite keys
+// can't be swapped or edited on the way to the member. The member verifies
+// it against the key the org config lists for the issuer, not IssuerKey.
+type InviteEnvelope struct {
+	SpaceID           string `json:"spaceId"`
+	InviteKey         string `json:"inviteKey"`                   // base64-encoded community invite private key
+	ReadOnlySpaceID   string `json:"readOnlySpaceId,omitempty"`   // community-readonly space ID
+	ReadOnlyInviteKey string `json:"readOnlyInviteKey,omitempty"` // base64-encoded community-readonly invite key
+	Issuer            string `json:"issuer"`                      // AID of the admin who issued the invite
+	IssuerKey         string `json:"issuerKey"`                   // base64-encoded public key the issuer signed with
+	ExpiresAt         string `json:"expiresAt"`                   // RFC3339
+	Signature         string `json:"signature,omitempty"`         // base64-encoded signature over every other field
+}
+
+// signingBytes returns the bytes the signature covers: the envelope's JSON
+// with the signature left out. Field order is fixed by the struct, so the
+// encoding is the same on both ends.
+func (e InviteEnvelope) signingBytes() ([]byte, error) {
+	e.Signature = ""
+	return json.Marshal(e)
+}
+
+// inviteSigningKey returns the base64 public key that invites signed with
+// key verify against, as listed for an admin in the org config.
+func inviteSigningKey(key crypto.PrivKey) (string, error) {
+	pubKey, err := key.GetPublic().Marshall()
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal issuer key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(pubKey), nil
+}
+
+// signInviteEnvelope fills in the issuer key and signature using key and
+// returns the envelope encoded for transport (base64url JSON).
+func signInviteEnvelope(env *InviteEnvelope, key crypto.PrivKey) (string, error) {
+	issuerKey, err := inviteSigningKey(key)
+	if err != nil {
+		return "", err
+	}
+	env.IssuerKey = issuerKey
+
+	data, err := env.signingBytes()
+	if err != nil {
+		return "", err
+	}
+	sig, err := key.Sign(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign invite: %w", err)
+	}
+	env.Signature = base64.StdEncoding.EncodeToString(sig)
+
+	encoded, err := json.Marshal(env)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(encoded), nil
+}
+
+// decodeInviteEnvelope decodes an envelope produced by signInviteEnvelope and
+// checks its expiry as of now and its signature against issuerKey(env.Issuer),
+// the trusted base64 key of that community admin. An issuer issuerKey has no
+// key for is rejected with errInviteIssuer.
+func decodeInviteEnvelope(encoded string, now time.Time, issuerKey func(aid string) string) (*InviteEnvelope, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errInviteMalformed
+	}
+	var env InviteEnvelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return nil, errInviteMalformed
+	}
+	if env.SpaceID == "" || env.InviteKey == "" || env.Issuer == "" || env.Signature == "" {
+		return nil, errInviteMalformed
+	}
+
+	trusted := ""
+	if issuerKey != nil {
+		trusted = issuerKey(env.Issuer)
+	}
+	if trusted == "" {
+		return nil, errInviteIssuer
+	}
+	pubKeyBytes, err := base64.StdEncoding.DecodeString(trusted)
+	if err != nil {
+		return nil, errInviteIssuer
+	}
+	pubKey, err := crypto.UnmarshalEd25519PublicKeyProto(pubKeyBytes)
+	if err != nil {
+		return nil, errInviteIssuer
+	}
+	sig, err := base64.StdEncoding.DecodeString(env.Signature)
+	if err != nil {
+		return nil, errInviteMalformed
+	}
+	data, err := env.signingBytes()
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := pubKey.Verify(data, sig); err != nil || !ok {
+		return nil, errInviteSignature
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, env.ExpiresAt)
+	if err != nil {
+		return nil, errInviteMalformed
+	}
+	if !now.Before(expiresAt) {
+		return nil, errInviteExpired
+	}
+	return &env, nil
+}
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/anyproto/any-sync/util/crypto"
+)
+
+// testInviteIssuer is the admin AID test invites are issued by.
+const testInviteIssuer = "EADMIN123456789"
+
+// newSignedTestInvite signs an envelope for the test community space that
+// expires at expiresAt. keyFor trusts the signing key for testInviteIssuer.
+func newSignedTestInvite(t *testing.T, expiresAt time.Time) (env *InviteEnvelope, encoded string, keyFor func(string) string) {
+	t.Helper()
+	issuerKey, _, err := crypto.GenerateRandomEd25519KeyPair()
+	if err != nil {
+		t.Fatalf("generating issuer key: %v", err)
+	}
+	trusted, err := inviteSigningKey(issuerKey)
+	if err != nil {
+		t.Fatalf("encoding issuer key: %v", err)
+	}
+	env = &InviteEnvelope{
+		SpaceID:           "test-community-space",
+		InviteKey:         base64.StdEncoding.EncodeToString([]byte("community-invite-key")),
+		ReadOnlySpaceID:   "test-readonly-space",
+		ReadOnlyInviteKey: base64.StdEncoding.EncodeToString([]byte("readonly-invite-key")),
+		Issuer:            testInviteIssuer,
+		ExpiresAt:         expiresAt.UTC().Format(time.RFC3339),
+	}
+	encoded, err = signInviteEnvelope(env, issuerKey)
+	if err != nil {
+		t.Fatalf("signing invite: %v", err)
+	}
+	keyFor = func(aid string) string {
+		if aid == testInviteIssuer {
+			return trusted
+		}
+		return ""
+	}
+	return env, encoded, keyFor
+}
+
+// reencodeInvite decodes a transport-encoded envelope, applies edit and
+// encodes it again without re-signing.
+func reencodeInvite(t *testing.T, encoded string, edit func(*InviteEnvelope)) string {
+	t.Helper()
+	raw, _ := base64.RawURLEncoding.DecodeString(encoded)
+	var env InviteEnvelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		t.Fatalf("decoding invite: %v", err)
+	}
+	edit(&env)
+	raw, _ = json.Marshal(env)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func TestInviteEnvelope(t *testing.T) {
+	now := time.Now()
+
+	t.Run("valid envelope", func(t *testing.T) {
+		want, encoded, keyFor := newSignedTestInvite(t, now.Add(time.Hour))
+		got, err := decodeInviteEnvelope(encoded, now, keyFor)
+		if err != nil {
+			t.Fatalf("expected the invite to verify, got %v", err)
+		}
+		if *got != *want {
+			t.Errorf("decoded %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("tampered spaceId", func(t *testing.T) {
+		_, encoded, keyFor := newSignedTestInvite(t, now.Add(time.Hour))
+		tampered := reencodeInvite(t, encoded, func(env *InviteEnvelope) { env.SpaceID = "attacker-space" })
+		if _, err := decodeInviteEnvelope(tampered, now, keyFor); !errors.Is(err, errInviteSignature) {
+			t.Errorf("expected a signature error, got %v", err)
+		}
+	})
+
+	t.Run("signed by a key other than the issuer's", func(t *testing.T) {
+		// A self-consistent envelope from an attacker naming the admin as issuer
+		_, encoded, _ := newSignedTestInvite(t, now.Add(time.Hour))
+		_, _, adminKeyFor := newSignedTestInvite(t, now.Add(time.Hour))
+		if _, err := decodeInviteEnvelope(encoded, now, adminKeyFor); !errors.Is(err, errInviteSignature) {
+			t.Errorf("expected a signature error, got %v", err)
+		}
+	})
+
+	t.Run("issuer not a community admin", func(t *testing.T) {
+		_, encoded, keyFor := newSignedTestInvite(t, now.Add(time.Hour))
+		forged := reencodeInvite(t, encoded, func(env *InviteEnvelope) { env.Issuer = "EMEMBER" })
+		if _, err := decodeInviteEnvelope(forged, now, keyFor); !errors.Is(err, errInviteIssuer) {
+			t.Errorf("expected an issuer error, got %v", err)
+		}
+		if _, err := decodeInviteEnvelope(encoded, now, nil); !errors.Is(err, errInviteIssuer) {
+			t.Errorf("expected an issuer error without trusted keys, got %v", err)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		_, encoded, keyFor := newSignedTestInvite(t, now.Add(-time.Minute))
+		if _, err := decodeInviteEnvelope(encoded, now, keyFor); !errors.Is(err, errInviteExpired) {
+			t.Errorf("expected an expiry error, got %v", err)
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		if _, err := decodeInviteEnvelope("not-an-invite", now, nil); !errors.Is(err, errInviteMalformed) {
+			t.Errorf("expected a malformed error, got %v", err)
+		}
+	})
+}
+
+func TestHandleJoinCommunity_InviteChecks(t *testing.T) {
+	handler, _, _ := setupTestSpacesHandler(t)
+	join := func(req JoinCommunityRequest) (int, JoinCommunityResponse) {
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		handler.HandleJoinCommunity(w, httptest.NewRequest(http.MethodPost, "/api/v1/spaces/community/join", bytes.NewBuffer(body)))
+		var resp JoinCommunityResponse
+		json.NewDecoder(w.Body).Decode(&resp)
+		return w.Code, resp
+	}
+	errOf := func(code int, resp JoinCommunityResponse) string {
+		if code != http.StatusBadRequest {
+			t.Errorf("expected 400, got %d: %+v", code, resp)
+		}
+		return resp.Error
+	}
+
+	env, encoded, keyFor := newSignedTestInvite(t, time.Now().Add(time.Hour))
+	handler.SetInviteIssuerKeys(keyFor)
+
+	t.Run("tampered envelope rejected", func(t *testing.T) {
+		tampered := reencodeInvite(t, encoded, func(env *InviteEnvelope) { env.ReadOnlySpaceID = "attacker-space" })
+		if msg := errOf(join(JoinCommunityRequest{UserAID: "EUSER123", Invite: tampered})); msg != "invalid invite: "+errInviteSignature.Error() {
+			t.Errorf("unexpected error %q", msg)
+		}
+	})
+
+	t.Run("loose fields must match the envelope", func(t *testing.T) {
+		if msg := errOf(join(JoinCommunityRequest{UserAID: "EUSER123", Invite: encoded, SpaceID: "other-space"})); msg != "invite fields do not match the signed invite" {
+			t.Errorf("unexpected error %q", msg)
+		}
+	})
+
+	t.Run("envelope for another community", func(t *testing.T) {
+		handler.spaceManager.SetCommunitySpaceID("local-community-space")
+		defer handler.spaceManager.SetCommunitySpaceID(env.SpaceID)
+		if msg := errOf(join(JoinCommunityRequest{UserAID: "EUSER123", Invite: encoded})); msg != "invite is for a different community space" {
+			t.Errorf("unexpected error %q", msg)
+		}
+	})
+
+	t.Run("unsigned invite rejected when disabled", func(t *testing.T) {
+		handler.SetAllowUnsignedInvites(false)
+		defer handler.SetAllowUnsignedInvites(true)
+		if msg := errOf(join(JoinCommunityRequest{UserAID: "EUSER123", InviteKey: env.InviteKey, SpaceID: env.SpaceID})); msg != "a signed invite is required" {
+			t.Errorf("unexpected error %q", msg)
+		}
+	})
+}
+
+func TestOrgConfig_SavingListsLocalSigningKey(t *testing.T) {
+	h := NewOrgConfigHandler(t.TempDir(), nil)
+	h.SetLocalSigningKey(func() (string, string) { return testInviteIssuer, "bG9jYWwta2V5" })
+
+	body := `{"organization":{"aid":"EORG","name":"Org"},"admins":[{"aid":"` + testInviteIssuer + `","name":"Admin"},{"aid":"EOTHER","name":"Other"}]}`
+	w := httptest.NewRecorder()
+	h.HandleSaveConfig(w, httptest.NewRequest(http.MethodPost, "/api/v1/org/config", bytes.NewBufferString(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if got := h.GetAdminSigningKey(testInviteIssuer); got != "bG9jYWwta2V5" {
+		t.Errorf("expected the local admin's key to be listed, got %q", got)
+	}
+	if got := h.GetAdminSigningKey("EOTHER"); got != "" {
+		t.Errorf("expected no key for another admin, got %q", got)
+	}
+	if got := h.GetAdminSigningKey("EMEMBER"); got != "" {
+		t.Errorf("expected no key for a non-admin, got %q", got)
+	}
+}
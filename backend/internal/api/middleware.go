@@ -5,10 +5,22 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
+// configuredOrigins are origins allowed in addition to the built-in ones
+var configuredOrigins atomic.Pointer[[]string]
+
+// SetAllowedOrigins sets the origins allowed in addition to the built-in
+// ones, in both CORS modes. Safe to call while serving requests.
+func SetAllowedOrigins(origins []string) {
+	origins = slices.Clone(origins)
+	configuredOrigins.Store(&origins)
+}
+
 // isBundledOrigin returns true if the origin is a valid bundled-app origin
 // (Electron file://, Capacitor, or any localhost/127.0.0.1).
 func isBundledOrigin(origin string) bool {
@@ -28,6 +40,10 @@ func isBundledOrigin(origin string) bool {
 // isAllowedOrigin checks whether the origin should get CORS headers,
 // respecting the MATOU_CORS_MODE env var ("bundled" or default "dev").
 func isAllowedOrigin(origin string) bool {
+	if origins := configuredOrigins.Load(); origin != "" && origins != nil && slices.Contains(*origins, origin) {
+		return true
+	}
+
 	mode := os.Getenv("MATOU_CORS_MODE")
 	if mode == "bundled" {
 		return isBundledOrigin(origin)
@@ -66,7 +82,7 @@ func CORSMiddleware(next http.Handler) http.Handler {
 
 		// Allow common headers and methods
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, Authorization, X-Requested-With, X-User-AID, X-Test-Config, X-User-Name")
+		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, Authorization, X-Requested-With, X-User-AID, X-Test-Config, X-User-Name, Idempotency-Key")
 		w.Header().Set("Access-Control-Max-Age", "86400")
 
 		// Handle preflight requests
@@ -97,7 +113,7 @@ func CORSHandler(handler http.HandlerFunc) http.HandlerFunc {
 		}
 
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, Authorization, X-Requested-With, X-User-AID, X-Test-Config, X-User-Name")
+		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, Authorization, X-Requested-With, X-User-AID, X-Test-Config, X-User-Name, Idempotency-Key")
 
 		if r.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusOK)
@@ -142,7 +158,7 @@ func (m *CORSMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if isAllowedOrigin(origin) {
 		w.Header().Set("Access-Control-Allow-Origin", origin)
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, Authorization, X-Requested-With, X-User-AID, X-Test-Config, X-User-Name")
+		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, Authorization, X-Requested-With, X-User-AID, X-Test-Config, X-User-Name, Idempotency-Key")
 	}
 
 	if r.Method == http.MethodOptions {
@@ -158,15 +174,27 @@ func (m *CORSMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	m.mux.ServeHTTP(w, r)
 }
 
-// RequestLogger logs each non-OPTIONS HTTP request with method, path, status, and duration.
-// Enabled by setting MATOU_REQUEST_LOG=1.
-func RequestLogger(next http.Handler) http.Handler {
-	if os.Getenv("MATOU_REQUEST_LOG") != "1" {
-		return next
+// requestLogging turns RequestLogger output on and off
+var requestLogging atomic.Bool
+
+// SetRequestLogging enables or disables request logging. Safe to call while
+// serving requests.
+func SetRequestLogging(enabled bool) {
+	if requestLogging.Swap(enabled) == enabled {
+		return
 	}
-	log.Println("[RequestLog] Request logging enabled")
+	if enabled {
+		log.Println("[RequestLog] Request logging enabled")
+	} else {
+		log.Println("[RequestLog] Request logging disabled")
+	}
+}
+
+// RequestLogger logs each non-OPTIONS HTTP request with method, path, status, and duration
+// while request logging is enabled (server.requestLog or MATOU_REQUEST_LOG=1).
+func RequestLogger(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodOptions {
+		if r.Method == http.MethodOptions || !requestLogging.Load() {
 			next.ServeHTTP(w, r)
 			return
 		}
@@ -201,14 +229,7 @@ func LocalhostGuard(next http.Handler) http.Handler {
 	log.Println("[Security] Localhost guard ACTIVE (MATOU_CORS_MODE=bundled)")
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		host, _, err := net.SplitHostPort(r.RemoteAddr)
-		if err != nil {
-			http.Error(w, "Forbidden", http.StatusForbidden)
-			return
-		}
-
-		ip := net.ParseIP(host)
-		if ip == nil || !ip.IsLoopback() {
+		if !isLoopbackRequest(r) {
 			http.Error(w, "Forbidden", http.StatusForbidden)
 			return
 		}
@@ -216,3 +237,13 @@ func LocalhostGuard(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// isLoopbackRequest reports whether r came from a loopback address.
+func isLoopbackRequest(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -398,3 +398,19 @@ func containsMiddle(s, substr string) bool {
 	}
 	return false
 }
+
+func TestCORSMiddleware_ConfiguredOrigin(t *testing.T) {
+	SetAllowedOrigins([]string{"https://app.example.org"})
+	defer SetAllowedOrigins(nil)
+
+	wrapped := CORSMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Origin", "https://app.example.org")
+	w := httptest.NewRecorder()
+	wrapped.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.org" {
+		t.Errorf("expected configured origin to be allowed, got %q", got)
+	}
+}
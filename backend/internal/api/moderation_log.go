@@ -0,0 +1,207 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/matou-dao/backend/internal/anysync"
+)
+
+// ModerationEventObjectType is the ObjectPayload.Type of moderation log
+// records. They are written only to the admin space.
+const ModerationEventObjectType = "ModerationEvent"
+
+// Moderator actions recorded in the moderation log.
+const (
+	ModerationActionDelete  = "delete"
+	ModerationActionEdit    = "edit"
+	ModerationActionArchive = "archive"
+	ModerationActionPin     = "pin"
+	ModerationActionUnpin   = "unpin"
+)
+
+// ModerationEvent records a moderator action on chat or notice content.
+type ModerationEvent struct {
+	ID         string `json:"id"`
+	ActorAID   string `json:"actorAid"`
+	Action     string `json:"action"`     // delete, edit, archive, pin or unpin
+	TargetType string `json:"targetType"` // e.g. ChatMessage, ChatChannel, Notice, NoticeComment
+	TargetID   string `json:"targetId"`
+	Reason     string `json:"reason,omitempty"`
+	Timestamp  string `json:"timestamp"` // RFC3339
+}
+
+// ModerationLogResponse is the response for GET /api/v1/admin/moderation-log.
+type ModerationLogResponse struct {
+	Events []ModerationEvent `json:"events"` // newest first
+	Count  int               `json:"count"`
+}
+
+// moderationRequest is the optional body of a moderator action.
+type moderationRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// moderationReason returns the reason a moderator gave for an action, from
+// the optional {"reason": "..."} request body. An empty body gives no
+// reason; a malformed one is answered with 400 and ok is false.
+func moderationReason(w http.ResponseWriter, r *http.Request) (reason string, ok bool) {
+	if r.Body == nil {
+		return "", true
+	}
+	var req moderationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("invalid request: %v", err),
+		})
+		return "", false
+	}
+	return req.Reason, true
+}
+
+// recordModerationEvent writes a moderation log record to the admin space and
+// fills in its ID and timestamp. Nodes without the admin space configured only
+// log the action.
+func recordModerationEvent(ctx context.Context, spaceManager *anysync.SpaceManager, event ModerationEvent) error {
+	now := time.Now().UTC()
+	event.ID = newObjectID(ModerationEventObjectType, event.ActorAID)
+	event.Timestamp = now.Format(time.RFC3339)
+	log.Printf("[Moderation] %s: %s %s %s", event.ActorAID, event.Action, event.TargetType, event.TargetID)
+
+	adminSpaceID := spaceManager.GetAdminSpaceID()
+	if adminSpaceID == "" {
+		return nil
+	}
+	client := spaceManager.GetClient()
+	if client == nil {
+		return fmt.Errorf("any-sync client not available")
+	}
+	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), adminSpaceID, client.GetSigningKey())
+	if err != nil {
+		return fmt.Errorf("loading space keys: %w", err)
+	}
+	dataBytes, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshaling moderation event: %w", err)
+	}
+	payload := &anysync.ObjectPayload{
+		ID:        event.ID,
+		Type:      ModerationEventObjectType,
+		Data:      dataBytes,
+		Timestamp: now.Unix(),
+		Version:   1,
+	}
+	_, err = spaceManager.ObjectTreeManager().AddObject(ctx, adminSpaceID, payload, keys.SigningKey)
+	return err
+}
+
+// logModeration records a moderator action, logging rather than failing when
+// the record can't be written: the action itself has already happened.
+func logModeration(ctx context.Context, spaceManager *anysync.SpaceManager, event ModerationEvent) {
+	if err := recordModerationEvent(ctx, spaceManager, event); err != nil {
+		log.Printf("[Moderation] Warning: failed to record %s of %s: %v", event.Action, event.TargetID, err)
+	}
+}
+
+// moderationLogFilter selects moderation events; empty fields match anything.
+type moderationLogFilter struct {
+	actorAID string
+	targetID string
+	from, to time.Time // [from, to)
+}
+
+func (f moderationLogFilter) matches(event ModerationEvent) bool {
+	if f.actorAID != "" && event.ActorAID != f.actorAID {
+		return false
+	}
+	if f.targetID != "" && event.TargetID != f.targetID {
+		return false
+	}
+	if f.from.IsZero() && f.to.IsZero() {
+		return true
+	}
+	at, err := time.Parse(time.RFC3339, event.Timestamp)
+	if err != nil {
+		return false
+	}
+	return (f.from.IsZero() || !at.Before(f.from)) && (f.to.IsZero() || at.Before(f.to))
+}
+
+// listModerationEvents returns the moderation events in the admin space that
+// match filter, newest first.
+func listModerationEvents(ctx context.Context, spaceManager *anysync.SpaceManager, adminSpaceID string, filter moderationLogFilter) ([]ModerationEvent, error) {
+	spaceManager.TreeManager().BuildSpaceIndex(ctx, adminSpaceID)
+
+	objects, err := spaceManager.ObjectTreeManager().ReadObjectsByType(ctx, adminSpaceID, ModerationEventObjectType)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]ModerationEvent, 0, len(objects))
+	for _, obj := range deduplicateObjects(objects) {
+		var event ModerationEvent
+		if err := json.Unmarshal(obj.Data, &event); err != nil {
+			continue
+		}
+		if filter.matches(event) {
+			events = append(events, event)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Timestamp != events[j].Timestamp {
+			return events[i].Timestamp > events[j].Timestamp
+		}
+		return events[i].ID > events[j].ID
+	})
+	return events, nil
+}
+
+// HandleModerationLog handles GET /api/v1/admin/moderation-log — list
+// moderator actions. Filters: ?actor= (AID), ?target= (object ID), and
+// ?from= / ?to= (RFC3339, [from, to)).
+func (h *AdminHandler) HandleModerationLog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		return
+	}
+
+	query := r.URL.Query()
+	filter := moderationLogFilter{actorAID: query.Get("actor"), targetID: query.Get("target")}
+	for name, dst := range map[string]*time.Time{"from": &filter.from, "to": &filter.to} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": fmt.Sprintf("invalid %s: must be an RFC3339 timestamp", name),
+			})
+			return
+		}
+		*dst = parsed
+	}
+
+	adminSpaceID := h.spaceManager.GetAdminSpaceID()
+	if adminSpaceID == "" {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "admin space not configured",
+		})
+		return
+	}
+
+	events, err := listModerationEvents(r.Context(), h.spaceManager, adminSpaceID, filter)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("failed to read moderation log: %v", err),
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, ModerationLogResponse{Events: events, Count: len(events)})
+}
@@ -0,0 +1,262 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anyproto/any-sync/commonspace/object/tree/objecttree"
+	"github.com/matou-dao/backend/internal/anysync"
+	"github.com/matou-dao/backend/internal/contributions"
+	"go.uber.org/mock/gomock"
+)
+
+const moderationTestModeratorAID = "ETEST_CHAT_MODERATOR"
+
+// addModerationAdminSpace gives spaceManager an admin space for moderation
+// records, with its keys under dataDir.
+func addModerationAdminSpace(t *testing.T, spaceManager *anysync.SpaceManager, dataDir string) {
+	t.Helper()
+	adminSpaceID := "space-admin-moderation-test"
+	keys, err := anysync.GenerateSpaceKeySet()
+	if err != nil {
+		t.Fatalf("generating keys: %v", err)
+	}
+	if err := anysync.PersistSpaceKeySet(dataDir, adminSpaceID, keys); err != nil {
+		t.Fatalf("persisting keys: %v", err)
+	}
+	spaceManager.SetAdminSpaceID(adminSpaceID)
+	ctrl := gomock.NewController(t)
+	treeSeq := 0
+	spaceManager.TreeManager().SetTestTreeFactory(adminSpaceID, func(objectID string) objecttree.ObjectTree {
+		treeSeq++
+		tree := setupStatefulMock(ctrl, &statefulMockTree{})
+		tree.EXPECT().Id().Return(fmt.Sprintf("tree-moderation-%d-%s", treeSeq, objectID)).AnyTimes()
+		tree.EXPECT().Header().Return(nil).AnyTimes()
+		return tree
+	})
+}
+
+// moderationAdminMux serves the admin routes, where "EAdmin" is a Founding
+// Member and moderationTestModeratorAID a Community Steward.
+func moderationAdminMux(spaceManager *anysync.SpaceManager) (*http.ServeMux, RoleLookup) {
+	roleLookup := &mockRoleLookup{roles: map[string][]contributions.Role{
+		moderationTestModeratorAID: {contributions.RoleCommunitySteward},
+		"EAdmin":                   {contributions.RoleFoundingMember},
+	}}
+	adminMux := http.NewServeMux()
+	NewAdminHandler(spaceManager).RegisterRoutes(adminMux, roleLookup)
+	return adminMux, roleLookup
+}
+
+// setupModerationTestEnv extends the chat test env with an admin space for
+// moderation records and the admin routes (see moderationAdminMux).
+func setupModerationTestEnv(t *testing.T) (*chatTestEnv, *http.ServeMux) {
+	t.Helper()
+	env := setupChatTestEnv(t)
+	t.Cleanup(env.cleanup)
+	addModerationAdminSpace(t, env.spaceManager, env.tmpDir)
+	adminMux, roleLookup := moderationAdminMux(env.spaceManager)
+	env.chatHandler.RegisterAdminRoutes(env.mux, roleLookup)
+	return env, adminMux
+}
+
+func getModerationLog(t *testing.T, mux *http.ServeMux, query url.Values) ModerationLogResponse {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/moderation-log?"+query.Encode(), nil)
+	req.Header.Set("X-User-AID", "EAdmin")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp ModerationLogResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	return resp
+}
+
+func deleteTestMessage(env *chatTestEnv, messageID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/chat/messages/"+messageID, nil)
+	w := httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	return w
+}
+
+func archiveTestChannel(env *chatTestEnv, channelID, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/chat/channels/"+channelID, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	return w
+}
+
+func TestModerationLog_ArchiveReason(t *testing.T) {
+	env, adminMux := setupModerationTestEnv(t)
+
+	channelID := createTestChannel(t, env, "moderated")
+	own := sendTestMessage(t, env, channelID, "my own message")
+	other := sendTestMessage(t, env, channelID, "someone else's")
+
+	// The sender deleting their own message isn't a moderator action
+	if w := deleteTestMessage(env, own); w.Code != http.StatusOK {
+		t.Fatalf("own delete: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := getModerationLog(t, adminMux, nil); got.Count != 0 {
+		t.Fatalf("expected no moderation entries for a self-delete, got %+v", got)
+	}
+
+	// Chat messages can only be deleted by their sender, moderators included
+	env.userIdentity.SetIdentity(moderationTestModeratorAID, "moderator-mnemonic")
+	if w := deleteTestMessage(env, other); w.Code != http.StatusForbidden {
+		t.Fatalf("moderator delete: expected 403, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := archiveTestChannel(env, channelID, "{not json"); w.Code != http.StatusBadRequest {
+		t.Fatalf("malformed body: expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := archiveTestChannel(env, channelID, `{"reason":"spam"}`); w.Code != http.StatusOK {
+		t.Fatalf("archive: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	got := getModerationLog(t, adminMux, nil)
+	if got.Count != 1 {
+		t.Fatalf("expected 1 moderation entry, got %+v", got)
+	}
+	event := got.Events[0]
+	if event.ActorAID != moderationTestModeratorAID || event.TargetID != channelID || event.TargetType != "ChatChannel" ||
+		event.Action != ModerationActionArchive || event.Reason != "spam" || event.Timestamp == "" {
+		t.Errorf("unexpected moderation entry %+v", event)
+	}
+}
+
+func TestModerationLog_NoticeEditAndCommentDelete(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+	addModerationAdminSpace(t, env.spaceManager, env.spaceManager.GetClient().GetDataDir())
+	adminMux, _ := moderationAdminMux(env.spaceManager)
+
+	noticeID := createTestNotice(t, env, map[string]interface{}{
+		"id": "moderated-notice", "type": "announcement", "title": "Member notice", "summary": "Summary",
+	})
+	// The author's own edit isn't logged
+	if w := env.do(t, http.MethodPut, "/api/v1/notices/"+noticeID, map[string]interface{}{"title": "Retitled"}); w.Code != http.StatusOK {
+		t.Fatalf("own edit: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	commentID := createTestComment(t, env, noticeID, "off topic")
+
+	env.userIdentity.SetIdentity(noticeTestAdminAID, "admin-mnemonic")
+	w := env.do(t, http.MethodPut, "/api/v1/notices/"+noticeID, map[string]interface{}{"title": "Moderated", "reason": "misleading title"}, noticeTestAdminAID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("admin edit: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	w = env.do(t, http.MethodDelete, "/api/v1/notices/"+noticeID+"/comments/"+commentID, map[string]interface{}{"reason": "off topic"}, noticeTestAdminAID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("comment delete: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	got := getModerationLog(t, adminMux, nil)
+	if got.Count != 2 {
+		t.Fatalf("expected 2 moderation entries, got %+v", got)
+	}
+	byAction := map[string]ModerationEvent{}
+	for _, event := range got.Events {
+		byAction[event.Action] = event
+	}
+	if edit := byAction[ModerationActionEdit]; edit.TargetID != noticeID || edit.TargetType != "Notice" || edit.Reason != "misleading title" {
+		t.Errorf("unexpected edit entry %+v", edit)
+	}
+	if del := byAction[ModerationActionDelete]; del.TargetID != commentID || del.TargetType != "NoticeComment" || del.Reason != "off topic" {
+		t.Errorf("unexpected delete entry %+v", del)
+	}
+}
+
+func TestModerationLog_NoticeArchive(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+	addModerationAdminSpace(t, env.spaceManager, env.spaceManager.GetClient().GetDataDir())
+	adminMux, _ := moderationAdminMux(env.spaceManager)
+
+	own := createTestNotice(t, env, map[string]interface{}{
+		"id": "own-notice", "type": "announcement", "title": "Own", "summary": "Summary",
+	})
+	other := createTestNotice(t, env, map[string]interface{}{
+		"id": "other-notice", "type": "announcement", "title": "Other", "summary": "Summary",
+	})
+	for _, noticeID := range []string{own, other} {
+		if w := env.do(t, http.MethodPost, "/api/v1/notices/"+noticeID+"/publish", nil); w.Code != http.StatusOK {
+			t.Fatalf("publish: expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+
+	// The creator archiving their own notice isn't logged
+	if w := env.do(t, http.MethodPost, "/api/v1/notices/"+own+"/archive", nil); w.Code != http.StatusOK {
+		t.Fatalf("own archive: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := getModerationLog(t, adminMux, nil); got.Count != 0 {
+		t.Fatalf("expected no moderation entries for a self-archive, got %+v", got)
+	}
+
+	env.userIdentity.SetIdentity(noticeTestAdminAID, "admin-mnemonic")
+	w := env.do(t, http.MethodPost, "/api/v1/notices/"+other+"/archive", map[string]interface{}{"reason": "outdated"}, noticeTestAdminAID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("admin archive: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	got := getModerationLog(t, adminMux, nil)
+	if got.Count != 1 {
+		t.Fatalf("expected 1 moderation entry, got %+v", got)
+	}
+	if event := got.Events[0]; event.Action != ModerationActionArchive || event.TargetID != other || event.Reason != "outdated" {
+		t.Errorf("unexpected archive entry %+v", event)
+	}
+}
+
+func TestModerationLog_Filters(t *testing.T) {
+	env, adminMux := setupModerationTestEnv(t)
+
+	first := createTestChannel(t, env, "first")
+	second := createTestChannel(t, env, "second")
+	third := createTestChannel(t, env, "third")
+	env.userIdentity.SetIdentity(moderationTestModeratorAID, "moderator-mnemonic")
+	for _, channelID := range []string{first, second, third} {
+		if w := archiveTestChannel(env, channelID, ""); w.Code != http.StatusOK {
+			t.Fatalf("archive: expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+
+	if got := getModerationLog(t, adminMux, nil); got.Count != 3 {
+		t.Fatalf("expected 3 moderation entries, got %+v", got)
+	}
+	if got := getModerationLog(t, adminMux, url.Values{"target": {second}}); got.Count != 1 || got.Events[0].Action != ModerationActionArchive {
+		t.Errorf("target filter: expected the channel archive, got %+v", got)
+	}
+	if got := getModerationLog(t, adminMux, url.Values{"actor": {"EAdmin"}}); got.Count != 0 {
+		t.Errorf("actor filter: expected no entries by EAdmin, got %+v", got)
+	}
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	if got := getModerationLog(t, adminMux, url.Values{"from": {future}}); got.Count != 0 {
+		t.Errorf("from filter: expected no entries, got %+v", got)
+	}
+	if got := getModerationLog(t, adminMux, url.Values{"to": {future}}); got.Count != 3 {
+		t.Errorf("to filter: expected all entries, got %+v", got)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/moderation-log?from=yesterday", nil)
+	req.Header.Set("X-User-AID", "EAdmin")
+	w := httptest.NewRecorder()
+	adminMux.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid from: expected 400, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/admin/moderation-log", nil)
+	req.Header.Set("X-User-AID", moderationTestModeratorAID)
+	w = httptest.NewRecorder()
+	adminMux.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("non-admin: expected 403, got %d", w.Code)
+	}
+}
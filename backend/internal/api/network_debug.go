@@ -0,0 +1,147 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/anyproto/any-sync/nodeconf"
+	"github.com/matou-dao/backend/internal/anysync"
+)
+
+// defaultPeerDialTimeout bounds each peer's reachability check, so one
+// unreachable node doesn't hold up the whole report.
+const defaultPeerDialTimeout = 3 * time.Second
+
+// diagnosedNodeTypes are the node roles whose peers are dialed; the
+// coordinator is covered by the last ping instead.
+var diagnosedNodeTypes = []nodeconf.NodeType{nodeconf.NodeTypeTree, nodeconf.NodeTypeConsensus, nodeconf.NodeTypeFile}
+
+// NetworkPeerStatus is one configured any-sync node and whether it answered.
+type NetworkPeerStatus struct {
+	PeerID    string   `json:"peerId"`
+	Types     []string `json:"types"`
+	Addresses []string `json:"addresses,omitempty"`
+	Reachable bool     `json:"reachable"`
+	LatencyMs int64    `json:"latencyMs,omitempty"` // time to get a connection from the pool
+	Error     string   `json:"error,omitempty"`
+}
+
+// NetworkDiagnostics is the response for GET /api/v1/debug/network.
+type NetworkDiagnostics struct {
+	NetworkID      string              `json:"networkId"`
+	CoordinatorURL string              `json:"coordinatorUrl"`
+	PeerID         string              `json:"peerId"`
+	Peers          []NetworkPeerStatus `json:"peers"`
+	LastPing       *CoordinatorStatus  `json:"lastPing,omitempty"`
+	Pool           *anysync.PoolStats  `json:"pool,omitempty"`
+}
+
+// NetworkDebugHandler reports the node's view of the any-sync network.
+type NetworkDebugHandler struct {
+	spaceManager *anysync.SpaceManager
+	watchdog     *CoordinatorWatchdog
+	dialTimeout  time.Duration
+}
+
+// NewNetworkDebugHandler creates a new network diagnostics handler.
+func NewNetworkDebugHandler(spaceManager *anysync.SpaceManager) *NetworkDebugHandler {
+	return &NetworkDebugHandler{spaceManager: spaceManager, dialTimeout: defaultPeerDialTimeout}
+}
+
+// SetCoordinatorWatchdog reports the watchdog's last coordinator ping.
+// Without one, each request pings the coordinator itself.
+func (h *NetworkDebugHandler) SetCoordinatorWatchdog(wd *CoordinatorWatchdog) {
+	h.watchdog = wd
+}
+
+// RegisterRoutes registers the diagnostics route; it requires a community
+// admin.
+func (h *NetworkDebugHandler) RegisterRoutes(mux *http.ServeMux, roleLookup RoleLookup) {
+	mux.HandleFunc("/api/v1/debug/network", RBACMiddleware(roleLookup, requireCommunityAdmin(h.HandleNetwork)))
+}
+
+// HandleNetwork handles GET /api/v1/debug/network — the network and peer
+// IDs, the coordinator, and each configured tree, consensus and file node
+// dialed through the connection pool.
+func (h *NetworkDebugHandler) HandleNetwork(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		return
+	}
+
+	client := h.spaceManager.GetClient()
+	if client == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "any-sync client not available"})
+		return
+	}
+
+	resp := NetworkDiagnostics{
+		NetworkID:      client.GetNetworkID(),
+		CoordinatorURL: client.GetCoordinatorURL(),
+		PeerID:         client.GetPeerID(),
+		Peers:          h.dialPeers(r.Context(), client),
+		Pool:           h.spaceManager.PoolStats(),
+	}
+	if h.watchdog != nil {
+		status := h.watchdog.Status()
+		resp.LastPing = &status
+	} else {
+		now := time.Now()
+		status := CoordinatorStatus{Mode: ModeReadWrite, Reachable: true, LastCheck: &now}
+		if err := client.Ping(); err != nil {
+			status.Reachable = false
+			status.Error = err.Error()
+		}
+		resp.LastPing = &status
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// dialPeers checks every configured tree, consensus and file node in
+// parallel, in the order the network config lists them.
+func (h *NetworkDebugHandler) dialPeers(ctx context.Context, client anysync.AnySyncClient) []NetworkPeerStatus {
+	conf := client.GetNodeConf()
+	if conf == nil {
+		return []NetworkPeerStatus{}
+	}
+	connPool := client.GetPool()
+
+	peers := []NetworkPeerStatus{}
+	for _, node := range conf.Configuration().Nodes {
+		var nodeTypes []string
+		for _, t := range node.Types {
+			if slices.Contains(diagnosedNodeTypes, t) {
+				nodeTypes = append(nodeTypes, string(t))
+			}
+		}
+		if len(nodeTypes) > 0 {
+			peers = append(peers, NetworkPeerStatus{PeerID: node.PeerId, Types: nodeTypes, Addresses: node.Addresses})
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := range peers {
+		wg.Add(1)
+		go func(p *NetworkPeerStatus) {
+			defer wg.Done()
+			if connPool == nil {
+				p.Error = "no connection pool"
+				return
+			}
+			dialCtx, cancel := context.WithTimeout(ctx, h.dialTimeout)
+			defer cancel()
+			start := time.Now()
+			if _, err := connPool.Get(dialCtx, p.PeerID); err != nil {
+				p.Error = err.Error()
+				return
+			}
+			p.Reachable = true
+			p.LatencyMs = time.Since(start).Milliseconds()
+		}(&peers[i])
+	}
+	wg.Wait()
+	return peers
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/anyproto/any-sync/net/pool"
+	"github.com/anyproto/any-sync/net/pool/mock_pool"
+	"github.com/anyproto/any-sync/nodeconf"
+	"github.com/anyproto/any-sync/nodeconf/mock_nodeconf"
+	"github.com/matou-dao/backend/internal/anysync"
+	"github.com/matou-dao/backend/internal/contributions"
+	"go.uber.org/mock/gomock"
+)
+
+// mockAnySyncClientForNetwork serves a mock pool and network config.
+type mockAnySyncClientForNetwork struct {
+	mockAnySyncClientForIntegration
+	pool     pool.Pool
+	nodeConf nodeconf.Service
+	pingErr  error
+}
+
+func (m *mockAnySyncClientForNetwork) GetPool() pool.Pool            { return m.pool }
+func (m *mockAnySyncClientForNetwork) GetNodeConf() nodeconf.Service { return m.nodeConf }
+func (m *mockAnySyncClientForNetwork) Ping() error                   { return m.pingErr }
+
+func TestNetworkDebug_MixedReachability(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	nodeConf := mock_nodeconf.NewMockService(ctrl)
+	nodeConf.EXPECT().Configuration().Return(nodeconf.Configuration{Nodes: []nodeconf.Node{
+		{PeerId: "tree-1", Addresses: []string{"127.0.0.1:1001"}, Types: []nodeconf.NodeType{nodeconf.NodeTypeTree}},
+		{PeerId: "tree-2", Addresses: []string{"127.0.0.1:1002"}, Types: []nodeconf.NodeType{nodeconf.NodeTypeTree}},
+		{PeerId: "coordinator", Addresses: []string{"127.0.0.1:1004"}, Types: []nodeconf.NodeType{nodeconf.NodeTypeCoordinator}},
+		{PeerId: "file-and-consensus", Addresses: []string{"127.0.0.1:1005"}, Types: []nodeconf.NodeType{nodeconf.NodeTypeFile, nodeconf.NodeTypeConsensus}},
+	}}).AnyTimes()
+
+	connPool := mock_pool.NewMockPool(ctrl)
+	connPool.EXPECT().Get(gomock.Any(), "tree-1").Return(nil, nil)
+	connPool.EXPECT().Get(gomock.Any(), "tree-2").Return(nil, errors.New("connection refused"))
+	connPool.EXPECT().Get(gomock.Any(), "file-and-consensus").Return(nil, nil)
+
+	client := &mockAnySyncClientForNetwork{pool: connPool, nodeConf: nodeConf, pingErr: errors.New("coordinator timeout")}
+	handler := NewNetworkDebugHandler(anysync.NewSpaceManager(client, &anysync.SpaceManagerConfig{}))
+	roleLookup := &mockRoleLookup{roles: map[string][]contributions.Role{
+		adminTestAdminAID:  contributions.MapKERIRole("Founding Member"),
+		adminTestMemberAID: contributions.MapKERIRole("Member"),
+	}}
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux, roleLookup)
+
+	get := func(aid string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/debug/network", nil)
+		req.Header.Set("X-User-AID", aid)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get(adminTestMemberAID); w.Code != http.StatusForbidden {
+		t.Fatalf("member: expected 403, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w := get(adminTestAdminAID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("admin: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp NetworkDiagnostics
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+
+	if resp.NetworkID != "test-network" || resp.CoordinatorURL != "http://localhost:1004" || resp.PeerID != "test-peer-123" {
+		t.Errorf("unexpected identity: %+v", resp)
+	}
+	if resp.LastPing == nil || resp.LastPing.Reachable || resp.LastPing.Error != "coordinator timeout" {
+		t.Errorf("expected the failed ping to be reported, got %+v", resp.LastPing)
+	}
+
+	want := map[string]bool{"tree-1": true, "tree-2": false, "file-and-consensus": true}
+	if len(resp.Peers) != len(want) {
+		t.Fatalf("expected %d peers (coordinator excluded), got %+v", len(want), resp.Peers)
+	}
+	for _, p := range resp.Peers {
+		reachable, ok := want[p.PeerID]
+		if !ok {
+			t.Errorf("unexpected peer %s", p.PeerID)
+			continue
+		}
+		if p.Reachable != reachable {
+			t.Errorf("peer %s: reachable = %v, want %v (error %q)", p.PeerID, p.Reachable, reachable, p.Error)
+		}
+		if !reachable && p.Error != "connection refused" {
+			t.Errorf("peer %s: expected the dial error, got %q", p.PeerID, p.Error)
+		}
+	}
+	if types := resp.Peers[2].Types; len(types) != 2 || types[0] != "file" || types[1] != "consensus" {
+		t.Errorf("unexpected types for the shared node: %v", types)
+	}
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/matou-dao/backend/internal/anystore"
+	"github.com/matou-dao/backend/internal/anysync"
+)
+
+// SetStore enables counting notice reactions and RSVPs from the local
+// anystore index (?countsOnly=true) instead of reading every interaction
+// tree. Interactions written through this handler are indexed as they are
+// written, and those arriving from peers by the tree listener; the first
+// counts request in a space backfills the index from the trees.
+func (h *NoticesHandler) SetStore(store *anystore.LocalStore) {
+	h.store = store
+}
+
+// countsIndex returns the index to answer a countsOnly request from, or nil
+// to read the trees instead.
+func (h *NoticesHandler) countsIndex(r *http.Request, spaceID string, countsOnly bool) *anystore.LocalStore {
+	if !countsOnly {
+		return nil
+	}
+	return h.interactionIndex(r.Context(), spaceID)
+}
+
+// interactionIndex returns the store once the interactions in spaceID have
+// been indexed, backfilling them on first use. It returns nil without a store
+// or if the backfill fails, and callers fall back to a full scan.
+func (h *NoticesHandler) interactionIndex(ctx context.Context, spaceID string) *anystore.LocalStore {
+	if h.store == nil {
+		return nil
+	}
+	h.indexMu.Lock()
+	defer h.indexMu.Unlock()
+	if h.indexedSpace == spaceID {
+		return h.store
+	}
+
+	noticeMgr := h.spaceManager.NoticeTreeManager()
+	reactions, err := noticeMgr.ReadAllReactions(ctx, spaceID)
+	if err != nil {
+		log.Printf("[Notices] Warning: failed to index reactions: %v", err)
+		return nil
+	}
+	rsvps, err := noticeMgr.ReadAllRSVPs(ctx, spaceID)
+	if err != nil {
+		log.Printf("[Notices] Warning: failed to index RSVPs: %v", err)
+		return nil
+	}
+	for _, reaction := range reactions {
+		if err := h.store.UpsertNoticeReaction(ctx, toStoreReaction(reaction)); err != nil {
+			log.Printf("[Notices] Warning: failed to index reaction %s: %v", reaction.ID, err)
+			return nil
+		}
+	}
+	for _, rsvp := range rsvps {
+		if err := h.store.UpsertNoticeRSVP(ctx, toStoreRSVP(rsvp)); err != nil {
+			log.Printf("[Notices] Warning: failed to index RSVP %s: %v", rsvp.ID, err)
+			return nil
+		}
+	}
+	log.Printf("[Notices] Indexed %d reactions and %d RSVPs in space %s", len(reactions), len(rsvps), spaceID)
+	h.indexedSpace = spaceID
+	return h.store
+}
+
+// indexReaction writes a reaction just written to its tree to the index.
+func (h *NoticesHandler) indexReaction(ctx context.Context, reaction *anysync.NoticeReactionPayload) {
+	if h.store == nil {
+		return
+	}
+	h.indexMu.Lock()
+	defer h.indexMu.Unlock()
+	if err := h.store.UpsertNoticeReaction(ctx, toStoreReaction(reaction)); err != nil {
+		log.Printf("[Notices] Warning: failed to index reaction %s: %v", reaction.ID, err)
+	}
+}
+
+// indexRSVP writes an RSVP just written to its tree to the index.
+func (h *NoticesHandler) indexRSVP(ctx context.Context, rsvp *anysync.NoticeRSVPPayload) {
+	if h.store == nil {
+		return
+	}
+	h.indexMu.Lock()
+	defer h.indexMu.Unlock()
+	if err := h.store.UpsertNoticeRSVP(ctx, toStoreRSVP(rsvp)); err != nil {
+		log.Printf("[Notices] Warning: failed to index RSVP %s: %v", rsvp.ID, err)
+	}
+}
+
+func toStoreReaction(r *anysync.NoticeReactionPayload) *anystore.NoticeReaction {
+	return &anystore.NoticeReaction{
+		ID:        r.ID,
+		NoticeID:  r.NoticeID,
+		UserID:    r.UserID,
+		Emoji:     r.Emoji,
+		Active:    r.Active,
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+func toStoreRSVP(r *anysync.NoticeRSVPPayload) *anystore.NoticeRSVP {
+	return &anystore.NoticeRSVP{
+		ID:             r.ID,
+		NoticeID:       r.NoticeID,
+		OccurrenceDate: r.OccurrenceDate,
+		UserID:         r.UserID,
+		Status:         r.Status,
+		UpdatedAt:      r.UpdatedAt,
+	}
+}
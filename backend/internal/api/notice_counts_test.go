@@ -0,0 +1,120 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/matou-dao/backend/internal/anystore"
+)
+
+type interactionCounts struct {
+	Reactions map[string]int
+	RSVPs     map[string]int
+	RSVPCount int
+}
+
+// getInteractionCounts reads a notice's reaction and RSVP counts, failing if
+// a countsOnly response still carries the full lists.
+func getInteractionCounts(t *testing.T, env *noticesTestEnv, noticeID string, countsOnly bool) interactionCounts {
+	t.Helper()
+	query := ""
+	if countsOnly {
+		query = "?countsOnly=true"
+	}
+
+	var reactions struct {
+		Reactions json.RawMessage `json:"reactions"`
+		Counts    map[string]int  `json:"counts"`
+	}
+	w := env.do(t, http.MethodGet, "/api/v1/notices/"+noticeID+"/reactions"+query, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("list reactions: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	json.NewDecoder(w.Body).Decode(&reactions)
+
+	var rsvps struct {
+		RSVPs  json.RawMessage `json:"rsvps"`
+		Count  int             `json:"count"`
+		Counts map[string]int  `json:"counts"`
+	}
+	w = env.do(t, http.MethodGet, "/api/v1/notices/"+noticeID+"/rsvp"+query, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("list RSVPs: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	json.NewDecoder(w.Body).Decode(&rsvps)
+
+	if countsOnly && (reactions.Reactions != nil || rsvps.RSVPs != nil) {
+		t.Errorf("expected countsOnly to omit the lists, got reactions=%s rsvps=%s", reactions.Reactions, rsvps.RSVPs)
+	}
+	return interactionCounts{Reactions: reactions.Counts, RSVPs: rsvps.Counts, RSVPCount: rsvps.Count}
+}
+
+func interact(t *testing.T, env *noticesTestEnv, noticeID, aid string, emojis []string, status string) {
+	t.Helper()
+	env.userIdentity.SetIdentity(aid, aid+"-mnemonic")
+	for _, emoji := range emojis {
+		if w := env.do(t, http.MethodPost, "/api/v1/notices/"+noticeID+"/reactions", ReactionRequest{Emoji: emoji}); w.Code != http.StatusOK {
+			t.Fatalf("reaction by %s: expected 200, got %d: %s", aid, w.Code, w.Body.String())
+		}
+	}
+	if status != "" {
+		if w := env.do(t, http.MethodPost, "/api/v1/notices/"+noticeID+"/rsvp", RSVPRequest{Status: status}); w.Code != http.StatusOK {
+			t.Fatalf("RSVP by %s: expected 200, got %d: %s", aid, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestNoticeCounts_IndexMatchesFullScan(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	noticeID := createTestNotice(t, env, map[string]interface{}{
+		"type": "event", "title": "Hui", "summary": "Monthly hui", "rsvpEnabled": true,
+	})
+	other := createTestNotice(t, env, map[string]interface{}{"type": "announcement", "title": "Other", "summary": "Another notice"})
+
+	// Written before the index exists: counted by the backfill
+	interact(t, env, noticeID, "EALICE", []string{"👍", "🎉"}, "going")
+	interact(t, env, noticeID, "EBOB", []string{"👍", "👍"}, "maybe") // toggled off again
+	interact(t, env, noticeID, "ECAROL", []string{"🎉"}, "going")
+	interact(t, env, other, "EALICE", []string{"👍"}, "")
+
+	scanned := getInteractionCounts(t, env, noticeID, true)
+	want := interactionCounts{
+		Reactions: map[string]int{"👍": 1, "🎉": 2},
+		RSVPs:     map[string]int{"going": 2, "maybe": 1, "not_going": 0, "waitlisted": 0},
+		RSVPCount: 3,
+	}
+	if !reflect.DeepEqual(scanned, want) {
+		t.Fatalf("full scan counts = %+v, want %+v", scanned, want)
+	}
+
+	store, err := anystore.NewLocalStore(anystore.DefaultConfig(t.TempDir()))
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer store.Close()
+	if err := store.EnsureNoticeIndexes(t.Context()); err != nil {
+		t.Fatalf("failed to create indexes: %v", err)
+	}
+	env.handler.SetStore(store)
+
+	if indexed := getInteractionCounts(t, env, noticeID, true); !reflect.DeepEqual(indexed, scanned) {
+		t.Errorf("indexed counts after backfill = %+v, full scan %+v", indexed, scanned)
+	}
+
+	// Written once indexed: counted as they are written
+	interact(t, env, noticeID, "EBOB", []string{"🎉"}, "going")
+	interact(t, env, noticeID, "EALICE", []string{"👍"}, "not_going")
+	interact(t, env, noticeID, "EDAVE", []string{"👍"}, "maybe")
+
+	full := getInteractionCounts(t, env, noticeID, false)
+	if indexed := getInteractionCounts(t, env, noticeID, true); !reflect.DeepEqual(indexed, full) {
+		t.Errorf("indexed counts = %+v, full scan %+v", indexed, full)
+	}
+	if full.Reactions["🎉"] != 3 || full.RSVPs["going"] != 2 || full.RSVPCount != 4 {
+		t.Errorf("unexpected counts after the later writes: %+v", full)
+	}
+}
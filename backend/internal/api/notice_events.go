@@ -0,0 +1,41 @@
+package api
+
+// SSE event types broadcast by NoticesHandler. Every event's data carries the
+// noticeId it concerns, except notice_pins_reordered, which carries the new
+// order as noticeIds. Saves live in the caller's private space and aren't
+// broadcast.
+const (
+	// Notice lifecycle
+	EventNoticeCreated       = "notice_created"        // new notice, in any state
+	EventNoticeUpdated       = "notice_updated"        // notice fields edited
+	EventNoticePublished     = "notice_published"      // draft or archived notice published
+	EventNoticeUnpublished   = "notice_unpublished"    // published notice taken back to draft
+	EventNoticeArchived      = "notice_archived"       // published notice archived
+	EventNoticePinned        = "notice_pinned"         // notice pinned to the top of the board
+	EventNoticeUnpinned      = "notice_unpinned"       // notice unpinned
+	EventNoticePinsReordered = "notice_pins_reordered" // pinned notices reordered by an admin
+
+	// Member interactions
+	EventNoticeRSVP           = "notice_rsvp"            // RSVP created or changed
+	EventNoticeRSVPPromoted   = "notice_rsvp_promoted"   // waitlisted RSVP moved to going
+	EventNoticeAck            = "notice_ack"             // acknowledgement recorded by a member or an admin
+	EventNoticeAckOverdue     = "notice_ack_overdue"     // ack deadline passed with members pending
+	EventNoticeComment        = "notice_comment"         // comment or reply posted
+	EventNoticeCommentReply   = "notice_comment_reply"   // reply posted, for the parent comment's author
+	EventNoticeCommentMention = "notice_comment_mention" // comment posted, for the members it mentions
+	EventNoticeCommentDeleted = "notice_comment_deleted" // comment soft-deleted
+	EventNoticeReaction       = "notice_reaction"        // reaction toggled
+)
+
+// noticeStateEvent returns the event type for a notice moving into state.
+func noticeStateEvent(state string) string {
+	switch state {
+	case "published":
+		return EventNoticePublished
+	case "draft":
+		return EventNoticeUnpublished
+	case "archived":
+		return EventNoticeArchived
+	}
+	return "notice_" + state
+}
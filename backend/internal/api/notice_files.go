@@ -0,0 +1,112 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/matou-dao/backend/internal/anysync"
+)
+
+// Caps on the files one notice may reference.
+const (
+	maxNoticeImages      = 10
+	maxNoticeAttachments = 20
+)
+
+// NoticeAttachment is one entry of a notice's attachments: a file uploaded
+// through POST /api/v1/files and served by GET /api/v1/files/{fileRef}.
+type NoticeAttachment struct {
+	Name     string `json:"name"`
+	FileRef  string `json:"fileRef"`
+	MimeType string `json:"mimeType"`
+	Size     int64  `json:"size"`
+}
+
+// SetFileStore gives notice create and update a filenode to resolve images
+// and attachments against, so their names, types and sizes come from the
+// upload rather than the request. Until it is set, a notice with any files
+// gets 503; text-only notices are unaffected.
+func (h *NoticesHandler) SetFileStore(fileManager *anysync.FileManager) {
+	if fileManager != nil {
+		h.files = fileManager
+	}
+}
+
+// normalizeNoticeFiles checks a notice's images (a list of file refs, each an
+// uploaded image) and attachments (a list of NoticeAttachment) name files
+// uploaded to spaceID, and returns them rewritten from the stored metadata.
+// A nil field is returned as nil. On failure it writes the error response and
+// returns false.
+func (h *NoticesHandler) normalizeNoticeFiles(ctx context.Context, w http.ResponseWriter, spaceID string, images, attachments json.RawMessage) (json.RawMessage, json.RawMessage, bool) {
+	var imageRefs []string
+	if !isJSONNull(images) {
+		if err := json.Unmarshal(images, &imageRefs); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "images must be a list of file refs"})
+			return nil, nil, false
+		}
+	}
+	var files []NoticeAttachment
+	if !isJSONNull(attachments) {
+		if err := json.Unmarshal(attachments, &files); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "attachments must be a list of {name, fileRef, mimeType, size}"})
+			return nil, nil, false
+		}
+	}
+	if len(imageRefs) > maxNoticeImages {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("a notice can have at most %d images", maxNoticeImages)})
+		return nil, nil, false
+	}
+	if len(files) > maxNoticeAttachments {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("a notice can have at most %d attachments", maxNoticeAttachments)})
+		return nil, nil, false
+	}
+	if len(imageRefs)+len(files) > 0 && h.files == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"error": "file storage not available (filenode not configured)",
+		})
+		return nil, nil, false
+	}
+
+	if imageRefs != nil {
+		normalized := make([]string, 0, len(imageRefs))
+		for i, ref := range imageRefs {
+			meta, err := h.noticeImageMeta(ctx, spaceID, ref)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("images[%d]: %v", i, err)})
+				return nil, nil, false
+			}
+			normalized = append(normalized, meta.CID)
+		}
+		images, _ = json.Marshal(normalized)
+	}
+	if files != nil {
+		normalized := make([]NoticeAttachment, 0, len(files))
+		for i, f := range files {
+			meta, err := attachmentMeta(ctx, h.files, spaceID, AttachmentRef{FileRef: f.FileRef, ContentType: f.MimeType, Size: f.Size})
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("attachments[%d]: %v", i, err)})
+				return nil, nil, false
+			}
+			normalized = append(normalized, NoticeAttachment{
+				Name:     attachmentFileName(f.Name, meta.CID),
+				FileRef:  meta.CID,
+				MimeType: meta.ContentType,
+				Size:     meta.Size,
+			})
+		}
+		attachments, _ = json.Marshal(normalized)
+	}
+	return images, attachments, true
+}
+
+// noticeImageMeta resolves an image ref and checks the file is an image.
+func (h *NoticesHandler) noticeImageMeta(ctx context.Context, spaceID, ref string) (*anysync.FileMeta, error) {
+	return imageFileMeta(ctx, h.files, spaceID, ref)
+}
+
+// isJSONNull reports whether raw is absent or a JSON null.
+func isJSONNull(raw json.RawMessage) bool {
+	return len(raw) == 0 || string(raw) == "null"
+}
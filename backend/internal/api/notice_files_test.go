@@ -0,0 +1,133 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// uploadNoticeFile uploads data through the files endpoint and returns its ref.
+func uploadNoticeFile(t *testing.T, files *FilesHandler, name, contentType string, data []byte) AttachmentRef {
+	t.Helper()
+	w := httptest.NewRecorder()
+	files.HandleUpload(w, multipartUpload(t, name, contentType, data))
+	if w.Code != http.StatusOK {
+		t.Fatalf("upload %s: %d %s", name, w.Code, w.Body.String())
+	}
+	var ref AttachmentRef
+	json.NewDecoder(w.Body).Decode(&ref)
+	return ref
+}
+
+func TestHandleCreateNotice_UploadedImageAndAttachment(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+	store := newMockFileStore()
+	env.handler.files = store
+	files := NewFilesHandler(nil, env.spaceManager)
+	files.files = store
+
+	photo := testJPEG(t, 40, 20)
+	image := uploadNoticeFile(t, files, "photo.jpg", "image/jpeg", photo)
+	agenda := uploadNoticeFile(t, files, "agenda.pdf", "application/pdf", []byte("%PDF-1.4 agenda"))
+
+	noticeID := createTestNotice(t, env, map[string]interface{}{
+		"type":    "event",
+		"title":   "Hui",
+		"summary": "Monthly gathering",
+		"images":  []string{image.FileRef},
+		"attachments": []NoticeAttachment{
+			{Name: "../agenda.pdf", FileRef: agenda.FileRef, MimeType: "application/pdf", Size: agenda.Size},
+		},
+	})
+
+	notice, err := env.spaceManager.NoticeTreeManager().ReadNotice(t.Context(), env.spaceManager.GetCommunitySpaceID(), noticeID)
+	if err != nil {
+		t.Fatalf("reading notice: %v", err)
+	}
+	var images []string
+	json.Unmarshal(notice.Images, &images)
+	if len(images) != 1 || images[0] != image.FileRef {
+		t.Fatalf("expected the image ref stored, got %s", notice.Images)
+	}
+	var attachments []NoticeAttachment
+	json.Unmarshal(notice.Attachments, &attachments)
+	want := NoticeAttachment{Name: "agenda.pdf", FileRef: agenda.FileRef, MimeType: "application/pdf", Size: agenda.Size}
+	if len(attachments) != 1 || attachments[0] != want {
+		t.Fatalf("expected attachment %+v, got %s", want, notice.Attachments)
+	}
+
+	// The stored refs resolve through the files endpoint
+	w := httptest.NewRecorder()
+	files.HandleDownload(w, httptest.NewRequest(http.MethodGet, "/api/v1/files/"+images[0], nil))
+	if w.Code != http.StatusOK || !bytes.Equal(w.Body.Bytes(), photo) {
+		t.Errorf("downloading image: got %d (%d bytes)", w.Code, w.Body.Len())
+	}
+	if got := w.Header().Get("Content-Type"); got != "image/jpeg" {
+		t.Errorf("image Content-Type = %q, want image/jpeg", got)
+	}
+}
+
+func TestHandleCreateNotice_InvalidFiles(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+	store := newMockFileStore()
+	env.handler.files = store
+	files := NewFilesHandler(nil, env.spaceManager)
+	files.files = store
+
+	pdf := uploadNoticeFile(t, files, "agenda.pdf", "application/pdf", []byte("%PDF-1.4 agenda"))
+	const missingRef = "bafkreigh2akiscaildcqabsyg3dfr6chu3fgpregiymsck7e7aqa4s52zy"
+
+	tests := []struct {
+		name      string
+		files     map[string]interface{}
+		wantError string
+	}{
+		{"image ref is not a CID", map[string]interface{}{"images": []string{"https://example.com/a.png"}}, "not a valid CID"},
+		{"image not uploaded", map[string]interface{}{"images": []string{missingRef}}, "not found"},
+		{"image is not an image", map[string]interface{}{"images": []string{pdf.FileRef}}, "not an image"},
+		{"images not a list", map[string]interface{}{"images": "photo.jpg"}, "list of file refs"},
+		{"attachment size mismatch", map[string]interface{}{"attachments": []NoticeAttachment{
+			{Name: "agenda.pdf", FileRef: pdf.FileRef, MimeType: "application/pdf", Size: pdf.Size + 1},
+		}}, "size"},
+		{"attachment type mismatch", map[string]interface{}{"attachments": []NoticeAttachment{
+			{Name: "agenda.pdf", FileRef: pdf.FileRef, MimeType: "image/png", Size: pdf.Size},
+		}}, "content type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := map[string]interface{}{"type": "update", "title": "Files", "summary": "With files"}
+			for k, v := range tt.files {
+				body[k] = v
+			}
+			w := env.do(t, http.MethodPost, "/api/v1/notices", body)
+			if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), tt.wantError) {
+				t.Errorf("expected 400 containing %q, got %d %s", tt.wantError, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	// Edits are checked the same way
+	noticeID := createTestNotice(t, env, map[string]interface{}{"type": "update", "title": "Files", "summary": "No files yet"})
+	w := env.do(t, http.MethodPut, "/api/v1/notices/"+noticeID, map[string]interface{}{"images": []string{pdf.FileRef}})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("editing in a non-image: expected 400, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleCreateNotice_FilesWithoutFileStore(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	w := env.do(t, http.MethodPost, "/api/v1/notices", map[string]interface{}{
+		"type": "update", "title": "Files", "summary": "With files",
+		"images": []string{"bafkreigh2akiscaildcqabsyg3dfr6chu3fgpregiymsck7e7aqa4s52zy"},
+	})
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 without a file store, got %d %s", w.Code, w.Body.String())
+	}
+}
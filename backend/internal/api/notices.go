@@ -1,14 +1,25 @@
 package api
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"math"
 	"net/http"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/anyproto/any-sync/util/crypto"
+	"github.com/matou-dao/backend/internal/anystore"
 	"github.com/matou-dao/backend/internal/anysync"
+	"github.com/matou-dao/backend/internal/contributions"
 	"github.com/matou-dao/backend/internal/identity"
 	"github.com/matou-dao/backend/internal/types"
 )
@@ -18,6 +29,26 @@ type NoticesHandler struct {
 	spaceManager *anysync.SpaceManager
 	userIdentity *identity.UserIdentity
 	eventBroker  *EventBroker
+	idempotency  *IdempotencyGuard
+	profiles     *ProfileResolver
+	files        fileStore   // resolves image and attachment refs (see SetFileStore)
+	quarantine   *Quarantine // records objects skipped on read (see SetQuarantine)
+
+	// Interaction count index (see SetStore)
+	store        *anystore.LocalStore
+	indexMu      sync.Mutex
+	indexedSpace string // community space whose interactions are backfilled
+
+	// Size limits in UTF-8 bytes; 0 uses the defaults
+	maxBodyBytes    int
+	maxCommentBytes int
+
+	// Overdue-ack checker state
+	mu              sync.Mutex
+	overdueNotified map[string]bool // notice IDs already reported as overdue
+	ackLoop         backgroundLoop
+
+	publishLoop backgroundLoop // scheduled publisher
 }
 
 // NewNoticesHandler creates a new notices handler.
@@ -27,17 +58,41 @@ func NewNoticesHandler(
 	eventBroker *EventBroker,
 ) *NoticesHandler {
 	return &NoticesHandler{
-		spaceManager: spaceManager,
-		userIdentity: userIdentity,
-		eventBroker:  eventBroker,
+		spaceManager:    spaceManager,
+		userIdentity:    userIdentity,
+		eventBroker:     eventBroker,
+		overdueNotified: make(map[string]bool),
+		profiles:        NewProfileResolver(spaceManager),
 	}
 }
 
+// SetContentLimits sets the largest notice body and comment text accepted,
+// in UTF-8 bytes. A limit of zero uses DefaultMaxNoticeBodyBytes or
+// DefaultMaxCommentBytes.
+func (h *NoticesHandler) SetContentLimits(maxBodyBytes, maxCommentBytes int) {
+	h.maxBodyBytes = maxBodyBytes
+	h.maxCommentBytes = maxCommentBytes
+}
+
+// SetProfileResolver shares a profile resolver (and its cache) with other
+// handlers. By default the handler uses its own.
+func (h *NoticesHandler) SetProfileResolver(p *ProfileResolver) {
+	h.profiles = p
+}
+
+// SetIdempotencyGuard enables Idempotency-Key handling on notice and RSVP creation.
+func (h *NoticesHandler) SetIdempotencyGuard(g *IdempotencyGuard) {
+	h.idempotency = g
+}
+
 // RegisterRoutes registers notice routes on the mux.
-func (h *NoticesHandler) RegisterRoutes(mux *http.ServeMux) {
-	mux.HandleFunc("/api/v1/notices", h.handleNotices)
-	mux.HandleFunc("/api/v1/notices/saved", h.HandleListSaved)
-	mux.HandleFunc("/api/v1/notices/", h.handleNoticeByID)
+// Caller roles are resolved from X-User-AID when present so that admin-only
+// operations (e.g. editing another member's notice) can be authorised.
+func (h *NoticesHandler) RegisterRoutes(mux *http.ServeMux, roleLookup RoleLookup) {
+	mux.HandleFunc("/api/v1/notices", OptionalRBACMiddleware(roleLookup, h.handleNotices))
+	mux.HandleFunc("/api/v1/notices/saved", OptionalRBACMiddleware(roleLookup, h.HandleListSaved))
+	mux.HandleFunc("/api/v1/notices/pin-order", OptionalRBACMiddleware(roleLookup, h.HandleReorderPins))
+	mux.HandleFunc("/api/v1/notices/", OptionalRBACMiddleware(roleLookup, h.handleNoticeByID))
 }
 
 // handleNotices routes /api/v1/notices requests.
@@ -46,7 +101,7 @@ func (h *NoticesHandler) handleNotices(w http.ResponseWriter, r *http.Request) {
 	case http.MethodGet:
 		h.HandleListNotices(w, r)
 	case http.MethodPost:
-		h.HandleCreateNotice(w, r)
+		h.idempotency.Wrap(h.HandleCreateNotice)(w, r)
 	default:
 		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
 	}
@@ -56,7 +111,7 @@ func (h *NoticesHandler) handleNotices(w http.ResponseWriter, r *http.Request) {
 func (h *NoticesHandler) handleNoticeByID(w http.ResponseWriter, r *http.Request) {
 	// Parse: /api/v1/notices/{id} or /api/v1/notices/{id}/{action}
 	path := strings.TrimPrefix(r.URL.Path, "/api/v1/notices/")
-	if path == "" || path == "saved" {
+	if path == "" || path == "saved" || path == "pin-order" {
 		return // handled by other routes
 	}
 
@@ -64,25 +119,55 @@ func (h *NoticesHandler) handleNoticeByID(w http.ResponseWriter, r *http.Request
 	noticeID := parts[0]
 
 	if len(parts) == 1 {
-		// GET /api/v1/notices/{id}
-		if r.Method != http.MethodGet {
+		// GET/PUT /api/v1/notices/{id}
+		switch r.Method {
+		case http.MethodGet:
+			h.HandleGetNotice(w, r, noticeID)
+		case http.MethodPut:
+			h.HandleUpdateNotice(w, r, noticeID)
+		default:
 			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
-			return
 		}
-		h.HandleGetNotice(w, r, noticeID)
 		return
 	}
 
-	action := parts[1]
+	// Actions may carry a sub-resource ID, e.g. comments/{commentId}
+	action, subID, _ := strings.Cut(parts[1], "/")
+	if subID != "" {
+		switch {
+		case action == "ack" && subID == "pending" && r.Method == http.MethodGet:
+			h.HandleListPendingAcks(w, r, noticeID)
+		case action == "ack" && subID == "pending":
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		case action == "ack" && subID == "bulk" && r.Method == http.MethodPost:
+			h.HandleBulkAck(w, r, noticeID)
+		case action == "ack" && subID == "summary" && r.Method == http.MethodGet:
+			h.HandleAckSummary(w, r, noticeID)
+		case action == "ack" && (subID == "bulk" || subID == "summary"):
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		case action == "comments" && r.Method == http.MethodDelete:
+			h.HandleDeleteComment(w, r, noticeID, subID)
+		case action == "comments":
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		default:
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown action"})
+		}
+		return
+	}
+
 	switch action {
 	case "publish":
 		h.HandlePublishNotice(w, r, noticeID)
 	case "archive":
 		h.HandleArchiveNotice(w, r, noticeID)
+	case "unpublish":
+		h.HandleUnpublishNotice(w, r, noticeID)
 	case "rsvp":
 		switch r.Method {
 		case http.MethodPost:
-			h.HandleCreateRSVP(w, r, noticeID)
+			h.idempotency.Wrap(func(w http.ResponseWriter, r *http.Request) {
+				h.HandleCreateRSVP(w, r, noticeID)
+			})(w, r)
 		case http.MethodGet:
 			h.HandleListRSVPs(w, r, noticeID)
 		default:
@@ -149,6 +234,12 @@ type CreateNoticeRequest struct {
 	AckDueAt     string          `json:"ackDueAt,omitempty"`
 	ActiveFrom   string          `json:"activeFrom,omitempty"`
 	ActiveUntil  string          `json:"activeUntil,omitempty"`
+	// PublishAt schedules a draft to be published by the scheduled publisher
+	// (see StartScheduledPublisher) once the time arrives. RFC3339.
+	PublishAt string `json:"publishAt,omitempty"`
+
+	// Recurrence makes an event repeat; see anysync.NoticeRecurrence.
+	Recurrence *anysync.NoticeRecurrence `json:"recurrence,omitempty"`
 }
 
 // HandleCreateNotice handles POST /api/v1/notices.
@@ -183,6 +274,9 @@ func (h *NoticesHandler) HandleCreateNotice(w http.ResponseWriter, r *http.Reque
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "summary is required"})
 		return
 	}
+	if !checkContentSize(w, "body", req.Body, h.maxBodyBytes, DefaultMaxNoticeBodyBytes) {
+		return
+	}
 
 	// Default state
 	if req.State == "" {
@@ -193,11 +287,31 @@ func (h *NoticesHandler) HandleCreateNotice(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Get user identity
-	aid := ""
-	if h.userIdentity != nil {
-		aid = h.userIdentity.GetAID()
+	if err := validateEventTimes(req.EventStart, req.EventEnd, req.Timezone); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	if req.PublishAt != "" {
+		if _, err := time.Parse(time.RFC3339, req.PublishAt); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "publishAt must be an RFC3339 timestamp"})
+			return
+		}
+		if req.State != "draft" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "publishAt schedules a draft; create the notice with state 'draft'"})
+			return
+		}
+	}
+
+	if req.Recurrence != nil {
+		if err := validateRecurrence(req.Recurrence, req.Type, req.EventStart); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
 	}
+
+	// Get user identity
+	aid := callerAID(r.Context(), h.userIdentity)
 	if aid == "" {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Identity not configured"})
 		return
@@ -210,6 +324,12 @@ func (h *NoticesHandler) HandleCreateNotice(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	// Images and attachments must be files uploaded to the community space
+	images, attachments, ok := h.normalizeNoticeFiles(r.Context(), w, spaceID, req.Images, req.Attachments)
+	if !ok {
+		return
+	}
+
 	// Get signing key
 	client := h.spaceManager.GetClient()
 	if client == nil {
@@ -228,7 +348,7 @@ func (h *NoticesHandler) HandleCreateNotice(w http.ResponseWriter, r *http.Reque
 	// Generate notice ID
 	noticeID := req.ID
 	if noticeID == "" {
-		noticeID = fmt.Sprintf("%d", time.Now().UnixMilli())
+		noticeID = newObjectID("", aid)
 	}
 
 	now := time.Now().UTC().Format(time.RFC3339)
@@ -240,8 +360,8 @@ func (h *NoticesHandler) HandleCreateNotice(w http.ResponseWriter, r *http.Reque
 		Summary:      req.Summary,
 		Body:         req.Body,
 		Links:        req.Links,
-		Images:       req.Images,
-		Attachments:  req.Attachments,
+		Images:       images,
+		Attachments:  attachments,
 		IssuerType:   "person",
 		IssuerID:     aid,
 		AudienceMode: "community",
@@ -261,6 +381,8 @@ func (h *NoticesHandler) HandleCreateNotice(w http.ResponseWriter, r *http.Reque
 		AckDueAt:     req.AckDueAt,
 		ActiveFrom:   req.ActiveFrom,
 		ActiveUntil:  req.ActiveUntil,
+		PublishAt:    req.PublishAt,
+		Recurrence:   req.Recurrence,
 	}
 
 	if req.State == "published" {
@@ -280,7 +402,7 @@ func (h *NoticesHandler) HandleCreateNotice(w http.ResponseWriter, r *http.Reque
 	// Broadcast SSE event
 	if h.eventBroker != nil {
 		h.eventBroker.Broadcast(SSEEvent{
-			Type: "notice_created",
+			Type: EventNoticeCreated,
 			Data: map[string]interface{}{
 				"noticeId": noticeID,
 				"type":     req.Type,
@@ -299,26 +421,47 @@ func (h *NoticesHandler) HandleCreateNotice(w http.ResponseWriter, r *http.Reque
 	})
 }
 
+// maxNoticesPageSize caps the limit query parameter on the notices list.
+const maxNoticesPageSize = 100
+
 // HandleListNotices handles GET /api/v1/notices.
-// Supports query params: ?view=upcoming|current|past&type=event|update
+// Supports filtering with ?view=upcoming|current|past|mine and
+// ?type=event|update, and cursor pagination via ?limit=N&cursor=<nextCursor>;
+// without a limit all matching notices are returned. The upcoming view
+// expands recurring events into their occurrences within recurrenceHorizon.
 func (h *NoticesHandler) HandleListNotices(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
 		return
 	}
 
+	// Parse pagination params
+	limit, ok := parseListLimit(w, r, 0, maxNoticesPageSize)
+	if !ok {
+		return
+	}
+
 	var spaceID string
 	if h.spaceManager != nil {
 		spaceID = h.spaceManager.GetCommunitySpaceID()
 	}
 	if spaceID == "" {
-		writeJSON(w, http.StatusOK, map[string]interface{}{
+		writeListJSON(w, ListPage{Limit: limit}, map[string]interface{}{
 			"notices": []interface{}{},
 			"count":   0,
 		})
 		return
 	}
 
+	var after *anysync.NoticePayload
+	if c := r.URL.Query().Get("cursor"); c != "" {
+		var err error
+		if after, err = decodeNoticeCursor(c); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid cursor"})
+			return
+		}
+	}
+
 	noticeMgr := h.spaceManager.NoticeTreeManager()
 	notices, err := noticeMgr.ReadNotices(r.Context(), spaceID)
 	if err != nil {
@@ -333,6 +476,11 @@ func (h *NoticesHandler) HandleListNotices(w http.ResponseWriter, r *http.Reques
 	typeFilter := r.URL.Query().Get("type")
 	now := time.Now().UTC()
 
+	if view == "mine" {
+		h.listMyNotices(w, r, spaceID, notices, typeFilter, after, limit)
+		return
+	}
+
 	var filtered []*anysync.NoticePayload
 	for _, n := range notices {
 		// Type filter
@@ -346,20 +494,19 @@ func (h *NoticesHandler) HandleListNotices(w http.ResponseWriter, r *http.Reques
 			if n.Type != "event" || n.State != "published" {
 				continue
 			}
+			if n.Recurrence != nil {
+				filtered = append(filtered, expandOccurrences(n, now, now.Add(recurrenceHorizon))...)
+				continue
+			}
 			if n.EventStart != "" {
 				if t, err := time.Parse(time.RFC3339, n.EventStart); err == nil && t.Before(now) {
 					continue
 				}
 			}
 		case "current":
-			if (n.Type != "update" && n.Type != "announcement") || n.State != "published" {
+			if (n.Type != "update" && n.Type != "announcement") || !noticeCurrent(n, now) {
 				continue
 			}
-			if n.ActiveUntil != "" {
-				if t, err := time.Parse(time.RFC3339, n.ActiveUntil); err == nil && t.Before(now) {
-					continue
-				}
-			}
 		case "past":
 			isPast := n.State == "archived"
 			if !isPast && n.ActiveUntil != "" {
@@ -378,13 +525,132 @@ func (h *NoticesHandler) HandleListNotices(w http.ResponseWriter, r *http.Reques
 	// Sort by view
 	sortNotices(filtered, view)
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"notices": filtered,
-		"count":   len(filtered),
+	page, nextCursor := paginateNotices(filtered, view, after, limit)
+
+	writeListJSON(w, noticesPage(len(filtered), limit, nextCursor), map[string]interface{}{
+		"notices": page,
+		"count":   len(page),
 		"view":    view,
 	})
 }
 
+// MyNotice is a notice in the "mine" view, annotated with the caller's
+// RSVP and save.
+type MyNotice struct {
+	*anysync.NoticePayload
+	// MyRSVPStatus is the caller's latest RSVP to the notice itself.
+	MyRSVPStatus string `json:"myRsvpStatus,omitempty"`
+	// MyRSVPOccurrences lists the occurrences of a recurring event the
+	// caller is going to or might attend.
+	MyRSVPOccurrences []string `json:"myRsvpOccurrences,omitempty"`
+	Saved             bool     `json:"saved"`
+}
+
+// listMyNotices serves ?view=mine: the notices the caller has RSVP'd
+// "going" or "maybe" to (the notice itself or any occurrence) or saved.
+//
+// This joins across spaces. RSVPs live in the community space, alongside
+// the notices, and are matched on the caller's AID; only the latest RSVP per
+// occurrence counts, so changing to "not_going" drops the notice. Saves live
+// in the private space of the node's identity, where a save is current while
+// it's pinned; they only count when the caller is that identity, so another
+// caller never sees the owner's saves. Without a private space only RSVPs
+// are considered. Drafts are listed to their author only.
+func (h *NoticesHandler) listMyNotices(
+	w http.ResponseWriter, r *http.Request, spaceID string,
+	notices []*anysync.NoticePayload, typeFilter string, after *anysync.NoticePayload, limit int,
+) {
+	aid := callerAID(r.Context(), h.userIdentity)
+	noticeMgr := h.spaceManager.NoticeTreeManager()
+
+	// Latest RSVP per notice occurrence ("" for the notice itself)
+	latest := map[string]map[string]*anysync.NoticeRSVPPayload{}
+	if aid != "" {
+		rsvps, err := noticeMgr.ReadUserRSVPs(r.Context(), spaceID, aid)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": fmt.Sprintf("failed to read RSVPs: %v", err),
+			})
+			return
+		}
+		for _, rsvp := range rsvps {
+			byDate := latest[rsvp.NoticeID]
+			if byDate == nil {
+				byDate = map[string]*anysync.NoticeRSVPPayload{}
+				latest[rsvp.NoticeID] = byDate
+			}
+			if prev, ok := byDate[rsvp.OccurrenceDate]; ok && !rsvpUpdatedBefore(prev, rsvp) {
+				continue
+			}
+			byDate[rsvp.OccurrenceDate] = rsvp
+		}
+	}
+
+	saved := map[string]bool{}
+	var privateSpaceID string
+	if h.userIdentity != nil && aid != "" && aid == h.userIdentity.GetAID() {
+		privateSpaceID = h.userIdentity.GetPrivateSpaceID()
+	}
+	if privateSpaceID != "" {
+		saves, err := noticeMgr.ReadSaves(r.Context(), privateSpaceID)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": fmt.Sprintf("failed to read saves: %v", err),
+			})
+			return
+		}
+		for _, save := range saves {
+			if save.Pinned {
+				saved[save.NoticeID] = true
+			}
+		}
+	}
+
+	annotations := map[string]*MyNotice{}
+	var filtered []*anysync.NoticePayload
+	for _, n := range notices {
+		if typeFilter != "" && n.Type != typeFilter {
+			continue
+		}
+		if n.State == "draft" && n.CreatedBy != aid {
+			continue
+		}
+		mine := &MyNotice{NoticePayload: n, Saved: saved[n.ID]}
+		attending := false
+		for date, rsvp := range latest[n.ID] {
+			if date == "" {
+				mine.MyRSVPStatus = rsvp.Status
+			}
+			if rsvp.Status != "going" && rsvp.Status != "maybe" {
+				continue
+			}
+			attending = true
+			if date != "" {
+				mine.MyRSVPOccurrences = append(mine.MyRSVPOccurrences, date)
+			}
+		}
+		if !attending && !mine.Saved {
+			continue
+		}
+		sort.Strings(mine.MyRSVPOccurrences)
+		annotations[n.ID] = mine
+		filtered = append(filtered, n)
+	}
+
+	sortNotices(filtered, "mine")
+	page, nextCursor := paginateNotices(filtered, "mine", after, limit)
+
+	result := make([]*MyNotice, 0, len(page))
+	for _, n := range page {
+		result = append(result, annotations[n.ID])
+	}
+	writeListJSON(w, noticesPage(len(filtered), limit, nextCursor), map[string]interface{}{
+		"notices": result,
+		"count":   len(result),
+		"view":    "mine",
+	})
+}
+
 // HandleGetNotice handles GET /api/v1/notices/{id}.
 func (h *NoticesHandler) HandleGetNotice(w http.ResponseWriter, r *http.Request, noticeID string) {
 	spaceID := h.spaceManager.GetCommunitySpaceID()
@@ -402,38 +668,94 @@ func (h *NoticesHandler) HandleGetNotice(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
-	writeJSON(w, http.StatusOK, notice)
+	writeJSON(w, http.StatusOK, NoticeDetail{
+		NoticePayload:      notice,
+		AllowedTransitions: types.NoticeTransitionsFrom(notice.State),
+	})
 }
 
-// HandlePublishNotice handles POST /api/v1/notices/{id}/publish.
-func (h *NoticesHandler) HandlePublishNotice(w http.ResponseWriter, r *http.Request, noticeID string) {
-	if r.Method != http.MethodPost {
+// NoticeDetail is the response for GET /api/v1/notices/{id}.
+type NoticeDetail struct {
+	*anysync.NoticePayload
+	// AllowedTransitions lists the states the notice can move to next, so
+	// clients only offer valid actions: "published" (POST /publish),
+	// "archived" (POST /archive) and "draft" (POST /unpublish).
+	AllowedTransitions []string `json:"allowedTransitions"`
+}
+
+// UpdateNoticeRequest represents a partial edit of a notice. Nil fields are
+// left unchanged. Lifecycle state is changed via publish/archive, not here.
+type UpdateNoticeRequest struct {
+	Type         *string          `json:"type,omitempty"`
+	Title        *string          `json:"title,omitempty"`
+	Summary      *string          `json:"summary,omitempty"`
+	Body         *string          `json:"body,omitempty"`
+	Links        *json.RawMessage `json:"links,omitempty"`
+	Images       *json.RawMessage `json:"images,omitempty"`
+	Attachments  *json.RawMessage `json:"attachments,omitempty"`
+	Subtype      *string          `json:"subtype,omitempty"`
+	EventStart   *string          `json:"eventStart,omitempty"`
+	EventEnd     *string          `json:"eventEnd,omitempty"`
+	Timezone     *string          `json:"timezone,omitempty"`
+	LocationMode *string          `json:"locationMode,omitempty"`
+	LocationText *string          `json:"locationText,omitempty"`
+	LocationURL  *string          `json:"locationUrl,omitempty"`
+	RSVPEnabled  *bool            `json:"rsvpEnabled,omitempty"`
+	RSVPRequired *bool            `json:"rsvpRequired,omitempty"`
+	RSVPCapacity *int             `json:"rsvpCapacity,omitempty"`
+	AckRequired  *bool            `json:"ackRequired,omitempty"`
+	AckDueAt     *string          `json:"ackDueAt,omitempty"`
+	ActiveFrom   *string          `json:"activeFrom,omitempty"`
+	ActiveUntil  *string          `json:"activeUntil,omitempty"`
+	// Reason is recorded in the moderation log when an admin edits someone
+	// else's notice.
+	Reason string `json:"reason,omitempty"`
+}
+
+// HandleUpdateNotice handles PUT /api/v1/notices/{id}.
+// Only the notice's creator or a community admin may edit it.
+func (h *NoticesHandler) HandleUpdateNotice(w http.ResponseWriter, r *http.Request, noticeID string) {
+	if r.Method != http.MethodPut {
 		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
 		return
 	}
 
-	h.transitionNotice(w, r, noticeID, "published")
-}
+	var req UpdateNoticeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("invalid request: %v", err),
+		})
+		return
+	}
 
-// HandleArchiveNotice handles POST /api/v1/notices/{id}/archive.
-func (h *NoticesHandler) HandleArchiveNotice(w http.ResponseWriter, r *http.Request, noticeID string) {
-	if r.Method != http.MethodPost {
-		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+	if req.Type != nil && *req.Type != "event" && *req.Type != "update" && *req.Type != "announcement" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "type must be 'event', 'update', or 'announcement'"})
+		return
+	}
+	if req.Title != nil && *req.Title == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "title cannot be empty"})
+		return
+	}
+	if req.Summary != nil && *req.Summary == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "summary cannot be empty"})
+		return
+	}
+	if req.Body != nil && !checkContentSize(w, "body", *req.Body, h.maxBodyBytes, DefaultMaxNoticeBodyBytes) {
 		return
 	}
 
-	h.transitionNotice(w, r, noticeID, "archived")
-}
+	aid := callerAID(r.Context(), h.userIdentity)
+	if aid == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Identity not configured"})
+		return
+	}
 
-// transitionNotice handles lifecycle state transitions for a notice.
-func (h *NoticesHandler) transitionNotice(w http.ResponseWriter, r *http.Request, noticeID, targetState string) {
 	spaceID := h.spaceManager.GetCommunitySpaceID()
 	if spaceID == "" {
 		writeJSON(w, http.StatusConflict, map[string]string{"error": "community space not configured"})
 		return
 	}
 
-	// Read current notice to validate transition
 	noticeMgr := h.spaceManager.NoticeTreeManager()
 	notice, err := noticeMgr.ReadNotice(r.Context(), spaceID, noticeID)
 	if err != nil {
@@ -443,14 +765,46 @@ func (h *NoticesHandler) transitionNotice(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	if !types.IsValidNoticeTransition(notice.State, targetState) {
-		writeJSON(w, http.StatusBadRequest, map[string]string{
-			"error": fmt.Sprintf("invalid transition: %s -> %s", notice.State, targetState),
-		})
+	if notice.CreatedBy != aid && !isNoticeAdmin(r) {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "only the notice creator or an admin can edit this notice"})
 		return
 	}
 
-	// Get signing key
+	if req.Images != nil || req.Attachments != nil {
+		var images, attachments json.RawMessage
+		if req.Images != nil {
+			images = *req.Images
+		}
+		if req.Attachments != nil {
+			attachments = *req.Attachments
+		}
+		images, attachments, ok := h.normalizeNoticeFiles(r.Context(), w, spaceID, images, attachments)
+		if !ok {
+			return
+		}
+		if req.Images != nil {
+			req.Images = &images
+		}
+		if req.Attachments != nil {
+			req.Attachments = &attachments
+		}
+	}
+
+	// A published event cannot be moved into the past.
+	if req.EventStart != nil && notice.State == "published" && notice.Type == "event" {
+		if t, err := time.Parse(time.RFC3339, *req.EventStart); err == nil && t.Before(time.Now()) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "cannot move a published event's start time into the past"})
+			return
+		}
+	}
+
+	applyNoticeUpdate(notice, &req)
+	if err := validateEventTimes(notice.EventStart, notice.EventEnd, notice.Timezone); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	notice.EditedAt = time.Now().UTC().Format(time.RFC3339)
+
 	client := h.spaceManager.GetClient()
 	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), spaceID, client.GetSigningKey())
 	if err != nil {
@@ -460,20 +814,44 @@ func (h *NoticesHandler) transitionNotice(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	if err := noticeMgr.UpdateNoticeState(r.Context(), spaceID, noticeID, targetState, keys.SigningKey); err != nil {
+	if err := noticeMgr.UpdateNotice(r.Context(), spaceID, notice, keys.SigningKey); err != nil {
+		if errors.Is(err, anysync.ErrVersionConflict) {
+			current, _ := noticeMgr.ReadNotice(r.Context(), spaceID, noticeID)
+			writeVersionConflict(w, err, current)
+			return
+		}
 		writeJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": fmt.Sprintf("failed to transition notice: %v", err),
+			"error": fmt.Sprintf("failed to update notice: %v", err),
+		})
+		return
+	}
+
+	if notice.CreatedBy != aid {
+		logModeration(r.Context(), h.spaceManager, ModerationEvent{
+			ActorAID:   aid,
+			Action:     ModerationActionEdit,
+			TargetType: "Notice",
+			TargetID:   noticeID,
+			Reason:     req.Reason,
+		})
+	}
+
+	updated, err := noticeMgr.ReadNotice(r.Context(), spaceID, noticeID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("failed to read updated notice: %v", err),
 		})
 		return
 	}
 
-	// Broadcast SSE event
 	if h.eventBroker != nil {
 		h.eventBroker.Broadcast(SSEEvent{
-			Type: "notice_" + targetState,
+			Type: EventNoticeUpdated,
 			Data: map[string]interface{}{
 				"noticeId": noticeID,
-				"state":    targetState,
+				"title":    updated.Title,
+				"version":  updated.Version,
+				"editedAt": updated.EditedAt,
 			},
 		})
 	}
@@ -481,122 +859,966 @@ func (h *NoticesHandler) transitionNotice(w http.ResponseWriter, r *http.Request
 	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"success":  true,
 		"noticeId": noticeID,
-		"state":    targetState,
+		"version":  updated.Version,
+		"editedAt": updated.EditedAt,
+		"notice":   updated,
 	})
 }
 
-// RSVPRequest represents a request to RSVP to a notice.
-type RSVPRequest struct {
-	Status string `json:"status"` // "going", "maybe", "not_going"
+// applyNoticeUpdate copies the non-nil fields of req onto notice.
+func applyNoticeUpdate(notice *anysync.NoticePayload, req *UpdateNoticeRequest) {
+	if req.Type != nil {
+		notice.Type = *req.Type
+	}
+	if req.Title != nil {
+		notice.Title = *req.Title
+	}
+	if req.Summary != nil {
+		notice.Summary = *req.Summary
+	}
+	if req.Body != nil {
+		notice.Body = *req.Body
+	}
+	if req.Links != nil {
+		notice.Links = *req.Links
+	}
+	if req.Images != nil {
+		notice.Images = *req.Images
+	}
+	if req.Attachments != nil {
+		notice.Attachments = *req.Attachments
+	}
+	if req.Subtype != nil {
+		notice.Subtype = *req.Subtype
+	}
+	if req.EventStart != nil {
+		notice.EventStart = *req.EventStart
+	}
+	if req.EventEnd != nil {
+		notice.EventEnd = *req.EventEnd
+	}
+	if req.Timezone != nil {
+		notice.Timezone = *req.Timezone
+	}
+	if req.LocationMode != nil {
+		notice.LocationMode = *req.LocationMode
+	}
+	if req.LocationText != nil {
+		notice.LocationText = *req.LocationText
+	}
+	if req.LocationURL != nil {
+		notice.LocationURL = *req.LocationURL
+	}
+	if req.RSVPEnabled != nil {
+		notice.RSVPEnabled = *req.RSVPEnabled
+	}
+	if req.RSVPRequired != nil {
+		notice.RSVPRequired = *req.RSVPRequired
+	}
+	if req.RSVPCapacity != nil {
+		notice.RSVPCapacity = *req.RSVPCapacity
+	}
+	if req.AckRequired != nil {
+		notice.AckRequired = *req.AckRequired
+	}
+	if req.AckDueAt != nil {
+		notice.AckDueAt = *req.AckDueAt
+	}
+	if req.ActiveFrom != nil {
+		notice.ActiveFrom = *req.ActiveFrom
+	}
+	if req.ActiveUntil != nil {
+		notice.ActiveUntil = *req.ActiveUntil
+	}
 }
 
-// HandleCreateRSVP handles POST /api/v1/notices/{id}/rsvp.
-func (h *NoticesHandler) HandleCreateRSVP(w http.ResponseWriter, r *http.Request, noticeID string) {
-	var req RSVPRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{
-			"error": fmt.Sprintf("invalid request: %v", err),
-		})
+// isNoticeAdmin reports whether the caller holds a community admin role
+// (Operations Steward or Founding Member) as resolved by OptionalRBACMiddleware.
+func isNoticeAdmin(r *http.Request) bool {
+	roles := GetUserRoles(r)
+	return contributions.HasRole(roles, contributions.RoleOperationsSteward) ||
+		contributions.HasRole(roles, contributions.RoleFoundingMember)
+}
+
+// isNoticeModerator reports whether the caller may moderate notice content:
+// community admins plus Community Stewards.
+func isNoticeModerator(r *http.Request) bool {
+	return isNoticeAdmin(r) || contributions.HasRole(GetUserRoles(r), contributions.RoleCommunitySteward)
+}
+
+// HandlePublishNotice handles POST /api/v1/notices/{id}/publish.
+// Publishes a draft, or republishes an archived notice.
+func (h *NoticesHandler) HandlePublishNotice(w http.ResponseWriter, r *http.Request, noticeID string) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
 		return
 	}
 
-	if req.Status != "going" && req.Status != "maybe" && req.Status != "not_going" {
-		writeJSON(w, http.StatusBadRequest, map[string]string{
-			"error": "status must be 'going', 'maybe', or 'not_going'",
-		})
+	h.transitionNotice(w, r, noticeID, "published")
+}
+
+// HandleUnpublishNotice handles POST /api/v1/notices/{id}/unpublish.
+// Takes a published notice off the board and back to draft.
+func (h *NoticesHandler) HandleUnpublishNotice(w http.ResponseWriter, r *http.Request, noticeID string) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
 		return
 	}
 
-	aid := ""
-	if h.userIdentity != nil {
-		aid = h.userIdentity.GetAID()
-	}
-	if aid == "" {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Identity not configured"})
+	h.transitionNotice(w, r, noticeID, "draft")
+}
+
+// HandleArchiveNotice handles POST /api/v1/notices/{id}/archive.
+func (h *NoticesHandler) HandleArchiveNotice(w http.ResponseWriter, r *http.Request, noticeID string) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
 		return
 	}
 
+	h.transitionNotice(w, r, noticeID, "archived")
+}
+
+// errInvalidTransition is returned by setNoticeState for a lifecycle change
+// types.IsValidNoticeTransition doesn't allow.
+var errInvalidTransition = errors.New("invalid transition")
+
+// transitionNotice handles lifecycle state transitions for a notice.
+func (h *NoticesHandler) transitionNotice(w http.ResponseWriter, r *http.Request, noticeID, targetState string) {
+	reason := ""
+	if targetState == "archived" {
+		var ok bool
+		if reason, ok = moderationReason(w, r); !ok {
+			return
+		}
+	}
+
 	spaceID := h.spaceManager.GetCommunitySpaceID()
 	if spaceID == "" {
 		writeJSON(w, http.StatusConflict, map[string]string{"error": "community space not configured"})
 		return
 	}
 
-	client := h.spaceManager.GetClient()
-	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), spaceID, client.GetSigningKey())
+	// Read current notice to validate transition
+	noticeMgr := h.spaceManager.NoticeTreeManager()
+	notice, err := noticeMgr.ReadNotice(r.Context(), spaceID, noticeID)
 	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": fmt.Sprintf("failed to load space keys: %v", err),
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": fmt.Sprintf("notice not found: %v", err),
 		})
 		return
 	}
 
-	now := time.Now().UTC().Format(time.RFC3339)
-	rsvp := &anysync.NoticeRSVPPayload{
-		NoticeID:  noticeID,
-		UserID:    aid,
-		Status:    req.Status,
-		UpdatedAt: now,
+	if err := h.setNoticeState(r.Context(), spaceID, notice, targetState); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errInvalidTransition) {
+			status = http.StatusBadRequest
+		}
+		writeJSON(w, status, map[string]string{"error": err.Error()})
+		return
 	}
 
-	noticeMgr := h.spaceManager.NoticeTreeManager()
-	treeID, err := noticeMgr.CreateRSVP(r.Context(), spaceID, rsvp, keys.SigningKey)
-	if err != nil {
+	// A creator archiving their own notice isn't a moderator action
+	if aid := callerAID(r.Context(), h.userIdentity); targetState == "archived" && aid != notice.CreatedBy {
+		logModeration(r.Context(), h.spaceManager, ModerationEvent{
+			ActorAID:   aid,
+			Action:     ModerationActionArchive,
+			TargetType: "Notice",
+			TargetID:   noticeID,
+			Reason:     reason,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success":  true,
+		"noticeId": noticeID,
+		"state":    targetState,
+	})
+}
+
+// setNoticeState validates and writes a lifecycle transition, then
+// broadcasts the SSE event for the new state (see noticeStateEvent).
+func (h *NoticesHandler) setNoticeState(ctx context.Context, spaceID string, notice *anysync.NoticePayload, targetState string) error {
+	if !types.IsValidNoticeTransition(notice.State, targetState) {
+		return fmt.Errorf("%w: %s -> %s", errInvalidTransition, notice.State, targetState)
+	}
+
+	// Get signing key
+	client := h.spaceManager.GetClient()
+	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), spaceID, client.GetSigningKey())
+	if err != nil {
+		return fmt.Errorf("failed to load space keys: %v", err)
+	}
+
+	if err := h.spaceManager.NoticeTreeManager().UpdateNoticeState(ctx, spaceID, notice.ID, targetState, keys.SigningKey); err != nil {
+		return fmt.Errorf("failed to transition notice: %v", err)
+	}
+
+	// Broadcast SSE event
+	if h.eventBroker != nil {
+		h.eventBroker.Broadcast(SSEEvent{
+			Type: noticeStateEvent(targetState),
+			Data: map[string]interface{}{
+				"noticeId": notice.ID,
+				"state":    targetState,
+			},
+		})
+	}
+	return nil
+}
+
+// StartScheduledPublisher begins a background loop that publishes draft
+// notices once their PublishAt arrives.
+func (h *NoticesHandler) StartScheduledPublisher(interval time.Duration) {
+	h.publishLoop.start(interval, func(ctx context.Context, now time.Time) {
+		h.PublishScheduledNotices(ctx, now)
+	})
+	log.Printf("[Notices] Started scheduled publisher (interval=%s)", interval)
+}
+
+// StopScheduledPublisher stops the loop started by StartScheduledPublisher.
+func (h *NoticesHandler) StopScheduledPublisher() {
+	h.publishLoop.stop()
+}
+
+// PublishScheduledNotices publishes each draft notice whose PublishAt is at
+// or before now, through the same transition as POST /publish. It returns
+// the IDs of the notices it published. Every member's node runs the
+// publisher, so only the community owner's node publishes, or each due notice
+// would be published once per node.
+func (h *NoticesHandler) PublishScheduledNotices(ctx context.Context, now time.Time) []string {
+	spaceID := h.spaceManager.GetCommunitySpaceID()
+	if spaceID == "" || !h.ownsCommunity(ctx, spaceID) {
+		return nil
+	}
+
+	notices, err := h.spaceManager.NoticeTreeManager().ReadNotices(ctx, spaceID)
+	if err != nil {
+		return nil
+	}
+
+	var published []string
+	for _, notice := range notices {
+		if notice.State != "draft" || notice.PublishAt == "" {
+			continue
+		}
+		publishAt, err := time.Parse(time.RFC3339, notice.PublishAt)
+		if err != nil || publishAt.After(now) {
+			continue
+		}
+		if err := h.setNoticeState(ctx, spaceID, notice, "published"); err != nil {
+			log.Printf("[Notices] failed to publish scheduled notice %s: %v", notice.ID, err)
+			continue
+		}
+		log.Printf("[Notices] Published scheduled notice %s (publishAt=%s)", notice.ID, notice.PublishAt)
+		published = append(published, notice.ID)
+	}
+	return published
+}
+
+// ownsCommunity reports whether this node's identity is the org admin, or its
+// account manages the community space's ACL.
+func (h *NoticesHandler) ownsCommunity(ctx context.Context, spaceID string) bool {
+	if h.userIdentity == nil {
+		return false
+	}
+	if aid := h.userIdentity.GetAID(); aid != "" && h.spaceManager.IsOrgAdmin(aid) {
+		return true
+	}
+	return managesSpaceACL(ctx, h.spaceManager, spaceID)
+}
+
+// RSVPRequest represents a request to RSVP to a notice.
+type RSVPRequest struct {
+	Status         string `json:"status"`                   // "going", "maybe", "not_going"; "waitlisted" is assigned by the server
+	OccurrenceDate string `json:"occurrenceDate,omitempty"` // occurrence of a recurring event, RFC3339
+}
+
+// HandleCreateRSVP handles POST /api/v1/notices/{id}/rsvp.
+func (h *NoticesHandler) HandleCreateRSVP(w http.ResponseWriter, r *http.Request, noticeID string) {
+	var req RSVPRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("invalid request: %v", err),
+		})
+		return
+	}
+
+	if req.Status != "going" && req.Status != "maybe" && req.Status != "not_going" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "status must be 'going', 'maybe', or 'not_going'",
+		})
+		return
+	}
+
+	aid := callerAID(r.Context(), h.userIdentity)
+	if aid == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Identity not configured"})
+		return
+	}
+
+	spaceID := h.spaceManager.GetCommunitySpaceID()
+	if spaceID == "" {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "community space not configured"})
+		return
+	}
+
+	client := h.spaceManager.GetClient()
+	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), spaceID, client.GetSigningKey())
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("failed to load space keys: %v", err),
+		})
+		return
+	}
+
+	noticeMgr := h.spaceManager.NoticeTreeManager()
+
+	notice, err := noticeMgr.ReadNotice(r.Context(), spaceID, noticeID)
+	occurrenceDate := req.OccurrenceDate
+	if occurrenceDate != "" {
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "notice not found"})
+			return
+		}
+		if occurrenceDate, err = resolveOccurrence(notice, occurrenceDate); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+	}
+
+	// Capacity check: over-capacity "going" RSVPs join the waitlist.
+	// Each occurrence of a recurring event has its own capacity.
+	status := req.Status
+	var current map[string]*anysync.NoticeRSVPPayload
+	if err == nil && notice.RSVPCapacity > 0 {
+		existing, err := noticeMgr.ReadRSVPs(r.Context(), spaceID, noticeID)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": fmt.Sprintf("failed to read RSVPs: %v", err),
+			})
+			return
+		}
+		current = latestRSVPsByUser(rsvpsForOccurrence(existing, occurrenceDate))
+		if status == "going" {
+			prev := current[aid]
+			alreadyGoing := prev != nil && prev.Status == "going"
+			if !alreadyGoing && countRSVPStatus(current, "going") >= notice.RSVPCapacity {
+				status = "waitlisted"
+			}
+		}
+	}
+
+	rsvp := &anysync.NoticeRSVPPayload{
+		NoticeID:       noticeID,
+		OccurrenceDate: occurrenceDate,
+		UserID:         aid,
+		Status:         status,
+		UpdatedAt:      time.Now().UTC().Format(time.RFC3339Nano),
+	}
+	if prev := current[aid]; prev != nil && prev.Status == "waitlisted" && status == "waitlisted" {
+		rsvp.UpdatedAt = prev.UpdatedAt // keep place in the waitlist
+	}
+
+	treeID, err := noticeMgr.CreateRSVP(r.Context(), spaceID, rsvp, keys.SigningKey)
+	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{
 			"error": fmt.Sprintf("failed to create RSVP: %v", err),
 		})
 		return
 	}
+	h.indexRSVP(r.Context(), rsvp)
+
+	if current != nil {
+		prev := current[aid]
+		current[aid] = rsvp
+		if prev != nil && prev.Status == "going" && status != "going" {
+			h.promoteWaitlistedRSVP(r.Context(), spaceID, notice, current, keys.SigningKey)
+		}
+	}
+
+	if h.eventBroker != nil {
+		h.eventBroker.Broadcast(SSEEvent{
+			Type: EventNoticeRSVP,
+			Data: map[string]interface{}{
+				"noticeId":       noticeID,
+				"occurrenceDate": occurrenceDate,
+				"userId":         aid,
+				"status":         status,
+			},
+		})
+	}
+
+	if status == "waitlisted" {
+		writeJSON(w, http.StatusConflict, map[string]interface{}{
+			"error":            "event is at capacity",
+			"noticeId":         noticeID,
+			"occurrenceDate":   occurrenceDate,
+			"status":           status,
+			"waitlistPosition": waitlistPosition(current, aid),
+			"treeId":           treeID,
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success":        true,
+		"noticeId":       noticeID,
+		"occurrenceDate": occurrenceDate,
+		"status":         status,
+		"treeId":         treeID,
+	})
+}
+
+// promoteWaitlistedRSVP moves the longest-waiting waitlisted RSVP to "going"
+// if the notice has a free slot. current is updated in place.
+func (h *NoticesHandler) promoteWaitlistedRSVP(
+	ctx context.Context,
+	spaceID string,
+	notice *anysync.NoticePayload,
+	current map[string]*anysync.NoticeRSVPPayload,
+	signingKey crypto.PrivKey,
+) {
+	if countRSVPStatus(current, "going") >= notice.RSVPCapacity {
+		return
+	}
+	waitlist := waitlistedRSVPs(current)
+	if len(waitlist) == 0 {
+		return
+	}
+
+	next := *waitlist[0]
+	next.Status = "going"
+	next.UpdatedAt = time.Now().UTC().Format(time.RFC3339Nano)
+	if _, err := h.spaceManager.NoticeTreeManager().CreateRSVP(ctx, spaceID, &next, signingKey); err != nil {
+		log.Printf("[Notices] failed to promote waitlisted RSVP %s for notice %s: %v", next.UserID, notice.ID, err)
+		return
+	}
+	h.indexRSVP(ctx, &next)
+	current[next.UserID] = &next
+
+	if h.eventBroker != nil {
+		h.eventBroker.Broadcast(SSEEvent{
+			Type: EventNoticeRSVPPromoted,
+			Data: map[string]interface{}{
+				"noticeId":       notice.ID,
+				"occurrenceDate": next.OccurrenceDate,
+				"userId":         next.UserID,
+				"status":         next.Status,
+			},
+		})
+	}
+}
+
+// rsvpsForOccurrence returns the RSVPs for one occurrence of a notice.
+// An empty occurrenceDate selects RSVPs to the notice itself.
+func rsvpsForOccurrence(rsvps []*anysync.NoticeRSVPPayload, occurrenceDate string) []*anysync.NoticeRSVPPayload {
+	var out []*anysync.NoticeRSVPPayload
+	for _, rsvp := range rsvps {
+		if rsvp.OccurrenceDate == occurrenceDate {
+			out = append(out, rsvp)
+		}
+	}
+	return out
+}
+
+// latestRSVPsByUser collapses RSVPs to the most recent one per user.
+func latestRSVPsByUser(rsvps []*anysync.NoticeRSVPPayload) map[string]*anysync.NoticeRSVPPayload {
+	latest := make(map[string]*anysync.NoticeRSVPPayload, len(rsvps))
+	for _, rsvp := range rsvps {
+		if prev, ok := latest[rsvp.UserID]; ok && !rsvpUpdatedBefore(prev, rsvp) {
+			continue
+		}
+		latest[rsvp.UserID] = rsvp
+	}
+	return latest
+}
+
+// countRSVPStatus counts RSVPs with the given status.
+func countRSVPStatus(rsvps map[string]*anysync.NoticeRSVPPayload, status string) int {
+	n := 0
+	for _, rsvp := range rsvps {
+		if rsvp.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// waitlistedRSVPs returns waitlisted RSVPs in queue order (oldest first).
+func waitlistedRSVPs(rsvps map[string]*anysync.NoticeRSVPPayload) []*anysync.NoticeRSVPPayload {
+	var waitlist []*anysync.NoticeRSVPPayload
+	for _, rsvp := range rsvps {
+		if rsvp.Status == "waitlisted" {
+			waitlist = append(waitlist, rsvp)
+		}
+	}
+	sort.Slice(waitlist, func(i, j int) bool {
+		if rsvpUpdatedBefore(waitlist[i], waitlist[j]) {
+			return true
+		}
+		if rsvpUpdatedBefore(waitlist[j], waitlist[i]) {
+			return false
+		}
+		return waitlist[i].UserID < waitlist[j].UserID
+	})
+	return waitlist
+}
+
+// waitlistPosition returns the 1-based waitlist position of a user, or 0.
+func waitlistPosition(rsvps map[string]*anysync.NoticeRSVPPayload, userID string) int {
+	for i, rsvp := range waitlistedRSVPs(rsvps) {
+		if rsvp.UserID == userID {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// rsvpUpdatedBefore reports whether a was updated strictly before b.
+func rsvpUpdatedBefore(a, b *anysync.NoticeRSVPPayload) bool {
+	return anystore.LaterTimestamp(b.UpdatedAt, a.UpdatedAt)
+}
+
+// HandleListRSVPs handles GET /api/v1/notices/{id}/rsvp.
+// Supports ?occurrenceDate= to list RSVPs for one occurrence of a recurring event,
+// and ?countsOnly=true to return only the counts per status.
+func (h *NoticesHandler) HandleListRSVPs(w http.ResponseWriter, r *http.Request, noticeID string) {
+	spaceID := h.spaceManager.GetCommunitySpaceID()
+	if spaceID == "" {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"rsvps": []interface{}{},
+			"count": 0,
+		})
+		return
+	}
+
+	occurrenceDate, err := normalizeOccurrenceDate(r.URL.Query().Get("occurrenceDate"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	counts := map[string]int{"going": 0, "maybe": 0, "not_going": 0, "waitlisted": 0}
+	countsOnly := r.URL.Query().Get("countsOnly") == "true"
+	if store := h.countsIndex(r, spaceID, countsOnly); store != nil {
+		indexed, err := store.CountNoticeRSVPs(r.Context(), noticeID, occurrenceDate)
+		if err == nil {
+			total := 0
+			for status, n := range indexed {
+				counts[status] += n
+				total += n
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{
+				"count":  total,
+				"counts": counts,
+			})
+			return
+		}
+		log.Printf("[Notices] Warning: counting RSVPs from the index failed, scanning: %v", err)
+	}
+
+	noticeMgr := h.spaceManager.NoticeTreeManager()
+	rsvps, err := noticeMgr.ReadRSVPs(r.Context(), spaceID, noticeID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("failed to read RSVPs: %v", err),
+		})
+		return
+	}
+
+	// A user may have several RSVP objects (e.g. concurrent writes from two
+	// devices); only the most recent one per user is effective.
+	effective := make([]*anysync.NoticeRSVPPayload, 0, len(rsvps))
+	for _, rsvp := range latestRSVPsByUser(rsvpsForOccurrence(rsvps, occurrenceDate)) {
+		effective = append(effective, rsvp)
+	}
+	sort.Slice(effective, func(i, j int) bool {
+		if rsvpUpdatedBefore(effective[i], effective[j]) {
+			return true
+		}
+		if rsvpUpdatedBefore(effective[j], effective[i]) {
+			return false
+		}
+		return effective[i].UserID < effective[j].UserID
+	})
+
+	// Compute counts
+	for _, rsvp := range effective {
+		counts[rsvp.Status]++
+	}
+
+	if countsOnly {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"count":  len(effective),
+			"counts": counts,
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"rsvps":  effective,
+		"count":  len(effective),
+		"counts": counts,
+	})
+}
+
+// AckRequest represents an optional body for acknowledging a notice.
+type AckRequest struct {
+	OccurrenceDate string `json:"occurrenceDate,omitempty"` // occurrence of a recurring event, RFC3339
+}
+
+// HandleCreateAck handles POST /api/v1/notices/{id}/ack.
+func (h *NoticesHandler) HandleCreateAck(w http.ResponseWriter, r *http.Request, noticeID string) {
+	var req AckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("invalid request: %v", err),
+		})
+		return
+	}
+
+	aid := callerAID(r.Context(), h.userIdentity)
+	if aid == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Identity not configured"})
+		return
+	}
+
+	spaceID := h.spaceManager.GetCommunitySpaceID()
+	if spaceID == "" {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "community space not configured"})
+		return
+	}
+
+	client := h.spaceManager.GetClient()
+	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), spaceID, client.GetSigningKey())
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("failed to load space keys: %v", err),
+		})
+		return
+	}
+
+	noticeMgr := h.spaceManager.NoticeTreeManager()
+
+	occurrenceDate := req.OccurrenceDate
+	if occurrenceDate != "" {
+		notice, err := noticeMgr.ReadNotice(r.Context(), spaceID, noticeID)
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "notice not found"})
+			return
+		}
+		if occurrenceDate, err = resolveOccurrence(notice, occurrenceDate); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	ack := &anysync.NoticeAckPayload{
+		NoticeID:       noticeID,
+		OccurrenceDate: occurrenceDate,
+		UserID:         aid,
+		AckAt:          now,
+		Method:         "explicit",
+	}
+
+	treeID, err := noticeMgr.CreateAck(r.Context(), spaceID, ack, keys.SigningKey)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("failed to create ack: %v", err),
+		})
+		return
+	}
+
+	if h.eventBroker != nil && treeID != "" {
+		h.eventBroker.Broadcast(SSEEvent{
+			Type: EventNoticeAck,
+			Data: map[string]interface{}{
+				"noticeId":       noticeID,
+				"occurrenceDate": occurrenceDate,
+				"userIds":        []string{aid},
+			},
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success":        true,
+		"noticeId":       noticeID,
+		"occurrenceDate": occurrenceDate,
+		"treeId":         treeID,
+	})
+}
+
+// HandleListAcks handles GET /api/v1/notices/{id}/ack.
+// Supports ?occurrenceDate= to list acks for one occurrence of a recurring event.
+func (h *NoticesHandler) HandleListAcks(w http.ResponseWriter, r *http.Request, noticeID string) {
+	spaceID := h.spaceManager.GetCommunitySpaceID()
+	if spaceID == "" {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"acks":  []interface{}{},
+			"count": 0,
+		})
+		return
+	}
+
+	noticeMgr := h.spaceManager.NoticeTreeManager()
+	acks, err := noticeMgr.ReadAcks(r.Context(), spaceID, noticeID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("failed to read acks: %v", err),
+		})
+		return
+	}
+
+	occurrenceDate, err := normalizeOccurrenceDate(r.URL.Query().Get("occurrenceDate"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	matching := make([]*anysync.NoticeAckPayload, 0, len(acks))
+	for _, ack := range acks {
+		if ack.OccurrenceDate == occurrenceDate {
+			matching = append(matching, ack)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"acks":  matching,
+		"count": len(matching),
+	})
+}
+
+// PendingAckMember is a community member who has not yet acknowledged a notice.
+type PendingAckMember struct {
+	AID         string `json:"aid"`
+	DisplayName string `json:"displayName"`
+	Role        string `json:"role,omitempty"`
+}
+
+// HandleListPendingAcks handles GET /api/v1/notices/{id}/ack/pending.
+// Returns community members who still owe an acknowledgment. Admin only.
+func (h *NoticesHandler) HandleListPendingAcks(w http.ResponseWriter, r *http.Request, noticeID string) {
+	if !isNoticeAdmin(r) {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "only admins can view pending acknowledgments"})
+		return
+	}
+
+	spaceID := h.spaceManager.GetCommunitySpaceID()
+	if spaceID == "" {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "community space not configured"})
+		return
+	}
+
+	notice, err := h.spaceManager.NoticeTreeManager().ReadNotice(r.Context(), spaceID, noticeID)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "notice not found"})
+		return
+	}
+	if !notice.AckRequired {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "notice does not require acknowledgment"})
+		return
+	}
+
+	pending, memberCount, err := h.pendingAcks(r.Context(), spaceID, notice)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("failed to compute pending acks: %v", err),
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"noticeId":    noticeID,
+		"ackDueAt":    notice.AckDueAt,
+		"overdue":     ackOverdue(notice, time.Now()) && len(pending) > 0,
+		"pending":     pending,
+		"count":       len(pending),
+		"memberCount": memberCount,
+	})
+}
+
+// pendingAcks diffs the active community members against the notice's acks.
+// It returns the members who have not acknowledged, sorted by AID, along
+// with the total number of active members.
+func (h *NoticesHandler) pendingAcks(ctx context.Context, spaceID string, notice *anysync.NoticePayload) ([]PendingAckMember, int, error) {
+	members, acked, err := h.memberAcks(ctx, spaceID, notice)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	pending := []PendingAckMember{}
+	for _, m := range members {
+		if acked[m.AID] == nil {
+			pending = append(pending, m)
+		}
+	}
+	return pending, len(members), nil
+}
+
+// memberAcks returns the active community members along with the notice's
+// series-level acks (not those for a single occurrence), keyed by user AID.
+func (h *NoticesHandler) memberAcks(ctx context.Context, spaceID string, notice *anysync.NoticePayload) ([]PendingAckMember, map[string]*anysync.NoticeAckPayload, error) {
+	members, err := h.communityMembers(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	acks, err := h.spaceManager.NoticeTreeManager().ReadAcks(ctx, spaceID, notice.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	acked := make(map[string]*anysync.NoticeAckPayload, len(acks))
+	for _, ack := range acks {
+		if ack.OccurrenceDate == "" {
+			acked[ack.UserID] = ack
+		}
+	}
+	return members, acked, nil
+}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"success":  true,
-		"noticeId": noticeID,
-		"status":   req.Status,
-		"treeId":   treeID,
-	})
+// BulkAckRequest is the request body for POST /api/v1/notices/{id}/ack/bulk.
+type BulkAckRequest struct {
+	AIDs []string `json:"aids"`
 }
 
-// HandleListRSVPs handles GET /api/v1/notices/{id}/rsvp.
-func (h *NoticesHandler) HandleListRSVPs(w http.ResponseWriter, r *http.Request, noticeID string) {
+// HandleBulkAck handles POST /api/v1/notices/{id}/ack/bulk.
+// Records acks with Method "admin" on behalf of members who acknowledged
+// out-of-band. Members who already acked keep their existing ack, and AIDs
+// that aren't active community members are skipped. Admin only.
+func (h *NoticesHandler) HandleBulkAck(w http.ResponseWriter, r *http.Request, noticeID string) {
+	if !isNoticeAdmin(r) {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "only admins can record acknowledgments for other members"})
+		return
+	}
+
+	var req BulkAckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("invalid request: %v", err),
+		})
+		return
+	}
+	if len(req.AIDs) == 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "aids is required"})
+		return
+	}
+
 	spaceID := h.spaceManager.GetCommunitySpaceID()
 	if spaceID == "" {
-		writeJSON(w, http.StatusOK, map[string]interface{}{
-			"rsvps": []interface{}{},
-			"count": 0,
-		})
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "community space not configured"})
 		return
 	}
 
 	noticeMgr := h.spaceManager.NoticeTreeManager()
-	rsvps, err := noticeMgr.ReadRSVPs(r.Context(), spaceID, noticeID)
+	notice, err := noticeMgr.ReadNotice(r.Context(), spaceID, noticeID)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "notice not found"})
+		return
+	}
+	if !notice.AckRequired {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "notice does not require acknowledgment"})
+		return
+	}
+
+	members, acked, err := h.memberAcks(r.Context(), spaceID, notice)
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": fmt.Sprintf("failed to read RSVPs: %v", err),
+			"error": fmt.Sprintf("failed to read acks: %v", err),
 		})
 		return
 	}
+	isMember := make(map[string]bool, len(members))
+	for _, m := range members {
+		isMember[m.AID] = true
+	}
 
-	// Compute counts
-	counts := map[string]int{"going": 0, "maybe": 0, "not_going": 0}
-	for _, rsvp := range rsvps {
-		counts[rsvp.Status]++
+	client := h.spaceManager.GetClient()
+	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), spaceID, client.GetSigningKey())
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("failed to load space keys: %v", err),
+		})
+		return
+	}
+
+	recordedBy := GetUserAID(r)
+	now := time.Now().UTC().Format(time.RFC3339)
+	created, alreadyAcked, notMembers := []string{}, []string{}, []string{}
+	seen := make(map[string]bool, len(req.AIDs))
+	for _, aid := range req.AIDs {
+		aid = strings.TrimSpace(aid)
+		if aid == "" || seen[aid] {
+			continue
+		}
+		seen[aid] = true
+
+		switch {
+		case !isMember[aid]:
+			notMembers = append(notMembers, aid)
+			continue
+		case acked[aid] != nil:
+			alreadyAcked = append(alreadyAcked, aid)
+			continue
+		}
+
+		ack := &anysync.NoticeAckPayload{
+			NoticeID:   noticeID,
+			UserID:     aid,
+			AckAt:      now,
+			Method:     "admin",
+			RecordedBy: recordedBy,
+		}
+		treeID, err := noticeMgr.CreateAck(r.Context(), spaceID, ack, keys.SigningKey)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": fmt.Sprintf("failed to create ack for %s: %v", aid, err),
+			})
+			return
+		}
+		if treeID == "" {
+			// Acked between reading the acks and writing this one
+			alreadyAcked = append(alreadyAcked, aid)
+			continue
+		}
+		created = append(created, aid)
+	}
+
+	log.Printf("[Notices] %s recorded %d admin acks for notice %s", recordedBy, len(created), noticeID)
+
+	if h.eventBroker != nil && len(created) > 0 {
+		h.eventBroker.Broadcast(SSEEvent{
+			Type: EventNoticeAck,
+			Data: map[string]interface{}{
+				"noticeId":   noticeID,
+				"userIds":    created,
+				"recordedBy": recordedBy,
+			},
+		})
 	}
 
 	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"rsvps":  rsvps,
-		"count":  len(rsvps),
-		"counts": counts,
+		"success":      true,
+		"noticeId":     noticeID,
+		"created":      created,
+		"alreadyAcked": alreadyAcked,
+		"notMembers":   notMembers,
 	})
 }
 
-// HandleCreateAck handles POST /api/v1/notices/{id}/ack.
-func (h *NoticesHandler) HandleCreateAck(w http.ResponseWriter, r *http.Request, noticeID string) {
-	aid := ""
-	if h.userIdentity != nil {
-		aid = h.userIdentity.GetAID()
-	}
-	if aid == "" {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Identity not configured"})
+// AckSummary is the response for GET /api/v1/notices/{id}/ack/summary.
+type AckSummary struct {
+	NoticeID     string         `json:"noticeId"`
+	Acked        int            `json:"acked"`
+	Total        int            `json:"total"`
+	Pending      int            `json:"pending"`
+	AckedPercent float64        `json:"ackedPercent"`
+	ByMethod     map[string]int `json:"byMethod"`
+}
+
+// HandleAckSummary handles GET /api/v1/notices/{id}/ack/summary.
+// Reports how many active members have acknowledged the notice, broken down
+// by ack method. Admin only.
+func (h *NoticesHandler) HandleAckSummary(w http.ResponseWriter, r *http.Request, noticeID string) {
+	if !isNoticeAdmin(r) {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "only admins can view acknowledgment progress"})
 		return
 	}
 
@@ -606,63 +1828,176 @@ func (h *NoticesHandler) HandleCreateAck(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
-	client := h.spaceManager.GetClient()
-	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), spaceID, client.GetSigningKey())
+	notice, err := h.spaceManager.NoticeTreeManager().ReadNotice(r.Context(), spaceID, noticeID)
 	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": fmt.Sprintf("failed to load space keys: %v", err),
-		})
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "notice not found"})
 		return
 	}
-
-	now := time.Now().UTC().Format(time.RFC3339)
-	ack := &anysync.NoticeAckPayload{
-		NoticeID: noticeID,
-		UserID:   aid,
-		AckAt:    now,
-		Method:   "explicit",
+	if !notice.AckRequired {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "notice does not require acknowledgment"})
+		return
 	}
 
-	noticeMgr := h.spaceManager.NoticeTreeManager()
-	treeID, err := noticeMgr.CreateAck(r.Context(), spaceID, ack, keys.SigningKey)
+	members, acked, err := h.memberAcks(r.Context(), spaceID, notice)
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": fmt.Sprintf("failed to create ack: %v", err),
+			"error": fmt.Sprintf("failed to read acks: %v", err),
 		})
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"success":  true,
-		"noticeId": noticeID,
-		"treeId":   treeID,
+	summary := AckSummary{NoticeID: noticeID, Total: len(members), ByMethod: map[string]int{}}
+	for _, m := range members {
+		if ack := acked[m.AID]; ack != nil {
+			summary.Acked++
+			summary.ByMethod[ack.Method]++
+		}
+	}
+	summary.Pending = summary.Total - summary.Acked
+	summary.AckedPercent = ackPercent(summary.Acked, summary.Total)
+
+	writeJSON(w, http.StatusOK, summary)
+}
+
+// ackPercent returns acked as a percentage of total, to one decimal place.
+func ackPercent(acked, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(acked)*1000/float64(total)) / 10
+}
+
+// communityMembers lists active members from the CommunityProfile objects
+// in the read-only space, sorted by AID. Removed members are excluded.
+func (h *NoticesHandler) communityMembers(ctx context.Context) ([]PendingAckMember, error) {
+	roSpaceID := h.spaceManager.GetCommunityReadOnlySpaceID()
+	if roSpaceID == "" {
+		return nil, fmt.Errorf("community-readonly space not configured")
+	}
+	objects, err := h.spaceManager.ObjectTreeManager().ReadObjectsByType(ctx, roSpaceID, "CommunityProfile")
+	if err != nil {
+		return nil, err
+	}
+
+	var members []PendingAckMember
+	for _, obj := range deduplicateObjects(objects) {
+		var profile struct {
+			UserAID string `json:"userAID"`
+			Role    string `json:"role"`
+			Status  string `json:"status"`
+		}
+		if err := decodeObjectData(h.quarantine, roSpaceID, obj, &profile); err != nil {
+			continue
+		}
+		aid := profile.UserAID
+		if aid == "" {
+			aid = strings.TrimPrefix(obj.ID, "CommunityProfile-")
+		}
+		if aid == "" || profile.Status == "removed" {
+			continue
+		}
+		members = append(members, PendingAckMember{AID: aid, Role: profile.Role})
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].AID < members[j].AID })
+
+	aids := make([]string, len(members))
+	for i, m := range members {
+		aids[i] = m.AID
+	}
+	profiles := h.profiles.ResolveMany(ctx, aids)
+	for i := range members {
+		members[i].DisplayName = profiles[members[i].AID].DisplayName
+	}
+	return members, nil
+}
+
+// noticeCurrent reports whether a notice is live at now: published, past
+// its scheduled PublishAt and ActiveFrom, and not yet past ActiveUntil.
+// Unparseable times are ignored rather than hiding the notice.
+func noticeCurrent(n *anysync.NoticePayload, now time.Time) bool {
+	if n.State != "published" {
+		return false
+	}
+	for _, from := range []string{n.PublishAt, n.ActiveFrom} {
+		if t, err := time.Parse(time.RFC3339, from); err == nil && t.After(now) {
+			return false
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, n.ActiveUntil); err == nil && t.Before(now) {
+		return false
+	}
+	return true
+}
+
+// ackOverdue reports whether a notice's ack due date has passed.
+func ackOverdue(notice *anysync.NoticePayload, now time.Time) bool {
+	if !notice.AckRequired || notice.AckDueAt == "" {
+		return false
+	}
+	due, err := time.Parse(time.RFC3339, notice.AckDueAt)
+	return err == nil && !now.Before(due)
+}
+
+// StartAckOverdueChecker begins a background loop that emits a
+// "notice_ack_overdue" SSE event once per notice when its AckDueAt passes
+// with acknowledgments still outstanding.
+func (h *NoticesHandler) StartAckOverdueChecker(interval time.Duration) {
+	h.ackLoop.start(interval, func(ctx context.Context, now time.Time) {
+		h.CheckOverdueAcks(ctx, now)
 	})
+	log.Printf("[Notices] Started ack overdue checker (interval=%s)", interval)
 }
 
-// HandleListAcks handles GET /api/v1/notices/{id}/ack.
-func (h *NoticesHandler) HandleListAcks(w http.ResponseWriter, r *http.Request, noticeID string) {
+// StopAckOverdueChecker stops the loop started by StartAckOverdueChecker.
+func (h *NoticesHandler) StopAckOverdueChecker() {
+	h.ackLoop.stop()
+}
+
+// CheckOverdueAcks broadcasts "notice_ack_overdue" for each published
+// notice whose ack due date has passed with members still pending. Each
+// notice is reported at most once. The check is skipped while no SSE
+// clients are connected so the event is not lost.
+func (h *NoticesHandler) CheckOverdueAcks(ctx context.Context, now time.Time) {
+	if h.eventBroker == nil || h.eventBroker.ClientCount() == 0 {
+		return
+	}
 	spaceID := h.spaceManager.GetCommunitySpaceID()
 	if spaceID == "" {
-		writeJSON(w, http.StatusOK, map[string]interface{}{
-			"acks":  []interface{}{},
-			"count": 0,
-		})
 		return
 	}
 
-	noticeMgr := h.spaceManager.NoticeTreeManager()
-	acks, err := noticeMgr.ReadAcks(r.Context(), spaceID, noticeID)
+	notices, err := h.spaceManager.NoticeTreeManager().ReadNotices(ctx, spaceID)
 	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": fmt.Sprintf("failed to read acks: %v", err),
-		})
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"acks":  acks,
-		"count": len(acks),
-	})
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, notice := range notices {
+		if notice.State != "published" || h.overdueNotified[notice.ID] || !ackOverdue(notice, now) {
+			continue
+		}
+		pending, _, err := h.pendingAcks(ctx, spaceID, notice)
+		if err != nil {
+			log.Printf("[Notices] failed to compute pending acks for %s: %v", notice.ID, err)
+			continue
+		}
+		h.overdueNotified[notice.ID] = true
+		if len(pending) == 0 {
+			continue
+		}
+
+		h.eventBroker.Broadcast(SSEEvent{
+			Type: EventNoticeAckOverdue,
+			Data: map[string]interface{}{
+				"noticeId":     notice.ID,
+				"title":        notice.Title,
+				"ackDueAt":     notice.AckDueAt,
+				"pendingCount": len(pending),
+			},
+		})
+	}
 }
 
 // HandleToggleSave handles POST /api/v1/notices/{id}/save.
@@ -672,10 +2007,7 @@ func (h *NoticesHandler) HandleToggleSave(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	aid := ""
-	if h.userIdentity != nil {
-		aid = h.userIdentity.GetAID()
-	}
+	aid := callerAID(r.Context(), h.userIdentity)
 	if aid == "" {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Identity not configured"})
 		return
@@ -763,48 +2095,244 @@ func (h *NoticesHandler) HandleListSaved(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Filter to only pinned saves
-	var pinned []*anysync.NoticeSavePayload
-	for _, s := range saves {
-		if s.Pinned {
-			pinned = append(pinned, s)
+	// Filter to only pinned saves
+	var pinned []*anysync.NoticeSavePayload
+	for _, s := range saves {
+		if s.Pinned {
+			pinned = append(pinned, s)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"saves": pinned,
+		"count": len(pinned),
+	})
+}
+
+// MaxCommentDepth is how deeply comment replies may nest. A top-level comment
+// is at depth 0 and a reply to it at depth 1.
+const MaxCommentDepth = 3
+
+// maxCommentMentions caps the members one comment can mention.
+const maxCommentMentions = 20
+
+// errCommentThreadUnresolved is returned by commentDepth when a comment's
+// ancestors haven't all synced, so its depth isn't known.
+var errCommentThreadUnresolved = errors.New("comment thread not fully synced")
+
+// CommentRequest represents a request to create a comment. ParentCommentID
+// makes it a reply to another comment on the same notice; Mentions lists the
+// AIDs of members the comment mentions.
+type CommentRequest struct {
+	Text            string   `json:"text"`
+	ParentCommentID string   `json:"parentCommentId,omitempty"`
+	Mentions        []string `json:"mentions,omitempty"`
+}
+
+// HandleCreateComment handles POST /api/v1/notices/{id}/comments.
+func (h *NoticesHandler) HandleCreateComment(w http.ResponseWriter, r *http.Request, noticeID string) {
+	var req CommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("invalid request: %v", err),
+		})
+		return
+	}
+
+	if req.Text == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "text is required"})
+		return
+	}
+	if !checkContentSize(w, "text", req.Text, h.maxCommentBytes, DefaultMaxCommentBytes) {
+		return
+	}
+	if len(req.Mentions) > maxCommentMentions {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("a comment can mention at most %d members", maxCommentMentions),
+		})
+		return
+	}
+
+	aid := callerAID(r.Context(), h.userIdentity)
+	if aid == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Identity not configured"})
+		return
+	}
+
+	spaceID := h.spaceManager.GetCommunitySpaceID()
+	if spaceID == "" {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "community space not configured"})
+		return
+	}
+
+	noticeMgr := h.spaceManager.NoticeTreeManager()
+	var parent *anysync.NoticeCommentPayload
+	if req.ParentCommentID != "" {
+		var depth int
+		var err error
+		parent, depth, err = commentDepth(r.Context(), noticeMgr, spaceID, noticeID, req.ParentCommentID)
+		if errors.Is(err, errCommentThreadUnresolved) {
+			writeJSON(w, http.StatusConflict, map[string]string{
+				"error": fmt.Sprintf("cannot reply yet: %v", err),
+			})
+			return
+		}
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{
+				"error": fmt.Sprintf("parent comment not found: %v", err),
+			})
+			return
+		}
+		if parent.DeletedAt != "" {
+			writeJSON(w, http.StatusConflict, map[string]string{"error": "cannot reply to a deleted comment"})
+			return
+		}
+		if depth+1 > MaxCommentDepth {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": fmt.Sprintf("replies can nest at most %d levels deep", MaxCommentDepth),
+			})
+			return
+		}
+	}
+
+	client := h.spaceManager.GetClient()
+	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), spaceID, client.GetSigningKey())
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("failed to load space keys: %v", err),
+		})
+		return
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	commentID := newObjectID("", aid)
+	comment := &anysync.NoticeCommentPayload{
+		ID:        commentID,
+		NoticeID:  noticeID,
+		UserID:    aid,
+		Text:      req.Text,
+		Mentions:  commentMentions(req.Mentions, aid),
+		CreatedAt: now,
+	}
+	if parent != nil {
+		comment.ParentCommentID = parent.ID
+	}
+
+	treeID, err := noticeMgr.CreateComment(r.Context(), spaceID, comment, keys.SigningKey)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("failed to create comment: %v", err),
+		})
+		return
+	}
+
+	if h.eventBroker != nil {
+		data := map[string]interface{}{
+			"noticeId":  noticeID,
+			"commentId": commentID,
+			"userId":    aid,
+		}
+		if parent != nil {
+			data["parentCommentId"] = parent.ID
+		}
+		h.eventBroker.Broadcast(SSEEvent{Type: EventNoticeComment, Data: data})
+
+		// Tell the parent's author they were replied to
+		if parent != nil && parent.UserID != aid {
+			h.eventBroker.Broadcast(SSEEvent{
+				Type: EventNoticeCommentReply,
+				Data: map[string]interface{}{
+					"noticeId":        noticeID,
+					"commentId":       commentID,
+					"parentCommentId": parent.ID,
+					"parentUserId":    parent.UserID,
+					"userId":          aid,
+				},
+				Recipients: []string{parent.UserID},
+			})
+		}
+
+		// Tell mentioned members, leaving out a parent author already told
+		var mentioned []string
+		for _, m := range comment.Mentions {
+			if parent == nil || m != parent.UserID {
+				mentioned = append(mentioned, m)
+			}
+		}
+		if len(mentioned) > 0 {
+			h.eventBroker.Broadcast(SSEEvent{
+				Type: EventNoticeCommentMention,
+				Data: map[string]interface{}{
+					"noticeId":  noticeID,
+					"commentId": commentID,
+					"userId":    aid,
+				},
+				Recipients: mentioned,
+			})
 		}
 	}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"saves": pinned,
-		"count": len(pinned),
-	})
+	resp := map[string]interface{}{
+		"success":   true,
+		"noticeId":  noticeID,
+		"commentId": commentID,
+		"treeId":    treeID,
+	}
+	if parent != nil {
+		resp["parentCommentId"] = parent.ID
+	}
+	writeJSON(w, http.StatusOK, resp)
 }
 
-// CommentRequest represents a request to create a comment.
-type CommentRequest struct {
-	Text string `json:"text"`
+// commentMentions trims and dedupes mentioned AIDs, dropping the author's own.
+func commentMentions(mentions []string, authorAID string) []string {
+	var out []string
+	for _, m := range mentions {
+		if m = strings.TrimSpace(m); m != "" && m != authorAID && !slices.Contains(out, m) {
+			out = append(out, m)
+		}
+	}
+	return out
 }
 
-// HandleCreateComment handles POST /api/v1/notices/{id}/comments.
-func (h *NoticesHandler) HandleCreateComment(w http.ResponseWriter, r *http.Request, noticeID string) {
-	var req CommentRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{
-			"error": fmt.Sprintf("invalid request: %v", err),
-		})
-		return
+// commentDepth reads a comment on noticeID and returns it with its nesting
+// depth, found by walking up its parents. The walk stops once it is past
+// MaxCommentDepth, since anything that deep can't take a reply anyway. If an
+// ancestor can't be read the depth is unknown and errCommentThreadUnresolved
+// is returned.
+func commentDepth(ctx context.Context, noticeMgr *anysync.NoticeTreeManager, spaceID, noticeID, commentID string) (*anysync.NoticeCommentPayload, int, error) {
+	comment, err := noticeMgr.ReadComment(ctx, spaceID, noticeID, commentID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if comment.NoticeID != noticeID {
+		return nil, 0, fmt.Errorf("comment %s is not on notice %s", commentID, noticeID)
+	}
+	depth := 0
+	for ancestor := comment; ancestor.ParentCommentID != "" && depth <= MaxCommentDepth; {
+		depth++
+		next, err := noticeMgr.ReadComment(ctx, spaceID, noticeID, ancestor.ParentCommentID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%w: reading comment %s: %v", errCommentThreadUnresolved, ancestor.ParentCommentID, err)
+		}
+		ancestor = next
 	}
+	return comment, depth, nil
+}
 
-	if req.Text == "" {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "text is required"})
+// HandleDeleteComment handles DELETE /api/v1/notices/{id}/comments/{commentId}.
+// Soft-deletes the comment; only its author or a moderator may delete it.
+func (h *NoticesHandler) HandleDeleteComment(w http.ResponseWriter, r *http.Request, noticeID, commentID string) {
+	if r.Method != http.MethodDelete {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
 		return
 	}
-	if len(req.Text) > 2000 {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "text must be 2000 characters or less"})
+	reason, ok := moderationReason(w, r)
+	if !ok {
 		return
 	}
 
-	aid := ""
-	if h.userIdentity != nil {
-		aid = h.userIdentity.GetAID()
-	}
+	aid := callerAID(r.Context(), h.userIdentity)
 	if aid == "" {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Identity not configured"})
 		return
@@ -816,6 +2344,20 @@ func (h *NoticesHandler) HandleCreateComment(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	noticeMgr := h.spaceManager.NoticeTreeManager()
+	comment, err := noticeMgr.ReadComment(r.Context(), spaceID, noticeID, commentID)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": fmt.Sprintf("comment not found: %v", err),
+		})
+		return
+	}
+
+	if comment.UserID != aid && !isNoticeModerator(r) {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "only the comment author or a moderator can delete this comment"})
+		return
+	}
+
 	client := h.spaceManager.GetClient()
 	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), spaceID, client.GetSigningKey())
 	if err != nil {
@@ -825,32 +2367,30 @@ func (h *NoticesHandler) HandleCreateComment(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	now := time.Now().UTC().Format(time.RFC3339)
-	commentID := fmt.Sprintf("%d", time.Now().UnixMilli())
-	comment := &anysync.NoticeCommentPayload{
-		ID:        commentID,
-		NoticeID:  noticeID,
-		UserID:    aid,
-		Text:      req.Text,
-		CreatedAt: now,
-	}
-
-	noticeMgr := h.spaceManager.NoticeTreeManager()
-	treeID, err := noticeMgr.CreateComment(r.Context(), spaceID, comment, keys.SigningKey)
-	if err != nil {
+	if err := noticeMgr.DeleteComment(r.Context(), spaceID, noticeID, commentID, aid, keys.SigningKey); err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": fmt.Sprintf("failed to create comment: %v", err),
+			"error": fmt.Sprintf("failed to delete comment: %v", err),
 		})
 		return
 	}
 
+	if comment.UserID != aid {
+		logModeration(r.Context(), h.spaceManager, ModerationEvent{
+			ActorAID:   aid,
+			Action:     ModerationActionDelete,
+			TargetType: "NoticeComment",
+			TargetID:   commentID,
+			Reason:     reason,
+		})
+	}
+
 	if h.eventBroker != nil {
 		h.eventBroker.Broadcast(SSEEvent{
-			Type: "notice_comment",
+			Type: EventNoticeCommentDeleted,
 			Data: map[string]interface{}{
 				"noticeId":  noticeID,
 				"commentId": commentID,
-				"userId":    aid,
+				"deletedBy": aid,
 			},
 		})
 	}
@@ -859,11 +2399,22 @@ func (h *NoticesHandler) HandleCreateComment(w http.ResponseWriter, r *http.Requ
 		"success":   true,
 		"noticeId":  noticeID,
 		"commentId": commentID,
-		"treeId":    treeID,
 	})
 }
 
+// NoticeCommentView is a comment as listed. Replies keep their
+// parentCommentId, and top-level comments carry the number of replies in
+// their thread.
+type NoticeCommentView struct {
+	*anysync.NoticeCommentPayload
+	ReplyCount *int `json:"replyCount,omitempty"`
+}
+
 // HandleListComments handles GET /api/v1/notices/{id}/comments.
+// Comments are listed flat, with replies pointing at their parent. Deleted
+// comments are omitted unless ?includeDeleted=true, in which case they are
+// returned as tombstones with their text and mentions removed; a deleted comment with
+// replies is always kept as a tombstone so its thread stays attached.
 func (h *NoticesHandler) HandleListComments(w http.ResponseWriter, r *http.Request, noticeID string) {
 	spaceID := h.spaceManager.GetCommunitySpaceID()
 	if spaceID == "" {
@@ -875,7 +2426,7 @@ func (h *NoticesHandler) HandleListComments(w http.ResponseWriter, r *http.Reque
 	}
 
 	noticeMgr := h.spaceManager.NoticeTreeManager()
-	comments, err := noticeMgr.ReadComments(r.Context(), spaceID, noticeID)
+	allComments, err := noticeMgr.ReadComments(r.Context(), spaceID, noticeID)
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{
 			"error": fmt.Sprintf("failed to read comments: %v", err),
@@ -883,9 +2434,53 @@ func (h *NoticesHandler) HandleListComments(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	// Count each live reply against the top of its thread, and note which
+	// comments have live replies under them
+	byID := make(map[string]*anysync.NoticeCommentPayload, len(allComments))
+	for _, c := range allComments {
+		byID[c.ID] = c
+	}
+	replyCounts := make(map[string]int)
+	hasReplies := make(map[string]bool)
+	for _, c := range allComments {
+		if c.DeletedAt != "" || c.ParentCommentID == "" {
+			continue
+		}
+		root := c
+		for steps := 0; root.ParentCommentID != "" && steps < len(byID); steps++ {
+			parent, ok := byID[root.ParentCommentID]
+			if !ok {
+				break
+			}
+			hasReplies[parent.ID] = true
+			root = parent
+		}
+		replyCounts[root.ID]++
+	}
+
+	includeDeleted := r.URL.Query().Get("includeDeleted") == "true"
+	comments := []NoticeCommentView{}
+	count := 0
+	for _, c := range allComments {
+		if c.DeletedAt != "" {
+			if !includeDeleted && !hasReplies[c.ID] {
+				continue
+			}
+			c.Text, c.Mentions = "", nil
+		} else {
+			count++
+		}
+		view := NoticeCommentView{NoticeCommentPayload: c}
+		if c.ParentCommentID == "" {
+			replies := replyCounts[c.ID]
+			view.ReplyCount = &replies
+		}
+		comments = append(comments, view)
+	}
+
 	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"comments": comments,
-		"count":    len(comments),
+		"count":    count,
 	})
 }
 
@@ -913,10 +2508,7 @@ func (h *NoticesHandler) HandleToggleReaction(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	aid := ""
-	if h.userIdentity != nil {
-		aid = h.userIdentity.GetAID()
-	}
+	aid := callerAID(r.Context(), h.userIdentity)
 	if aid == "" {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Identity not configured"})
 		return
@@ -963,10 +2555,11 @@ func (h *NoticesHandler) HandleToggleReaction(w http.ResponseWriter, r *http.Req
 		})
 		return
 	}
+	h.indexReaction(r.Context(), reaction)
 
 	if h.eventBroker != nil {
 		h.eventBroker.Broadcast(SSEEvent{
-			Type: "notice_reaction",
+			Type: EventNoticeReaction,
 			Data: map[string]interface{}{
 				"noticeId": noticeID,
 				"userId":   aid,
@@ -986,6 +2579,7 @@ func (h *NoticesHandler) HandleToggleReaction(w http.ResponseWriter, r *http.Req
 }
 
 // HandleListReactions handles GET /api/v1/notices/{id}/reactions.
+// Supports ?countsOnly=true to return only the counts per emoji.
 func (h *NoticesHandler) HandleListReactions(w http.ResponseWriter, r *http.Request, noticeID string) {
 	spaceID := h.spaceManager.GetCommunitySpaceID()
 	if spaceID == "" {
@@ -996,6 +2590,16 @@ func (h *NoticesHandler) HandleListReactions(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	countsOnly := r.URL.Query().Get("countsOnly") == "true"
+	if store := h.countsIndex(r, spaceID, countsOnly); store != nil {
+		counts, err := store.CountNoticeReactions(r.Context(), noticeID)
+		if err == nil {
+			writeJSON(w, http.StatusOK, map[string]interface{}{"counts": counts})
+			return
+		}
+		log.Printf("[Notices] Warning: counting reactions from the index failed, scanning: %v", err)
+	}
+
 	noticeMgr := h.spaceManager.NoticeTreeManager()
 	allReactions, err := noticeMgr.ReadReactions(r.Context(), spaceID, noticeID)
 	if err != nil {
@@ -1015,6 +2619,10 @@ func (h *NoticesHandler) HandleListReactions(w http.ResponseWriter, r *http.Requ
 		}
 	}
 
+	if countsOnly {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"counts": counts})
+		return
+	}
 	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"reactions": active,
 		"counts":    counts,
@@ -1027,6 +2635,10 @@ func (h *NoticesHandler) HandleTogglePin(w http.ResponseWriter, r *http.Request,
 		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
 		return
 	}
+	reason, ok := moderationReason(w, r)
+	if !ok {
+		return
+	}
 
 	spaceID := h.spaceManager.GetCommunitySpaceID()
 	if spaceID == "" {
@@ -1046,6 +2658,24 @@ func (h *NoticesHandler) HandleTogglePin(w http.ResponseWriter, r *http.Request,
 
 	newPinned := !notice.Pinned
 
+	// A new pin goes after the existing ones
+	pinOrder := 0
+	if newPinned {
+		notices, err := noticeMgr.ReadNotices(r.Context(), spaceID)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": fmt.Sprintf("failed to read notices: %v", err),
+			})
+			return
+		}
+		for _, n := range notices {
+			if n.Pinned && n.PinOrder > pinOrder {
+				pinOrder = n.PinOrder
+			}
+		}
+		pinOrder++
+	}
+
 	client := h.spaceManager.GetClient()
 	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), spaceID, client.GetSigningKey())
 	if err != nil {
@@ -1055,19 +2685,36 @@ func (h *NoticesHandler) HandleTogglePin(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
-	if err := noticeMgr.UpdateNoticePinned(r.Context(), spaceID, noticeID, newPinned, keys.SigningKey); err != nil {
+	if err := noticeMgr.UpdateNoticePinned(r.Context(), spaceID, noticeID, newPinned, pinOrder, keys.SigningKey); err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{
 			"error": fmt.Sprintf("failed to toggle pin: %v", err),
 		})
 		return
 	}
 
+	action := ModerationActionUnpin
+	if newPinned {
+		action = ModerationActionPin
+	}
+	logModeration(r.Context(), h.spaceManager, ModerationEvent{
+		ActorAID:   callerAID(r.Context(), h.userIdentity),
+		Action:     action,
+		TargetType: "Notice",
+		TargetID:   noticeID,
+		Reason:     reason,
+	})
+
 	if h.eventBroker != nil {
+		eventType := EventNoticeUnpinned
+		if newPinned {
+			eventType = EventNoticePinned
+		}
 		h.eventBroker.Broadcast(SSEEvent{
-			Type: "notice_published",
+			Type: eventType,
 			Data: map[string]interface{}{
 				"noticeId": noticeID,
 				"pinned":   newPinned,
+				"pinOrder": pinOrder,
 			},
 		})
 	}
@@ -1076,41 +2723,431 @@ func (h *NoticesHandler) HandleTogglePin(w http.ResponseWriter, r *http.Request,
 		"success":  true,
 		"noticeId": noticeID,
 		"pinned":   newPinned,
+		"pinOrder": pinOrder,
 	})
 }
 
-// sortNotices sorts notices based on the board view.
-func sortNotices(notices []*anysync.NoticePayload, view string) {
-	if len(notices) <= 1 {
+// ReorderPinsRequest sets the order of pinned notices.
+type ReorderPinsRequest struct {
+	NoticeIDs []string `json:"noticeIds"`
+}
+
+// HandleReorderPins handles PUT /api/v1/notices/pin-order. Admin only.
+// The listed notices, which must all be pinned, take pinOrder 1..n in the
+// given order. Pinned notices left out keep their relative order after them.
+// Each notice is written separately; if one fails, the error response lists
+// the notices already written under "written", which keep their new order.
+func (h *NoticesHandler) HandleReorderPins(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		return
+	}
+	if !isNoticeAdmin(r) {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "only admins can reorder pinned notices"})
 		return
 	}
 
-	// Simple insertion sort (sufficient for v1 scale)
-	for i := 1; i < len(notices); i++ {
-		for j := i; j > 0; j-- {
-			if shouldSwap(notices[j-1], notices[j], view) {
-				notices[j-1], notices[j] = notices[j], notices[j-1]
-			}
+	var req ReorderPinsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("invalid request: %v", err),
+		})
+		return
+	}
+	if len(req.NoticeIDs) == 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "noticeIds is required"})
+		return
+	}
+
+	spaceID := h.spaceManager.GetCommunitySpaceID()
+	if spaceID == "" {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "community space not configured"})
+		return
+	}
+
+	noticeMgr := h.spaceManager.NoticeTreeManager()
+	notices, err := noticeMgr.ReadNotices(r.Context(), spaceID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("failed to read notices: %v", err),
+		})
+		return
+	}
+
+	pinned := make(map[string]*anysync.NoticePayload)
+	var current []*anysync.NoticePayload
+	for _, n := range notices {
+		if n.Pinned {
+			pinned[n.ID] = n
+			current = append(current, n)
+		}
+	}
+	sortNotices(current, "")
+
+	order := make([]*anysync.NoticePayload, 0, len(current))
+	listed := make(map[string]bool, len(req.NoticeIDs))
+	for _, id := range req.NoticeIDs {
+		n, ok := pinned[id]
+		if !ok {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": fmt.Sprintf("notice %s is not pinned", id),
+			})
+			return
+		}
+		if listed[id] {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": fmt.Sprintf("notice %s is listed more than once", id),
+			})
+			return
+		}
+		listed[id] = true
+		order = append(order, n)
+	}
+	for _, n := range current {
+		if !listed[n.ID] {
+			order = append(order, n)
+		}
+	}
+
+	client := h.spaceManager.GetClient()
+	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), spaceID, client.GetSigningKey())
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("failed to load space keys: %v", err),
+		})
+		return
+	}
+
+	ids := make([]string, len(order))
+	for i, n := range order {
+		ids[i] = n.ID
+	}
+	written := []string{}
+	for i, n := range order {
+		if n.PinOrder == i+1 {
+			continue
+		}
+		if err := noticeMgr.UpdateNoticePinned(r.Context(), spaceID, n.ID, true, i+1, keys.SigningKey); err != nil {
+			// The notices already written keep their new pinOrder
+			writeJSON(w, http.StatusInternalServerError, map[string]interface{}{
+				"error":   fmt.Sprintf("failed to reorder pins at %s: %v", n.ID, err),
+				"written": written,
+			})
+			return
 		}
+		written = append(written, n.ID)
+	}
+
+	if h.eventBroker != nil {
+		h.eventBroker.Broadcast(SSEEvent{
+			Type: EventNoticePinsReordered,
+			Data: map[string]interface{}{
+				"noticeIds": ids,
+			},
+		})
 	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success":   true,
+		"noticeIds": ids,
+	})
+}
+
+// sortNotices sorts notices based on the board view. The sort is stable and
+// noticeLess defines a total order, so results are deterministic.
+func sortNotices(notices []*anysync.NoticePayload, view string) {
+	sort.SliceStable(notices, func(i, j int) bool {
+		return noticeLess(notices[i], notices[j], view)
+	})
 }
 
-// shouldSwap returns true if a should come after b in the sort order.
-func shouldSwap(a, b *anysync.NoticePayload, view string) bool {
+// noticeLess reports whether a sorts before b in the given board view. In
+// the default board view (no view) pinned notices come first, by pinOrder;
+// the other views order by their own key alone. Ties on the view's key fall
+// back to createdAt (newest first), then ID, so the ordering is total and
+// safe to paginate over.
+func noticeLess(a, b *anysync.NoticePayload, view string) bool {
+	if view == "" {
+		if ra, rb := pinRank(a), pinRank(b); ra != rb {
+			return ra < rb
+		}
+	}
 	switch view {
 	case "upcoming":
 		// Sort by eventStart ascending
-		return a.EventStart > b.EventStart
-	case "current":
+		if a.EventStart != b.EventStart {
+			return a.EventStart < b.EventStart
+		}
+	case "current", "past":
 		// Sort by publishAt descending (most recent first)
-		return a.PublishAt < b.PublishAt
-	case "past":
-		// Sort by publishAt descending
-		return a.PublishAt < b.PublishAt
-	default:
-		// Default: most recently created first
-		return a.CreatedAt < b.CreatedAt
+		if a.PublishAt != b.PublishAt {
+			return a.PublishAt > b.PublishAt
+		}
+	}
+	// Default: most recently created first
+	if a.CreatedAt != b.CreatedAt {
+		return a.CreatedAt > b.CreatedAt
+	}
+	return a.ID < b.ID
+}
+
+// pinRank places pinned notices by pinOrder ahead of unpinned ones. Pins
+// without an order, made before pinOrder existed, follow the ordered pins.
+func pinRank(n *anysync.NoticePayload) int {
+	switch {
+	case !n.Pinned:
+		return math.MaxInt
+	case n.PinOrder > 0:
+		return n.PinOrder
+	}
+	return math.MaxInt - 1
+}
+
+// noticeCursor is the decoded form of a notices list pagination cursor.
+// It captures the sort keys of the last notice on the previous page.
+type noticeCursor struct {
+	Pinned     bool   `json:"pn,omitempty"`
+	PinOrder   int    `json:"po,omitempty"`
+	EventStart string `json:"e,omitempty"`
+	PublishAt  string `json:"p,omitempty"`
+	CreatedAt  string `json:"c,omitempty"`
+	ID         string `json:"i"`
+}
+
+// encodeNoticeCursor returns an opaque cursor pointing just after n.
+func encodeNoticeCursor(n *anysync.NoticePayload) string {
+	data, _ := json.Marshal(noticeCursor{
+		Pinned:     n.Pinned,
+		PinOrder:   n.PinOrder,
+		EventStart: n.EventStart,
+		PublishAt:  n.PublishAt,
+		CreatedAt:  n.CreatedAt,
+		ID:         n.ID,
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeNoticeCursor parses a cursor produced by encodeNoticeCursor.
+func decodeNoticeCursor(cursor string) (*anysync.NoticePayload, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+	var c noticeCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	if c.ID == "" {
+		return nil, fmt.Errorf("cursor has no id")
+	}
+	return &anysync.NoticePayload{
+		ID:         c.ID,
+		Pinned:     c.Pinned,
+		PinOrder:   c.PinOrder,
+		EventStart: c.EventStart,
+		PublishAt:  c.PublishAt,
+		CreatedAt:  c.CreatedAt,
+	}, nil
+}
+
+// paginateNotices returns the page of sorted notices that follows after
+// (or the first page when after is nil), and the cursor for the next page.
+// limit <= 0 means no limit.
+func paginateNotices(sorted []*anysync.NoticePayload, view string, after *anysync.NoticePayload, limit int) ([]*anysync.NoticePayload, string) {
+	start := 0
+	if after != nil {
+		start = sort.Search(len(sorted), func(i int) bool {
+			return noticeLess(after, sorted[i], view)
+		})
+	}
+	page := sorted[start:]
+	if limit <= 0 || len(page) <= limit {
+		return page, ""
+	}
+	page = page[:limit]
+	return page, encodeNoticeCursor(page[len(page)-1])
+}
+
+// noticesPage is the envelope for a page of the notices list.
+func noticesPage(total, limit int, nextCursor string) ListPage {
+	return ListPage{Total: total, Limit: limit, NextCursor: nextCursor, HasMore: nextCursor != ""}
+}
+
+// validateEventTimes checks that event times are RFC3339, that the end does
+// not precede the start, and that the timezone is a known IANA zone. Empty
+// values are allowed.
+func validateEventTimes(eventStart, eventEnd, timezone string) error {
+	var start, end time.Time
+	var err error
+	if eventStart != "" {
+		if start, err = time.Parse(time.RFC3339, eventStart); err != nil {
+			return fmt.Errorf("eventStart must be an RFC3339 timestamp")
+		}
+	}
+	if eventEnd != "" {
+		if end, err = time.Parse(time.RFC3339, eventEnd); err != nil {
+			return fmt.Errorf("eventEnd must be an RFC3339 timestamp")
+		}
+	}
+	if !start.IsZero() && !end.IsZero() && end.Before(start) {
+		return fmt.Errorf("eventEnd must not be before eventStart")
+	}
+	if timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return fmt.Errorf("timezone %q is not a valid IANA timezone", timezone)
+		}
+	}
+	return nil
+}
+
+// recurrenceHorizon bounds how far ahead the upcoming view expands
+// recurring events.
+const recurrenceHorizon = 90 * 24 * time.Hour
+
+// maxOccurrences caps the occurrences expanded for a single recurring event.
+const maxOccurrences = 100
+
+// validateRecurrence checks a recurrence rule for a new notice.
+func validateRecurrence(rec *anysync.NoticeRecurrence, noticeType, eventStart string) error {
+	if noticeType != "event" {
+		return fmt.Errorf("recurrence is only supported for events")
+	}
+	start, err := time.Parse(time.RFC3339, eventStart)
+	if err != nil {
+		return fmt.Errorf("recurrence requires a valid eventStart")
+	}
+	if rec.Frequency != "weekly" && rec.Frequency != "monthly" {
+		return fmt.Errorf("recurrence.frequency must be 'weekly' or 'monthly'")
+	}
+	if rec.Interval < 0 {
+		return fmt.Errorf("recurrence.interval must not be negative")
+	}
+	if rec.Count < 0 {
+		return fmt.Errorf("recurrence.count must not be negative")
+	}
+	if rec.Count > 0 && rec.Until != "" {
+		return fmt.Errorf("recurrence may set count or until, not both")
+	}
+	if rec.Until != "" {
+		until, err := time.Parse(time.RFC3339, rec.Until)
+		if err != nil {
+			return fmt.Errorf("recurrence.until must be an RFC3339 timestamp")
+		}
+		if until.Before(start) {
+			return fmt.Errorf("recurrence.until must not be before eventStart")
+		}
+	}
+	return nil
+}
+
+// noticeOccurrences returns the start times of a recurring notice's
+// occurrences that begin within [from, to], in order. Occurrences are
+// computed in the notice's timezone so they keep their local wall-clock
+// time across DST changes. Monthly occurrences on days the month does not
+// have (e.g. the 31st) are skipped.
+func noticeOccurrences(n *anysync.NoticePayload, from, to time.Time) []time.Time {
+	rec := n.Recurrence
+	if rec == nil {
+		return nil
+	}
+	start, err := time.Parse(time.RFC3339, n.EventStart)
+	if err != nil {
+		return nil
+	}
+	if loc, err := time.LoadLocation(n.Timezone); n.Timezone != "" && err == nil {
+		start = start.In(loc)
+	}
+	var until time.Time
+	if rec.Until != "" {
+		if until, err = time.Parse(time.RFC3339, rec.Until); err != nil {
+			return nil
+		}
+	}
+	interval := max(rec.Interval, 1)
+
+	var out []time.Time
+	emitted := 0
+	for step := 0; ; step += interval {
+		var occ time.Time
+		switch rec.Frequency {
+		case "weekly":
+			occ = start.AddDate(0, 0, 7*step)
+		case "monthly":
+			occ = start.AddDate(0, step, 0)
+		default:
+			return out
+		}
+		if occ.After(to) || (!until.IsZero() && occ.After(until)) {
+			return out
+		}
+		if rec.Frequency == "monthly" && occ.Day() != start.Day() {
+			continue // month overflowed, e.g. Jan 31 + 1 month
+		}
+		if rec.Count > 0 && emitted >= rec.Count {
+			return out
+		}
+		emitted++
+		if !occ.Before(from) {
+			out = append(out, occ)
+			if len(out) >= maxOccurrences {
+				return out
+			}
+		}
+	}
+}
+
+// expandOccurrences returns virtual copies of a recurring notice, one per
+// occurrence within [from, to], with eventStart/eventEnd shifted and
+// occurrenceDate set.
+func expandOccurrences(n *anysync.NoticePayload, from, to time.Time) []*anysync.NoticePayload {
+	var duration time.Duration
+	if start, err := time.Parse(time.RFC3339, n.EventStart); err == nil {
+		if end, err := time.Parse(time.RFC3339, n.EventEnd); err == nil {
+			duration = end.Sub(start)
+		}
+	}
+
+	var out []*anysync.NoticePayload
+	for _, occ := range noticeOccurrences(n, from, to) {
+		instance := *n
+		instance.OccurrenceDate = occ.UTC().Format(time.RFC3339)
+		instance.EventStart = instance.OccurrenceDate
+		if n.EventEnd != "" {
+			instance.EventEnd = occ.Add(duration).UTC().Format(time.RFC3339)
+		}
+		out = append(out, &instance)
+	}
+	return out
+}
+
+// normalizeOccurrenceDate parses an occurrence date and returns it in the
+// canonical UTC RFC3339 form used in interaction object IDs.
+func normalizeOccurrenceDate(occurrenceDate string) (string, error) {
+	if occurrenceDate == "" {
+		return "", nil
+	}
+	t, err := time.Parse(time.RFC3339, occurrenceDate)
+	if err != nil {
+		return "", fmt.Errorf("occurrenceDate must be an RFC3339 timestamp")
+	}
+	return t.UTC().Format(time.RFC3339), nil
+}
+
+// resolveOccurrence validates that occurrenceDate is an occurrence of the
+// notice and returns it normalized.
+func resolveOccurrence(notice *anysync.NoticePayload, occurrenceDate string) (string, error) {
+	normalized, err := normalizeOccurrenceDate(occurrenceDate)
+	if err != nil {
+		return "", err
+	}
+	if notice.Recurrence == nil {
+		return "", fmt.Errorf("occurrenceDate is only valid for recurring events")
+	}
+	t, _ := time.Parse(time.RFC3339, normalized)
+	if len(noticeOccurrences(notice, t, t)) == 0 {
+		return "", fmt.Errorf("occurrenceDate is not an occurrence of this event")
 	}
+	return normalized, nil
 }
 
 func init() {
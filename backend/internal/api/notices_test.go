@@ -2,12 +2,156 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"slices"
+	"sort"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/anyproto/any-sync/commonspace/object/tree/objecttree"
+	"github.com/matou-dao/backend/internal/anysync"
+	"github.com/matou-dao/backend/internal/contributions"
+	"github.com/matou-dao/backend/internal/identity"
+	"github.com/matou-dao/backend/internal/types"
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	noticeTestAdminAID     = "ETEST_NOTICE_ADMIN"
+	noticeTestModeratorAID = "ETEST_NOTICE_MODERATOR"
 )
 
+// noticesTestEnv holds the test environment for notice handler tests.
+type noticesTestEnv struct {
+	spaceManager *anysync.SpaceManager
+	userIdentity *identity.UserIdentity
+	eventBroker  *EventBroker
+	handler      *NoticesHandler
+	mux          *http.ServeMux
+	cleanup      func()
+}
+
+// setupNoticesTestEnv builds a NoticesHandler backed by in-memory mock trees.
+// noticeTestAdminAID resolves to a Founding Member and noticeTestModeratorAID
+// to a Community Steward via the X-User-AID header.
+func setupNoticesTestEnv(t *testing.T) *noticesTestEnv {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+
+	tmpDir, err := os.MkdirTemp("", "notices_test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+
+	communitySpaceID := "space-community-notices-test"
+	privateSpaceID := "space-private-notices-test"
+	roSpaceID := "space-readonly-notices-test"
+
+	for _, spaceID := range []string{communitySpaceID, privateSpaceID, roSpaceID} {
+		keys, err := anysync.GenerateSpaceKeySet()
+		if err != nil {
+			os.RemoveAll(tmpDir)
+			t.Fatalf("generating keys: %v", err)
+		}
+		if err := anysync.PersistSpaceKeySet(tmpDir, spaceID, keys); err != nil {
+			os.RemoveAll(tmpDir)
+			t.Fatalf("persisting keys: %v", err)
+		}
+	}
+
+	anysyncClient := &mockAnySyncClientForChat{
+		mockAnySyncClientForIntegration: mockAnySyncClientForIntegration{
+			spaces: make(map[string]*anysync.SpaceCreateResult),
+		},
+		dataDir: tmpDir,
+	}
+
+	spaceManager := anysync.NewSpaceManager(anysyncClient, &anysync.SpaceManagerConfig{
+		CommunitySpaceID:         communitySpaceID,
+		CommunityReadOnlySpaceID: roSpaceID,
+		OrgAID:                   "EOrg_NoticesTest",
+	})
+
+	treeSeq := 0
+	makeFactory := func(c *gomock.Controller) anysync.TestTreeFactory {
+		return func(objectID string) objecttree.ObjectTree {
+			treeSeq++
+			state := &statefulMockTree{}
+			tree := setupStatefulMock(c, state)
+			treeID := fmt.Sprintf("tree-%d-%s", treeSeq, objectID)
+			tree.EXPECT().Id().Return(treeID).AnyTimes()
+			tree.EXPECT().Header().Return(nil).AnyTimes()
+			return tree
+		}
+	}
+	utm := spaceManager.TreeManager()
+	utm.SetTestTreeFactory(communitySpaceID, makeFactory(ctrl))
+	utm.SetTestTreeFactory(privateSpaceID, makeFactory(ctrl))
+	utm.SetTestTreeFactory(roSpaceID, makeFactory(ctrl))
+
+	userIdentity := identity.New(tmpDir)
+	userIdentity.SetIdentity("ETEST_NOTICE_USER01", "test-mnemonic")
+	userIdentity.SetPrivateSpaceID(privateSpaceID)
+
+	eventBroker := NewEventBroker()
+	handler := NewNoticesHandler(spaceManager, userIdentity, eventBroker)
+	roleLookup := &mockRoleLookup{roles: map[string][]contributions.Role{
+		noticeTestAdminAID:     contributions.MapKERIRole("Founding Member"),
+		noticeTestModeratorAID: contributions.MapKERIRole("Community Steward"),
+	}}
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux, roleLookup)
+
+	return &noticesTestEnv{
+		spaceManager: spaceManager,
+		userIdentity: userIdentity,
+		eventBroker:  eventBroker,
+		handler:      handler,
+		mux:          mux,
+		cleanup:      func() { os.RemoveAll(tmpDir) },
+	}
+}
+
+// do sends a JSON request through the notices mux. An optional caller AID
+// is sent as X-User-AID for role resolution.
+func (env *noticesTestEnv) do(t *testing.T, method, path string, body interface{}, callerAID ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	var reader *bytes.Reader
+	if body != nil {
+		b, _ := json.Marshal(body)
+		reader = bytes.NewReader(b)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if len(callerAID) > 0 {
+		req.Header.Set("X-User-AID", callerAID[0])
+	}
+	w := httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	return w
+}
+
+// createTestNotice creates a notice as the current identity and returns its ID.
+func createTestNotice(t *testing.T, env *noticesTestEnv, body map[string]interface{}) string {
+	t.Helper()
+	w := env.do(t, http.MethodPost, "/api/v1/notices", body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("failed to create notice: %d %s", w.Code, w.Body.String())
+	}
+	var resp map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&resp)
+	return resp["noticeId"].(string)
+}
+
 func TestHandleCreateNotice_Validation(t *testing.T) {
 	handler := &NoticesHandler{}
 
@@ -53,6 +197,31 @@ func TestHandleCreateNotice_Validation(t *testing.T) {
 			wantStatus: http.StatusBadRequest,
 			wantError:  "state must be 'draft' or 'published'",
 		},
+		{
+			name: "reversed event times",
+			body: map[string]string{"type": "event", "title": "Test", "summary": "Test",
+				"eventStart": "2026-03-01T12:00:00Z", "eventEnd": "2026-03-01T10:00:00Z"},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "eventEnd must not be before eventStart",
+		},
+		{
+			name:       "non-RFC3339 eventStart",
+			body:       map[string]string{"type": "event", "title": "Test", "summary": "Test", "eventStart": "next tuesday"},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "eventStart must be an RFC3339 timestamp",
+		},
+		{
+			name:       "non-RFC3339 eventEnd",
+			body:       map[string]string{"type": "event", "title": "Test", "summary": "Test", "eventEnd": "2026-03-01 10:00"},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "eventEnd must be an RFC3339 timestamp",
+		},
+		{
+			name:       "bogus timezone",
+			body:       map[string]string{"type": "event", "title": "Test", "summary": "Test", "timezone": "Mars/Olympus_Mons"},
+			wantStatus: http.StatusBadRequest,
+			wantError:  `timezone "Mars/Olympus_Mons" is not a valid IANA timezone`,
+		},
 		{
 			name:       "valid but no identity",
 			body:       map[string]string{"type": "event", "title": "Test", "summary": "Test"},
@@ -222,50 +391,104 @@ func TestHandleListSaved_NoPrivateSpace(t *testing.T) {
 }
 
 func TestSortNotices(t *testing.T) {
-	notices := []*noticePayloadForTest{
-		{EventStart: "2026-03-01T10:00:00Z", PublishAt: "2026-02-01T10:00:00Z", CreatedAt: "2026-01-01T10:00:00Z"},
-		{EventStart: "2026-02-01T10:00:00Z", PublishAt: "2026-03-01T10:00:00Z", CreatedAt: "2026-02-01T10:00:00Z"},
-		{EventStart: "2026-04-01T10:00:00Z", PublishAt: "2026-01-01T10:00:00Z", CreatedAt: "2026-03-01T10:00:00Z"},
+	ids := func(notices []*anysync.NoticePayload) []string {
+		out := make([]string, len(notices))
+		for i, n := range notices {
+			out[i] = n.ID
+		}
+		return out
 	}
 
-	// We can't directly test with anysync.NoticePayload without importing the package,
-	// but we can verify the shouldSwap logic
-	t.Run("upcoming sort ascending by eventStart", func(t *testing.T) {
-		if !shouldSwapTest("2026-03-01T10:00:00Z", "2026-02-01T10:00:00Z", "upcoming") {
-			t.Error("expected swap for upcoming: earlier eventStart should come first")
+	t.Run("upcoming ascending by eventStart", func(t *testing.T) {
+		notices := []*anysync.NoticePayload{
+			{ID: "c", EventStart: "2026-04-01T10:00:00Z", CreatedAt: "2026-01-01T10:00:00Z"},
+			{ID: "a", EventStart: "2026-02-01T10:00:00Z", CreatedAt: "2026-01-01T10:00:00Z"},
+			{ID: "b", EventStart: "2026-03-01T10:00:00Z", CreatedAt: "2026-01-01T10:00:00Z"},
 		}
-		if shouldSwapTest("2026-02-01T10:00:00Z", "2026-03-01T10:00:00Z", "upcoming") {
-			t.Error("should not swap: already in correct order")
+		sortNotices(notices, "upcoming")
+		if got := fmt.Sprint(ids(notices)); got != "[a b c]" {
+			t.Errorf("order = %s, want [a b c]", got)
 		}
 	})
 
-	t.Run("current sort descending by publishAt", func(t *testing.T) {
-		if !shouldSwapTest("2026-01-01T10:00:00Z", "2026-03-01T10:00:00Z", "current") {
-			t.Error("expected swap for current: more recent publishAt should come first")
+	for _, view := range []string{"current", "past"} {
+		t.Run(view+" descending by publishAt", func(t *testing.T) {
+			notices := []*anysync.NoticePayload{
+				{ID: "b", PublishAt: "2026-02-01T10:00:00Z", CreatedAt: "2026-01-01T10:00:00Z"},
+				{ID: "c", PublishAt: "2026-01-01T10:00:00Z", CreatedAt: "2026-01-01T10:00:00Z"},
+				{ID: "a", PublishAt: "2026-03-01T10:00:00Z", CreatedAt: "2026-01-01T10:00:00Z"},
+			}
+			sortNotices(notices, view)
+			if got := fmt.Sprint(ids(notices)); got != "[a b c]" {
+				t.Errorf("order = %s, want [a b c]", got)
+			}
+		})
+	}
+
+	t.Run("ties broken by createdAt descending then ID", func(t *testing.T) {
+		for _, view := range []string{"upcoming", "current", "past"} {
+			notices := []*anysync.NoticePayload{
+				{ID: "old", EventStart: "2026-03-01T10:00:00Z", PublishAt: "2026-02-01T10:00:00Z", CreatedAt: "2026-01-01T10:00:00Z"},
+				{ID: "new-b", EventStart: "2026-03-01T10:00:00Z", PublishAt: "2026-02-01T10:00:00Z", CreatedAt: "2026-01-05T10:00:00Z"},
+				{ID: "new-a", EventStart: "2026-03-01T10:00:00Z", PublishAt: "2026-02-01T10:00:00Z", CreatedAt: "2026-01-05T10:00:00Z"},
+			}
+			sortNotices(notices, view)
+			if got := fmt.Sprint(ids(notices)); got != "[new-a new-b old]" {
+				t.Errorf("%s: order = %s, want [new-a new-b old]", view, got)
+			}
 		}
 	})
 
-	// Suppress unused warning
-	_ = notices
-}
+	t.Run("primary key wins over createdAt", func(t *testing.T) {
+		notices := []*anysync.NoticePayload{
+			{ID: "late-created", EventStart: "2026-02-01T10:00:00Z", CreatedAt: "2026-01-09T10:00:00Z"},
+			{ID: "early-created", EventStart: "2026-01-15T10:00:00Z", CreatedAt: "2026-01-01T10:00:00Z"},
+		}
+		sortNotices(notices, "upcoming")
+		if got := fmt.Sprint(ids(notices)); got != "[early-created late-created]" {
+			t.Errorf("order = %s, want [early-created late-created]", got)
+		}
+	})
 
-// noticePayloadForTest is a test helper
-type noticePayloadForTest struct {
-	EventStart string
-	PublishAt  string
-	CreatedAt  string
-}
+	t.Run("order independent of input", func(t *testing.T) {
+		forward := []*anysync.NoticePayload{
+			{ID: "x", PublishAt: "2026-02-01T10:00:00Z", CreatedAt: "2026-01-01T10:00:00Z"},
+			{ID: "y", PublishAt: "2026-02-01T10:00:00Z", CreatedAt: "2026-01-01T10:00:00Z"},
+			{ID: "z", PublishAt: "2026-02-01T10:00:00Z", CreatedAt: "2026-01-02T10:00:00Z"},
+		}
+		reversed := []*anysync.NoticePayload{forward[2], forward[1], forward[0]}
+		sortNotices(forward, "current")
+		sortNotices(reversed, "current")
+		if a, b := fmt.Sprint(ids(forward)), fmt.Sprint(ids(reversed)); a != b {
+			t.Errorf("order depends on input: %s vs %s", a, b)
+		}
+	})
 
-// shouldSwapTest tests the comparison logic directly with string timestamps.
-func shouldSwapTest(aTime, bTime, view string) bool {
-	switch view {
-	case "upcoming":
-		return aTime > bTime
-	case "current", "past":
-		return aTime < bTime
-	default:
-		return aTime < bTime
-	}
+	t.Run("pinned first by pinOrder on the default board", func(t *testing.T) {
+		notices := []*anysync.NoticePayload{
+			{ID: "newest", CreatedAt: "2026-01-09T10:00:00Z"},
+			{ID: "pin-2", Pinned: true, PinOrder: 2, CreatedAt: "2026-01-02T10:00:00Z"},
+			{ID: "pin-legacy", Pinned: true, CreatedAt: "2026-01-08T10:00:00Z"},
+			{ID: "pin-1", Pinned: true, PinOrder: 1, CreatedAt: "2026-01-01T10:00:00Z"},
+		}
+		sortNotices(notices, "")
+		if got := fmt.Sprint(ids(notices)); got != "[pin-1 pin-2 pin-legacy newest]" {
+			t.Errorf("order = %s, want [pin-1 pin-2 pin-legacy newest]", got)
+		}
+	})
+
+	t.Run("pins ignored in other views", func(t *testing.T) {
+		for _, view := range []string{"upcoming", "past"} {
+			notices := []*anysync.NoticePayload{
+				{ID: "pinned", Pinned: true, PinOrder: 1, EventStart: "2026-03-01T10:00:00Z", PublishAt: "2026-01-01T10:00:00Z", CreatedAt: "2026-01-01T10:00:00Z"},
+				{ID: "first", EventStart: "2026-01-01T10:00:00Z", PublishAt: "2026-03-01T10:00:00Z", CreatedAt: "2026-01-01T10:00:00Z"},
+			}
+			sortNotices(notices, view)
+			if got := fmt.Sprint(ids(notices)); got != "[first pinned]" {
+				t.Errorf("%s: order = %s, want [first pinned]", view, got)
+			}
+		}
+	})
 }
 
 func TestHandlePublishNotice_MethodNotAllowed(t *testing.T) {
@@ -397,3 +620,1558 @@ func TestHandleToggleReaction_Validation(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleUpdateNotice_Owner(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	noticeID := createTestNotice(t, env, map[string]interface{}{
+		"id": "edit-owner", "type": "announcement", "title": "Original", "summary": "Original summary",
+	})
+
+	events := env.eventBroker.Subscribe()
+	defer env.eventBroker.Unsubscribe(events)
+
+	w := env.do(t, http.MethodPut, "/api/v1/notices/"+noticeID, map[string]interface{}{
+		"title": "Edited title",
+		"body":  "New body",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Version  int                   `json:"version"`
+		EditedAt string                `json:"editedAt"`
+		Notice   anysync.NoticePayload `json:"notice"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Notice.Title != "Edited title" || resp.Notice.Body != "New body" {
+		t.Errorf("edit not applied: title=%q body=%q", resp.Notice.Title, resp.Notice.Body)
+	}
+	if resp.Notice.Summary != "Original summary" {
+		t.Errorf("unedited field changed: summary=%q", resp.Notice.Summary)
+	}
+	if resp.Version != 2 {
+		t.Errorf("version = %d, want 2", resp.Version)
+	}
+	if resp.EditedAt == "" {
+		t.Error("expected non-empty editedAt")
+	}
+
+	select {
+	case ev := <-events:
+		if ev.Type != "notice_updated" {
+			t.Errorf("event type = %q, want notice_updated", ev.Type)
+		}
+	default:
+		t.Error("expected notice_updated event")
+	}
+}
+
+func TestHandleTogglePin_Events(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	noticeID := createTestNotice(t, env, map[string]interface{}{
+		"id": "pin-events", "type": "announcement", "title": "T", "summary": "S",
+	})
+	events := env.eventBroker.Subscribe()
+	defer env.eventBroker.Unsubscribe(events)
+
+	for _, want := range []string{EventNoticePinned, EventNoticeUnpinned} {
+		w := env.do(t, http.MethodPost, "/api/v1/notices/"+noticeID+"/pin", nil, noticeTestAdminAID)
+		if w.Code != http.StatusOK {
+			t.Fatalf("toggle pin: expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		select {
+		case ev := <-events:
+			if ev.Type != want {
+				t.Errorf("event type = %q, want %q", ev.Type, want)
+			}
+		default:
+			t.Errorf("expected %s event", want)
+		}
+	}
+	if len(events) > 0 {
+		t.Errorf("expected no other events, got %q", (<-events).Type)
+	}
+}
+
+// listNoticeIDs returns the notice IDs from GET /api/v1/notices plus query.
+func listNoticeIDs(t *testing.T, env *noticesTestEnv, query string) []string {
+	t.Helper()
+	w := env.do(t, http.MethodGet, "/api/v1/notices"+query, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("list notices: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Notices []anysync.NoticePayload `json:"notices"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	ids := make([]string, len(resp.Notices))
+	for i, n := range resp.Notices {
+		ids[i] = n.ID
+	}
+	return ids
+}
+
+func TestHandleReorderPins(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	for _, id := range []string{"pin-a", "pin-b", "pin-c", "unpinned"} {
+		createTestNotice(t, env, map[string]interface{}{
+			"id": id, "type": "announcement", "title": id, "summary": "S",
+		})
+	}
+	for _, id := range []string{"pin-a", "pin-b", "pin-c"} {
+		if w := env.do(t, http.MethodPost, "/api/v1/notices/"+id+"/pin", nil, noticeTestAdminAID); w.Code != http.StatusOK {
+			t.Fatalf("pin %s: expected 200, got %d: %s", id, w.Code, w.Body.String())
+		}
+	}
+
+	// Pins keep the order they were made in, ahead of the newer unpinned notice
+	if got := fmt.Sprint(listNoticeIDs(t, env, "")); got != "[pin-a pin-b pin-c unpinned]" {
+		t.Errorf("after pinning: order = %s, want [pin-a pin-b pin-c unpinned]", got)
+	}
+
+	events := env.eventBroker.Subscribe()
+	defer env.eventBroker.Unsubscribe(events)
+
+	w := env.do(t, http.MethodPut, "/api/v1/notices/pin-order", map[string]interface{}{
+		"noticeIds": []string{"pin-c", "pin-a"},
+	}, noticeTestAdminAID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("reorder: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	select {
+	case ev := <-events:
+		if ev.Type != EventNoticePinsReordered {
+			t.Errorf("event type = %q, want %q", ev.Type, EventNoticePinsReordered)
+		}
+	default:
+		t.Error("expected notice_pins_reordered event")
+	}
+
+	// Unlisted pins follow the listed ones
+	if got := fmt.Sprint(listNoticeIDs(t, env, "")); got != "[pin-c pin-a pin-b unpinned]" {
+		t.Errorf("after reorder: order = %s, want [pin-c pin-a pin-b unpinned]", got)
+	}
+	if got := fmt.Sprint(listNoticeIDs(t, env, "?limit=1")); got != "[pin-c]" {
+		t.Errorf("first page = %s, want [pin-c]", got)
+	}
+
+	// Unpinning drops a notice back among the unpinned; pinning again adds it last
+	for range 2 {
+		env.do(t, http.MethodPost, "/api/v1/notices/pin-c/pin", nil, noticeTestAdminAID)
+	}
+	if got := fmt.Sprint(listNoticeIDs(t, env, "")); got != "[pin-a pin-b pin-c unpinned]" {
+		t.Errorf("after re-pinning: order = %s, want [pin-a pin-b pin-c unpinned]", got)
+	}
+}
+
+func TestHandleReorderPins_ReportsPartialWrite(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	for _, id := range []string{"pin-a", "pin-b"} {
+		createTestNotice(t, env, map[string]interface{}{
+			"id": id, "type": "announcement", "title": id, "summary": "S",
+		})
+	}
+	// pin-c's tree fails to write once the node goes down
+	var down atomic.Bool
+	ctrl := gomock.NewController(t)
+	env.spaceManager.TreeManager().SetTestTreeFactory(env.spaceManager.GetCommunitySpaceID(), func(objectID string) objecttree.ObjectTree {
+		tree := setupStatefulMock(ctrl, &statefulMockTree{})
+		tree.EXPECT().Id().Return("flaky-tree-" + objectID).AnyTimes()
+		tree.EXPECT().Header().Return(nil).AnyTimes()
+		return &flakyTree{ObjectTree: tree, down: &down}
+	})
+	createTestNotice(t, env, map[string]interface{}{
+		"id": "pin-c", "type": "announcement", "title": "pin-c", "summary": "S",
+	})
+	for _, id := range []string{"pin-a", "pin-b", "pin-c"} {
+		if w := env.do(t, http.MethodPost, "/api/v1/notices/"+id+"/pin", nil, noticeTestAdminAID); w.Code != http.StatusOK {
+			t.Fatalf("pin %s: expected 200, got %d: %s", id, w.Code, w.Body.String())
+		}
+	}
+	down.Store(true)
+
+	w := env.do(t, http.MethodPut, "/api/v1/notices/pin-order", map[string]interface{}{
+		"noticeIds": []string{"pin-b", "pin-c", "pin-a"},
+	}, noticeTestAdminAID)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Written []string `json:"written"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if fmt.Sprint(resp.Written) != "[pin-b]" {
+		t.Errorf("written = %v, want [pin-b]", resp.Written)
+	}
+}
+
+func TestHandleReorderPins_Rejects(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	for _, id := range []string{"pinned", "unpinned"} {
+		createTestNotice(t, env, map[string]interface{}{
+			"id": id, "type": "announcement", "title": id, "summary": "S",
+		})
+	}
+	env.do(t, http.MethodPost, "/api/v1/notices/pinned/pin", nil, noticeTestAdminAID)
+
+	tests := []struct {
+		name   string
+		ids    []string
+		caller string
+		want   int
+	}{
+		{"non-admin", []string{"pinned"}, noticeTestModeratorAID, http.StatusForbidden},
+		{"empty", nil, noticeTestAdminAID, http.StatusBadRequest},
+		{"unpinned notice", []string{"pinned", "unpinned"}, noticeTestAdminAID, http.StatusBadRequest},
+		{"duplicate", []string{"pinned", "pinned"}, noticeTestAdminAID, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := env.do(t, http.MethodPut, "/api/v1/notices/pin-order", map[string]interface{}{"noticeIds": tt.ids}, tt.caller)
+			if w.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleUpdateNotice_NonOwnerForbidden(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	noticeID := createTestNotice(t, env, map[string]interface{}{
+		"id": "edit-forbidden", "type": "announcement", "title": "Mine", "summary": "Mine",
+	})
+
+	env.userIdentity.SetIdentity("EOTHER_NOTICE_USER", "other-mnemonic")
+
+	w := env.do(t, http.MethodPut, "/api/v1/notices/"+noticeID, map[string]interface{}{"title": "Hacked"}, "EOTHER_NOTICE_USER")
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleUpdateNotice_AdminCanEdit(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	noticeID := createTestNotice(t, env, map[string]interface{}{
+		"id": "edit-admin", "type": "announcement", "title": "Member notice", "summary": "Summary",
+	})
+
+	env.userIdentity.SetIdentity(noticeTestAdminAID, "admin-mnemonic")
+
+	w := env.do(t, http.MethodPut, "/api/v1/notices/"+noticeID, map[string]interface{}{"title": "Moderated"}, noticeTestAdminAID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleUpdateNotice_PublishedEventStartInPast(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	future := time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339)
+	noticeID := createTestNotice(t, env, map[string]interface{}{
+		"id": "edit-past", "type": "event", "title": "Hui", "summary": "Hui",
+		"state": "published", "eventStart": future,
+	})
+
+	past := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	w := env.do(t, http.MethodPut, "/api/v1/notices/"+noticeID, map[string]interface{}{"eventStart": past})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// createTestComment posts a comment as the current identity and returns its ID.
+func createTestComment(t *testing.T, env *noticesTestEnv, noticeID, text string) string {
+	t.Helper()
+	w := env.do(t, http.MethodPost, "/api/v1/notices/"+noticeID+"/comments", map[string]string{"text": text})
+	if w.Code != http.StatusOK {
+		t.Fatalf("failed to create comment: %d %s", w.Code, w.Body.String())
+	}
+	var resp map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&resp)
+	return resp["commentId"].(string)
+}
+
+// listTestComments returns the comments list response for a notice.
+func listTestComments(t *testing.T, env *noticesTestEnv, path string) (comments []anysync.NoticeCommentPayload, count int) {
+	t.Helper()
+	w := env.do(t, http.MethodGet, path, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("failed to list comments: %d %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Comments []anysync.NoticeCommentPayload `json:"comments"`
+		Count    int                            `json:"count"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	return resp.Comments, resp.Count
+}
+
+func TestHandleDeleteComment_Author(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	noticeID := createTestNotice(t, env, map[string]interface{}{
+		"id": "comment-author", "type": "announcement", "title": "T", "summary": "S",
+	})
+	commentID := createTestComment(t, env, noticeID, "typo")
+
+	events := env.eventBroker.Subscribe()
+	defer env.eventBroker.Unsubscribe(events)
+
+	w := env.do(t, http.MethodDelete, "/api/v1/notices/"+noticeID+"/comments/"+commentID, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	select {
+	case ev := <-events:
+		if ev.Type != "notice_comment_deleted" {
+			t.Errorf("event type = %q, want notice_comment_deleted", ev.Type)
+		}
+	default:
+		t.Error("expected notice_comment_deleted event")
+	}
+
+	comments, count := listTestComments(t, env, "/api/v1/notices/"+noticeID+"/comments")
+	if count != 0 || len(comments) != 0 {
+		t.Errorf("expected deleted comment to be omitted, got count=%d len=%d", count, len(comments))
+	}
+
+	comments, _ = listTestComments(t, env, "/api/v1/notices/"+noticeID+"/comments?includeDeleted=true")
+	if len(comments) != 1 {
+		t.Fatalf("expected 1 tombstone, got %d", len(comments))
+	}
+	if comments[0].DeletedAt == "" || comments[0].Text != "" {
+		t.Errorf("expected tombstone with deletedAt and no text, got %+v", comments[0])
+	}
+}
+
+func TestHandleDeleteComment_Moderator(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	noticeID := createTestNotice(t, env, map[string]interface{}{
+		"id": "comment-moderator", "type": "announcement", "title": "T", "summary": "S",
+	})
+	commentID := createTestComment(t, env, noticeID, "abusive")
+
+	env.userIdentity.SetIdentity(noticeTestModeratorAID, "moderator-mnemonic")
+
+	w := env.do(t, http.MethodDelete, "/api/v1/notices/"+noticeID+"/comments/"+commentID, nil, noticeTestModeratorAID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	comments, _ := listTestComments(t, env, "/api/v1/notices/"+noticeID+"/comments?includeDeleted=true")
+	if len(comments) != 1 || comments[0].DeletedBy != noticeTestModeratorAID {
+		t.Errorf("expected comment deleted by moderator, got %+v", comments)
+	}
+}
+
+func TestHandleDeleteComment_Forbidden(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	noticeID := createTestNotice(t, env, map[string]interface{}{
+		"id": "comment-forbidden", "type": "announcement", "title": "T", "summary": "S",
+	})
+	commentID := createTestComment(t, env, noticeID, "mine")
+
+	env.userIdentity.SetIdentity("EOTHER_NOTICE_USER", "other-mnemonic")
+
+	w := env.do(t, http.MethodDelete, "/api/v1/notices/"+noticeID+"/comments/"+commentID, nil, "EOTHER_NOTICE_USER")
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d: %s", w.Code, w.Body.String())
+	}
+
+	_, count := listTestComments(t, env, "/api/v1/notices/"+noticeID+"/comments")
+	if count != 1 {
+		t.Errorf("expected comment to remain, count = %d", count)
+	}
+}
+
+// replyTestComment posts a reply as the current identity and returns the response.
+func replyTestComment(t *testing.T, env *noticesTestEnv, noticeID, parentID, text string) *httptest.ResponseRecorder {
+	t.Helper()
+	return env.do(t, http.MethodPost, "/api/v1/notices/"+noticeID+"/comments", map[string]string{"text": text, "parentCommentId": parentID})
+}
+
+func TestHandleCreateComment_Reply(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	noticeID := createTestNotice(t, env, map[string]interface{}{
+		"id": "comment-reply", "type": "announcement", "title": "T", "summary": "S",
+	})
+	createTestComment(t, env, noticeID, "first")
+	second := createTestComment(t, env, noticeID, "second")
+
+	secondID := "Comment-" + noticeID + "-" + second
+
+	env.userIdentity.SetIdentity("EREPLY_NOTICE_USER", "reply-mnemonic")
+	events := env.eventBroker.SubscribeAs("ETEST_NOTICE_USER01")
+	defer env.eventBroker.Unsubscribe(events)
+	others := env.eventBroker.SubscribeAs("EOTHER_NOTICE_USER")
+	defer env.eventBroker.Unsubscribe(others)
+
+	w := replyTestComment(t, env, noticeID, second, "reply to second")
+	if w.Code != http.StatusOK {
+		t.Fatalf("reply: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var created map[string]interface{}
+	json.NewDecoder(w.Body).Decode(&created)
+	reply := created["commentId"].(string)
+	if w := replyTestComment(t, env, noticeID, reply, "nested reply"); w.Code != http.StatusOK {
+		t.Fatalf("nested reply: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var replyEvent *SSEEvent
+	for len(events) > 0 {
+		if ev := <-events; ev.Type == "notice_comment_reply" && replyEvent == nil {
+			replyEvent = &ev
+		}
+	}
+	if replyEvent == nil {
+		t.Fatal("expected a notice_comment_reply event")
+	}
+	if data := replyEvent.Data.(map[string]interface{}); data["parentUserId"] != "ETEST_NOTICE_USER01" || data["parentCommentId"] != secondID {
+		t.Errorf("unexpected reply event %v", data)
+	}
+	for len(others) > 0 {
+		if ev := <-others; ev.Type == "notice_comment_reply" {
+			t.Error("expected the reply event only for the parent's author")
+		}
+	}
+
+	w = env.do(t, http.MethodGet, "/api/v1/notices/"+noticeID+"/comments", nil)
+	var resp struct {
+		Comments []NoticeCommentView `json:"comments"`
+		Count    int                 `json:"count"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Count != 4 {
+		t.Fatalf("expected 4 comments, got %d", resp.Count)
+	}
+	byText := make(map[string]NoticeCommentView)
+	for _, c := range resp.Comments {
+		byText[c.Text] = c
+	}
+	if got := byText["reply to second"].ParentCommentID; got != secondID {
+		t.Errorf("reply attached to %q, want the second comment", got)
+	}
+	if got := byText["nested reply"].ParentCommentID; got != byText["reply to second"].ID {
+		t.Errorf("nested reply attached to %q, want %q", got, byText["reply to second"].ID)
+	}
+	if c := byText["second"]; c.ReplyCount == nil || *c.ReplyCount != 2 {
+		t.Errorf("expected 2 replies under the second comment, got %v", c.ReplyCount)
+	}
+	if c := byText["first"]; c.ReplyCount == nil || *c.ReplyCount != 0 {
+		t.Errorf("expected no replies under the first comment, got %v", c.ReplyCount)
+	}
+	if byText["nested reply"].ReplyCount != nil {
+		t.Error("expected replies not to carry a reply count")
+	}
+}
+
+func TestHandleCreateComment_MaxDepth(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	noticeID := createTestNotice(t, env, map[string]interface{}{
+		"id": "comment-depth", "type": "announcement", "title": "T", "summary": "S",
+	})
+	parent := createTestComment(t, env, noticeID, "depth 0")
+	for depth := 1; depth <= MaxCommentDepth; depth++ {
+		w := replyTestComment(t, env, noticeID, parent, fmt.Sprintf("depth %d", depth))
+		if w.Code != http.StatusOK {
+			t.Fatalf("reply at depth %d: expected 200, got %d: %s", depth, w.Code, w.Body.String())
+		}
+		var resp map[string]interface{}
+		json.NewDecoder(w.Body).Decode(&resp)
+		parent = resp["commentId"].(string)
+	}
+
+	if w := replyTestComment(t, env, noticeID, parent, "too deep"); w.Code != http.StatusBadRequest {
+		t.Errorf("reply past max depth: expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := replyTestComment(t, env, noticeID, "no-such-comment", "orphan"); w.Code != http.StatusNotFound {
+		t.Errorf("reply to missing comment: expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+	if _, count := listTestComments(t, env, "/api/v1/notices/"+noticeID+"/comments"); count != MaxCommentDepth+1 {
+		t.Errorf("expected %d comments, got %d", MaxCommentDepth+1, count)
+	}
+}
+
+func TestHandleCreateComment_Mentions(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	noticeID := createTestNotice(t, env, map[string]interface{}{
+		"id": "comment-mention", "type": "announcement", "title": "T", "summary": "S",
+	})
+	mentioned := env.eventBroker.SubscribeAs("EMENTIONED_USER")
+	defer env.eventBroker.Unsubscribe(mentioned)
+	author := env.eventBroker.SubscribeAs("ETEST_NOTICE_USER01")
+	defer env.eventBroker.Unsubscribe(author)
+
+	w := env.do(t, http.MethodPost, "/api/v1/notices/"+noticeID+"/comments", map[string]interface{}{
+		"text":     "hey @mentioned",
+		"mentions": []string{"EMENTIONED_USER", " EMENTIONED_USER ", "ETEST_NOTICE_USER01"},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var mentionEvents int
+	for len(mentioned) > 0 {
+		if ev := <-mentioned; ev.Type == EventNoticeCommentMention {
+			mentionEvents++
+		}
+	}
+	if mentionEvents != 1 {
+		t.Errorf("expected one mention event for the mentioned member, got %d", mentionEvents)
+	}
+	for len(author) > 0 {
+		if ev := <-author; ev.Type == EventNoticeCommentMention {
+			t.Error("expected the author not to be told of mentioning themselves")
+		}
+	}
+
+	comments, _ := listTestComments(t, env, "/api/v1/notices/"+noticeID+"/comments")
+	if len(comments) != 1 || !slices.Equal(comments[0].Mentions, []string{"EMENTIONED_USER"}) {
+		t.Errorf("expected the comment to keep the deduped mention, got %+v", comments)
+	}
+
+	tooMany := make([]string, maxCommentMentions+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("EMEMBER%02d", i)
+	}
+	w = env.do(t, http.MethodPost, "/api/v1/notices/"+noticeID+"/comments", map[string]interface{}{"text": "all", "mentions": tooMany})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("too many mentions: expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleCreateComment_UnresolvedThread(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	noticeID := createTestNotice(t, env, map[string]interface{}{
+		"id": "comment-unresolved", "type": "announcement", "title": "T", "summary": "S",
+	})
+
+	// A reply whose own parent hasn't synced to this node
+	spaceID := env.spaceManager.GetCommunitySpaceID()
+	client := env.spaceManager.GetClient()
+	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), spaceID, client.GetSigningKey())
+	if err != nil {
+		t.Fatalf("loading space keys: %v", err)
+	}
+	orphan := &anysync.NoticeCommentPayload{
+		ID:              "orphan",
+		NoticeID:        noticeID,
+		ParentCommentID: "Comment-" + noticeID + "-unsynced",
+		UserID:          "ETEST_NOTICE_USER01",
+		Text:            "orphan",
+		CreatedAt:       time.Now().UTC().Format(time.RFC3339),
+	}
+	if _, err := env.spaceManager.NoticeTreeManager().CreateComment(context.Background(), spaceID, orphan, keys.SigningKey); err != nil {
+		t.Fatalf("creating orphan comment: %v", err)
+	}
+
+	if w := replyTestComment(t, env, noticeID, "orphan", "reply"); w.Code != http.StatusConflict {
+		t.Errorf("reply under an unresolved thread: expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// rsvpAs switches the current identity and posts an RSVP, returning the response.
+func rsvpAs(t *testing.T, env *noticesTestEnv, aid, noticeID, status string) *httptest.ResponseRecorder {
+	t.Helper()
+	env.userIdentity.SetIdentity(aid, "mnemonic-"+aid)
+	return env.do(t, http.MethodPost, "/api/v1/notices/"+noticeID+"/rsvp", map[string]string{"status": status})
+}
+
+// rsvpCounts returns the status counts from GET /api/v1/notices/{id}/rsvp.
+func rsvpCounts(t *testing.T, env *noticesTestEnv, noticeID string) map[string]int {
+	t.Helper()
+	w := env.do(t, http.MethodGet, "/api/v1/notices/"+noticeID+"/rsvp", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("failed to list RSVPs: %d %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Counts map[string]int `json:"counts"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	return resp.Counts
+}
+
+func TestHandleCreateRSVP_Capacity(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	noticeID := createTestNotice(t, env, map[string]interface{}{
+		"id": "rsvp-capacity", "type": "event", "title": "Hui", "summary": "Hui",
+		"state": "published", "rsvpEnabled": true, "rsvpCapacity": 2,
+	})
+
+	t.Run("fill to capacity", func(t *testing.T) {
+		for _, aid := range []string{"EUSER_A", "EUSER_B"} {
+			if w := rsvpAs(t, env, aid, noticeID, "going"); w.Code != http.StatusOK {
+				t.Fatalf("%s: expected 200, got %d: %s", aid, w.Code, w.Body.String())
+			}
+		}
+		if got := rsvpCounts(t, env, noticeID)["going"]; got != 2 {
+			t.Errorf("going = %d, want 2", got)
+		}
+	})
+
+	t.Run("reject when full with waitlist position", func(t *testing.T) {
+		for i, aid := range []string{"EUSER_C", "EUSER_D"} {
+			w := rsvpAs(t, env, aid, noticeID, "going")
+			if w.Code != http.StatusConflict {
+				t.Fatalf("%s: expected 409, got %d: %s", aid, w.Code, w.Body.String())
+			}
+			var resp map[string]interface{}
+			json.NewDecoder(w.Body).Decode(&resp)
+			if resp["status"] != "waitlisted" {
+				t.Errorf("%s: status = %v, want waitlisted", aid, resp["status"])
+			}
+			if pos := int(resp["waitlistPosition"].(float64)); pos != i+1 {
+				t.Errorf("%s: waitlistPosition = %d, want %d", aid, pos, i+1)
+			}
+		}
+		counts := rsvpCounts(t, env, noticeID)
+		if counts["going"] != 2 || counts["waitlisted"] != 2 {
+			t.Errorf("counts = %v, want going=2 waitlisted=2", counts)
+		}
+	})
+
+	t.Run("promote on not_going", func(t *testing.T) {
+		if w := rsvpAs(t, env, "EUSER_A", noticeID, "not_going"); w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		counts := rsvpCounts(t, env, noticeID)
+		if counts["going"] != 2 || counts["waitlisted"] != 1 || counts["not_going"] != 1 {
+			t.Errorf("counts = %v, want going=2 waitlisted=1 not_going=1", counts)
+		}
+
+		w := env.do(t, http.MethodGet, "/api/v1/notices/"+noticeID+"/rsvp", nil)
+		var resp struct {
+			RSVPs []anysync.NoticeRSVPPayload `json:"rsvps"`
+		}
+		json.NewDecoder(w.Body).Decode(&resp)
+		for _, r := range resp.RSVPs {
+			if r.UserID == "EUSER_C" && r.Status != "going" {
+				t.Errorf("EUSER_C status = %q, want going (first on waitlist)", r.Status)
+			}
+			if r.UserID == "EUSER_D" && r.Status != "waitlisted" {
+				t.Errorf("EUSER_D status = %q, want waitlisted", r.Status)
+			}
+		}
+	})
+}
+
+func TestHandleListRSVPs_LatestPerUser(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	noticeID := createTestNotice(t, env, map[string]interface{}{
+		"id": "rsvp-dedupe", "type": "event", "title": "Hui", "summary": "Hui", "state": "published",
+	})
+
+	if w := rsvpAs(t, env, "EUSER_A", noticeID, "maybe"); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Simulate a stale duplicate RSVP object for the same user arriving from
+	// another device, followed by the user's latest "going" response.
+	spaceID := env.spaceManager.GetCommunitySpaceID()
+	tree, _, err := env.spaceManager.TreeManager().CreateObjectTree(
+		context.Background(), spaceID, "RSVP-"+noticeID+"-EUSER_A-device2", "NoticeRSVP", anysync.InteractionTreeType, nil)
+	if err != nil {
+		t.Fatalf("creating duplicate RSVP tree: %v", err)
+	}
+	fields := map[string]json.RawMessage{}
+	for k, v := range map[string]string{
+		"noticeId": noticeID, "userId": "EUSER_A", "status": "maybe",
+		"updatedAt": time.Now().Add(-time.Minute).UTC().Format(time.RFC3339),
+	} {
+		fields[k], _ = json.Marshal(v)
+	}
+	data, _ := json.Marshal(anysync.InitChange(fields))
+	tree.AddContent(context.Background(), objecttree.SignableChangeContent{Data: data, DataType: anysync.ObjectChangeType})
+
+	if w := rsvpAs(t, env, "EUSER_A", noticeID, "going"); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w := env.do(t, http.MethodGet, "/api/v1/notices/"+noticeID+"/rsvp", nil)
+	var resp struct {
+		RSVPs  []anysync.NoticeRSVPPayload `json:"rsvps"`
+		Count  int                         `json:"count"`
+		Counts map[string]int              `json:"counts"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+
+	if resp.Counts["going"] != 1 || resp.Counts["maybe"] != 0 {
+		t.Errorf("counts = %v, want going=1 maybe=0", resp.Counts)
+	}
+	if resp.Count != 1 || len(resp.RSVPs) != 1 || resp.RSVPs[0].Status != "going" {
+		t.Errorf("expected a single effective 'going' RSVP, got %+v", resp.RSVPs)
+	}
+}
+
+func TestHandleListNotices_PaginateFilteredView(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	base := time.Now().Add(24 * time.Hour).UTC()
+	// Events created out of eventStart order, plus an update that the
+	// upcoming view must filter out.
+	for _, offset := range []int{3, 1, 4, 0, 2} {
+		createTestNotice(t, env, map[string]interface{}{
+			"id": fmt.Sprintf("event-%d", offset), "type": "event", "title": "E", "summary": "S",
+			"state": "published", "eventStart": base.Add(time.Duration(offset) * time.Hour).Format(time.RFC3339),
+		})
+	}
+	createTestNotice(t, env, map[string]interface{}{
+		"id": "update-1", "type": "update", "title": "U", "summary": "S", "state": "published",
+	})
+
+	var got []string
+	cursor := ""
+	for page := 0; page < 5; page++ {
+		path := "/api/v1/notices?view=upcoming&type=event&limit=2"
+		if cursor != "" {
+			path += "&cursor=" + cursor
+		}
+		w := env.do(t, http.MethodGet, path, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("page %d: expected 200, got %d: %s", page, w.Code, w.Body.String())
+		}
+		var resp struct {
+			Notices    []anysync.NoticePayload `json:"notices"`
+			Total      int                     `json:"total"`
+			NextCursor string                  `json:"nextCursor"`
+		}
+		json.NewDecoder(w.Body).Decode(&resp)
+		if resp.Total != 5 {
+			t.Errorf("page %d: total = %d, want 5", page, resp.Total)
+		}
+		if len(resp.Notices) > 2 {
+			t.Errorf("page %d: got %d notices, want at most 2", page, len(resp.Notices))
+		}
+		for _, n := range resp.Notices {
+			got = append(got, n.ID)
+		}
+		cursor = resp.NextCursor
+		if cursor == "" {
+			break
+		}
+	}
+
+	want := []string{"event-0", "event-1", "event-2", "event-3", "event-4"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("paged IDs = %v, want %v", got, want)
+	}
+}
+
+func TestHandleListNotices_InvalidPagination(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	for _, path := range []string{"/api/v1/notices?limit=0", "/api/v1/notices?limit=abc", "/api/v1/notices?cursor=not-a-cursor!"} {
+		if w := env.do(t, http.MethodGet, path, nil); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", path, w.Code)
+		}
+	}
+}
+
+func TestHandleListNotices_CurrentViewActiveWindow(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	now := time.Now().UTC()
+	at := func(d time.Duration) string { return now.Add(d).Format(time.RFC3339) }
+	announce := func(id, activeFrom, activeUntil string) {
+		createTestNotice(t, env, map[string]interface{}{
+			"id": id, "type": "announcement", "title": id, "summary": id, "state": "published",
+			"activeFrom": activeFrom, "activeUntil": activeUntil,
+		})
+	}
+	announce("scheduled", at(24*time.Hour), at(48*time.Hour))
+	announce("active", at(-time.Hour), at(time.Hour))
+	announce("expired", at(-48*time.Hour), at(-24*time.Hour))
+	announce("open-ended", "", "")
+
+	w := env.do(t, http.MethodGet, "/api/v1/notices?view=current", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Notices []anysync.NoticePayload `json:"notices"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	var got []string
+	for _, n := range resp.Notices {
+		got = append(got, n.ID)
+	}
+	sort.Strings(got)
+	if fmt.Sprint(got) != "[active open-ended]" {
+		t.Errorf("current view = %v, want [active open-ended]", got)
+	}
+}
+
+func TestNoticeCurrent(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		notice anysync.NoticePayload
+		want   bool
+	}{
+		{"published without window", anysync.NoticePayload{State: "published"}, true},
+		{"draft", anysync.NoticePayload{State: "draft"}, false},
+		{"publish scheduled", anysync.NoticePayload{State: "published", PublishAt: "2026-05-02T00:00:00Z"}, false},
+		{"published earlier", anysync.NoticePayload{State: "published", PublishAt: "2026-04-30T00:00:00Z"}, true},
+		{"active from the future", anysync.NoticePayload{State: "published", ActiveFrom: "2026-05-01T12:00:01Z"}, false},
+		{"inside window", anysync.NoticePayload{State: "published", ActiveFrom: "2026-05-01T00:00:00Z", ActiveUntil: "2026-05-02T00:00:00Z"}, true},
+		{"expired", anysync.NoticePayload{State: "published", ActiveUntil: "2026-05-01T11:59:59Z"}, false},
+		{"unparseable times ignored", anysync.NoticePayload{State: "published", ActiveFrom: "soon", ActiveUntil: "later"}, true},
+	}
+	for _, tt := range tests {
+		if got := noticeCurrent(&tt.notice, now); got != tt.want {
+			t.Errorf("%s: noticeCurrent = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNoticeOccurrences_Weekly(t *testing.T) {
+	n := &anysync.NoticePayload{
+		EventStart: "2026-03-02T18:00:00Z",
+		Recurrence: &anysync.NoticeRecurrence{Frequency: "weekly"},
+	}
+	from := time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	var got []string
+	for _, occ := range noticeOccurrences(n, from, to) {
+		got = append(got, occ.UTC().Format(time.RFC3339))
+	}
+	want := []string{"2026-03-09T18:00:00Z", "2026-03-16T18:00:00Z", "2026-03-23T18:00:00Z", "2026-03-30T18:00:00Z"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("occurrences = %v, want %v", got, want)
+	}
+
+	t.Run("interval and count", func(t *testing.T) {
+		n := &anysync.NoticePayload{
+			EventStart: "2026-03-02T18:00:00Z",
+			Recurrence: &anysync.NoticeRecurrence{Frequency: "weekly", Interval: 2, Count: 3},
+		}
+		got := noticeOccurrences(n, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC))
+		if len(got) != 3 {
+			t.Fatalf("got %d occurrences, want 3", len(got))
+		}
+		if last := got[2].UTC().Format(time.RFC3339); last != "2026-03-30T18:00:00Z" {
+			t.Errorf("last occurrence = %s, want 2026-03-30T18:00:00Z", last)
+		}
+	})
+
+	t.Run("keeps local time across DST", func(t *testing.T) {
+		if _, err := time.LoadLocation("Pacific/Auckland"); err != nil {
+			t.Skip("timezone data not available")
+		}
+		// NZ daylight saving ends on 2026-04-05: 18:00 NZDT is 05:00Z, 18:00 NZST is 06:00Z.
+		n := &anysync.NoticePayload{
+			EventStart: "2026-03-30T05:00:00Z",
+			Timezone:   "Pacific/Auckland",
+			Recurrence: &anysync.NoticeRecurrence{Frequency: "weekly", Count: 2},
+		}
+		got := noticeOccurrences(n, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC))
+		if len(got) != 2 || got[1].UTC().Format(time.RFC3339) != "2026-04-06T06:00:00Z" {
+			t.Errorf("occurrences = %v, want second at 2026-04-06T06:00:00Z", got)
+		}
+	})
+}
+
+func TestNoticeOccurrences_UntilCutoff(t *testing.T) {
+	n := &anysync.NoticePayload{
+		EventStart: "2026-03-02T18:00:00Z",
+		Recurrence: &anysync.NoticeRecurrence{Frequency: "weekly", Until: "2026-03-16T18:00:00Z"},
+	}
+	got := noticeOccurrences(n, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC))
+	if len(got) != 3 {
+		t.Fatalf("got %d occurrences, want 3 (until is inclusive)", len(got))
+	}
+	if last := got[2].UTC().Format(time.RFC3339); last != "2026-03-16T18:00:00Z" {
+		t.Errorf("last occurrence = %s, want 2026-03-16T18:00:00Z", last)
+	}
+}
+
+func TestNoticeOccurrences_MonthlySkipsShortMonths(t *testing.T) {
+	n := &anysync.NoticePayload{
+		EventStart: "2026-01-31T09:00:00Z",
+		Recurrence: &anysync.NoticeRecurrence{Frequency: "monthly", Count: 3},
+	}
+	var got []string
+	for _, occ := range noticeOccurrences(n, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		got = append(got, occ.UTC().Format("2006-01-02"))
+	}
+	want := []string{"2026-01-31", "2026-03-31", "2026-05-31"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("occurrences = %v, want %v", got, want)
+	}
+}
+
+func TestHandleCreateNotice_RecurrenceValidation(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	start := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	tests := []struct {
+		name string
+		body map[string]interface{}
+	}{
+		{"not an event", map[string]interface{}{"type": "update", "eventStart": start,
+			"recurrence": map[string]interface{}{"frequency": "weekly"}}},
+		{"missing eventStart", map[string]interface{}{"type": "event",
+			"recurrence": map[string]interface{}{"frequency": "weekly"}}},
+		{"bad frequency", map[string]interface{}{"type": "event", "eventStart": start,
+			"recurrence": map[string]interface{}{"frequency": "hourly"}}},
+		{"count and until", map[string]interface{}{"type": "event", "eventStart": start,
+			"recurrence": map[string]interface{}{"frequency": "weekly", "count": 3, "until": start}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.body["title"] = "T"
+			tt.body["summary"] = "S"
+			if w := env.do(t, http.MethodPost, "/api/v1/notices", tt.body); w.Code != http.StatusBadRequest {
+				t.Errorf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleListNotices_RecurringUpcoming(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	start := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	noticeID := createTestNotice(t, env, map[string]interface{}{
+		"id": "weekly-hui", "type": "event", "title": "Weekly hui", "summary": "S", "state": "published",
+		"eventStart": start.Format(time.RFC3339), "eventEnd": start.Add(time.Hour).Format(time.RFC3339),
+		"rsvpEnabled": true,
+		"recurrence":  map[string]interface{}{"frequency": "weekly", "until": start.Add(15 * 24 * time.Hour).Format(time.RFC3339)},
+	})
+
+	w := env.do(t, http.MethodGet, "/api/v1/notices?view=upcoming", nil)
+	var resp struct {
+		Notices []anysync.NoticePayload `json:"notices"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if len(resp.Notices) != 3 {
+		t.Fatalf("expected 3 occurrences before until cutoff, got %d", len(resp.Notices))
+	}
+	for i, n := range resp.Notices {
+		wantStart := start.AddDate(0, 0, 7*i).Format(time.RFC3339)
+		if n.ID != noticeID || n.EventStart != wantStart || n.OccurrenceDate != wantStart {
+			t.Errorf("occurrence %d = {id %s, start %s, occ %s}, want start %s", i, n.ID, n.EventStart, n.OccurrenceDate, wantStart)
+		}
+		if wantEnd := start.AddDate(0, 0, 7*i).Add(time.Hour).Format(time.RFC3339); n.EventEnd != wantEnd {
+			t.Errorf("occurrence %d eventEnd = %s, want %s", i, n.EventEnd, wantEnd)
+		}
+	}
+
+	t.Run("RSVP attaches to an occurrence", func(t *testing.T) {
+		second := resp.Notices[1].OccurrenceDate
+		w := env.do(t, http.MethodPost, "/api/v1/notices/"+noticeID+"/rsvp",
+			map[string]string{"status": "going", "occurrenceDate": second})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		w = env.do(t, http.MethodGet, "/api/v1/notices/"+noticeID+"/rsvp?occurrenceDate="+second, nil)
+		var list struct {
+			RSVPs []anysync.NoticeRSVPPayload `json:"rsvps"`
+		}
+		json.NewDecoder(w.Body).Decode(&list)
+		if len(list.RSVPs) != 1 || list.RSVPs[0].OccurrenceDate != second {
+			t.Errorf("expected one RSVP for occurrence %s, got %+v", second, list.RSVPs)
+		}
+		if counts := rsvpCounts(t, env, noticeID); counts["going"] != 0 {
+			t.Errorf("RSVP to an occurrence should not count toward the series, got %v", counts)
+		}
+	})
+
+	t.Run("ack attaches to an occurrence", func(t *testing.T) {
+		first := resp.Notices[0].OccurrenceDate
+		if w := env.do(t, http.MethodPost, "/api/v1/notices/"+noticeID+"/ack",
+			map[string]string{"occurrenceDate": first}); w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		w := env.do(t, http.MethodGet, "/api/v1/notices/"+noticeID+"/ack?occurrenceDate="+first, nil)
+		var list struct {
+			Count int `json:"count"`
+		}
+		json.NewDecoder(w.Body).Decode(&list)
+		if list.Count != 1 {
+			t.Errorf("expected 1 ack for occurrence, got %d", list.Count)
+		}
+	})
+
+	t.Run("unknown occurrence rejected", func(t *testing.T) {
+		bogus := start.Add(time.Hour).Format(time.RFC3339)
+		w := env.do(t, http.MethodPost, "/api/v1/notices/"+noticeID+"/rsvp",
+			map[string]string{"status": "going", "occurrenceDate": bogus})
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
+
+// seedCommunityMember writes a CommunityProfile for aid into the read-only space.
+func seedCommunityMember(t *testing.T, env *noticesTestEnv, aid, role, status string) {
+	t.Helper()
+	roSpaceID := env.spaceManager.GetCommunityReadOnlySpaceID()
+	client := env.spaceManager.GetClient()
+	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), roSpaceID, client.GetSigningKey())
+	if err != nil {
+		t.Fatalf("loading readonly keys: %v", err)
+	}
+	data, _ := json.Marshal(map[string]string{"userAID": aid, "role": role, "status": status})
+	if _, err := env.spaceManager.ObjectTreeManager().AddObject(context.Background(), roSpaceID, &anysync.ObjectPayload{
+		ID:   "CommunityProfile-" + aid,
+		Type: "CommunityProfile",
+		Data: data,
+	}, keys.SigningKey); err != nil {
+		t.Fatalf("seeding CommunityProfile for %s: %v", aid, err)
+	}
+}
+
+// ackAs acknowledges a notice as the given identity.
+func ackAs(t *testing.T, env *noticesTestEnv, aid, noticeID string) {
+	t.Helper()
+	env.userIdentity.SetIdentity(aid, "mnemonic-"+aid)
+	if w := env.do(t, http.MethodPost, "/api/v1/notices/"+noticeID+"/ack", nil); w.Code != http.StatusOK {
+		t.Fatalf("ack as %s: %d %s", aid, w.Code, w.Body.String())
+	}
+}
+
+func TestHandleListPendingAcks(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	seedCommunityMember(t, env, "EMEMBER_A", "Member", "")
+	seedCommunityMember(t, env, "EMEMBER_B", "Member", "")
+	seedCommunityMember(t, env, "EMEMBER_C", "Member", "removed")
+	seedCommunityMember(t, env, "EMEMBER_D", "Community Steward", "")
+
+	noticeID := createTestNotice(t, env, map[string]interface{}{
+		"id": "ack-pending", "type": "announcement", "title": "Policy", "summary": "Read me",
+		"state": "published", "ackRequired": true,
+		"ackDueAt": time.Now().Add(-time.Hour).UTC().Format(time.RFC3339),
+	})
+	ackAs(t, env, "EMEMBER_A", noticeID)
+	ackAs(t, env, "EMEMBER_D", noticeID)
+
+	t.Run("non-admin forbidden", func(t *testing.T) {
+		w := env.do(t, http.MethodGet, "/api/v1/notices/"+noticeID+"/ack/pending", nil, "EMEMBER_A")
+		if w.Code != http.StatusForbidden {
+			t.Errorf("expected 403, got %d", w.Code)
+		}
+	})
+
+	t.Run("admin sees outstanding members", func(t *testing.T) {
+		w := env.do(t, http.MethodGet, "/api/v1/notices/"+noticeID+"/ack/pending", nil, noticeTestAdminAID)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp struct {
+			Pending     []PendingAckMember `json:"pending"`
+			Count       int                `json:"count"`
+			MemberCount int                `json:"memberCount"`
+			Overdue     bool               `json:"overdue"`
+		}
+		json.NewDecoder(w.Body).Decode(&resp)
+		if resp.Count != 1 || len(resp.Pending) != 1 || resp.Pending[0].AID != "EMEMBER_B" {
+			t.Errorf("pending = %+v, want only EMEMBER_B", resp.Pending)
+		}
+		if resp.MemberCount != 3 {
+			t.Errorf("memberCount = %d, want 3 (removed member excluded)", resp.MemberCount)
+		}
+		if !resp.Overdue {
+			t.Error("expected overdue = true past the due date")
+		}
+	})
+
+	t.Run("notice without ack requirement", func(t *testing.T) {
+		otherID := createTestNotice(t, env, map[string]interface{}{
+			"id": "ack-not-required", "type": "update", "title": "FYI", "summary": "S", "state": "published",
+		})
+		w := env.do(t, http.MethodGet, "/api/v1/notices/"+otherID+"/ack/pending", nil, noticeTestAdminAID)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400, got %d", w.Code)
+		}
+	})
+}
+
+func TestHandleBulkAck(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	seedCommunityMember(t, env, "EMEMBER_A", "Member", "")
+	seedCommunityMember(t, env, "EMEMBER_B", "Member", "")
+	seedCommunityMember(t, env, "EMEMBER_C", "Member", "")
+
+	noticeID := createTestNotice(t, env, map[string]interface{}{
+		"id": "ack-bulk", "type": "announcement", "title": "Policy", "summary": "Read me",
+		"state": "published", "ackRequired": true,
+	})
+	ackAs(t, env, "EMEMBER_A", noticeID)
+	path := "/api/v1/notices/" + noticeID + "/ack/bulk"
+
+	t.Run("non-admin forbidden", func(t *testing.T) {
+		w := env.do(t, http.MethodPost, path, map[string]interface{}{"aids": []string{"EMEMBER_B"}}, "EMEMBER_A")
+		if w.Code != http.StatusForbidden {
+			t.Errorf("expected 403, got %d", w.Code)
+		}
+	})
+
+	t.Run("empty list rejected", func(t *testing.T) {
+		w := env.do(t, http.MethodPost, path, map[string]interface{}{"aids": []string{}}, noticeTestAdminAID)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("admin records acks without duplicating existing ones", func(t *testing.T) {
+		w := env.do(t, http.MethodPost, path, map[string]interface{}{
+			"aids": []string{"EMEMBER_A", "EMEMBER_B", "EMEMBER_B", "ESTRANGER"},
+		}, noticeTestAdminAID)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp struct {
+			Created      []string `json:"created"`
+			AlreadyAcked []string `json:"alreadyAcked"`
+			NotMembers   []string `json:"notMembers"`
+		}
+		json.NewDecoder(w.Body).Decode(&resp)
+		if len(resp.Created) != 1 || resp.Created[0] != "EMEMBER_B" {
+			t.Errorf("created = %v, want [EMEMBER_B]", resp.Created)
+		}
+		if len(resp.AlreadyAcked) != 1 || resp.AlreadyAcked[0] != "EMEMBER_A" {
+			t.Errorf("alreadyAcked = %v, want [EMEMBER_A]", resp.AlreadyAcked)
+		}
+		if len(resp.NotMembers) != 1 || resp.NotMembers[0] != "ESTRANGER" {
+			t.Errorf("notMembers = %v, want [ESTRANGER]", resp.NotMembers)
+		}
+
+		// Repeating the bulk ack creates nothing new
+		w = env.do(t, http.MethodPost, path, map[string]interface{}{"aids": []string{"EMEMBER_B"}}, noticeTestAdminAID)
+		json.NewDecoder(w.Body).Decode(&resp)
+		if len(resp.Created) != 0 {
+			t.Errorf("expected no new acks on repeat, got %v", resp.Created)
+		}
+
+		acks, err := env.spaceManager.NoticeTreeManager().ReadAcks(context.Background(), env.spaceManager.GetCommunitySpaceID(), noticeID)
+		if err != nil {
+			t.Fatalf("reading acks: %v", err)
+		}
+		if len(acks) != 2 {
+			t.Fatalf("expected 2 acks, got %d", len(acks))
+		}
+		for _, ack := range acks {
+			switch ack.UserID {
+			case "EMEMBER_A":
+				if ack.Method != "explicit" || ack.RecordedBy != "" {
+					t.Errorf("self ack = %+v, want explicit with no recorder", ack)
+				}
+			case "EMEMBER_B":
+				if ack.Method != "admin" || ack.RecordedBy != noticeTestAdminAID {
+					t.Errorf("admin ack = %+v, want admin recorded by %s", ack, noticeTestAdminAID)
+				}
+			}
+		}
+	})
+
+	t.Run("summary reports progress by method", func(t *testing.T) {
+		w := env.do(t, http.MethodGet, "/api/v1/notices/"+noticeID+"/ack/summary", nil, noticeTestAdminAID)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var summary AckSummary
+		json.NewDecoder(w.Body).Decode(&summary)
+		if summary.Acked != 2 || summary.Total != 3 || summary.Pending != 1 {
+			t.Errorf("summary = %+v, want 2 of 3 acked", summary)
+		}
+		if summary.AckedPercent != 66.7 {
+			t.Errorf("ackedPercent = %v, want 66.7", summary.AckedPercent)
+		}
+		if summary.ByMethod["explicit"] != 1 || summary.ByMethod["admin"] != 1 {
+			t.Errorf("byMethod = %v, want 1 explicit and 1 admin", summary.ByMethod)
+		}
+	})
+}
+
+func TestAckPercent(t *testing.T) {
+	tests := []struct {
+		acked, total int
+		want         float64
+	}{
+		{0, 0, 0},
+		{0, 4, 0},
+		{1, 3, 33.3},
+		{2, 3, 66.7},
+		{1, 8, 12.5},
+		{5, 5, 100},
+	}
+	for _, tt := range tests {
+		if got := ackPercent(tt.acked, tt.total); got != tt.want {
+			t.Errorf("ackPercent(%d, %d) = %v, want %v", tt.acked, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestCheckOverdueAcks_DueBoundary(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	seedCommunityMember(t, env, "EMEMBER_A", "Member", "")
+	seedCommunityMember(t, env, "EMEMBER_B", "Member", "")
+
+	due := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	for _, id := range []string{"ack-outstanding", "ack-complete"} {
+		createTestNotice(t, env, map[string]interface{}{
+			"id": id, "type": "announcement", "title": id, "summary": "S",
+			"state": "published", "ackRequired": true, "ackDueAt": due.Format(time.RFC3339),
+		})
+	}
+	ackAs(t, env, "EMEMBER_A", "ack-outstanding")
+	ackAs(t, env, "EMEMBER_A", "ack-complete")
+	ackAs(t, env, "EMEMBER_B", "ack-complete")
+
+	ch := env.eventBroker.Subscribe()
+	defer env.eventBroker.Unsubscribe(ch)
+	drain := func() []SSEEvent {
+		var events []SSEEvent
+		for {
+			select {
+			case e := <-ch:
+				if e.Type == "notice_ack_overdue" {
+					events = append(events, e)
+				}
+			default:
+				return events
+			}
+		}
+	}
+	drain()
+
+	ctx := context.Background()
+	env.handler.CheckOverdueAcks(ctx, due.Add(-time.Second))
+	if events := drain(); len(events) != 0 {
+		t.Fatalf("expected no overdue events before the due date, got %d", len(events))
+	}
+
+	env.handler.CheckOverdueAcks(ctx, due)
+	events := drain()
+	if len(events) != 1 {
+		t.Fatalf("expected 1 overdue event at the due date, got %d", len(events))
+	}
+	data := events[0].Data.(map[string]interface{})
+	if data["noticeId"] != "ack-outstanding" || data["pendingCount"] != 1 {
+		t.Errorf("event data = %v, want ack-outstanding with 1 pending", data)
+	}
+
+	env.handler.CheckOverdueAcks(ctx, due.Add(time.Minute))
+	if events := drain(); len(events) != 0 {
+		t.Errorf("expected overdue event to fire once, got %d more", len(events))
+	}
+}
+
+func TestPublishScheduledNotices(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	createTestNotice(t, env, map[string]interface{}{
+		"id": "scheduled-past", "type": "announcement", "title": "Past", "summary": "S",
+		"publishAt": now.Add(-time.Minute).Format(time.RFC3339),
+	})
+	createTestNotice(t, env, map[string]interface{}{
+		"id": "scheduled-future", "type": "announcement", "title": "Future", "summary": "S",
+		"publishAt": now.Add(time.Hour).Format(time.RFC3339),
+	})
+	createTestNotice(t, env, map[string]interface{}{
+		"id": "plain-draft", "type": "announcement", "title": "Draft", "summary": "S",
+	})
+
+	// A member's node leaves publishing to the community owner's
+	if published := env.handler.PublishScheduledNotices(context.Background(), now); len(published) != 0 {
+		t.Fatalf("member node published %v", published)
+	}
+	env.spaceManager.SetOrgAID(env.userIdentity.GetAID())
+
+	ch := env.eventBroker.Subscribe()
+	defer env.eventBroker.Unsubscribe(ch)
+
+	published := env.handler.PublishScheduledNotices(context.Background(), now)
+	if fmt.Sprint(published) != "[scheduled-past]" {
+		t.Fatalf("published = %v, want [scheduled-past]", published)
+	}
+
+	select {
+	case e := <-ch:
+		if e.Type != "notice_published" || e.Data.(map[string]interface{})["noticeId"] != "scheduled-past" {
+			t.Errorf("unexpected event %+v", e)
+		}
+	default:
+		t.Error("expected a notice_published event")
+	}
+
+	noticeMgr := env.spaceManager.NoticeTreeManager()
+	spaceID := env.spaceManager.GetCommunitySpaceID()
+	for id, want := range map[string]string{"scheduled-past": "published", "scheduled-future": "draft", "plain-draft": "draft"} {
+		notice, err := noticeMgr.ReadNotice(context.Background(), spaceID, id)
+		if err != nil {
+			t.Fatalf("reading %s: %v", id, err)
+		}
+		if notice.State != want {
+			t.Errorf("%s state = %s, want %s", id, notice.State, want)
+		}
+	}
+
+	// Already published notices aren't published again
+	if again := env.handler.PublishScheduledNotices(context.Background(), now); len(again) != 0 {
+		t.Errorf("expected nothing left to publish, got %v", again)
+	}
+}
+
+func TestNoticeLifecycleTransitions(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	noticeID := createTestNotice(t, env, map[string]interface{}{
+		"id": "lifecycle", "type": "announcement", "title": "Lifecycle", "summary": "S",
+	})
+	get := func() NoticeDetail {
+		t.Helper()
+		w := env.do(t, http.MethodGet, "/api/v1/notices/"+noticeID, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("get notice: %d %s", w.Code, w.Body.String())
+		}
+		var detail NoticeDetail
+		json.NewDecoder(w.Body).Decode(&detail)
+		return detail
+	}
+	step := func(action string, wantStatus int, wantState string) {
+		t.Helper()
+		if w := env.do(t, http.MethodPost, "/api/v1/notices/"+noticeID+"/"+action, nil); w.Code != wantStatus {
+			t.Fatalf("%s: expected %d, got %d: %s", action, wantStatus, w.Code, w.Body.String())
+		}
+		detail := get()
+		if detail.State != wantState {
+			t.Fatalf("after %s: state = %s, want %s", action, detail.State, wantState)
+		}
+		if fmt.Sprint(detail.AllowedTransitions) != fmt.Sprint(types.NoticeTransitionsFrom(wantState)) {
+			t.Errorf("after %s: allowedTransitions = %v", action, detail.AllowedTransitions)
+		}
+	}
+
+	if got := get().AllowedTransitions; fmt.Sprint(got) != "[published]" {
+		t.Errorf("draft allowedTransitions = %v, want [published]", got)
+	}
+
+	step("archive", http.StatusBadRequest, "draft")   // drafts can't be archived
+	step("unpublish", http.StatusBadRequest, "draft") // already a draft
+	step("publish", http.StatusOK, "published")
+	step("publish", http.StatusBadRequest, "published")
+
+	step("unpublish", http.StatusOK, "draft")
+	if detail := get(); detail.PublishedAt != "" || detail.PublishAt != "" {
+		t.Errorf("expected unpublishing to clear the publish times, got %q/%q", detail.PublishedAt, detail.PublishAt)
+	}
+	env.spaceManager.SetOrgAID(env.userIdentity.GetAID())
+	if again := env.handler.PublishScheduledNotices(context.Background(), time.Now()); len(again) != 0 {
+		t.Errorf("expected an unpublished notice to stay draft, published %v", again)
+	}
+
+	step("publish", http.StatusOK, "published")
+	step("archive", http.StatusOK, "archived")
+	step("unpublish", http.StatusBadRequest, "archived")
+	step("archive", http.StatusBadRequest, "archived")
+
+	step("publish", http.StatusOK, "published") // republish
+	if detail := get(); detail.ArchivedAt != "" || detail.PublishedAt == "" {
+		t.Errorf("expected republishing to clear archivedAt, got archivedAt=%q publishedAt=%q", detail.ArchivedAt, detail.PublishedAt)
+	}
+}
+
+func TestPublishNotice_AheadOfSchedule(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	noticeID := createTestNotice(t, env, map[string]interface{}{
+		"id": "early", "type": "announcement", "title": "Early", "summary": "S",
+		"publishAt": time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339),
+	})
+	if w := env.do(t, http.MethodPost, "/api/v1/notices/"+noticeID+"/publish", nil); w.Code != http.StatusOK {
+		t.Fatalf("publish: %d %s", w.Code, w.Body.String())
+	}
+
+	// Publishing by hand moves publishAt up, so the notice is current now
+	w := env.do(t, http.MethodGet, "/api/v1/notices?view=current", nil)
+	var resp struct {
+		Count int `json:"count"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Count != 1 {
+		t.Errorf("expected the early-published notice in the current view, got %s", w.Body.String())
+	}
+}
+
+func TestHandleCreateNotice_PublishAtValidation(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	for name, body := range map[string]map[string]interface{}{
+		"not RFC3339":       {"publishAt": "tomorrow"},
+		"already published": {"publishAt": time.Now().Add(time.Hour).UTC().Format(time.RFC3339), "state": "published"},
+	} {
+		body["type"], body["title"], body["summary"] = "announcement", "T", "S"
+		if w := env.do(t, http.MethodPost, "/api/v1/notices", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d: %s", name, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestHandleUpdateNotice_ReversedEventTimes(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	start := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	noticeID := createTestNotice(t, env, map[string]interface{}{
+		"id": "event-times", "type": "event", "title": "Hui", "summary": "S",
+		"eventStart": start.Format(time.RFC3339), "eventEnd": start.Add(time.Hour).Format(time.RFC3339),
+	})
+
+	w := env.do(t, http.MethodPut, "/api/v1/notices/"+noticeID,
+		map[string]string{"eventEnd": start.Add(-time.Hour).Format(time.RFC3339)})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for end before existing start, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleListNotices_MineView(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+
+	const me = "ETEST_NOTICE_USER01"
+	event := func(id string) string {
+		return createTestNotice(t, env, map[string]interface{}{
+			"id": id, "type": "event", "title": id, "summary": id,
+			"state": "published", "rsvpEnabled": true,
+		})
+	}
+	goingID := event("mine-going")
+	otherID := event("mine-others-only")
+	declinedID := event("mine-declined")
+	savedID := createTestNotice(t, env, map[string]interface{}{
+		"id": "mine-saved", "type": "update", "title": "Saved", "summary": "Saved", "state": "published",
+	})
+
+	// Someone else's RSVP doesn't make the notice mine
+	if w := rsvpAs(t, env, "EOTHER_MEMBER", otherID, "going"); w.Code != http.StatusOK {
+		t.Fatalf("rsvp: %d %s", w.Code, w.Body.String())
+	}
+	for id, status := range map[string]string{goingID: "going", declinedID: "not_going"} {
+		if w := rsvpAs(t, env, me, id, status); w.Code != http.StatusOK {
+			t.Fatalf("rsvp: %d %s", w.Code, w.Body.String())
+		}
+	}
+	if w := env.do(t, http.MethodPost, "/api/v1/notices/"+savedID+"/save", nil); w.Code != http.StatusOK {
+		t.Fatalf("save: %d %s", w.Code, w.Body.String())
+	}
+
+	w := env.do(t, http.MethodGet, "/api/v1/notices?view=mine", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Notices []struct {
+			ID           string `json:"id"`
+			MyRSVPStatus string `json:"myRsvpStatus"`
+			Saved        bool   `json:"saved"`
+		} `json:"notices"`
+		Count int    `json:"count"`
+		View  string `json:"view"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+
+	if resp.View != "mine" || resp.Count != 2 {
+		t.Fatalf("expected 2 notices in the mine view, got %s", w.Body.String())
+	}
+	byID := map[string]int{}
+	for i, n := range resp.Notices {
+		byID[n.ID] = i
+	}
+	if i, ok := byID[goingID]; !ok || resp.Notices[i].MyRSVPStatus != "going" || resp.Notices[i].Saved {
+		t.Errorf("expected %s with a going RSVP, got %+v", goingID, resp.Notices)
+	}
+	if i, ok := byID[savedID]; !ok || !resp.Notices[i].Saved || resp.Notices[i].MyRSVPStatus != "" {
+		t.Errorf("expected %s as saved without an RSVP, got %+v", savedID, resp.Notices)
+	}
+
+	// Someone else's draft stays hidden even when saved
+	env.userIdentity.SetIdentity("EOTHER_MEMBER", "mnemonic-EOTHER_MEMBER")
+	draftID := createTestNotice(t, env, map[string]interface{}{
+		"id": "mine-others-draft", "type": "update", "title": "Draft", "summary": "Draft",
+	})
+	env.userIdentity.SetIdentity(me, "mnemonic-"+me)
+	if w := env.do(t, http.MethodPost, "/api/v1/notices/"+draftID+"/save", nil); w.Code != http.StatusOK {
+		t.Fatalf("save draft: %d %s", w.Code, w.Body.String())
+	}
+	w = env.do(t, http.MethodGet, "/api/v1/notices?view=mine", nil)
+	json.NewDecoder(w.Body).Decode(&resp)
+	for _, n := range resp.Notices {
+		if n.ID == draftID {
+			t.Errorf("another member's draft listed in the mine view: %s", w.Body.String())
+		}
+	}
+	env.do(t, http.MethodPost, "/api/v1/notices/"+draftID+"/save", nil)
+
+	// A caller other than the node's identity doesn't get the owner's saves
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/notices?view=mine", nil)
+	req = req.WithContext(context.WithValue(req.Context(), ctxAuth, &authState{aid: "EOTHER_MEMBER"}))
+	w = httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	resp.Notices, resp.Count = nil, 0
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Count != 1 || resp.Notices[0].ID != otherID || resp.Notices[0].Saved {
+		t.Errorf("expected only the other member's RSVP, got %s", w.Body.String())
+	}
+
+	// Unsaving drops the notice again
+	env.do(t, http.MethodPost, "/api/v1/notices/"+savedID+"/save", nil)
+	w = env.do(t, http.MethodGet, "/api/v1/notices?view=mine&type=update", nil)
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Count != 0 {
+		t.Errorf("expected no saved updates after unsaving, got %s", w.Body.String())
+	}
+}
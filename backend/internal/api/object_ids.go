@@ -0,0 +1,87 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// objectIDAIDPrefixLen is how much of the creator's AID goes into an object ID.
+const objectIDAIDPrefixLen = 8
+
+// lastObjectIDNanos is the timestamp of the most recently generated object ID,
+// so IDs created in the same nanosecond still get distinct, increasing timestamps.
+var lastObjectIDNanos atomic.Int64
+
+// newObjectID returns a new unique object ID of the form
+// "<prefix>-<timestamp>-<aid prefix>-<random>", e.g.
+// "ChatMessage-ChatChannel-123-1717000000000000000-EAbc1234-9f86d081".
+//
+// The timestamp comes from a monotonic source (strictly increasing within the
+// process) and the random suffix separates IDs created at the same instant on
+// different peers. The prefix and AID parts are omitted when empty, and AIDs
+// shorter than the prefix length are used whole.
+func newObjectID(prefix, aid string) string {
+	parts := make([]string, 0, 4)
+	if prefix != "" {
+		parts = append(parts, prefix)
+	}
+	parts = append(parts, strconv.FormatInt(nextObjectIDNanos(), 10))
+	if aid = aidPrefix(aid, objectIDAIDPrefixLen); aid != "" {
+		parts = append(parts, aid)
+	}
+	parts = append(parts, randomIDSuffix())
+	return strings.Join(parts, "-")
+}
+
+// nextObjectIDNanos returns the current time in nanoseconds, or one more than
+// the last value returned if the clock hasn't moved on (or went backwards).
+func nextObjectIDNanos() int64 {
+	for {
+		last := lastObjectIDNanos.Load()
+		next := time.Now().UnixNano()
+		if next <= last {
+			next = last + 1
+		}
+		if lastObjectIDNanos.CompareAndSwap(last, next) {
+			return next
+		}
+	}
+}
+
+// aidPrefix returns the first n characters of aid, or all of it if shorter.
+func aidPrefix(aid string, n int) string {
+	if len(aid) <= n {
+		return aid
+	}
+	return aid[:n]
+}
+
+// randomIDSuffix returns 8 random hex characters.
+func randomIDSuffix() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		// The monotonic timestamp is still unique within this process
+		return "00000000"
+	}
+	return hex.EncodeToString(b)
+}
+
+// reactionObjectID returns the deterministic object ID for emoji reactions to
+// a message: "MessageReaction-<messageID>-<hash>", where hash is the first 16
+// hex characters of the emoji's SHA-256. Hashing keeps the ID to path-safe
+// characters whatever the emoji's codepoints.
+func reactionObjectID(messageID, emoji string) string {
+	sum := sha256.Sum256([]byte(emoji))
+	return "MessageReaction-" + messageID + "-" + hex.EncodeToString(sum[:8])
+}
+
+// legacyReactionObjectID is the ID reactions were stored under before
+// reactionObjectID, with the emoji embedded verbatim.
+func legacyReactionObjectID(messageID, emoji string) string {
+	return "MessageReaction-" + messageID + "-" + emoji
+}
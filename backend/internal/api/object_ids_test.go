@@ -0,0 +1,123 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNewObjectID_ConcurrentUnique(t *testing.T) {
+	const workers = 32
+	const perWorker = 2000
+
+	ids := make([][]string, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < perWorker; j++ {
+				ids[i] = append(ids[i], newObjectID("ChatMessage-channel-1", "EALICE0123456789"))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool, workers*perWorker)
+	for _, batch := range ids {
+		for _, id := range batch {
+			if seen[id] {
+				t.Fatalf("duplicate ID %s", id)
+			}
+			seen[id] = true
+		}
+	}
+}
+
+func TestNewObjectID_Format(t *testing.T) {
+	tests := []struct {
+		prefix, aid string
+		wantPrefix  string
+		wantParts   int
+	}{
+		{"ChatChannel", "EALICE0123456789", "ChatChannel-", 4},
+		{"ChatChannel", "E1", "ChatChannel-", 4},
+		{"ChatChannel", "", "ChatChannel-", 3},
+		{"", "EALICE0123456789", "", 3},
+	}
+	for _, tt := range tests {
+		id := newObjectID(tt.prefix, tt.aid)
+		if !strings.HasPrefix(id, tt.wantPrefix) {
+			t.Errorf("newObjectID(%q, %q) = %q, want prefix %q", tt.prefix, tt.aid, id, tt.wantPrefix)
+		}
+		if parts := strings.Split(id, "-"); len(parts) != tt.wantParts {
+			t.Errorf("newObjectID(%q, %q) = %q, want %d parts", tt.prefix, tt.aid, id, tt.wantParts)
+		}
+	}
+
+	if id := newObjectID("ChatChannel", "EALICE0123456789"); !strings.Contains(id, "-EALICE01-") {
+		t.Errorf("expected the first 8 characters of the AID in %q", id)
+	}
+}
+
+func TestChat_SendMessage_ShortAID(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	channelID := createTestChannel(t, env, "short-aid")
+	if err := env.userIdentity.SetIdentity("E1", "test-mnemonic"); err != nil {
+		t.Fatalf("failed to set identity: %v", err)
+	}
+
+	first := sendTestMessage(t, env, channelID, "hello")
+	second := sendTestMessage(t, env, channelID, "hello again")
+	if first == "" || first == second {
+		t.Errorf("expected distinct message IDs, got %q and %q", first, second)
+	}
+}
+
+func TestChat_SendMessage_ThreeCharAID(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	channelID := createTestChannel(t, env, "three-char-aid")
+	if err := env.userIdentity.SetIdentity("EAB", "test-mnemonic"); err != nil {
+		t.Fatalf("failed to set identity: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/chat/channels/"+channelID+"/messages", bytes.NewBufferString(`{"content":"hi"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/chat/channels/"+channelID+"/messages", nil)
+	w = httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	var resp struct {
+		Messages []MessageResponse `json:"messages"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if len(resp.Messages) != 1 || resp.Messages[0].SenderAID != "EAB" || resp.Messages[0].SenderName != "EAB" {
+		t.Errorf("expected one message from EAB, got %+v", resp.Messages)
+	}
+}
+
+func TestTruncateAID(t *testing.T) {
+	for aid, want := range map[string]string{
+		"":                   "",
+		"EAB":                "EAB",
+		"EABCDEFGHIJK":       "EABCDEFGHIJK",
+		"EABCDEFGHIJKLMNOPQ": "EABCDEFGHIJK",
+	} {
+		if got := truncateAID(aid); got != want {
+			t.Errorf("truncateAID(%q) = %q, want %q", aid, got, want)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/matou-dao/backend/internal/anysync"
+)
+
+// seedChannelMessages writes perChannel messages to each of channels
+// channels directly to the object tree and returns the channel IDs.
+func seedChannelMessages(tb testing.TB, env *chatTestEnv, channels, perChannel int) []string {
+	tb.Helper()
+	ctx := context.Background()
+	spaceID := env.spaceManager.GetCommunitySpaceID()
+	client := env.spaceManager.GetClient()
+	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), spaceID, client.GetSigningKey())
+	if err != nil {
+		tb.Fatalf("loading space keys: %v", err)
+	}
+
+	objMgr := env.spaceManager.ObjectTreeManager()
+	channelIDs := make([]string, channels)
+	for c := range channelIDs {
+		channelIDs[c] = fmt.Sprintf("ChatChannel-%d", c)
+		for i := 0; i < perChannel; i++ {
+			data, _ := json.Marshal(ChatMessageData{
+				ChannelID: channelIDs[c],
+				SenderAID: "ETEST_CHAT_USER01",
+				Content:   fmt.Sprintf("message %d", i),
+				SentAt:    time.Now().UTC().Format(time.RFC3339),
+			})
+			payload := &anysync.ObjectPayload{
+				ID:        newObjectID("ChatMessage-"+channelIDs[c], "ETEST_CHAT_USER01"),
+				Type:      "ChatMessage",
+				Data:      data,
+				Timestamp: time.Now().Unix(),
+				Version:   1,
+			}
+			if _, err := objMgr.AddObject(ctx, spaceID, payload, keys.SigningKey); err != nil {
+				tb.Fatalf("adding message: %v", err)
+			}
+		}
+	}
+	return channelIDs
+}
+
+// unfilteredChannelMessageIDs is the ReadObjectsByType path: read every
+// message, then filter by channel in Go.
+func unfilteredChannelMessageIDs(tb testing.TB, objMgr *anysync.ObjectTreeManager, spaceID, channelID string) []string {
+	objects, err := objMgr.ReadObjectsByType(context.Background(), spaceID, "ChatMessage")
+	if err != nil {
+		tb.Fatalf("reading messages: %v", err)
+	}
+	var ids []string
+	for _, obj := range objects {
+		var data ChatMessageData
+		if json.Unmarshal(obj.Data, &data) == nil && data.ChannelID == channelID {
+			ids = append(ids, obj.ID)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func TestReadObjectsByTypePaged_MatchesUnfiltered(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	channelIDs := seedChannelMessages(t, env, 3, 12)
+	objMgr := env.spaceManager.ObjectTreeManager()
+	spaceID := env.spaceManager.GetCommunitySpaceID()
+	ctx := context.Background()
+
+	for _, channelID := range channelIDs {
+		want := unfilteredChannelMessageIDs(t, objMgr, spaceID, channelID)
+
+		page, err := objMgr.ReadObjectsByTypePaged(ctx, spaceID, "ChatMessage", anysync.ObjectQuery{Field: "channelId", Value: channelID})
+		if err != nil {
+			t.Fatalf("paged read: %v", err)
+		}
+		var got []string
+		for _, obj := range page.Objects {
+			got = append(got, obj.ID)
+		}
+		if !slices.Equal(got, want) || page.NextCursor != "" {
+			t.Errorf("channel %s: got %v (cursor %q), want %v", channelID, got, page.NextCursor, want)
+		}
+
+		// Walking the pages returns the same objects in the same order
+		var paged []string
+		cursor := ""
+		for pages := 0; ; pages++ {
+			if pages > len(want) {
+				t.Fatalf("channel %s: paging did not terminate", channelID)
+			}
+			page, err := objMgr.ReadObjectsByTypePaged(ctx, spaceID, "ChatMessage", anysync.ObjectQuery{
+				Field: "channelId", Value: channelID, AfterID: cursor, Limit: 5,
+			})
+			if err != nil {
+				t.Fatalf("paged read: %v", err)
+			}
+			if len(page.Objects) > 5 {
+				t.Fatalf("page of %d exceeds limit", len(page.Objects))
+			}
+			for _, obj := range page.Objects {
+				paged = append(paged, obj.ID)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			cursor = page.NextCursor
+		}
+		if !slices.Equal(paged, want) {
+			t.Errorf("channel %s: paged %v, want %v", channelID, paged, want)
+		}
+	}
+}
+
+func BenchmarkReadChannelMessages(b *testing.B) {
+	env := setupChatTestEnv(b)
+	defer env.cleanup()
+
+	channelIDs := seedChannelMessages(b, env, 20, 50)
+	objMgr := env.spaceManager.ObjectTreeManager()
+	spaceID := env.spaceManager.GetCommunitySpaceID()
+	channelID := channelIDs[0]
+	ctx := context.Background()
+
+	b.Run("ReadObjectsByType", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			unfilteredChannelMessageIDs(b, objMgr, spaceID, channelID)
+		}
+	})
+	b.Run("ReadObjectsByTypePaged", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := objMgr.ReadObjectsByTypePaged(ctx, spaceID, "ChatMessage", anysync.ObjectQuery{Field: "channelId", Value: channelID}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("ReadObjectsByTypePagedLimit", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := objMgr.ReadObjectsByTypePaged(ctx, spaceID, "ChatMessage", anysync.ObjectQuery{Field: "channelId", Value: channelID, Limit: 10}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -19,7 +19,8 @@ type OrgConfigHandler struct {
 	configPath string
 	mu         sync.RWMutex
 	cache      *OrgConfigData
-	onUpdate   func(*OrgConfigData) // Callback when config is updated
+	onUpdate   func(*OrgConfigData)     // Callback when config is updated
+	localKey   func() (aid, key string) // see SetLocalSigningKey
 }
 
 // OrgConfigData represents the organization configuration
@@ -48,6 +49,9 @@ type AdminData struct {
 	AID  string `json:"aid" yaml:"aid"`
 	Name string `json:"name" yaml:"name"`
 	OOBI string `json:"oobi,omitempty" yaml:"oobi,omitempty"`
+	// SigningKey is the base64 public key of the admin's peer, which signs
+	// the invites members verify when joining
+	SigningKey string `json:"signingKey,omitempty" yaml:"signingKey,omitempty"`
 }
 
 // Registry holds credential registry info
@@ -67,6 +71,13 @@ func (h *OrgConfigHandler) AddOnUpdate(fn func(*OrgConfigData)) {
 	}
 }
 
+// SetLocalSigningKey makes a saved config list this node's invite signing
+// key for its identity: fn returns the local AID and the base64 public peer
+// key, and an admin entry for that AID without a signingKey gets it.
+func (h *OrgConfigHandler) SetLocalSigningKey(fn func() (aid, key string)) {
+	h.localKey = fn
+}
+
 // NewOrgConfigHandler creates a new org config handler
 func NewOrgConfigHandler(dataDir string, onUpdate func(*OrgConfigData)) *OrgConfigHandler {
 	configPath := filepath.Join(dataDir, "org-config.yaml")
@@ -174,6 +185,16 @@ func (h *OrgConfigHandler) HandleSaveConfig(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	if h.localKey != nil {
+		if aid, key := h.localKey(); aid != "" && key != "" {
+			for i := range config.Admins {
+				if config.Admins[i].AID == aid && config.Admins[i].SigningKey == "" {
+					config.Admins[i].SigningKey = key
+				}
+			}
+		}
+	}
+
 	h.mu.Lock()
 	h.cache = &config
 	err := h.saveToDisk()
@@ -193,8 +214,10 @@ func (h *OrgConfigHandler) HandleSaveConfig(w http.ResponseWriter, r *http.Reque
 	}
 
 	log.Printf("[OrgConfig] Saved config for: %s\n", config.Organization.Name)
-	writeJSON(w, http.StatusOK, map[string]string{
+	// Return the config as saved, so the caller can publish the signing keys
+	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"status": "saved",
+		"config": config,
 	})
 }
 
@@ -307,6 +330,22 @@ func (h *OrgConfigHandler) GetAdminAID() string {
 	return h.cache.Admins[0].AID
 }
 
+// GetAdminSigningKey returns the invite signing key listed for admin aid, or
+// empty string if aid isn't a configured admin or has no key
+func (h *OrgConfigHandler) GetAdminSigningKey(aid string) string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.cache == nil || aid == "" {
+		return ""
+	}
+	for _, admin := range h.cache.Admins {
+		if admin.AID == aid {
+			return admin.SigningKey
+		}
+	}
+	return ""
+}
+
 // GetCommunitySpaceID returns the community space ID, or empty string if not configured
 func (h *OrgConfigHandler) GetCommunitySpaceID() string {
 	h.mu.RLock()
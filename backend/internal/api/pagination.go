@@ -0,0 +1,83 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/matou-dao/backend/pkg/client"
+)
+
+// maxListPageSize caps ?limit= on list endpoints without their own page
+// sizes. Those endpoints return everything when no limit is given.
+const maxListPageSize = 500
+
+// ListPage is the pagination envelope shared by list endpoints. Struct
+// responses embed it; for map responses writeListJSON merges it in. The
+// total is also sent as X-Total-Count.
+type ListPage = client.ListPage
+
+// parseListLimit reads ?limit=, which when given must be a positive integer;
+// larger values are capped at maxLimit. Without one it returns defaultLimit,
+// where 0 means no limit. On a bad value it writes a 400 and returns false.
+func parseListLimit(w http.ResponseWriter, r *http.Request, defaultLimit, maxLimit int) (int, bool) {
+	l := r.URL.Query().Get("limit")
+	if l == "" {
+		return defaultLimit, true
+	}
+	parsed, err := strconv.Atoi(l)
+	if err != nil || parsed <= 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "limit must be a positive integer"})
+		return 0, false
+	}
+	return min(parsed, maxLimit), true
+}
+
+// parseListOffset reads ?offset=, which defaults to 0. On a bad value it
+// writes a 400 and returns false.
+func parseListOffset(w http.ResponseWriter, r *http.Request) (int, bool) {
+	o := r.URL.Query().Get("offset")
+	if o == "" {
+		return 0, true
+	}
+	parsed, err := strconv.Atoi(o)
+	if err != nil || parsed < 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "offset must be a non-negative integer"})
+		return 0, false
+	}
+	return parsed, true
+}
+
+// offsetPage returns the limit items of all starting at offset, along with
+// their envelope. A limit of 0 returns everything from offset on.
+func offsetPage[T any](all []T, offset, limit int) ([]T, ListPage) {
+	start := min(offset, len(all))
+	end := len(all)
+	if limit > 0 {
+		end = min(start+limit, len(all))
+	}
+	return all[start:end], ListPage{
+		Total:   len(all),
+		Limit:   limit,
+		Offset:  offset,
+		HasMore: end < len(all),
+	}
+}
+
+// writeListJSON writes a 200 list response with X-Total-Count set. A map
+// response gets the envelope fields added; a struct response should embed
+// page.
+func writeListJSON(w http.ResponseWriter, page ListPage, resp interface{}) {
+	if m, ok := resp.(map[string]interface{}); ok {
+		m["total"] = page.Total
+		m["limit"] = page.Limit
+		m["hasMore"] = page.HasMore
+		if page.Offset > 0 {
+			m["offset"] = page.Offset
+		}
+		if page.NextCursor != "" {
+			m["nextCursor"] = page.NextCursor
+		}
+	}
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	writeJSON(w, http.StatusOK, resp)
+}
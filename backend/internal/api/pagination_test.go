@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+type listEnvelope struct {
+	Channels   []ChannelResponse `json:"channels"`
+	Notices    []json.RawMessage `json:"notices"`
+	Count      int               `json:"count"`
+	Total      int               `json:"total"`
+	Limit      int               `json:"limit"`
+	Offset     int               `json:"offset"`
+	NextCursor string            `json:"nextCursor"`
+	HasMore    bool              `json:"hasMore"`
+}
+
+// decodeListPage checks the status and that X-Total-Count matches the
+// envelope's total.
+func decodeListPage(t *testing.T, w *httptest.ResponseRecorder) listEnvelope {
+	t.Helper()
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var page listEnvelope
+	if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if got := w.Header().Get("X-Total-Count"); got != strconv.Itoa(page.Total) {
+		t.Errorf("X-Total-Count = %q, total %d", got, page.Total)
+	}
+	return page
+}
+
+func TestPagination_Channels(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+	for _, name := range []string{"one", "two", "three"} {
+		createTestChannel(t, env, name)
+	}
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/chat/channels"+query, nil)
+		w := httptest.NewRecorder()
+		env.mux.ServeHTTP(w, req)
+		return w
+	}
+
+	first := decodeListPage(t, get("?limit=2"))
+	if len(first.Channels) != 2 || first.Count != 2 || first.Total != 3 || first.Limit != 2 || !first.HasMore {
+		t.Fatalf("unexpected first page: %+v", first)
+	}
+	second := decodeListPage(t, get("?limit=2&offset=2"))
+	if len(second.Channels) != 1 || second.Total != 3 || second.Offset != 2 || second.HasMore {
+		t.Fatalf("unexpected second page: %+v", second)
+	}
+	seen := map[string]bool{}
+	for _, ch := range append(first.Channels, second.Channels...) {
+		if seen[ch.ID] {
+			t.Errorf("channel %s appears on both pages", ch.ID)
+		}
+		seen[ch.ID] = true
+	}
+
+	all := decodeListPage(t, get(""))
+	if all.Limit != 0 || len(all.Channels) != 3 || all.HasMore {
+		t.Errorf("unexpected default page: %+v", all)
+	}
+	if capped := decodeListPage(t, get("?limit=100000")); capped.Limit != maxListPageSize {
+		t.Errorf("expected limit capped at %d, got %d", maxListPageSize, capped.Limit)
+	}
+	for _, query := range []string{"?limit=0", "?limit=abc", "?offset=-1"} {
+		if w := get(query); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestPagination_Notices(t *testing.T) {
+	env := setupNoticesTestEnv(t)
+	defer env.cleanup()
+	for _, title := range []string{"One", "Two", "Three"} {
+		createTestNotice(t, env, map[string]interface{}{"type": "announcement", "title": title, "summary": "Paged"})
+	}
+
+	first := decodeListPage(t, env.do(t, http.MethodGet, "/api/v1/notices?limit=2", nil))
+	if len(first.Notices) != 2 || first.Total != 3 || first.Limit != 2 || !first.HasMore || first.NextCursor == "" {
+		t.Fatalf("unexpected first page: %+v", first)
+	}
+	second := decodeListPage(t, env.do(t, http.MethodGet, "/api/v1/notices?limit=2&cursor="+first.NextCursor, nil))
+	if len(second.Notices) != 1 || second.Total != 3 || second.HasMore || second.NextCursor != "" {
+		t.Fatalf("unexpected second page: %+v", second)
+	}
+
+	if all := decodeListPage(t, env.do(t, http.MethodGet, "/api/v1/notices", nil)); all.Limit != 0 || len(all.Notices) != 3 {
+		t.Errorf("unexpected default page: %+v", all)
+	}
+	if w := env.do(t, http.MethodGet, "/api/v1/notices?limit=-2", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("negative limit: expected 400, got %d", w.Code)
+	}
+}
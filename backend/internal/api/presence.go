@@ -0,0 +1,210 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultPresenceTTL is how long a member stays online after their stream's
+// last heartbeat: three missed heartbeats at the default interval.
+const DefaultPresenceTTL = 3 * defaultHeartbeatInterval
+
+// PresenceEntry describes an online member.
+type PresenceEntry struct {
+	AID         string `json:"aid"`
+	OnlineSince string `json:"onlineSince"`
+	LastSeenAt  string `json:"lastSeenAt"`
+}
+
+// PresenceResponse is the response for GET /api/v1/presence.
+type PresenceResponse struct {
+	Online []PresenceEntry `json:"online"` // sorted by AID
+	Count  int             `json:"count"`
+}
+
+type presenceState struct {
+	since    time.Time
+	lastSeen time.Time
+	streams  int  // open event streams, see Connect
+	lapsed   bool // swept offline while streams were still open
+}
+
+// PresenceTracker tracks which members are online. A member is online while
+// an event stream registered with their AID keeps sending heartbeats (see
+// EventsHandler); they go offline when their last stream closes, or once no
+// heartbeat has been seen for the TTL. Transitions are broadcast as
+// "presence:online" and "presence:offline". Presence is ephemeral, so it is
+// kept in memory only.
+type PresenceTracker struct {
+	broker *EventBroker
+	ttl    time.Duration
+
+	mu     sync.Mutex
+	online map[string]*presenceState
+
+	loop backgroundLoop // sweeper (see StartSweeper)
+}
+
+// NewPresenceTracker creates a tracker that broadcasts transitions on broker.
+func NewPresenceTracker(broker *EventBroker, ttl time.Duration) *PresenceTracker {
+	if ttl <= 0 {
+		ttl = DefaultPresenceTTL
+	}
+	return &PresenceTracker{broker: broker, ttl: ttl, online: make(map[string]*presenceState)}
+}
+
+// Touch records a heartbeat from aid at now, marking it online if it wasn't.
+func (p *PresenceTracker) Touch(aid string, now time.Time) {
+	p.touch(aid, now, 0)
+}
+
+// Connect records a stream opened by aid at now, marking it online if it
+// wasn't. Each Connect is paired with a Disconnect when the stream closes.
+func (p *PresenceTracker) Connect(aid string, now time.Time) {
+	p.touch(aid, now, 1)
+}
+
+func (p *PresenceTracker) touch(aid string, now time.Time, streams int) {
+	if p == nil || aid == "" {
+		return
+	}
+	p.mu.Lock()
+	state, ok := p.online[aid]
+	if ok && !state.lapsed {
+		state.lastSeen = now
+		state.streams += streams
+		p.mu.Unlock()
+		return
+	}
+	if ok {
+		// Heartbeats resumed on a stream that outlived the TTL
+		state.since, state.lastSeen, state.lapsed = now, now, false
+		state.streams += streams
+	} else {
+		p.online[aid] = &presenceState{since: now, lastSeen: now, streams: streams}
+	}
+	p.mu.Unlock()
+
+	p.broadcast("presence:online", aid, now)
+}
+
+// Disconnect records that a stream opened by aid closed at now. Once the AID
+// has no streams left it goes offline without waiting for the TTL.
+func (p *PresenceTracker) Disconnect(aid string, now time.Time) {
+	if p == nil || aid == "" {
+		return
+	}
+	p.mu.Lock()
+	state, ok := p.online[aid]
+	if !ok {
+		p.mu.Unlock()
+		return
+	}
+	if state.streams--; state.streams > 0 {
+		p.mu.Unlock()
+		return
+	}
+	delete(p.online, aid)
+	p.mu.Unlock()
+	if state.lapsed {
+		return // already reported offline by Sweep
+	}
+
+	p.broadcast("presence:offline", aid, now)
+}
+
+// Sweep marks members whose last heartbeat is older than the TTL at now as
+// offline, and returns how many went offline. A member with streams still
+// open keeps their stream count, so a later Disconnect stays balanced.
+func (p *PresenceTracker) Sweep(now time.Time) int {
+	p.mu.Lock()
+	var expired []string
+	for aid, state := range p.online {
+		if state.lapsed || p.live(state, now) {
+			continue
+		}
+		expired = append(expired, aid)
+		if state.streams > 0 {
+			state.lapsed = true
+		} else {
+			delete(p.online, aid)
+		}
+	}
+	p.mu.Unlock()
+
+	sort.Strings(expired)
+	for _, aid := range expired {
+		p.broadcast("presence:offline", aid, now)
+	}
+	return len(expired)
+}
+
+// Online returns the members online at now, sorted by AID. Members past the
+// TTL are left out even before the sweeper has run.
+func (p *PresenceTracker) Online(now time.Time) []PresenceEntry {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	entries := make([]PresenceEntry, 0, len(p.online))
+	for aid, state := range p.online {
+		if state.lapsed || !p.live(state, now) {
+			continue
+		}
+		entries = append(entries, PresenceEntry{
+			AID:         aid,
+			OnlineSince: state.since.UTC().Format(time.RFC3339),
+			LastSeenAt:  state.lastSeen.UTC().Format(time.RFC3339),
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].AID < entries[j].AID })
+	return entries
+}
+
+func (p *PresenceTracker) live(state *presenceState, now time.Time) bool {
+	return now.Sub(state.lastSeen) < p.ttl
+}
+
+func (p *PresenceTracker) broadcast(eventType, aid string, at time.Time) {
+	if p.broker == nil {
+		return
+	}
+	p.broker.Broadcast(SSEEvent{
+		Type: eventType,
+		Data: map[string]interface{}{
+			"aid": aid,
+			"at":  at.UTC().Format(time.RFC3339),
+		},
+	})
+}
+
+// HandlePresence handles GET /api/v1/presence — list the members online.
+func (p *PresenceTracker) HandlePresence(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		return
+	}
+	online := p.Online(time.Now())
+	writeJSON(w, http.StatusOK, PresenceResponse{Online: online, Count: len(online)})
+}
+
+// RegisterRoutes registers the presence route.
+func (p *PresenceTracker) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/api/v1/presence", p.HandlePresence)
+}
+
+// StartSweeper begins a background loop that marks members offline once their
+// heartbeats lapse, checking every interval.
+func (p *PresenceTracker) StartSweeper(interval time.Duration) {
+	p.loop.start(interval, func(_ context.Context, now time.Time) {
+		p.Sweep(now)
+	})
+	log.Printf("[Presence] Started presence sweeper (ttl=%s, interval=%s)", p.ttl, interval)
+}
+
+// StopSweeper stops the loop started by StartSweeper.
+func (p *PresenceTracker) StopSweeper() {
+	p.loop.stop()
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func getPresence(t *testing.T, mux *http.ServeMux) PresenceResponse {
+	t.Helper()
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/presence", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp PresenceResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	return resp
+}
+
+func TestPresence_ConnectingClientOnline(t *testing.T) {
+	broker := NewEventBroker()
+	presence := NewPresenceTracker(broker, time.Minute)
+	handler := NewEventsHandler(broker)
+	handler.SetPresenceTracker(presence)
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+	presence.RegisterRoutes(mux)
+	auth := newTestAuthenticator(t, AuthModeToken)
+	srv := httptest.NewServer(AuthMiddleware(auth, mux))
+	defer srv.Close()
+
+	watcher := broker.SubscribeAs("EBOB")
+	defer broker.Unsubscribe(watcher)
+
+	token, _, _ := auth.IssueToken("EALICE", time.Now())
+	resp, err := http.Get(srv.URL + "/api/v1/events?access_token=" + token)
+	if err != nil {
+		t.Fatalf("connecting to SSE stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if f := readSSE(t, bufio.NewReader(resp.Body)); f.event != "connected" {
+		t.Fatalf("first event = %q, want connected", f.event)
+	}
+
+	event, ok := receive(watcher)
+	if !ok || event.Type != "presence:online" || event.Data.(map[string]interface{})["aid"] != "EALICE" {
+		t.Fatalf("expected presence:online for EALICE, got %+v", event)
+	}
+	got := getPresence(t, mux)
+	if got.Count != 1 || got.Online[0].AID != "EALICE" || got.Online[0].OnlineSince == "" {
+		t.Errorf("expected EALICE online, got %+v", got)
+	}
+
+	// Anonymous streams don't count as anyone being online
+	anon, _ := openSSE(t, srv.URL, "")
+	defer anon.Body.Close()
+	if got := getPresence(t, mux); got.Count != 1 {
+		t.Errorf("expected an anonymous stream not to be tracked, got %+v", got)
+	}
+
+	// Closing the stream takes Alice offline without waiting for the TTL
+	resp.Body.Close()
+	select {
+	case event = <-watcher:
+		if event.Type != "presence:offline" || event.Data.(map[string]interface{})["aid"] != "EALICE" {
+			t.Fatalf("expected presence:offline for EALICE, got %+v", event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected presence:offline once the stream closed")
+	}
+	if got := getPresence(t, mux); got.Count != 0 {
+		t.Errorf("expected nobody online once the stream closed, got %+v", got)
+	}
+}
+
+func TestPresence_OfflineWhenLastStreamCloses(t *testing.T) {
+	broker := NewEventBroker()
+	presence := NewPresenceTracker(broker, time.Minute)
+	watcher := broker.Subscribe()
+	defer broker.Unsubscribe(watcher)
+
+	now := time.Now()
+	presence.Connect("EALICE", now)
+	presence.Connect("EALICE", now)
+	receive(watcher)
+
+	// Another tab still has a stream open
+	presence.Disconnect("EALICE", now)
+	if _, ok := receive(watcher); ok {
+		t.Error("expected no event while another stream is open")
+	}
+	presence.Disconnect("EALICE", now)
+	if event, ok := receive(watcher); !ok || event.Type != "presence:offline" {
+		t.Errorf("expected presence:offline once the last stream closed, got %+v", event)
+	}
+	if online := presence.Online(now); len(online) != 0 {
+		t.Errorf("expected nobody online, got %+v", online)
+	}
+}
+
+func TestPresence_SweptStreamsStayCounted(t *testing.T) {
+	broker := NewEventBroker()
+	presence := NewPresenceTracker(broker, time.Minute)
+	watcher := broker.Subscribe()
+	defer broker.Unsubscribe(watcher)
+
+	start := time.Now()
+	presence.Connect("EALICE", start)
+	presence.Connect("EALICE", start)
+	receive(watcher)
+
+	// Heartbeats lapse, then resume on the open streams
+	if n := presence.Sweep(start.Add(time.Minute)); n != 1 {
+		t.Fatalf("expected 1 member swept offline, got %d", n)
+	}
+	receive(watcher)
+	at := start.Add(time.Minute + time.Second)
+	presence.Touch("EALICE", at)
+	if event, ok := receive(watcher); !ok || event.Type != "presence:online" {
+		t.Fatalf("expected presence:online once heartbeats resumed, got %+v", event)
+	}
+
+	// Closing one of the two streams leaves Alice online
+	presence.Disconnect("EALICE", at)
+	if event, ok := receive(watcher); ok {
+		t.Errorf("expected no event while another stream is open, got %+v", event)
+	}
+	if online := presence.Online(at); len(online) != 1 || online[0].AID != "EALICE" {
+		t.Errorf("expected EALICE online, got %+v", online)
+	}
+	presence.Disconnect("EALICE", at)
+	if event, ok := receive(watcher); !ok || event.Type != "presence:offline" {
+		t.Errorf("expected presence:offline once the last stream closed, got %+v", event)
+	}
+}
+
+func TestPresence_OfflineAfterTTL(t *testing.T) {
+	broker := NewEventBroker()
+	presence := NewPresenceTracker(broker, time.Minute)
+	watcher := broker.Subscribe()
+	defer broker.Unsubscribe(watcher)
+
+	start := time.Now()
+	presence.Touch("EALICE", start)
+	presence.Touch("EBOB", start)
+	receive(watcher)
+	receive(watcher)
+
+	// A heartbeat keeps Bob online; Alice's lapse
+	presence.Touch("EBOB", start.Add(45*time.Second))
+	if _, ok := receive(watcher); ok {
+		t.Error("expected no event for a heartbeat from an online member")
+	}
+
+	at := start.Add(time.Minute)
+	if online := presence.Online(at); len(online) != 1 || online[0].AID != "EBOB" {
+		t.Errorf("expected only EBOB listed once Alice's TTL lapsed, got %+v", online)
+	}
+	if n := presence.Sweep(at); n != 1 {
+		t.Fatalf("expected 1 member swept offline, got %d", n)
+	}
+	event, ok := receive(watcher)
+	if !ok || event.Type != "presence:offline" || event.Data.(map[string]interface{})["aid"] != "EALICE" {
+		t.Fatalf("expected presence:offline for EALICE, got %+v", event)
+	}
+
+	// Reconnecting brings Alice back online
+	presence.Touch("EALICE", at)
+	if event, ok := receive(watcher); !ok || event.Type != "presence:online" {
+		t.Errorf("expected presence:online on reconnect, got %+v", event)
+	}
+	if n := presence.Sweep(start.Add(2 * time.Minute)); n != 2 {
+		t.Errorf("expected both members swept once every heartbeat lapsed, got %d", n)
+	}
+}
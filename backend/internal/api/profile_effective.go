@@ -0,0 +1,292 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/matou-dao/backend/internal/anysync"
+)
+
+// effectiveProfileHiddenFields are left out of the effective profile: object
+// bookkeeping and admin-only notes rather than profile data.
+var effectiveProfileHiddenFields = map[string]bool{
+	"aid":         true,
+	"userAID":     true,
+	"adminNotes":  true,
+	"flags":       true,
+	"typeVersion": true,
+}
+
+// EffectiveProfile is a member's profile merged from the profiles each space
+// holds for them. Precedence, per field:
+//
+//   - Role and permission fields (those CommunityProfile defines) are
+//     org-issued and always come from the CommunityProfile, so a self-claim
+//     can't grant itself a role.
+//   - Display fields are self-claimed: the member's SharedProfile wins, and
+//     the member's own PrivateProfile adds its fields when they read their
+//     own profile. A CommunityProfile copy is used only when no self-claim
+//     sets the field.
+//   - lastActiveAt takes the newest value from any profile.
+type EffectiveProfile struct {
+	AID    string                 `json:"aid"`
+	Fields map[string]interface{} `json:"fields"`
+	// Sources maps each field to the profile type it was taken from
+	Sources map[string]string `json:"sources"`
+	// Versions holds the version of each profile merged, keyed by type
+	Versions map[string]int `json:"versions"`
+}
+
+// mergeEffectiveProfile merges the latest profiles for aid by the precedence
+// documented on EffectiveProfile. Any of the profiles may be nil; orgFields
+// names the org-issued fields.
+func mergeEffectiveProfile(aid string, shared, community, private *anysync.ObjectPayload, orgFields map[string]bool) *EffectiveProfile {
+	profile := &EffectiveProfile{
+		AID:      aid,
+		Fields:   make(map[string]interface{}),
+		Sources:  make(map[string]string),
+		Versions: make(map[string]int),
+	}
+	set := func(typeName, field string, value interface{}) {
+		profile.Fields[field] = value
+		profile.Sources[field] = typeName
+	}
+
+	// Self-claims first, the member's private profile over their shared one
+	for _, obj := range []*anysync.ObjectPayload{shared, private} {
+		for field, value := range profileFields(profile, obj) {
+			if !orgFields[field] || field == "lastActiveAt" {
+				set(obj.Type, field, value)
+			}
+		}
+	}
+
+	for field, value := range profileFields(profile, community) {
+		_, claimed := profile.Fields[field]
+		switch {
+		case field == "lastActiveAt":
+			if current, ok := profile.Fields[field].(string); !ok || laterTimestamp(value, current) {
+				set(community.Type, field, value)
+			}
+		case orgFields[field] || !claimed:
+			set(community.Type, field, value)
+		}
+	}
+	return profile
+}
+
+// profileFields returns obj's data fields, minus hidden ones, and records its
+// version on profile. A nil or unparseable object has no fields.
+func profileFields(profile *EffectiveProfile, obj *anysync.ObjectPayload) map[string]interface{} {
+	if obj == nil {
+		return nil
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(obj.Data, &data); err != nil {
+		return nil
+	}
+	profile.Versions[obj.Type] = obj.Version
+	for field, value := range data {
+		if effectiveProfileHiddenFields[field] || value == nil {
+			delete(data, field)
+		}
+	}
+	return data
+}
+
+// laterTimestamp reports whether RFC3339 timestamp a is after b. A value that
+// doesn't parse is never later.
+func laterTimestamp(a interface{}, b string) bool {
+	as, _ := a.(string)
+	at, err := time.Parse(time.RFC3339, as)
+	if err != nil {
+		return false
+	}
+	bt, err := time.Parse(time.RFC3339, b)
+	return err != nil || at.After(bt)
+}
+
+// latestProfile returns the highest version of typeName in spaceID belonging
+// to aid, or nil if there is none.
+func (h *ProfilesHandler) latestProfile(ctx context.Context, spaceID, typeName, aid string) *anysync.ObjectPayload {
+	if spaceID == "" {
+		return nil
+	}
+	objects, err := h.spaceManager.ObjectTreeManager().ReadObjectsByType(ctx, spaceID, typeName)
+	if err != nil {
+		return nil
+	}
+	var latest *anysync.ObjectPayload
+	for _, obj := range filterObjectsByAID(deduplicateObjects(objects), aid) {
+		if latest == nil || obj.Version > latest.Version {
+			latest = obj
+		}
+	}
+	return latest
+}
+
+// HandleEffectiveProfile handles GET /api/v1/profile/{aid}/effective — the
+// member's profile merged across spaces (see EffectiveProfile).
+func (h *ProfilesHandler) HandleEffectiveProfile(w http.ResponseWriter, r *http.Request, aid string) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		return
+	}
+
+	ctx := r.Context()
+	shared := h.latestProfile(ctx, h.spaceManager.GetCommunitySpaceID(), "SharedProfile", aid)
+	community := h.latestProfile(ctx, h.spaceManager.GetCommunityReadOnlySpaceID(), "CommunityProfile", aid)
+
+	// The private profile is only readable on the member's own node, by them
+	var private *anysync.ObjectPayload
+	if h.userIdentity != nil && h.userIdentity.GetAID() == aid && callerAID(ctx, h.userIdentity) == aid {
+		if spaceID := h.userIdentity.GetPrivateSpaceID(); spaceID != "" {
+			if obj, err := h.spaceManager.ObjectTreeManager().ReadLatestByID(ctx, spaceID, "PrivateProfile-"+aid); err == nil {
+				private = obj
+			}
+		}
+	}
+
+	if shared == nil && community == nil && private == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": fmt.Sprintf("no profile found for %s", aid),
+		})
+		return
+	}
+
+	orgFields := make(map[string]bool)
+	if def, ok := h.registry.Get("CommunityProfile"); ok {
+		for _, field := range def.Fields {
+			orgFields[field.Name] = true
+		}
+	}
+	writeJSON(w, http.StatusOK, mergeEffectiveProfile(aid, shared, community, private, orgFields))
+}
+
+// maxProfileWriteAttempts bounds how often HandleUpdateOwnProfile re-reads
+// and re-merges the profile after losing a race with another write.
+const maxProfileWriteAttempts = 3
+
+// HandleUpdateOwnProfile handles PUT /api/v1/profile/me — update fields of the
+// caller's SharedProfile. The body is a JSON object of the fields to change;
+// only fields on the SharedProfile form can be set. The profile is written to
+// the community space as the next version of the one the fields were merged
+// into, so a concurrent edit is merged again instead of being overwritten.
+func (h *ProfilesHandler) HandleUpdateOwnProfile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		return
+	}
+
+	var updates map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("invalid request: %v", err),
+		})
+		return
+	}
+	if len(updates) == 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "no fields to update"})
+		return
+	}
+
+	def, ok := h.registry.Get("SharedProfile")
+	if !ok {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "SharedProfile type not registered"})
+		return
+	}
+	editable := def.Layouts["form"].Fields
+	for field := range updates {
+		if !slices.Contains(editable, field) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": fmt.Sprintf("field %q can't be updated", field),
+			})
+			return
+		}
+	}
+
+	ctx := r.Context()
+	aid := callerAID(ctx, h.userIdentity)
+	if aid == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "Identity not configured",
+		})
+		return
+	}
+	communitySpaceID := h.spaceManager.GetCommunitySpaceID()
+	for attempt := 1; ; attempt++ {
+		existing := h.latestProfile(ctx, communitySpaceID, "SharedProfile", aid)
+		if existing == nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{
+				"error": fmt.Sprintf("no SharedProfile found for %s", aid),
+			})
+			return
+		}
+
+		var data map[string]interface{}
+		if err := json.Unmarshal(existing.Data, &data); err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": fmt.Sprintf("invalid SharedProfile data: %v", err),
+			})
+			return
+		}
+		for field, value := range updates {
+			data[field] = value
+		}
+		data["updatedAt"] = time.Now().UTC().Format(time.RFC3339)
+		dataBytes, err := json.Marshal(data)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{
+				"error": fmt.Sprintf("failed to marshal profile: %v", err),
+			})
+			return
+		}
+
+		// WriteProfile validates the merged data and bumps the version
+		expected := existing.Version
+		result, err := h.WriteProfile(ctx, CreateProfileRequest{
+			Type:            "SharedProfile",
+			ID:              existing.ID,
+			Data:            dataBytes,
+			SpaceID:         communitySpaceID,
+			ExpectedVersion: &expected,
+		})
+		var writeErr *ProfileWriteError
+		if errors.As(err, &writeErr) && writeErr.Status == http.StatusConflict && attempt < maxProfileWriteAttempts {
+			continue
+		}
+		if err != nil {
+			writeProfileWriteError(w, err)
+			return
+		}
+		h.invalidateProfile(aid)
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"success":  true,
+			"objectId": result.ObjectID,
+			"version":  result.Version,
+			"profile":  data,
+		})
+		return
+	}
+}
+
+// handleProfile routes /api/v1/profile/* requests.
+func (h *ProfilesHandler) handleProfile(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/api/v1/profile/")
+	if rest == "me" {
+		h.HandleUpdateOwnProfile(w, r)
+		return
+	}
+	if aid, ok := strings.CutSuffix(rest, "/effective"); ok && aid != "" && !strings.Contains(aid, "/") {
+		h.HandleEffectiveProfile(w, r, aid)
+		return
+	}
+	writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+}
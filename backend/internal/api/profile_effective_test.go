@@ -0,0 +1,234 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matou-dao/backend/internal/anysync"
+	"github.com/matou-dao/backend/internal/types"
+)
+
+func profileObject(t *testing.T, typeName, id string, version int, data map[string]interface{}) *anysync.ObjectPayload {
+	t.Helper()
+	raw, err := json.Marshal(data)
+	if err != nil {
+		t.Fatalf("marshaling %s: %v", typeName, err)
+	}
+	return &anysync.ObjectPayload{ID: id, Type: typeName, Data: raw, Version: version}
+}
+
+func TestMergeEffectiveProfile_Precedence(t *testing.T) {
+	const aid = "EMember_Merge"
+	shared := profileObject(t, "SharedProfile", "SharedProfile-"+aid, 3, map[string]interface{}{
+		"aid":          aid,
+		"displayName":  "Self Claimed",
+		"bio":          "my own words",
+		"role":         "Founding Member", // self-claimed role must not win
+		"lastActiveAt": "2026-03-01T00:00:00Z",
+	})
+	community := profileObject(t, "CommunityProfile", "CommunityProfile-"+aid, 2, map[string]interface{}{
+		"userAID":      aid,
+		"role":         "Member",
+		"permissions":  []string{"participate"},
+		"displayName":  "Org Copy",
+		"location":     "Aotearoa",
+		"adminNotes":   "not for the member view",
+		"lastActiveAt": "2026-04-01T00:00:00Z",
+	})
+	private := profileObject(t, "PrivateProfile", "PrivateProfile-"+aid, 1, map[string]interface{}{
+		"privacySettings": map[string]interface{}{"showEmail": false},
+	})
+	orgFields := make(map[string]bool)
+	for _, field := range types.CommunityProfileType().Fields {
+		orgFields[field.Name] = true
+	}
+
+	got := mergeEffectiveProfile(aid, shared, community, private, orgFields)
+
+	expect := map[string]struct {
+		value  interface{}
+		source string
+	}{
+		"displayName":  {"Self Claimed", "SharedProfile"},
+		"bio":          {"my own words", "SharedProfile"},
+		"role":         {"Member", "CommunityProfile"},
+		"location":     {"Aotearoa", "CommunityProfile"}, // no self-claim, so the org copy fills in
+		"lastActiveAt": {"2026-04-01T00:00:00Z", "CommunityProfile"},
+	}
+	for field, want := range expect {
+		if got.Fields[field] != want.value || got.Sources[field] != want.source {
+			t.Errorf("%s = %v from %s, want %v from %s", field, got.Fields[field], got.Sources[field], want.value, want.source)
+		}
+	}
+	if got.Sources["privacySettings"] != "PrivateProfile" || got.Sources["permissions"] != "CommunityProfile" {
+		t.Errorf("unexpected sources %v", got.Sources)
+	}
+	for _, hidden := range []string{"aid", "userAID", "adminNotes"} {
+		if _, ok := got.Fields[hidden]; ok {
+			t.Errorf("expected %s to be left out", hidden)
+		}
+	}
+	if got.Versions["SharedProfile"] != 3 || got.Versions["CommunityProfile"] != 2 || got.Versions["PrivateProfile"] != 1 {
+		t.Errorf("unexpected versions %v", got.Versions)
+	}
+
+	// A newer self-claimed activity time wins over the org copy
+	shared = profileObject(t, "SharedProfile", "SharedProfile-"+aid, 4, map[string]interface{}{
+		"displayName":  "Self Claimed",
+		"lastActiveAt": "2026-05-01T00:00:00Z",
+	})
+	got = mergeEffectiveProfile(aid, shared, community, nil, orgFields)
+	if got.Fields["lastActiveAt"] != "2026-05-01T00:00:00Z" || got.Sources["lastActiveAt"] != "SharedProfile" {
+		t.Errorf("expected the newest lastActiveAt, got %v from %s", got.Fields["lastActiveAt"], got.Sources["lastActiveAt"])
+	}
+}
+
+func TestProfile_SelfUpdateRoundTrip(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+	ctx := context.Background()
+	aid := env.userIdentity.GetAID()
+
+	registry := types.NewRegistry()
+	registry.Bootstrap()
+	handler := NewProfilesHandler(env.spaceManager, env.userIdentity, registry, nil, nil)
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	client := env.spaceManager.GetClient()
+	seed := func(spaceID string, obj *anysync.ObjectPayload) {
+		keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), spaceID, client.GetSigningKey())
+		if err != nil {
+			t.Fatalf("loading keys: %v", err)
+		}
+		if _, err := env.spaceManager.ObjectTreeManager().AddObject(ctx, spaceID, obj, keys.SigningKey); err != nil {
+			t.Fatalf("seeding %s: %v", obj.ID, err)
+		}
+	}
+	seed(env.spaceManager.GetCommunitySpaceID(), profileObject(t, "SharedProfile", "SharedProfile-"+aid, 1, map[string]interface{}{
+		"aid":         aid,
+		"status":      "approved",
+		"displayName": "Before",
+	}))
+	seed(env.spaceManager.GetCommunityReadOnlySpaceID(), profileObject(t, "CommunityProfile", "CommunityProfile-"+aid, 1, map[string]interface{}{
+		"userAID":    aid,
+		"credential": "ESAID_Profile",
+		"role":       "Member",
+	}))
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(method, path, bytes.NewBufferString(body)))
+		return w
+	}
+
+	w := do(http.MethodPut, "/api/v1/profile/me", `{"displayName":"After","bio":"updated bio"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var updated struct {
+		Version int `json:"version"`
+	}
+	json.NewDecoder(w.Body).Decode(&updated)
+	if updated.Version != 2 {
+		t.Errorf("expected the update written as version 2, got %d", updated.Version)
+	}
+
+	w = do(http.MethodGet, "/api/v1/profile/"+aid+"/effective", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("effective: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var profile EffectiveProfile
+	json.NewDecoder(w.Body).Decode(&profile)
+	if profile.Fields["displayName"] != "After" || profile.Fields["bio"] != "updated bio" || profile.Fields["status"] != "approved" {
+		t.Errorf("expected the updated self-claim with untouched fields kept, got %v", profile.Fields)
+	}
+	if profile.Fields["role"] != "Member" || profile.Versions["SharedProfile"] != 2 {
+		t.Errorf("expected role from CommunityProfile and SharedProfile v2, got %v / %v", profile.Fields, profile.Versions)
+	}
+
+	t.Run("org-issued and read-only fields rejected", func(t *testing.T) {
+		for _, body := range []string{`{"role":"Founding Member"}`, `{"status":"approved"}`, `{"aid":"EOther"}`} {
+			if w := do(http.MethodPut, "/api/v1/profile/me", body); w.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected 400, got %d", body, w.Code)
+			}
+		}
+	})
+
+	t.Run("invalid value rejected by the schema", func(t *testing.T) {
+		if w := do(http.MethodPut, "/api/v1/profile/me", `{"displayName":""}`); w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("stale expected version conflicts", func(t *testing.T) {
+		stale := 1
+		_, err := handler.WriteProfile(ctx, CreateProfileRequest{
+			Type:            "SharedProfile",
+			ID:              "SharedProfile-" + aid,
+			Data:            json.RawMessage(`{"aid":"` + aid + `","status":"approved","displayName":"Lost"}`),
+			SpaceID:         env.spaceManager.GetCommunitySpaceID(),
+			ExpectedVersion: &stale,
+		})
+		var writeErr *ProfileWriteError
+		if !errors.As(err, &writeErr) || writeErr.Status != http.StatusConflict {
+			t.Fatalf("expected a 409 for a write against version 1, got %v", err)
+		}
+		w := do(http.MethodGet, "/api/v1/profile/"+aid+"/effective", "")
+		var profile EffectiveProfile
+		json.NewDecoder(w.Body).Decode(&profile)
+		if profile.Fields["displayName"] != "After" {
+			t.Errorf("expected the stale write not applied, got %v", profile.Fields)
+		}
+	})
+
+	t.Run("unknown member", func(t *testing.T) {
+		if w := do(http.MethodGet, "/api/v1/profile/ENobody/effective", ""); w.Code != http.StatusNotFound {
+			t.Errorf("expected 404, got %d", w.Code)
+		}
+	})
+}
+
+func TestProfile_SelfUpdateKeepsLegacyAvatarURL(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+	aid := env.userIdentity.GetAID()
+	const legacyURL = "https://example.com/me.png"
+
+	registry := types.NewRegistry()
+	registry.Bootstrap()
+	handler := NewProfilesHandler(env.spaceManager, env.userIdentity, registry, nil, nil)
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	communitySpaceID := env.spaceManager.GetCommunitySpaceID()
+	client := env.spaceManager.GetClient()
+	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), communitySpaceID, client.GetSigningKey())
+	if err != nil {
+		t.Fatalf("loading keys: %v", err)
+	}
+	if _, err := env.spaceManager.ObjectTreeManager().AddObject(context.Background(), communitySpaceID, profileObject(t, "SharedProfile", "SharedProfile-"+aid, 1, map[string]interface{}{
+		"aid": aid, "status": "approved", "displayName": "Before", "avatar": legacyURL,
+	}), keys.SigningKey); err != nil {
+		t.Fatalf("seeding profile: %v", err)
+	}
+
+	do := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/api/v1/profile/me", bytes.NewBufferString(body)))
+		return w
+	}
+
+	// An avatar stored before avatars had to be uploaded doesn't block other edits
+	if w := do(`{"displayName":"After"}`); w.Code != http.StatusOK {
+		t.Fatalf("update: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do(`{"avatar":"https://example.com/other.png"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("new url avatar: expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
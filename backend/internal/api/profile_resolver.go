@@ -0,0 +1,180 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/matou-dao/backend/internal/anysync"
+)
+
+// profileCacheTTL bounds how long a resolved profile is served from cache.
+// Local writes invalidate explicitly; the TTL picks up edits synced from peers.
+const profileCacheTTL = 5 * time.Minute
+
+// ResolvedProfile is the display information for a member, as shown next to
+// their messages, acks and membership entries.
+type ResolvedProfile struct {
+	AID         string `json:"aid"`
+	DisplayName string `json:"displayName"`
+	Avatar      string `json:"avatar,omitempty"`
+	// Found is false when the member has no SharedProfile yet and DisplayName
+	// falls back to their truncated AID.
+	Found bool `json:"found"`
+}
+
+type cachedProfile struct {
+	profile   ResolvedProfile
+	expiresAt time.Time
+}
+
+// ProfileResolver resolves member AIDs to the display name and avatar from
+// their SharedProfile in the community space. Resolved profiles are cached
+// and shared by every handler that shows member names; unknown AIDs are not
+// cached so a newly created profile shows up on the next lookup.
+type ProfileResolver struct {
+	spaceManager *anysync.SpaceManager
+
+	mu    sync.RWMutex
+	cache map[string]cachedProfile
+}
+
+// NewProfileResolver creates a profile resolver reading from spaceManager's
+// community space.
+func NewProfileResolver(spaceManager *anysync.SpaceManager) *ProfileResolver {
+	return &ProfileResolver{
+		spaceManager: spaceManager,
+		cache:        make(map[string]cachedProfile),
+	}
+}
+
+// Resolve returns the profile for a single AID.
+func (p *ProfileResolver) Resolve(ctx context.Context, aid string) ResolvedProfile {
+	return p.ResolveMany(ctx, []string{aid})[aid]
+}
+
+// DisplayName returns the display name for aid, or its truncated AID when
+// the member has no profile.
+func (p *ProfileResolver) DisplayName(ctx context.Context, aid string) string {
+	return p.Resolve(ctx, aid).DisplayName
+}
+
+// ResolveMany returns profiles for aids keyed by AID. Cache misses are read
+// from the community space in one pass.
+func (p *ProfileResolver) ResolveMany(ctx context.Context, aids []string) map[string]ResolvedProfile {
+	result := make(map[string]ResolvedProfile, len(aids))
+	var misses []string
+
+	now := time.Now()
+	p.mu.RLock()
+	for _, aid := range aids {
+		if _, done := result[aid]; done {
+			continue
+		}
+		if cached, ok := p.cache[aid]; ok && now.Before(cached.expiresAt) {
+			result[aid] = cached.profile
+		} else {
+			result[aid] = ResolvedProfile{}
+			misses = append(misses, aid)
+		}
+	}
+	p.mu.RUnlock()
+
+	if len(misses) == 0 {
+		return result
+	}
+
+	found := p.readProfiles(ctx, misses)
+	p.mu.Lock()
+	for _, aid := range misses {
+		profile, ok := found[aid]
+		if !ok {
+			result[aid] = fallbackProfile(aid)
+			continue
+		}
+		result[aid] = profile
+		p.cache[aid] = cachedProfile{profile: profile, expiresAt: now.Add(profileCacheTTL)}
+	}
+	p.mu.Unlock()
+	return result
+}
+
+// Invalidate drops the cached profile for aid, e.g. after its SharedProfile
+// was written.
+func (p *ProfileResolver) Invalidate(aid string) {
+	p.mu.Lock()
+	delete(p.cache, aid)
+	p.mu.Unlock()
+}
+
+// InvalidateAll drops every cached profile.
+func (p *ProfileResolver) InvalidateAll() {
+	p.mu.Lock()
+	p.cache = make(map[string]cachedProfile)
+	p.mu.Unlock()
+}
+
+// readProfiles reads the SharedProfiles for aids from the community space.
+// A single AID is read directly; several are matched against one listing.
+func (p *ProfileResolver) readProfiles(ctx context.Context, aids []string) map[string]ResolvedProfile {
+	found := make(map[string]ResolvedProfile)
+	communitySpaceID := p.spaceManager.GetCommunitySpaceID()
+	if communitySpaceID == "" {
+		return found
+	}
+	objMgr := p.spaceManager.ObjectTreeManager()
+
+	if len(aids) == 1 {
+		obj, err := objMgr.ReadObject(ctx, communitySpaceID, "SharedProfile-"+aids[0])
+		if err == nil && obj != nil {
+			if profile, ok := parseSharedProfile(aids[0], obj.Data); ok {
+				found[aids[0]] = profile
+			}
+		}
+		return found
+	}
+
+	wanted := make(map[string]bool, len(aids))
+	for _, aid := range aids {
+		wanted[aid] = true
+	}
+	objects, err := objMgr.ReadObjectsByType(ctx, communitySpaceID, "SharedProfile")
+	if err != nil {
+		return found
+	}
+	for _, obj := range deduplicateObjects(objects) {
+		aid := strings.TrimPrefix(obj.ID, "SharedProfile-")
+		if !wanted[aid] {
+			continue
+		}
+		if profile, ok := parseSharedProfile(aid, obj.Data); ok {
+			found[aid] = profile
+		}
+	}
+	return found
+}
+
+// parseSharedProfile extracts the display fields from SharedProfile data.
+// A profile without a display name is treated as not found.
+func parseSharedProfile(aid string, data json.RawMessage) (ResolvedProfile, bool) {
+	var fields struct {
+		DisplayName string `json:"displayName"`
+		Avatar      string `json:"avatar"`
+	}
+	if json.Unmarshal(data, &fields) != nil || fields.DisplayName == "" {
+		return ResolvedProfile{}, false
+	}
+	return ResolvedProfile{AID: aid, DisplayName: fields.DisplayName, Avatar: fields.Avatar, Found: true}, true
+}
+
+// fallbackProfile stands in for a member without a SharedProfile, showing
+// their truncated AID as the name.
+func fallbackProfile(aid string) ResolvedProfile {
+	name := aid
+	if len(aid) > 12 {
+		name = truncateAID(aid) + "..."
+	}
+	return ResolvedProfile{AID: aid, DisplayName: name}
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/matou-dao/backend/internal/anysync"
+)
+
+// seedSharedProfile writes a SharedProfile for aid to the community space.
+func seedSharedProfile(t *testing.T, spaceManager *anysync.SpaceManager, aid, displayName string, version int) {
+	t.Helper()
+	spaceID := spaceManager.GetCommunitySpaceID()
+	client := spaceManager.GetClient()
+	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), spaceID, client.GetSigningKey())
+	if err != nil {
+		t.Fatalf("loading community keys: %v", err)
+	}
+	data, _ := json.Marshal(map[string]string{"aid": aid, "displayName": displayName, "avatar": "avatar-" + aid})
+	if _, err := spaceManager.ObjectTreeManager().AddObject(context.Background(), spaceID, &anysync.ObjectPayload{
+		ID:      "SharedProfile-" + aid,
+		Type:    "SharedProfile",
+		Data:    data,
+		Version: version,
+	}, keys.SigningKey); err != nil {
+		t.Fatalf("seeding SharedProfile for %s: %v", aid, err)
+	}
+}
+
+func TestProfileResolver(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+	ctx := context.Background()
+
+	seedSharedProfile(t, env.spaceManager, "EAlice_Resolver", "Alice", 1)
+	seedSharedProfile(t, env.spaceManager, "EBob_Resolver", "Bob", 1)
+
+	t.Run("batch resolution", func(t *testing.T) {
+		resolver := NewProfileResolver(env.spaceManager)
+		got := resolver.ResolveMany(ctx, []string{"EAlice_Resolver", "EBob_Resolver", "EAlice_Resolver"})
+		if len(got) != 2 {
+			t.Fatalf("expected 2 profiles, got %v", got)
+		}
+		if alice := got["EAlice_Resolver"]; !alice.Found || alice.DisplayName != "Alice" || alice.Avatar != "avatar-EAlice_Resolver" {
+			t.Errorf("unexpected profile for Alice: %+v", alice)
+		}
+		if got["EBob_Resolver"].DisplayName != "Bob" {
+			t.Errorf("unexpected profile for Bob: %+v", got["EBob_Resolver"])
+		}
+	})
+
+	t.Run("cache hit", func(t *testing.T) {
+		resolver := NewProfileResolver(env.spaceManager)
+		if name := resolver.DisplayName(ctx, "EAlice_Resolver"); name != "Alice" {
+			t.Fatalf("expected Alice, got %q", name)
+		}
+
+		seedSharedProfile(t, env.spaceManager, "EAlice_Resolver", "Alice Renamed", 2)
+		if name := resolver.DisplayName(ctx, "EAlice_Resolver"); name != "Alice" {
+			t.Errorf("expected the cached name, got %q", name)
+		}
+
+		resolver.Invalidate("EAlice_Resolver")
+		if name := resolver.DisplayName(ctx, "EAlice_Resolver"); name != "Alice Renamed" {
+			t.Errorf("expected the new name after invalidation, got %q", name)
+		}
+	})
+
+	t.Run("unknown AID falls back to truncated AID", func(t *testing.T) {
+		resolver := NewProfileResolver(env.spaceManager)
+		aid := "EUnknownMember_0123456789"
+		got := resolver.Resolve(ctx, aid)
+		if got.Found || got.DisplayName != "EUnknownMemb..." {
+			t.Errorf("unexpected fallback %+v", got)
+		}
+		if name := resolver.DisplayName(ctx, "EShort"); name != "EShort" {
+			t.Errorf("expected a short AID unchanged, got %q", name)
+		}
+
+		// Not cached: a profile created afterwards is picked up right away
+		seedSharedProfile(t, env.spaceManager, aid, "Newcomer", 1)
+		if name := resolver.DisplayName(ctx, aid); name != "Newcomer" {
+			t.Errorf("expected the new profile, got %q", name)
+		}
+	})
+}
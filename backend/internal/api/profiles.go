@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -22,6 +23,7 @@ type ProfilesHandler struct {
 	registry     *types.Registry
 	fileManager  *anysync.FileManager
 	eventBroker  *EventBroker
+	profiles     *ProfileResolver
 }
 
 // NewProfilesHandler creates a new profiles handler.
@@ -41,6 +43,19 @@ func NewProfilesHandler(
 	}
 }
 
+// SetProfileResolver sets the resolver whose cache is invalidated when a
+// SharedProfile is written here.
+func (h *ProfilesHandler) SetProfileResolver(p *ProfileResolver) {
+	h.profiles = p
+}
+
+// invalidateProfile drops the cached display name for aid, if a resolver is set.
+func (h *ProfilesHandler) invalidateProfile(aid string) {
+	if h.profiles != nil {
+		h.profiles.Invalidate(aid)
+	}
+}
+
 // HandleListTypes handles GET /api/v1/types — list all type definitions.
 func (h *ProfilesHandler) HandleListTypes(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -83,6 +98,9 @@ type CreateProfileRequest struct {
 	ID      string          `json:"id"`      // Object ID (auto-generated if empty)
 	Data    json.RawMessage `json:"data"`    // Profile data
 	SpaceID string          `json:"spaceId"` // Target space ID (optional, derived from type)
+	// ExpectedVersion, if set, makes the write fail with 409 unless the
+	// object is still at this version (0 for a new object)
+	ExpectedVersion *int `json:"expectedVersion,omitempty"`
 }
 
 // HandleCreateProfile handles POST /api/v1/profiles — create or update a profile.
@@ -100,29 +118,80 @@ func (h *ProfilesHandler) HandleCreateProfile(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	if req.Type == "" {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "type is required"})
+	result, err := h.WriteProfile(r.Context(), req)
+	if err != nil {
+		writeProfileWriteError(w, err)
 		return
 	}
 
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"success":  true,
+		"objectId": result.ObjectID,
+		"headId":   result.HeadID,
+		"treeId":   result.TreeID,
+		"version":  result.Version,
+		"spaceId":  result.SpaceID,
+	})
+}
+
+// ProfileWriteResult describes a profile object written by WriteProfile.
+type ProfileWriteResult struct {
+	ObjectID string `json:"objectId"`
+	HeadID   string `json:"headId"`
+	TreeID   string `json:"treeId"`
+	Version  int    `json:"version"`
+	SpaceID  string `json:"spaceId"`
+}
+
+// ProfileWriteError is a profile write that failed, with the HTTP status it
+// maps to. ValidationErrors lists schema violations when the data was invalid.
+type ProfileWriteError struct {
+	Status           int
+	Message          string
+	ValidationErrors []string
+}
+
+func (e *ProfileWriteError) Error() string {
+	if len(e.ValidationErrors) > 0 {
+		return fmt.Sprintf("%s: %s", e.Message, strings.Join(e.ValidationErrors, "; "))
+	}
+	return e.Message
+}
+
+// writeProfileWriteError writes the response for a failed WriteProfile.
+func writeProfileWriteError(w http.ResponseWriter, err error) {
+	var writeErr *ProfileWriteError
+	if !errors.As(err, &writeErr) {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	} else if len(writeErr.ValidationErrors) > 0 {
+		writeJSON(w, writeErr.Status, map[string]interface{}{
+			"error":            writeErr.Message,
+			"validationErrors": writeErr.ValidationErrors,
+		})
+	} else {
+		writeJSON(w, writeErr.Status, map[string]string{"error": writeErr.Message})
+	}
+}
+
+// WriteProfile validates req against its type definition and writes it as
+// the next version of the object, generating an ID for the caller if none is
+// given. With req.ExpectedVersion the write is a compare-and-swap (see
+// CompareAndAddObject). Failures are returned as *ProfileWriteError.
+func (h *ProfilesHandler) WriteProfile(ctx context.Context, req CreateProfileRequest) (*ProfileWriteResult, error) {
+	if req.Type == "" {
+		return nil, &ProfileWriteError{Status: http.StatusBadRequest, Message: "type is required"}
+	}
+
 	// Validate against type definition
 	def, ok := h.registry.Get(req.Type)
 	if !ok {
-		writeJSON(w, http.StatusBadRequest, map[string]string{
-			"error": fmt.Sprintf("unknown type: %s", req.Type),
-		})
-		return
+		return nil, &ProfileWriteError{Status: http.StatusBadRequest, Message: fmt.Sprintf("unknown type: %s", req.Type)}
 	}
 
 	if errs, err := h.registry.Validate(req.Type, req.Data); err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
-		return
+		return nil, &ProfileWriteError{Status: http.StatusBadRequest, Message: err.Error()}
 	} else if len(errs) > 0 {
-		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
-			"error":            "validation failed",
-			"validationErrors": errs,
-		})
-		return
+		return nil, &ProfileWriteError{Status: http.StatusBadRequest, Message: "validation failed", ValidationErrors: errs}
 	}
 
 	// Determine target space
@@ -131,45 +200,46 @@ func (h *ProfilesHandler) HandleCreateProfile(w http.ResponseWriter, r *http.Req
 		spaceID = h.resolveSpaceForType(def)
 	}
 	if spaceID == "" {
-		writeJSON(w, http.StatusBadRequest, map[string]string{
-			"error": fmt.Sprintf("no space configured for type %s (space=%s)", req.Type, def.Space),
-		})
-		return
+		return nil, &ProfileWriteError{
+			Status:  http.StatusBadRequest,
+			Message: fmt.Sprintf("no space configured for type %s (space=%s)", req.Type, def.Space),
+		}
 	}
 
 	// Generate object ID if not provided
 	objectID := req.ID
 	if objectID == "" {
-		aid := ""
-		if h.userIdentity != nil {
-			aid = h.userIdentity.GetAID()
-		}
+		aid := callerAID(ctx, h.userIdentity)
 		objectID = fmt.Sprintf("%s-%s-%d", req.Type, aid, time.Now().UnixMilli())
 	}
 
 	// Get signing key for the space
 	client := h.spaceManager.GetClient()
 	if client == nil {
-		writeJSON(w, http.StatusServiceUnavailable, map[string]string{
-			"error": "any-sync client not available",
-		})
-		return
+		return nil, &ProfileWriteError{Status: http.StatusServiceUnavailable, Message: "any-sync client not available"}
 	}
 
 	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), spaceID, client.GetSigningKey())
 	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": fmt.Sprintf("failed to load space keys: %v", err),
-		})
-		return
+		return nil, &ProfileWriteError{Status: http.StatusInternalServerError, Message: fmt.Sprintf("failed to load space keys: %v", err)}
 	}
 
 	// Determine version (read existing to increment)
-	ctx := r.Context()
 	objMgr := h.spaceManager.ObjectTreeManager()
 	version := 1
+	var previous json.RawMessage
 	if existing, err := objMgr.ReadLatestByID(ctx, spaceID, objectID); err == nil {
 		version = existing.Version + 1
+		previous = existing.Data
+	}
+	if req.ExpectedVersion != nil && *req.ExpectedVersion != version-1 {
+		return nil, &ProfileWriteError{
+			Status:  http.StatusConflict,
+			Message: fmt.Sprintf("%s is at version %d, expected %d", objectID, version-1, *req.ExpectedVersion),
+		}
+	}
+	if err := h.checkImageFields(ctx, def, req.Data, previous); err != nil {
+		return nil, &ProfileWriteError{Status: http.StatusBadRequest, Message: "validation failed", ValidationErrors: []string{err.Error()}}
 	}
 
 	// Build owner key
@@ -190,25 +260,56 @@ func (h *ProfilesHandler) HandleCreateProfile(w http.ResponseWriter, r *http.Req
 		Version:   version,
 	}
 
-	headID, err := objMgr.AddObject(ctx, spaceID, payload, keys.SigningKey)
+	var headID string
+	if req.ExpectedVersion != nil {
+		headID, err = objMgr.CompareAndAddObject(ctx, spaceID, payload, *req.ExpectedVersion, keys.SigningKey)
+	} else {
+		headID, err = objMgr.AddObject(ctx, spaceID, payload, keys.SigningKey)
+	}
+	if errors.Is(err, anysync.ErrVersionConflict) {
+		return nil, &ProfileWriteError{Status: http.StatusConflict, Message: err.Error()}
+	}
 	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": fmt.Sprintf("failed to write profile: %v", err),
-		})
-		return
+		return nil, &ProfileWriteError{Status: objectWriteStatus(err), Message: fmt.Sprintf("failed to write profile: %v", err)}
 	}
 
-	// Get tree ID for the response
-	treeID := objMgr.GetTreeIDForObject(objectID)
+	if req.Type == "SharedProfile" && h.profiles != nil {
+		// Generic writes don't tell us whose profile this is; drop them all
+		h.profiles.InvalidateAll()
+	}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"success":  true,
-		"objectId": objectID,
-		"headId":   headID,
-		"treeId":   treeID,
-		"version":  version,
-		"spaceId":  spaceID,
-	})
+	return &ProfileWriteResult{
+		ObjectID: objectID,
+		HeadID:   headID,
+		TreeID:   objMgr.GetTreeIDForObject(objectID),
+		Version:  version,
+		SpaceID:  spaceID,
+	}, nil
+}
+
+// checkImageFields checks each image-upload field of def in data (an avatar
+// or logo) with checkAvatarRef against the community space, where avatars
+// are uploaded. previous is the object's current data, whose values may be
+// kept as they are.
+func (h *ProfilesHandler) checkImageFields(ctx context.Context, def *types.TypeDefinition, data, previous json.RawMessage) error {
+	var fields, old map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil // Already rejected by the type validation
+	}
+	if len(previous) > 0 {
+		json.Unmarshal(previous, &old)
+	}
+	for _, f := range def.Fields {
+		if f.UIHints == nil || f.UIHints.InputType != "image-upload" {
+			continue
+		}
+		ref, _ := fields[f.Name].(string)
+		prev, _ := old[f.Name].(string)
+		if err := checkAvatarRef(ctx, avatarFiles(h.fileManager), h.spaceManager.GetCommunitySpaceID(), ref, prev); err != nil {
+			return fmt.Errorf("field %q: %w", f.Name, err)
+		}
+	}
+	return nil
 }
 
 // HandleListProfiles handles GET /api/v1/profiles/{type} — list profiles of a type.
@@ -308,10 +409,7 @@ func (h *ProfilesHandler) HandleMyProfiles(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	aid := ""
-	if h.userIdentity != nil {
-		aid = h.userIdentity.GetAID()
-	}
+	aid := callerAID(r.Context(), h.userIdentity)
 	if aid == "" {
 		writeJSON(w, http.StatusBadRequest, map[string]string{
 			"error": "Identity not configured",
@@ -420,15 +518,29 @@ func (h *ProfilesHandler) HandleInitMemberProfiles(w http.ResponseWriter, r *htt
 		return
 	}
 
+	if err := checkAvatarRef(r.Context(), avatarFiles(h.fileManager), h.spaceManager.GetCommunitySpaceID(), req.Avatar, ""); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("avatar must be the fileRef of an uploaded image: %v", err),
+		})
+		return
+	}
+
 	// If no pre-uploaded avatar fileRef but base64 data is available, upload now.
 	// Use a separate context so the retry loop doesn't consume the request timeout.
 	if req.Avatar == "" && req.AvatarData != "" {
+		avatarData, avatarType, err := decodeBase64Avatar(req.AvatarData, req.AvatarMimeType, defaultMaxAvatarSize)
+		if err != nil {
+			writeJSON(w, avatarErrorStatus(err), map[string]string{
+				"error": fmt.Sprintf("invalid avatarData: %v", err),
+			})
+			return
+		}
 		communitySpaceID := h.spaceManager.GetCommunitySpaceID()
 		if communitySpaceID != "" {
 			client := h.spaceManager.GetClient()
 			if client != nil {
 				avatarCtx, avatarCancel := context.WithTimeout(context.Background(), 12*time.Second)
-				if fileRef, uploadErr := uploadBase64Avatar(avatarCtx, h.fileManager, communitySpaceID, client.GetSigningKey(), req.AvatarData, req.AvatarMimeType); uploadErr != nil {
+				if fileRef, uploadErr := storeAvatar(avatarCtx, h.fileManager, communitySpaceID, client.GetSigningKey(), avatarData, avatarType); uploadErr != nil {
 					fmt.Printf("Warning: failed to upload base64 member avatar: %v\n", uploadErr)
 				} else {
 					req.Avatar = fileRef
@@ -543,7 +655,7 @@ func (h *ProfilesHandler) HandleInitMemberProfiles(w http.ResponseWriter, r *htt
 
 	headID, err := objMgr.AddObject(ctx, roSpaceID, payload, keys.SigningKey)
 	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{
+		writeJSON(w, objectWriteStatus(err), map[string]string{
 			"error": fmt.Sprintf("failed to write CommunityProfile: %v", err),
 		})
 		return
@@ -629,6 +741,8 @@ func (h *ProfilesHandler) HandleInitMemberProfiles(w http.ResponseWriter, r *htt
 			return
 		}
 
+		h.invalidateProfile(req.MemberAID)
+
 		result["sharedProfileObjectId"] = sharedObjectID
 		result["sharedProfileHeadId"] = sharedHeadID
 		result["sharedProfileTreeId"] = objMgr.GetTreeIDForObject(sharedObjectID)
@@ -751,7 +865,7 @@ func (h *ProfilesHandler) HandleUpdateMemberRole(w http.ResponseWriter, r *http.
 	}
 
 	if _, err := objMgr.UpsertFields(ctx, roSpaceID, targetObj.ID, newFields, keys.SigningKey); err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{
+		writeJSON(w, objectWriteStatus(err), map[string]string{
 			"error": fmt.Sprintf("failed to update profile: %v", err),
 		})
 		return
@@ -788,10 +902,7 @@ func (h *ProfilesHandler) HandleRemoveMember(w http.ResponseWriter, r *http.Requ
 	// Body is optional for DELETE; ignore decode errors
 	_ = json.NewDecoder(r.Body).Decode(&req)
 
-	adminAID := ""
-	if h.userIdentity != nil {
-		adminAID = h.userIdentity.GetAID()
-	}
+	adminAID := callerAID(r.Context(), h.userIdentity)
 
 	roSpaceID := h.spaceManager.GetCommunityReadOnlySpaceID()
 	if roSpaceID == "" {
@@ -846,7 +957,7 @@ func (h *ProfilesHandler) HandleRemoveMember(w http.ResponseWriter, r *http.Requ
 
 	communityProfileID := fmt.Sprintf("CommunityProfile-%s", memberAID)
 	if _, err := objMgr.UpsertFields(ctx, roSpaceID, communityProfileID, roFields, roKeys.SigningKey); err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{
+		writeJSON(w, objectWriteStatus(err), map[string]string{
 			"error": fmt.Sprintf("failed to update CommunityProfile: %v", err),
 		})
 		return
@@ -874,6 +985,8 @@ func (h *ProfilesHandler) HandleRemoveMember(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	h.invalidateProfile(memberAID)
+
 	log.Printf("[RemoveMember] Removed member %s by admin %s", memberAID, adminAID)
 
 	if h.eventBroker != nil {
@@ -960,6 +1073,7 @@ func (h *ProfilesHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/api/v1/profiles/me", h.HandleMyProfiles)
 	mux.HandleFunc("/api/v1/profiles/init-member", h.HandleInitMemberProfiles)
 	mux.HandleFunc("/api/v1/members/", h.handleMembers)
+	mux.HandleFunc("/api/v1/profile/", h.handleProfile)
 }
 
 // handleMembers routes /api/v1/members/* requests.
@@ -201,7 +201,7 @@ func (h *ProposalsHandler) HandleTransition(w http.ResponseWriter, r *http.Reque
 	// Rejection requires admin (steward/founding) role
 	targetStatus := contributions.ProposalStatus(req.Status)
 	if targetStatus == contributions.ProposalRejected {
-		aid := r.Header.Get("X-User-AID")
+		aid := requestUserAID(r)
 		if aid == "" {
 			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "X-User-AID header required"})
 			return
@@ -218,7 +218,7 @@ func (h *ProposalsHandler) HandleTransition(w http.ResponseWriter, r *http.Reque
 
 	// Sign-off: community admin role OR the proposal's assigned steward.
 	if targetStatus == contributions.ProposalSignedOff {
-		aid := r.Header.Get("X-User-AID")
+		aid := requestUserAID(r)
 		if aid == "" {
 			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "X-User-AID header required"})
 			return
@@ -245,7 +245,7 @@ func (h *ProposalsHandler) HandleTransition(w http.ResponseWriter, r *http.Reque
 
 	// Withdrawal: only the proposer or a community admin may withdraw
 	if targetStatus == contributions.ProposalWithdrawn {
-		aid := r.Header.Get("X-User-AID")
+		aid := requestUserAID(r)
 		if aid == "" {
 			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "X-User-AID header required"})
 			return
@@ -280,7 +280,7 @@ func (h *ProposalsHandler) HandleTransition(w http.ResponseWriter, r *http.Reque
 	}
 	h.service.AddHistoryEntry(r.Context(), spaceID, &contributions.ProposalHistoryEntry{
 		ProposalID: id,
-		UserID:     r.Header.Get("X-User-AID"),
+		UserID:     requestUserAID(r),
 		Action:     action,
 	})
 
@@ -391,7 +391,7 @@ func (h *ProposalsHandler) HandleUpdate(w http.ResponseWriter, r *http.Request,
 		return
 	}
 	if existing.Status == contributions.ProposalInReview && !isRoleClaimOnly(&req) {
-		aid := r.Header.Get("X-User-AID")
+		aid := requestUserAID(r)
 		if aid == "" {
 			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "X-User-AID header required"})
 			return
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/matou-dao/backend/internal/anysync"
+)
+
+// maxQuarantinedObjects caps how many objects the quarantine lists; the
+// oldest entry is dropped to make room.
+const maxQuarantinedObjects = 1000
+
+// QuarantinedObject is an object that read paths skipped because its data
+// didn't parse.
+type QuarantinedObject struct {
+	SpaceID   string `json:"spaceId"`
+	ObjectID  string `json:"objectId"`
+	Type      string `json:"type"`
+	Version   int    `json:"version"`
+	Error     string `json:"error"`
+	Skips     int    `json:"skips"`     // reads that skipped it
+	FirstSeen string `json:"firstSeen"` // RFC3339
+	LastSeen  string `json:"lastSeen"`  // RFC3339
+}
+
+// QuarantineResponse is the response for GET /api/v1/admin/quarantine.
+type QuarantineResponse struct {
+	Objects []QuarantinedObject `json:"objects"` // most recently skipped first
+	Count   int                 `json:"count"`
+	Skipped int64               `json:"skipped"` // skips since startup, including dropped entries
+}
+
+// Quarantine records objects whose data fails to parse on read. Reads still
+// skip them, but operators can list them to spot schema drift or corruption
+// instead of items silently going missing. An object that parses again is
+// taken off the list. A nil *Quarantine records nothing.
+type Quarantine struct {
+	mu      sync.Mutex
+	objects map[string]*QuarantinedObject
+	order   []string // keys, oldest first
+
+	size    atomic.Int64 // len(objects), for the lock-free fast path
+	skipped atomic.Int64
+}
+
+// NewQuarantine creates an empty quarantine.
+func NewQuarantine() *Quarantine {
+	return &Quarantine{objects: make(map[string]*QuarantinedObject)}
+}
+
+func quarantineKey(spaceID, objectID string) string {
+	return spaceID + "/" + objectID
+}
+
+// Record notes that obj in spaceID was skipped because its data failed to
+// parse with err.
+func (q *Quarantine) Record(spaceID string, obj *anysync.ObjectPayload, err error) {
+	if q == nil {
+		return
+	}
+	q.skipped.Add(1)
+	now := time.Now().UTC().Format(time.RFC3339)
+	key := quarantineKey(spaceID, obj.ID)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	entry, ok := q.objects[key]
+	if !ok {
+		log.Printf("[Quarantine] Skipping %s %s v%d in %s: %v", obj.Type, obj.ID, obj.Version, spaceID, err)
+		if len(q.order) >= maxQuarantinedObjects {
+			delete(q.objects, q.order[0])
+			q.order = q.order[1:]
+		}
+		entry = &QuarantinedObject{SpaceID: spaceID, ObjectID: obj.ID, FirstSeen: now}
+		q.objects[key] = entry
+		q.order = append(q.order, key)
+		q.size.Store(int64(len(q.objects)))
+	}
+	entry.Type = obj.Type
+	entry.Version = obj.Version
+	entry.Error = err.Error()
+	entry.Skips++
+	entry.LastSeen = now
+}
+
+// release takes obj off the list once a version of it at or after the
+// quarantined one parses.
+func (q *Quarantine) release(spaceID string, obj *anysync.ObjectPayload) {
+	if q == nil || q.size.Load() == 0 {
+		return
+	}
+	key := quarantineKey(spaceID, obj.ID)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	entry, ok := q.objects[key]
+	if !ok || obj.Version < entry.Version {
+		return
+	}
+	delete(q.objects, key)
+	for i, k := range q.order {
+		if k == key {
+			q.order = append(q.order[:i], q.order[i+1:]...)
+			break
+		}
+	}
+	q.size.Store(int64(len(q.objects)))
+}
+
+// List returns the quarantined objects, most recently skipped first.
+func (q *Quarantine) List() []QuarantinedObject {
+	if q == nil {
+		return []QuarantinedObject{}
+	}
+	q.mu.Lock()
+	list := make([]QuarantinedObject, 0, len(q.objects))
+	for _, entry := range q.objects {
+		list = append(list, *entry)
+	}
+	q.mu.Unlock()
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].LastSeen != list[j].LastSeen {
+			return list[i].LastSeen > list[j].LastSeen
+		}
+		return list[i].ObjectID < list[j].ObjectID
+	})
+	return list
+}
+
+// Skipped returns how many reads skipped a quarantined object since startup.
+func (q *Quarantine) Skipped() int64 {
+	if q == nil {
+		return 0
+	}
+	return q.skipped.Load()
+}
+
+// decodeObjectData unmarshals obj's data into v. When it doesn't parse, obj
+// is recorded in q and the error returned, so read paths can still skip it.
+func decodeObjectData(q *Quarantine, spaceID string, obj *anysync.ObjectPayload, v any) error {
+	if err := json.Unmarshal(obj.Data, v); err != nil {
+		q.Record(spaceID, obj, err)
+		return err
+	}
+	q.release(spaceID, obj)
+	return nil
+}
+
+// SetQuarantine records chat objects skipped on read in q.
+func (h *ChatHandler) SetQuarantine(q *Quarantine) {
+	h.quarantine = q
+}
+
+// SetQuarantine records notice-board objects skipped on read in q.
+func (h *NoticesHandler) SetQuarantine(q *Quarantine) {
+	h.quarantine = q
+}
+
+// SetQuarantine sets the quarantine served by GET /api/v1/admin/quarantine.
+func (h *AdminHandler) SetQuarantine(q *Quarantine) {
+	h.quarantine = q
+}
+
+// HandleQuarantine handles GET /api/v1/admin/quarantine, listing objects
+// skipped on read because their data didn't parse.
+func (h *AdminHandler) HandleQuarantine(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		return
+	}
+	objects := h.quarantine.List()
+	writeJSON(w, http.StatusOK, QuarantineResponse{
+		Objects: objects,
+		Count:   len(objects),
+		Skipped: h.quarantine.Skipped(),
+	})
+}
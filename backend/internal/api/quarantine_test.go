@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matou-dao/backend/internal/anysync"
+)
+
+func TestQuarantine_MalformedChannelReported(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+	quarantine := NewQuarantine()
+	env.chatHandler.SetQuarantine(quarantine)
+	admin := NewAdminHandler(env.spaceManager)
+	admin.SetQuarantine(quarantine)
+
+	createTestChannel(t, env, "general")
+
+	// A channel whose data no longer matches ChatChannelData
+	spaceID := env.spaceManager.GetCommunitySpaceID()
+	client := env.spaceManager.GetClient()
+	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), spaceID, client.GetSigningKey())
+	if err != nil {
+		t.Fatalf("loading keys: %v", err)
+	}
+	objMgr := env.spaceManager.ObjectTreeManager()
+	bad := &anysync.ObjectPayload{ID: "ChatChannel-corrupt", Type: "ChatChannel", Data: json.RawMessage(`{"name": 42}`), Version: 1}
+	if _, err := objMgr.AddObject(t.Context(), spaceID, bad, keys.SigningKey); err != nil {
+		t.Fatalf("writing malformed channel: %v", err)
+	}
+
+	listChannels := func() []map[string]interface{} {
+		t.Helper()
+		w := httptest.NewRecorder()
+		env.mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/chat/channels", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("listing channels: %d %s", w.Code, w.Body.String())
+		}
+		var resp struct {
+			Channels []map[string]interface{} `json:"channels"`
+		}
+		json.NewDecoder(w.Body).Decode(&resp)
+		return resp.Channels
+	}
+	getQuarantine := func() QuarantineResponse {
+		t.Helper()
+		w := httptest.NewRecorder()
+		admin.HandleQuarantine(w, httptest.NewRequest(http.MethodGet, "/api/v1/admin/quarantine", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("quarantine: %d %s", w.Code, w.Body.String())
+		}
+		var resp QuarantineResponse
+		json.NewDecoder(w.Body).Decode(&resp)
+		return resp
+	}
+
+	// The read still skips the object, but it's reported
+	if channels := listChannels(); len(channels) != 1 || channels[0]["name"] != "general" {
+		t.Fatalf("expected only the general channel, got %v", channels)
+	}
+	resp := getQuarantine()
+	if resp.Count != 1 || resp.Skipped != 1 {
+		t.Fatalf("expected 1 quarantined object and 1 skip, got %+v", resp)
+	}
+	got := resp.Objects[0]
+	if got.ObjectID != "ChatChannel-corrupt" || got.Type != "ChatChannel" || got.Version != 1 || got.SpaceID != spaceID || got.Error == "" {
+		t.Errorf("unexpected quarantine entry %+v", got)
+	}
+
+	listChannels()
+	if resp := getQuarantine(); resp.Count != 1 || resp.Skipped != 2 || resp.Objects[0].Skips != 2 {
+		t.Errorf("expected repeated skips counted on the same entry, got %+v", resp)
+	}
+
+	// A repaired version is listed again and leaves the quarantine
+	fixed := &anysync.ObjectPayload{ID: "ChatChannel-corrupt", Type: "ChatChannel", Data: json.RawMessage(`{"name": "repaired"}`), Version: 2}
+	if _, err := objMgr.AddObject(t.Context(), spaceID, fixed, keys.SigningKey); err != nil {
+		t.Fatalf("repairing channel: %v", err)
+	}
+	if channels := listChannels(); len(channels) != 2 {
+		t.Errorf("expected the repaired channel listed, got %v", channels)
+	}
+	if resp := getQuarantine(); resp.Count != 0 || resp.Skipped != 2 {
+		t.Errorf("expected the repaired object released, got %+v", resp)
+	}
+}
+
+func TestQuarantine_EvictsOldest(t *testing.T) {
+	q := NewQuarantine()
+	for i := 0; i <= maxQuarantinedObjects; i++ {
+		q.Record("space", &anysync.ObjectPayload{ID: fmt.Sprintf("obj-%d", i), Type: "ChatMessage"}, json.Unmarshal([]byte("x"), new(int)))
+	}
+	list := q.List()
+	if len(list) != maxQuarantinedObjects {
+		t.Fatalf("expected %d entries, got %d", maxQuarantinedObjects, len(list))
+	}
+	for _, o := range list {
+		if o.ObjectID == "obj-0" {
+			t.Error("expected the oldest entry to be dropped")
+		}
+	}
+	if q.Skipped() != maxQuarantinedObjects+1 {
+		t.Errorf("expected every skip counted, got %d", q.Skipped())
+	}
+}
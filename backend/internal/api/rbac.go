@@ -25,10 +25,11 @@ type RoleLookup interface {
 }
 
 // RBACMiddleware extracts the user AID from the X-User-AID header,
-// resolves their roles, and stores both in the request context.
+// resolves their roles, and stores both in the request context. In token
+// auth mode the AID comes from the session token and the header is ignored.
 func RBACMiddleware(lookup RoleLookup, next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		aid := r.Header.Get("X-User-AID")
+		aid := requestUserAID(r)
 		if aid == "" {
 			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "X-User-AID header required"})
 			return
@@ -59,6 +60,15 @@ func RequireAction(action contributions.Action, next http.HandlerFunc) http.Hand
 	}
 }
 
+// requestUserAID returns the AID the request acts as for RBAC: the session
+// token's AID in token auth mode, otherwise the X-User-AID header.
+func requestUserAID(r *http.Request) string {
+	if aid, ok := authenticatedAID(r); ok {
+		return aid
+	}
+	return r.Header.Get("X-User-AID")
+}
+
 // GetUserAID extracts the user AID from the request context.
 func GetUserAID(r *http.Request) string {
 	aid, _ := r.Context().Value(ctxUserAID).(string)
@@ -77,7 +87,7 @@ func GetUserRoles(r *http.Request) []contributions.Role {
 // through with no roles set.
 func OptionalRBACMiddleware(lookup RoleLookup, next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		aid := r.Header.Get("X-User-AID")
+		aid := requestUserAID(r)
 		if aid != "" && lookup != nil {
 			roles, err := lookup.GetUserRoles(aid)
 			if err != nil {
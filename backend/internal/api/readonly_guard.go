@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/matou-dao/backend/internal/anysync"
+	"github.com/matou-dao/backend/internal/identity"
+)
+
+// NewReadOnlySpaceGuard returns an ObjectTreeManager write guard that keeps
+// the community read-only space read-only for members. A member's backend
+// holds a signing key for the space once it has joined, so the keys on disk
+// can't decide this. A write there is allowed when the caller is the org AID,
+// when admins resolves the caller to a community admin role, or when the
+// caller is this backend's own identity and its account manages the space's
+// ACL (the admin's backend while it seeds a new community, before the org
+// config lists any admins). Writes to any other space pass.
+//
+// admins must not count the backend's own identity as an admin (as
+// IdentityRoleLookup does), or every member would pass.
+func NewReadOnlySpaceGuard(spaceManager *anysync.SpaceManager, userIdentity *identity.UserIdentity, admins RoleLookup) func(ctx context.Context, spaceID string) error {
+	return func(ctx context.Context, spaceID string) error {
+		roSpaceID := spaceManager.GetCommunityReadOnlySpaceID()
+		if roSpaceID == "" || spaceID != roSpaceID {
+			return nil
+		}
+		aid := callerAID(ctx, userIdentity)
+		if aid != "" && spaceManager.IsOrgAdmin(aid) {
+			return nil
+		}
+		if aid != "" && admins != nil {
+			if roles, err := admins.GetUserRoles(aid); err == nil && isCommunityAdmin(roles) {
+				return nil
+			}
+		}
+		if aid != "" && userIdentity != nil && aid == userIdentity.GetAID() && managesSpaceACL(ctx, spaceManager, spaceID) {
+			return nil
+		}
+		log.Printf("[ReadOnlyGuard] Rejected write to read-only space %s by %q", spaceID, aid)
+		return fmt.Errorf("%w: only community admins can write to the community read-only space", anysync.ErrReadOnlySpace)
+	}
+}
+
+// managesSpaceACL reports whether this backend's account is an owner or admin
+// in the space's ACL. Any failure to read the ACL counts as no.
+func managesSpaceACL(ctx context.Context, spaceManager *anysync.SpaceManager, spaceID string) bool {
+	client := spaceManager.GetClient()
+	aclMgr := spaceManager.ACLManager()
+	if client == nil || aclMgr == nil || client.GetSigningKey() == nil {
+		return false
+	}
+	perm, err := aclMgr.GetPermissions(ctx, spaceID, client.GetSigningKey().GetPublic())
+	if err != nil {
+		return false
+	}
+	return perm.IsOwner() || perm.CanManageAccounts()
+}
+
+// objectWriteStatus maps an object write error to its HTTP status: 403 for
+// writes the write guard refused, 500 otherwise.
+func objectWriteStatus(err error) int {
+	if errors.Is(err, anysync.ErrReadOnlySpace) {
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matou-dao/backend/internal/anysync"
+	"github.com/matou-dao/backend/internal/contributions"
+	"github.com/matou-dao/backend/internal/types"
+)
+
+func TestReadOnlySpaceGuard_MemberRejectedAdminAllowed(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	const adminAID = "EADMIN_RO_GUARD"
+	memberAID := env.userIdentity.GetAID()
+	admins := &mockRoleLookup{roles: map[string][]contributions.Role{
+		adminAID: {contributions.RoleFoundingMember},
+	}}
+	objMgr := env.spaceManager.ObjectTreeManager()
+	objMgr.SetWriteGuard(NewReadOnlySpaceGuard(env.spaceManager, env.userIdentity, admins))
+
+	registry := types.NewRegistry()
+	registry.Bootstrap()
+	handler := NewProfilesHandler(env.spaceManager, env.userIdentity, registry, nil, nil)
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	write := func(typeName, id string, data map[string]interface{}) *httptest.ResponseRecorder {
+		raw, _ := json.Marshal(data)
+		body, _ := json.Marshal(CreateProfileRequest{Type: typeName, ID: id, Data: raw})
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/profiles", bytes.NewReader(body)))
+		return w
+	}
+	communityProfile := map[string]interface{}{
+		"userAID":    memberAID,
+		"credential": "ESAID_Guard",
+		"role":       "Founding Member",
+	}
+
+	// The member's backend has keys for the read-only space, but may not write there
+	if w := write("CommunityProfile", "CommunityProfile-"+memberAID, communityProfile); w.Code != http.StatusForbidden {
+		t.Fatalf("member write to read-only space: expected 403, got %d: %s", w.Code, w.Body.String())
+	}
+	client := env.spaceManager.GetClient()
+	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), env.spaceManager.GetCommunityReadOnlySpaceID(), client.GetSigningKey())
+	if err != nil {
+		t.Fatalf("loading keys: %v", err)
+	}
+	payload := profileObject(t, "CommunityProfile", "CommunityProfile-"+memberAID, 1, communityProfile)
+	if _, err := objMgr.AddObject(t.Context(), env.spaceManager.GetCommunityReadOnlySpaceID(), payload, keys.SigningKey); !errors.Is(err, anysync.ErrReadOnlySpace) {
+		t.Errorf("member AddObject: expected ErrReadOnlySpace, got %v", err)
+	}
+	roSpaceID := env.spaceManager.GetCommunityReadOnlySpaceID()
+	fields := map[string]json.RawMessage{"userAID": json.RawMessage(`"` + memberAID + `"`)}
+	if _, _, err := objMgr.CreateObject(t.Context(), roSpaceID, "CommunityProfile-guard", "CommunityProfile", anysync.ProfileTreeType, fields, keys.SigningKey); !errors.Is(err, anysync.ErrReadOnlySpace) {
+		t.Errorf("member CreateObject: expected ErrReadOnlySpace, got %v", err)
+	}
+	if _, err := objMgr.UpdateObject(t.Context(), roSpaceID, "CommunityProfile-guard", fields, keys.SigningKey); !errors.Is(err, anysync.ErrReadOnlySpace) {
+		t.Errorf("member UpdateObject: expected ErrReadOnlySpace, got %v", err)
+	}
+
+	// Writes to the community space are unaffected
+	if w := write("SharedProfile", "SharedProfile-"+memberAID, map[string]interface{}{"aid": memberAID, "displayName": "Member", "status": "approved"}); w.Code != http.StatusOK {
+		t.Errorf("member write to community space: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	env.userIdentity.SetIdentity(adminAID, "admin-mnemonic")
+	communityProfile["role"] = "Member"
+	if w := write("CommunityProfile", "CommunityProfile-"+memberAID, communityProfile); w.Code != http.StatusOK {
+		t.Fatalf("admin write to read-only space: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	obj, err := objMgr.ReadLatestByID(t.Context(), env.spaceManager.GetCommunityReadOnlySpaceID(), "CommunityProfile-"+memberAID)
+	if err != nil {
+		t.Fatalf("reading admin write: %v", err)
+	}
+	if fields, _ := anysync.FieldsFromJSON(obj.Data); string(fields["role"]) != `"Member"` {
+		t.Errorf("expected the admin's write, got %s", obj.Data)
+	}
+}
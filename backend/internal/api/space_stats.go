@@ -0,0 +1,90 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/matou-dao/backend/internal/anysync"
+)
+
+// SpaceStatsTotals sums SpaceStats across spaces.
+type SpaceStatsTotals struct {
+	SpaceCount    int   `json:"spaceCount"`
+	DBSizeBytes   int64 `json:"dbSizeBytes"`
+	DiskSizeBytes int64 `json:"diskSizeBytes"`
+	ObjectCount   int   `json:"objectCount"`
+}
+
+// AllSpaceStatsResponse is the response for GET /api/v1/spaces/stats.
+type AllSpaceStatsResponse struct {
+	Spaces []*anysync.SpaceStats `json:"spaces"`
+	Totals SpaceStatsTotals      `json:"totals"`
+}
+
+// HandleSpaceStats handles GET /api/v1/spaces/{id}/stats.
+// Reports the space's on-disk size, object counts by type and when its
+// storage was last written. Only the community's own spaces (community,
+// read-only and admin) are reported.
+func (h *SpacesHandler) HandleSpaceStats(w http.ResponseWriter, r *http.Request) {
+	spaceID, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/spaces/"), "/stats")
+	if !ok || !h.isCommunitySpace(spaceID) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		return
+	}
+
+	// Pick up trees that arrived via sync since the index was last built
+	if treeMgr := h.spaceManager.TreeManager(); treeMgr != nil {
+		_ = treeMgr.BuildSpaceIndex(r.Context(), spaceID)
+	}
+
+	stats, err := h.spaceManager.SpaceStats(spaceID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("failed to read space stats: %v", err),
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// HandleAllSpaceStats handles GET /api/v1/spaces/stats.
+// Reports stats for every space on this node along with their totals.
+func (h *SpacesHandler) HandleAllSpaceStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method not allowed"})
+		return
+	}
+
+	all, err := h.spaceManager.AllSpaceStats()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("failed to read space stats: %v", err),
+		})
+		return
+	}
+
+	resp := AllSpaceStatsResponse{Spaces: all}
+	for _, stats := range all {
+		resp.Totals.SpaceCount++
+		resp.Totals.DBSizeBytes += stats.DBSizeBytes
+		resp.Totals.DiskSizeBytes += stats.DiskSizeBytes
+		resp.Totals.ObjectCount += stats.ObjectCount
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// isCommunitySpace reports whether spaceID is one of the configured
+// community spaces.
+func (h *SpacesHandler) isCommunitySpace(spaceID string) bool {
+	if spaceID == "" {
+		return false
+	}
+	return spaceID == h.spaceManager.GetCommunitySpaceID() ||
+		spaceID == h.spaceManager.GetCommunityReadOnlySpaceID() ||
+		spaceID == h.spaceManager.GetAdminSpaceID()
+}
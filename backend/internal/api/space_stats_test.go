@@ -0,0 +1,126 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matou-dao/backend/internal/anysync"
+	"github.com/matou-dao/backend/internal/contributions"
+)
+
+func TestSpaceStats(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	createTestChannel(t, env, "stats-a")
+	channelID := createTestChannel(t, env, "stats-b")
+	sendTestMessage(t, env, channelID, "counted")
+
+	// The SDK's storage provider keeps each space's database in its own directory
+	communitySpaceID := env.spaceManager.GetCommunitySpaceID()
+	spaceDir := filepath.Join(anysync.SpacesStorageDir(env.spaceManager.GetClient().GetDataDir()), communitySpaceID)
+	if err := os.MkdirAll(spaceDir, 0755); err != nil {
+		t.Fatalf("creating space dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(spaceDir, "data.db"), make([]byte, 4096), 0644); err != nil {
+		t.Fatalf("writing data.db: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(spaceDir, "data.db-wal"), make([]byte, 512), 0644); err != nil {
+		t.Fatalf("writing data.db-wal: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	roleLookup := &mockRoleLookup{roles: map[string][]contributions.Role{
+		"EADMIN":  {contributions.RoleOperationsSteward},
+		"EMEMBER": {contributions.RoleMember},
+	}}
+	NewSpacesHandler(env.spaceManager, nil, env.userIdentity, nil).RegisterRoutes(mux, roleLookup)
+	getAs := func(aid, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-User-AID", aid)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+	get := func(path string) *httptest.ResponseRecorder { return getAs("EADMIN", path) }
+
+	t.Run("single space", func(t *testing.T) {
+		w := get("/api/v1/spaces/" + communitySpaceID + "/stats")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var stats anysync.SpaceStats
+		json.NewDecoder(w.Body).Decode(&stats)
+		if stats.Missing {
+			t.Error("expected the space directory to be found")
+		}
+		if stats.ObjectsByType["ChatChannel"] != 2 || stats.ObjectsByType["ChatMessage"] != 1 {
+			t.Errorf("objectsByType = %v, want 2 channels and 1 message", stats.ObjectsByType)
+		}
+		if stats.DBSizeBytes != 4096 || stats.DiskSizeBytes != 4096+512 {
+			t.Errorf("sizes = %d/%d, want 4096 for data.db and %d on disk", stats.DBSizeBytes, stats.DiskSizeBytes, 4096+512)
+		}
+		if stats.LastModified == nil || stats.LastModified.IsZero() {
+			t.Error("expected a last-modified time")
+		}
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		w := get("/api/v1/spaces/" + env.spaceManager.GetCommunityReadOnlySpaceID() + "/stats")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var stats anysync.SpaceStats
+		json.NewDecoder(w.Body).Decode(&stats)
+		if !stats.Missing || stats.DBSizeBytes != 0 || stats.ObjectCount != 0 {
+			t.Errorf("expected an empty missing space, got %+v", stats)
+		}
+	})
+
+	t.Run("summary", func(t *testing.T) {
+		w := get("/api/v1/spaces/stats")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp AllSpaceStatsResponse
+		json.NewDecoder(w.Body).Decode(&resp)
+
+		byID := map[string]*anysync.SpaceStats{}
+		for _, stats := range resp.Spaces {
+			byID[stats.SpaceID] = stats
+		}
+		if byID[communitySpaceID] == nil || byID[communitySpaceID].DBSizeBytes != 4096 {
+			t.Errorf("expected the community space in the summary, got %+v", resp.Spaces)
+		}
+		if ro := byID[env.spaceManager.GetCommunityReadOnlySpaceID()]; ro == nil || !ro.Missing {
+			t.Errorf("expected the configured readonly space reported as missing, got %+v", ro)
+		}
+		if resp.Totals.SpaceCount != len(resp.Spaces) || resp.Totals.DBSizeBytes != 4096 || resp.Totals.ObjectCount < 3 {
+			t.Errorf("unexpected totals %+v", resp.Totals)
+		}
+	})
+
+	t.Run("admins only", func(t *testing.T) {
+		for _, path := range []string{"/api/v1/spaces/" + communitySpaceID + "/stats", "/api/v1/spaces/stats"} {
+			if w := getAs("EMEMBER", path); w.Code != http.StatusForbidden {
+				t.Errorf("%s as member: expected 403, got %d", path, w.Code)
+			}
+		}
+	})
+
+	t.Run("non-community space", func(t *testing.T) {
+		if w := get("/api/v1/spaces/some-private-space/stats"); w.Code != http.StatusNotFound {
+			t.Errorf("expected 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("unknown sub-path", func(t *testing.T) {
+		if w := get("/api/v1/spaces/" + communitySpaceID + "/other"); w.Code != http.StatusNotFound {
+			t.Errorf("expected 404, got %d", w.Code)
+		}
+	})
+}
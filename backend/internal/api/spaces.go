@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/anyproto/any-sync/commonspace/object/acl/list"
@@ -24,17 +25,94 @@ type SpacesHandler struct {
 	spaceStore   anysync.SpaceStore
 	userIdentity *identity.UserIdentity
 	fileManager  *anysync.FileManager
+
+	// joinSyncTimeout bounds how long HandleJoinCommunity waits for the
+	// joined spaces to sync before responding
+	joinSyncTimeout time.Duration
+
+	// allowUnsignedInvites lets HandleJoinCommunity accept the legacy loose
+	// invite fields without a signed envelope
+	allowUnsignedInvites bool
+
+	// inviteIssuerKey returns the trusted signing key of a community admin,
+	// or "" for any other AID (see SetInviteIssuerKeys)
+	inviteIssuerKey func(aid string) string
+
+	// inviteLimiter caps invite requests per inviter (see invite_audit.go)
+	inviteLimiter *inviteRateLimiter
+
+	// outbox queues invite audit records whose write fails (see SetWriteOutbox)
+	outbox *WriteOutbox
+
+	// communityMu serializes HandleCreateCommunity so a concurrent request
+	// finds the spaces the first one derived instead of seeding them again
+	communityMu sync.Mutex
+
+	// Sync stall monitor (see sync_stall.go)
+	eventBroker        *EventBroker
+	syncStallThreshold time.Duration
+	loop               backgroundLoop
 }
 
 // NewSpacesHandler creates a new spaces handler
 func NewSpacesHandler(spaceManager *anysync.SpaceManager, store *anystore.LocalStore, userIdentity *identity.UserIdentity, fileManager *anysync.FileManager) *SpacesHandler {
 	return &SpacesHandler{
-		spaceManager: spaceManager,
-		store:        store,
-		spaceStore:   anystore.NewSpaceStoreAdapter(store),
-		userIdentity: userIdentity,
-		fileManager:  fileManager,
+		spaceManager:         spaceManager,
+		store:                store,
+		spaceStore:           anystore.NewSpaceStoreAdapter(store),
+		userIdentity:         userIdentity,
+		fileManager:          fileManager,
+		joinSyncTimeout:      DefaultJoinSyncTimeout,
+		allowUnsignedInvites: true,
+		inviteLimiter:        newInviteRateLimiter(DefaultInviteRateLimit, DefaultInviteRateWindow),
+		syncStallThreshold:   anysync.DefaultSyncStallThreshold,
+	}
+}
+
+// SetJoinSyncTimeout sets how long joining the community waits for initial
+// sync before returning a "pending" sync status.
+func (h *SpacesHandler) SetJoinSyncTimeout(d time.Duration) {
+	h.joinSyncTimeout = d
+}
+
+// SetAllowUnsignedInvites sets whether joining the community accepts invites
+// without a signed envelope, as sent by older clients.
+func (h *SpacesHandler) SetAllowUnsignedInvites(allow bool) {
+	h.allowUnsignedInvites = allow
+}
+
+// SetInviteIssuerKeys sets how joining the community finds the key a signed
+// invite must verify against: keyFor returns the base64 public key of the
+// community admin aid, or "" if aid isn't one. Without it, every signed
+// invite is rejected.
+func (h *SpacesHandler) SetInviteIssuerKeys(keyFor func(aid string) string) {
+	h.inviteIssuerKey = keyFor
+}
+
+// LocalInviteSigner returns this node's AID and the key its invites are
+// signed with, or empty strings before the identity and SDK are set up.
+func (h *SpacesHandler) LocalInviteSigner() (aid, key string) {
+	client := h.spaceManager.GetClient()
+	if h.userIdentity == nil || client == nil || client.GetSigningKey() == nil {
+		return "", ""
+	}
+	key, err := inviteSigningKey(client.GetSigningKey())
+	if err != nil {
+		return "", ""
 	}
+	return h.userIdentity.GetAID(), key
+}
+
+// SetInviteRateLimit sets how many invite requests each inviter may make per
+// window. A limit of zero or less removes the limit.
+func (h *SpacesHandler) SetInviteRateLimit(limit int, window time.Duration) {
+	h.inviteLimiter = newInviteRateLimiter(limit, window)
+}
+
+// SetWriteOutbox queues invite audit records for retry when their write to
+// the admin space fails. Without it such an invite fails instead.
+func (h *SpacesHandler) SetWriteOutbox(o *WriteOutbox) {
+	h.outbox = o
 }
 
 // CreateCommunityRequest represents a request to create a community space
@@ -107,6 +185,7 @@ type InviteResponse struct {
 	InviteKey              string `json:"inviteKey,omitempty"`              // base64-encoded community invite private key
 	ReadOnlyInviteKey      string `json:"readOnlyInviteKey,omitempty"`      // base64-encoded community-readonly invite key
 	ReadOnlySpaceID        string `json:"readOnlySpaceId,omitempty"`        // community-readonly space ID
+	Invite                 string `json:"invite,omitempty"`                 // signed InviteEnvelope covering the fields above
 	Error                  string `json:"error,omitempty"`
 }
 
@@ -137,8 +216,8 @@ func (h *SpacesHandler) HandleGetUserSpaces(w http.ResponseWriter, r *http.Reque
 	}
 
 	aid := r.URL.Query().Get("aid")
-	if aid == "" && h.userIdentity != nil {
-		aid = h.userIdentity.GetAID()
+	if aid == "" {
+		aid = callerAID(r.Context(), h.userIdentity)
 	}
 	if aid == "" {
 		writeJSON(w, http.StatusBadRequest, map[string]string{
@@ -243,6 +322,33 @@ func (h *SpacesHandler) HandleCreateCommunity(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	// The admin avatar is seeded into profiles as a fileRef: either one
+	// already uploaded, or inline image data uploaded below once the space
+	// exists. Both are checked before anything is created, the uploaded file
+	// itself once there is a space to look it up in.
+	if err := checkAvatarRef(r.Context(), nil, "", req.AdminAvatar, ""); err != nil {
+		writeJSON(w, http.StatusBadRequest, CreateCommunityResponse{
+			Success: false,
+			Error:   fmt.Sprintf("adminAvatar must be the fileRef of an uploaded image: %v", err),
+		})
+		return
+	}
+	var avatarData []byte
+	var avatarType string
+	if req.AdminAvatar == "" && req.AdminAvatarData != "" {
+		var avatarErr error
+		if avatarData, avatarType, avatarErr = decodeBase64Avatar(req.AdminAvatarData, req.AdminAvatarMimeType, defaultMaxAvatarSize); avatarErr != nil {
+			writeJSON(w, avatarErrorStatus(avatarErr), CreateCommunityResponse{
+				Success: false,
+				Error:   fmt.Sprintf("invalid adminAvatarData: %v", avatarErr),
+			})
+			return
+		}
+	}
+
+	h.communityMu.Lock()
+	defer h.communityMu.Unlock()
+
 	// Check if community space already exists
 	existingSpace, err := h.spaceManager.GetCommunitySpace(r.Context())
 	if err == nil && existingSpace != nil {
@@ -267,16 +373,26 @@ func (h *SpacesHandler) HandleCreateCommunity(w http.ResponseWriter, r *http.Req
 			})
 			return
 		}
+		// With the coordinator down the space can't be verified either way;
+		// keep the persisted IDs rather than recreating the community
+		if client != nil && client.Ping() != nil {
+			writeJSON(w, http.StatusServiceUnavailable, CreateCommunityResponse{
+				Success: false,
+				Error:   "coordinator unreachable: cannot verify the existing community space",
+			})
+			return
+		}
 		// Clear stale cached IDs so we fall through to recreation
 		h.spaceManager.SetCommunitySpaceID("")
 		h.spaceManager.SetCommunityReadOnlySpaceID("")
 		h.spaceManager.SetAdminSpaceID("")
 	}
 
-	// Create new community space via any-sync client using mnemonic-derived keys.
+	// Derive the community spaces via any-sync client from mnemonic-derived keys.
 	// The admin's identity must be set first (POST /api/v1/identity/set) so we
 	// can derive deterministic keys from the stored mnemonic. This makes the admin
-	// the recoverable owner of the community space.
+	// the recoverable owner of the community space, and since the space IDs are
+	// derived too, the same mnemonic always yields the same spaces.
 	ctx := r.Context()
 	client := h.spaceManager.GetClient()
 	if client == nil {
@@ -290,6 +406,13 @@ func (h *SpacesHandler) HandleCreateCommunity(w http.ResponseWriter, r *http.Req
 	// Derive community space keys from stored mnemonic (index 1; index 0 = private space)
 	mnemonic := ""
 	if h.userIdentity != nil {
+		if h.userIdentity.IsLocked() {
+			writeJSON(w, http.StatusLocked, CreateCommunityResponse{
+				Success: false,
+				Error:   errIdentityLocked,
+			})
+			return
+		}
 		mnemonic = h.userIdentity.GetMnemonic()
 	}
 	if mnemonic == "" {
@@ -314,7 +437,7 @@ func (h *SpacesHandler) HandleCreateCommunity(w http.ResponseWriter, r *http.Req
 	// like BuildInviteAnyone succeed when the admin creates invites later.
 	keys.SigningKey = client.GetSigningKey()
 
-	result, err := client.CreateSpaceWithKeys(ctx, req.OrgAID, anysync.SpaceTypeCommunity, keys)
+	result, err := client.DeriveSpaceWithKeys(ctx, req.OrgAID, anysync.SpaceTypeCommunity, keys)
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, CreateCommunityResponse{
 			Success: false,
@@ -346,13 +469,20 @@ func (h *SpacesHandler) HandleCreateCommunity(w http.ResponseWriter, r *http.Req
 	// Update space manager with the new community space ID
 	h.spaceManager.SetCommunitySpaceID(result.SpaceID)
 
-	// If no pre-uploaded avatar fileRef but base64 data is available, upload now.
-	// Use a separate context so the avatar retry loop doesn't consume the
-	// request's timeout budget (the frontend has a 15s AbortSignal).
-	if req.AdminAvatar == "" && req.AdminAvatarData != "" {
+	// A pre-uploaded avatar fileRef must resolve to an image in the space; it
+	// is dropped rather than seeded broken. If there is none but base64 data
+	// is available, upload now. Use a separate context so the avatar retry
+	// loop doesn't consume the request's timeout budget (the frontend has a
+	// 15s AbortSignal).
+	if req.AdminAvatar != "" {
+		if err := checkAvatarRef(ctx, avatarFiles(h.fileManager), result.SpaceID, req.AdminAvatar, ""); err != nil {
+			log.Printf("Warning: dropping admin avatar: %v\n", err)
+			req.AdminAvatar = ""
+		}
+	} else if avatarData != nil {
 		avatarCtx, avatarCancel := context.WithTimeout(context.Background(), 12*time.Second)
 		defer avatarCancel()
-		if fileRef, uploadErr := uploadBase64Avatar(avatarCtx, h.fileManager, result.SpaceID, client.GetSigningKey(), req.AdminAvatarData, req.AdminAvatarMimeType); uploadErr != nil {
+		if fileRef, uploadErr := storeAvatar(avatarCtx, h.fileManager, result.SpaceID, client.GetSigningKey(), avatarData, avatarType); uploadErr != nil {
 			log.Printf("Warning: failed to upload base64 admin avatar: %v\n", uploadErr)
 		} else {
 			req.AdminAvatar = fileRef
@@ -389,7 +519,7 @@ func (h *SpacesHandler) HandleCreateCommunity(w http.ResponseWriter, r *http.Req
 		log.Printf("Warning: failed to derive community-readonly space keys: %v\n", err)
 	} else {
 		roKeys.SigningKey = client.GetSigningKey()
-		roResult, err := client.CreateSpaceWithKeys(ctx, req.OrgAID, anysync.SpaceTypeCommunityReadOnly, roKeys)
+		roResult, err := client.DeriveSpaceWithKeys(ctx, req.OrgAID, anysync.SpaceTypeCommunityReadOnly, roKeys)
 		if err != nil {
 			log.Printf("Warning: failed to create community-readonly space: %v\n", err)
 		} else {
@@ -454,7 +584,7 @@ func (h *SpacesHandler) HandleCreateCommunity(w http.ResponseWriter, r *http.Req
 		log.Printf("Warning: failed to derive admin space keys: %v\n", err)
 	} else {
 		adminKeys.SigningKey = client.GetSigningKey()
-		adminResult, err := client.CreateSpaceWithKeys(ctx, req.OrgAID, anysync.SpaceTypeAdmin, adminKeys)
+		adminResult, err := client.DeriveSpaceWithKeys(ctx, req.OrgAID, anysync.SpaceTypeAdmin, adminKeys)
 		if err != nil {
 			log.Printf("Warning: failed to create admin space: %v\n", err)
 		} else {
@@ -478,6 +608,9 @@ func (h *SpacesHandler) HandleCreateCommunity(w http.ResponseWriter, r *http.Req
 					log.Printf("Warning: failed to persist admin space ID: %v\n", err)
 				}
 			}
+			if err := seedAdminSettings(ctx, h.spaceManager, adminResult.SpaceID, req.AdminAID); err != nil {
+				log.Printf("Warning: failed to seed admin settings: %v\n", err)
+			}
 		}
 	}
 
@@ -592,10 +725,8 @@ func (h *SpacesHandler) HandleCreatePrivate(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// In per-user mode, use local identity as fallback
-	if req.UserAID == "" && h.userIdentity != nil {
-		req.UserAID = h.userIdentity.GetAID()
-	}
+	// In per-user mode, use the caller's identity as fallback
+	req.UserAID = requestedAID(r, req.UserAID, h.userIdentity)
 	if req.UserAID == "" {
 		writeJSON(w, http.StatusBadRequest, CreatePrivateResponse{
 			Success: false,
@@ -760,6 +891,10 @@ func (h *SpacesHandler) HandleInvite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.checkInviteRateLimit(w, r) {
+		return
+	}
+
 	ctx := r.Context()
 
 	// Get community space
@@ -793,6 +928,15 @@ func (h *SpacesHandler) HandleInvite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	inviterAID := callerAID(ctx, h.userIdentity)
+	if err := recordInviteAudit(ctx, h.spaceManager, h.outbox, inviteKey, inviterAID, communitySpace.SpaceID, InvitePermissionWriter); err != nil {
+		writeJSON(w, http.StatusInternalServerError, InviteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("failed to record invite audit: %v", err),
+		})
+		return
+	}
+
 	// Marshal invite private key to bytes and base64-encode
 	inviteKeyBytes, err := inviteKey.Marshall()
 	if err != nil {
@@ -819,6 +963,9 @@ func (h *SpacesHandler) HandleInvite(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 		roInviteKey, roErr := aclMgr.CreateOpenInvite(ctx, roSpaceID, list.AclPermissionsReader)
+		if roErr == nil {
+			roErr = recordInviteAudit(ctx, h.spaceManager, h.outbox, roInviteKey, inviterAID, roSpaceID, InvitePermissionReader)
+		}
 		if roErr != nil {
 			log.Printf("Warning: failed to create community-readonly invite: %v\n", roErr)
 		} else {
@@ -830,6 +977,25 @@ func (h *SpacesHandler) HandleInvite(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Sign the invite so the member's node can tell if it was altered in transit
+	if client != nil && client.GetSigningKey() != nil {
+		env := &InviteEnvelope{
+			SpaceID:           resp.CommunitySpaceID,
+			InviteKey:         resp.InviteKey,
+			ReadOnlySpaceID:   resp.ReadOnlySpaceID,
+			ReadOnlyInviteKey: resp.ReadOnlyInviteKey,
+			ExpiresAt:         time.Now().Add(inviteEnvelopeTTL).UTC().Format(time.RFC3339),
+		}
+		if h.userIdentity != nil {
+			env.Issuer = h.userIdentity.GetAID()
+		}
+		if signed, err := signInviteEnvelope(env, client.GetSigningKey()); err != nil {
+			log.Printf("[Invite] Warning: failed to sign invite: %v\n", err)
+		} else {
+			resp.Invite = signed
+		}
+	}
+
 	writeJSON(w, http.StatusOK, resp)
 }
 
@@ -840,13 +1006,58 @@ type JoinCommunityRequest struct {
 	SpaceID            string `json:"spaceId,omitempty"`            // community space ID (fallback if not configured locally)
 	ReadOnlyInviteKey  string `json:"readOnlyInviteKey,omitempty"`  // base64-encoded community-readonly invite key
 	ReadOnlySpaceID    string `json:"readOnlySpaceId,omitempty"`    // community-readonly space ID
+	Invite             string `json:"invite,omitempty"`             // signed InviteEnvelope; takes the place of the fields above
 }
 
+// DefaultJoinSyncTimeout is how long joining the community waits, in total,
+// for the community and readonly spaces to sync.
+const DefaultJoinSyncTimeout = 30 * time.Second
+
+// Sync status values returned by HandleJoinCommunity
+const (
+	JoinSyncStatusSynced  = "synced"  // joined spaces have synced their initial trees
+	JoinSyncStatusPending = "pending" // joined, but sync is still in progress; poll sync-status
+)
+
 // JoinCommunityResponse represents the response for community join
 type JoinCommunityResponse struct {
-	Success bool   `json:"success"`
-	SpaceID string `json:"spaceId,omitempty"`
-	Error   string `json:"error,omitempty"`
+	Success    bool   `json:"success"`
+	SpaceID    string `json:"spaceId,omitempty"`
+	SyncStatus string `json:"syncStatus,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// syncWaiter waits for a space's trees to arrive (UnifiedTreeManager implements it).
+type syncWaiter interface {
+	WaitForSync(ctx context.Context, spaceID string, minTrees int, timeout time.Duration) error
+}
+
+// waitForSpacesSync waits for each space to sync at least one tree. The waits
+// run concurrently and share a single deadline, timeout from now; it reports
+// whether every space synced in time.
+func waitForSpacesSync(ctx context.Context, waiter syncWaiter, timeout time.Duration, spaceIDs ...string) bool {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allSynced := true
+	for _, spaceID := range spaceIDs {
+		wg.Add(1)
+		go func(spaceID string) {
+			defer wg.Done()
+			if err := waiter.WaitForSync(ctx, spaceID, 1, timeout); err != nil {
+				log.Printf("[JoinCommunity] WaitForSync warning for space %s: %v", spaceID, err)
+				mu.Lock()
+				allSynced = false
+				mu.Unlock()
+				return
+			}
+			log.Printf("[JoinCommunity] WaitForSync OK for space %s", spaceID)
+		}(spaceID)
+	}
+	wg.Wait()
+	return allSynced
 }
 
 // HandleJoinCommunity handles POST /api/v1/spaces/community/join
@@ -868,6 +1079,40 @@ func (h *SpacesHandler) HandleJoinCommunity(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	signed := req.Invite != ""
+	if signed {
+		env, err := decodeInviteEnvelope(req.Invite, time.Now(), h.inviteIssuerKey)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, JoinCommunityResponse{
+				Success: false,
+				Error:   fmt.Sprintf("invalid invite: %v", err),
+			})
+			return
+		}
+		// Loose fields sent alongside the envelope must agree with it
+		if (req.SpaceID != "" && req.SpaceID != env.SpaceID) ||
+			(req.InviteKey != "" && req.InviteKey != env.InviteKey) ||
+			(req.ReadOnlySpaceID != "" && req.ReadOnlySpaceID != env.ReadOnlySpaceID) ||
+			(req.ReadOnlyInviteKey != "" && req.ReadOnlyInviteKey != env.ReadOnlyInviteKey) {
+			writeJSON(w, http.StatusBadRequest, JoinCommunityResponse{
+				Success: false,
+				Error:   "invite fields do not match the signed invite",
+			})
+			return
+		}
+		req.SpaceID = env.SpaceID
+		req.InviteKey = env.InviteKey
+		req.ReadOnlySpaceID = env.ReadOnlySpaceID
+		req.ReadOnlyInviteKey = env.ReadOnlyInviteKey
+		log.Printf("[JoinCommunity] Verified invite from %s for space %s", env.Issuer, env.SpaceID)
+	} else if !h.allowUnsignedInvites {
+		writeJSON(w, http.StatusBadRequest, JoinCommunityResponse{
+			Success: false,
+			Error:   "a signed invite is required",
+		})
+		return
+	}
+
 	if req.UserAID == "" || req.InviteKey == "" {
 		writeJSON(w, http.StatusBadRequest, JoinCommunityResponse{
 			Success: false,
@@ -892,6 +1137,13 @@ func (h *SpacesHandler) HandleJoinCommunity(w http.ResponseWriter, r *http.Reque
 		})
 		return
 	}
+	if signed && communitySpace.SpaceID != req.SpaceID {
+		writeJSON(w, http.StatusBadRequest, JoinCommunityResponse{
+			Success: false,
+			Error:   "invite is for a different community space",
+		})
+		return
+	}
 
 	// Decode invite key from base64
 	inviteKeyBytes, err := base64.StdEncoding.DecodeString(req.InviteKey)
@@ -964,14 +1216,7 @@ func (h *SpacesHandler) HandleJoinCommunity(w http.ResponseWriter, r *http.Reque
 		return
 	}
 	log.Printf("[JoinCommunity] Generated and persisted space keys for community space %s\n", communitySpace.SpaceID)
-
-	// Wait for initial sync to complete so member sees existing data
-	if treeMgr := h.spaceManager.TreeManager(); treeMgr != nil {
-		if err := treeMgr.WaitForSync(ctx, communitySpace.SpaceID, 1, 30*time.Second); err != nil {
-			log.Printf("[JoinCommunity] WaitForSync warning for space %s: %v\n", communitySpace.SpaceID, err)
-			// Don't fail — data will arrive via next HeadSync cycle
-		}
-	}
+	syncSpaceIDs := []string{communitySpace.SpaceID}
 
 	// Also join community-readonly space if invite key is provided
 	log.Printf("[JoinCommunity] readOnly check: key=%v spaceID=%q", req.ReadOnlyInviteKey != "", req.ReadOnlySpaceID)
@@ -990,7 +1235,7 @@ func (h *SpacesHandler) HandleJoinCommunity(w http.ResponseWriter, r *http.Reque
 					h.spaceManager.SetCommunityReadOnlySpaceID(req.ReadOnlySpaceID)
 					log.Printf("[JoinCommunity] User %s joined community-readonly space %s", req.UserAID, req.ReadOnlySpaceID)
 
-					// Persist keys for the readonly space FIRST (before WaitForSync).
+					// Persist keys for the readonly space before waiting for sync.
 					// Same reasoning as the community space above.
 					roKeys, roKeyGenErr := anysync.GenerateSpaceKeySet()
 					if roKeyGenErr == nil {
@@ -1001,26 +1246,28 @@ func (h *SpacesHandler) HandleJoinCommunity(w http.ResponseWriter, r *http.Reque
 							log.Printf("[JoinCommunity] Generated and persisted space keys for readonly space %s", req.ReadOnlySpaceID)
 						}
 					}
-
-					// Wait for initial sync of readonly space (same as community space above)
-					if treeMgr := h.spaceManager.TreeManager(); treeMgr != nil {
-						log.Printf("[JoinCommunity] calling WaitForSync for readonly space %s", req.ReadOnlySpaceID)
-						if waitErr := treeMgr.WaitForSync(ctx, req.ReadOnlySpaceID, 1, 30*time.Second); waitErr != nil {
-							log.Printf("[JoinCommunity] WaitForSync warning for readonly space %s: %v", req.ReadOnlySpaceID, waitErr)
-						} else {
-							log.Printf("[JoinCommunity] WaitForSync OK for readonly space %s", req.ReadOnlySpaceID)
-						}
-					} else {
-						log.Printf("[JoinCommunity] TreeManager is nil — skipping WaitForSync for readonly space")
-					}
+					syncSpaceIDs = append(syncSpaceIDs, req.ReadOnlySpaceID)
 				}
 			}
 		}
 	}
 
+	// Wait for initial sync so the member sees existing data. A slow sync
+	// doesn't fail the join — data arrives via the next HeadSync cycle and
+	// the client polls sync-status while the status is pending.
+	syncStatus := JoinSyncStatusPending
+	if treeMgr := h.spaceManager.TreeManager(); treeMgr != nil {
+		if waitForSpacesSync(ctx, treeMgr, h.joinSyncTimeout, syncSpaceIDs...) {
+			syncStatus = JoinSyncStatusSynced
+		}
+	} else {
+		log.Printf("[JoinCommunity] TreeManager is nil — skipping WaitForSync")
+	}
+
 	writeJSON(w, http.StatusOK, JoinCommunityResponse{
-		Success: true,
-		SpaceID: communitySpace.SpaceID,
+		Success:    true,
+		SpaceID:    communitySpace.SpaceID,
+		SyncStatus: syncStatus,
 	})
 }
 
@@ -1140,6 +1387,10 @@ func (h *SpacesHandler) HandleCommunityReadOnlyInvite(w http.ResponseWriter, r *
 		return
 	}
 
+	if !h.checkInviteRateLimit(w, r) {
+		return
+	}
+
 	aclMgr := h.spaceManager.ACLManager()
 	inviteKey, err := aclMgr.CreateOpenInvite(ctx, roSpaceID, list.AclPermissionsReader)
 	if err != nil {
@@ -1149,6 +1400,13 @@ func (h *SpacesHandler) HandleCommunityReadOnlyInvite(w http.ResponseWriter, r *
 		})
 		return
 	}
+	if err := recordInviteAudit(ctx, h.spaceManager, h.outbox, inviteKey, callerAID(ctx, h.userIdentity), roSpaceID, InvitePermissionReader); err != nil {
+		writeJSON(w, http.StatusInternalServerError, InviteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("failed to record invite audit: %v", err),
+		})
+		return
+	}
 
 	inviteKeyBytes, err := inviteKey.Marshall()
 	if err != nil {
@@ -1168,14 +1426,16 @@ func (h *SpacesHandler) HandleCommunityReadOnlyInvite(w http.ResponseWriter, r *
 
 // SyncStatusResponse reports sync readiness for the user's spaces.
 type SyncStatusResponse struct {
-	Community SpaceSyncStatus `json:"community"`
-	ReadOnly  SpaceSyncStatus `json:"readOnly"`
-	Ready     bool            `json:"ready"`
+	Community SpaceSyncStatus    `json:"community"`
+	ReadOnly  SpaceSyncStatus    `json:"readOnly"`
+	Ready     bool               `json:"ready"`
+	Pool      *anysync.PoolStats `json:"pool,omitempty"` // peer connection pool counters
 }
 
 // SyncMetrics reports P2P sync activity for a space.
 type SyncMetrics struct {
 	TreesChanged  int `json:"treesChanged"`  // number of locally changed trees
+	TreesPending  int `json:"treesPending"`  // locally changed trees no peer has acknowledged
 	HeadsReceived int `json:"headsReceived"` // total head receive events from peers
 	HeadsApplied  int `json:"headsApplied"`  // total head apply events (successful merges)
 }
@@ -1187,6 +1447,10 @@ type SpaceSyncStatus struct {
 	ObjectCount   int          `json:"objectCount"`
 	ProfileCount  int          `json:"profileCount"`
 	Sync          *SyncMetrics `json:"sync,omitempty"`
+	// Stalled is true while sync makes no progress despite local changes
+	// waiting for a peer to acknowledge them (see sync_stall.go)
+	Stalled     bool                 `json:"stalled"`
+	SyncHistory []anysync.SyncSample `json:"syncHistory,omitempty"` // oldest first
 }
 
 // HandleSyncStatus handles GET /api/v1/spaces/sync-status.
@@ -1234,9 +1498,12 @@ func (h *SpacesHandler) HandleSyncStatus(w http.ResponseWriter, r *http.Request)
 				changed, received, applied := ss.GetStatus()
 				resp.Community.Sync = &SyncMetrics{
 					TreesChanged:  changed,
+					TreesPending:  ss.Pending(),
 					HeadsReceived: received,
 					HeadsApplied:  applied,
 				}
+				resp.Community.Stalled = ss.Stalled()
+				resp.Community.SyncHistory = ss.History()
 			}
 		} else {
 			resp.Community.HasObjectTree = objMgr.HasObjectTree(ctx, communitySpaceID)
@@ -1272,9 +1539,12 @@ func (h *SpacesHandler) HandleSyncStatus(w http.ResponseWriter, r *http.Request)
 				changed, received, applied := ss.GetStatus()
 				resp.ReadOnly.Sync = &SyncMetrics{
 					TreesChanged:  changed,
+					TreesPending:  ss.Pending(),
 					HeadsReceived: received,
 					HeadsApplied:  applied,
 				}
+				resp.ReadOnly.Stalled = ss.Stalled()
+				resp.ReadOnly.SyncHistory = ss.History()
 			}
 		} else {
 			resp.ReadOnly.HasObjectTree = objMgr.HasObjectTree(ctx, roSpaceID)
@@ -1292,6 +1562,7 @@ func (h *SpacesHandler) HandleSyncStatus(w http.ResponseWriter, r *http.Request)
 	}
 
 	resp.Ready = resp.Community.HasObjectTree && resp.ReadOnly.HasObjectTree
+	resp.Pool = h.spaceManager.PoolStats()
 
 	log.Printf("[SyncStatus] community={has=%v obj=%d prof=%d} readOnly={has=%v obj=%d prof=%d} ready=%v",
 		resp.Community.HasObjectTree, resp.Community.ObjectCount, resp.Community.ProfileCount,
@@ -1301,8 +1572,9 @@ func (h *SpacesHandler) HandleSyncStatus(w http.ResponseWriter, r *http.Request)
 	writeJSON(w, http.StatusOK, resp)
 }
 
-// RegisterRoutes registers space routes on the mux
-func (h *SpacesHandler) RegisterRoutes(mux *http.ServeMux) {
+// RegisterRoutes registers space routes on the mux. Space stats are for
+// community admins only.
+func (h *SpacesHandler) RegisterRoutes(mux *http.ServeMux, roleLookup RoleLookup) {
 	mux.HandleFunc("/api/v1/spaces/community", h.handleCommunitySpace)
 	mux.HandleFunc("/api/v1/spaces/community/invite", h.HandleInvite)
 	mux.HandleFunc("/api/v1/spaces/community/join", h.HandleJoinCommunity)
@@ -1311,12 +1583,12 @@ func (h *SpacesHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/api/v1/spaces/private", h.HandleCreatePrivate)
 	mux.HandleFunc("/api/v1/spaces/user", h.HandleGetUserSpaces)
 	mux.HandleFunc("/api/v1/spaces/sync-status", h.HandleSyncStatus)
+	mux.HandleFunc("/api/v1/spaces/stats", RBACMiddleware(roleLookup, requireCommunityAdmin(h.HandleAllSpaceStats)))
+	mux.HandleFunc("/api/v1/spaces/", RBACMiddleware(roleLookup, requireCommunityAdmin(h.HandleSpaceStats))) // {id}/stats
 }
 
-// truncateAID returns the first 12 characters of an AID for display purposes
+// truncateAID returns the first 12 characters of an AID (or all of a shorter
+// one) for display purposes
 func truncateAID(aid string) string {
-	if len(aid) > 12 {
-		return aid[:12]
-	}
-	return aid
+	return aidPrefix(aid, 12)
 }
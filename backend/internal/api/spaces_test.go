@@ -3,10 +3,12 @@ package api
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
@@ -21,11 +23,13 @@ import (
 	"github.com/anyproto/any-sync/nodeconf"
 	"github.com/anyproto/any-sync/util/crypto"
 	"github.com/matou-dao/backend/internal/anysync"
+	"github.com/matou-dao/backend/internal/identity"
 	"go.uber.org/mock/gomock"
 )
 
 // mockAnySyncClient implements anysync.AnySyncClient for testing
 type mockAnySyncClient struct {
+	mu             sync.Mutex
 	spaces         map[string]*anysync.SpaceCreateResult
 	createSpaceErr error
 	addToACLErr    error
@@ -49,6 +53,8 @@ func (m *mockAnySyncClient) CreateSpace(ctx context.Context, ownerAID string, sp
 		return nil, m.createSpaceErr
 	}
 	spaceID := fmt.Sprintf("space_%s_%s", spaceType, ownerAID[:8])
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if existing, ok := m.spaces[spaceID]; ok {
 		return existing, nil
 	}
@@ -95,6 +101,10 @@ func (m *mockAnySyncClient) CreateSpaceWithKeys(ctx context.Context, ownerAID st
 	return m.CreateSpace(ctx, ownerAID, spaceType, nil)
 }
 
+func (m *mockAnySyncClient) DeriveSpaceWithKeys(ctx context.Context, ownerAID string, spaceType string, keys *anysync.SpaceKeySet) (*anysync.SpaceCreateResult, error) {
+	return m.CreateSpace(ctx, ownerAID, spaceType, nil)
+}
+
 func (m *mockAnySyncClient) GetSpace(ctx context.Context, spaceID string) (commonspace.Space, error) {
 	if m.space != nil {
 		return m.space, nil
@@ -326,6 +336,37 @@ func TestHandleCreateCommunity_MissingOrgAID(t *testing.T) {
 	}
 }
 
+func TestHandleCreateCommunity_RejectsInvalidAvatar(t *testing.T) {
+	handler, _, _ := setupTestSpacesHandler(t)
+	oversize := base64.StdEncoding.EncodeToString(make([]byte, defaultMaxAvatarSize+1))
+
+	tests := []struct {
+		name string
+		req  CreateCommunityRequest
+		want int
+	}{
+		{"url avatar", CreateCommunityRequest{AdminAvatar: "https://example.com/me.png"}, http.StatusBadRequest},
+		{"oversize data", CreateCommunityRequest{AdminAvatarData: oversize, AdminAvatarMimeType: "image/png"}, http.StatusRequestEntityTooLarge},
+		{"non-image data", CreateCommunityRequest{AdminAvatarData: base64.StdEncoding.EncodeToString([]byte("not an image"))}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.OrgAID = "EORG123"
+			tt.req.OrgName = "Test Org"
+			body, _ := json.Marshal(tt.req)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/spaces/community", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			handler.HandleCreateCommunity(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("expected status %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
 func TestHandleCreateCommunity_Idempotent(t *testing.T) {
 	handler, _, _ := setupTestSpacesHandler(t)
 
@@ -351,6 +392,73 @@ func TestHandleCreateCommunity_Idempotent(t *testing.T) {
 	}
 }
 
+func TestHandleCreateCommunity_ConcurrentDerivesOneSpace(t *testing.T) {
+	handler, mockClient, _ := setupTestSpacesHandler(t)
+	handler.spaceManager.SetCommunitySpaceID("")
+	handler.userIdentity = identity.New(t.TempDir())
+	if err := handler.userIdentity.SetIdentity("EADMIN", testBackupMnemonic); err != nil {
+		t.Fatalf("SetIdentity: %v", err)
+	}
+
+	body, _ := json.Marshal(CreateCommunityRequest{OrgAID: "EORG123456789", OrgName: "Test Org"})
+	create := func() CreateCommunityResponse {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/spaces/community", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.HandleCreateCommunity(w, req)
+		var resp CreateCommunityResponse
+		json.NewDecoder(w.Body).Decode(&resp)
+		if w.Code != http.StatusOK || !resp.Success {
+			t.Errorf("expected status 200, got %d: %s", w.Code, resp.Error)
+		}
+		return resp
+	}
+
+	resps := make([]CreateCommunityResponse, 2)
+	var wg sync.WaitGroup
+	for i := range resps {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resps[i] = create()
+		}(i)
+	}
+	wg.Wait()
+
+	id := resps[0].CommunitySpaceID
+	if id == "" || resps[1].CommunitySpaceID != id {
+		t.Fatalf("expected one stable community space ID, got %q and %q", id, resps[1].CommunitySpaceID)
+	}
+	if len(mockClient.spaces) != 3 {
+		t.Errorf("expected 3 spaces derived (community, readonly, admin), got %d", len(mockClient.spaces))
+	}
+
+	// Recovering from the same mnemonic with no cached IDs derives the same space
+	handler.spaceManager.SetCommunitySpaceID("")
+	if again := create(); again.CommunitySpaceID != id {
+		t.Errorf("expected re-derivation to return %q, got %q", id, again.CommunitySpaceID)
+	}
+}
+
+func TestHandleCreateCommunity_IdentityLocked(t *testing.T) {
+	handler, _, _ := setupTestSpacesHandler(t)
+	handler.spaceManager.SetCommunitySpaceID("")
+
+	userIdentity := identity.New(t.TempDir())
+	userIdentity.SetIdentityWithPassphrase("EADMIN", "admin-mnemonic", "correct horse")
+	userIdentity.Lock()
+	handler.userIdentity = userIdentity
+
+	body, _ := json.Marshal(CreateCommunityRequest{OrgAID: "EORG123456789", OrgName: "Test Org"})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/spaces/community", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	handler.HandleCreateCommunity(w, req)
+
+	if w.Code != http.StatusLocked {
+		t.Errorf("expected status %d, got %d", http.StatusLocked, w.Code)
+	}
+}
+
 func TestHandleCreateCommunity_MethodNotAllowed(t *testing.T) {
 	handler, _, _ := setupTestSpacesHandler(t)
 
@@ -694,7 +802,7 @@ func TestSpacesHandler_RegisterRoutes(t *testing.T) {
 	handler, _, _ := setupTestSpacesHandler(t)
 
 	mux := http.NewServeMux()
-	handler.RegisterRoutes(mux)
+	handler.RegisterRoutes(mux, nil)
 
 	// Test that routes are registered by making requests
 	testCases := []struct {
@@ -760,3 +868,64 @@ func TestInviteRequest(t *testing.T) {
 		t.Errorf("Schema mismatch")
 	}
 }
+
+// blockingSyncWaiter records concurrent WaitForSync calls. Spaces in synced
+// return once every expected wait has started; others block until ctx is done.
+type blockingSyncWaiter struct {
+	started chan string
+	expect  int
+	synced  map[string]bool
+}
+
+func (b *blockingSyncWaiter) WaitForSync(ctx context.Context, spaceID string, minTrees int, timeout time.Duration) error {
+	b.started <- spaceID
+	if !b.synced[spaceID] {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	// Wait for the other space's call so a sequential implementation deadlocks
+	for len(b.started) < b.expect {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Millisecond):
+		}
+	}
+	return nil
+}
+
+func TestWaitForSpacesSync_Concurrent(t *testing.T) {
+	waiter := &blockingSyncWaiter{
+		started: make(chan string, 2),
+		expect:  2,
+		synced:  map[string]bool{"community": true, "readonly": true},
+	}
+
+	start := time.Now()
+	if !waitForSpacesSync(context.Background(), waiter, 5*time.Second, "community", "readonly") {
+		t.Fatal("expected both spaces to sync")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected concurrent waits to finish quickly, took %v", elapsed)
+	}
+	if len(waiter.started) != 2 {
+		t.Errorf("expected 2 waits, got %d", len(waiter.started))
+	}
+}
+
+func TestWaitForSpacesSync_SharedDeadline(t *testing.T) {
+	waiter := &blockingSyncWaiter{
+		started: make(chan string, 2),
+		expect:  2,
+		synced:  map[string]bool{"community": true},
+	}
+
+	start := time.Now()
+	if waitForSpacesSync(context.Background(), waiter, 100*time.Millisecond, "community", "readonly") {
+		t.Fatal("expected a pending sync when the readonly space times out")
+	}
+	// Both waits share the one deadline rather than each getting the full timeout
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the wait to stop at the deadline, took %v", elapsed)
+	}
+}
@@ -1,10 +1,14 @@
 package api
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"slices"
 	"time"
 
 	"github.com/matou-dao/backend/internal/anysync"
@@ -22,6 +26,16 @@ type SyncHandler struct {
 	spaceManager  *anysync.SpaceManager
 	spaceStore    anysync.SpaceStore
 	userIdentity  *identity.UserIdentity
+	trustUpdater  TrustGraphUpdater
+	profiles      *ProfileResolver
+	profileWriter ProfileWriter
+	eventBroker   *EventBroker
+}
+
+// TrustGraphUpdater receives newly synced credentials so the trust graph can
+// be updated incrementally rather than rebuilt.
+type TrustGraphUpdater interface {
+	ApplyCredentials(ctx context.Context, creds []*anystore.CachedCredential)
 }
 
 // NewSyncHandler creates a new sync handler
@@ -38,14 +52,36 @@ func NewSyncHandler(
 		spaceManager: spaceManager,
 		spaceStore:   spaceStore,
 		userIdentity: userIdentity,
+		profiles:     NewProfileResolver(spaceManager),
 	}
 }
 
+// SetProfileResolver shares a profile resolver (and its cache) with other
+// handlers. By default the handler uses its own.
+func (h *SyncHandler) SetProfileResolver(p *ProfileResolver) {
+	h.profiles = p
+}
+
+// SetTrustGraphUpdater sets the receiver for synced credentials. Without one,
+// a sync just invalidates the trust graph cache.
+func (h *SyncHandler) SetTrustGraphUpdater(u TrustGraphUpdater) {
+	h.trustUpdater = u
+}
+
+// SetProfileWriter sets what writes profile updates sent to the batch sync
+// endpoint. Without one, profile items in a batch are rejected.
+func (h *SyncHandler) SetProfileWriter(pw ProfileWriter) {
+	h.profileWriter = pw
+}
+
 // SyncCredentialsRequest represents a credential sync request from frontend.
 // UserAID is optional in per-user mode (falls back to userIdentity).
 type SyncCredentialsRequest struct {
 	UserAID     string            `json:"userAid,omitempty"`
 	Credentials []keri.Credential `json:"credentials"`
+	// OOBIs maps issuer AIDs to OOBI URLs for resolving issuers that aren't
+	// the org and whose KEL isn't cached
+	OOBIs map[string]string `json:"oobis,omitempty"`
 }
 
 // SyncCredentialsResponse represents a credential sync response
@@ -68,25 +104,52 @@ type SyncKELRequest struct {
 
 // KELEvent represents a single event in a Key Event Log
 type KELEvent struct {
-	Type      string `json:"type"`      // "icp", "rot", "ixn"
-	Sequence  int    `json:"sequence"`  // Event sequence number
-	Digest    string `json:"digest"`    // Event digest
-	Data      any    `json:"data"`      // Event data
-	Timestamp string `json:"timestamp"` // ISO 8601 timestamp
+	Type       string         `json:"type"`                 // "icp", "rot", "ixn"
+	Sequence   int            `json:"sequence"`             // Event sequence number
+	Digest     string         `json:"digest"`               // Event digest
+	Prior      string         `json:"prior,omitempty"`      // Digest of the previous event
+	Data       any            `json:"data"`                 // Event data
+	Raw        string         `json:"raw,omitempty"`        // Serialized event the signatures cover
+	Signatures []string       `json:"signatures,omitempty"` // CESR signatures (icp/rot)
+	Receipts   []keri.Receipt `json:"receipts,omitempty"`   // Witness receipts
+	Timestamp  string         `json:"timestamp"`            // ISO 8601 timestamp
 }
 
 // SyncKELResponse represents a KEL sync response
 type SyncKELResponse struct {
-	Success      bool   `json:"success"`
-	EventsStored int    `json:"eventsStored"`
-	PrivateSpace string `json:"privateSpace,omitempty"`
-	Error        string `json:"error,omitempty"`
+	Success      bool             `json:"success"`
+	EventsStored int              `json:"eventsStored"`
+	PrivateSpace string           `json:"privateSpace,omitempty"`
+	Events       []SyncedKELEvent `json:"events,omitempty"`
+	Error        string           `json:"error,omitempty"`
+	InvalidEvent *keri.KELError   `json:"invalidEvent,omitempty"`
+}
+
+// SyncedKELEvent reports the witness status of a stored KEL event
+type SyncedKELEvent struct {
+	Sequence  int    `json:"sequence"`
+	Digest    string `json:"digest"`
+	Witnessed bool   `json:"witnessed"`
+}
+
+// kelRecord is a KEL event as stored in the KEL cache
+type kelRecord struct {
+	ID      string `json:"id"`
+	UserAID string `json:"userAid"`
+	keri.KeyEvent
+	Timestamp string `json:"timestamp"`
+	CachedAt  string `json:"cachedAt"`
+	// Witnesses is the designated witness set in effect after this event
+	Witnesses []string `json:"witnesses,omitempty"`
+	Witnessed bool     `json:"witnessed"`
 }
 
 // CommunityMember represents a member in the community
 type CommunityMember struct {
 	AID            string `json:"aid"`
 	Alias          string `json:"alias,omitempty"`
+	DisplayName    string `json:"displayName"`
+	Avatar         string `json:"avatar,omitempty"`
 	Role           string `json:"role"`
 	JoinedAt       string `json:"joinedAt"`
 	CredentialSAID string `json:"credentialSaid"`
@@ -95,7 +158,7 @@ type CommunityMember struct {
 // CommunityMembersResponse represents the community members list
 type CommunityMembersResponse struct {
 	Members []CommunityMember `json:"members"`
-	Total   int               `json:"total"`
+	ListPage
 }
 
 // CommunityCredentialsResponse represents community-visible credentials
@@ -124,11 +187,8 @@ func (h *SyncHandler) HandleSyncCredentials(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// In per-user mode, use local identity. Fall back to request body for backward compat.
-	userAID := req.UserAID
-	if userAID == "" && h.userIdentity != nil {
-		userAID = h.userIdentity.GetAID()
-	}
+	// In per-user mode, use the caller's identity. Fall back to request body for backward compat.
+	userAID := requestedAID(r, req.UserAID, h.userIdentity)
 	if userAID == "" {
 		writeJSON(w, http.StatusConflict, SyncCredentialsResponse{
 			Success: false,
@@ -142,6 +202,8 @@ func (h *SyncHandler) HandleSyncCredentials(w http.ResponseWriter, r *http.Reque
 	synced := 0
 	failed := 0
 	spaceSet := make(map[string]bool)
+	var stored []*anystore.CachedCredential
+	issuers := make(map[string]bool)
 
 	// Get or create user's private space
 	privateSpace, err := h.spaceManager.GetOrCreatePrivateSpace(ctx, userAID, h.spaceStore)
@@ -154,51 +216,28 @@ func (h *SyncHandler) HandleSyncCredentials(w http.ResponseWriter, r *http.Reque
 
 	// Process each credential
 	for _, cred := range req.Credentials {
-		// Validate credential structure
-		if err := h.keriClient.ValidateCredential(&cred); err != nil {
-			errors = append(errors, fmt.Sprintf("invalid credential %s: %v", cred.SAID, err))
-			failed++
-			continue
-		}
-
-		// Store in anystore (local cache)
-		cachedCred := &anystore.CachedCredential{
-			ID:         cred.SAID,
-			IssuerAID:  cred.Issuer,
-			SubjectAID: cred.Recipient,
-			SchemaID:   cred.Schema,
-			Data:       cred.Data,
-			CachedAt:   time.Now().UTC(),
-			Verified:   h.keriClient.IsOrgIssued(&cred),
-		}
-
-		if err := h.store.StoreCredential(ctx, cachedCred); err != nil {
-			errors = append(errors, fmt.Sprintf("failed to cache credential %s: %v", cred.SAID, err))
+		result, err := h.syncCredential(ctx, &cred, req.OOBIs, issuers)
+		if err != nil {
+			errors = append(errors, err.Error())
 			failed++
 			continue
 		}
-
-		// Route credential to appropriate spaces
-		anysyncCred := &anysync.Credential{
-			SAID:      cred.SAID,
-			Issuer:    cred.Issuer,
-			Recipient: cred.Recipient,
-			Schema:    cred.Schema,
-			Data:      cred.Data,
-		}
-
-		routedSpaces, routeErr := h.spaceManager.RouteCredential(ctx, anysyncCred, h.spaceStore)
-		if routeErr != nil {
-			errors = append(errors, fmt.Sprintf("failed to route credential %s: %v", cred.SAID, routeErr))
+		stored = append(stored, result.cached)
+		if result.routeErr != nil {
 			// Don't fail - credential is cached even if routing fails
+			errors = append(errors, result.routeErr.Error())
 		}
-		for _, sid := range routedSpaces {
+		for _, sid := range result.spaces {
 			spaceSet[sid] = true
 		}
 
 		synced++
 	}
 
+	h.applyStoredCredentials(ctx, stored)
+
+	h.reverifyResolvedIssuers(ctx, issuers)
+
 	// Collect unique space IDs
 	var spaces []string
 	for sid := range spaceSet {
@@ -229,6 +268,83 @@ func (h *SyncHandler) HandleSyncCredentials(w http.ResponseWriter, r *http.Reque
 	writeJSON(w, status, resp)
 }
 
+// credentialSync is the outcome of syncing one credential.
+type credentialSync struct {
+	cached   *anystore.CachedCredential
+	spaces   []string // spaces the credential was routed to
+	routeErr error    // routing failure; the credential is cached regardless
+}
+
+// syncCredential validates cred, caches it and routes it to its spaces.
+// issuers memoises issuer resolution across the credentials of one request
+// (see issuerVerifiable).
+func (h *SyncHandler) syncCredential(ctx context.Context, cred *keri.Credential, oobis map[string]string, issuers map[string]bool) (*credentialSync, error) {
+	// Validate credential structure
+	if err := h.keriClient.ValidateCredential(cred); err != nil {
+		return nil, fmt.Errorf("invalid credential %s: %v", cred.SAID, err)
+	}
+
+	// Store in anystore (local cache)
+	cachedCred := &anystore.CachedCredential{
+		ID:         cred.SAID,
+		IssuerAID:  cred.Issuer,
+		SubjectAID: cred.Recipient,
+		SchemaID:   cred.Schema,
+		Data:       cred.Data,
+		CachedAt:   time.Now().UTC(),
+		Verified:   h.issuerVerifiable(ctx, cred, oobis, issuers),
+		Signature:  cred.Signature,
+	}
+
+	if err := h.store.StoreCredential(ctx, cachedCred); err != nil {
+		return nil, fmt.Errorf("failed to cache credential %s: %v", cred.SAID, err)
+	}
+
+	// Route credential to appropriate spaces
+	anysyncCred := &anysync.Credential{
+		SAID:      cred.SAID,
+		Issuer:    cred.Issuer,
+		Recipient: cred.Recipient,
+		Schema:    cred.Schema,
+		Data:      cred.Data,
+	}
+
+	result := &credentialSync{cached: cachedCred}
+	routedSpaces, routeErr := h.spaceManager.RouteCredential(ctx, anysyncCred, h.spaceStore)
+	if routeErr != nil {
+		result.routeErr = fmt.Errorf("failed to route credential %s: %v", cred.SAID, routeErr)
+	}
+	result.spaces = routedSpaces
+	return result, nil
+}
+
+// applyStoredCredentials updates or invalidates cached trust scores after
+// credentials were stored, since the credential set changed.
+func (h *SyncHandler) applyStoredCredentials(ctx context.Context, stored []*anystore.CachedCredential) {
+	if len(stored) == 0 {
+		return
+	}
+	if h.trustUpdater != nil {
+		h.trustUpdater.ApplyCredentials(ctx, stored)
+	} else if err := h.store.InvalidateTrustGraphCache(ctx); err != nil {
+		log.Printf("[Sync] Failed to invalidate trust graph cache: %v", err)
+	}
+}
+
+// keyEvent converts a synced KEL event to the form the KERI package verifies.
+func (e KELEvent) keyEvent() keri.KeyEvent {
+	return keri.KeyEvent{
+		Type:       e.Type,
+		Sequence:   e.Sequence,
+		Digest:     e.Digest,
+		Prior:      e.Prior,
+		Data:       e.Data,
+		Raw:        e.Raw,
+		Signatures: e.Signatures,
+		Receipts:   e.Receipts,
+	}
+}
+
 // HandleSyncKEL handles POST /api/v1/sync/kel
 // Receives KEL from frontend (fetched from KERIA) and syncs to private space
 func (h *SyncHandler) HandleSyncKEL(w http.ResponseWriter, r *http.Request) {
@@ -249,11 +365,8 @@ func (h *SyncHandler) HandleSyncKEL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// In per-user mode, use local identity. Fall back to request body for backward compat.
-	kelUserAID := req.UserAID
-	if kelUserAID == "" && h.userIdentity != nil {
-		kelUserAID = h.userIdentity.GetAID()
-	}
+	// In per-user mode, use the caller's identity. Fall back to request body for backward compat.
+	kelUserAID := requestedAID(r, req.UserAID, h.userIdentity)
 	if kelUserAID == "" {
 		writeJSON(w, http.StatusConflict, SyncKELResponse{
 			Success: false,
@@ -272,6 +385,24 @@ func (h *SyncHandler) HandleSyncKEL(w http.ResponseWriter, r *http.Request) {
 
 	ctx := context.Background()
 
+	// Verify SAIDs, sequence, digest chaining and signatures
+	events := make([]keri.KeyEvent, len(req.KEL))
+	for i, e := range req.KEL {
+		events[i] = e.keyEvent()
+	}
+	if err := keri.VerifyKEL(kelUserAID, events, h.priorKEL(ctx, kelUserAID, events[0].Sequence)); err != nil {
+		resp := SyncKELResponse{
+			Success: false,
+			Error:   err.Error(),
+		}
+		var kelErr *keri.KELError
+		if errors.As(err, &kelErr) {
+			resp.InvalidEvent = kelErr
+		}
+		writeJSON(w, http.StatusBadRequest, resp)
+		return
+	}
+
 	// Get or create user's private space
 	privateSpace, err := h.spaceManager.GetOrCreatePrivateSpace(ctx, kelUserAID, h.spaceStore)
 	if err != nil {
@@ -283,7 +414,20 @@ func (h *SyncHandler) HandleSyncKEL(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Store KEL events in anystore
-	kelCollection, err := h.store.KELCache(ctx)
+	timestamps := make([]string, len(req.KEL))
+	for i, e := range req.KEL {
+		timestamps[i] = e.Timestamp
+	}
+	synced, err := h.storeKEL(ctx, kelUserAID, events, timestamps)
+	var kelErr *keri.KELError
+	if errors.As(err, &kelErr) {
+		writeJSON(w, http.StatusConflict, SyncKELResponse{
+			Success:      false,
+			Error:        err.Error(),
+			InvalidEvent: kelErr,
+		})
+		return
+	}
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, SyncKELResponse{
 			Success: false,
@@ -292,18 +436,86 @@ func (h *SyncHandler) HandleSyncKEL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	eventsStored := 0
-	for _, event := range req.KEL {
+	// Credentials cached before this KEL arrived may verify now
+	h.reverifyIssuer(ctx, kelUserAID)
+
+	writeJSON(w, http.StatusOK, SyncKELResponse{
+		Success:      true,
+		EventsStored: len(synced),
+		PrivateSpace: privateSpace.SpaceID,
+		Events:       synced,
+	})
+}
+
+// priorKEL returns aid's cached KEL events before sequence, which a sync
+// starting at sequence continues.
+func (h *SyncHandler) priorKEL(ctx context.Context, aid string, sequence int) []keri.KeyEvent {
+	if sequence == 0 {
+		return nil
+	}
+	var events []keri.KeyEvent
+	for _, rec := range h.storedKELRecords(ctx, aid) {
+		if rec.Sequence < sequence {
+			events = append(events, rec.KeyEvent)
+		}
+	}
+	return events
+}
+
+// storeKEL stores verified KEL events for aid in the KEL cache, collecting
+// receipts already stored for the same events and recording whether each
+// event is witnessed. timestamps holds each event's timestamp, if known.
+// An event differing from the one already stored at its sequence is
+// duplicitous: nothing is stored and a *keri.KELError identifies it.
+// Returns the events that were stored.
+func (h *SyncHandler) storeKEL(ctx context.Context, aid string, events []keri.KeyEvent, timestamps []string) ([]SyncedKELEvent, error) {
+	kelCollection, err := h.store.KELCache(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var prevWitnesses []string
+	if first := events[0].Sequence; first > 0 {
+		if rec := h.storedKELRecord(ctx, aid, first-1); rec != nil {
+			prevWitnesses = rec.Witnesses
+		}
+	}
+
+	// Reject duplicity and collect receipts already stored for the same
+	// event, then check each event against the witness threshold
+	for i := range events {
+		rec := h.storedKELRecord(ctx, aid, events[i].Sequence)
+		if rec == nil {
+			continue
+		}
+		if rec.Digest != events[i].Digest {
+			return nil, &keri.KELError{
+				Index:    i,
+				Sequence: events[i].Sequence,
+				Digest:   events[i].Digest,
+				Reason:   fmt.Sprintf("duplicitous event: sequence %d is already event %q", events[i].Sequence, rec.Digest),
+			}
+		}
+		events[i].Receipts = mergeReceipts(events[i].Receipts, rec.Receipts)
+	}
+	witnessed := keri.ValidateReceipts(events, prevWitnesses, h.keriClient.WitnessThreshold())
+
+	var synced []SyncedKELEvent
+	witnesses := prevWitnesses
+	for i, event := range events {
+		witnesses = keri.DesignatedWitnesses(events[i:i+1], witnesses)
+
 		// Create KEL record
-		record := map[string]interface{}{
-			"id":        fmt.Sprintf("%s-%d", kelUserAID, event.Sequence),
-			"userAid":   kelUserAID,
-			"type":      event.Type,
-			"sequence":  event.Sequence,
-			"digest":    event.Digest,
-			"data":      event.Data,
-			"timestamp": event.Timestamp,
-			"cachedAt":  time.Now().UTC().Format(time.RFC3339),
+		record := kelRecord{
+			ID:        fmt.Sprintf("%s-%d", aid, event.Sequence),
+			UserAID:   aid,
+			KeyEvent:  event,
+			CachedAt:  time.Now().UTC().Format(time.RFC3339),
+			Witnesses: witnesses,
+			Witnessed: witnessed[i],
+		}
+		if i < len(timestamps) {
+			record.Timestamp = timestamps[i]
 		}
 
 		recordJSON, err := json.Marshal(record)
@@ -315,18 +527,141 @@ func (h *SyncHandler) HandleSyncKEL(w http.ResponseWriter, r *http.Request) {
 		if err := kelCollection.UpsertOne(ctx, doc); err != nil {
 			continue
 		}
-		eventsStored++
+		synced = append(synced, SyncedKELEvent{
+			Sequence:  event.Sequence,
+			Digest:    event.Digest,
+			Witnessed: witnessed[i],
+		})
 	}
+	return synced, nil
+}
 
-	writeJSON(w, http.StatusOK, SyncKELResponse{
-		Success:      true,
-		EventsStored: eventsStored,
-		PrivateSpace: privateSpace.SpaceID,
-	})
+// CacheKEL implements keri.KELCache, storing KELs resolved from OOBIs in the
+// KEL cache alongside synced ones.
+func (h *SyncHandler) CacheKEL(ctx context.Context, aid string, events []keri.KeyEvent) error {
+	_, err := h.storeKEL(ctx, aid, events, nil)
+	return err
+}
+
+// issuerVerifiable reports whether a credential verifies against its
+// issuer's KEL (see kelVerified), resolving the KEL from the OOBI given for
+// the issuer when it isn't cached. resolved memoises, per issuer, whether its
+// KEL is available, so each issuer is resolved at most once.
+func (h *SyncHandler) issuerVerifiable(ctx context.Context, cred *keri.Credential, oobis map[string]string, resolved map[string]bool) bool {
+	available, ok := resolved[cred.Issuer]
+	if !ok {
+		available = h.resolveIssuer(ctx, cred.Issuer, oobis)
+		resolved[cred.Issuer] = available
+	}
+	return available && h.kelVerified(ctx, cred)
+}
+
+// resolveIssuer reports whether issuer's KEL is in the KEL cache, resolving
+// it from the OOBI given for it if it isn't.
+func (h *SyncHandler) resolveIssuer(ctx context.Context, issuer string, oobis map[string]string) bool {
+	if h.storedKELRecord(ctx, issuer, 0) != nil {
+		return true
+	}
+	oobiURL := oobis[issuer]
+	if oobiURL == "" {
+		return false
+	}
+	if _, err := h.keriClient.ResolveOOBI(ctx, issuer, oobiURL); err != nil {
+		log.Printf("[Sync] Failed to resolve OOBI for issuer %s: %v", issuer, err)
+		return false
+	}
+	return true
+}
+
+// storedKELRecord returns a previously synced KEL event for aid, or nil if it
+// isn't cached.
+func (h *SyncHandler) storedKELRecord(ctx context.Context, aid string, sequence int) *kelRecord {
+	coll, err := h.store.KELCache(ctx)
+	if err != nil {
+		return nil
+	}
+	doc, err := coll.FindId(ctx, fmt.Sprintf("%s-%d", aid, sequence))
+	if err != nil {
+		return nil
+	}
+	var rec kelRecord
+	if err := json.Unmarshal([]byte(doc.Value().String()), &rec); err != nil {
+		return nil
+	}
+	return &rec
+}
+
+// mergeReceipts adds stored receipts not already present in received
+func mergeReceipts(received, stored []keri.Receipt) []keri.Receipt {
+	for _, r := range stored {
+		if !slices.Contains(received, r) {
+			received = append(received, r)
+		}
+	}
+	return received
+}
+
+// storedKELRecords returns the cached KEL for aid in sequence order
+func (h *SyncHandler) storedKELRecords(ctx context.Context, aid string) []kelRecord {
+	coll, err := h.store.KELCache(ctx)
+	if err != nil {
+		return nil
+	}
+	filter := anystore.MustParseJSON(fmt.Sprintf(`{"userAid": %q}`, aid))
+	iter, err := coll.Find(filter).Iter(ctx)
+	if err != nil {
+		return nil
+	}
+	defer iter.Close()
+
+	var records []kelRecord
+	for iter.Next() {
+		doc, err := iter.Doc()
+		if err != nil {
+			continue
+		}
+		var rec kelRecord
+		if err := json.Unmarshal([]byte(doc.Value().String()), &rec); err != nil {
+			continue
+		}
+		records = append(records, rec)
+	}
+	slices.SortFunc(records, func(a, b kelRecord) int { return cmp.Compare(a.Sequence, b.Sequence) })
+	return records
+}
+
+// kelVerified checks a credential against its issuer's cached KEL. It fails
+// when the credential is unsigned, when the issuer has no cached KEL, when the
+// KEL anchors the credential in an event that isn't witnessed, or when the
+// signature doesn't verify against the issuer's keys as of its anchoring
+// event (following rotations).
+func (h *SyncHandler) kelVerified(ctx context.Context, cred *keri.Credential) bool {
+	if cred.Signature == "" {
+		return false
+	}
+	records := h.storedKELRecords(ctx, cred.Issuer)
+	if len(records) == 0 {
+		return false
+	}
+
+	events := make([]keri.KeyEvent, len(records))
+	for i, rec := range records {
+		events[i] = rec.KeyEvent
+		if slices.Contains(keri.EventAnchors(&rec.KeyEvent), cred.SAID) && !rec.Witnessed {
+			return false
+		}
+	}
+
+	if err := keri.VerifyCredentialSignature(cred, events); err != nil {
+		log.Printf("[Sync] Credential %s signature invalid: %v", cred.SAID, err)
+		return false
+	}
+	return true
 }
 
 // HandleGetCommunityMembers handles GET /api/v1/community/members
-// Returns all members with community-visible membership credentials.
+// Returns all members with community-visible membership credentials,
+// paginated via ?limit=N&offset=M; without a limit all members are returned.
 // Tries AnySync community space ObjectTree first (P2P synced data),
 // falls back to anystore cache if tree is not available.
 func (h *SyncHandler) HandleGetCommunityMembers(w http.ResponseWriter, r *http.Request) {
@@ -336,6 +671,14 @@ func (h *SyncHandler) HandleGetCommunityMembers(w http.ResponseWriter, r *http.R
 		})
 		return
 	}
+	limit, ok := parseListLimit(w, r, 0, maxListPageSize)
+	if !ok {
+		return
+	}
+	offset, ok := parseListOffset(w, r)
+	if !ok {
+		return
+	}
 
 	ctx := context.Background()
 	members := []CommunityMember{}
@@ -362,10 +705,7 @@ func (h *SyncHandler) HandleGetCommunityMembers(w http.ResponseWriter, r *http.R
 						CredentialSAID: cred.SAID,
 					})
 				}
-				writeJSON(w, http.StatusOK, CommunityMembersResponse{
-					Members: members,
-					Total:   len(members),
-				})
+				h.writeMembersPage(ctx, w, members, offset, limit)
 				return
 			}
 		}
@@ -413,12 +753,38 @@ func (h *SyncHandler) HandleGetCommunityMembers(w http.ResponseWriter, r *http.R
 		})
 	}
 
-	writeJSON(w, http.StatusOK, CommunityMembersResponse{
-		Members: members,
-		Total:   len(members),
+	h.writeMembersPage(ctx, w, members, offset, limit)
+}
+
+// writeMembersPage writes one page of members, earliest joined first (ties
+// by AID, so pages are stable), resolving display names for that page only.
+func (h *SyncHandler) writeMembersPage(ctx context.Context, w http.ResponseWriter, members []CommunityMember, offset, limit int) {
+	slices.SortFunc(members, func(a, b CommunityMember) int {
+		return cmp.Or(cmp.Compare(a.JoinedAt, b.JoinedAt), cmp.Compare(a.AID, b.AID))
+	})
+	members, page := offsetPage(members, offset, limit)
+	writeListJSON(w, page, CommunityMembersResponse{
+		Members:  h.withDisplayNames(ctx, members),
+		ListPage: page,
 	})
 }
 
+// withDisplayNames fills in each member's display name and avatar from their
+// SharedProfile.
+func (h *SyncHandler) withDisplayNames(ctx context.Context, members []CommunityMember) []CommunityMember {
+	aids := make([]string, len(members))
+	for i, m := range members {
+		aids[i] = m.AID
+	}
+	profiles := h.profiles.ResolveMany(ctx, aids)
+	for i := range members {
+		profile := profiles[members[i].AID]
+		members[i].DisplayName = profile.DisplayName
+		members[i].Avatar = profile.Avatar
+	}
+	return members
+}
+
 // HandleGetCommunityCredentials handles GET /api/v1/community/credentials
 // Returns all community-visible credentials (memberships, roles).
 // Tries AnySync community space ObjectTree first (P2P synced data),
@@ -524,10 +890,12 @@ func (h *SyncHandler) HandleGetCommunityCredentials(w http.ResponseWriter, r *ht
 }
 
 // RegisterRoutes registers sync routes on the mux
-func (h *SyncHandler) RegisterRoutes(mux *http.ServeMux) {
+func (h *SyncHandler) RegisterRoutes(mux *http.ServeMux, roleLookup RoleLookup) {
 	// Sync endpoints
 	mux.HandleFunc("/api/v1/sync/credentials", h.HandleSyncCredentials)
 	mux.HandleFunc("/api/v1/sync/kel", h.HandleSyncKEL)
+	mux.HandleFunc("/api/v1/sync/batch", h.HandleSyncBatch)
+	mux.HandleFunc("/api/v1/credentials/reverify", RBACMiddleware(roleLookup, requireCommunityAdmin(h.HandleReverify)))
 
 	// Community endpoints
 	mux.HandleFunc("/api/v1/community/members", h.HandleGetCommunityMembers)
@@ -0,0 +1,236 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/matou-dao/backend/internal/anystore"
+	"github.com/matou-dao/backend/internal/keri"
+)
+
+// Item kinds in a batch sync
+const (
+	SyncItemKEL        = "kel"
+	SyncItemCredential = "credential"
+	SyncItemProfile    = "profile"
+)
+
+// ProfileWriter writes profile objects for the batch sync endpoint
+// (ProfilesHandler implements it).
+type ProfileWriter interface {
+	WriteProfile(ctx context.Context, req CreateProfileRequest) (*ProfileWriteResult, error)
+}
+
+// SyncBatchRequest carries KEL events, credentials and profile updates in one
+// request. UserAID is optional in per-user mode (falls back to userIdentity).
+type SyncBatchRequest struct {
+	UserAID     string                 `json:"userAid,omitempty"`
+	KEL         []KELEvent             `json:"kel,omitempty"`
+	Credentials []keri.Credential      `json:"credentials,omitempty"`
+	OOBIs       map[string]string      `json:"oobis,omitempty"` // as in SyncCredentialsRequest
+	Profiles    []CreateProfileRequest `json:"profiles,omitempty"`
+}
+
+// SyncBatchItemResult reports the outcome of one item in a batch.
+type SyncBatchItemResult struct {
+	Kind    string `json:"kind"`         // kel, credential or profile
+	Index   int    `json:"index"`        // position in the request's list of that kind
+	ID      string `json:"id,omitempty"` // event digest, credential SAID or profile object ID
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+	// Warning is set when the item was stored but a follow-up step failed,
+	// e.g. routing a cached credential to its spaces
+	Warning   string              `json:"warning,omitempty"`
+	Witnessed *bool               `json:"witnessed,omitempty"` // kel items
+	Spaces    []string            `json:"spaces,omitempty"`    // credential items
+	Profile   *ProfileWriteResult `json:"profile,omitempty"`   // profile items
+}
+
+// SyncBatchResponse is the response for POST /api/v1/sync/batch. Results are
+// in processing order: KEL events, then credentials, then profiles.
+type SyncBatchResponse struct {
+	Success      bool                  `json:"success"` // every item succeeded
+	Succeeded    int                   `json:"succeeded"`
+	Failed       int                   `json:"failed"`
+	PrivateSpace string                `json:"privateSpace,omitempty"`
+	Results      []SyncBatchItemResult `json:"results"`
+	Error        string                `json:"error,omitempty"`
+}
+
+// HandleSyncBatch handles POST /api/v1/sync/batch.
+// Processes KEL events before the credentials that anchor in them, then
+// profile updates. Each item succeeds or fails on its own: a bad credential
+// doesn't stop the rest of the batch, and a KEL event that fails verification
+// rejects only itself and the events after it.
+func (h *SyncHandler) HandleSyncBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, SyncBatchResponse{
+			Success: false,
+			Error:   "Method not allowed",
+		})
+		return
+	}
+
+	var req SyncBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, SyncBatchResponse{
+			Success: false,
+			Error:   fmt.Sprintf("invalid request: %v", err),
+		})
+		return
+	}
+
+	userAID := requestedAID(r, req.UserAID, h.userIdentity)
+	if userAID == "" {
+		writeJSON(w, http.StatusConflict, SyncBatchResponse{
+			Success: false,
+			Error:   "identity not configured — call POST /api/v1/identity/set first",
+		})
+		return
+	}
+
+	total := len(req.KEL) + len(req.Credentials) + len(req.Profiles)
+	if total == 0 {
+		writeJSON(w, http.StatusBadRequest, SyncBatchResponse{
+			Success: false,
+			Error:   "batch is empty",
+		})
+		return
+	}
+
+	ctx := context.Background()
+	resp := SyncBatchResponse{Results: make([]SyncBatchItemResult, 0, total)}
+
+	privateSpace, err := h.spaceManager.GetOrCreatePrivateSpace(ctx, userAID, h.spaceStore)
+	if err != nil {
+		log.Printf("[Sync] Batch: failed to get/create private space for %s: %v", userAID, err)
+	} else {
+		resp.PrivateSpace = privateSpace.SpaceID
+	}
+
+	if len(req.KEL) > 0 {
+		resp.Results = append(resp.Results, h.syncBatchKEL(ctx, userAID, req.KEL)...)
+	}
+
+	issuers := make(map[string]bool)
+	stored := make([]*anystore.CachedCredential, 0, len(req.Credentials))
+	for i := range req.Credentials {
+		cred := &req.Credentials[i]
+		item := SyncBatchItemResult{Kind: SyncItemCredential, Index: i, ID: cred.SAID}
+		result, err := h.syncCredential(ctx, cred, req.OOBIs, issuers)
+		if err != nil {
+			item.Error = err.Error()
+		} else {
+			item.Success = true
+			item.Spaces = result.spaces
+			if result.routeErr != nil {
+				item.Warning = result.routeErr.Error()
+			}
+			stored = append(stored, result.cached)
+		}
+		resp.Results = append(resp.Results, item)
+	}
+	h.applyStoredCredentials(ctx, stored)
+	h.reverifyResolvedIssuers(ctx, issuers)
+
+	for i, profileReq := range req.Profiles {
+		item := SyncBatchItemResult{Kind: SyncItemProfile, Index: i, ID: profileReq.ID}
+		if h.profileWriter == nil {
+			item.Error = "profile updates are not supported on this node"
+		} else if result, err := h.profileWriter.WriteProfile(r.Context(), profileReq); err != nil {
+			item.Error = err.Error()
+		} else {
+			item.Success = true
+			item.ID = result.ObjectID
+			item.Profile = result
+		}
+		resp.Results = append(resp.Results, item)
+	}
+
+	for _, item := range resp.Results {
+		if item.Success {
+			resp.Succeeded++
+		} else {
+			resp.Failed++
+		}
+	}
+	resp.Success = resp.Failed == 0
+
+	status := http.StatusOK
+	if resp.Succeeded == 0 {
+		status = http.StatusBadRequest
+	}
+	writeJSON(w, status, resp)
+}
+
+// syncBatchKEL verifies and stores the KEL events of a batch. Events up to
+// the first one that fails verification are stored; that event and every
+// event after it are rejected, since they can't chain from a rejected event.
+func (h *SyncHandler) syncBatchKEL(ctx context.Context, aid string, kel []KELEvent) []SyncBatchItemResult {
+	results := make([]SyncBatchItemResult, len(kel))
+	events := make([]keri.KeyEvent, len(kel))
+	timestamps := make([]string, len(kel))
+	for i, e := range kel {
+		results[i] = SyncBatchItemResult{Kind: SyncItemKEL, Index: i, ID: e.Digest}
+		events[i] = e.keyEvent()
+		timestamps[i] = e.Timestamp
+	}
+
+	valid := len(events)
+	reject := func(err error) {
+		var kelErr *keri.KELError
+		if errors.As(err, &kelErr) {
+			valid = kelErr.Index
+		} else {
+			valid = 0
+		}
+		results[valid].Error = err.Error()
+		for i := valid + 1; i < len(results); i++ {
+			results[i].Error = fmt.Sprintf("follows rejected event at sequence %d", events[valid].Sequence)
+		}
+	}
+	if err := keri.VerifyKEL(aid, events, h.priorKEL(ctx, aid, events[0].Sequence)); err != nil {
+		reject(err)
+	}
+	if valid == 0 {
+		return results
+	}
+
+	synced, err := h.storeKEL(ctx, aid, events[:valid], timestamps[:valid])
+	var kelErr *keri.KELError
+	if errors.As(err, &kelErr) {
+		// Store the events before the duplicitous one
+		reject(err)
+		if valid == 0 {
+			return results
+		}
+		synced, err = h.storeKEL(ctx, aid, events[:valid], timestamps[:valid])
+	}
+	if err != nil {
+		for i := 0; i < valid; i++ {
+			results[i].Error = fmt.Sprintf("failed to get KEL collection: %v", err)
+		}
+		return results
+	}
+	stored := make(map[int]bool, len(synced))
+	for _, event := range synced {
+		stored[event.Sequence] = true
+		witnessed := event.Witnessed
+		for i := 0; i < valid; i++ {
+			if events[i].Sequence == event.Sequence {
+				results[i].Success = true
+				results[i].Witnessed = &witnessed
+			}
+		}
+	}
+	for i := 0; i < valid; i++ {
+		if !stored[events[i].Sequence] {
+			results[i].Error = "failed to store event"
+		}
+	}
+	return results
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// SetEventBroker enables "sync_stalled" and "sync_resumed" SSE events.
+func (h *SpacesHandler) SetEventBroker(broker *EventBroker) {
+	h.eventBroker = broker
+}
+
+// SetSyncStallThreshold sets how long sync may make no progress, while local
+// changes wait for a peer to acknowledge them, before a space is reported as
+// stalled.
+func (h *SpacesHandler) SetSyncStallThreshold(d time.Duration) {
+	h.syncStallThreshold = d
+}
+
+// StartSyncMonitor begins a background loop that samples the community
+// spaces' sync counters every interval (see RecordSyncMetrics).
+func (h *SpacesHandler) StartSyncMonitor(interval time.Duration) {
+	h.loop.start(interval, func(_ context.Context, now time.Time) {
+		h.RecordSyncMetrics(now)
+	})
+	log.Printf("[SyncStatus] Started sync monitor (interval=%s, stall threshold=%s)", interval, h.syncStallThreshold)
+}
+
+// StopSyncMonitor stops the loop started by StartSyncMonitor.
+func (h *SpacesHandler) StopSyncMonitor() {
+	h.loop.stop()
+}
+
+// RecordSyncMetrics adds a sample to the sync history of the community and
+// readonly spaces, and logs and broadcasts "sync_stalled" when a space stalls
+// and "sync_resumed" when it makes progress again.
+func (h *SpacesHandler) RecordSyncMetrics(now time.Time) {
+	treeMgr := h.spaceManager.TreeManager()
+	if treeMgr == nil {
+		return
+	}
+
+	for _, spaceID := range []string{h.spaceManager.GetCommunitySpaceID(), h.spaceManager.GetCommunityReadOnlySpaceID()} {
+		if spaceID == "" {
+			continue
+		}
+		ss := treeMgr.GetSyncStatus(spaceID)
+		if ss == nil {
+			continue
+		}
+		stalled, transitioned := ss.Record(now, h.syncStallThreshold)
+		if !transitioned {
+			continue
+		}
+
+		eventType := "sync_resumed"
+		if stalled {
+			eventType = "sync_stalled"
+		}
+		changed, received, applied := ss.GetStatus()
+		pending := ss.Pending()
+		log.Printf("[SyncStatus] %s: space=%s treesChanged=%d treesPending=%d headsReceived=%d headsApplied=%d",
+			eventType, spaceID, changed, pending, received, applied)
+
+		if h.eventBroker != nil {
+			h.eventBroker.Broadcast(SSEEvent{
+				Type: eventType,
+				Data: map[string]interface{}{
+					"spaceId":       spaceID,
+					"stalled":       stalled,
+					"treesChanged":  changed,
+					"treesPending":  pending,
+					"headsReceived": received,
+					"headsApplied":  applied,
+				},
+			})
+		}
+	}
+}
@@ -3,11 +3,14 @@ package api
 import (
 	"bytes"
 	"context"
+	"crypto/ed25519"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -83,6 +86,10 @@ func (m *mockSyncAnySyncClient) CreateSpaceWithKeys(ctx context.Context, ownerAI
 	return m.CreateSpace(ctx, ownerAID, spaceType, nil)
 }
 
+func (m *mockSyncAnySyncClient) DeriveSpaceWithKeys(ctx context.Context, ownerAID string, spaceType string, keys *anysync.SpaceKeySet) (*anysync.SpaceCreateResult, error) {
+	return m.CreateSpace(ctx, ownerAID, spaceType, nil)
+}
+
 func (m *mockSyncAnySyncClient) GetSpace(ctx context.Context, spaceID string) (commonspace.Space, error) {
 	return nil, fmt.Errorf("mock: GetSpace not supported")
 }
@@ -100,9 +107,10 @@ func setupSyncTestHandler(t *testing.T) (*SyncHandler, *anystore.LocalStore, fun
 
 	// Create KERI client
 	keriClient, err := keri.NewClient(&keri.Config{
-		OrgAID:   "EAID123456789",
-		OrgAlias: "test-org",
-		OrgName:  "Test Organization",
+		OrgAID:    "EAID123456789",
+		OrgAlias:  "test-org",
+		OrgName:   "Test Organization",
+		OOBIHosts: []string{"127.0.0.1"},
 	})
 	if err != nil {
 		os.RemoveAll(tmpDir)
@@ -133,7 +141,9 @@ func setupSyncTestHandler(t *testing.T) (*SyncHandler, *anystore.LocalStore, fun
 		os.RemoveAll(tmpDir)
 	}
 
-	return NewSyncHandler(keriClient, store, spaceManager, spaceStore, nil), store, cleanup
+	handler := NewSyncHandler(keriClient, store, spaceManager, spaceStore, nil)
+	keriClient.SetKELCache(handler)
+	return handler, store, cleanup
 }
 
 // ============================================
@@ -382,37 +392,124 @@ func TestHandleSyncCredentials_MethodNotAllowed(t *testing.T) {
 // HandleSyncKEL Tests
 // ============================================
 
-func TestHandleSyncKEL_ValidKEL(t *testing.T) {
-	handler, _, cleanup := setupSyncTestHandler(t)
-	defer cleanup()
+// testSAIDPlaceholder stands in for an event's SAID while it is computed.
+var testSAIDPlaceholder = strings.Repeat("#", 44)
+
+// testKEL builds a KEL for a self-addressing AID, serialized as KERIA does
+// with computed SAIDs. Events are signed by testKey(seed) for the current
+// seed; each establishment event commits to the next seed's key and each
+// rotation moves to it.
+type testKEL struct {
+	aid  string
+	seed byte
+	last KELEvent
+}
 
-	body := `{
-		"userAid": "EUSER123",
-		"kel": [
-			{
-				"type": "icp",
-				"sequence": 0,
-				"digest": "EDIGEST001",
-				"data": {"keys": ["key1"]},
-				"timestamp": "2026-01-19T00:00:00Z"
-			}
-		]
-	}`
-	req := httptest.NewRequest(http.MethodPost, "/api/v1/sync/kel", bytes.NewBufferString(body))
+// newTestKEL incepts a KEL signed by testKey(seed) that designates
+// witnesses, returning the builder and the inception event.
+func newTestKEL(seed byte, witnesses ...string) (*testKEL, KELEvent) {
+	k := &testKEL{seed: seed}
+	b, _ := json.Marshal(append([]string{}, witnesses...))
+	icp := k.build("icp", 0, "", fmt.Sprintf(`,"kt":"1","k":[%q],"nt":"1","n":[%q],"bt":"%x","b":%s,"a":[]`,
+		k.verKey(), k.nextDigest(), min(len(witnesses), 1), b))
+	k.aid, k.last = icp.Digest, icp
+	return k, icp
+}
+
+// verKey returns the current signing key, CESR encoded.
+func (k *testKEL) verKey() string {
+	return keri.EncodeVerKey(testKey(k.seed).Public().(ed25519.PublicKey))
+}
+
+// nextDigest returns the commitment to the next signing key, testKey(seed+1).
+func (k *testKEL) nextDigest() string {
+	return keri.Digest([]byte(keri.EncodeVerKey(testKey(k.seed + 1).Public().(ed25519.PublicKey))))
+}
+
+// rotate returns a rotation to testKey(seed+1).
+func (k *testKEL) rotate() KELEvent {
+	k.seed++
+	rot := k.build("rot", k.last.Sequence+1, k.last.Digest, fmt.Sprintf(`,"kt":"1","k":[%q],"nt":"1","n":[%q],"br":[],"ba":[],"a":[]`,
+		k.verKey(), k.nextDigest()))
+	k.last = rot
+	return rot
+}
+
+// interact returns an interaction anchoring saids.
+func (k *testKEL) interact(saids ...string) KELEvent {
+	ixn := k.build("ixn", k.last.Sequence+1, k.last.Digest, anchorSeals(saids))
+	k.last = ixn
+	return ixn
+}
+
+// build serializes and signs an event without advancing the log, e.g. to
+// build one that doesn't chain. fields holds the fields after "p".
+func (k *testKEL) build(typ string, seq int, prior, fields string) KELEvent {
+	prefix := k.aid
+	if typ == "icp" {
+		prefix = testSAIDPlaceholder
+	}
+	raw := fmt.Sprintf(`{"v":"KERI10JSON000000_","t":%q,"d":%q,"i":%q,"s":"%x","p":%q%s}`,
+		typ, testSAIDPlaceholder, prefix, seq, prior, fields)
+	said := keri.Digest([]byte(raw))
+	raw = strings.ReplaceAll(raw, testSAIDPlaceholder, said)
+	return KELEvent{
+		Type:       typ,
+		Sequence:   seq,
+		Digest:     said,
+		Prior:      prior,
+		Raw:        raw,
+		Signatures: []string{keri.EncodeIndexedSignature(0, ed25519.Sign(testKey(k.seed), []byte(raw)))},
+		Timestamp:  "2026-01-19T00:00:00Z",
+	}
+}
+
+// anchorSeals returns the "a" field of an event anchoring saids.
+func anchorSeals(saids []string) string {
+	seals := make([]map[string]string, len(saids))
+	for i, said := range saids {
+		seals[i] = map[string]string{"d": said}
+	}
+	a, _ := json.Marshal(seals)
+	return fmt.Sprintf(`,"a":%s`, a)
+}
+
+// keyEvents converts synced events to KERI key events, as served by an OOBI.
+func keyEvents(events ...KELEvent) []keri.KeyEvent {
+	out := make([]keri.KeyEvent, len(events))
+	for i, e := range events {
+		out[i] = e.keyEvent()
+	}
+	return out
+}
+
+// postKELFor sends a KEL sync request for aid.
+func postKELFor(t *testing.T, handler *SyncHandler, aid string, events ...KELEvent) (*httptest.ResponseRecorder, SyncKELResponse) {
+	t.Helper()
+	body, _ := json.Marshal(SyncKELRequest{UserAID: aid, KEL: events})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sync/kel", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
 	handler.HandleSyncKEL(w, req)
 
-	if w.Code != http.StatusOK {
-		t.Errorf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
-	}
-
 	var resp SyncKELResponse
-	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+	if err := json.NewDecoder(bytes.NewReader(w.Body.Bytes())).Decode(&resp); err != nil {
 		t.Fatalf("failed to decode response: %v", err)
 	}
+	return w, resp
+}
+
+func TestHandleSyncKEL_ValidKEL(t *testing.T) {
+	handler, _, cleanup := setupSyncTestHandler(t)
+	defer cleanup()
 
+	kel, icp := newTestKEL(1)
+	w, resp := postKELFor(t, handler, kel.aid, icp)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
 	if !resp.Success {
 		t.Errorf("expected success, got error: %s", resp.Error)
 	}
@@ -428,49 +525,469 @@ func TestHandleSyncKEL_MultipleEvents(t *testing.T) {
 	handler, _, cleanup := setupSyncTestHandler(t)
 	defer cleanup()
 
-	body := `{
-		"userAid": "EUSER123",
-		"kel": [
-			{
-				"type": "icp",
-				"sequence": 0,
-				"digest": "EDIGEST001",
-				"data": {"keys": ["key1"]},
-				"timestamp": "2026-01-19T00:00:00Z"
-			},
-			{
-				"type": "rot",
-				"sequence": 1,
-				"digest": "EDIGEST002",
-				"data": {"keys": ["key2"]},
-				"timestamp": "2026-01-19T01:00:00Z"
-			},
-			{
-				"type": "ixn",
-				"sequence": 2,
-				"digest": "EDIGEST003",
-				"data": {"anchor": "cred123"},
-				"timestamp": "2026-01-19T02:00:00Z"
-			}
-		]
-	}`
-	req := httptest.NewRequest(http.MethodPost, "/api/v1/sync/kel", bytes.NewBufferString(body))
-	req.Header.Set("Content-Type", "application/json")
+	kel, icp := newTestKEL(1)
+	rot := kel.rotate()
+	ixn := kel.interact("cred123")
+
+	w, resp := postKELFor(t, handler, kel.aid, icp, rot, ixn)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if resp.EventsStored != 3 {
+		t.Errorf("expected 3 events stored, got %d", resp.EventsStored)
+	}
+}
+
+func TestHandleSyncKEL_SequenceGap(t *testing.T) {
+	handler, _, cleanup := setupSyncTestHandler(t)
+	defer cleanup()
+
+	kel, icp := newTestKEL(1)
+	ixn := kel.build("ixn", 2, icp.Digest, anchorSeals(nil))
+
+	w, resp := postKELFor(t, handler, kel.aid, icp, ixn)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+	if resp.InvalidEvent == nil || resp.InvalidEvent.Index != 1 || resp.InvalidEvent.Sequence != 2 {
+		t.Errorf("expected invalid event at index 1 (sequence 2), got %+v", resp.InvalidEvent)
+	}
+	if resp.EventsStored != 0 {
+		t.Errorf("expected nothing stored, got %d", resp.EventsStored)
+	}
+}
+
+func TestHandleSyncKEL_BrokenDigestChain(t *testing.T) {
+	handler, _, cleanup := setupSyncTestHandler(t)
+	defer cleanup()
+
+	kel, icp := newTestKEL(1)
+	kel.seed++
+	rot := kel.build("rot", 1, "EDIGEST999", fmt.Sprintf(`,"kt":"1","k":[%q]`, kel.verKey()))
+
+	w, resp := postKELFor(t, handler, kel.aid, icp, rot)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+	if resp.InvalidEvent == nil || resp.InvalidEvent.Index != 1 || resp.InvalidEvent.Digest != rot.Digest {
+		t.Errorf("expected invalid event %s at index 1, got %+v", rot.Digest, resp.InvalidEvent)
+	}
+}
+
+func TestHandleSyncKEL_UnsignedInception(t *testing.T) {
+	handler, _, cleanup := setupSyncTestHandler(t)
+	defer cleanup()
+
+	kel, icp := newTestKEL(1)
+	icp.Signatures = nil
+
+	w, resp := postKELFor(t, handler, kel.aid, icp)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+	if resp.InvalidEvent == nil || resp.InvalidEvent.Index != 0 {
+		t.Errorf("expected invalid event at index 0, got %+v", resp.InvalidEvent)
+	}
+}
+
+func TestHandleSyncKEL_RotationToUncommittedKey(t *testing.T) {
+	handler, _, cleanup := setupSyncTestHandler(t)
+	defer cleanup()
+
+	// Whoever holds only the current key can't rotate to a key of their choosing
+	kel, icp := newTestKEL(1)
+	kel.seed = 7
+	rot := kel.build("rot", 1, icp.Digest, fmt.Sprintf(`,"kt":"1","k":[%q],"nt":"1","n":[%q]`, kel.verKey(), kel.nextDigest()))
+
+	w, resp := postKELFor(t, handler, kel.aid, icp, rot)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+	if resp.InvalidEvent == nil || resp.InvalidEvent.Index != 1 {
+		t.Errorf("expected invalid event at index 1, got %+v", resp.InvalidEvent)
+	}
+}
+
+func TestHandleSyncKEL_KELForAnotherAID(t *testing.T) {
+	handler, store, cleanup := setupSyncTestHandler(t)
+	defer cleanup()
+
+	// A valid KEL can't be filed under an AID it doesn't establish
+	_, icp := newTestKEL(1)
+	w, resp := postKELFor(t, handler, "EAID123456789", icp)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+	if resp.InvalidEvent == nil || resp.InvalidEvent.Index != 0 {
+		t.Errorf("expected invalid event at index 0, got %+v", resp.InvalidEvent)
+	}
+	if count, _ := store.CountKELEvents(context.Background()); count != 0 {
+		t.Errorf("expected nothing stored, got %d events", count)
+	}
+}
+
+func TestHandleSyncKEL_ContinuesStoredKEL(t *testing.T) {
+	handler, _, cleanup := setupSyncTestHandler(t)
+	defer cleanup()
+
+	kel, icp := newTestKEL(1)
+	if w, _ := postKELFor(t, handler, kel.aid, icp); w.Code != http.StatusOK {
+		t.Fatalf("inception sync failed: %s", w.Body.String())
+	}
+
+	// A later sync carrying only new events chains onto the stored inception
+	ixn := kel.interact()
+	if w, resp := postKELFor(t, handler, kel.aid, ixn); w.Code != http.StatusOK {
+		t.Errorf("expected continuation to be accepted, got %d: %s", w.Code, resp.Error)
+	}
+
+	bad := kel.build("ixn", 2, "EWRONG", anchorSeals(nil))
+	if w, _ := postKELFor(t, handler, kel.aid, bad); w.Code != http.StatusBadRequest {
+		t.Errorf("expected continuation with wrong prior to be rejected, got %d", w.Code)
+	}
+}
+
+func TestHandleSyncKEL_RejectsDuplicity(t *testing.T) {
+	handler, _, cleanup := setupSyncTestHandler(t)
+	defer cleanup()
+
+	kel, icp := newTestKEL(1)
+	ixn := kel.interact("ESAID001")
+	if w, _ := postKELFor(t, handler, kel.aid, icp, ixn); w.Code != http.StatusOK {
+		t.Fatalf("KEL sync failed: %s", w.Body.String())
+	}
+
+	// A second, validly signed event at sequence 1 is duplicitous
+	fork := kel.build("ixn", 1, icp.Digest, anchorSeals([]string{"ESAID002"}))
+	w, resp := postKELFor(t, handler, kel.aid, fork)
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected status %d, got %d: %s", http.StatusConflict, w.Code, w.Body.String())
+	}
+	if resp.InvalidEvent == nil || resp.InvalidEvent.Sequence != 1 {
+		t.Errorf("expected the fork at sequence 1 to be reported, got %+v", resp.InvalidEvent)
+	}
+	if rec := handler.storedKELRecord(context.Background(), kel.aid, 1); rec == nil || rec.Digest != ixn.Digest {
+		t.Errorf("expected the first-seen event to be kept, got %+v", rec)
+	}
+
+	// Resubmitting the stored event is fine
+	if w, _ := postKELFor(t, handler, kel.aid, ixn); w.Code != http.StatusOK {
+		t.Errorf("expected resubmission to be accepted, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// testKey returns a deterministic Ed25519 key for seed.
+func testKey(seed byte) ed25519.PrivateKey {
+	keySeed := make([]byte, ed25519.SeedSize)
+	keySeed[0] = seed
+	return ed25519.NewKeyFromSeed(keySeed)
+}
+
+// testWitness returns a deterministic witness key and its AID.
+func testWitness(seed byte) (ed25519.PrivateKey, string) {
+	key := testKey(seed)
+	return key, keri.EncodeWitnessAID(key.Public().(ed25519.PublicKey))
+}
+
+// witnessReceipt signs event's raw serialization with witness.
+func witnessReceipt(event KELEvent, witness ed25519.PrivateKey) keri.Receipt {
+	return keri.Receipt{
+		Witness:   keri.EncodeWitnessAID(witness.Public().(ed25519.PublicKey)),
+		Signature: keri.EncodeSignature(ed25519.Sign(witness, []byte(event.Raw))),
+	}
+}
+
+// witnessedKEL returns a KEL whose inception designates witnessAID, followed
+// by an interaction event anchoring said, without receipts.
+func witnessedKEL(witnessAID, said string) (*testKEL, KELEvent, KELEvent) {
+	kel, icp := newTestKEL(1, witnessAID)
+	return kel, icp, kel.interact(said)
+}
+
+func TestHandleSyncKEL_WitnessThreshold(t *testing.T) {
+	handler, _, cleanup := setupSyncTestHandler(t)
+	defer cleanup()
+
+	witness, witnessAID := testWitness(9)
+	kel, icp, ixn := witnessedKEL(witnessAID, "ESAID001")
+	icp.Receipts = []keri.Receipt{witnessReceipt(icp, witness)}
+
+	w, resp := postKELFor(t, handler, kel.aid, icp, ixn)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if len(resp.Events) != 2 {
+		t.Fatalf("expected 2 synced events, got %d", len(resp.Events))
+	}
+	if !resp.Events[0].Witnessed {
+		t.Error("expected inception with a valid receipt to be witnessed")
+	}
+	if resp.Events[1].Witnessed {
+		t.Error("expected interaction without receipts to be unwitnessed")
+	}
+
+	// A receipt arriving in a later sync is collected with the stored event
+	ixn.Receipts = []keri.Receipt{witnessReceipt(ixn, witness)}
+	_, resp = postKELFor(t, handler, kel.aid, ixn)
+	if len(resp.Events) != 1 || !resp.Events[0].Witnessed {
+		t.Errorf("expected late receipt to witness stored event, got %+v", resp.Events)
+	}
+}
+
+func TestHandleSyncKEL_WitnessThresholdNotMet(t *testing.T) {
+	handler, _, cleanup := setupSyncTestHandler(t)
+	defer cleanup()
+
+	witness, witnessAID := testWitness(9)
+	_, otherAID := testWitness(10)
+	kel, icp := newTestKEL(1, witnessAID, otherAID)
+	icp.Receipts = []keri.Receipt{witnessReceipt(icp, witness)}
+
+	handler.keriClient, _ = keri.NewClient(&keri.Config{OrgAID: "EAID123456789", WitnessThreshold: 2})
+	_, resp := postKELFor(t, handler, kel.aid, icp)
+	if len(resp.Events) != 1 || resp.Events[0].Witnessed {
+		t.Errorf("expected inception with 1 of 2 receipts to be unwitnessed, got %+v", resp.Events)
+	}
+}
+
+func TestHandleSyncCredentials_UnwitnessedAnchorNotVerified(t *testing.T) {
+	handler, store, cleanup := setupSyncTestHandler(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	witness, witnessAID := testWitness(9)
+	kel, icp, ixn := witnessedKEL(witnessAID, "ESAID001")
+	icp.Receipts = []keri.Receipt{witnessReceipt(icp, witness)}
+	anchored := kel.interact("ESAID002")
+	anchored.Receipts = []keri.Receipt{witnessReceipt(anchored, witness)}
+	if w, _ := postKELFor(t, handler, kel.aid, icp, ixn, anchored); w.Code != http.StatusOK {
+		t.Fatalf("KEL sync failed: %s", w.Body.String())
+	}
+
+	body, _ := json.Marshal(SyncCredentialsRequest{
+		UserAID: "EUSER123",
+		Credentials: []keri.Credential{
+			{SAID: "ESAID001", Issuer: kel.aid, Recipient: "EUSER123", Schema: "EMatouMembershipSchemaV1",
+				Data: keri.CredentialData{CommunityName: "MATOU", Role: "Member"}, Signature: signSAID("ESAID001", 1)},
+			{SAID: "ESAID002", Issuer: kel.aid, Recipient: "EUSER456", Schema: "EMatouMembershipSchemaV1",
+				Data: keri.CredentialData{CommunityName: "MATOU", Role: "Member"}, Signature: signSAID("ESAID002", 1)},
+		},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sync/credentials", bytes.NewReader(body))
 	w := httptest.NewRecorder()
+	handler.HandleSyncCredentials(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("credential sync failed: %s", w.Body.String())
+	}
 
-	handler.HandleSyncKEL(w, req)
+	unwitnessed, err := store.GetCredential(ctx, "ESAID001")
+	if err != nil {
+		t.Fatalf("failed to get credential: %v", err)
+	}
+	if unwitnessed.Verified {
+		t.Error("expected credential anchored in an unwitnessed event not to be verified")
+	}
+	witnessed, err := store.GetCredential(ctx, "ESAID002")
+	if err != nil {
+		t.Fatalf("failed to get credential: %v", err)
+	}
+	if !witnessed.Verified {
+		t.Error("expected credential anchored in a witnessed event to be verified")
+	}
+}
 
+func TestHandleSyncCredentials_UnsignedNotVerified(t *testing.T) {
+	handler, store, cleanup := setupSyncTestHandler(t)
+	defer cleanup()
+
+	witness, witnessAID := testWitness(9)
+	kel, icp, ixn := witnessedKEL(witnessAID, "ESAID001")
+	ixn.Receipts = []keri.Receipt{witnessReceipt(ixn, witness)}
+	if w, _ := postKELFor(t, handler, kel.aid, icp, ixn); w.Code != http.StatusOK {
+		t.Fatalf("KEL sync failed: %s", w.Body.String())
+	}
+
+	// Anchored in a witnessed event, but carrying no signature
+	body, _ := json.Marshal(SyncCredentialsRequest{
+		UserAID: "EUSER123",
+		Credentials: []keri.Credential{
+			{SAID: "ESAID001", Issuer: kel.aid, Recipient: "EUSER123", Schema: "EMatouMembershipSchemaV1",
+				Data: keri.CredentialData{CommunityName: "MATOU", Role: "Member"}},
+		},
+	})
+	w := httptest.NewRecorder()
+	handler.HandleSyncCredentials(w, httptest.NewRequest(http.MethodPost, "/api/v1/sync/credentials", bytes.NewReader(body)))
 	if w.Code != http.StatusOK {
-		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+		t.Fatalf("credential sync failed: %s", w.Body.String())
 	}
+	if cred, err := store.GetCredential(context.Background(), "ESAID001"); err != nil || cred.Verified {
+		t.Errorf("expected unsigned credential not to be verified, got %+v (err %v)", cred, err)
+	}
+}
 
-	var resp SyncKELResponse
-	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
-		t.Fatalf("failed to decode response: %v", err)
+func TestHandleSyncCredentials_SignatureFollowsRotation(t *testing.T) {
+	handler, store, cleanup := setupSyncTestHandler(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// The issuer incepts with key 1, rotates to key 2, then anchors both credentials
+	witness, witnessAID := testWitness(9)
+	kel, icp := newTestKEL(1, witnessAID)
+	rot := kel.rotate()
+	ixn := kel.interact("ESAID201", "ESAID202")
+	ixn.Receipts = []keri.Receipt{witnessReceipt(ixn, witness)}
+	if w, _ := postKELFor(t, handler, kel.aid, icp, rot, ixn); w.Code != http.StatusOK {
+		t.Fatalf("KEL sync failed: %s", w.Body.String())
+	}
+
+	body, _ := json.Marshal(SyncCredentialsRequest{
+		UserAID: "EUSER123",
+		Credentials: []keri.Credential{
+			{SAID: "ESAID201", Issuer: kel.aid, Recipient: "EUSER123", Schema: "EMatouMembershipSchemaV1",
+				Data: keri.CredentialData{CommunityName: "MATOU", Role: "Member"}, Signature: signSAID("ESAID201", 2)},
+			{SAID: "ESAID202", Issuer: kel.aid, Recipient: "EUSER456", Schema: "EMatouMembershipSchemaV1",
+				Data: keri.CredentialData{CommunityName: "MATOU", Role: "Member"}, Signature: signSAID("ESAID202", 1)},
+		},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sync/credentials", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.HandleSyncCredentials(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("credential sync failed: %s", w.Body.String())
 	}
 
-	if resp.EventsStored != 3 {
-		t.Errorf("expected 3 events stored, got %d", resp.EventsStored)
+	rotated, err := store.GetCredential(ctx, "ESAID201")
+	if err != nil {
+		t.Fatalf("failed to get credential: %v", err)
+	}
+	if !rotated.Verified {
+		t.Error("expected credential signed with the rotated keys to be verified")
+	}
+	stale, err := store.GetCredential(ctx, "ESAID202")
+	if err != nil {
+		t.Fatalf("failed to get credential: %v", err)
+	}
+	if stale.Verified {
+		t.Error("expected credential signed with the inception keys not to be verified")
+	}
+}
+
+// signSAID returns an indexed signature over said by testKey(seed).
+func signSAID(said string, seed byte) string {
+	return keri.EncodeIndexedSignature(0, ed25519.Sign(testKey(seed), []byte(said)))
+}
+
+// postExternalCredential syncs a membership credential issued by issuer and
+// signed with testKey(5), with optional OOBIs for resolving issuers.
+func postExternalCredential(t *testing.T, handler *SyncHandler, said, issuer string, oobis map[string]string) {
+	t.Helper()
+	body, _ := json.Marshal(SyncCredentialsRequest{
+		UserAID: "EUSER123",
+		Credentials: []keri.Credential{
+			{SAID: said, Issuer: issuer, Recipient: "EUSER123", Schema: "EMatouMembershipSchemaV1",
+				Data: keri.CredentialData{CommunityName: "MATOU", Role: "Member"}, Signature: signSAID(said, 5)},
+		},
+		OOBIs: oobis,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sync/credentials", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.HandleSyncCredentials(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("credential sync failed: %s", w.Body.String())
+	}
+}
+
+func TestHandleSyncCredentials_ResolvesIssuerOOBI(t *testing.T) {
+	handler, store, cleanup := setupSyncTestHandler(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Mock OOBI endpoint serving the external issuer's KEL
+	issuer, icp := newTestKEL(5)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		json.NewEncoder(w).Encode(keyEvents(icp))
+	}))
+	defer server.Close()
+
+	// Without an OOBI the unknown issuer can't be verified
+	postExternalCredential(t, handler, "ESAID101", issuer.aid, nil)
+	cred, err := store.GetCredential(ctx, "ESAID101")
+	if err != nil {
+		t.Fatalf("failed to get credential: %v", err)
+	}
+	if cred.Verified {
+		t.Error("expected credential from unknown issuer not to be verified")
+	}
+
+	oobis := map[string]string{issuer.aid: server.URL + "/oobi/" + issuer.aid}
+	postExternalCredential(t, handler, "ESAID102", issuer.aid, oobis)
+	cred, err = store.GetCredential(ctx, "ESAID102")
+	if err != nil {
+		t.Fatalf("failed to get credential: %v", err)
+	}
+	if !cred.Verified {
+		t.Error("expected credential from resolved issuer to be verified")
+	}
+	if handler.storedKELRecord(ctx, issuer.aid, 0) == nil {
+		t.Error("expected resolved KEL to be cached")
+	}
+
+	// The issuer is now known locally, so it isn't resolved again
+	postExternalCredential(t, handler, "ESAID103", issuer.aid, oobis)
+	if requests != 1 {
+		t.Errorf("expected 1 OOBI request, got %d", requests)
+	}
+}
+
+func TestHandleSyncCredentials_ResolvedIssuerBadSignature(t *testing.T) {
+	handler, store, cleanup := setupSyncTestHandler(t)
+	defer cleanup()
+
+	// The credential is signed with key 5, the resolved issuer's key is 6
+	issuer, icp := newTestKEL(6)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(keyEvents(icp))
+	}))
+	defer server.Close()
+
+	postExternalCredential(t, handler, "ESAID101", issuer.aid, map[string]string{issuer.aid: server.URL})
+	cred, err := store.GetCredential(context.Background(), "ESAID101")
+	if err != nil {
+		t.Fatalf("failed to get credential: %v", err)
+	}
+	if cred.Verified {
+		t.Error("expected credential not signed by the resolved issuer not to be verified")
+	}
+}
+
+func TestHandleSyncCredentials_InvalidIssuerOOBI(t *testing.T) {
+	handler, store, cleanup := setupSyncTestHandler(t)
+	defer cleanup()
+
+	// The OOBI serves a KEL for a different AID
+	_, icp := newTestKEL(5)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(keyEvents(icp))
+	}))
+	defer server.Close()
+
+	postExternalCredential(t, handler, "ESAID101", "EEXTERNAL001", map[string]string{"EEXTERNAL001": server.URL})
+	cred, err := store.GetCredential(context.Background(), "ESAID101")
+	if err != nil {
+		t.Fatalf("failed to get credential: %v", err)
+	}
+	if cred.Verified {
+		t.Error("expected credential to stay unverified when OOBI resolution fails")
+	}
+	if handler.storedKELRecord(context.Background(), "EEXTERNAL001", 0) != nil {
+		t.Error("expected the mismatched KEL not to be cached")
 	}
 }
 
@@ -645,6 +1162,56 @@ func TestHandleGetCommunityMembers_WithMembers(t *testing.T) {
 	}
 }
 
+func TestHandleGetCommunityMembers_PagesByJoinDate(t *testing.T) {
+	handler, store, cleanup := setupSyncTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	for i, m := range []struct{ aid, joinedAt string }{
+		{"EUSER_C", "2026-03-01T00:00:00Z"},
+		{"EUSER_A", "2026-01-01T00:00:00Z"},
+		{"EUSER_D", "2026-02-01T00:00:00Z"},
+		{"EUSER_B", "2026-01-01T00:00:00Z"},
+	} {
+		cred := &anystore.CachedCredential{
+			ID:         fmt.Sprintf("ESAID_PAGE_%d", i),
+			IssuerAID:  "EAID123456789",
+			SubjectAID: m.aid,
+			SchemaID:   "EMatouMembershipSchemaV1",
+			Data:       map[string]interface{}{"role": "Member", "joinedAt": m.joinedAt},
+			Verified:   true,
+		}
+		if err := store.StoreCredential(ctx, cred); err != nil {
+			t.Fatalf("failed to store credential: %v", err)
+		}
+	}
+
+	get := func(query string) CommunityMembersResponse {
+		t.Helper()
+		w := httptest.NewRecorder()
+		handler.HandleGetCommunityMembers(w, httptest.NewRequest(http.MethodGet, "/api/v1/community/members"+query, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", query, w.Code, w.Body.String())
+		}
+		var resp CommunityMembersResponse
+		json.NewDecoder(w.Body).Decode(&resp)
+		return resp
+	}
+
+	var paged []string
+	for _, query := range []string{"?limit=2", "?limit=2&offset=2"} {
+		for _, m := range get(query).Members {
+			paged = append(paged, m.AID)
+		}
+	}
+	if want := []string{"EUSER_A", "EUSER_B", "EUSER_D", "EUSER_C"}; !slices.Equal(paged, want) {
+		t.Errorf("paged members = %v, want %v", paged, want)
+	}
+	if all := get(""); len(all.Members) != 4 || all.HasMore {
+		t.Errorf("expected every member without a limit, got %+v", all)
+	}
+}
+
 func TestHandleGetCommunityMembers_MethodNotAllowed(t *testing.T) {
 	handler, _, cleanup := setupSyncTestHandler(t)
 	defer cleanup()
@@ -852,7 +1419,7 @@ func TestSyncHandler_RegisterRoutes(t *testing.T) {
 	defer cleanup()
 
 	mux := http.NewServeMux()
-	handler.RegisterRoutes(mux)
+	handler.RegisterRoutes(mux, &mockRoleLookup{})
 
 	// Test that routes are registered
 	paths := []struct {
@@ -861,6 +1428,8 @@ func TestSyncHandler_RegisterRoutes(t *testing.T) {
 	}{
 		{http.MethodPost, "/api/v1/sync/credentials"},
 		{http.MethodPost, "/api/v1/sync/kel"},
+		{http.MethodPost, "/api/v1/sync/batch"},
+		{http.MethodPost, "/api/v1/credentials/reverify"},
 		{http.MethodGet, "/api/v1/community/members"},
 		{http.MethodGet, "/api/v1/community/credentials"},
 	}
@@ -884,3 +1453,227 @@ func TestSyncHandler_RegisterRoutes(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleSyncCredentials_InvalidatesTrustCache(t *testing.T) {
+	handler, store, cleanup := setupSyncTestHandler(t)
+	defer cleanup()
+
+	trustHandler := NewTrustHandler(store, "EAID123456789", nil)
+	trustStatus := func() string {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/trust/scores", nil)
+		w := httptest.NewRecorder()
+		trustHandler.HandleGetScores(w, req)
+		return w.Header().Get("X-Trust-Cache")
+	}
+	syncCredential := func() {
+		t.Helper()
+		body := `{
+			"userAid": "EUSER123",
+			"credentials": [
+				{
+					"said": "ESAID001",
+					"issuer": "EAID123456789",
+					"recipient": "EUSER123",
+					"schema": "EMatouMembershipSchemaV1",
+					"data": {"role": "Member", "joinedAt": "2026-01-19T00:00:00Z"}
+				}
+			]
+		}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/sync/credentials", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		handler.HandleSyncCredentials(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("sync failed: %d %s", w.Code, w.Body.String())
+		}
+	}
+
+	syncCredential()
+	trustStatus()
+	if got := trustStatus(); got != "hit" {
+		t.Fatalf("expected cached scores before re-sync, got %q", got)
+	}
+
+	// Re-syncing the same credential leaves the set's fingerprint unchanged,
+	// so only the sync's invalidation can force a rebuild
+	syncCredential()
+	if got := trustStatus(); got != "miss" {
+		t.Errorf("expected cache miss after sync, got %q", got)
+	}
+}
+
+func TestHandleSyncCredentials_UpdatesTrustGraphIncrementally(t *testing.T) {
+	handler, store, cleanup := setupSyncTestHandler(t)
+	defer cleanup()
+
+	trustHandler := NewTrustHandler(store, "EAID123456789", nil)
+	handler.SetTrustGraphUpdater(trustHandler)
+
+	syncCredential := func(said, recipient string) {
+		t.Helper()
+		body := fmt.Sprintf(`{
+			"userAid": "EUSER123",
+			"credentials": [
+				{
+					"said": %q,
+					"issuer": "EAID123456789",
+					"recipient": %q,
+					"schema": "EMatouMembershipSchemaV1",
+					"data": {"role": "Member", "joinedAt": "2026-01-19T00:00:00Z"}
+				}
+			]
+		}`, said, recipient)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/sync/credentials", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		handler.HandleSyncCredentials(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("sync failed: %d %s", w.Code, w.Body.String())
+		}
+	}
+	getScores := func() (ScoresResponse, string) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/trust/scores?limit=100", nil)
+		w := httptest.NewRecorder()
+		trustHandler.HandleGetScores(w, req)
+		var resp ScoresResponse
+		json.NewDecoder(w.Body).Decode(&resp)
+		return resp, w.Header().Get("X-Trust-Cache")
+	}
+
+	syncCredential("ESAID001", "EUSER123")
+	if _, status := getScores(); status != "miss" {
+		t.Fatalf("expected cold start miss, got %q", status)
+	}
+
+	// A new credential is applied to the cached graph rather than rebuilt
+	syncCredential("ESAID002", "EUSER456")
+	resp, status := getScores()
+	if status != "hit" {
+		t.Errorf("expected incremental update to keep the cache warm, got %q", status)
+	}
+	found := false
+	for _, s := range resp.Scores {
+		if s.AID == "EUSER456" {
+			found = true
+			if s.IncomingCredentials != 1 || s.GraphDepth != 1 {
+				t.Errorf("unexpected score for new member: %+v", s)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("new member missing from cached scores: %+v", resp.Scores)
+	}
+}
+
+// fakeProfileWriter accepts profile writes unless the type is "Broken".
+type fakeProfileWriter struct {
+	written []CreateProfileRequest
+}
+
+func (f *fakeProfileWriter) WriteProfile(ctx context.Context, req CreateProfileRequest) (*ProfileWriteResult, error) {
+	if req.Type == "Broken" {
+		return nil, &ProfileWriteError{Status: http.StatusBadRequest, Message: "unknown type: Broken"}
+	}
+	f.written = append(f.written, req)
+	return &ProfileWriteResult{ObjectID: req.ID, Version: 1, SpaceID: "space-community-test"}, nil
+}
+
+func TestHandleSyncBatch_PartialSuccess(t *testing.T) {
+	handler, store, cleanup := setupSyncTestHandler(t)
+	defer cleanup()
+	profiles := &fakeProfileWriter{}
+	handler.SetProfileWriter(profiles)
+
+	kel, icp := newTestKEL(1)
+	rot := kel.rotate()
+	broken := kel.build("ixn", 2, "EDIGEST999", anchorSeals(nil))
+	after := kel.build("ixn", 3, broken.Digest, anchorSeals(nil))
+
+	body, _ := json.Marshal(SyncBatchRequest{
+		UserAID: kel.aid,
+		KEL:     []KELEvent{icp, rot, broken, after},
+		Credentials: []keri.Credential{
+			{SAID: "ESAID_BATCH_OK", Issuer: "EAID123456789", Recipient: "EUSER123", Schema: "EMatouMembershipSchemaV1",
+				Data: keri.CredentialData{CommunityName: "MATOU", Role: "Member"}},
+			{SAID: ""},
+		},
+		Profiles: []CreateProfileRequest{
+			{Type: "SharedProfile", ID: "SharedProfile-EUSER123", Data: json.RawMessage(`{"aid":"EUSER123"}`)},
+			{Type: "Broken", ID: "Broken-1"},
+		},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sync/batch", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.HandleSyncBatch(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 for a partially successful batch, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp SyncBatchResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Success || resp.Succeeded != 4 || resp.Failed != 4 {
+		t.Errorf("expected 4 succeeded and 4 failed, got success=%v %d/%d", resp.Success, resp.Succeeded, resp.Failed)
+	}
+	if resp.PrivateSpace == "" {
+		t.Error("expected the private space to be set")
+	}
+
+	want := []struct {
+		kind    string
+		index   int
+		success bool
+	}{
+		{SyncItemKEL, 0, true},
+		{SyncItemKEL, 1, true},
+		{SyncItemKEL, 2, false},
+		{SyncItemKEL, 3, false},
+		{SyncItemCredential, 0, true},
+		{SyncItemCredential, 1, false},
+		{SyncItemProfile, 0, true},
+		{SyncItemProfile, 1, false},
+	}
+	if len(resp.Results) != len(want) {
+		t.Fatalf("expected %d results, got %+v", len(want), resp.Results)
+	}
+	for i, wantItem := range want {
+		got := resp.Results[i]
+		if got.Kind != wantItem.kind || got.Index != wantItem.index || got.Success != wantItem.success {
+			t.Errorf("result %d = %+v, want kind=%s index=%d success=%v", i, got, wantItem.kind, wantItem.index, wantItem.success)
+		}
+		if !got.Success && got.Error == "" {
+			t.Errorf("result %d failed without an error", i)
+		}
+	}
+	if resp.Results[3].Error != "follows rejected event at sequence 2" {
+		t.Errorf("expected the event after the broken one to be rejected with it, got %q", resp.Results[3].Error)
+	}
+
+	// The valid items were stored despite the failures
+	if rec := handler.storedKELRecord(context.Background(), kel.aid, 1); rec == nil || rec.Digest != rot.Digest {
+		t.Errorf("expected the rotation event to be stored, got %+v", rec)
+	}
+	if rec := handler.storedKELRecord(context.Background(), kel.aid, 2); rec != nil {
+		t.Errorf("expected the broken event not to be stored, got %+v", rec)
+	}
+	if cred, err := store.GetCredential(context.Background(), "ESAID_BATCH_OK"); err != nil || cred == nil {
+		t.Errorf("expected the valid credential to be cached, got %v (err %v)", cred, err)
+	}
+	if len(profiles.written) != 1 || profiles.written[0].ID != "SharedProfile-EUSER123" {
+		t.Errorf("expected one profile written, got %+v", profiles.written)
+	}
+}
+
+func TestHandleSyncBatch_EmptyBatch(t *testing.T) {
+	handler, _, cleanup := setupSyncTestHandler(t)
+	defer cleanup()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sync/batch", bytes.NewBufferString(`{"userAid":"EUSER123"}`))
+	w := httptest.NewRecorder()
+	handler.HandleSyncBatch(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // register GIF decoding
+	"image/jpeg"
+	"image/png"
+)
+
+const (
+	// defaultThumbnailMaxDimension bounds thumbnail width and height in pixels
+	defaultThumbnailMaxDimension = 320
+	// maxThumbnailSourcePixels is the largest image decoded for a thumbnail, so
+	// a small file declaring huge dimensions can't exhaust memory
+	maxThumbnailSourcePixels = 50_000_000
+	thumbnailJPEGQuality     = 80
+)
+
+// makeThumbnail downscales an image so neither side exceeds maxDim, keeping
+// its aspect ratio. JPEGs are re-encoded as JPEG and other formats as PNG
+// (preserving transparency). It returns nil data, with no error, for images
+// already within maxDim.
+func makeThumbnail(data []byte, maxDim int) ([]byte, string, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("reading image header: %w", err)
+	}
+	if cfg.Width <= maxDim && cfg.Height <= maxDim {
+		return nil, "", nil
+	}
+	if cfg.Width*cfg.Height > maxThumbnailSourcePixels {
+		return nil, "", fmt.Errorf("image is %dx%d, too large to thumbnail", cfg.Width, cfg.Height)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("decoding image: %w", err)
+	}
+
+	width, height := maxDim, maxDim
+	if cfg.Width >= cfg.Height {
+		height = max(1, cfg.Height*maxDim/cfg.Width)
+	} else {
+		width = max(1, cfg.Width*maxDim/cfg.Height)
+	}
+	thumb := downscale(src, width, height)
+
+	var buf bytes.Buffer
+	if format == "jpeg" {
+		err = jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: thumbnailJPEGQuality})
+		return buf.Bytes(), "image/jpeg", err
+	}
+	err = png.Encode(&buf, thumb)
+	return buf.Bytes(), "image/png", err
+}
+
+// downscale resizes src to width x height by averaging the source pixels
+// that fall in each destination pixel (a box filter).
+func downscale(src image.Image, width, height int) *image.RGBA {
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/height
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*bounds.Dy()/height)
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/width
+			x1 := max(x0+1, bounds.Min.X+(x+1)*bounds.Dx()/width)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					// RGBA returns alpha-premultiplied 16-bit channels
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(b / n >> 8),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// testImage returns a width x height image, half opaque red and half
+// transparent.
+func testImage(width, height int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x < width/2 {
+				img.Set(x, y, color.NRGBA{R: 255, A: 255})
+			}
+		}
+	}
+	return img
+}
+
+func testJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(width, height), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage(width, height)); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestMakeThumbnail_JPEG(t *testing.T) {
+	thumb, contentType, err := makeThumbnail(testJPEG(t, 300, 600), 100)
+	if err != nil {
+		t.Fatalf("makeThumbnail: %v", err)
+	}
+	if contentType != "image/jpeg" {
+		t.Errorf("content type = %q, want image/jpeg", contentType)
+	}
+	img, format, err := image.Decode(bytes.NewReader(thumb))
+	if err != nil {
+		t.Fatalf("decoding thumbnail: %v", err)
+	}
+	if format != "jpeg" || img.Bounds().Dx() != 50 || img.Bounds().Dy() != 100 {
+		t.Errorf("thumbnail is %s %v, want jpeg 50x100", format, img.Bounds().Size())
+	}
+}
+
+func TestMakeThumbnail_PNGKeepsTransparency(t *testing.T) {
+	thumb, contentType, err := makeThumbnail(testPNG(t, 800, 400), 200)
+	if err != nil {
+		t.Fatalf("makeThumbnail: %v", err)
+	}
+	if contentType != "image/png" {
+		t.Errorf("content type = %q, want image/png", contentType)
+	}
+	img, err := png.Decode(bytes.NewReader(thumb))
+	if err != nil {
+		t.Fatalf("decoding thumbnail: %v", err)
+	}
+	if img.Bounds().Dx() != 200 || img.Bounds().Dy() != 100 {
+		t.Fatalf("thumbnail is %v, want 200x100", img.Bounds().Size())
+	}
+	if _, _, _, a := img.At(10, 50).RGBA(); a != 0xffff {
+		t.Errorf("left half alpha = %#x, want opaque", a)
+	}
+	if _, _, _, a := img.At(190, 50).RGBA(); a != 0 {
+		t.Errorf("right half alpha = %#x, want transparent", a)
+	}
+}
+
+func TestMakeThumbnail_SmallImageUnchanged(t *testing.T) {
+	thumb, _, err := makeThumbnail(testPNG(t, 50, 40), 100)
+	if err != nil || thumb != nil {
+		t.Errorf("expected no thumbnail for a small image, got %d bytes, err %v", len(thumb), err)
+	}
+}
+
+func TestMakeThumbnail_NotAnImage(t *testing.T) {
+	if _, _, err := makeThumbnail([]byte("not an image"), 100); err == nil {
+		t.Error("expected error for non-image data")
+	}
+}
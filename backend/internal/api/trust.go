@@ -3,9 +3,14 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"log"
+	"maps"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/matou-dao/backend/internal/anysync"
 	"github.com/matou-dao/backend/internal/anystore"
@@ -18,6 +23,23 @@ type TrustHandler struct {
 	orgAID       string
 	calculator   *trust.Calculator
 	spaceManager *anysync.SpaceManager
+
+	mu       sync.Mutex
+	cached   *trustSnapshot
+	cacheTTL time.Duration // 0 keeps scores until the credential set changes
+}
+
+// decayedScoreTTL is how long decayed scores are reused before being
+// recomputed. They fall as credentials age, even while the set is unchanged.
+const decayedScoreTTL = time.Hour
+
+// trustSnapshot is the graph and scores computed from one credential set
+type trustSnapshot struct {
+	version     string
+	credentials []*anystore.CachedCredential
+	graph       *trust.Graph
+	scores      map[string]*trust.Score
+	computedAt  time.Time
 }
 
 // NewTrustHandler creates a new trust handler
@@ -30,6 +52,37 @@ func NewTrustHandler(store *anystore.LocalStore, orgAID string, spaceManager *an
 	}
 }
 
+// SetDecayHalfLife enables exponential time decay of credential contributions
+// to trust scores. A non-positive half-life disables decay.
+func (h *TrustHandler) SetDecayHalfLife(halfLife time.Duration) {
+	weights := trust.DefaultWeights()
+	if halfLife > 0 {
+		weights.DecayHalfLife = halfLife
+	}
+	h.SetWeights(weights)
+}
+
+// SetWeights replaces the trust score weights and drops the cached scores so
+// the next query recomputes them. With decay on, cached scores also expire
+// after decayedScoreTTL. Safe to call while serving requests.
+func (h *TrustHandler) SetWeights(weights trust.ScoreWeights) {
+	h.mu.Lock()
+	h.calculator = trust.NewCalculator(weights)
+	h.cached = nil
+	h.cacheTTL = 0
+	if weights.DecayHalfLife > 0 {
+		h.cacheTTL = decayedScoreTTL
+	}
+	h.mu.Unlock()
+}
+
+// currentCalculator returns the calculator for the current weights
+func (h *TrustHandler) currentCalculator() *trust.Calculator {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.calculator
+}
+
 // GraphResponse represents the trust graph API response
 type GraphResponse struct {
 	Graph   *trust.Graph         `json:"graph"`
@@ -38,7 +91,8 @@ type GraphResponse struct {
 
 // ScoreResponse represents a single trust score response
 type ScoreResponse struct {
-	Score *trust.Score `json:"score"`
+	Score     *trust.Score          `json:"score"`
+	Breakdown *trust.ScoreBreakdown `json:"breakdown,omitempty"`
 }
 
 // ScoresResponse represents multiple trust scores response
@@ -47,6 +101,11 @@ type ScoresResponse struct {
 	Total  int            `json:"total"`
 }
 
+// PathResponse represents a trust path response
+type PathResponse struct {
+	Path *trust.Path `json:"path"`
+}
+
 // getCommunityCredentials fetches credentials from the AnySync community space
 // ObjectTree and converts them to CachedCredential format for the trust builder.
 func (h *TrustHandler) getCommunityCredentials(ctx context.Context) []*anystore.CachedCredential {
@@ -91,11 +150,178 @@ func (h *TrustHandler) newBuilder(ctx context.Context) *trust.Builder {
 	return builder
 }
 
+// snapshot returns the full trust graph and scores for the current credential
+// set. The computation is reused while the set's fingerprint is unchanged and
+// its scores are still in the trust_graph_cache collection, which credential
+// sync drops to invalidate, and, with decay on, for up to decayedScoreTTL.
+// fresh forces a rebuild. The returned bool reports
+// a cache hit.
+func (h *TrustHandler) snapshot(ctx context.Context, fresh bool) (*trustSnapshot, bool, error) {
+	builder := h.newBuilder(ctx)
+	credentials, err := builder.Credentials(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	version := trust.Fingerprint(credentials)
+
+	h.mu.Lock()
+	cached := h.cached
+	calculator := h.calculator
+	ttl := h.cacheTTL
+	h.mu.Unlock()
+
+	expired := cached != nil && ttl > 0 && time.Since(cached.computedAt) >= ttl
+	if !fresh && cached != nil && cached.version == version && !expired {
+		// The org node is written last, so it marks a complete cache
+		if node, err := h.store.GetTrustNode(ctx, h.orgAID); err == nil && node.Version == version {
+			return cached, true, nil
+		}
+	}
+
+	graph := builder.BuildFrom(credentials)
+	snap := &trustSnapshot{
+		version:     version,
+		credentials: credentials,
+		graph:       graph,
+		scores:      calculator.CalculateAllScores(graph),
+		computedAt:  time.Now(),
+	}
+	h.persistScores(ctx, snap)
+
+	h.mu.Lock()
+	h.cached = snap
+	h.mu.Unlock()
+
+	return snap, false, nil
+}
+
+// ApplyCredentials updates the cached snapshot with newly synced credentials
+// instead of rebuilding: new credentials are applied to a copy of the graph
+// and only the affected scores are recomputed. A credential that replaces a
+// cached one with different content can't be applied incrementally, so it
+// invalidates the cache and the next query rebuilds.
+func (h *TrustHandler) ApplyCredentials(ctx context.Context, creds []*anystore.CachedCredential) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	invalidate := func() {
+		h.cached = nil
+		if err := h.store.InvalidateTrustGraphCache(ctx); err != nil {
+			log.Printf("[Trust] Failed to invalidate trust graph cache: %v", err)
+		}
+	}
+
+	snap := h.cached
+	if snap == nil {
+		invalidate()
+		return
+	}
+
+	known := make(map[string]*anystore.CachedCredential, len(snap.credentials))
+	for _, c := range snap.credentials {
+		known[c.ID] = c
+	}
+	var added []*anystore.CachedCredential
+	for _, c := range creds {
+		if old, ok := known[c.ID]; ok {
+			if trust.Fingerprint([]*anystore.CachedCredential{old}) != trust.Fingerprint([]*anystore.CachedCredential{c}) {
+				invalidate()
+				return
+			}
+			continue
+		}
+		known[c.ID] = c
+		added = append(added, c)
+	}
+	if len(added) == 0 {
+		return
+	}
+
+	graph := snap.graph.Clone()
+	scores := maps.Clone(snap.scores)
+	builder := trust.NewBuilder(h.store, h.orgAID)
+	var changed []string
+	for _, c := range added {
+		changed = append(changed, builder.ApplyCredential(graph, c)...)
+	}
+	updated := h.calculator.UpdateScores(graph, scores, changed)
+
+	credentials := append(slices.Clip(snap.credentials), added...)
+	next := &trustSnapshot{
+		version:     trust.Fingerprint(credentials),
+		credentials: credentials,
+		graph:       graph,
+		scores:      scores,
+		computedAt:  snap.computedAt, // the scores not recomputed are as old as snap's
+	}
+	h.cached = next
+	h.persistNodes(ctx, next, updated)
+}
+
+// persistScores replaces the trust_graph_cache contents with snap's scores.
+// Failures are logged; the snapshot is still served from memory.
+func (h *TrustHandler) persistScores(ctx context.Context, snap *trustSnapshot) {
+	if err := h.store.InvalidateTrustGraphCache(ctx); err != nil {
+		log.Printf("[Trust] Failed to clear trust graph cache: %v", err)
+		return
+	}
+	h.persistNodes(ctx, snap, slices.Collect(maps.Keys(snap.scores)))
+}
+
+// persistNodes writes the scores for aids to trust_graph_cache, followed by
+// the org node, which marks the cache as complete for snap's version.
+func (h *TrustHandler) persistNodes(ctx context.Context, snap *trustSnapshot, aids []string) {
+	connections := make(map[string][]string)
+	for _, e := range snap.graph.Edges {
+		connections[e.From] = append(connections[e.From], e.To)
+		connections[e.To] = append(connections[e.To], e.From)
+	}
+
+	now := time.Now().UTC()
+	store := func(score *trust.Score) error {
+		return h.store.StoreTrustNode(ctx, &anystore.TrustGraphNode{
+			AID:         score.AID,
+			DisplayName: score.Alias,
+			TrustScore:  score.Score,
+			Connections: connections[score.AID],
+			Depth:       score.GraphDepth,
+			CachedAt:    now,
+			Version:     snap.version,
+		})
+	}
+	for _, aid := range aids {
+		score, ok := snap.scores[aid]
+		if !ok || aid == h.orgAID {
+			continue
+		}
+		if err := store(score); err != nil {
+			log.Printf("[Trust] Failed to cache trust node %s: %v", aid, err)
+			return
+		}
+	}
+	if org, ok := snap.scores[h.orgAID]; ok {
+		if err := store(org); err != nil {
+			log.Printf("[Trust] Failed to cache trust node %s: %v", h.orgAID, err)
+		}
+	}
+}
+
+// setCacheHeader reports whether the response was served from the trust cache
+func setCacheHeader(w http.ResponseWriter, hit bool) {
+	if hit {
+		w.Header().Set("X-Trust-Cache", "hit")
+	} else {
+		w.Header().Set("X-Trust-Cache", "miss")
+	}
+}
+
 // HandleGetGraph handles GET /api/v1/trust/graph
 // Query params:
 //   - aid: Focus on specific AID (optional)
 //   - depth: Depth limit for subgraph (optional, default: full graph)
 //   - summary: Include summary stats (optional, default: false)
+//   - format: json (default), dot or graphml
+//   - fresh: Bypass the trust cache (optional, default: false)
 func (h *TrustHandler) HandleGetGraph(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{
@@ -104,17 +330,26 @@ func (h *TrustHandler) HandleGetGraph(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	format := r.URL.Query().Get("format")
+	switch format {
+	case "", "json", "dot", "graphml":
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "format must be one of: json, dot, graphml",
+		})
+		return
+	}
+
 	ctx := r.Context()
 
 	// Parse query parameters
 	aidFilter := r.URL.Query().Get("aid")
 	depthStr := r.URL.Query().Get("depth")
 	includeSummary := r.URL.Query().Get("summary") == "true"
-
-	// Create builder
-	builder := h.newBuilder(ctx)
+	fresh := r.URL.Query().Get("fresh") == "true"
 
 	var graph *trust.Graph
+	var snap *trustSnapshot
 	var err error
 
 	// Build graph
@@ -126,10 +361,15 @@ func (h *TrustHandler) HandleGetGraph(w http.ResponseWriter, r *http.Request) {
 				depth = d
 			}
 		}
-		graph, err = builder.BuildForAID(ctx, aidFilter, depth)
+		graph, err = h.newBuilder(ctx).BuildForAID(ctx, aidFilter, depth)
 	} else {
-		// Build full graph
-		graph, err = builder.Build(ctx)
+		// Full graph, served from cache when the credential set is unchanged
+		var hit bool
+		snap, hit, err = h.snapshot(ctx, fresh)
+		if err == nil {
+			graph = snap.graph
+			setCacheHeader(w, hit)
+		}
 	}
 
 	if err != nil {
@@ -139,6 +379,20 @@ func (h *TrustHandler) HandleGetGraph(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Stream export formats directly to the client
+	switch format {
+	case "dot":
+		w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="trust.dot"`)
+		graph.WriteDOT(w)
+		return
+	case "graphml":
+		w.Header().Set("Content-Type", "application/graphml+xml; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="trust.graphml"`)
+		graph.WriteGraphML(w)
+		return
+	}
+
 	// Build response
 	resp := GraphResponse{
 		Graph: graph,
@@ -146,13 +400,21 @@ func (h *TrustHandler) HandleGetGraph(w http.ResponseWriter, r *http.Request) {
 
 	// Include summary if requested
 	if includeSummary {
-		resp.Summary = h.calculator.CalculateSummary(graph)
+		calculator := h.currentCalculator()
+		if snap != nil {
+			resp.Summary = calculator.Summarize(graph, snap.scores)
+		} else {
+			resp.Summary = calculator.CalculateSummary(graph)
+		}
 	}
 
 	writeJSON(w, http.StatusOK, resp)
 }
 
 // HandleGetScore handles GET /api/v1/trust/score/{aid}
+// Query params:
+//   - explain: Include the components that sum to the score (optional, default: false)
+//   - fresh: Bypass the trust cache (optional, default: false)
 func (h *TrustHandler) HandleGetScore(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{
@@ -176,35 +438,41 @@ func (h *TrustHandler) HandleGetScore(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	// Build graph
-	builder := h.newBuilder(ctx)
-	graph, err := builder.Build(ctx)
+	snap, hit, err := h.snapshot(ctx, r.URL.Query().Get("fresh") == "true")
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{
 			"error": "failed to build trust graph: " + err.Error(),
 		})
 		return
 	}
+	setCacheHeader(w, hit)
 
 	// Check if AID exists in graph
-	if graph.GetNode(aid) == nil {
+	score, ok := snap.scores[aid]
+	if !ok {
 		writeJSON(w, http.StatusNotFound, map[string]string{
 			"error": "AID not found in trust graph",
 		})
 		return
 	}
 
-	// Calculate score
-	score := h.calculator.CalculateScore(aid, graph)
-
-	writeJSON(w, http.StatusOK, ScoreResponse{
+	resp := ScoreResponse{
 		Score: score,
-	})
+	}
+	if r.URL.Query().Get("explain") == "true" {
+		// Decay is evaluated now, so with decay enabled the breakdown's total
+		// can drift slightly from a score cached earlier
+		resp.Breakdown = h.currentCalculator().ExplainScore(aid, snap.graph)
+	}
+
+	writeJSON(w, http.StatusOK, resp)
 }
 
 // HandleGetScores handles GET /api/v1/trust/scores
 // Query params:
 //   - limit: Maximum number of scores to return (optional, default: 10)
 //   - sort: Sort order - "score" (default), "depth", "credentials"
+//   - fresh: Bypass the trust cache (optional, default: false)
 func (h *TrustHandler) HandleGetScores(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{
@@ -225,17 +493,17 @@ func (h *TrustHandler) HandleGetScores(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Build graph
-	builder := h.newBuilder(ctx)
-	graph, err := builder.Build(ctx)
+	snap, hit, err := h.snapshot(ctx, r.URL.Query().Get("fresh") == "true")
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{
 			"error": "failed to build trust graph: " + err.Error(),
 		})
 		return
 	}
+	setCacheHeader(w, hit)
 
 	// Get top scores
-	scores := h.calculator.GetTopScores(graph, limit)
+	scores := trust.TopScores(snap.scores, limit)
 
 	writeJSON(w, http.StatusOK, ScoresResponse{
 		Scores: scores,
@@ -255,24 +523,84 @@ func (h *TrustHandler) HandleGetSummary(w http.ResponseWriter, r *http.Request)
 	ctx := r.Context()
 
 	// Build graph
-	builder := h.newBuilder(ctx)
-	graph, err := builder.Build(ctx)
+	snap, hit, err := h.snapshot(ctx, r.URL.Query().Get("fresh") == "true")
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{
 			"error": "failed to build trust graph: " + err.Error(),
 		})
 		return
 	}
+	setCacheHeader(w, hit)
 
 	// Calculate summary
-	summary := h.calculator.CalculateSummary(graph)
+	summary := h.currentCalculator().Summarize(snap.graph, snap.scores)
 
 	writeJSON(w, http.StatusOK, summary)
 }
 
+// HandleGetPath handles GET /api/v1/trust/path
+// Query params:
+//   - from: Starting AID (required)
+//   - to: Target AID (required)
+//   - maxDepth: Maximum number of hops (optional, default: unlimited)
+func (h *TrustHandler) HandleGetPath(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{
+			"error": "Method not allowed",
+		})
+		return
+	}
+
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+	if from == "" || to == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "from and to query parameters are required",
+		})
+		return
+	}
+
+	maxDepth := 0
+	if depthStr := r.URL.Query().Get("maxDepth"); depthStr != "" {
+		d, err := strconv.Atoi(depthStr)
+		if err != nil || d <= 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "maxDepth must be a positive integer",
+			})
+			return
+		}
+		maxDepth = d
+	}
+
+	ctx := r.Context()
+
+	// Build graph
+	snap, hit, err := h.snapshot(ctx, r.URL.Query().Get("fresh") == "true")
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to build trust graph: " + err.Error(),
+		})
+		return
+	}
+	setCacheHeader(w, hit)
+
+	path := snap.graph.ShortestPath(from, to, maxDepth)
+	if path == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error": "no trust path found between the given AIDs",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, PathResponse{
+		Path: path,
+	})
+}
+
 // RegisterRoutes registers trust routes on the mux
 func (h *TrustHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/api/v1/trust/graph", h.HandleGetGraph)
+	mux.HandleFunc("/api/v1/trust/path", h.HandleGetPath)
 	mux.HandleFunc("/api/v1/trust/score/", h.HandleGetScore)
 	mux.HandleFunc("/api/v1/trust/scores", h.HandleGetScores)
 	mux.HandleFunc("/api/v1/trust/summary", h.HandleGetSummary)
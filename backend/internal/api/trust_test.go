@@ -3,9 +3,12 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"encoding/xml"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -287,6 +290,53 @@ func TestHandleGetScore(t *testing.T) {
 	}
 }
 
+func TestHandleGetScore_Explain(t *testing.T) {
+	store, cleanup := setupTrustTestStore(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	for _, c := range []*anystore.CachedCredential{
+		{ID: "ESAID001", IssuerAID: "EORG123", SubjectAID: "EUSER1", SchemaID: "EMatouMembershipSchemaV1"},
+		{ID: "ESAID002", IssuerAID: "EORG123", SubjectAID: "EUSER2", SchemaID: "EMatouMembershipSchemaV1"},
+		{ID: "ESAID003", IssuerAID: "EUSER2", SubjectAID: "EUSER1", SchemaID: "EInvitationSchemaV1"},
+		{ID: "ESAID004", IssuerAID: "EUSER1", SubjectAID: "EUSER2", SchemaID: "EInvitationSchemaV1"},
+	} {
+		c.CachedAt = time.Now()
+		c.Data = map[string]interface{}{"role": "Member"}
+		store.StoreCredential(ctx, c)
+	}
+
+	handler := NewTrustHandler(store, "EORG123", nil)
+	get := func(query string) ScoreResponse {
+		w := httptest.NewRecorder()
+		handler.HandleGetScore(w, httptest.NewRequest(http.MethodGet, "/api/v1/trust/score/EUSER1"+query, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var result ScoreResponse
+		json.NewDecoder(w.Body).Decode(&result)
+		return result
+	}
+
+	if result := get(""); result.Breakdown != nil {
+		t.Error("expected no breakdown without explain=true")
+	}
+
+	result := get("?explain=true")
+	b := result.Breakdown
+	if b == nil {
+		t.Fatal("expected a breakdown")
+	}
+	// Org credential 1+2, EUSER2's mutual credential 1+3, two issuers at 2, one level deep
+	sum := b.IncomingCredentials + b.OrgIssuedBonus + b.UniqueIssuers + b.BidirectionalBonus + b.DepthPenalty + b.Floor
+	if math.Abs(sum-result.Score.Score) > 1e-9 || math.Abs(b.Total-result.Score.Score) > 1e-9 {
+		t.Errorf("components sum to %f, total %f, score %f", sum, b.Total, result.Score.Score)
+	}
+	if b.OrgIssuedBonus != 2 || b.BidirectionalBonus != 3 || b.UniqueIssuers != 4 || len(b.Credentials) != 2 {
+		t.Errorf("unexpected breakdown %+v", b)
+	}
+}
+
 func TestHandleGetScore_NotFound(t *testing.T) {
 	store, cleanup := setupTrustTestStore(t)
 	defer cleanup()
@@ -548,3 +598,249 @@ func TestTrustHandler_RegisterRoutes(t *testing.T) {
 		})
 	}
 }
+
+// storeChainCredentials stores org → user1 → user2 plus an unconnected self-claim.
+func storeChainCredentials(t *testing.T, store *anystore.LocalStore) {
+	t.Helper()
+	ctx := context.Background()
+	creds := []*anystore.CachedCredential{
+		{ID: "ESAID001", IssuerAID: "EORG123", SubjectAID: "EUSER1", SchemaID: "EMatouMembershipSchemaV1"},
+		{ID: "ESAID002", IssuerAID: "EUSER1", SubjectAID: "EUSER2", SchemaID: "EInvitationSchemaV1"},
+		{ID: "ESAID003", IssuerAID: "ELONER", SubjectAID: "ELONER", SchemaID: "ESelfClaimSchemaV1"},
+	}
+	for _, c := range creds {
+		c.CachedAt = time.Now()
+		c.Data = map[string]interface{}{"role": "Member"}
+		if err := store.StoreCredential(ctx, c); err != nil {
+			t.Fatalf("storing credential %s: %v", c.ID, err)
+		}
+	}
+}
+
+func TestHandleGetPath(t *testing.T) {
+	store, cleanup := setupTrustTestStore(t)
+	defer cleanup()
+	storeChainCredentials(t, store)
+
+	handler := NewTrustHandler(store, "EORG123", nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/trust/path?from=EORG123&to=EUSER2", nil)
+	w := httptest.NewRecorder()
+	handler.HandleGetPath(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var result PathResponse
+	json.NewDecoder(w.Body).Decode(&result)
+
+	want := []string{"EORG123", "EUSER1", "EUSER2"}
+	if result.Path == nil || len(result.Path.AIDs) != len(want) {
+		t.Fatalf("expected path %v, got %+v", want, result.Path)
+	}
+	for i, aid := range want {
+		if result.Path.AIDs[i] != aid {
+			t.Errorf("AIDs[%d] = %s, want %s", i, result.Path.AIDs[i], aid)
+		}
+	}
+	if len(result.Path.Hops) != 2 || result.Path.Hops[1].Credentials[0].CredentialID != "ESAID002" {
+		t.Errorf("expected second hop via ESAID002, got %+v", result.Path.Hops)
+	}
+}
+
+func TestHandleGetPath_Unreachable(t *testing.T) {
+	store, cleanup := setupTrustTestStore(t)
+	defer cleanup()
+	storeChainCredentials(t, store)
+
+	handler := NewTrustHandler(store, "EORG123", nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/trust/path?from=EORG123&to=ELONER", nil)
+	w := httptest.NewRecorder()
+	handler.HandleGetPath(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", w.Code)
+	}
+}
+
+func TestHandleGetPath_MaxDepth(t *testing.T) {
+	store, cleanup := setupTrustTestStore(t)
+	defer cleanup()
+	storeChainCredentials(t, store)
+
+	handler := NewTrustHandler(store, "EORG123", nil)
+
+	tests := []struct {
+		query  string
+		status int
+	}{
+		{"from=EORG123&to=EUSER2&maxDepth=1", http.StatusNotFound},
+		{"from=EORG123&to=EUSER2&maxDepth=2", http.StatusOK},
+		{"from=EORG123&to=EUSER2&maxDepth=0", http.StatusBadRequest},
+		{"from=EORG123", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/trust/path?"+tt.query, nil)
+			w := httptest.NewRecorder()
+			handler.HandleGetPath(w, req)
+			if w.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandleGetGraph_ExportFormats(t *testing.T) {
+	store, cleanup := setupTrustTestStore(t)
+	defer cleanup()
+	storeChainCredentials(t, store)
+
+	handler := NewTrustHandler(store, "EORG123", nil)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/trust/graph"+query, nil)
+		w := httptest.NewRecorder()
+		handler.HandleGetGraph(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d", query, w.Code)
+		}
+		return w
+	}
+
+	var jsonView GraphResponse
+	json.NewDecoder(get("").Body).Decode(&jsonView)
+	nodes, edges := len(jsonView.Graph.Nodes), len(jsonView.Graph.Edges)
+
+	t.Run("dot", func(t *testing.T) {
+		w := get("?format=dot")
+		if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/vnd.graphviz") {
+			t.Errorf("unexpected content type %q", ct)
+		}
+		out := w.Body.String()
+		if !strings.HasPrefix(out, "digraph trust {") || !strings.HasSuffix(out, "}\n") {
+			t.Errorf("unexpected DOT framing:\n%s", out)
+		}
+		if got := strings.Count(out, " -> "); got != edges {
+			t.Errorf("expected %d edges, got %d", edges, got)
+		}
+		if got := strings.Count(out, "verification="); got != nodes {
+			t.Errorf("expected %d nodes, got %d", nodes, got)
+		}
+	})
+
+	t.Run("graphml", func(t *testing.T) {
+		w := get("?format=graphml")
+		var doc struct {
+			Graph struct {
+				Nodes []struct{} `xml:"node"`
+				Edges []struct{} `xml:"edge"`
+			} `xml:"graph"`
+		}
+		if err := xml.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+			t.Fatalf("invalid GraphML: %v", err)
+		}
+		if len(doc.Graph.Nodes) != nodes || len(doc.Graph.Edges) != edges {
+			t.Errorf("expected %d nodes / %d edges, got %d / %d",
+				nodes, edges, len(doc.Graph.Nodes), len(doc.Graph.Edges))
+		}
+	})
+}
+
+func TestHandleGetGraph_InvalidFormat(t *testing.T) {
+	store, cleanup := setupTrustTestStore(t)
+	defer cleanup()
+
+	handler := NewTrustHandler(store, "EORG123", nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/trust/graph?format=svg", nil)
+	w := httptest.NewRecorder()
+	handler.HandleGetGraph(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", w.Code)
+	}
+}
+
+func TestTrustCache_HitAndFresh(t *testing.T) {
+	store, cleanup := setupTrustTestStore(t)
+	defer cleanup()
+	storeChainCredentials(t, store)
+
+	handler := NewTrustHandler(store, "EORG123", nil)
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	cacheStatus := func(path string) string {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d: %s", path, w.Code, w.Body.String())
+		}
+		return w.Header().Get("X-Trust-Cache")
+	}
+
+	if got := cacheStatus("/api/v1/trust/scores"); got != "miss" {
+		t.Errorf("first request: cache %q, want miss", got)
+	}
+	if got := cacheStatus("/api/v1/trust/scores"); got != "hit" {
+		t.Errorf("identical request: cache %q, want hit", got)
+	}
+	if got := cacheStatus("/api/v1/trust/graph"); got != "hit" {
+		t.Errorf("graph after scores: cache %q, want hit", got)
+	}
+	if got := cacheStatus("/api/v1/trust/scores?fresh=true"); got != "miss" {
+		t.Errorf("fresh request: cache %q, want miss", got)
+	}
+
+	// The computed scores are persisted with the credential set version
+	node, err := store.GetTrustNode(context.Background(), "EUSER2")
+	if err != nil {
+		t.Fatalf("expected EUSER2 in trust graph cache: %v", err)
+	}
+	if node.Version == "" || node.Depth != 2 {
+		t.Errorf("unexpected cached node: %+v", node)
+	}
+
+	// A new credential changes the credential set
+	store.StoreCredential(context.Background(), &anystore.CachedCredential{
+		ID: "ESAID004", IssuerAID: "EORG123", SubjectAID: "EUSER3",
+		SchemaID: "EMatouMembershipSchemaV1", CachedAt: time.Now(),
+	})
+	if got := cacheStatus("/api/v1/trust/scores"); got != "miss" {
+		t.Errorf("after credential change: cache %q, want miss", got)
+	}
+}
+
+func TestTrustCache_DecayedScoresExpire(t *testing.T) {
+	store, cleanup := setupTrustTestStore(t)
+	defer cleanup()
+	storeChainCredentials(t, store)
+
+	handler := NewTrustHandler(store, "EORG123", nil)
+	ctx := context.Background()
+
+	if _, _, err := handler.snapshot(ctx, false); err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	handler.cached.computedAt = time.Now().Add(-2 * decayedScoreTTL)
+	if _, hit, _ := handler.snapshot(ctx, false); !hit {
+		t.Error("without decay, an old snapshot should still be a cache hit")
+	}
+
+	handler.SetDecayHalfLife(30 * 24 * time.Hour)
+	if _, hit, _ := handler.snapshot(ctx, false); hit {
+		t.Error("expected a miss after the weights changed")
+	}
+	if _, hit, _ := handler.snapshot(ctx, false); !hit {
+		t.Error("expected a hit for decayed scores within the TTL")
+	}
+	handler.cached.computedAt = time.Now().Add(-decayedScoreTTL)
+	if _, hit, _ := handler.snapshot(ctx, false); hit {
+		t.Error("expected decayed scores past the TTL to be recomputed")
+	}
+}
@@ -0,0 +1,252 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/matou-dao/backend/internal/anystore"
+	"github.com/matou-dao/backend/internal/anysync"
+)
+
+// Retry schedule for queued writes: the delay doubles from outboxBaseBackoff
+// after each failed attempt, up to outboxMaxBackoff.
+const (
+	outboxBaseBackoff = 5 * time.Second
+	outboxMaxBackoff  = 10 * time.Minute
+)
+
+// DefaultOutboxMaxAttempts is how many times a queued write is tried before
+// it is dead-lettered.
+const DefaultOutboxMaxAttempts = 10
+
+// OutboxStore persists queued writes. *anystore.LocalStore implements it.
+type OutboxStore interface {
+	SaveOutboxEntry(ctx context.Context, entry *anystore.OutboxEntry) error
+	ListOutboxEntries(ctx context.Context) ([]*anystore.OutboxEntry, error)
+	DeleteOutboxEntry(ctx context.Context, id string) error
+}
+
+// outboxEvent is the SSE event stored with a queued write. SSEEvent doesn't
+// serialise its recipients, so they are kept alongside.
+type outboxEvent struct {
+	Type       string          `json:"type"`
+	Data       json.RawMessage `json:"data"`
+	Recipients []string        `json:"recipients,omitempty"`
+}
+
+// OutboxDepth counts the writes in the outbox.
+type OutboxDepth struct {
+	Pending      int `json:"pending"`
+	DeadLettered int `json:"deadLettered"`
+}
+
+// WriteOutbox keeps object-tree writes that failed, so a transient tree-node
+// outage doesn't lose them. Queued writes are retried with backoff by the
+// worker (see StartWorker); once one lands its SSE event is broadcast. After
+// maxAttempts failures a write is dead-lettered: it stays in the store for
+// inspection but is no longer retried.
+//
+// Only writes that create an object (payload version 1) are queued. Edits
+// and deletes are versioned writes of an existing object: replayed later,
+// they could overwrite a newer version written in the meantime, so Enqueue
+// refuses them and their handlers report the failure to the caller instead.
+type WriteOutbox struct {
+	store        OutboxStore
+	spaceManager *anysync.SpaceManager
+	eventBroker  *EventBroker
+	listener     *anysync.TreeUpdateListener
+	maxAttempts  int
+
+	mu sync.Mutex // serialises retry passes
+
+	loop backgroundLoop // retry worker (see StartWorker)
+}
+
+// NewWriteOutbox creates an outbox that keeps queued writes in store.
+func NewWriteOutbox(store OutboxStore, spaceManager *anysync.SpaceManager, eventBroker *EventBroker) *WriteOutbox {
+	return &WriteOutbox{
+		store:        store,
+		spaceManager: spaceManager,
+		eventBroker:  eventBroker,
+		maxAttempts:  DefaultOutboxMaxAttempts,
+	}
+}
+
+// SetTreeListener registers replayed writes with the chat index listener, as
+// the handlers do for writes that land first time.
+func (o *WriteOutbox) SetTreeListener(l *anysync.TreeUpdateListener) {
+	o.listener = l
+}
+
+// SetMaxAttempts sets how many attempts a write gets before it is dead-lettered.
+func (o *WriteOutbox) SetMaxAttempts(n int) {
+	o.maxAttempts = n
+}
+
+// Enqueue queues a write to spaceID that failed with writeErr, signed by the
+// space's key set when it is retried. event, if set, is broadcast once the
+// write lands. A nil outbox, or a payload past version 1, queues nothing and
+// returns an error, so callers fall back to reporting the failure.
+func (o *WriteOutbox) Enqueue(ctx context.Context, spaceID string, payload *anysync.ObjectPayload, event *SSEEvent, writeErr error) error {
+	if o == nil {
+		return fmt.Errorf("write outbox not configured")
+	}
+	if payload.Version > 1 {
+		return fmt.Errorf("cannot queue %s: only creations are queued, not version %d", payload.ID, payload.Version)
+	}
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshaling payload: %w", err)
+	}
+
+	now := time.Now().UTC()
+	entry := &anystore.OutboxEntry{
+		ID:            payload.ID,
+		SpaceID:       spaceID,
+		Payload:       string(payloadBytes),
+		KeyRef:        spaceID,
+		Attempts:      1,
+		CreatedAt:     now,
+		NextAttemptAt: now.Add(outboxBackoff(1)),
+	}
+	if writeErr != nil {
+		entry.LastError = writeErr.Error()
+	}
+	if event != nil {
+		data, err := json.Marshal(event.Data)
+		if err != nil {
+			return fmt.Errorf("marshaling event: %w", err)
+		}
+		eventBytes, err := json.Marshal(outboxEvent{Type: event.Type, Data: data, Recipients: event.Recipients})
+		if err != nil {
+			return fmt.Errorf("marshaling event: %w", err)
+		}
+		entry.Event = string(eventBytes)
+	}
+
+	if err := o.store.SaveOutboxEntry(ctx, entry); err != nil {
+		return err
+	}
+	log.Printf("[Outbox] Queued %s write %s to space %s after failure: %v", payload.Type, payload.ID, spaceID, writeErr)
+	return nil
+}
+
+// outboxBackoff returns the delay before the next attempt of a write that has
+// failed attempts times.
+func outboxBackoff(attempts int) time.Duration {
+	delay := outboxBaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= outboxMaxBackoff {
+			return outboxMaxBackoff
+		}
+	}
+	return delay
+}
+
+// RetryDue retries the queued writes whose next attempt is due at now, and
+// returns how many landed.
+func (o *WriteOutbox) RetryDue(ctx context.Context, now time.Time) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	entries, err := o.store.ListOutboxEntries(ctx)
+	if err != nil {
+		log.Printf("[Outbox] Failed to list queued writes: %v", err)
+		return 0
+	}
+
+	written := 0
+	for _, entry := range entries {
+		if entry.DeadAt != nil || entry.NextAttemptAt.After(now) {
+			continue
+		}
+		if err := o.replay(ctx, entry); err != nil {
+			entry.Attempts++
+			entry.LastError = err.Error()
+			entry.NextAttemptAt = now.Add(outboxBackoff(entry.Attempts))
+			if entry.Attempts >= o.maxAttempts {
+				deadAt := now.UTC()
+				entry.DeadAt = &deadAt
+				log.Printf("[Outbox] Dead-lettered write %s after %d attempts: %v", entry.ID, entry.Attempts, err)
+			}
+			if err := o.store.SaveOutboxEntry(ctx, entry); err != nil {
+				log.Printf("[Outbox] Failed to update queued write %s: %v", entry.ID, err)
+			}
+			continue
+		}
+
+		if err := o.store.DeleteOutboxEntry(ctx, entry.ID); err != nil {
+			log.Printf("[Outbox] Failed to remove landed write %s: %v", entry.ID, err)
+		}
+		written++
+		log.Printf("[Outbox] Wrote %s to space %s after %d failed attempts", entry.ID, entry.SpaceID, entry.Attempts)
+	}
+	return written
+}
+
+// replay writes a queued entry to its space and broadcasts its event.
+func (o *WriteOutbox) replay(ctx context.Context, entry *anystore.OutboxEntry) error {
+	var payload anysync.ObjectPayload
+	if err := json.Unmarshal([]byte(entry.Payload), &payload); err != nil {
+		return fmt.Errorf("invalid queued payload: %w", err)
+	}
+	client := o.spaceManager.GetClient()
+	if client == nil {
+		return fmt.Errorf("any-sync client not available")
+	}
+	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), entry.KeyRef, client.GetSigningKey())
+	if err != nil {
+		return fmt.Errorf("loading space keys: %w", err)
+	}
+	if _, err := o.spaceManager.ObjectTreeManager().AddObject(ctx, entry.SpaceID, &payload, keys.SigningKey); err != nil {
+		return err
+	}
+
+	if o.listener != nil {
+		o.listener.RegisterObject(&payload)
+	}
+	if entry.Event != "" && o.eventBroker != nil {
+		var event outboxEvent
+		if err := json.Unmarshal([]byte(entry.Event), &event); err == nil {
+			var data interface{}
+			json.Unmarshal(event.Data, &data)
+			o.eventBroker.Broadcast(SSEEvent{Type: event.Type, Data: data, Recipients: event.Recipients})
+		}
+	}
+	return nil
+}
+
+// Depth counts the pending and dead-lettered writes in the outbox.
+func (o *WriteOutbox) Depth(ctx context.Context) (OutboxDepth, error) {
+	var depth OutboxDepth
+	entries, err := o.store.ListOutboxEntries(ctx)
+	if err != nil {
+		return depth, err
+	}
+	for _, entry := range entries {
+		if entry.DeadAt != nil {
+			depth.DeadLettered++
+		} else {
+			depth.Pending++
+		}
+	}
+	return depth, nil
+}
+
+// StartWorker begins a background loop that retries due writes every interval.
+func (o *WriteOutbox) StartWorker(interval time.Duration) {
+	o.loop.start(interval, func(ctx context.Context, now time.Time) {
+		o.RetryDue(ctx, now)
+	})
+	log.Printf("[Outbox] Started write retry worker (interval=%s)", interval)
+}
+
+// StopWorker stops the loop started by StartWorker.
+func (o *WriteOutbox) StopWorker() {
+	o.loop.stop()
+}
@@ -0,0 +1,253 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/anyproto/any-sync/commonspace/object/tree/objecttree"
+	"github.com/matou-dao/backend/internal/anystore"
+	"github.com/matou-dao/backend/internal/anysync"
+	"go.uber.org/mock/gomock"
+)
+
+// flakyTree fails AddContent while down is set, like a tree node outage.
+type flakyTree struct {
+	objecttree.ObjectTree
+	down *atomic.Bool
+}
+
+func (t *flakyTree) AddContent(ctx context.Context, content objecttree.SignableChangeContent) (objecttree.AddResult, error) {
+	if t.down.Load() {
+		return objecttree.AddResult{}, errors.New("tree node unavailable")
+	}
+	return t.ObjectTree.AddContent(ctx, content)
+}
+
+func (t *flakyTree) Delete() error { return nil }
+
+func TestWriteOutbox_RetriesFailedMessage(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+	ctx := context.Background()
+
+	store, err := anystore.NewLocalStore(anystore.DefaultConfig(t.TempDir()))
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer store.Close()
+	outbox := NewWriteOutbox(store, env.spaceManager, env.eventBroker)
+	env.chatHandler.SetWriteOutbox(outbox)
+
+	channelID := createTestChannel(t, env, "outage")
+
+	// Trees created from here on fail to write until the node is back
+	var down atomic.Bool
+	down.Store(true)
+	ctrl := gomock.NewController(t)
+	treeSeq := 0
+	env.spaceManager.TreeManager().SetTestTreeFactory(env.spaceManager.GetCommunitySpaceID(), func(objectID string) objecttree.ObjectTree {
+		treeSeq++
+		tree := setupStatefulMock(ctrl, &statefulMockTree{})
+		tree.EXPECT().Id().Return(fmt.Sprintf("flaky-tree-%d-%s", treeSeq, objectID)).AnyTimes()
+		tree.EXPECT().Header().Return(nil).AnyTimes()
+		return &flakyTree{ObjectTree: tree, down: &down}
+	})
+
+	events := env.eventBroker.SubscribeAs(env.userIdentity.GetAID())
+	defer env.eventBroker.Unsubscribe(events)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/chat/channels/"+channelID+"/messages", bytes.NewBufferString(`{"content":"sent during the outage"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	env.mux.ServeHTTP(w, req)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var sent struct {
+		MessageID string `json:"messageId"`
+		Queued    bool   `json:"queued"`
+	}
+	json.NewDecoder(w.Body).Decode(&sent)
+	if !sent.Queued || sent.MessageID == "" {
+		t.Fatalf("expected the message to be queued, got %+v", sent)
+	}
+
+	entries, err := store.ListOutboxEntries(ctx)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected one queued write, got %d (%v)", len(entries), err)
+	}
+	entry := entries[0]
+	if entry.ID != sent.MessageID || entry.SpaceID != env.spaceManager.GetCommunitySpaceID() || entry.KeyRef == "" || entry.Attempts != 1 || entry.LastError == "" {
+		t.Errorf("unexpected outbox entry %+v", entry)
+	}
+	select {
+	case event := <-events:
+		t.Fatalf("expected no event before the write lands, got %s", event.Type)
+	default:
+	}
+
+	// Still down: the retry fails and is pushed back
+	if written := outbox.RetryDue(ctx, entry.NextAttemptAt); written != 0 {
+		t.Fatalf("expected no writes while the node is down, got %d", written)
+	}
+	entries, _ = store.ListOutboxEntries(ctx)
+	if len(entries) != 1 || entries[0].Attempts != 2 || !strings.Contains(entries[0].LastError, "tree node unavailable") || !entries[0].NextAttemptAt.After(entry.NextAttemptAt) {
+		t.Fatalf("expected a second attempt with backoff, got %+v", entries)
+	}
+	if depth, _ := outbox.Depth(ctx); depth.Pending != 1 || depth.DeadLettered != 0 {
+		t.Errorf("expected depth 1 pending, got %+v", depth)
+	}
+
+	// Not due yet: nothing is retried
+	down.Store(false)
+	if written := outbox.RetryDue(ctx, entry.NextAttemptAt); written != 0 {
+		t.Errorf("expected the write to wait for its backoff, got %d written", written)
+	}
+	if written := outbox.RetryDue(ctx, entries[0].NextAttemptAt); written != 1 {
+		t.Fatalf("expected the retry to land, got %d written", written)
+	}
+
+	if entries, _ := store.ListOutboxEntries(ctx); len(entries) != 0 {
+		t.Errorf("expected the outbox to be empty, got %d entries", len(entries))
+	}
+	if ids := listTestMessageIDs(t, env, channelID); len(ids) != 1 || ids[0] != sent.MessageID {
+		t.Errorf("expected the message in the channel, got %v", ids)
+	}
+	select {
+	case event := <-events:
+		if event.Type != "chat:message:new" {
+			t.Errorf("expected chat:message:new, got %s", event.Type)
+		}
+	case <-time.After(time.Second):
+		t.Error("expected the message event once the write landed")
+	}
+}
+
+func TestWriteOutbox_DeadLetter(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+	ctx := context.Background()
+
+	store, err := anystore.NewLocalStore(anystore.DefaultConfig(t.TempDir()))
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer store.Close()
+	outbox := NewWriteOutbox(store, env.spaceManager, env.eventBroker)
+	outbox.SetMaxAttempts(2)
+
+	// A write the tree can never accept
+	now := time.Now()
+	if err := store.SaveOutboxEntry(ctx, &anystore.OutboxEntry{
+		ID:            "ChatMessage-bad",
+		SpaceID:       env.spaceManager.GetCommunitySpaceID(),
+		Payload:       `{"id":"ChatMessage-bad","type":"ChatMessage","data":"not an object","version":1}`,
+		KeyRef:        env.spaceManager.GetCommunitySpaceID(),
+		Attempts:      1,
+		CreatedAt:     now,
+		NextAttemptAt: now,
+	}); err != nil {
+		t.Fatalf("queueing write: %v", err)
+	}
+
+	outbox.RetryDue(ctx, now)
+	entries, _ := store.ListOutboxEntries(ctx)
+	if len(entries) != 1 || entries[0].DeadAt == nil {
+		t.Fatalf("expected the write dead-lettered after 2 attempts, got %+v", entries)
+	}
+	if depth, _ := outbox.Depth(ctx); depth.Pending != 0 || depth.DeadLettered != 1 {
+		t.Errorf("expected depth 1 dead-lettered, got %+v", depth)
+	}
+
+	// Dead letters are kept but not retried
+	outbox.RetryDue(ctx, now.Add(time.Hour))
+	if entries, _ := store.ListOutboxEntries(ctx); len(entries) != 1 || entries[0].Attempts != 2 {
+		t.Errorf("expected the dead letter left alone, got %+v", entries)
+	}
+}
+
+func TestWriteOutbox_RejectsEdits(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+
+	store, err := anystore.NewLocalStore(anystore.DefaultConfig(t.TempDir()))
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer store.Close()
+	outbox := NewWriteOutbox(store, env.spaceManager, env.eventBroker)
+
+	edit := &anysync.ObjectPayload{ID: "msg-edited", Type: "ChatMessage", Version: 2}
+	if err := outbox.Enqueue(t.Context(), env.spaceManager.GetCommunitySpaceID(), edit, nil, errors.New("tree node unavailable")); err == nil {
+		t.Fatal("expected an edit to be refused")
+	}
+	if depth, _ := outbox.Depth(t.Context()); depth.Pending != 0 {
+		t.Errorf("pending = %d, want 0", depth.Pending)
+	}
+}
+
+func TestCreateObject_FailureDropsTree(t *testing.T) {
+	env := setupChatTestEnv(t)
+	defer env.cleanup()
+	spaceID := env.spaceManager.GetCommunitySpaceID()
+
+	var down atomic.Bool
+	down.Store(true)
+	ctrl := gomock.NewController(t)
+	treeSeq := 0
+	utm := env.spaceManager.TreeManager()
+	utm.SetTestTreeFactory(spaceID, func(objectID string) objecttree.ObjectTree {
+		treeSeq++
+		tree := setupStatefulMock(ctrl, &statefulMockTree{})
+		tree.EXPECT().Id().Return(fmt.Sprintf("flaky-tree-%d-%s", treeSeq, objectID)).AnyTimes()
+		tree.EXPECT().Header().Return(nil).AnyTimes()
+		return &flakyTree{ObjectTree: tree, down: &down}
+	})
+
+	client := env.spaceManager.GetClient()
+	keys, err := anysync.LoadOrCreateSpaceKeySet(client.GetDataDir(), spaceID, client.GetSigningKey())
+	if err != nil {
+		t.Fatalf("loading keys: %v", err)
+	}
+	objMgr := env.spaceManager.ObjectTreeManager()
+	fields := map[string]json.RawMessage{"content": json.RawMessage(`"hello"`)}
+
+	if _, _, err := objMgr.CreateObject(t.Context(), spaceID, "msg-create-fail", "ChatMessage", anysync.ChatTreeType, fields, keys.SigningKey); err == nil {
+		t.Fatal("expected CreateObject to fail while the node is down")
+	}
+	if treeID := utm.GetTreeIDForObject("msg-create-fail"); treeID != "" {
+		t.Fatalf("empty tree %s left behind after the failed create", treeID)
+	}
+
+	// The retry creates the object afresh
+	down.Store(false)
+	treeID, _, err := objMgr.CreateObject(t.Context(), spaceID, "msg-create-fail", "ChatMessage", anysync.ChatTreeType, fields, keys.SigningKey)
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if got := utm.GetTreeIDForObject("msg-create-fail"); got != treeID {
+		t.Errorf("object tree = %q, want the retry's %q", got, treeID)
+	}
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	for attempts, want := range map[int]time.Duration{
+		1:  outboxBaseBackoff,
+		2:  2 * outboxBaseBackoff,
+		4:  8 * outboxBaseBackoff,
+		50: outboxMaxBackoff,
+	} {
+		if got := outboxBackoff(attempts); got != want {
+			t.Errorf("outboxBackoff(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
@@ -2,8 +2,12 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -19,19 +23,116 @@ type SMTPConfig struct {
 	RelayURL    string `yaml:"relayUrl"` // Config server URL for email relay (production)
 }
 
-// Config represents the complete application configuration
+// Config represents the complete application configuration.
+// Watch hot-reloads Server.CORSOrigins, Server.RequestLog and Trust; other
+// fields are read once at startup and need a restart to change.
 type Config struct {
 	Server    ServerConfig    `yaml:"server"`
 	KERI      KERIConfig      `yaml:"keri"`
 	AnySync   AnySyncConfig   `yaml:"anysync"`
 	Bootstrap BootstrapConfig `yaml:"bootstrap"`
 	SMTP      SMTPConfig      `yaml:"smtp"`
+	Trust     TrustConfig     `yaml:"trust"`
+	Files     FilesConfig     `yaml:"files"`
+	Content   ContentConfig   `yaml:"content"`
+	Features  FeaturesConfig  `yaml:"features"`
+	Invites   InvitesConfig   `yaml:"invites"`
 }
 
 // ServerConfig holds HTTP server configuration
 type ServerConfig struct {
 	Host string `yaml:"host"`
 	Port int    `yaml:"port"`
+	// SSEHeartbeatSeconds is the interval between SSE keep-alive comments
+	SSEHeartbeatSeconds int `yaml:"sseHeartbeatSeconds"`
+	// SSEBufferSize is how many undelivered events each SSE client can have
+	// queued before SSEOverflowPolicy applies
+	SSEBufferSize int `yaml:"sseBufferSize"`
+	// SSEOverflowPolicy is "drop-oldest" (drop the client's oldest queued
+	// event and send it a resync hint) or "disconnect"
+	SSEOverflowPolicy string `yaml:"sseOverflowPolicy"`
+	// SSEMaxClients caps concurrent SSE streams; further connections get a
+	// 503. 0 is unlimited.
+	SSEMaxClients int `yaml:"sseMaxClients"`
+	// SSEMaxClientsPerAID caps the streams one AID (or, for anonymous
+	// streams, one remote host) can hold open; further connections get a
+	// 429. 0 is unlimited.
+	SSEMaxClientsPerAID int `yaml:"sseMaxClientsPerAid"`
+	// SSEEvictIdle admits a connection at SSEMaxClients by disconnecting the
+	// stream that has gone longest without an event instead
+	SSEEvictIdle bool `yaml:"sseEvictIdle"`
+	// CORSOrigins are origins allowed in addition to the built-in ones
+	// (hot-reloadable)
+	CORSOrigins []string `yaml:"corsOrigins"`
+	// RequestLog enables per-request logging (hot-reloadable)
+	RequestLog bool `yaml:"requestLog"`
+	// DataDir is the base directory for all local state (MATOU_DATA_DIR).
+	// Empty uses ./data, or ./data-test in test mode.
+	DataDir string `yaml:"dataDir"`
+}
+
+// FilesConfig holds file upload limits
+type FilesConfig struct {
+	// MaxSizeMB is the largest file that can be uploaded, in megabytes
+	// (0 uses the 20 MB default)
+	MaxSizeMB int `yaml:"maxSizeMb"`
+	// AllowedContentTypes restricts uploads to these media types; entries
+	// may end in "/*" (e.g. "image/*"). Empty allows any type.
+	AllowedContentTypes []string `yaml:"allowedContentTypes"`
+	// ThumbnailMaxDimension bounds the width and height of thumbnails
+	// generated for uploaded images, in pixels. 0 disables thumbnails.
+	ThumbnailMaxDimension int `yaml:"thumbnailMaxDimension"`
+}
+
+// ContentConfig holds size limits for user-written text, counted in UTF-8
+// bytes. 0 uses the default for that limit.
+type ContentConfig struct {
+	// MaxMessageBytes bounds a chat message's content
+	MaxMessageBytes int `yaml:"maxMessageBytes"`
+	// MaxNoticeBodyBytes bounds a notice's body
+	MaxNoticeBodyBytes int `yaml:"maxNoticeBodyBytes"`
+	// MaxCommentBytes bounds a notice comment's text
+	MaxCommentBytes int `yaml:"maxCommentBytes"`
+}
+
+// FeaturesConfig turns optional features on or off for the deployment.
+// A disabled feature's routes answer 404; community admins can also turn
+// features off in the admin settings, but not back on.
+type FeaturesConfig struct {
+	Chat    bool `yaml:"chat"`
+	Notices bool `yaml:"notices"`
+	Trust   bool `yaml:"trust"`
+}
+
+// InvitesConfig holds community invite and join settings
+type InvitesConfig struct {
+	// RateLimitPerHour caps the invite requests each admin can make per hour
+	// (MATOU_INVITE_RATE_LIMIT). 0 disables the limit.
+	RateLimitPerHour int `yaml:"rateLimitPerHour"`
+	// JoinSyncTimeoutSeconds bounds how long joining the community waits for
+	// the initial sync (MATOU_JOIN_SYNC_TIMEOUT, a duration such as "45s").
+	JoinSyncTimeoutSeconds int `yaml:"joinSyncTimeoutSeconds"`
+	// AllowUnsigned accepts the legacy unsigned invite form
+	// (MATOU_ALLOW_UNSIGNED_INVITES). Turn it off once every client signs invites.
+	AllowUnsigned bool `yaml:"allowUnsigned"`
+}
+
+// TrustConfig holds trust score configuration (hot-reloadable)
+type TrustConfig struct {
+	// DecayHalfLifeDays is the credential age in days at which its score
+	// contribution halves. 0 disables time decay.
+	DecayHalfLifeDays int `yaml:"decayHalfLifeDays"`
+	// Weights are the trust score weights
+	Weights TrustWeightsConfig `yaml:"weights"`
+}
+
+// TrustWeightsConfig holds the trust score weights
+type TrustWeightsConfig struct {
+	IncomingCredential    float64 `yaml:"incomingCredential"`
+	UniqueIssuer          float64 `yaml:"uniqueIssuer"`
+	BidirectionalRelation float64 `yaml:"bidirectionalRelation"`
+	DepthPenalty          float64 `yaml:"depthPenalty"`
+	OrgIssuedBonus        float64 `yaml:"orgIssuedBonus"`
 }
 
 // KERIConfig holds KERI/KERIA connection configuration
@@ -39,12 +140,57 @@ type KERIConfig struct {
 	AdminURL string `yaml:"adminUrl"`
 	BootURL  string `yaml:"bootUrl"`
 	CESRURL  string `yaml:"cesrUrl"`
+	// WitnessThreshold is the number of valid witness receipts a KEL event
+	// needs to count as witnessed
+	WitnessThreshold int `yaml:"witnessThreshold"`
+	// OOBIHosts are the hosts ("host" or "host:port") issuer OOBIs may be
+	// resolved from. Defaults to the host of CESRURL.
+	OOBIHosts []string `yaml:"oobiHosts"`
 }
 
 // AnySyncConfig holds any-sync connection configuration
 type AnySyncConfig struct {
 	ClientConfigPath string `yaml:"clientConfigPath"`
 	NetworkID        string `yaml:"networkId"`
+	// PerIdentityDirs keeps each identity's peer key and space data in its
+	// own subdirectory of the data dir (MATOU_PER_IDENTITY_DIRS=1), so
+	// identities or test instances sharing a data dir can't clobber each
+	// other's files. Off by default, since existing data lives at the top
+	// of the data dir.
+	PerIdentityDirs bool `yaml:"perIdentityDirs"`
+	// CoordinatorProbeSeconds is the interval between the coordinator
+	// reachability checks that put the node in read-only mode
+	// (MATOU_COORDINATOR_PROBE_SECONDS). 0 uses the default, 15.
+	CoordinatorProbeSeconds int `yaml:"coordinatorProbeSeconds"`
+	// SyncStallSeconds is how long a space may hold unacknowledged local
+	// changes without sync progress before it is reported as stalled
+	// (MATOU_SYNC_STALL_THRESHOLD, a duration such as "10m"). 0 uses the
+	// default, 5 minutes.
+	SyncStallSeconds int `yaml:"syncStallSeconds"`
+	// Tuning adjusts sync and network parameters for constrained or
+	// high-latency networks
+	Tuning AnySyncTuningConfig `yaml:"tuning"`
+}
+
+// AnySyncTuningConfig holds any-sync sync and network parameters. 0 uses the
+// default for that parameter.
+type AnySyncTuningConfig struct {
+	// GCTTLSeconds is how long an unused space stays open (default 60)
+	GCTTLSeconds int `yaml:"gcTtlSeconds"`
+	// SyncPeriodSeconds is the interval between head syncs (default 5)
+	SyncPeriodSeconds int `yaml:"syncPeriodSeconds"`
+	// SendQueueSize is the per-stream send queue length (default 100)
+	SendQueueSize int `yaml:"sendQueueSize"`
+	// DialQueueWorkers is how many peers are dialed at once (default 4)
+	DialQueueWorkers int `yaml:"dialQueueWorkers"`
+	// DialQueueSize is how many dials can be queued (default 100)
+	DialQueueSize int `yaml:"dialQueueSize"`
+	// DialTimeoutSeconds bounds connecting to a peer (default 10)
+	DialTimeoutSeconds int `yaml:"dialTimeoutSeconds"`
+	// WriteTimeoutSeconds bounds a connection write (default 10)
+	WriteTimeoutSeconds int `yaml:"writeTimeoutSeconds"`
+	// KeepAlivePeriodSeconds is the yamux keep-alive interval (default 30)
+	KeepAlivePeriodSeconds int `yaml:"keepAlivePeriodSeconds"`
 }
 
 // BootstrapConfig holds bootstrap identity information
@@ -98,32 +244,10 @@ type AccessControlConfig struct {
 	Issuer string `yaml:"issuer"`
 }
 
-// Load reads configuration from files and environment.
+// Load reads configuration from files and environment and validates it.
 // bootstrapPath is now optional - org config is loaded from dataDir/org-config.yaml.
 func Load(configPath, bootstrapPath string) (*Config, error) {
-	cfg := &Config{
-		// Default values
-		Server: ServerConfig{
-			Host: "localhost",
-			Port: 8080,
-		},
-		KERI: KERIConfig{
-			AdminURL: "http://localhost:3901",
-			BootURL:  "http://localhost:3903",
-			CESRURL:  "http://localhost:3902",
-		},
-		AnySync: AnySyncConfig{
-			ClientConfigPath: "config/client.yml",
-		},
-		SMTP: SMTPConfig{
-			Host:        "localhost",
-			Port:        2525,
-			From:        "invites@matou.nz",
-			FromName:    "MATOU",
-			LogoURL:     "https://i.imgur.com/zi01gTx.png",
-			TextLogoURL: "https://i.imgur.com/1D3iLWa.png",
-		},
-	}
+	cfg := defaults()
 
 	// Load main config if exists
 	if configPath != "" {
@@ -141,6 +265,74 @@ func Load(configPath, bootstrapPath string) (*Config, error) {
 		}
 	}
 
+	applyEnv(cfg)
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// defaults returns the configuration used when no config file sets a value
+func defaults() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Host:                "localhost",
+			Port:                8080,
+			SSEHeartbeatSeconds: 30,
+			SSEBufferSize:       16,
+			SSEOverflowPolicy:   "drop-oldest",
+			SSEMaxClientsPerAID: 10,
+		},
+		KERI: KERIConfig{
+			AdminURL:         "http://localhost:3901",
+			BootURL:          "http://localhost:3903",
+			CESRURL:          "http://localhost:3902",
+			WitnessThreshold: 1,
+		},
+		AnySync: AnySyncConfig{
+			ClientConfigPath: "config/client.yml",
+		},
+		SMTP: SMTPConfig{
+			Host:        "localhost",
+			Port:        2525,
+			From:        "invites@matou.nz",
+			FromName:    "MATOU",
+			LogoURL:     "https://i.imgur.com/zi01gTx.png",
+			TextLogoURL: "https://i.imgur.com/1D3iLWa.png",
+		},
+		Files: FilesConfig{
+			MaxSizeMB:             20,
+			ThumbnailMaxDimension: 320,
+		},
+		Content: ContentConfig{
+			MaxMessageBytes:    16 << 10,
+			MaxNoticeBodyBytes: 64 << 10,
+			MaxCommentBytes:    2000,
+		},
+		Features: FeaturesConfig{
+			Chat:    true,
+			Notices: true,
+			Trust:   true,
+		},
+		Invites: InvitesConfig{
+			RateLimitPerHour:       20,
+			JoinSyncTimeoutSeconds: 30,
+			AllowUnsigned:          true,
+		},
+		Trust: TrustConfig{
+			Weights: TrustWeightsConfig{
+				IncomingCredential:    1.0,
+				UniqueIssuer:          2.0,
+				BidirectionalRelation: 3.0,
+				DepthPenalty:          0.1,
+				OrgIssuedBonus:        2.0,
+			},
+		},
+	}
+}
+
+// applyEnv applies MATOU_* environment variable overrides
+func applyEnv(cfg *Config) {
 	// Apply SMTP env var overrides
 	if host := os.Getenv("MATOU_SMTP_HOST"); host != "" {
 		cfg.SMTP.Host = host
@@ -154,7 +346,97 @@ func Load(configPath, bootstrapPath string) (*Config, error) {
 		cfg.SMTP.RelayURL = relayURL
 	}
 
-	return cfg, nil
+	// Apply server env var overrides
+	if secsStr := os.Getenv("MATOU_SSE_HEARTBEAT_SECONDS"); secsStr != "" {
+		if secs, err := strconv.Atoi(secsStr); err == nil && secs > 0 {
+			cfg.Server.SSEHeartbeatSeconds = secs
+		}
+	}
+	if os.Getenv("MATOU_REQUEST_LOG") == "1" {
+		cfg.Server.RequestLog = true
+	}
+	if dataDir := os.Getenv("MATOU_DATA_DIR"); dataDir != "" {
+		cfg.Server.DataDir = dataDir
+	}
+
+	// Apply any-sync env var overrides
+	if os.Getenv("MATOU_PER_IDENTITY_DIRS") == "1" {
+		cfg.AnySync.PerIdentityDirs = true
+	}
+	if secsStr := os.Getenv("MATOU_COORDINATOR_PROBE_SECONDS"); secsStr != "" {
+		if secs, err := strconv.Atoi(secsStr); err == nil && secs > 0 {
+			cfg.AnySync.CoordinatorProbeSeconds = secs
+		}
+	}
+	if thresholdStr := os.Getenv("MATOU_SYNC_STALL_THRESHOLD"); thresholdStr != "" {
+		if threshold, err := time.ParseDuration(thresholdStr); err == nil && threshold >= time.Second {
+			cfg.AnySync.SyncStallSeconds = int(threshold / time.Second)
+		}
+	}
+
+	// Apply KERI env var overrides
+	if thresholdStr := os.Getenv("MATOU_WITNESS_THRESHOLD"); thresholdStr != "" {
+		if threshold, err := strconv.Atoi(thresholdStr); err == nil && threshold > 0 {
+			cfg.KERI.WitnessThreshold = threshold
+		}
+	}
+	if hosts := os.Getenv("MATOU_OOBI_HOSTS"); hosts != "" {
+		cfg.KERI.OOBIHosts = nil
+		for _, h := range strings.Split(hosts, ",") {
+			if h = strings.TrimSpace(h); h != "" {
+				cfg.KERI.OOBIHosts = append(cfg.KERI.OOBIHosts, h)
+			}
+		}
+	}
+	if len(cfg.KERI.OOBIHosts) == 0 {
+		if u, err := url.Parse(cfg.KERI.CESRURL); err == nil && u.Host != "" {
+			cfg.KERI.OOBIHosts = []string{u.Host}
+		}
+	}
+
+	// Apply file upload env var overrides
+	if sizeStr := os.Getenv("MATOU_FILES_MAX_SIZE_MB"); sizeStr != "" {
+		if size, err := strconv.Atoi(sizeStr); err == nil && size > 0 {
+			cfg.Files.MaxSizeMB = size
+		}
+	}
+	if dimStr := os.Getenv("MATOU_FILES_THUMBNAIL_MAX_DIMENSION"); dimStr != "" {
+		if dim, err := strconv.Atoi(dimStr); err == nil && dim >= 0 {
+			cfg.Files.ThumbnailMaxDimension = dim
+		}
+	}
+	if types := os.Getenv("MATOU_FILES_ALLOWED_TYPES"); types != "" {
+		cfg.Files.AllowedContentTypes = nil
+		for _, t := range strings.Split(types, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				cfg.Files.AllowedContentTypes = append(cfg.Files.AllowedContentTypes, t)
+			}
+		}
+	}
+
+	// Apply invite env var overrides
+	if limitStr := os.Getenv("MATOU_INVITE_RATE_LIMIT"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit >= 0 {
+			cfg.Invites.RateLimitPerHour = limit
+		}
+	}
+	if timeoutStr := os.Getenv("MATOU_JOIN_SYNC_TIMEOUT"); timeoutStr != "" {
+		if timeout, err := time.ParseDuration(timeoutStr); err == nil && timeout >= time.Second {
+			cfg.Invites.JoinSyncTimeoutSeconds = int(timeout / time.Second)
+		}
+	}
+	if allowStr := os.Getenv("MATOU_ALLOW_UNSIGNED_INVITES"); allowStr != "" {
+		if allow, err := strconv.ParseBool(allowStr); err == nil {
+			cfg.Invites.AllowUnsigned = allow
+		}
+	}
+
+	// Apply trust env var overrides
+	if daysStr := os.Getenv("MATOU_TRUST_DECAY_HALF_LIFE_DAYS"); daysStr != "" {
+		if days, err := strconv.Atoi(daysStr); err == nil && days >= 0 {
+			cfg.Trust.DecayHalfLifeDays = days
+		}
+	}
 }
 
 // loadYAML loads a YAML file into a struct
@@ -171,13 +453,174 @@ func loadYAML(path string, target interface{}) error {
 	return nil
 }
 
-// Validate checks that all required configuration is present
+// ValidationError lists every problem found in a configuration
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString("invalid configuration:")
+	for _, problem := range e.Problems {
+		b.WriteString("\n  - ")
+		b.WriteString(problem)
+	}
+	return b.String()
+}
+
+// Validate checks that all required configuration is present and returns a
+// *ValidationError listing every problem. Organization fields are only
+// required once an organization is configured; before that the org is set up
+// through the frontend.
 func (c *Config) Validate() error {
+	var problems []string
+
+	if c.Server.Host == "" {
+		problems = append(problems, "server.host is required")
+	}
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		problems = append(problems, fmt.Sprintf("server.port must be between 1 and 65535, got %d", c.Server.Port))
+	}
+
 	// Validate KERI URLs
 	if c.KERI.AdminURL == "" {
-		return fmt.Errorf("KERI admin URL is required")
+		problems = append(problems, "keri.adminUrl is required")
+	}
+	for i, host := range c.KERI.OOBIHosts {
+		if host == "" || strings.ContainsAny(host, "/?#@") {
+			problems = append(problems, fmt.Sprintf("keri.oobiHosts[%d] must be a host or host:port, got %q", i, host))
+		}
+	}
+
+	org := c.Bootstrap.Organization
+	if org.Name != "" || org.AID != "" {
+		if org.Name == "" {
+			problems = append(problems, "bootstrap.organization.name is required when the organization AID is set")
+		}
+		if org.AID == "" {
+			problems = append(problems, "bootstrap.organization.aid is required when the organization name is set")
+		}
+	}
+	for i, admin := range c.Bootstrap.Admins {
+		if admin.AID == "" {
+			problems = append(problems, fmt.Sprintf("bootstrap.admins[%d].aid is required", i))
+		}
+	}
+
+	if c.Server.SSEBufferSize < 0 {
+		problems = append(problems, fmt.Sprintf("server.sseBufferSize must not be negative, got %d", c.Server.SSEBufferSize))
+	}
+	if c.Server.SSEMaxClients < 0 {
+		problems = append(problems, fmt.Sprintf("server.sseMaxClients must not be negative, got %d", c.Server.SSEMaxClients))
+	}
+	if c.Server.SSEMaxClientsPerAID < 0 {
+		problems = append(problems, fmt.Sprintf("server.sseMaxClientsPerAid must not be negative, got %d", c.Server.SSEMaxClientsPerAID))
+	}
+	switch c.Server.SSEOverflowPolicy {
+	case "", "drop-oldest", "disconnect":
+	default:
+		problems = append(problems, fmt.Sprintf("server.sseOverflowPolicy must be \"drop-oldest\" or \"disconnect\", got %q", c.Server.SSEOverflowPolicy))
+	}
+
+	for _, origin := range c.Server.CORSOrigins {
+		u, err := url.Parse(origin)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("server.corsOrigins: %q is not an origin like https://host[:port]", origin))
+		}
 	}
 
+	if c.Files.MaxSizeMB < 0 {
+		problems = append(problems, fmt.Sprintf("files.maxSizeMb must not be negative, got %d", c.Files.MaxSizeMB))
+	}
+	if c.Files.ThumbnailMaxDimension < 0 {
+		problems = append(problems, fmt.Sprintf("files.thumbnailMaxDimension must not be negative, got %d", c.Files.ThumbnailMaxDimension))
+	}
+	for _, contentType := range c.Files.AllowedContentTypes {
+		if family, subtype, ok := strings.Cut(contentType, "/"); !ok || family == "" || subtype == "" {
+			problems = append(problems, fmt.Sprintf("files.allowedContentTypes: %q is not a media type like image/png or image/*", contentType))
+		}
+	}
+
+	if c.Content.MaxMessageBytes < 0 {
+		problems = append(problems, fmt.Sprintf("content.maxMessageBytes must not be negative, got %d", c.Content.MaxMessageBytes))
+	}
+	if c.Content.MaxNoticeBodyBytes < 0 {
+		problems = append(problems, fmt.Sprintf("content.maxNoticeBodyBytes must not be negative, got %d", c.Content.MaxNoticeBodyBytes))
+	}
+	if c.Content.MaxCommentBytes < 0 {
+		problems = append(problems, fmt.Sprintf("content.maxCommentBytes must not be negative, got %d", c.Content.MaxCommentBytes))
+	}
+
+	if c.AnySync.CoordinatorProbeSeconds < 0 || c.AnySync.CoordinatorProbeSeconds > 3600 {
+		problems = append(problems, fmt.Sprintf("anysync.coordinatorProbeSeconds must be between 1 and 3600 (or 0 for the default), got %d", c.AnySync.CoordinatorProbeSeconds))
+	}
+	if c.AnySync.SyncStallSeconds < 0 || c.AnySync.SyncStallSeconds > 86400 {
+		problems = append(problems, fmt.Sprintf("anysync.syncStallSeconds must be between 1 and 86400 (or 0 for the default), got %d", c.AnySync.SyncStallSeconds))
+	}
+
+	t := c.AnySync.Tuning
+	for _, p := range []struct {
+		name     string
+		value    int
+		min, max int
+	}{
+		{"gcTtlSeconds", t.GCTTLSeconds, 1, 86400},
+		{"syncPeriodSeconds", t.SyncPeriodSeconds, 1, 3600},
+		{"sendQueueSize", t.SendQueueSize, 1, 100000},
+		{"dialQueueWorkers", t.DialQueueWorkers, 1, 256},
+		{"dialQueueSize", t.DialQueueSize, 1, 100000},
+		{"dialTimeoutSeconds", t.DialTimeoutSeconds, 1, 600},
+		{"writeTimeoutSeconds", t.WriteTimeoutSeconds, 1, 600},
+		{"keepAlivePeriodSeconds", t.KeepAlivePeriodSeconds, 1, 3600},
+	} {
+		if p.value != 0 && (p.value < p.min || p.value > p.max) {
+			problems = append(problems, fmt.Sprintf("anysync.tuning.%s must be between %d and %d (or 0 for the default), got %d", p.name, p.min, p.max, p.value))
+		}
+	}
+
+	if c.Invites.RateLimitPerHour < 0 {
+		problems = append(problems, fmt.Sprintf("invites.rateLimitPerHour must not be negative, got %d", c.Invites.RateLimitPerHour))
+	}
+	if c.Invites.JoinSyncTimeoutSeconds < 0 || c.Invites.JoinSyncTimeoutSeconds > 3600 {
+		problems = append(problems, fmt.Sprintf("invites.joinSyncTimeoutSeconds must be between 1 and 3600 (or 0 for the default), got %d", c.Invites.JoinSyncTimeoutSeconds))
+	}
+
+	if c.Trust.DecayHalfLifeDays < 0 {
+		problems = append(problems, "trust.decayHalfLifeDays must not be negative")
+	}
+	w := c.Trust.Weights
+	for _, weight := range []float64{w.IncomingCredential, w.UniqueIssuer, w.BidirectionalRelation, w.DepthPenalty, w.OrgIssuedBonus} {
+		if weight < 0 {
+			problems = append(problems, "trust.weights must not be negative")
+			break
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// CheckCoordinator checks that the any-sync coordinator at address accepts
+// TCP connections, so an unreachable network is reported at startup rather
+// than on first space creation. QUIC addresses (quic://) can't be probed
+// without a handshake and are not checked.
+func CheckCoordinator(address string, timeout time.Duration) error {
+	if address == "" {
+		return &ValidationError{Problems: []string{"no coordinator node in the any-sync client config"}}
+	}
+	if strings.HasPrefix(address, "quic://") {
+		return nil
+	}
+
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return &ValidationError{Problems: []string{
+			fmt.Sprintf("any-sync coordinator %s is unreachable (%v) — check that the any-sync network is running and the client config (MATOU_ANYSYNC_CONFIG) is for it", address, err),
+		}}
+	}
+	conn.Close()
 	return nil
 }
 
@@ -1,7 +1,14 @@
 package config
 
 import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestConfigValidation(t *testing.T) {
@@ -20,6 +27,10 @@ func TestConfigValidation(t *testing.T) {
 			},
 			// Admin AID is optional - set later when admin creates identity
 		},
+		Server: ServerConfig{
+			Host: "localhost",
+			Port: 8080,
+		},
 		KERI: KERIConfig{
 			AdminURL: "http://localhost:3901",
 		},
@@ -29,3 +40,210 @@ func TestConfigValidation(t *testing.T) {
 		t.Errorf("Expected valid config, got error: %v", err)
 	}
 }
+
+// validationProblems returns the problems reported by cfg.Validate
+func validationProblems(t *testing.T, cfg *Config) []string {
+	t.Helper()
+	err := cfg.Validate()
+	if err == nil {
+		return nil
+	}
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *ValidationError, got %T: %v", err, err)
+	}
+	return verr.Problems
+}
+
+func TestConfigValidation_MissingFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   string
+	}{
+		{"server host", func(c *Config) { c.Server.Host = "" }, "server.host"},
+		{"server port", func(c *Config) { c.Server.Port = 0 }, "server.port"},
+		{"server port out of range", func(c *Config) { c.Server.Port = 70000 }, "server.port"},
+		{"KERI admin URL", func(c *Config) { c.KERI.AdminURL = "" }, "keri.adminUrl"},
+		{"org name", func(c *Config) { c.Bootstrap.Organization.AID = "EORG" }, "bootstrap.organization.name"},
+		{"org AID", func(c *Config) { c.Bootstrap.Organization.Name = "MATOU" }, "bootstrap.organization.aid"},
+		{"admin AID", func(c *Config) { c.Bootstrap.Admins = []AdminInfo{{Name: "Admin"}} }, "bootstrap.admins[0].aid"},
+		{"file size limit", func(c *Config) { c.Files.MaxSizeMB = -1 }, "files.maxSizeMb"},
+		{"file content type", func(c *Config) { c.Files.AllowedContentTypes = []string{"image"} }, "files.allowedContentTypes"},
+		{"message size limit", func(c *Config) { c.Content.MaxMessageBytes = -1 }, "content.maxMessageBytes"},
+		{"SSE overflow policy", func(c *Config) { c.Server.SSEOverflowPolicy = "block" }, "server.sseOverflowPolicy"},
+		{"SSE max clients", func(c *Config) { c.Server.SSEMaxClients = -1 }, "server.sseMaxClients"},
+		{"negative sync period", func(c *Config) { c.AnySync.Tuning.SyncPeriodSeconds = -1 }, "anysync.tuning.syncPeriodSeconds"},
+		{"sync period too long", func(c *Config) { c.AnySync.Tuning.SyncPeriodSeconds = 7200 }, "anysync.tuning.syncPeriodSeconds"},
+		{"dial queue workers", func(c *Config) { c.AnySync.Tuning.DialQueueWorkers = 1000 }, "anysync.tuning.dialQueueWorkers"},
+		{"dial timeout", func(c *Config) { c.AnySync.Tuning.DialTimeoutSeconds = -5 }, "anysync.tuning.dialTimeoutSeconds"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaults()
+			tt.modify(cfg)
+			problems := validationProblems(t, cfg)
+			if len(problems) != 1 || !strings.Contains(problems[0], tt.want) {
+				t.Errorf("problems = %q, want one mentioning %s", problems, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigValidation_AggregatesProblems(t *testing.T) {
+	cfg := defaults()
+	cfg.Server.Host = ""
+	cfg.Server.Port = -1
+	cfg.KERI.AdminURL = ""
+	cfg.Bootstrap.Organization.AID = "EORG"
+
+	problems := validationProblems(t, cfg)
+	if len(problems) != 4 {
+		t.Fatalf("expected 4 problems, got %d: %q", len(problems), problems)
+	}
+	msg := cfg.Validate().Error()
+	for _, field := range []string{"server.host", "server.port", "keri.adminUrl", "bootstrap.organization.name"} {
+		if !strings.Contains(msg, field) {
+			t.Errorf("error %q does not mention %s", msg, field)
+		}
+	}
+}
+
+func TestLoad_Validates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("server:\n  port: 0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path, ""); err == nil {
+		t.Error("expected Load to reject an invalid config")
+	}
+}
+
+func TestLoad_Features(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("features:\n  chat: false\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path, "")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if want := (FeaturesConfig{Chat: false, Notices: true, Trust: true}); cfg.Features != want {
+		t.Errorf("features = %+v, want %+v (unset features stay on)", cfg.Features, want)
+	}
+}
+
+func TestLoad_OOBIHosts(t *testing.T) {
+	cfg, err := Load("", "")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if want := []string{"localhost:3902"}; !reflect.DeepEqual(cfg.KERI.OOBIHosts, want) {
+		t.Errorf("default oobiHosts = %q, want %q (the CESR URL host)", cfg.KERI.OOBIHosts, want)
+	}
+
+	t.Setenv("MATOU_OOBI_HOSTS", "witness1.example:5642, witness2.example")
+	if cfg, err = Load("", ""); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if want := []string{"witness1.example:5642", "witness2.example"}; !reflect.DeepEqual(cfg.KERI.OOBIHosts, want) {
+		t.Errorf("oobiHosts = %q, want %q", cfg.KERI.OOBIHosts, want)
+	}
+
+	t.Setenv("MATOU_OOBI_HOSTS", "http://witness.example")
+	if _, err := Load("", ""); err == nil {
+		t.Error("expected Load to reject an OOBI host with a scheme")
+	}
+}
+
+func TestLoad_SyncStallThreshold(t *testing.T) {
+	t.Setenv("MATOU_SYNC_STALL_THRESHOLD", "10m")
+	cfg, err := Load("", "")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.AnySync.SyncStallSeconds != 600 {
+		t.Errorf("syncStallSeconds = %d, want 600", cfg.AnySync.SyncStallSeconds)
+	}
+
+	cfg.AnySync.SyncStallSeconds = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected a negative syncStallSeconds to fail validation")
+	}
+}
+
+func TestLoad_InviteRateLimit(t *testing.T) {
+	cfg, err := Load("", "")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Invites.RateLimitPerHour != 20 {
+		t.Errorf("default rateLimitPerHour = %d, want 20", cfg.Invites.RateLimitPerHour)
+	}
+
+	t.Setenv("MATOU_INVITE_RATE_LIMIT", "0")
+	if cfg, err = Load("", ""); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Invites.RateLimitPerHour != 0 {
+		t.Errorf("rateLimitPerHour = %d, want 0 (disabled)", cfg.Invites.RateLimitPerHour)
+	}
+
+	cfg.Invites.RateLimitPerHour = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected a negative rateLimitPerHour to fail validation")
+	}
+}
+
+func TestLoad_JoinSettings(t *testing.T) {
+	cfg, err := Load("", "")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Invites.JoinSyncTimeoutSeconds != 30 || !cfg.Invites.AllowUnsigned {
+		t.Errorf("defaults = %ds, allowUnsigned %v; want 30s, true", cfg.Invites.JoinSyncTimeoutSeconds, cfg.Invites.AllowUnsigned)
+	}
+
+	t.Setenv("MATOU_JOIN_SYNC_TIMEOUT", "45s")
+	t.Setenv("MATOU_ALLOW_UNSIGNED_INVITES", "false")
+	if cfg, err = Load("", ""); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Invites.JoinSyncTimeoutSeconds != 45 || cfg.Invites.AllowUnsigned {
+		t.Errorf("got %ds, allowUnsigned %v; want 45s, false", cfg.Invites.JoinSyncTimeoutSeconds, cfg.Invites.AllowUnsigned)
+	}
+
+	// Unparseable values keep the default
+	t.Setenv("MATOU_JOIN_SYNC_TIMEOUT", "soon")
+	if cfg, err = Load("", ""); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Invites.JoinSyncTimeoutSeconds != 30 {
+		t.Errorf("joinSyncTimeoutSeconds = %d, want the default 30", cfg.Invites.JoinSyncTimeoutSeconds)
+	}
+
+	cfg.Invites.JoinSyncTimeoutSeconds = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected a negative joinSyncTimeoutSeconds to fail validation")
+	}
+}
+
+func TestCheckCoordinator(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+
+	if err := CheckCoordinator(addr, time.Second); err != nil {
+		t.Errorf("expected reachable coordinator, got %v", err)
+	}
+
+	ln.Close()
+	if err := CheckCoordinator(addr, time.Second); err == nil {
+		t.Error("expected error for unreachable coordinator")
+	}
+	if err := CheckCoordinator("", time.Second); err == nil {
+		t.Error("expected error for missing coordinator")
+	}
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce is how long Watch waits after the last change to the file
+// before reloading, so a file written in several steps is reloaded once
+const reloadDebounce = 100 * time.Millisecond
+
+// Watcher hot-reloads a config file (see Watch)
+type Watcher struct {
+	path     string
+	onReload func(*Config)
+	current  atomic.Pointer[Config]
+	loaded   *Config // Last valid config read from path
+	fsw      *fsnotify.Watcher
+	done     chan struct{}
+}
+
+// Watch monitors configPath and reloads it when it changes. Each reload is
+// validated, then the hot-reloadable fields (Server.CORSOrigins,
+// Server.RequestLog and Trust) are swapped into a copy of the current config,
+// starting from initial, and onReload is called with the result. Changes to
+// other fields are logged as needing a restart. Invalid files are logged and
+// ignored.
+func Watch(configPath string, initial *Config, onReload func(*Config)) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("creating file watcher: %w", err)
+	}
+	// Watch the directory: editors often replace the file instead of writing it
+	path := filepath.Clean(configPath)
+	if err := fsw.Add(filepath.Dir(path)); err != nil {
+		fsw.Close()
+		return nil, fmt.Errorf("watching %s: %w", configPath, err)
+	}
+
+	w := &Watcher{
+		path:     path,
+		onReload: onReload,
+		fsw:      fsw,
+		done:     make(chan struct{}),
+	}
+	w.current.Store(initial)
+	w.loaded, _ = w.read()
+
+	go w.run()
+	return w, nil
+}
+
+// Current returns the current config. The returned config must not be modified.
+func (w *Watcher) Current() *Config {
+	return w.current.Load()
+}
+
+// Close stops watching the file
+func (w *Watcher) Close() error {
+	err := w.fsw.Close()
+	<-w.done
+	return err
+}
+
+func (w *Watcher) run() {
+	defer close(w.done)
+
+	timer := time.NewTimer(reloadDebounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			if event.Name == w.path && event.Has(fsnotify.Write|fsnotify.Create) {
+				timer.Reset(reloadDebounce)
+			}
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("[Config] Watch error for %s: %v\n", w.path, err)
+		case <-timer.C:
+			w.reload()
+		}
+	}
+}
+
+// reload reads the file and applies its hot-reloadable fields
+func (w *Watcher) reload() {
+	loaded, err := w.read()
+	if err != nil {
+		log.Printf("[Config] Ignoring change to %s: %v\n", w.path, err)
+		return
+	}
+
+	if w.loaded != nil {
+		for _, field := range restartFields(w.loaded, loaded) {
+			log.Printf("[Config] %s changed in %s — restart to apply\n", field, w.path)
+		}
+	}
+	w.loaded = loaded
+
+	next := *w.current.Load()
+	next.Server.CORSOrigins = loaded.Server.CORSOrigins
+	next.Server.RequestLog = loaded.Server.RequestLog
+	next.Trust = loaded.Trust
+	w.current.Store(&next)
+
+	log.Printf("[Config] Reloaded %s\n", w.path)
+	if w.onReload != nil {
+		w.onReload(&next)
+	}
+}
+
+// read loads and validates the file the same way Load does
+func (w *Watcher) read() (*Config, error) {
+	cfg := defaults()
+	if err := loadYAML(w.path, cfg); err != nil {
+		return nil, err
+	}
+	applyEnv(cfg)
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// restartFields names the fields that differ between two loaded configs and
+// only take effect on restart
+func restartFields(old, next *Config) []string {
+	checks := []struct {
+		name string
+		same bool
+	}{
+		{"server.host", old.Server.Host == next.Server.Host},
+		{"server.port", old.Server.Port == next.Server.Port},
+		{"server.sseHeartbeatSeconds", old.Server.SSEHeartbeatSeconds == next.Server.SSEHeartbeatSeconds},
+		{"server.sseBufferSize", old.Server.SSEBufferSize == next.Server.SSEBufferSize},
+		{"server.sseOverflowPolicy", old.Server.SSEOverflowPolicy == next.Server.SSEOverflowPolicy},
+		{"server.sseMaxClients", old.Server.SSEMaxClients == next.Server.SSEMaxClients},
+		{"server.sseMaxClientsPerAid", old.Server.SSEMaxClientsPerAID == next.Server.SSEMaxClientsPerAID},
+		{"server.sseEvictIdle", old.Server.SSEEvictIdle == next.Server.SSEEvictIdle},
+		{"server.dataDir", old.Server.DataDir == next.Server.DataDir},
+		{"keri", reflect.DeepEqual(old.KERI, next.KERI)},
+		{"anysync", old.AnySync == next.AnySync},
+		{"smtp", old.SMTP == next.SMTP},
+		{"files", reflect.DeepEqual(old.Files, next.Files)},
+		{"features", old.Features == next.Features},
+		{"content", old.Content == next.Content},
+		{"invites", old.Invites == next.Invites},
+		{"bootstrap", reflect.DeepEqual(old.Bootstrap, next.Bootstrap)},
+	}
+
+	var changed []string
+	for _, check := range checks {
+		if !check.same {
+			changed = append(changed, check.name)
+		}
+	}
+	return changed
+}
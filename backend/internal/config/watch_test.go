@@ -0,0 +1,132 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, path, contents string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("writing config: %v", err)
+	}
+}
+
+func TestWatch_ReloadsHotFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfigFile(t, path, `
+server:
+  port: 8080
+  corsOrigins: ["https://old.example"]
+trust:
+  decayHalfLifeDays: 30
+`)
+
+	initial, err := Load(path, "")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	reloaded := make(chan *Config, 4)
+	w, err := Watch(path, initial, func(cfg *Config) { reloaded <- cfg })
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	defer w.Close()
+
+	// An invalid file is ignored, and the next valid one applied
+	writeConfigFile(t, path, `
+trust:
+  decayHalfLifeDays: -1
+`)
+	time.Sleep(2 * reloadDebounce)
+	writeConfigFile(t, path, `
+server:
+  port: 9999
+  corsOrigins: ["https://new.example"]
+  requestLog: true
+trust:
+  decayHalfLifeDays: 7
+  weights:
+    uniqueIssuer: 5
+`)
+
+	var cfg *Config
+	select {
+	case cfg = <-reloaded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reload")
+	}
+
+	if !slices.Equal(cfg.Server.CORSOrigins, []string{"https://new.example"}) {
+		t.Errorf("CORSOrigins = %v, want [https://new.example]", cfg.Server.CORSOrigins)
+	}
+	if !cfg.Server.RequestLog {
+		t.Error("expected RequestLog to be reloaded")
+	}
+	if cfg.Trust.DecayHalfLifeDays != 7 || cfg.Trust.Weights.UniqueIssuer != 5 {
+		t.Errorf("Trust = %+v, want half-life 7 and uniqueIssuer 5", cfg.Trust)
+	}
+	if cfg.Trust.Weights.IncomingCredential != 1.0 {
+		t.Errorf("IncomingCredential = %v, want default 1.0", cfg.Trust.Weights.IncomingCredential)
+	}
+	if cfg.Server.Port != 8080 {
+		t.Errorf("Port = %d, want 8080 (not hot-reloadable)", cfg.Server.Port)
+	}
+	if w.Current() != cfg {
+		t.Error("expected Current to return the reloaded config")
+	}
+	if initial.Trust.DecayHalfLifeDays != 30 {
+		t.Error("expected the initial config not to be modified")
+	}
+}
+
+func TestRestartFields(t *testing.T) {
+	tests := []struct {
+		field  string
+		modify func(*Config)
+	}{
+		{"server.port", func(c *Config) { c.Server.Port = 9999 }},
+		{"server.sseMaxClientsPerAid", func(c *Config) { c.Server.SSEMaxClientsPerAID = 3 }},
+		{"content", func(c *Config) { c.Content.MaxMessageBytes = 1024 }},
+		{"invites", func(c *Config) { c.Invites.RateLimitPerHour = 5 }},
+		{"invites", func(c *Config) { c.Invites.AllowUnsigned = false }},
+	}
+	for _, tt := range tests {
+		old, next := defaults(), defaults()
+		tt.modify(next)
+		if got := restartFields(old, next); !slices.Equal(got, []string{tt.field}) {
+			t.Errorf("restartFields = %v, want [%s]", got, tt.field)
+		}
+	}
+
+	// Hot-reloaded fields don't need a restart
+	old, next := defaults(), defaults()
+	next.Server.RequestLog = !old.Server.RequestLog
+	next.Trust.DecayHalfLifeDays = 7
+	if got := restartFields(old, next); len(got) != 0 {
+		t.Errorf("expected no restart fields for hot changes, got %v", got)
+	}
+}
+
+func TestConfigValidation_HotFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{"invalid CORS origin", func(c *Config) { c.Server.CORSOrigins = []string{"not-a-url"} }},
+		{"negative half-life", func(c *Config) { c.Trust.DecayHalfLifeDays = -1 }},
+		{"negative weight", func(c *Config) { c.Trust.Weights.DepthPenalty = -0.5 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaults()
+			tt.modify(cfg)
+			if err := cfg.Validate(); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+}
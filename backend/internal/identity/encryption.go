@@ -0,0 +1,91 @@
+package identity
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// scrypt parameters for deriving the mnemonic encryption key
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+	saltSize     = 16
+)
+
+var (
+	// ErrLocked is returned when the mnemonic is needed but the identity is
+	// passphrase-protected and hasn't been unlocked.
+	ErrLocked = errors.New("identity is locked")
+	// ErrWrongPassphrase is returned when a passphrase doesn't decrypt the
+	// stored mnemonic.
+	ErrWrongPassphrase = errors.New("incorrect passphrase")
+)
+
+// encryptedMnemonic is a mnemonic encrypted with AES-256-GCM under a key
+// derived from a passphrase with scrypt. Byte fields are base64 in JSON.
+type encryptedMnemonic struct {
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+}
+
+// encryptMnemonic encrypts mnemonic under passphrase with a fresh salt and nonce.
+func encryptMnemonic(mnemonic, passphrase string) (*encryptedMnemonic, error) {
+	enc := &encryptedMnemonic{
+		Salt: make([]byte, saltSize),
+		N:    scryptN,
+		R:    scryptR,
+		P:    scryptP,
+	}
+	if _, err := rand.Read(enc.Salt); err != nil {
+		return nil, fmt.Errorf("generating salt: %w", err)
+	}
+
+	gcm, err := enc.cipher(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	enc.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(enc.Nonce); err != nil {
+		return nil, fmt.Errorf("generating nonce: %w", err)
+	}
+	enc.Ciphertext = gcm.Seal(nil, enc.Nonce, []byte(mnemonic), nil)
+	return enc, nil
+}
+
+// decrypt returns the mnemonic, or ErrWrongPassphrase if passphrase doesn't
+// authenticate the ciphertext.
+func (e *encryptedMnemonic) decrypt(passphrase string) (string, error) {
+	gcm, err := e.cipher(passphrase)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := gcm.Open(nil, e.Nonce, e.Ciphertext, nil)
+	if err != nil {
+		return "", ErrWrongPassphrase
+	}
+	return string(plaintext), nil
+}
+
+// cipher derives the AES-GCM cipher for passphrase using the stored parameters.
+func (e *encryptedMnemonic) cipher(passphrase string) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), e.Salt, e.N, e.R, e.P, scryptKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("deriving key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("creating cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
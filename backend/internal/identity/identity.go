@@ -1,68 +1,165 @@
-// Package identity manages the local user's identity in per-user mode.
-// The backend only operates on behalf of one user at a time.
+// Package identity manages the local user's identities in per-user mode.
+// The backend only operates on behalf of one identity at a time.
 package identity
 
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"sync"
 )
 
-// UserIdentity holds the local user's AID and mnemonic with thread-safe access.
-// It persists to {dataDir}/identity.json so it survives restarts.
+// UserIdentity holds the local user's identities (AID, mnemonic and runtime
+// config) with thread-safe access. Several identities can be stored, keyed by
+// AID, with one current at a time; getters and setters act on the current
+// identity. It persists to {dataDir}/identity.json so it survives restarts.
 type UserIdentity struct {
-	mu       sync.RWMutex
-	aid      string
-	mnemonic string
-	peerID   string
-	dataDir  string
-
-	// Runtime config fields (set by frontend after fetching org config)
-	orgAID                   string
-	communitySpaceID         string
-	communityReadOnlySpaceID string
-	adminSpaceID             string
-	privateSpaceID           string
+	mu         sync.RWMutex
+	identities map[string]*persistedIdentity
+	current    *persistedIdentity
+	dataDir    string
 }
 
-// persistedIdentity is the JSON structure written to disk.
+// persistedIdentity is the JSON structure of one identity written to disk.
+// When the mnemonic is passphrase-protected only EncryptedMnemonic is
+// written; Mnemonic then holds the decrypted phrase in memory while unlocked.
 type persistedIdentity struct {
 	AID                      string `json:"aid"`
-	Mnemonic                 string `json:"mnemonic"`
+	Mnemonic                 string `json:"mnemonic,omitempty"`
 	PeerID                   string `json:"peerId,omitempty"`
 	OrgAID                   string `json:"orgAid,omitempty"`
 	CommunitySpaceID         string `json:"communitySpaceId,omitempty"`
 	CommunityReadOnlySpaceID string `json:"communityReadOnlySpaceId,omitempty"`
 	AdminSpaceID             string `json:"adminSpaceId,omitempty"`
 	PrivateSpaceID           string `json:"privateSpaceId,omitempty"`
+	// BackupVerified is set once the user has confirmed their written-down
+	// mnemonic matches
+	BackupVerified bool `json:"backupVerified,omitempty"`
+
+	EncryptedMnemonic *encryptedMnemonic `json:"encryptedMnemonic,omitempty"`
+}
+
+// persistedIdentities is the identity file: all stored identities and the
+// current selection.
+type persistedIdentities struct {
+	Current    string              `json:"current"`
+	Identities []persistedIdentity `json:"identities"`
+}
+
+// Info describes a stored identity, without its mnemonic.
+type Info struct {
+	AID            string `json:"aid"`
+	PeerID         string `json:"peerId,omitempty"`
+	OrgAID         string `json:"orgAid,omitempty"`
+	PrivateSpaceID string `json:"privateSpaceId,omitempty"`
+	BackupVerified bool   `json:"backupVerified"`
+	Current        bool   `json:"current"`
 }
 
 // New creates a new UserIdentity bound to the given data directory.
 // If an identity file exists on disk, it is loaded automatically.
 func New(dataDir string) *UserIdentity {
-	ui := &UserIdentity{dataDir: dataDir}
+	ui := &UserIdentity{
+		identities: make(map[string]*persistedIdentity),
+		current:    &persistedIdentity{},
+		dataDir:    dataDir,
+	}
 	ui.load()
 	return ui
 }
 
-// SetIdentity sets the user's AID and mnemonic and persists to disk.
+// SetIdentity sets the user's AID and mnemonic, makes it the current
+// identity and persists to disk. A new AID is added alongside the stored
+// identities and inherits the current org config; a stored AID is selected
+// with its own config. The mnemonic is stored in plaintext; use
+// SetIdentityWithPassphrase to encrypt it.
 func (u *UserIdentity) SetIdentity(aid, mnemonic string) error {
+	return u.SetIdentityWithPassphrase(aid, mnemonic, "")
+}
+
+// SetIdentityWithPassphrase is SetIdentity with the mnemonic encrypted at
+// rest under passphrase. The identity starts unlocked. An empty passphrase
+// stores the mnemonic in plaintext.
+func (u *UserIdentity) SetIdentityWithPassphrase(aid, mnemonic, passphrase string) error {
+	var enc *encryptedMnemonic
+	if passphrase != "" {
+		var err error
+		if enc, err = encryptMnemonic(mnemonic, passphrase); err != nil {
+			return fmt.Errorf("encrypting mnemonic: %w", err)
+		}
+	}
+
 	u.mu.Lock()
 	defer u.mu.Unlock()
 
-	u.aid = aid
-	u.mnemonic = mnemonic
+	id, ok := u.identities[aid]
+	switch {
+	case ok:
+	case u.current.AID == "":
+		// First identity: keep any config set before it
+		id = u.current
+	default:
+		id = &persistedIdentity{
+			OrgAID:                   u.current.OrgAID,
+			CommunitySpaceID:         u.current.CommunitySpaceID,
+			CommunityReadOnlySpaceID: u.current.CommunityReadOnlySpaceID,
+			AdminSpaceID:             u.current.AdminSpaceID,
+		}
+	}
+	if id.Mnemonic != mnemonic {
+		id.BackupVerified = false
+	}
+	id.AID = aid
+	id.Mnemonic = mnemonic
+	id.EncryptedMnemonic = enc
+	u.identities[aid] = id
+	u.current = id
 	return u.persist()
 }
 
+// Switch makes a stored identity current and persists the selection.
+func (u *UserIdentity) Switch(aid string) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	id, ok := u.identities[aid]
+	if !ok {
+		return fmt.Errorf("identity %s not found", aid)
+	}
+	u.current = id
+	return u.persist()
+}
+
+// List returns the stored identities ordered by AID.
+func (u *UserIdentity) List() []Info {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	infos := make([]Info, 0, len(u.identities))
+	for _, id := range u.identities {
+		infos = append(infos, Info{
+			AID:            id.AID,
+			PeerID:         id.PeerID,
+			OrgAID:         id.OrgAID,
+			PrivateSpaceID: id.PrivateSpaceID,
+			BackupVerified: id.BackupVerified,
+			Current:        id == u.current,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].AID < infos[j].AID })
+	return infos
+}
+
 // SetPeerID stores the derived peer ID.
 func (u *UserIdentity) SetPeerID(peerID string) error {
 	u.mu.Lock()
 	defer u.mu.Unlock()
 
-	u.peerID = peerID
+	u.current.PeerID = peerID
 	return u.persist()
 }
 
@@ -71,8 +168,8 @@ func (u *UserIdentity) SetOrgConfig(orgAID, communitySpaceID string) error {
 	u.mu.Lock()
 	defer u.mu.Unlock()
 
-	u.orgAID = orgAID
-	u.communitySpaceID = communitySpaceID
+	u.current.OrgAID = orgAID
+	u.current.CommunitySpaceID = communitySpaceID
 	return u.persist()
 }
 
@@ -81,7 +178,7 @@ func (u *UserIdentity) SetPrivateSpaceID(spaceID string) error {
 	u.mu.Lock()
 	defer u.mu.Unlock()
 
-	u.privateSpaceID = spaceID
+	u.current.PrivateSpaceID = spaceID
 	return u.persist()
 }
 
@@ -89,42 +186,42 @@ func (u *UserIdentity) SetPrivateSpaceID(spaceID string) error {
 func (u *UserIdentity) GetAID() string {
 	u.mu.RLock()
 	defer u.mu.RUnlock()
-	return u.aid
+	return u.current.AID
 }
 
-// GetMnemonic returns the stored mnemonic (empty if not configured).
+// GetMnemonic returns the stored mnemonic (empty if not configured or locked).
 func (u *UserIdentity) GetMnemonic() string {
 	u.mu.RLock()
 	defer u.mu.RUnlock()
-	return u.mnemonic
+	return u.current.Mnemonic
 }
 
 // GetPeerID returns the stored peer ID.
 func (u *UserIdentity) GetPeerID() string {
 	u.mu.RLock()
 	defer u.mu.RUnlock()
-	return u.peerID
+	return u.current.PeerID
 }
 
 // GetOrgAID returns the org AID from runtime config.
 func (u *UserIdentity) GetOrgAID() string {
 	u.mu.RLock()
 	defer u.mu.RUnlock()
-	return u.orgAID
+	return u.current.OrgAID
 }
 
 // GetCommunitySpaceID returns the community space ID from runtime config.
 func (u *UserIdentity) GetCommunitySpaceID() string {
 	u.mu.RLock()
 	defer u.mu.RUnlock()
-	return u.communitySpaceID
+	return u.current.CommunitySpaceID
 }
 
 // GetCommunityReadOnlySpaceID returns the community read-only space ID.
 func (u *UserIdentity) GetCommunityReadOnlySpaceID() string {
 	u.mu.RLock()
 	defer u.mu.RUnlock()
-	return u.communityReadOnlySpaceID
+	return u.current.CommunityReadOnlySpaceID
 }
 
 // SetCommunityReadOnlySpaceID stores the community read-only space ID.
@@ -132,7 +229,7 @@ func (u *UserIdentity) SetCommunityReadOnlySpaceID(spaceID string) error {
 	u.mu.Lock()
 	defer u.mu.Unlock()
 
-	u.communityReadOnlySpaceID = spaceID
+	u.current.CommunityReadOnlySpaceID = spaceID
 	return u.persist()
 }
 
@@ -140,7 +237,7 @@ func (u *UserIdentity) SetCommunityReadOnlySpaceID(spaceID string) error {
 func (u *UserIdentity) GetAdminSpaceID() string {
 	u.mu.RLock()
 	defer u.mu.RUnlock()
-	return u.adminSpaceID
+	return u.current.AdminSpaceID
 }
 
 // SetAdminSpaceID stores the admin space ID.
@@ -148,7 +245,7 @@ func (u *UserIdentity) SetAdminSpaceID(spaceID string) error {
 	u.mu.Lock()
 	defer u.mu.Unlock()
 
-	u.adminSpaceID = spaceID
+	u.current.AdminSpaceID = spaceID
 	return u.persist()
 }
 
@@ -156,29 +253,89 @@ func (u *UserIdentity) SetAdminSpaceID(spaceID string) error {
 func (u *UserIdentity) GetPrivateSpaceID() string {
 	u.mu.RLock()
 	defer u.mu.RUnlock()
-	return u.privateSpaceID
+	return u.current.PrivateSpaceID
+}
+
+// SetBackupVerified records that the user has verified their mnemonic backup.
+func (u *UserIdentity) SetBackupVerified() error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.current.BackupVerified = true
+	return u.persist()
+}
+
+// IsBackupVerified returns true if the current mnemonic's backup was verified.
+func (u *UserIdentity) IsBackupVerified() bool {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.current.BackupVerified
 }
 
 // IsConfigured returns true if an AID and mnemonic have been set.
 func (u *UserIdentity) IsConfigured() bool {
 	u.mu.RLock()
 	defer u.mu.RUnlock()
-	return u.aid != "" && u.mnemonic != ""
+	return u.current.AID != "" && (u.current.Mnemonic != "" || u.current.EncryptedMnemonic != nil)
 }
 
-// Clear removes the identity and deletes the persisted file.
+// IsEncrypted returns true if the current mnemonic is passphrase-protected.
+func (u *UserIdentity) IsEncrypted() bool {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.current.EncryptedMnemonic != nil
+}
+
+// IsLocked returns true if the current mnemonic is passphrase-protected and
+// not decrypted in memory.
+func (u *UserIdentity) IsLocked() bool {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.current.EncryptedMnemonic != nil && u.current.Mnemonic == ""
+}
+
+// Unlock decrypts the current mnemonic into memory. Returns
+// ErrWrongPassphrase if passphrase is incorrect.
+func (u *UserIdentity) Unlock(passphrase string) error {
+	u.mu.RLock()
+	enc := u.current.EncryptedMnemonic
+	u.mu.RUnlock()
+	if enc == nil {
+		return fmt.Errorf("identity is not passphrase-protected")
+	}
+
+	// Derive outside the lock, scrypt is deliberately slow
+	mnemonic, err := enc.decrypt(passphrase)
+	if err != nil {
+		return err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.current.EncryptedMnemonic == enc {
+		u.current.Mnemonic = mnemonic
+	}
+	return nil
+}
+
+// Lock discards the decrypted mnemonic of every passphrase-protected identity.
+func (u *UserIdentity) Lock() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for _, id := range u.identities {
+		if id.EncryptedMnemonic != nil {
+			id.Mnemonic = ""
+		}
+	}
+}
+
+// Clear removes all stored identities and deletes the persisted file.
 func (u *UserIdentity) Clear() error {
 	u.mu.Lock()
 	defer u.mu.Unlock()
 
-	u.aid = ""
-	u.mnemonic = ""
-	u.peerID = ""
-	u.orgAID = ""
-	u.communitySpaceID = ""
-	u.communityReadOnlySpaceID = ""
-	u.adminSpaceID = ""
-	u.privateSpaceID = ""
+	u.identities = make(map[string]*persistedIdentity)
+	u.current = &persistedIdentity{}
 
 	path := u.filePath()
 	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
@@ -194,16 +351,21 @@ func (u *UserIdentity) filePath() string {
 
 // persist writes the current state to disk. Caller must hold u.mu.
 func (u *UserIdentity) persist() error {
-	data := persistedIdentity{
-		AID:                      u.aid,
-		Mnemonic:                 u.mnemonic,
-		PeerID:                   u.peerID,
-		OrgAID:                   u.orgAID,
-		CommunitySpaceID:         u.communitySpaceID,
-		CommunityReadOnlySpaceID: u.communityReadOnlySpaceID,
-		AdminSpaceID:             u.adminSpaceID,
-		PrivateSpaceID:           u.privateSpaceID,
+	data := persistedIdentities{Current: u.current.AID}
+	ids := slices.Collect(maps.Values(u.identities))
+	if u.current.AID == "" {
+		// Config set before any identity
+		ids = append(ids, u.current)
+	}
+	for _, id := range ids {
+		record := *id
+		if record.EncryptedMnemonic != nil {
+			// Never write the decrypted phrase
+			record.Mnemonic = ""
+		}
+		data.Identities = append(data.Identities, record)
 	}
+	sort.Slice(data.Identities, func(i, j int) bool { return data.Identities[i].AID < data.Identities[j].AID })
 
 	bytes, err := json.MarshalIndent(data, "", "  ")
 	if err != nil {
@@ -221,26 +383,39 @@ func (u *UserIdentity) persist() error {
 	return nil
 }
 
-// load reads identity from disk if available. Does not return errors
-// because missing identity is normal (first boot).
+// load reads identities from disk if available. Does not return errors
+// because missing identity is normal (first boot). Files written before
+// multiple identities were supported hold a single identity at the top level.
 func (u *UserIdentity) load() {
 	bytes, err := os.ReadFile(u.filePath())
 	if err != nil {
 		return // File doesn't exist yet — normal for first boot
 	}
 
-	var data persistedIdentity
+	var data struct {
+		persistedIdentities
+		persistedIdentity
+	}
 	if err := json.Unmarshal(bytes, &data); err != nil {
 		fmt.Printf("Warning: failed to parse identity.json: %v\n", err)
 		return
 	}
 
-	u.aid = data.AID
-	u.mnemonic = data.Mnemonic
-	u.peerID = data.PeerID
-	u.orgAID = data.OrgAID
-	u.communitySpaceID = data.CommunitySpaceID
-	u.communityReadOnlySpaceID = data.CommunityReadOnlySpaceID
-	u.adminSpaceID = data.AdminSpaceID
-	u.privateSpaceID = data.PrivateSpaceID
+	identities := data.Identities
+	current := data.Current
+	if len(identities) == 0 {
+		identities = []persistedIdentity{data.persistedIdentity}
+		current = data.AID
+	}
+	for i := range identities {
+		id := &identities[i]
+		if id.AID == "" {
+			u.current = id
+			continue
+		}
+		u.identities[id.AID] = id
+	}
+	if id, ok := u.identities[current]; ok {
+		u.current = id
+	}
 }
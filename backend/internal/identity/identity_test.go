@@ -0,0 +1,231 @@
+package identity
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupTwoIdentities stores a personal and an org identity, each with its own
+// private space, leaving the org identity current.
+func setupTwoIdentities(t *testing.T, dir string) *UserIdentity {
+	t.Helper()
+	ui := New(dir)
+	if err := ui.SetIdentity("EPERSONAL", "personal-mnemonic"); err != nil {
+		t.Fatalf("SetIdentity: %v", err)
+	}
+	ui.SetOrgConfig("EORG", "space-community")
+	ui.SetPrivateSpaceID("space-personal")
+	if err := ui.SetIdentity("EORGIDENTITY", "org-mnemonic"); err != nil {
+		t.Fatalf("SetIdentity: %v", err)
+	}
+	ui.SetPrivateSpaceID("space-org")
+	return ui
+}
+
+func TestSwitch(t *testing.T) {
+	ui := setupTwoIdentities(t, t.TempDir())
+
+	if got := ui.GetAID(); got != "EORGIDENTITY" {
+		t.Errorf("GetAID = %q, want EORGIDENTITY", got)
+	}
+	if got := ui.GetPrivateSpaceID(); got != "space-org" {
+		t.Errorf("GetPrivateSpaceID = %q, want space-org", got)
+	}
+
+	if err := ui.Switch("EPERSONAL"); err != nil {
+		t.Fatalf("Switch: %v", err)
+	}
+	if got := ui.GetAID(); got != "EPERSONAL" {
+		t.Errorf("GetAID = %q, want EPERSONAL", got)
+	}
+	if got := ui.GetMnemonic(); got != "personal-mnemonic" {
+		t.Errorf("GetMnemonic = %q, want personal-mnemonic", got)
+	}
+	if got := ui.GetPrivateSpaceID(); got != "space-personal" {
+		t.Errorf("GetPrivateSpaceID = %q, want space-personal", got)
+	}
+
+	if err := ui.Switch("EUNKNOWN"); err == nil {
+		t.Error("expected switching to an unknown identity to fail")
+	}
+	if got := ui.GetAID(); got != "EPERSONAL" {
+		t.Errorf("failed switch changed current identity to %q", got)
+	}
+}
+
+func TestSetIdentity_NewIdentityInheritsOrgConfig(t *testing.T) {
+	ui := setupTwoIdentities(t, t.TempDir())
+
+	if got := ui.GetOrgAID(); got != "EORG" {
+		t.Errorf("GetOrgAID = %q, want EORG inherited", got)
+	}
+	if got := ui.GetCommunitySpaceID(); got != "space-community" {
+		t.Errorf("GetCommunitySpaceID = %q, want space-community inherited", got)
+	}
+
+	// Setting a stored AID again selects it with its own config
+	if err := ui.SetIdentity("EPERSONAL", "personal-mnemonic"); err != nil {
+		t.Fatalf("SetIdentity: %v", err)
+	}
+	if got := ui.GetPrivateSpaceID(); got != "space-personal" {
+		t.Errorf("GetPrivateSpaceID = %q, want space-personal", got)
+	}
+}
+
+func TestList(t *testing.T) {
+	ui := setupTwoIdentities(t, t.TempDir())
+
+	infos := ui.List()
+	if len(infos) != 2 {
+		t.Fatalf("expected 2 identities, got %d", len(infos))
+	}
+	if infos[0].AID != "EORGIDENTITY" || !infos[0].Current {
+		t.Errorf("infos[0] = %+v, want current EORGIDENTITY", infos[0])
+	}
+	if infos[1].AID != "EPERSONAL" || infos[1].Current || infos[1].PrivateSpaceID != "space-personal" {
+		t.Errorf("infos[1] = %+v, want non-current EPERSONAL", infos[1])
+	}
+}
+
+func TestPersistence(t *testing.T) {
+	dir := t.TempDir()
+	ui := setupTwoIdentities(t, dir)
+	ui.Switch("EPERSONAL")
+
+	reloaded := New(dir)
+	if got := reloaded.GetAID(); got != "EPERSONAL" {
+		t.Errorf("reloaded GetAID = %q, want EPERSONAL", got)
+	}
+	if got := len(reloaded.List()); got != 2 {
+		t.Errorf("reloaded %d identities, want 2", got)
+	}
+	if err := reloaded.Switch("EORGIDENTITY"); err != nil {
+		t.Fatalf("Switch after reload: %v", err)
+	}
+	if got := reloaded.GetPrivateSpaceID(); got != "space-org" {
+		t.Errorf("GetPrivateSpaceID = %q, want space-org", got)
+	}
+}
+
+func TestLoad_SingleIdentityFile(t *testing.T) {
+	dir := t.TempDir()
+	legacy := `{"aid": "ELEGACY", "mnemonic": "legacy-mnemonic", "privateSpaceId": "space-legacy"}`
+	if err := os.WriteFile(filepath.Join(dir, "identity.json"), []byte(legacy), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ui := New(dir)
+	if !ui.IsConfigured() || ui.GetAID() != "ELEGACY" {
+		t.Errorf("expected legacy identity to be current, got %q", ui.GetAID())
+	}
+	if got := ui.GetPrivateSpaceID(); got != "space-legacy" {
+		t.Errorf("GetPrivateSpaceID = %q, want space-legacy", got)
+	}
+}
+
+func TestClear(t *testing.T) {
+	dir := t.TempDir()
+	ui := setupTwoIdentities(t, dir)
+
+	if err := ui.Clear(); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+	if ui.IsConfigured() || len(ui.List()) != 0 {
+		t.Error("expected no identities after Clear")
+	}
+	if New(dir).IsConfigured() {
+		t.Error("expected cleared identities not to be reloaded")
+	}
+}
+
+func TestBackupVerified_ResetOnNewMnemonic(t *testing.T) {
+	dir := t.TempDir()
+	ui := New(dir)
+	ui.SetIdentity("EUSER", "first-mnemonic")
+	if err := ui.SetBackupVerified(); err != nil {
+		t.Fatalf("SetBackupVerified: %v", err)
+	}
+	if !New(dir).IsBackupVerified() {
+		t.Error("expected backupVerified to persist")
+	}
+
+	ui.SetIdentity("EUSER", "first-mnemonic")
+	if !ui.IsBackupVerified() {
+		t.Error("expected backupVerified to survive setting the same mnemonic")
+	}
+	ui.SetIdentity("EUSER", "second-mnemonic")
+	if ui.IsBackupVerified() {
+		t.Error("expected backupVerified to reset for a new mnemonic")
+	}
+}
+
+func TestPassphrase_LockUnlock(t *testing.T) {
+	dir := t.TempDir()
+	ui := New(dir)
+	if err := ui.SetIdentityWithPassphrase("EUSER", "secret-mnemonic", "correct horse"); err != nil {
+		t.Fatalf("SetIdentityWithPassphrase: %v", err)
+	}
+	if ui.IsLocked() || ui.GetMnemonic() != "secret-mnemonic" {
+		t.Error("expected identity to start unlocked")
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "identity.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret-mnemonic") {
+		t.Error("expected mnemonic not to be stored in plaintext")
+	}
+
+	// A restart starts locked
+	reloaded := New(dir)
+	if !reloaded.IsConfigured() || !reloaded.IsEncrypted() || !reloaded.IsLocked() {
+		t.Fatal("expected reloaded identity to be configured, encrypted and locked")
+	}
+	if got := reloaded.GetMnemonic(); got != "" {
+		t.Errorf("GetMnemonic while locked = %q, want empty", got)
+	}
+
+	if err := reloaded.Unlock("correct horse"); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if got := reloaded.GetMnemonic(); got != "secret-mnemonic" {
+		t.Errorf("GetMnemonic after unlock = %q, want secret-mnemonic", got)
+	}
+
+	reloaded.Lock()
+	if !reloaded.IsLocked() || reloaded.GetMnemonic() != "" {
+		t.Error("expected Lock to discard the decrypted mnemonic")
+	}
+}
+
+func TestPassphrase_WrongPassphrase(t *testing.T) {
+	dir := t.TempDir()
+	New(dir).SetIdentityWithPassphrase("EUSER", "secret-mnemonic", "correct horse")
+
+	ui := New(dir)
+	if err := ui.Unlock("wrong horse"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Unlock with wrong passphrase = %v, want ErrWrongPassphrase", err)
+	}
+	if !ui.IsLocked() {
+		t.Error("expected identity to stay locked")
+	}
+}
+
+func TestPassphrase_SetIdentityWithoutPassphrase(t *testing.T) {
+	ui := New(t.TempDir())
+	ui.SetIdentityWithPassphrase("EUSER", "secret-mnemonic", "correct horse")
+
+	if err := ui.SetIdentity("EUSER", "secret-mnemonic"); err != nil {
+		t.Fatalf("SetIdentity: %v", err)
+	}
+	if ui.IsEncrypted() {
+		t.Error("expected SetIdentity to store the mnemonic unencrypted")
+	}
+	if err := ui.Unlock("correct horse"); err == nil {
+		t.Error("expected Unlock to fail for an unencrypted identity")
+	}
+}
@@ -3,15 +3,20 @@ package keri
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 )
 
 // Client provides KERI configuration and credential utilities.
 // Note: Credential issuance is handled by the frontend via signify-ts.
 // This client provides org info, role definitions, and credential validation.
 type Client struct {
-	orgAID   string
-	orgAlias string
-	orgName  string
+	orgAID           string
+	orgAlias         string
+	orgName          string
+	witnessThreshold int
+	oobiHosts        []string
+	httpClient       *http.Client
+	kelCache         KELCache
 }
 
 // Config holds KERI client configuration
@@ -19,6 +24,12 @@ type Config struct {
 	OrgAID   string
 	OrgAlias string
 	OrgName  string
+	// WitnessThreshold is the number of valid witness receipts an event
+	// needs to count as witnessed (default: DefaultWitnessThreshold)
+	WitnessThreshold int
+	// OOBIHosts are the hosts ("host" or "host:port") ResolveOOBI may fetch
+	// from. Empty disables OOBI resolution.
+	OOBIHosts []string
 }
 
 // CredentialData contains ACDC credential attributes (schema v2)
@@ -78,12 +89,19 @@ func NewClient(cfg *Config) (*Client, error) {
 	if cfg.OrgName == "" {
 		cfg.OrgName = "MATOU DAO"
 	}
+	if cfg.WitnessThreshold <= 0 {
+		cfg.WitnessThreshold = DefaultWitnessThreshold
+	}
 
-	return &Client{
-		orgAID:   cfg.OrgAID,
-		orgAlias: cfg.OrgAlias,
-		orgName:  cfg.OrgName,
-	}, nil
+	c := &Client{
+		orgAID:           cfg.OrgAID,
+		orgAlias:         cfg.OrgAlias,
+		orgName:          cfg.OrgName,
+		witnessThreshold: cfg.WitnessThreshold,
+		oobiHosts:        cfg.OOBIHosts,
+	}
+	c.httpClient = c.newOOBIHTTPClient()
+	return c, nil
 }
 
 // GetOrgInfo returns organization information for the frontend
@@ -0,0 +1,334 @@
+package keri
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"lukechampine.com/blake3"
+)
+
+// Key event types
+const (
+	EventInception   = "icp"
+	EventRotation    = "rot"
+	EventInteraction = "ixn"
+)
+
+// KeyEvent is a single event in a Key Event Log as received from KERIA.
+// Raw is the event's KERI serialization: the signatures and receipts are made
+// over it, and its "d" field is the event SAID. Verification reads the event
+// body (prefix, sequence, prior, keys, threshold, anchors) from Raw only; the
+// Type, Sequence, Digest and Prior fields must agree with it. Data carries
+// the event body as reported by KERIA for clients and is never trusted.
+type KeyEvent struct {
+	Type       string    `json:"type"`
+	Sequence   int       `json:"sequence"`
+	Digest     string    `json:"digest"`
+	Prior      string    `json:"prior,omitempty"`
+	Data       any       `json:"data"`
+	Raw        string    `json:"raw,omitempty"`
+	Signatures []string  `json:"signatures,omitempty"`
+	Receipts   []Receipt `json:"receipts,omitempty"`
+}
+
+// eventBody holds the KERI fields of a serialized key event
+type eventBody struct {
+	Type          string   `json:"t"`
+	SAID          string   `json:"d"`
+	Prefix        string   `json:"i"`
+	Sequence      string   `json:"s"`
+	Prior         string   `json:"p"`
+	Threshold     any      `json:"kt"`
+	Keys          []string `json:"k"`
+	NextThreshold any      `json:"nt"`
+	NextKeys      []string `json:"n"`
+	Witnesses     []string `json:"b"`
+	WitnessCuts   []string `json:"br"`
+	WitnessAdds   []string `json:"ba"`
+	Anchors       []any    `json:"a"`
+}
+
+// saidPlaceholder stands in for the SAID fields while the SAID is computed
+var saidPlaceholder = strings.Repeat("#", 44)
+
+// KELError identifies the first event that failed KEL verification.
+// Index is the event's position in the submitted log.
+type KELError struct {
+	Index    int    `json:"index"`
+	Sequence int    `json:"sequence"`
+	Digest   string `json:"digest,omitempty"`
+	Reason   string `json:"reason"`
+}
+
+func (e *KELError) Error() string {
+	return fmt.Sprintf("invalid KEL event %d (sequence %d): %s", e.Index, e.Sequence, e.Reason)
+}
+
+// VerifyKEL checks that events form a contiguous, correctly chained log for
+// aid: every event's SAID matches its serialization, names aid as its prefix
+// and is signed by the keys in effect (the keys it declares, for inception
+// and rotation). The inception must derive aid, either as its own SAID or as
+// its single key. prior is the already-accepted log, from inception, that
+// events continue, or nil when events start at inception. Returns a *KELError
+// for the first bad event.
+func VerifyKEL(aid string, events []KeyEvent, prior []KeyEvent) error {
+	var state *KeyState
+	if len(prior) > 0 {
+		s, err := KeyStateAt(prior, prior[len(prior)-1].Sequence)
+		if err != nil {
+			return fmt.Errorf("stored KEL: %w", err)
+		}
+		state = s
+	}
+
+	for i := range events {
+		event := &events[i]
+		fail := func(format string, args ...any) error {
+			return &KELError{
+				Index:    i,
+				Sequence: event.Sequence,
+				Digest:   event.Digest,
+				Reason:   fmt.Sprintf(format, args...),
+			}
+		}
+
+		if event.Digest == "" {
+			return fail("digest is required")
+		}
+		if state == nil && (event.Sequence != 0 || event.Type != EventInception) {
+			return fail("log must start with an inception event at sequence 0")
+		}
+
+		body, err := parseEvent(event)
+		if err != nil {
+			return fail("%v", err)
+		}
+		if body.Prefix != aid {
+			return fail("event is for %q, not %q", body.Prefix, aid)
+		}
+
+		next := KeyState{}
+		if state != nil {
+			next = *state
+		}
+		if err := next.apply(event, body); err != nil {
+			return fail("%v", err)
+		}
+		if err := verifySignatures(event, next.Keys, next.Threshold); err != nil {
+			return fail("%v", err)
+		}
+		state = &next
+	}
+	return nil
+}
+
+// parseEvent decodes event.Raw, checks that the event fields agree with it
+// and that its SAID is the digest of the serialization.
+func parseEvent(event *KeyEvent) (*eventBody, error) {
+	body, err := decodeEventBody(event.Raw)
+	if err != nil {
+		return nil, err
+	}
+
+	seq, err := strconv.ParseInt(body.Sequence, 16, 0)
+	switch {
+	case body.Type != event.Type:
+		return nil, fmt.Errorf("raw event type %q does not match %q", body.Type, event.Type)
+	case err != nil || int(seq) != event.Sequence:
+		return nil, fmt.Errorf("raw event sequence %q does not match %d", body.Sequence, event.Sequence)
+	case body.Prior != event.Prior:
+		return nil, fmt.Errorf("raw event prior %q does not match %q", body.Prior, event.Prior)
+	case body.SAID != event.Digest:
+		return nil, fmt.Errorf("raw event SAID %q does not match digest %q", body.SAID, event.Digest)
+	}
+
+	said, err := eventSAID(event.Raw, body)
+	if err != nil {
+		return nil, err
+	}
+	if said != body.SAID {
+		return nil, fmt.Errorf("digest %q does not match the raw event (SAID %q)", body.SAID, said)
+	}
+	return body, nil
+}
+
+// decodeEventBody decodes an event's raw serialization without checking it.
+func decodeEventBody(raw string) (*eventBody, error) {
+	if raw == "" {
+		return nil, fmt.Errorf("raw event is required")
+	}
+	var body eventBody
+	if err := json.Unmarshal([]byte(raw), &body); err != nil {
+		return nil, fmt.Errorf("decoding raw event: %w", err)
+	}
+	return &body, nil
+}
+
+// eventSAID computes the SAID of a serialized event: the digest of raw with
+// its "d" field (and, for a self-addressing inception, its "i" field)
+// replaced by the placeholder.
+func eventSAID(raw string, body *eventBody) (string, error) {
+	if body.SAID == "" {
+		return "", fmt.Errorf("raw event has no SAID")
+	}
+	field := func(label, value string) string { return fmt.Sprintf("%q:%q", label, value) }
+
+	dummied := strings.Replace(raw, field("d", body.SAID), field("d", saidPlaceholder), 1)
+	if dummied == raw {
+		return "", fmt.Errorf("raw event SAID field is not in canonical form")
+	}
+	if body.Type == EventInception && body.Prefix == body.SAID {
+		dummied = strings.Replace(dummied, field("i", body.Prefix), field("i", saidPlaceholder), 1)
+	}
+	return Digest([]byte(dummied)), nil
+}
+
+// verifySignatures verifies an event's signatures over its raw serialization
+// against keys, requiring at least threshold of them.
+func verifySignatures(event *KeyEvent, keys []ed25519.PublicKey, threshold int) error {
+	verified := make(map[int]bool)
+	for pos, sig := range event.Signatures {
+		index, raw, err := decodeSignature(sig, pos)
+		if err != nil {
+			return fmt.Errorf("signature %d: %w", pos, err)
+		}
+		if index >= len(keys) {
+			return fmt.Errorf("signature %d: key index %d out of range", pos, index)
+		}
+		if !ed25519.Verify(keys[index], []byte(event.Raw), raw) {
+			return fmt.Errorf("signature %d does not verify against key %d", pos, index)
+		}
+		verified[index] = true
+	}
+	if len(verified) < threshold {
+		return fmt.Errorf("%d of %d required signatures present", len(verified), threshold)
+	}
+	return nil
+}
+
+// establishmentKeys decodes the keys ("k") and signing threshold ("kt") of
+// an establishment event. The threshold defaults to 1.
+func establishmentKeys(body *eventBody) ([]ed25519.PublicKey, int, error) {
+	if len(body.Keys) == 0 {
+		return nil, 0, fmt.Errorf("establishment event must declare keys")
+	}
+
+	keys := make([]ed25519.PublicKey, 0, len(body.Keys))
+	for i, s := range body.Keys {
+		key, err := decodeVerKey(s)
+		if err != nil {
+			return nil, 0, fmt.Errorf("key %d: %w", i, err)
+		}
+		keys = append(keys, key)
+	}
+
+	threshold, err := parseThreshold(body.Threshold)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid signing threshold: %w", err)
+	}
+	if threshold > len(keys) {
+		return nil, 0, fmt.Errorf("signing threshold %d exceeds %d keys", threshold, len(keys))
+	}
+	return keys, threshold, nil
+}
+
+// nextKeyCommitment returns the next-key digests ("n") and threshold ("nt")
+// an establishment event commits to. An event committing to no next keys
+// makes the identifier non-transferable.
+func nextKeyCommitment(body *eventBody) ([]string, int, error) {
+	if len(body.NextKeys) == 0 {
+		return nil, 0, nil
+	}
+	threshold, err := parseThreshold(body.NextThreshold)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid next threshold: %w", err)
+	}
+	if threshold > len(body.NextKeys) {
+		return nil, 0, fmt.Errorf("next threshold %d exceeds %d next keys", threshold, len(body.NextKeys))
+	}
+	return body.NextKeys, threshold, nil
+}
+
+// parseThreshold decodes a KERI threshold (a hex string), defaulting to 1.
+// Weighted thresholds aren't supported.
+func parseThreshold(v any) (int, error) {
+	switch kt := v.(type) {
+	case nil:
+		return 1, nil
+	case string:
+		n, err := strconv.ParseInt(kt, 16, 0)
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("%q", kt)
+		}
+		return int(n), nil
+	case float64:
+		if kt < 1 {
+			return 0, fmt.Errorf("%v", kt)
+		}
+		return int(kt), nil
+	}
+	return 0, fmt.Errorf("unsupported threshold %v", v)
+}
+
+// decodeVerKey decodes a CESR Ed25519 verification key ("D" transferable or
+// "B" non-transferable prefix, 44 characters).
+func decodeVerKey(s string) (ed25519.PublicKey, error) {
+	if len(s) != 44 || (s[0] != 'D' && s[0] != 'B') {
+		return nil, fmt.Errorf("not a CESR Ed25519 key: %q", s)
+	}
+	raw, err := base64.RawURLEncoding.DecodeString("A" + s[1:])
+	if err != nil {
+		return nil, fmt.Errorf("decoding key: %w", err)
+	}
+	return ed25519.PublicKey(raw[1:]), nil
+}
+
+// decodeSignature decodes a CESR Ed25519 signature (88 characters): either
+// indexed ("A" + index character) or unindexed ("0B"), in which case the
+// key index is the signature's position.
+func decodeSignature(s string, pos int) (int, []byte, error) {
+	if len(s) != 88 {
+		return 0, nil, fmt.Errorf("not a CESR Ed25519 signature")
+	}
+	index := pos
+	switch {
+	case s[0] == 'A':
+		idx, err := base64.RawURLEncoding.DecodeString("AAA" + s[1:2])
+		if err != nil {
+			return 0, nil, fmt.Errorf("decoding signature index: %w", err)
+		}
+		index = int(idx[2])
+	case s[:2] == "0B":
+	default:
+		return 0, nil, fmt.Errorf("unsupported signature code %q", s[:2])
+	}
+	raw, err := base64.RawURLEncoding.DecodeString("AA" + s[2:])
+	if err != nil {
+		return 0, nil, fmt.Errorf("decoding signature: %w", err)
+	}
+	return index, raw[2:], nil
+}
+
+// EncodeVerKey encodes an Ed25519 public key as a transferable CESR
+// verification key ("D" prefix).
+func EncodeVerKey(key ed25519.PublicKey) string {
+	return "D" + base64.RawURLEncoding.EncodeToString(append([]byte{0}, key...))[1:]
+}
+
+// EncodeIndexedSignature encodes an Ed25519 signature as an indexed CESR
+// signature ("A" + index character) for the key at index (0-63).
+func EncodeIndexedSignature(index int, sig []byte) string {
+	code := base64.RawURLEncoding.EncodeToString([]byte{0, 0, byte(index)})[3:]
+	return "A" + code + base64.RawURLEncoding.EncodeToString(append([]byte{0, 0}, sig...))[2:]
+}
+
+// Digest returns the CESR Blake3-256 digest of data ("E" prefix), as used
+// for event SAIDs.
+func Digest(data []byte) string {
+	sum := blake3.Sum256(data)
+	return "E" + base64.RawURLEncoding.EncodeToString(append([]byte{0}, sum[:]...))[1:]
+}
@@ -0,0 +1,244 @@
+package keri
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// testKeys generates n deterministic Ed25519 key pairs.
+func testKeys(n int) []ed25519.PrivateKey {
+	keys := make([]ed25519.PrivateKey, n)
+	for i := range keys {
+		seed := make([]byte, ed25519.SeedSize)
+		seed[0] = byte(i + 1)
+		keys[i] = ed25519.NewKeyFromSeed(seed)
+	}
+	return keys
+}
+
+// testEvent serializes a key event for prefix as KERIA does (compact JSON in
+// KERI field order), fills in its SAID and signs it with signers. An empty
+// prefix makes an inception self-addressing. fields holds the fields after
+// "p", each with a leading comma.
+func testEvent(typ string, seq int, prefix, prior, fields string, signers ...ed25519.PrivateKey) KeyEvent {
+	if prefix == "" {
+		prefix = saidPlaceholder
+	}
+	raw := fmt.Sprintf(`{"v":"KERI10JSON000000_","t":%q,"d":%q,"i":%q,"s":"%x","p":%q%s}`,
+		typ, saidPlaceholder, prefix, seq, prior, fields)
+	said := Digest([]byte(raw))
+	raw = strings.ReplaceAll(raw, saidPlaceholder, said)
+
+	event := KeyEvent{Type: typ, Sequence: seq, Digest: said, Prior: prior, Raw: raw}
+	for i, k := range signers {
+		event.Signatures = append(event.Signatures, EncodeIndexedSignature(i, ed25519.Sign(k, []byte(raw))))
+	}
+	return event
+}
+
+// keyFields returns the "kt" and "k" fields declaring signers' keys.
+func keyFields(threshold int, signers ...ed25519.PrivateKey) string {
+	keys := make([]string, len(signers))
+	for i, k := range signers {
+		keys[i] = EncodeVerKey(k.Public().(ed25519.PublicKey))
+	}
+	k, _ := json.Marshal(keys)
+	return fmt.Sprintf(`,"kt":"%x","k":%s`, threshold, k)
+}
+
+// nextFields returns the "nt" and "n" fields committing to next's keys.
+func nextFields(next ...ed25519.PrivateKey) string {
+	digests := make([]string, len(next))
+	for i, k := range next {
+		digests[i] = Digest([]byte(EncodeVerKey(k.Public().(ed25519.PublicKey))))
+	}
+	n, _ := json.Marshal(digests)
+	return fmt.Sprintf(`,"nt":"1","n":%s`, n)
+}
+
+// establishment builds an icp or rot event for prefix declaring, and signed
+// by, signer and committing to next as the next key.
+func establishment(typ string, seq int, prefix, prior string, signer, next ed25519.PrivateKey) KeyEvent {
+	return testEvent(typ, seq, prefix, prior, keyFields(1, signer)+nextFields(next), signer)
+}
+
+// anchoring returns an ixn for prefix at seq anchoring said, signed by signer.
+func anchoring(seq int, prefix, prior, said string, signer ed25519.PrivateKey) KeyEvent {
+	return testEvent(EventInteraction, seq, prefix, prior, fmt.Sprintf(`,"a":[{"d":%q}]`, said), signer)
+}
+
+// validKEL returns icp → rot → ixn for a self-addressing AID (the icp
+// digest), correctly chained and signed.
+func validKEL() []KeyEvent {
+	keys := testKeys(3)
+	icp := establishment(EventInception, 0, "", "", keys[0], keys[1])
+	rot := establishment(EventRotation, 1, icp.Digest, icp.Digest, keys[1], keys[2])
+	ixn := anchoring(2, icp.Digest, rot.Digest, "ESAID001", keys[1])
+	return []KeyEvent{icp, rot, ixn}
+}
+
+// kelError asserts err is a *KELError for the event at index.
+func kelError(t *testing.T, err error, index int, reason string) {
+	t.Helper()
+	var kelErr *KELError
+	if !errors.As(err, &kelErr) {
+		t.Fatalf("expected *KELError, got %v", err)
+	}
+	if kelErr.Index != index {
+		t.Errorf("error index = %d, want %d (%s)", kelErr.Index, index, kelErr.Reason)
+	}
+	if !strings.Contains(kelErr.Reason, reason) {
+		t.Errorf("error reason %q does not mention %q", kelErr.Reason, reason)
+	}
+}
+
+func TestVerifyKEL_Valid(t *testing.T) {
+	kel := validKEL()
+	if err := VerifyKEL(kel[0].Digest, kel, nil); err != nil {
+		t.Fatalf("expected valid KEL, got %v", err)
+	}
+}
+
+func TestVerifyKEL_SequenceGap(t *testing.T) {
+	kel := validKEL()
+	kel[2] = anchoring(3, kel[0].Digest, kel[1].Digest, "ESAID001", testKeys(2)[1])
+
+	kelError(t, VerifyKEL(kel[0].Digest, kel, nil), 2, "expected sequence 2")
+}
+
+func TestVerifyKEL_BrokenDigestChain(t *testing.T) {
+	kel := validKEL()
+	kel[2] = anchoring(2, kel[0].Digest, "EDIGEST999", "ESAID001", testKeys(2)[1])
+
+	kelError(t, VerifyKEL(kel[0].Digest, kel, nil), 2, "prior digest")
+}
+
+func TestVerifyKEL_MustStartAtInception(t *testing.T) {
+	kel := validKEL()
+
+	kelError(t, VerifyKEL(kel[0].Digest, kel[1:], nil), 0, "inception")
+}
+
+func TestVerifyKEL_ContinuesStoredEvent(t *testing.T) {
+	kel := validKEL()
+
+	if err := VerifyKEL(kel[0].Digest, kel[1:], kel[:1]); err != nil {
+		t.Errorf("expected continuation to verify, got %v", err)
+	}
+}
+
+func TestVerifyKEL_BadSignature(t *testing.T) {
+	kel := validKEL()
+	// Rotation signed by a key it doesn't declare
+	other := testKeys(3)[2]
+	kel[1].Signatures = []string{EncodeIndexedSignature(0, ed25519.Sign(other, []byte(kel[1].Raw)))}
+
+	kelError(t, VerifyKEL(kel[0].Digest, kel, nil), 1, "does not verify")
+}
+
+func TestVerifyKEL_TamperedRaw(t *testing.T) {
+	kel := validKEL()
+	kel[2].Raw = strings.Replace(kel[2].Raw, "ESAID001", "ESAID666", 1)
+
+	kelError(t, VerifyKEL(kel[0].Digest, kel, nil), 2, "does not match the raw event")
+}
+
+func TestVerifyKEL_FieldsMustMatchRaw(t *testing.T) {
+	kel := validKEL()
+	kel[1].Digest = kel[2].Digest
+
+	kelError(t, VerifyKEL(kel[0].Digest, kel, nil), 1, "does not match digest")
+}
+
+func TestVerifyKEL_IgnoresData(t *testing.T) {
+	kel := validKEL()
+	// Keys claimed in Data are not used: the rotation still needs keys[1]
+	other := testKeys(3)[2]
+	kel[1].Data = map[string]any{"keys": []any{EncodeVerKey(other.Public().(ed25519.PublicKey))}}
+	kel[1].Signatures = []string{EncodeIndexedSignature(0, ed25519.Sign(other, []byte(kel[1].Raw)))}
+
+	kelError(t, VerifyKEL(kel[0].Digest, kel, nil), 1, "does not verify")
+}
+
+func TestVerifyKEL_BindsAID(t *testing.T) {
+	kel := validKEL()
+	kelError(t, VerifyKEL("EOTHER", kel, nil), 0, "not \"EOTHER\"")
+
+	// An inception may not claim a prefix it doesn't derive
+	key := testKeys(1)[0]
+	icp := testEvent(EventInception, 0, "EOTHER", "", keyFields(1, key), key)
+	kelError(t, VerifyKEL("EOTHER", []KeyEvent{icp}, nil), 0, "not derived")
+
+	// A basic prefix is the inception's single key
+	basic := EncodeVerKey(key.Public().(ed25519.PublicKey))
+	icp = testEvent(EventInception, 0, basic, "", keyFields(1, key), key)
+	if err := VerifyKEL(basic, []KeyEvent{icp}, nil); err != nil {
+		t.Errorf("expected basic-prefix inception to verify, got %v", err)
+	}
+}
+
+func TestVerifyKEL_RotationMustUseCommittedKeys(t *testing.T) {
+	kel := validKEL()
+	// A correctly signed rotation to a key the inception didn't commit to
+	other := testKeys(4)[3]
+	kel[1] = establishment(EventRotation, 1, kel[0].Digest, kel[0].Digest, other, other)
+
+	kelError(t, VerifyKEL(kel[0].Digest, kel[:2], nil), 1, "not committed")
+}
+
+func TestVerifyKEL_NonTransferableCannotRotate(t *testing.T) {
+	keys := testKeys(2)
+	icp := testEvent(EventInception, 0, "", "", keyFields(1, keys[0])+`,"nt":"0","n":[]`, keys[0])
+	rot := establishment(EventRotation, 1, icp.Digest, icp.Digest, keys[1], keys[1])
+
+	kelError(t, VerifyKEL(icp.Digest, []KeyEvent{icp, rot}, nil), 1, "non-transferable")
+}
+
+func TestVerifyKEL_UnsignedInteraction(t *testing.T) {
+	kel := validKEL()
+	kel[2].Signatures = nil
+
+	kelError(t, VerifyKEL(kel[0].Digest, kel, nil), 2, "required signatures")
+}
+
+func TestVerifyKEL_Threshold(t *testing.T) {
+	keys := testKeys(2)
+	icp := testEvent(EventInception, 0, "", "", keyFields(2, keys...), keys[0])
+
+	kelError(t, VerifyKEL(icp.Digest, []KeyEvent{icp}, nil), 0, "1 of 2 required signatures")
+}
+
+func TestVerifyKEL_UnsignedEstablishment(t *testing.T) {
+	kel := validKEL()
+	kel[0].Signatures = nil
+
+	kelError(t, VerifyKEL(kel[0].Digest, kel, nil), 0, "required signatures")
+}
+
+func TestCESRRoundTrip(t *testing.T) {
+	key := testKeys(1)[0]
+	pub := key.Public().(ed25519.PublicKey)
+
+	encoded := EncodeVerKey(pub)
+	if len(encoded) != 44 || encoded[0] != 'D' {
+		t.Fatalf("unexpected verkey encoding %q", encoded)
+	}
+	decoded, err := decodeVerKey(encoded)
+	if err != nil || !decoded.Equal(pub) {
+		t.Errorf("verkey round trip failed: %v", err)
+	}
+
+	sig := ed25519.Sign(key, []byte("msg"))
+	encodedSig := EncodeIndexedSignature(5, sig)
+	if len(encodedSig) != 88 {
+		t.Fatalf("unexpected signature length %d", len(encodedSig))
+	}
+	index, raw, err := decodeSignature(encodedSig, 0)
+	if err != nil || index != 5 || string(raw) != string(sig) {
+		t.Errorf("signature round trip failed: index %d, err %v", index, err)
+	}
+}
@@ -0,0 +1,192 @@
+package keri
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"slices"
+)
+
+// KeyState is an identifier's signing authority after a point in its KEL:
+// the keys and threshold declared by the latest establishment event, and
+// the witnesses designated so far.
+type KeyState struct {
+	Prefix        string // AID the log establishes
+	Sequence      int    // Sequence of the last applied event
+	Digest        string // Digest of the last applied event
+	Establishment int    // Sequence of the establishment event the keys come from
+	Keys          []ed25519.PublicKey
+	Threshold     int
+	NextDigests   []string // Digests of the keys the next rotation must use
+	NextThreshold int      // How many of the committed keys it must use
+	Witnesses     []string // Witnesses designated by the establishment events
+}
+
+// Apply advances the key state by one event, read from its raw
+// serialization. Inception sets the prefix and keys and rotation replaces
+// the keys, which must be among those the previous establishment event
+// committed to; interaction events leave them unchanged. The event must
+// chain from the current state. Signatures aren't checked here; see
+// VerifyKEL.
+func (s *KeyState) Apply(event *KeyEvent) error {
+	body, err := parseEvent(event)
+	if err != nil {
+		return fmt.Errorf("event %d: %w", event.Sequence, err)
+	}
+	return s.apply(event, body)
+}
+
+// apply advances the key state by event, whose raw serialization decodes to
+// body.
+func (s *KeyState) apply(event *KeyEvent, body *eventBody) error {
+	switch event.Type {
+	case EventInception:
+		if s.Keys != nil || event.Sequence != 0 {
+			return fmt.Errorf("inception is only valid at sequence 0")
+		}
+		keys, threshold, err := establishmentKeys(body)
+		if err != nil {
+			return fmt.Errorf("event %d: %w", event.Sequence, err)
+		}
+		// Self-addressing (the inception SAID) or basic (the single key) prefix
+		if body.Prefix != body.SAID && (len(body.Keys) != 1 || body.Prefix != body.Keys[0]) {
+			return fmt.Errorf("prefix %q is not derived from the inception event", body.Prefix)
+		}
+		s.Prefix = body.Prefix
+		if err := s.establish(event, body, keys, threshold); err != nil {
+			return err
+		}
+	case EventRotation, EventInteraction:
+		if s.Keys == nil {
+			return fmt.Errorf("%s at sequence %d before inception", event.Type, event.Sequence)
+		}
+		switch {
+		case body.Prefix != s.Prefix:
+			return fmt.Errorf("event is for %q, not %q", body.Prefix, s.Prefix)
+		case event.Sequence != s.Sequence+1:
+			return fmt.Errorf("expected sequence %d after %d", s.Sequence+1, s.Sequence)
+		case event.Prior != s.Digest:
+			return fmt.Errorf("prior digest %q does not match previous event digest %q", event.Prior, s.Digest)
+		}
+		if event.Type == EventRotation {
+			keys, threshold, err := establishmentKeys(body)
+			if err != nil {
+				return fmt.Errorf("event %d: %w", event.Sequence, err)
+			}
+			if err := s.checkCommitment(body.Keys); err != nil {
+				return fmt.Errorf("event %d: %w", event.Sequence, err)
+			}
+			if err := s.establish(event, body, keys, threshold); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unknown event type %q", event.Type)
+	}
+	s.Sequence = event.Sequence
+	s.Digest = event.Digest
+	return nil
+}
+
+// establish sets the keys, next-key commitment and witnesses declared by an
+// establishment event.
+func (s *KeyState) establish(event *KeyEvent, body *eventBody, keys []ed25519.PublicKey, threshold int) error {
+	next, nextThreshold, err := nextKeyCommitment(body)
+	if err != nil {
+		return fmt.Errorf("event %d: %w", event.Sequence, err)
+	}
+	witnesses, err := designate(event.Type, body, s.Witnesses)
+	if err != nil {
+		return fmt.Errorf("event %d: %w", event.Sequence, err)
+	}
+	s.Witnesses = witnesses
+	s.Keys = keys
+	s.Threshold = threshold
+	s.NextDigests = next
+	s.NextThreshold = nextThreshold
+	s.Establishment = event.Sequence
+	return nil
+}
+
+// checkCommitment checks a rotation's keys against the current next-key
+// commitment: every key must be one of the committed keys, and at least the
+// committed threshold of them must be used.
+func (s *KeyState) checkCommitment(keys []string) error {
+	if len(s.NextDigests) == 0 {
+		return fmt.Errorf("identifier is non-transferable: no next keys were committed")
+	}
+	used := make(map[string]bool)
+	for i, key := range keys {
+		if !slices.Contains(s.NextDigests, Digest([]byte(key))) {
+			return fmt.Errorf("key %d was not committed to by establishment event %d", i, s.Establishment)
+		}
+		used[key] = true
+	}
+	if len(used) < s.NextThreshold {
+		return fmt.Errorf("%d of %d committed next keys used", len(used), s.NextThreshold)
+	}
+	return nil
+}
+
+// KeyStateAt replays events up to and including sequence and returns the
+// key state in effect at that event.
+func KeyStateAt(events []KeyEvent, sequence int) (*KeyState, error) {
+	state := &KeyState{}
+	for i := range events {
+		if events[i].Sequence > sequence {
+			break
+		}
+		if err := state.Apply(&events[i]); err != nil {
+			return nil, err
+		}
+	}
+	if state.Keys == nil || state.Sequence != sequence {
+		return nil, fmt.Errorf("sequence %d not in KEL", sequence)
+	}
+	return state, nil
+}
+
+// VerifySignature checks a CESR indexed signature over data against the
+// current keys. A single signature only satisfies a signing threshold of 1.
+func (s *KeyState) VerifySignature(data []byte, signature string) error {
+	if s.Threshold > 1 {
+		return fmt.Errorf("signing threshold %d requires multiple signatures", s.Threshold)
+	}
+	index, sig, err := decodeSignature(signature, 0)
+	if err != nil {
+		return err
+	}
+	if index >= len(s.Keys) {
+		return fmt.Errorf("key index %d out of range", index)
+	}
+	if !ed25519.Verify(s.Keys[index], data, sig) {
+		return fmt.Errorf("signature does not verify against key %d of establishment event %d", index, s.Establishment)
+	}
+	return nil
+}
+
+// VerifyCredentialSignature checks cred.Signature, a signature over the
+// credential SAID, against the issuer's keys as of the event anchoring the
+// credential in events (the issuer's KEL), following any rotations before
+// it. Credentials not anchored in events are checked against the latest keys.
+func VerifyCredentialSignature(cred *Credential, events []KeyEvent) error {
+	if cred.Signature == "" {
+		return fmt.Errorf("credential is not signed")
+	}
+	if len(events) == 0 {
+		return fmt.Errorf("issuer KEL is empty")
+	}
+
+	anchor := events[len(events)-1].Sequence
+	for _, event := range events {
+		if slices.Contains(EventAnchors(&event), cred.SAID) {
+			anchor = event.Sequence
+			break
+		}
+	}
+
+	state, err := KeyStateAt(events, anchor)
+	if err != nil {
+		return err
+	}
+	return state.VerifySignature([]byte(cred.SAID), cred.Signature)
+}
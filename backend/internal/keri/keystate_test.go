@@ -0,0 +1,128 @@
+package keri
+
+import (
+	"crypto/ed25519"
+	"strings"
+	"testing"
+)
+
+// signedCredential returns a credential whose SAID is signed by key.
+func signedCredential(said string, key ed25519.PrivateKey) *Credential {
+	return &Credential{
+		SAID:      said,
+		Issuer:    "EISSUER",
+		Signature: EncodeIndexedSignature(0, ed25519.Sign(key, []byte(said))),
+	}
+}
+
+func TestKeyState_RotationAdvancesKeys(t *testing.T) {
+	keys := testKeys(2)
+	kel := validKEL()
+
+	state := &KeyState{}
+	if err := state.Apply(&kel[0]); err != nil {
+		t.Fatalf("apply inception: %v", err)
+	}
+	if !state.Keys[0].Equal(keys[0].Public()) {
+		t.Error("expected inception keys after icp")
+	}
+	for i := 1; i < len(kel); i++ {
+		if err := state.Apply(&kel[i]); err != nil {
+			t.Fatalf("apply event %d: %v", i, err)
+		}
+	}
+	if !state.Keys[0].Equal(keys[1].Public()) {
+		t.Error("expected rotated keys after rot")
+	}
+	if state.Sequence != 2 || state.Establishment != 1 {
+		t.Errorf("state at sequence %d from establishment %d, want 2 from 1", state.Sequence, state.Establishment)
+	}
+}
+
+func TestKeyState_RotationChecksCommitment(t *testing.T) {
+	keys := testKeys(3)
+	icp := establishment(EventInception, 0, "", "", keys[0], keys[1])
+	state := &KeyState{}
+	if err := state.Apply(&icp); err != nil {
+		t.Fatalf("apply inception: %v", err)
+	}
+	if len(state.NextDigests) != 1 || state.NextThreshold != 1 {
+		t.Fatalf("expected one committed next key, got %v (threshold %d)", state.NextDigests, state.NextThreshold)
+	}
+
+	rot := establishment(EventRotation, 1, icp.Digest, icp.Digest, keys[2], keys[2])
+	if err := state.Apply(&rot); err == nil || !strings.Contains(err.Error(), "not committed") {
+		t.Errorf("expected rotation to an uncommitted key to fail, got %v", err)
+	}
+}
+
+func TestKeyState_InteractionBeforeInception(t *testing.T) {
+	state := &KeyState{}
+	ixn := anchoring(0, "EAID", "", "ESAID001", testKeys(1)[0])
+	if err := state.Apply(&ixn); err == nil {
+		t.Error("expected interaction before inception to fail")
+	}
+}
+
+func TestKeyStateAt(t *testing.T) {
+	keys := testKeys(2)
+	kel := validKEL()
+
+	state, err := KeyStateAt(kel, 0)
+	if err != nil {
+		t.Fatalf("KeyStateAt(0): %v", err)
+	}
+	if !state.Keys[0].Equal(keys[0].Public()) {
+		t.Error("expected inception keys at sequence 0")
+	}
+	if _, err := KeyStateAt(kel, 5); err == nil {
+		t.Error("expected error for sequence past the end of the KEL")
+	}
+}
+
+func TestVerifyCredentialSignature_FollowsRotation(t *testing.T) {
+	keys := testKeys(3)
+	icp := establishment(EventInception, 0, "", "", keys[0], keys[1])
+	aid := icp.Digest
+	rot := establishment(EventRotation, 1, aid, icp.Digest, keys[1], keys[2])
+	cred := signedCredential("ESAID001", keys[1])
+
+	rotated := []KeyEvent{icp, rot, anchoring(2, aid, rot.Digest, cred.SAID, keys[1])}
+	if err := VerifyKEL(aid, rotated, nil); err != nil {
+		t.Fatalf("test KEL is invalid: %v", err)
+	}
+	if err := VerifyCredentialSignature(cred, rotated); err != nil {
+		t.Errorf("expected credential signed with rotated keys to verify: %v", err)
+	}
+
+	// The same credential fails when the rotation isn't applied
+	unrotated := []KeyEvent{icp, anchoring(1, aid, icp.Digest, cred.SAID, keys[0])}
+	if err := VerifyCredentialSignature(cred, unrotated); err == nil {
+		t.Error("expected credential to fail verification without the rotation")
+	}
+
+	// A credential signed with the pre-rotation keys no longer verifies
+	if err := VerifyCredentialSignature(signedCredential("ESAID001", keys[0]), rotated); err == nil {
+		t.Error("expected credential signed with rotated-out keys to fail")
+	}
+}
+
+func TestVerifyCredentialSignature_AnchoredBeforeRotation(t *testing.T) {
+	keys := testKeys(3)
+	icp := establishment(EventInception, 0, "", "", keys[0], keys[1])
+	aid := icp.Digest
+	cred := signedCredential("ESAID001", keys[0])
+	ixn := anchoring(1, aid, icp.Digest, cred.SAID, keys[0])
+	rot := establishment(EventRotation, 2, aid, ixn.Digest, keys[1], keys[2])
+
+	// Keys as of the anchoring event apply, not the latest keys
+	if err := VerifyCredentialSignature(cred, []KeyEvent{icp, ixn, rot}); err != nil {
+		t.Errorf("expected credential anchored before rotation to verify: %v", err)
+	}
+}
+
+func TestVerifyCredentialSignature_Unsigned(t *testing.T) {
+	if err := VerifyCredentialSignature(&Credential{SAID: "ESAID001"}, validKEL()); err == nil {
+		t.Error("expected unsigned credential to fail")
+	}
+}
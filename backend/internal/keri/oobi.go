@@ -0,0 +1,160 @@
+package keri
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// OOBI resolution limits
+const (
+	oobiTimeout         = 10 * time.Second
+	maxOOBIResponseSize = 4 << 20
+)
+
+// KELCache stores KELs resolved from OOBIs
+type KELCache interface {
+	CacheKEL(ctx context.Context, aid string, events []KeyEvent) error
+}
+
+// SetKELCache sets where ResolveOOBI caches resolved KELs
+func (c *Client) SetKELCache(cache KELCache) {
+	c.kelCache = cache
+}
+
+// ResolveOOBI fetches aid's KEL from an OOBI endpoint, verifies it and caches
+// it in the configured KEL cache. The endpoint must return the KEL as a JSON
+// array of key events, starting at inception, and the log must establish aid
+// (see VerifyKEL). Only http(s) URLs on the configured OOBI hosts are
+// fetched, and loopback, private and link-local addresses are refused unless
+// the configured host is itself one (a local KERIA, say).
+func (c *Client) ResolveOOBI(ctx context.Context, aid, oobiURL string) ([]KeyEvent, error) {
+	if err := c.checkOOBIURL(oobiURL); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, oobiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating OOBI request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching OOBI: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OOBI endpoint returned status %d", resp.StatusCode)
+	}
+
+	var events []KeyEvent
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxOOBIResponseSize)).Decode(&events); err != nil {
+		return nil, fmt.Errorf("decoding OOBI KEL: %w", err)
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("OOBI returned an empty KEL")
+	}
+	if err := VerifyKEL(aid, events, nil); err != nil {
+		return nil, err
+	}
+
+	if c.kelCache != nil {
+		if err := c.kelCache.CacheKEL(ctx, aid, events); err != nil {
+			return nil, fmt.Errorf("caching KEL: %w", err)
+		}
+	}
+	return events, nil
+}
+
+// checkOOBIURL checks that u is an http(s) URL on a configured OOBI host
+func (c *Client) checkOOBIURL(u string) error {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return fmt.Errorf("invalid OOBI URL: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("OOBI URL scheme %q is not allowed", parsed.Scheme)
+	}
+	for _, host := range c.oobiHosts {
+		if strings.EqualFold(host, parsed.Host) || strings.EqualFold(host, parsed.Hostname()) {
+			return nil
+		}
+	}
+	return fmt.Errorf("OOBI host %q is not a configured OOBI host", parsed.Host)
+}
+
+// newOOBIHTTPClient returns the client ResolveOOBI fetches with. It dials
+// only addresses allowed for the request's host, checked after DNS
+// resolution so a configured name can't be pointed at an internal address,
+// and re-checks the host of every redirect.
+func (c *Client) newOOBIHTTPClient() *http.Client {
+	dialer := &net.Dialer{Timeout: oobiTimeout}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			host, port, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+			ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+			if err != nil {
+				return nil, err
+			}
+			internal := c.internalOOBIHost(host)
+			for _, ip := range ips {
+				if internalAddr(ip.IP) && !internal {
+					continue
+				}
+				return dialer.DialContext(ctx, network, net.JoinHostPort(ip.IP.String(), port))
+			}
+			return nil, fmt.Errorf("OOBI host %q resolves only to internal addresses", host)
+		},
+		TLSHandshakeTimeout: oobiTimeout,
+	}
+	return &http.Client{
+		Timeout:   oobiTimeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return fmt.Errorf("too many OOBI redirects")
+			}
+			return c.checkOOBIURL(req.URL.String())
+		},
+	}
+}
+
+// internalOOBIHost reports whether host is configured as an OOBI host that
+// is itself internal (localhost or an internal address literal), so may be
+// dialed at internal addresses.
+func (c *Client) internalOOBIHost(host string) bool {
+	for _, configured := range c.oobiHosts {
+		name := configured
+		if h, _, err := net.SplitHostPort(configured); err == nil {
+			name = h
+		}
+		if !strings.EqualFold(name, host) {
+			continue
+		}
+		if strings.EqualFold(name, "localhost") {
+			return true
+		}
+		if ip := net.ParseIP(name); ip != nil && internalAddr(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// internalAddr reports whether ip is loopback, private, link-local,
+// multicast or unspecified
+func internalAddr(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() || ip.IsUnspecified()
+}
@@ -0,0 +1,145 @@
+package keri
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// memoryKELCache records cached KELs by AID.
+type memoryKELCache map[string][]KeyEvent
+
+func (m memoryKELCache) CacheKEL(ctx context.Context, aid string, events []KeyEvent) error {
+	m[aid] = events
+	return nil
+}
+
+// oobiServer serves events as a KEL at /oobi.
+func oobiServer(t *testing.T, events []KeyEvent) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/oobi" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(events)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestResolveOOBI(t *testing.T) {
+	kel := validKEL()
+	server := oobiServer(t, kel)
+	client, _ := NewClient(&Config{OrgAID: "EORG", OOBIHosts: []string{"127.0.0.1"}})
+	cache := memoryKELCache{}
+	client.SetKELCache(cache)
+
+	events, err := client.ResolveOOBI(context.Background(), kel[0].Digest, server.URL+"/oobi")
+	if err != nil {
+		t.Fatalf("ResolveOOBI failed: %v", err)
+	}
+	if len(events) != len(kel) {
+		t.Errorf("expected %d events, got %d", len(kel), len(events))
+	}
+	if len(cache[kel[0].Digest]) != len(kel) {
+		t.Errorf("expected resolved KEL to be cached, got %v", cache)
+	}
+}
+
+func TestResolveOOBI_Errors(t *testing.T) {
+	kel := validKEL()
+	broken := validKEL()
+	broken[2] = anchoring(2, kel[0].Digest, "EWRONG", "ESAID001", testKeys(2)[1])
+
+	tests := []struct {
+		name   string
+		events []KeyEvent
+		aid    string
+		path   string
+		want   string
+	}{
+		{"wrong AID", kel, "EOTHER", "/oobi", "not \"EOTHER\""},
+		{"invalid KEL", broken, kel[0].Digest, "/oobi", "prior digest"},
+		{"empty KEL", []KeyEvent{}, kel[0].Digest, "/oobi", "empty KEL"},
+		{"not found", kel, kel[0].Digest, "/missing", "status 404"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := oobiServer(t, tt.events)
+			client, _ := NewClient(&Config{OrgAID: "EORG", OOBIHosts: []string{"127.0.0.1"}})
+			cache := memoryKELCache{}
+			client.SetKELCache(cache)
+
+			_, err := client.ResolveOOBI(context.Background(), tt.aid, server.URL+tt.path)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+			if len(cache) != 0 {
+				t.Error("expected nothing to be cached")
+			}
+		})
+	}
+}
+
+func TestResolveOOBI_RestrictedHosts(t *testing.T) {
+	kel := validKEL()
+	server := oobiServer(t, kel)
+	aid := kel[0].Digest
+
+	tests := []struct {
+		name  string
+		hosts []string
+		url   string
+		want  string
+	}{
+		{"no hosts configured", nil, server.URL + "/oobi", "not a configured OOBI host"},
+		{"other host", []string{"witness.example.com"}, server.URL + "/oobi", "not a configured OOBI host"},
+		{"other port", []string{"127.0.0.1:1"}, server.URL + "/oobi", "not a configured OOBI host"},
+		{"not http", []string{"127.0.0.1"}, "file:///etc/passwd", "scheme"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := NewClient(&Config{OrgAID: "EORG", OOBIHosts: tt.hosts})
+			if _, err := client.ResolveOOBI(context.Background(), aid, tt.url); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	// A redirect off the configured hosts isn't followed
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data", http.StatusFound)
+	}))
+	t.Cleanup(redirect.Close)
+	client, _ := NewClient(&Config{OrgAID: "EORG", OOBIHosts: []string{"127.0.0.1"}})
+	if _, err := client.ResolveOOBI(context.Background(), aid, redirect.URL); err == nil || !strings.Contains(err.Error(), "not a configured OOBI host") {
+		t.Errorf("expected redirect to be refused, got %v", err)
+	}
+}
+
+func TestInternalOOBIHost(t *testing.T) {
+	client, _ := NewClient(&Config{OrgAID: "EORG", OOBIHosts: []string{"localhost:3902", "10.0.0.5", "witness.example.com"}})
+	for host, want := range map[string]bool{
+		"localhost":           true,
+		"10.0.0.5":            true,
+		"witness.example.com": false, // a public name may not resolve to an internal address
+		"127.0.0.1":           false, // not configured
+	} {
+		if got := client.internalOOBIHost(host); got != want {
+			t.Errorf("internalOOBIHost(%q) = %v, want %v", host, got, want)
+		}
+	}
+	for addr, want := range map[string]bool{
+		"127.0.0.1": true, "10.1.2.3": true, "192.168.0.1": true, "169.254.169.254": true,
+		"::1": true, "fd00::1": true, "0.0.0.0": true, "93.184.216.34": false,
+	} {
+		if got := internalAddr(net.ParseIP(addr)); got != want {
+			t.Errorf("internalAddr(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package keri
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"slices"
+)
+
+// DefaultWitnessThreshold is the number of valid witness receipts an event
+// needs to be considered witnessed when none is configured.
+const DefaultWitnessThreshold = 1
+
+// Receipt is a witness's signature over a key event
+type Receipt struct {
+	Witness   string `json:"witness"`   // Witness AID (CESR non-transferable Ed25519 key, "B" prefix)
+	Signature string `json:"signature"` // CESR Ed25519 signature over the event's raw serialization
+}
+
+// WitnessThreshold returns the number of valid receipts required for an
+// event to be considered witnessed
+func (c *Client) WitnessThreshold() int {
+	return c.witnessThreshold
+}
+
+// ValidateReceipts reports, for each event, whether it is witnessed: it
+// carries valid receipts from at least threshold distinct witnesses
+// designated as of that event. Establishment events designate witnesses in
+// their raw serialization (see DesignatedWitnesses); witnesses is the
+// designated set in effect before events (from a stored log), or nil at
+// inception. Events are assumed to have passed VerifyKEL.
+func ValidateReceipts(events []KeyEvent, witnesses []string, threshold int) []bool {
+	witnessed := make([]bool, len(events))
+	for i := range events {
+		witnesses = DesignatedWitnesses(events[i:i+1], witnesses)
+		witnessed[i] = threshold > 0 && countReceipts(&events[i], witnesses) >= threshold
+	}
+	return witnessed
+}
+
+// DesignatedWitnesses returns the witness set in effect after events, given
+// the set in effect before them, as the key state tracks it: an inception
+// designates its "b" witnesses and a rotation removes "br" and adds "ba".
+// Witnesses claimed only in Data are ignored.
+func DesignatedWitnesses(events []KeyEvent, witnesses []string) []string {
+	for i := range events {
+		if events[i].Type != EventInception && events[i].Type != EventRotation {
+			continue
+		}
+		body, err := decodeEventBody(events[i].Raw)
+		if err != nil {
+			continue
+		}
+		if designated, err := designate(events[i].Type, body, witnesses); err == nil {
+			witnesses = designated
+		}
+	}
+	return witnesses
+}
+
+// designate returns the witnesses designated after an establishment event,
+// given those before it.
+func designate(typ string, body *eventBody, witnesses []string) ([]string, error) {
+	if typ == EventInception {
+		return slices.Clone(body.Witnesses), nil
+	}
+	designated := slices.Clone(witnesses)
+	for _, cut := range body.WitnessCuts {
+		i := slices.Index(designated, cut)
+		if i < 0 {
+			return nil, fmt.Errorf("witness %q removed but not designated", cut)
+		}
+		designated = slices.Delete(designated, i, i+1)
+	}
+	for _, add := range body.WitnessAdds {
+		if slices.Contains(designated, add) {
+			return nil, fmt.Errorf("witness %q added but already designated", add)
+		}
+		designated = append(designated, add)
+	}
+	return designated, nil
+}
+
+// countReceipts counts distinct designated witnesses with a valid receipt
+// on event
+func countReceipts(event *KeyEvent, witnesses []string) int {
+	if event.Raw == "" {
+		return 0
+	}
+	valid := make(map[string]bool)
+	for _, r := range event.Receipts {
+		if valid[r.Witness] || !slices.Contains(witnesses, r.Witness) {
+			continue
+		}
+		key, err := decodeVerKey(r.Witness)
+		if err != nil {
+			continue
+		}
+		_, sig, err := decodeSignature(r.Signature, 0)
+		if err != nil {
+			continue
+		}
+		if ed25519.Verify(key, []byte(event.Raw), sig) {
+			valid[r.Witness] = true
+		}
+	}
+	return len(valid)
+}
+
+// EventAnchors returns the SAIDs an event anchors, read from the "a" field
+// of its raw serialization: plain SAIDs or seals carrying one in "d". Events
+// whose serialization doesn't decode anchor nothing.
+func EventAnchors(event *KeyEvent) []string {
+	body, err := decodeEventBody(event.Raw)
+	if err != nil {
+		return nil
+	}
+	var anchors []string
+	for _, a := range body.Anchors {
+		switch seal := a.(type) {
+		case string:
+			if seal != "" {
+				anchors = append(anchors, seal)
+			}
+		case map[string]any:
+			if d, ok := seal["d"].(string); ok && d != "" {
+				anchors = append(anchors, d)
+			}
+		}
+	}
+	return anchors
+}
+
+// EncodeWitnessAID encodes an Ed25519 public key as a non-transferable CESR
+// identifier ("B" prefix), as used for witnesses
+func EncodeWitnessAID(key ed25519.PublicKey) string {
+	return "B" + EncodeVerKey(key)[1:]
+}
+
+// EncodeSignature encodes an Ed25519 signature as an unindexed CESR
+// signature ("0B" prefix), as used for witness receipts
+func EncodeSignature(sig []byte) string {
+	return "0B" + EncodeIndexedSignature(0, sig)[2:]
+}
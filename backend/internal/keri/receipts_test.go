@@ -0,0 +1,160 @@
+package keri
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// witnessedKEL returns an icp designating the witnesses' AIDs, followed by an
+// ixn, with no receipts attached.
+func witnessedKEL(witnesses ...ed25519.PrivateKey) []KeyEvent {
+	keys := testKeys(2)
+	icp := testEvent(EventInception, 0, "", "", keyFields(1, keys[0])+nextFields(keys[1])+witnessFields("b", witnesses...), keys[0])
+	ixn := anchoring(1, icp.Digest, icp.Digest, "ESAID001", keys[0])
+	return []KeyEvent{icp, ixn}
+}
+
+// witnessFields returns the named witness list field ("b", "br" or "ba")
+// for the witnesses' AIDs.
+func witnessFields(field string, witnesses ...ed25519.PrivateKey) string {
+	aids := make([]string, len(witnesses))
+	for i, w := range witnesses {
+		aids[i] = EncodeWitnessAID(w.Public().(ed25519.PublicKey))
+	}
+	list, _ := json.Marshal(aids)
+	return fmt.Sprintf(`,%q:%s`, field, list)
+}
+
+// receipt signs event's raw serialization with witness.
+func receipt(event KeyEvent, witness ed25519.PrivateKey) Receipt {
+	return Receipt{
+		Witness:   EncodeWitnessAID(witness.Public().(ed25519.PublicKey)),
+		Signature: EncodeSignature(ed25519.Sign(witness, []byte(event.Raw))),
+	}
+}
+
+func TestValidateReceipts_ThresholdMet(t *testing.T) {
+	witnesses := testKeys(3)
+	events := witnessedKEL(witnesses...)
+	events[0].Receipts = []Receipt{receipt(events[0], witnesses[0]), receipt(events[0], witnesses[1])}
+	events[1].Receipts = []Receipt{receipt(events[1], witnesses[2]), receipt(events[1], witnesses[0])}
+
+	got := ValidateReceipts(events, nil, 2)
+	if !got[0] || !got[1] {
+		t.Errorf("witnessed = %v, want [true true]", got)
+	}
+}
+
+func TestValidateReceipts_ThresholdMissed(t *testing.T) {
+	witnesses := testKeys(3)
+	events := witnessedKEL(witnesses...)
+	events[0].Receipts = []Receipt{receipt(events[0], witnesses[0])}
+	// A duplicate receipt from the same witness counts once
+	events[1].Receipts = []Receipt{receipt(events[1], witnesses[1]), receipt(events[1], witnesses[1])}
+
+	got := ValidateReceipts(events, nil, 2)
+	if got[0] || got[1] {
+		t.Errorf("witnessed = %v, want [false false]", got)
+	}
+}
+
+func TestValidateReceipts_UndesignatedWitness(t *testing.T) {
+	keys := testKeys(2)
+	events := witnessedKEL(keys[0])
+	events[0].Receipts = []Receipt{receipt(events[0], keys[1])}
+
+	if got := ValidateReceipts(events, nil, 1); got[0] {
+		t.Error("expected receipt from undesignated witness to be ignored")
+	}
+}
+
+func TestValidateReceipts_BadSignature(t *testing.T) {
+	witnesses := testKeys(1)
+	events := witnessedKEL(witnesses...)
+	// Receipt for the icp attached to the ixn
+	events[1].Receipts = []Receipt{receipt(events[0], witnesses[0])}
+
+	if got := ValidateReceipts(events, nil, 1); got[1] {
+		t.Error("expected receipt with invalid signature to be rejected")
+	}
+}
+
+func TestValidateReceipts_StoredWitnesses(t *testing.T) {
+	witnesses := testKeys(1)
+	events := witnessedKEL(witnesses...)
+	designated := DesignatedWitnesses(events[:1], nil)
+	if len(designated) != 1 {
+		t.Fatalf("expected 1 designated witness, got %v", designated)
+	}
+
+	// Continuing a stored log uses the witnesses designated before it
+	ixn := events[1]
+	ixn.Receipts = []Receipt{receipt(ixn, witnesses[0])}
+	if got := ValidateReceipts([]KeyEvent{ixn}, designated, 1); !got[0] {
+		t.Error("expected continuation to be witnessed by stored witness set")
+	}
+	if got := ValidateReceipts([]KeyEvent{ixn}, nil, 1); got[0] {
+		t.Error("expected continuation without a witness set to be unwitnessed")
+	}
+}
+
+func TestDesignatedWitnesses_Rotation(t *testing.T) {
+	witnesses := testKeys(3)
+	keys := testKeys(2)
+	events := witnessedKEL(witnesses[0], witnesses[1])
+	rot := testEvent(EventRotation, 2, events[0].Digest, events[1].Digest,
+		keyFields(1, keys[1])+nextFields(keys[1])+witnessFields("br", witnesses[0])+witnessFields("ba", witnesses[2]), keys[1])
+	events = append(events, rot)
+	if err := VerifyKEL(events[0].Digest, events, nil); err != nil {
+		t.Fatalf("test KEL is invalid: %v", err)
+	}
+
+	got := DesignatedWitnesses(events, nil)
+	want := []string{
+		EncodeWitnessAID(witnesses[1].Public().(ed25519.PublicKey)),
+		EncodeWitnessAID(witnesses[2].Public().(ed25519.PublicKey)),
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("DesignatedWitnesses = %v, want %v", got, want)
+	}
+	state, err := KeyStateAt(events, 2)
+	if err != nil || !slices.Equal(state.Witnesses, want) {
+		t.Errorf("key state witnesses = %v (err %v), want %v", state.Witnesses, err, want)
+	}
+
+	// The rotation's receipts count against the rotated witness set
+	rot.Receipts = []Receipt{receipt(rot, witnesses[0]), receipt(rot, witnesses[2])}
+	if got := ValidateReceipts([]KeyEvent{rot}, DesignatedWitnesses(events[:2], nil), 2); got[0] {
+		t.Error("expected a receipt from a removed witness not to count")
+	}
+}
+
+func TestDesignatedWitnesses_IgnoresData(t *testing.T) {
+	witnesses := testKeys(2)
+	events := witnessedKEL()
+	events[0].Data = map[string]any{"witnesses": []any{EncodeWitnessAID(witnesses[1].Public().(ed25519.PublicKey))}}
+	events[0].Receipts = []Receipt{receipt(events[0], witnesses[1])}
+
+	if got := DesignatedWitnesses(events, nil); len(got) != 0 {
+		t.Errorf("expected no designated witnesses, got %v", got)
+	}
+	if got := ValidateReceipts(events, nil, 1); got[0] {
+		t.Error("expected a receipt from a witness claimed only in Data not to count")
+	}
+}
+
+func TestEventAnchors(t *testing.T) {
+	key := testKeys(1)[0]
+	ixn := testEvent(EventInteraction, 1, "EAID", "EPRIOR", `,"a":["ESAID001",{"i":"EAID","d":"ESAID002"},""]`, key)
+	got := EventAnchors(&ixn)
+	if len(got) != 2 || got[0] != "ESAID001" || got[1] != "ESAID002" {
+		t.Errorf("EventAnchors = %v, want [ESAID001 ESAID002]", got)
+	}
+	// Anchors claimed only in Data don't count
+	if got := EventAnchors(&KeyEvent{Data: map[string]any{"anchor": "ESAID003"}}); len(got) != 0 {
+		t.Errorf("EventAnchors without raw = %v, want empty", got)
+	}
+}
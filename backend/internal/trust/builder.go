@@ -2,7 +2,10 @@ package trust
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"sort"
 	"time"
 
 	"github.com/matou-dao/backend/internal/anystore"
@@ -32,16 +35,16 @@ func (b *Builder) WithExtraCredentials(creds []*anystore.CachedCredential) *Buil
 
 // Build constructs the trust graph from all cached credentials
 func (b *Builder) Build(ctx context.Context) (*Graph, error) {
-	graph := NewGraph(b.orgAID)
-
-	// Add organization as root node
-	graph.AddNode(&Node{
-		AID:      b.orgAID,
-		Alias:    "matou",
-		Role:     "Organization",
-		JoinedAt: time.Time{}, // Unknown
-	})
+	credentials, err := b.Credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return b.BuildFrom(credentials), nil
+}
 
+// Credentials returns the credential set the graph is built from: all cached
+// credentials merged with any extra credentials.
+func (b *Builder) Credentials(ctx context.Context) ([]*anystore.CachedCredential, error) {
 	// Get all cached credentials
 	credentials, err := b.getAllCredentials(ctx)
 	if err != nil {
@@ -62,6 +65,21 @@ func (b *Builder) Build(ctx context.Context) (*Graph, error) {
 		}
 	}
 
+	return credentials, nil
+}
+
+// BuildFrom constructs the trust graph from the given credentials
+func (b *Builder) BuildFrom(credentials []*anystore.CachedCredential) *Graph {
+	graph := NewGraph(b.orgAID)
+
+	// Add organization as root node
+	graph.AddNode(&Node{
+		AID:      b.orgAID,
+		Alias:    "matou",
+		Role:     "Organization",
+		JoinedAt: time.Time{}, // Unknown
+	})
+
 	// Process each credential
 	for _, cred := range credentials {
 		b.processCredential(graph, cred)
@@ -73,7 +91,31 @@ func (b *Builder) Build(ctx context.Context) (*Graph, error) {
 	// Update timestamp
 	graph.Updated = time.Now().UTC()
 
-	return graph, nil
+	return graph
+}
+
+// Fingerprint returns a stable hash of a credential set, independent of
+// order, that changes whenever a credential is added, removed or altered.
+// Data is canonicalized so a typed payload and the map it is read back as
+// from the cache hash the same.
+func Fingerprint(credentials []*anystore.CachedCredential) string {
+	lines := make([]string, 0, len(credentials))
+	for _, c := range credentials {
+		data, _ := json.Marshal(c.Data)
+		var canonical interface{}
+		if json.Unmarshal(data, &canonical) == nil {
+			data, _ = json.Marshal(canonical)
+		}
+		lines = append(lines, c.ID+"|"+c.IssuerAID+"|"+c.SubjectAID+"|"+c.SchemaID+"|"+string(data))
+	}
+	sort.Strings(lines)
+
+	h := sha256.New()
+	for _, line := range lines {
+		h.Write([]byte(line))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))
 }
 
 // getAllCredentials retrieves all credentials from the cache
@@ -161,6 +203,39 @@ func (b *Builder) processCredential(graph *Graph, cred *anystore.CachedCredentia
 	graph.AddEdge(edge)
 }
 
+// ApplyCredential adds a single credential to an existing graph, as Build
+// would have, and returns the AIDs whose node or edges changed. Only the new
+// edge and its reverse are marked bidirectional rather than re-marking every
+// edge as Build does, but finding a duplicate and the reverse still scans the
+// edges, so the cost grows with the graph.
+func (b *Builder) ApplyCredential(graph *Graph, cred *anystore.CachedCredential) []string {
+	var reverse []*Edge
+	for _, e := range graph.Edges {
+		if e.CredentialID == cred.ID {
+			return nil // Already applied
+		}
+		if e.From == cred.SubjectAID && e.To == cred.IssuerAID {
+			reverse = append(reverse, e)
+		}
+	}
+
+	edgeCount := len(graph.Edges)
+	b.processCredential(graph, cred)
+	graph.Updated = time.Now().UTC()
+
+	if len(graph.Edges) == edgeCount {
+		// Self-claim: only the subject node changed
+		return []string{cred.SubjectAID}
+	}
+
+	edge := graph.Edges[len(graph.Edges)-1]
+	for _, e := range reverse {
+		e.Bidirectional = true
+		edge.Bidirectional = true
+	}
+	return []string{cred.IssuerAID, cred.SubjectAID}
+}
+
 // credentialData holds extracted data from a credential
 type credentialData struct {
 	role        string
@@ -2,8 +2,11 @@ package trust
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"testing"
 	"time"
 
@@ -484,3 +487,197 @@ func TestBuilder_Build_StoresPersistently(t *testing.T) {
 		}
 	}
 }
+
+// generateCredentials returns n deterministic credentials: org-issued
+// memberships for the first tenth, invitations between earlier and later
+// members for the rest, with periodic reverse invitations and self-claims
+// that arrive before the member is invited.
+func generateCredentials(n int) []*anystore.CachedCredential {
+	user := func(i int) string { return fmt.Sprintf("EUSER%05d", i) }
+	inviter := func(i int) int { return int(uint32(i) * 2654435761 % uint32(i)) }
+	members := n / 10
+	if members == 0 {
+		members = 1
+	}
+
+	creds := make([]*anystore.CachedCredential, 0, n)
+	for i := 0; len(creds) < n; i++ {
+		id := fmt.Sprintf("ESAID%05d", len(creds))
+		switch {
+		case i < members:
+			creds = append(creds, &anystore.CachedCredential{
+				ID: id, IssuerAID: "EORG123", SubjectAID: user(i), SchemaID: "EMatouMembershipSchemaV1",
+				Data: map[string]interface{}{"role": "Member", "joinedAt": "2026-01-19T00:00:00Z"},
+			})
+		case i%25 == 0:
+			// Reverse of the invitation that brought user(i-1) in
+			creds = append(creds, &anystore.CachedCredential{
+				ID: id, IssuerAID: user(i - 1), SubjectAID: user(inviter(i - 1)), SchemaID: "EInvitationSchemaV1",
+			})
+		case i%40 == 0:
+			creds = append(creds, &anystore.CachedCredential{
+				ID: id, IssuerAID: user(i + 1), SubjectAID: user(i + 1), SchemaID: "ESelfClaimSchemaV1",
+				Data: map[string]interface{}{"displayName": fmt.Sprintf("user %d", i+1)},
+			})
+		default:
+			creds = append(creds, &anystore.CachedCredential{
+				ID: id, IssuerAID: user(inviter(i)), SubjectAID: user(i), SchemaID: "EInvitationSchemaV1",
+			})
+		}
+	}
+	return creds
+}
+
+func TestBuilder_ApplyCredential_ConvergesToBuild(t *testing.T) {
+	builder := NewBuilder(nil, "EORG123")
+	calc := NewDefaultCalculator()
+	creds := generateCredentials(500)
+
+	full := builder.BuildFrom(creds)
+	fullScores := calc.CalculateAllScores(full)
+
+	// Cold start from a prefix, then apply the rest one at a time
+	graph := builder.BuildFrom(creds[:100])
+	scores := calc.CalculateAllScores(graph)
+	for _, cred := range creds[100:] {
+		changed := builder.ApplyCredential(graph, cred)
+		calc.UpdateScores(graph, scores, changed)
+	}
+
+	if !reflect.DeepEqual(graph.Nodes, full.Nodes) {
+		t.Errorf("incremental nodes differ from full rebuild (%d vs %d nodes)", len(graph.Nodes), len(full.Nodes))
+	}
+	if !reflect.DeepEqual(graph.Edges, full.Edges) {
+		t.Errorf("incremental edges differ from full rebuild (%d vs %d edges)", len(graph.Edges), len(full.Edges))
+	}
+	for aid, want := range fullScores {
+		if got := scores[aid]; !reflect.DeepEqual(got, want) {
+			t.Errorf("score for %s = %+v, want %+v", aid, got, want)
+		}
+	}
+	if len(scores) != len(fullScores) {
+		t.Errorf("expected %d scores, got %d", len(fullScores), len(scores))
+	}
+}
+
+func TestBuilder_ApplyCredential_Duplicate(t *testing.T) {
+	builder := NewBuilder(nil, "EORG123")
+	creds := generateCredentials(20)
+	graph := builder.BuildFrom(creds)
+
+	if changed := builder.ApplyCredential(graph, creds[5]); changed != nil {
+		t.Errorf("expected no change for an already applied credential, got %v", changed)
+	}
+	if graph.EdgeCount() != builder.BuildFrom(creds).EdgeCount() {
+		t.Error("duplicate credential added an edge")
+	}
+}
+
+// checkBidirectionalCache compares graph's cached bidirectional pairs and
+// node lists against computing them from the edges on demand.
+func checkBidirectionalCache(t *testing.T, graph *Graph) {
+	t.Helper()
+	onDemand := &Graph{Nodes: graph.Nodes, Edges: graph.Edges, OrgAID: graph.OrgAID}
+
+	if got, want := graph.BidirectionalCount(), onDemand.BidirectionalCount(); got != want {
+		t.Errorf("BidirectionalCount() = %d, on demand %d", got, want)
+	}
+	aids := make([]string, 0, len(graph.Nodes))
+	for aid := range graph.Nodes {
+		aids = append(aids, aid)
+	}
+	for _, a := range aids {
+		var want []string
+		for _, b := range aids {
+			cached := graph.HasBidirectionalRelation(a, b)
+			if computed := onDemand.HasBidirectionalRelation(a, b); cached != computed {
+				t.Fatalf("HasBidirectionalRelation(%s, %s) = %v, on demand %v", a, b, cached, computed)
+			}
+			if cached {
+				want = append(want, b)
+			}
+		}
+		sort.Strings(want)
+		if got := graph.Nodes[a].BidirectionalWith; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s BidirectionalWith = %v, want %v", a, got, want)
+		}
+	}
+}
+
+func TestGraph_BidirectionalCacheMatchesOnDemand(t *testing.T) {
+	builder := NewBuilder(nil, "EORG123")
+	creds := generateCredentials(200)
+
+	full := builder.BuildFrom(creds)
+	if full.BidirectionalCount() == 0 {
+		t.Fatal("expected the generated credentials to include bidirectional pairs")
+	}
+	checkBidirectionalCache(t, full)
+
+	// Applied one at a time, to a clone of the cold-start graph
+	base := builder.BuildFrom(creds[:50])
+	graph := base.Clone()
+	for _, cred := range creds[50:] {
+		builder.ApplyCredential(graph, cred)
+	}
+	checkBidirectionalCache(t, graph)
+	checkBidirectionalCache(t, base)
+	if graph.BidirectionalCount() == base.BidirectionalCount() {
+		t.Error("expected applying credentials to add bidirectional pairs")
+	}
+}
+
+func BenchmarkTrustFullRebuild10k(b *testing.B) {
+	builder := NewBuilder(nil, "EORG123")
+	calc := NewDefaultCalculator()
+	creds := generateCredentials(10000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		graph := builder.BuildFrom(creds)
+		calc.CalculateAllScores(graph)
+	}
+}
+
+func BenchmarkTrustIncremental10k(b *testing.B) {
+	builder := NewBuilder(nil, "EORG123")
+	calc := NewDefaultCalculator()
+	creds := generateCredentials(10000)
+	last := creds[len(creds)-1]
+
+	base := builder.BuildFrom(creds[:len(creds)-1])
+	baseScores := calc.CalculateAllScores(base)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		graph := base.Clone()
+		scores := make(map[string]*Score, len(baseScores))
+		for aid, s := range baseScores {
+			scores[aid] = s
+		}
+		b.StartTimer()
+
+		changed := builder.ApplyCredential(graph, last)
+		calc.UpdateScores(graph, scores, changed)
+	}
+}
+
+func BenchmarkTrustSummary10k(b *testing.B) {
+	builder := NewBuilder(nil, "EORG123")
+	calc := NewDefaultCalculator()
+	graph := builder.BuildFrom(generateCredentials(10000))
+	scores := calc.CalculateAllScores(graph)
+	aids := make([]string, 0, len(graph.Nodes))
+	for aid := range graph.Nodes {
+		aids = append(aids, aid)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		calc.Summarize(graph, scores)
+		for j, aid := range aids[:100] {
+			graph.HasBidirectionalRelation(aid, aids[(j+1)%len(aids)])
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package trust
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Verification status of a node in exported graphs
+const (
+	VerificationOrganization = "organization"
+	VerificationVerified     = "verified"
+	VerificationUnverified   = "unverified"
+)
+
+// verificationColors maps verification status to a node fill color
+var verificationColors = map[string]string{
+	VerificationOrganization: "gold",
+	VerificationVerified:     "palegreen",
+	VerificationUnverified:   "lightgrey",
+}
+
+// Verification returns the verification status for each node: the org
+// itself, members reachable from the org through issued credentials, and
+// everyone else (e.g. identities known only from self-claims).
+func (g *Graph) Verification() map[string]string {
+	status := make(map[string]string, len(g.Nodes))
+	for aid := range g.Nodes {
+		status[aid] = VerificationUnverified
+	}
+
+	queue := []string{g.OrgAID}
+	seen := map[string]bool{g.OrgAID: true}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, edge := range g.GetEdgesFrom(current) {
+			if !seen[edge.To] {
+				seen[edge.To] = true
+				status[edge.To] = VerificationVerified
+				queue = append(queue, edge.To)
+			}
+		}
+	}
+
+	if _, ok := g.Nodes[g.OrgAID]; ok {
+		status[g.OrgAID] = VerificationOrganization
+	}
+	return status
+}
+
+// sortedNodes returns the graph's nodes ordered by AID for stable output
+func (g *Graph) sortedNodes() []*Node {
+	nodes := make([]*Node, 0, len(g.Nodes))
+	for _, n := range g.Nodes {
+		nodes = append(nodes, n)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].AID < nodes[j].AID })
+	return nodes
+}
+
+// nodeLabel returns the display name (falling back to the AID)
+func nodeLabel(n *Node) string {
+	if n.Alias != "" {
+		return n.Alias
+	}
+	return n.AID
+}
+
+// WriteDOT writes the graph in Graphviz DOT format. Nodes are labelled with
+// display name and role and filled by verification status; edges are
+// labelled with the credential type.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	status := g.Verification()
+
+	fmt.Fprintln(bw, "digraph trust {")
+	fmt.Fprintln(bw, "  node [shape=box, style=filled];")
+	for _, n := range g.sortedNodes() {
+		fmt.Fprintf(bw, "  %s [label=%s, role=%s, verification=%s, fillcolor=%s];\n",
+			dotQuote(n.AID),
+			dotQuote(nodeLabel(n)+"\n"+n.Role),
+			dotQuote(n.Role),
+			dotQuote(status[n.AID]),
+			dotQuote(verificationColors[status[n.AID]]),
+		)
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(bw, "  %s -> %s [label=%s, credential=%s];\n",
+			dotQuote(e.From),
+			dotQuote(e.To),
+			dotQuote(e.Type),
+			dotQuote(e.CredentialID),
+		)
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// dotQuote returns s as a quoted DOT identifier
+func dotQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// WriteGraphML writes the graph in GraphML format with node label, role,
+// verification and color attributes and edge label and credential attributes.
+func (g *Graph) WriteGraphML(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	status := g.Verification()
+
+	fmt.Fprintln(bw, xml.Header+`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">`)
+	fmt.Fprintln(bw, `  <key id="label" for="node" attr.name="label" attr.type="string"/>`)
+	fmt.Fprintln(bw, `  <key id="role" for="node" attr.name="role" attr.type="string"/>`)
+	fmt.Fprintln(bw, `  <key id="verification" for="node" attr.name="verification" attr.type="string"/>`)
+	fmt.Fprintln(bw, `  <key id="color" for="node" attr.name="color" attr.type="string"/>`)
+	fmt.Fprintln(bw, `  <key id="type" for="edge" attr.name="label" attr.type="string"/>`)
+	fmt.Fprintln(bw, `  <key id="credential" for="edge" attr.name="credentialId" attr.type="string"/>`)
+	fmt.Fprintln(bw, `  <graph id="trust" edgedefault="directed">`)
+	for _, n := range g.sortedNodes() {
+		fmt.Fprintf(bw, "    <node id=%s>\n", xmlAttr(n.AID))
+		writeGraphMLData(bw, "label", nodeLabel(n))
+		writeGraphMLData(bw, "role", n.Role)
+		writeGraphMLData(bw, "verification", status[n.AID])
+		writeGraphMLData(bw, "color", verificationColors[status[n.AID]])
+		fmt.Fprintln(bw, "    </node>")
+	}
+	for i, e := range g.Edges {
+		fmt.Fprintf(bw, "    <edge id=\"e%d\" source=%s target=%s>\n", i, xmlAttr(e.From), xmlAttr(e.To))
+		writeGraphMLData(bw, "type", e.Type)
+		writeGraphMLData(bw, "credential", e.CredentialID)
+		fmt.Fprintln(bw, "    </edge>")
+	}
+	fmt.Fprintln(bw, "  </graph>")
+	fmt.Fprintln(bw, "</graphml>")
+	return bw.Flush()
+}
+
+// writeGraphMLData writes a <data> element with escaped text
+func writeGraphMLData(w io.Writer, key, value string) {
+	fmt.Fprintf(w, "      <data key=%q>", key)
+	xml.EscapeText(w, []byte(value))
+	fmt.Fprintln(w, "</data>")
+}
+
+// xmlAttr returns s as a quoted, escaped XML attribute value
+func xmlAttr(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return `"` + b.String() + `"`
+}
@@ -0,0 +1,98 @@
+package trust
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestGraph_Verification(t *testing.T) {
+	g := chainGraph()
+
+	status := g.Verification()
+	want := map[string]string{
+		"EORG":      VerificationOrganization,
+		"EUSER1":    VerificationVerified,
+		"EUSER2":    VerificationVerified,
+		"EOUTSIDER": VerificationUnverified,
+	}
+	for aid, v := range want {
+		if status[aid] != v {
+			t.Errorf("%s verification = %q, want %q", aid, status[aid], v)
+		}
+	}
+}
+
+func TestGraph_WriteDOT(t *testing.T) {
+	g := chainGraph()
+	g.Nodes["EUSER1"].Alias = `alice "al"`
+	g.Nodes["EUSER1"].Role = "Member"
+
+	var buf bytes.Buffer
+	if err := g.WriteDOT(&buf); err != nil {
+		t.Fatalf("WriteDOT: %v", err)
+	}
+	out := buf.String()
+
+	if !strings.HasPrefix(out, "digraph trust {\n") || !strings.HasSuffix(out, "}\n") {
+		t.Errorf("unexpected DOT framing:\n%s", out)
+	}
+	if got := strings.Count(out, " -> "); got != len(g.Edges) {
+		t.Errorf("expected %d edges, got %d", len(g.Edges), got)
+	}
+	if got := strings.Count(out, "verification="); got != len(g.Nodes) {
+		t.Errorf("expected %d nodes, got %d", len(g.Nodes), got)
+	}
+	if !strings.Contains(out, `"EUSER1" [label="alice \"al\"\nMember"`) {
+		t.Errorf("expected escaped label with role for EUSER1:\n%s", out)
+	}
+	if !strings.Contains(out, `"EORG" -> "EUSER1" [label="membership"`) {
+		t.Errorf("expected membership edge label:\n%s", out)
+	}
+}
+
+func TestGraph_WriteGraphML(t *testing.T) {
+	g := chainGraph()
+	g.Nodes["EUSER2"].Alias = "bob <b&b>"
+
+	var buf bytes.Buffer
+	if err := g.WriteGraphML(&buf); err != nil {
+		t.Fatalf("WriteGraphML: %v", err)
+	}
+
+	var doc struct {
+		Graph struct {
+			Nodes []struct {
+				ID   string `xml:"id,attr"`
+				Data []struct {
+					Key   string `xml:"key,attr"`
+					Value string `xml:",chardata"`
+				} `xml:"data"`
+			} `xml:"node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+				Target string `xml:"target,attr"`
+			} `xml:"edge"`
+		} `xml:"graph"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid GraphML: %v\n%s", err, buf.String())
+	}
+	if len(doc.Graph.Nodes) != len(g.Nodes) {
+		t.Errorf("expected %d nodes, got %d", len(g.Nodes), len(doc.Graph.Nodes))
+	}
+	if len(doc.Graph.Edges) != len(g.Edges) {
+		t.Errorf("expected %d edges, got %d", len(g.Edges), len(doc.Graph.Edges))
+	}
+	for _, n := range doc.Graph.Nodes {
+		if n.ID != "EUSER2" {
+			continue
+		}
+		for _, d := range n.Data {
+			if d.Key == "label" && d.Value != "bob <b&b>" {
+				t.Errorf("label = %q, want unescaped round-trip", d.Value)
+			}
+		}
+	}
+}
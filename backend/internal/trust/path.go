@@ -0,0 +1,99 @@
+package trust
+
+import "sort"
+
+// PathHop is one step in a trust path, with the credentials linking the two AIDs
+type PathHop struct {
+	From        string  `json:"from"`
+	To          string  `json:"to"`
+	Credentials []*Edge `json:"credentials"`
+}
+
+// Path is a chain of identities connected by credentials
+type Path struct {
+	AIDs   []string   `json:"aids"`
+	Hops   []*PathHop `json:"hops"`
+	Length int        `json:"length"`
+}
+
+// ShortestPath finds the shortest chain of credentials connecting two AIDs
+// using BFS. Credentials link their issuer and subject in either direction,
+// so the path answers "how are these two connected"; each hop lists the
+// credentials with their original issuer/subject. maxDepth limits the number
+// of hops (0 means unlimited). Returns nil if no path exists.
+func (g *Graph) ShortestPath(from, to string, maxDepth int) *Path {
+	if g.GetNode(from) == nil || g.GetNode(to) == nil {
+		return nil
+	}
+	if from == to {
+		return &Path{AIDs: []string{from}, Hops: []*PathHop{}}
+	}
+
+	// Undirected adjacency, plus the edges linking each pair
+	neighbors := make(map[string][]string)
+	links := make(map[[2]string][]*Edge)
+	for _, e := range g.Edges {
+		key := pairKey(e.From, e.To)
+		if len(links[key]) == 0 {
+			neighbors[e.From] = append(neighbors[e.From], e.To)
+			neighbors[e.To] = append(neighbors[e.To], e.From)
+		}
+		links[key] = append(links[key], e)
+	}
+	for _, n := range neighbors {
+		sort.Strings(n) // deterministic choice among equal-length paths
+	}
+
+	prev := map[string]string{from: ""}
+	depth := map[string]int{from: 0}
+	queue := []string{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if maxDepth > 0 && depth[current] >= maxDepth {
+			continue
+		}
+		for _, next := range neighbors[current] {
+			if _, seen := prev[next]; seen {
+				continue
+			}
+			prev[next] = current
+			depth[next] = depth[current] + 1
+			if next == to {
+				return buildPath(from, to, prev, links)
+			}
+			queue = append(queue, next)
+		}
+	}
+	return nil
+}
+
+// buildPath walks the BFS predecessor map back from to and assembles the path
+func buildPath(from, to string, prev map[string]string, links map[[2]string][]*Edge) *Path {
+	aids := []string{to}
+	for aid := to; aid != from; {
+		aid = prev[aid]
+		aids = append(aids, aid)
+	}
+	for i, j := 0, len(aids)-1; i < j; i, j = i+1, j-1 {
+		aids[i], aids[j] = aids[j], aids[i]
+	}
+
+	hops := make([]*PathHop, 0, len(aids)-1)
+	for i := 0; i < len(aids)-1; i++ {
+		hops = append(hops, &PathHop{
+			From:        aids[i],
+			To:          aids[i+1],
+			Credentials: links[pairKey(aids[i], aids[i+1])],
+		})
+	}
+	return &Path{AIDs: aids, Hops: hops, Length: len(hops)}
+}
+
+// pairKey returns an order-independent key for two AIDs
+func pairKey(a, b string) [2]string {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]string{a, b}
+}
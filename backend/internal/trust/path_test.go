@@ -0,0 +1,92 @@
+package trust
+
+import (
+	"reflect"
+	"testing"
+)
+
+// chainGraph builds org → user1 → user2, plus an isolated outsider.
+func chainGraph() *Graph {
+	g := NewGraph("EORG")
+	for _, aid := range []string{"EORG", "EUSER1", "EUSER2", "EOUTSIDER"} {
+		g.AddNode(&Node{AID: aid})
+	}
+	g.AddEdge(&Edge{From: "EORG", To: "EUSER1", CredentialID: "ESAID1", Type: EdgeTypeMembership})
+	g.AddEdge(&Edge{From: "EUSER1", To: "EUSER2", CredentialID: "ESAID2", Type: EdgeTypeInvitation})
+	return g
+}
+
+func TestGraph_ShortestPath_Chain(t *testing.T) {
+	g := chainGraph()
+
+	path := g.ShortestPath("EORG", "EUSER2", 0)
+	if path == nil {
+		t.Fatal("expected a path from org to user2")
+	}
+	if want := []string{"EORG", "EUSER1", "EUSER2"}; !reflect.DeepEqual(path.AIDs, want) {
+		t.Errorf("AIDs = %v, want %v", path.AIDs, want)
+	}
+	if path.Length != 2 || len(path.Hops) != 2 {
+		t.Fatalf("expected 2 hops, got length %d with %d hops", path.Length, len(path.Hops))
+	}
+	if path.Hops[0].Credentials[0].CredentialID != "ESAID1" || path.Hops[1].Credentials[0].CredentialID != "ESAID2" {
+		t.Errorf("unexpected hop credentials: %+v, %+v", path.Hops[0].Credentials[0], path.Hops[1].Credentials[0])
+	}
+}
+
+func TestGraph_ShortestPath_ReverseDirection(t *testing.T) {
+	g := chainGraph()
+
+	path := g.ShortestPath("EUSER2", "EORG", 0)
+	if path == nil {
+		t.Fatal("expected a path from user2 back to org")
+	}
+	if want := []string{"EUSER2", "EUSER1", "EORG"}; !reflect.DeepEqual(path.AIDs, want) {
+		t.Errorf("AIDs = %v, want %v", path.AIDs, want)
+	}
+	// Credentials keep their original issuer → subject direction
+	if e := path.Hops[0].Credentials[0]; e.From != "EUSER1" || e.To != "EUSER2" {
+		t.Errorf("first hop credential = %s→%s, want EUSER1→EUSER2", e.From, e.To)
+	}
+}
+
+func TestGraph_ShortestPath_PrefersShorter(t *testing.T) {
+	g := chainGraph()
+	g.AddEdge(&Edge{From: "EORG", To: "EUSER2", CredentialID: "ESAID3", Type: EdgeTypeMembership})
+
+	path := g.ShortestPath("EORG", "EUSER2", 0)
+	if path == nil || path.Length != 1 {
+		t.Fatalf("expected direct 1-hop path, got %+v", path)
+	}
+}
+
+func TestGraph_ShortestPath_Unreachable(t *testing.T) {
+	g := chainGraph()
+
+	if path := g.ShortestPath("EORG", "EOUTSIDER", 0); path != nil {
+		t.Errorf("expected no path to isolated node, got %v", path.AIDs)
+	}
+	if path := g.ShortestPath("EORG", "EUNKNOWN", 0); path != nil {
+		t.Errorf("expected no path to unknown AID, got %v", path.AIDs)
+	}
+}
+
+func TestGraph_ShortestPath_MaxDepth(t *testing.T) {
+	g := chainGraph()
+
+	if path := g.ShortestPath("EORG", "EUSER2", 1); path != nil {
+		t.Errorf("expected no path within 1 hop, got %v", path.AIDs)
+	}
+	if path := g.ShortestPath("EORG", "EUSER2", 2); path == nil {
+		t.Error("expected a path within 2 hops")
+	}
+}
+
+func TestGraph_ShortestPath_Self(t *testing.T) {
+	g := chainGraph()
+
+	path := g.ShortestPath("EUSER1", "EUSER1", 0)
+	if path == nil || path.Length != 0 || !reflect.DeepEqual(path.AIDs, []string{"EUSER1"}) {
+		t.Errorf("expected zero-length path, got %+v", path)
+	}
+}
@@ -1,5 +1,10 @@
 package trust
 
+import (
+	"math"
+	"time"
+)
+
 // ScoreWeights defines the weights for trust score calculation
 type ScoreWeights struct {
 	IncomingCredential    float64 // Weight per incoming credential
@@ -7,6 +12,9 @@ type ScoreWeights struct {
 	BidirectionalRelation float64 // Weight per bidirectional relationship
 	DepthPenalty          float64 // Penalty per level of depth from org
 	OrgIssuedBonus        float64 // Bonus for credentials issued by org
+	// DecayHalfLife is the credential age at which its contribution halves.
+	// Zero disables decay so every credential counts in full.
+	DecayHalfLife time.Duration
 }
 
 // DefaultWeights returns the default score weights
@@ -23,11 +31,12 @@ func DefaultWeights() ScoreWeights {
 // Calculator calculates trust scores from a graph
 type Calculator struct {
 	weights ScoreWeights
+	now     func() time.Time
 }
 
 // NewCalculator creates a new trust score calculator
 func NewCalculator(weights ScoreWeights) *Calculator {
-	return &Calculator{weights: weights}
+	return &Calculator{weights: weights, now: time.Now}
 }
 
 // NewDefaultCalculator creates a calculator with default weights
@@ -37,6 +46,12 @@ func NewDefaultCalculator() *Calculator {
 
 // CalculateScore calculates the trust score for a specific AID
 func (c *Calculator) CalculateScore(aid string, graph *Graph) *Score {
+	return c.scoreAtDepth(aid, graph, c.calculateDepth(aid, graph))
+}
+
+// scoreAtDepth calculates the trust score for an AID whose depth from the
+// org is already known
+func (c *Calculator) scoreAtDepth(aid string, graph *Graph, depth int) *Score {
 	score := &Score{AID: aid}
 
 	// Get node info
@@ -67,8 +82,7 @@ func (c *Calculator) CalculateScore(aid string, graph *Graph) *Score {
 		}
 	}
 
-	// Calculate graph depth using BFS from org
-	score.GraphDepth = c.calculateDepth(aid, graph)
+	score.GraphDepth = depth
 
 	// Calculate final score
 	score.Score = c.computeScore(score, graph, incomingEdges)
@@ -118,54 +132,259 @@ func (c *Calculator) calculateDepth(aid string, graph *Graph) int {
 	return -1
 }
 
-// computeScore computes the final trust score
+// decayFactor returns the multiplier applied to an edge's contribution based
+// on the age of its credential. Edges without a known issuance time, or
+// issued in the future, are not decayed.
+func (c *Calculator) decayFactor(edge *Edge) float64 {
+	if c.weights.DecayHalfLife <= 0 || edge.CreatedAt.IsZero() {
+		return 1
+	}
+	age := c.now().Sub(edge.CreatedAt)
+	if age <= 0 {
+		return 1
+	}
+	return math.Pow(0.5, float64(age)/float64(c.weights.DecayHalfLife))
+}
+
+// allDepths calculates every node's depth from the org with a single BFS
+// along issued credentials. Unreachable nodes are absent.
+func (c *Calculator) allDepths(graph *Graph) map[string]int {
+	outgoing := make(map[string][]string)
+	for _, e := range graph.Edges {
+		outgoing[e.From] = append(outgoing[e.From], e.To)
+	}
+
+	depths := map[string]int{graph.OrgAID: 0}
+	queue := []string{graph.OrgAID}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range outgoing[current] {
+			if _, seen := depths[next]; !seen {
+				depths[next] = depths[current] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+	return depths
+}
+
+// nodeDepth looks up an AID in allDepths output, -1 if unreachable
+func nodeDepth(depths map[string]int, aid string) int {
+	if d, ok := depths[aid]; ok {
+		return d
+	}
+	return -1
+}
+
+// computeScore computes the final trust score. With decay enabled each
+// incoming edge's contribution is scaled by its decay factor.
 func (c *Calculator) computeScore(s *Score, graph *Graph, incomingEdges []*Edge) float64 {
-	score := 0.0
+	return c.breakdown(s.GraphDepth, graph, incomingEdges, false).Total
+}
 
-	// Base score from incoming credentials
-	score += float64(s.IncomingCredentials) * c.weights.IncomingCredential
+// ScoreBreakdown explains a trust score as the components that sum to it.
+// Every component is already weighted and decayed.
+type ScoreBreakdown struct {
+	IncomingCredentials float64 `json:"incomingCredentials"` // per incoming credential
+	OrgIssuedBonus      float64 `json:"orgIssuedBonus"`      // per credential issued by the org
+	UniqueIssuers       float64 `json:"uniqueIssuers"`       // per distinct issuer, freshest credential
+	BidirectionalBonus  float64 `json:"bidirectionalBonus"`  // per credential in a mutual relationship
+	DepthPenalty        float64 `json:"depthPenalty"`        // negative, per level below the org
+	Floor               float64 `json:"floor"`               // added back when the sum is negative
+	Total               float64 `json:"total"`
+	// DecayFactor is how much of the credential components survived decay:
+	// their decayed sum over their undecayed sum, 1 with decay disabled
+	DecayFactor float64                  `json:"decayFactor"`
+	GraphDepth  int                      `json:"graphDepth"`
+	Credentials []CredentialContribution `json:"credentials"`
+	Weights     ExplainedWeights         `json:"weights"`
+}
 
-	// Bonus for unique issuers (diversity of trust sources)
-	score += float64(s.UniqueIssuers) * c.weights.UniqueIssuer
+// CredentialContribution is one incoming credential's share of a score
+type CredentialContribution struct {
+	CredentialID  string  `json:"credentialId"`
+	Issuer        string  `json:"issuer"`
+	Type          string  `json:"type"`
+	DecayFactor   float64 `json:"decayFactor"`
+	OrgIssued     bool    `json:"orgIssued"`
+	Bidirectional bool    `json:"bidirectional"`
+	// UniqueIssuer is set on the credential that carries its issuer's
+	// unique-issuer bonus
+	UniqueIssuer bool    `json:"uniqueIssuer"`
+	Score        float64 `json:"score"`
+}
 
-	// Bonus for bidirectional relationships (mutual trust)
-	score += float64(s.BidirectionalRelations) * c.weights.BidirectionalRelation
+// ExplainedWeights are the weights a breakdown was computed with
+type ExplainedWeights struct {
+	IncomingCredential    float64 `json:"incomingCredential"`
+	UniqueIssuer          float64 `json:"uniqueIssuer"`
+	BidirectionalRelation float64 `json:"bidirectionalRelation"`
+	DepthPenalty          float64 `json:"depthPenalty"`
+	OrgIssuedBonus        float64 `json:"orgIssuedBonus"`
+	DecayHalfLifeDays     float64 `json:"decayHalfLifeDays,omitempty"`
+}
 
-	// Bonus for org-issued credentials
-	for _, edge := range incomingEdges {
+// ExplainScore returns the breakdown of aid's trust score
+func (c *Calculator) ExplainScore(aid string, graph *Graph) *ScoreBreakdown {
+	b := c.breakdown(c.calculateDepth(aid, graph), graph, graph.GetEdgesTo(aid), true)
+	return &b
+}
+
+// breakdown computes the score components for a node at depth with the
+// given incoming edges. Per-credential contributions are only collected when
+// withCredentials is set; plain scoring still tracks each issuer's freshest
+// credential for the unique-issuer bonus.
+func (c *Calculator) breakdown(depth int, graph *Graph, incomingEdges []*Edge, withCredentials bool) ScoreBreakdown {
+	b := ScoreBreakdown{GraphDepth: depth, DecayFactor: 1}
+	undecayed := 0.0
+
+	// Freshest credential from each issuer counts toward issuer diversity
+	issuerFactors := make(map[string]float64)
+	var issuerEdge map[string]int // issuer -> index of that credential
+	if withCredentials {
+		issuerEdge = make(map[string]int)
+	}
+
+	for i, edge := range incomingEdges {
+		factor := c.decayFactor(edge)
+		weight := c.weights.IncomingCredential
+
+		// Base score from incoming credentials
+		b.IncomingCredentials += factor * c.weights.IncomingCredential
+
+		// Bonus for bidirectional relationships (mutual trust)
+		if edge.Bidirectional {
+			b.BidirectionalBonus += factor * c.weights.BidirectionalRelation
+			weight += c.weights.BidirectionalRelation
+		}
+
+		// Bonus for org-issued credentials
 		if edge.From == graph.OrgAID {
-			score += c.weights.OrgIssuedBonus
+			b.OrgIssuedBonus += factor * c.weights.OrgIssuedBonus
+			weight += c.weights.OrgIssuedBonus
+		}
+
+		if factor > issuerFactors[edge.From] {
+			issuerFactors[edge.From] = factor
+			if withCredentials {
+				issuerEdge[edge.From] = i
+			}
+		}
+		undecayed += weight
+
+		if withCredentials {
+			b.Credentials = append(b.Credentials, CredentialContribution{
+				CredentialID:  edge.CredentialID,
+				Issuer:        edge.From,
+				Type:          edge.Type,
+				DecayFactor:   factor,
+				OrgIssued:     edge.From == graph.OrgAID,
+				Bidirectional: edge.Bidirectional,
+				Score:         factor * weight,
+			})
+		}
+	}
+
+	// Bonus for unique issuers (diversity of trust sources)
+	for issuer, factor := range issuerFactors {
+		b.UniqueIssuers += factor * c.weights.UniqueIssuer
+		undecayed += c.weights.UniqueIssuer
+		if withCredentials {
+			contribution := &b.Credentials[issuerEdge[issuer]]
+			contribution.UniqueIssuer = true
+			contribution.Score += factor * c.weights.UniqueIssuer
 		}
 	}
 
 	// Penalty for depth (closer to org = higher trust)
-	if s.GraphDepth > 0 {
-		score -= float64(s.GraphDepth) * c.weights.DepthPenalty
+	if depth > 0 {
+		b.DepthPenalty = -float64(depth) * c.weights.DepthPenalty
+	}
+
+	credentialTotal := b.IncomingCredentials + b.BidirectionalBonus + b.OrgIssuedBonus + b.UniqueIssuers
+	if undecayed > 0 {
+		b.DecayFactor = credentialTotal / undecayed
 	}
 
 	// Ensure score is not negative
-	if score < 0 {
-		score = 0
+	b.Total = credentialTotal + b.DepthPenalty
+	if b.Total < 0 {
+		b.Floor = -b.Total
+		b.Total = 0
 	}
 
-	return score
+	if withCredentials {
+		if b.Credentials == nil {
+			b.Credentials = []CredentialContribution{}
+		}
+		b.Weights = ExplainedWeights{
+			IncomingCredential:    c.weights.IncomingCredential,
+			UniqueIssuer:          c.weights.UniqueIssuer,
+			BidirectionalRelation: c.weights.BidirectionalRelation,
+			DepthPenalty:          c.weights.DepthPenalty,
+			OrgIssuedBonus:        c.weights.OrgIssuedBonus,
+			DecayHalfLifeDays:     c.weights.DecayHalfLife.Hours() / 24,
+		}
+	}
+	return b
 }
 
 // CalculateAllScores calculates trust scores for all nodes in the graph
 func (c *Calculator) CalculateAllScores(graph *Graph) map[string]*Score {
 	scores := make(map[string]*Score)
+	depths := c.allDepths(graph)
 
 	for aid := range graph.Nodes {
-		scores[aid] = c.CalculateScore(aid, graph)
+		scores[aid] = c.scoreAtDepth(aid, graph, nodeDepth(depths, aid))
 	}
 
 	return scores
 }
 
+// UpdateScores refreshes scores after the given AIDs changed (e.g. from
+// Builder.ApplyCredential). Only those nodes, their neighbors and nodes whose
+// depth from the org moved are recomputed; scores is updated in place and the
+// recomputed AIDs are returned.
+func (c *Calculator) UpdateScores(graph *Graph, scores map[string]*Score, changed []string) []string {
+	changedSet := make(map[string]bool, len(changed))
+	affected := make(map[string]bool)
+	for _, aid := range changed {
+		changedSet[aid] = true
+		affected[aid] = true
+	}
+	for _, e := range graph.Edges {
+		if changedSet[e.From] || changedSet[e.To] {
+			affected[e.From] = true
+			affected[e.To] = true
+		}
+	}
+
+	depths := c.allDepths(graph)
+	for aid := range graph.Nodes {
+		if s, ok := scores[aid]; !ok || s.GraphDepth != nodeDepth(depths, aid) {
+			affected[aid] = true
+		}
+	}
+
+	updated := make([]string, 0, len(affected))
+	for aid := range affected {
+		if graph.GetNode(aid) == nil {
+			continue
+		}
+		scores[aid] = c.scoreAtDepth(aid, graph, nodeDepth(depths, aid))
+		updated = append(updated, aid)
+	}
+	return updated
+}
+
 // GetTopScores returns the top N nodes by trust score
 func (c *Calculator) GetTopScores(graph *Graph, limit int) []*Score {
-	allScores := c.CalculateAllScores(graph)
+	return TopScores(c.CalculateAllScores(graph), limit)
+}
 
+// TopScores returns the top N of already computed scores
+func TopScores(allScores map[string]*Score, limit int) []*Score {
 	// Convert to slice
 	scores := make([]*Score, 0, len(allScores))
 	for _, s := range allScores {
@@ -190,28 +409,37 @@ func (c *Calculator) GetTopScores(graph *Graph, limit int) []*Score {
 
 // ScoreSummary provides a summary of trust scores in the graph
 type ScoreSummary struct {
-	TotalNodes     int     `json:"totalNodes"`
-	TotalEdges     int     `json:"totalEdges"`
-	AverageScore   float64 `json:"averageScore"`
-	MaxScore       float64 `json:"maxScore"`
-	MinScore       float64 `json:"minScore"`
-	MedianDepth    int     `json:"medianDepth"`
-	BidirectionalCount int `json:"bidirectionalCount"`
+	TotalNodes         int     `json:"totalNodes"`
+	TotalEdges         int     `json:"totalEdges"`
+	AverageScore       float64 `json:"averageScore"`
+	MaxScore           float64 `json:"maxScore"`
+	MinScore           float64 `json:"minScore"`
+	MedianDepth        int     `json:"medianDepth"`
+	BidirectionalCount int     `json:"bidirectionalCount"`
+	DecayEnabled       bool    `json:"decayEnabled"`
+	DecayHalfLifeDays  float64 `json:"decayHalfLifeDays,omitempty"`
 }
 
 // CalculateSummary calculates a summary of trust scores
 func (c *Calculator) CalculateSummary(graph *Graph) *ScoreSummary {
+	return c.Summarize(graph, c.CalculateAllScores(graph))
+}
+
+// Summarize builds the summary from already computed scores for graph
+func (c *Calculator) Summarize(graph *Graph, allScores map[string]*Score) *ScoreSummary {
 	summary := &ScoreSummary{
 		TotalNodes: graph.NodeCount(),
 		TotalEdges: graph.EdgeCount(),
 	}
+	if c.weights.DecayHalfLife > 0 {
+		summary.DecayEnabled = true
+		summary.DecayHalfLifeDays = c.weights.DecayHalfLife.Hours() / 24
+	}
 
 	if summary.TotalNodes == 0 {
 		return summary
 	}
 
-	allScores := c.CalculateAllScores(graph)
-
 	var totalScore float64
 	summary.MinScore = -1
 	depths := make([]int, 0)
@@ -246,14 +474,7 @@ func (c *Calculator) CalculateSummary(graph *Graph) *ScoreSummary {
 		summary.MedianDepth = depths[len(depths)/2]
 	}
 
-	// Count bidirectional edges
-	for _, edge := range graph.Edges {
-		if edge.Bidirectional {
-			summary.BidirectionalCount++
-		}
-	}
-	// Each bidirectional relationship is counted twice, so divide by 2
-	summary.BidirectionalCount /= 2
+	summary.BidirectionalCount = graph.BidirectionalCount()
 
 	return summary
 }
@@ -1,7 +1,10 @@
 package trust
 
 import (
+	"math"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestNewDefaultCalculator(t *testing.T) {
@@ -322,3 +325,184 @@ func TestCalculator_CustomWeights(t *testing.T) {
 		t.Errorf("expected score %f, got %f", expectedScore, score.Score)
 	}
 }
+
+// agedGraph builds two members with identical org-issued credentials, one
+// issued yesterday and one two years ago.
+func agedGraph(now time.Time) *Graph {
+	graph := NewGraph("EORG123")
+	graph.AddNode(&Node{AID: "EORG123", Role: "Organization"})
+	graph.AddNode(&Node{AID: "ERECENT", Role: "Member"})
+	graph.AddNode(&Node{AID: "EOLD", Role: "Member"})
+	graph.AddEdge(&Edge{From: "EORG123", To: "ERECENT", CredentialID: "E1", CreatedAt: now.AddDate(0, 0, -1)})
+	graph.AddEdge(&Edge{From: "EORG123", To: "EOLD", CredentialID: "E2", CreatedAt: now.AddDate(-2, 0, 0)})
+	return graph
+}
+
+func TestCalculator_Decay(t *testing.T) {
+	now := time.Date(2026, 1, 19, 12, 0, 0, 0, time.UTC)
+	graph := agedGraph(now)
+
+	// Decay disabled: age makes no difference
+	calc := NewDefaultCalculator()
+	recent := calc.CalculateScore("ERECENT", graph)
+	old := calc.CalculateScore("EOLD", graph)
+	if recent.Score != old.Score {
+		t.Errorf("expected equal scores without decay, got recent %f, old %f", recent.Score, old.Score)
+	}
+
+	// Decay enabled: older credential contributes less
+	weights := DefaultWeights()
+	weights.DecayHalfLife = 365 * 24 * time.Hour
+	calc = NewCalculator(weights)
+	calc.now = func() time.Time { return now }
+	recent = calc.CalculateScore("ERECENT", graph)
+	old = calc.CalculateScore("EOLD", graph)
+	if old.Score >= recent.Score {
+		t.Errorf("expected older member to score lower with decay, got recent %f, old %f", recent.Score, old.Score)
+	}
+
+	// Two half-lives: edge contributions (1 + 2 + 2) scaled by ~0.25, minus depth penalty
+	want := 5.0*math.Pow(0.5, float64(now.Sub(now.AddDate(-2, 0, 0)))/float64(weights.DecayHalfLife)) - 0.1
+	if math.Abs(old.Score-want) > 1e-9 {
+		t.Errorf("expected old score %f, got %f", want, old.Score)
+	}
+}
+
+func TestCalculator_Decay_UnknownIssuanceTime(t *testing.T) {
+	weights := DefaultWeights()
+	weights.DecayHalfLife = 24 * time.Hour
+	calc := NewCalculator(weights)
+
+	graph := NewGraph("EORG123")
+	graph.AddNode(&Node{AID: "EORG123", Role: "Organization"})
+	graph.AddNode(&Node{AID: "EUSER1", Role: "Member"})
+	graph.AddEdge(&Edge{From: "EORG123", To: "EUSER1", CredentialID: "E1"})
+
+	if got := calc.CalculateScore("EUSER1", graph).Score; got != 4.9 {
+		t.Errorf("expected undated credential to count in full (4.9), got %f", got)
+	}
+}
+
+func TestCalculator_CalculateSummary_Decay(t *testing.T) {
+	graph := agedGraph(time.Now())
+
+	if summary := NewDefaultCalculator().CalculateSummary(graph); summary.DecayEnabled {
+		t.Error("expected decay disabled by default")
+	}
+
+	weights := DefaultWeights()
+	weights.DecayHalfLife = 180 * 24 * time.Hour
+	summary := NewCalculator(weights).CalculateSummary(graph)
+	if !summary.DecayEnabled {
+		t.Error("expected decay enabled")
+	}
+	if summary.DecayHalfLifeDays != 180 {
+		t.Errorf("expected half-life 180 days, got %f", summary.DecayHalfLifeDays)
+	}
+}
+
+// breakdownSum adds up the components of a score breakdown
+func breakdownSum(b *ScoreBreakdown) float64 {
+	return b.IncomingCredentials + b.OrgIssuedBonus + b.UniqueIssuers + b.BidirectionalBonus + b.DepthPenalty + b.Floor
+}
+
+// explainGraph has EUSER1 credentialed by the org, by EUSER2 (who it
+// credentialed back) and by EUSER3, with EUSER3's credential two years old.
+func explainGraph(now time.Time) *Graph {
+	graph := NewGraph("EORG123")
+	for _, aid := range []string{"EORG123", "EUSER1", "EUSER2", "EUSER3"} {
+		graph.AddNode(&Node{AID: aid, Role: "Member"})
+	}
+	graph.AddEdge(&Edge{From: "EORG123", To: "EUSER1", CredentialID: "E1", Type: EdgeTypeMembership})
+	graph.AddEdge(&Edge{From: "EORG123", To: "EUSER2", CredentialID: "E2", Type: EdgeTypeMembership})
+	graph.AddEdge(&Edge{From: "EUSER2", To: "EUSER1", CredentialID: "E3", Type: EdgeTypeInvitation, CreatedAt: now})
+	graph.AddEdge(&Edge{From: "EUSER1", To: "EUSER2", CredentialID: "E4", Type: EdgeTypeInvitation, CreatedAt: now})
+	graph.AddEdge(&Edge{From: "EUSER3", To: "EUSER1", CredentialID: "E5", Type: EdgeTypeInvitation, CreatedAt: now.AddDate(-2, 0, 0)})
+	graph.MarkBidirectionalEdges()
+	return graph
+}
+
+func TestCalculator_ExplainScore(t *testing.T) {
+	now := time.Date(2026, 1, 19, 12, 0, 0, 0, time.UTC)
+	graph := explainGraph(now)
+
+	calc := NewDefaultCalculator()
+	b := calc.ExplainScore("EUSER1", graph)
+	want := ScoreBreakdown{
+		IncomingCredentials: 3,   // three credentials at 1.0
+		OrgIssuedBonus:      2,   // one from the org
+		UniqueIssuers:       6,   // three issuers at 2.0
+		BidirectionalBonus:  3,   // EUSER2 <-> EUSER1
+		DepthPenalty:        -.1, // one level below the org
+		DecayFactor:         1,
+		GraphDepth:          1,
+	}
+	got := *b
+	got.Total, got.Credentials, got.Weights = 0, nil, ExplainedWeights{}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("breakdown = %+v, want %+v", got, want)
+	}
+	if math.Abs(b.Total-13.9) > 1e-9 {
+		t.Errorf("expected total 13.9, got %f", b.Total)
+	}
+	if score := calc.CalculateScore("EUSER1", graph).Score; b.Total != score {
+		t.Errorf("breakdown total %f, score %f", b.Total, score)
+	}
+	if math.Abs(breakdownSum(b)-b.Total) > 1e-9 {
+		t.Errorf("components sum to %f, total is %f", breakdownSum(b), b.Total)
+	}
+	if b.Weights.UniqueIssuer != 2 || b.Weights.DecayHalfLifeDays != 0 {
+		t.Errorf("unexpected weights %+v", b.Weights)
+	}
+
+	credentialSum := 0.0
+	for _, c := range b.Credentials {
+		credentialSum += c.Score
+		if !c.UniqueIssuer {
+			t.Errorf("%s: expected the unique-issuer bonus on the only credential from %s", c.CredentialID, c.Issuer)
+		}
+	}
+	if len(b.Credentials) != 3 || math.Abs(credentialSum+b.DepthPenalty-b.Total) > 1e-9 {
+		t.Errorf("credential contributions %+v don't add up to %f", b.Credentials, b.Total)
+	}
+
+	// With decay, EUSER3's two-year-old credential counts a quarter
+	weights := DefaultWeights()
+	weights.DecayHalfLife = 365 * 24 * time.Hour
+	calc = NewCalculator(weights)
+	calc.now = func() time.Time { return now }
+	b = calc.ExplainScore("EUSER1", graph)
+	factor := math.Pow(0.5, float64(now.Sub(now.AddDate(-2, 0, 0)))/float64(weights.DecayHalfLife))
+	if math.Abs(b.IncomingCredentials-(2+factor)) > 1e-9 || math.Abs(b.UniqueIssuers-2*(2+factor)) > 1e-9 {
+		t.Errorf("expected EUSER3's credential decayed by %f, got %+v", factor, b)
+	}
+	if wantDecay := (11 + 3*factor) / 14; math.Abs(b.DecayFactor-wantDecay) > 1e-9 {
+		t.Errorf("decay factor = %f, want %f", b.DecayFactor, wantDecay)
+	}
+	if score := calc.CalculateScore("EUSER1", graph).Score; math.Abs(breakdownSum(b)-score) > 1e-9 || b.Total != score {
+		t.Errorf("components sum to %f, total %f, score %f", breakdownSum(b), b.Total, score)
+	}
+	if b.Weights.DecayHalfLifeDays != 365 {
+		t.Errorf("expected half-life 365 days, got %f", b.Weights.DecayHalfLifeDays)
+	}
+}
+
+func TestCalculator_ExplainScore_Floor(t *testing.T) {
+	weights := DefaultWeights()
+	weights.DepthPenalty = 20
+	calc := NewCalculator(weights)
+	b := calc.ExplainScore("EUSER1", explainGraph(time.Now()))
+
+	if b.Total != 0 || b.Floor <= 0 {
+		t.Errorf("expected a floored total of 0, got %+v", b)
+	}
+	if breakdownSum(b) != b.Total {
+		t.Errorf("components sum to %f, total is %f", breakdownSum(b), b.Total)
+	}
+
+	// Unknown and credential-less AIDs explain to an empty breakdown
+	b = calc.ExplainScore("EUNKNOWN", explainGraph(time.Now()))
+	if b.Total != 0 || b.GraphDepth != -1 || len(b.Credentials) != 0 || b.DecayFactor != 1 {
+		t.Errorf("unexpected breakdown for an unknown AID: %+v", b)
+	}
+}
@@ -1,6 +1,7 @@
 package trust
 
 import (
+	"slices"
 	"time"
 )
 
@@ -11,6 +12,8 @@ type Node struct {
 	Role            string    `json:"role"`
 	JoinedAt        time.Time `json:"joinedAt"`
 	CredentialCount int       `json:"credentialCount"`
+	// AIDs this node holds credentials with in both directions, sorted
+	BidirectionalWith []string `json:"bidirectionalWith,omitempty"`
 }
 
 // Edge represents a credential relationship between two identities
@@ -29,6 +32,11 @@ type Graph struct {
 	Edges   []*Edge          `json:"edges"`
 	OrgAID  string           `json:"orgAid"`
 	Updated time.Time        `json:"updated"`
+
+	// bidirectional holds the mutual pairs, keyed by pairKey, once
+	// MarkBidirectionalEdges has run. AddEdge keeps it current; while it is
+	// nil, lookups scan the edges.
+	bidirectional map[[2]string]bool
 }
 
 // NewGraph creates a new empty trust graph
@@ -61,12 +69,19 @@ func (g *Graph) AddNode(node *Node) {
 // AddEdge adds an edge to the graph
 func (g *Graph) AddEdge(edge *Edge) {
 	// Check for duplicate edge
+	hasReverse := false
 	for _, e := range g.Edges {
 		if e.CredentialID == edge.CredentialID {
 			return // Edge already exists
 		}
+		if e.From == edge.To && e.To == edge.From {
+			hasReverse = true
+		}
 	}
 	g.Edges = append(g.Edges, edge)
+	if hasReverse && g.bidirectional != nil {
+		g.linkBidirectional(edge.From, edge.To)
+	}
 }
 
 // GetNode returns a node by AID
@@ -98,6 +113,10 @@ func (g *Graph) GetEdgesTo(aid string) []*Edge {
 
 // HasBidirectionalRelation checks if two AIDs have a bidirectional relationship
 func (g *Graph) HasBidirectionalRelation(aid1, aid2 string) bool {
+	if g.bidirectional != nil {
+		return g.bidirectional[pairKey(aid1, aid2)]
+	}
+
 	hasForward := false
 	hasReverse := false
 
@@ -113,23 +132,105 @@ func (g *Graph) HasBidirectionalRelation(aid1, aid2 string) bool {
 	return hasForward && hasReverse
 }
 
-// MarkBidirectionalEdges updates edges to mark bidirectional relationships
-func (g *Graph) MarkBidirectionalEdges() {
+// BidirectionalCount returns the number of AID pairs with a bidirectional
+// relationship
+func (g *Graph) BidirectionalCount() int {
+	if g.bidirectional != nil {
+		return len(g.bidirectional)
+	}
+	return len(g.bidirectionalPairs())
+}
+
+// bidirectionalPairs computes the mutual pairs from the edges
+func (g *Graph) bidirectionalPairs() map[[2]string]bool {
 	// Build a map of edge pairs
-	edgeMap := make(map[string]bool) // "from:to" -> exists
+	edgeMap := make(map[[2]string]bool) // {from, to} -> exists
+	for _, e := range g.Edges {
+		edgeMap[[2]string{e.From, e.To}] = true
+	}
 
+	pairs := make(map[[2]string]bool)
 	for _, e := range g.Edges {
-		key := e.From + ":" + e.To
-		edgeMap[key] = true
+		if edgeMap[[2]string{e.To, e.From}] {
+			pairs[pairKey(e.From, e.To)] = true
+		}
 	}
+	return pairs
+}
+
+// MarkBidirectionalEdges updates edges to mark bidirectional relationships,
+// and caches the mutual pairs and each node's BidirectionalWith list
+func (g *Graph) MarkBidirectionalEdges() {
+	g.bidirectional = g.bidirectionalPairs()
 
 	// Mark bidirectional edges
 	for _, e := range g.Edges {
-		reverseKey := e.To + ":" + e.From
-		if edgeMap[reverseKey] {
+		if g.bidirectional[pairKey(e.From, e.To)] {
 			e.Bidirectional = true
 		}
 	}
+
+	for _, n := range g.Nodes {
+		n.BidirectionalWith = nil
+	}
+	for pair := range g.bidirectional {
+		if n := g.Nodes[pair[0]]; n != nil {
+			n.BidirectionalWith = append(n.BidirectionalWith, pair[1])
+		}
+		if n := g.Nodes[pair[1]]; n != nil && pair[0] != pair[1] {
+			n.BidirectionalWith = append(n.BidirectionalWith, pair[0])
+		}
+	}
+	for _, n := range g.Nodes {
+		slices.Sort(n.BidirectionalWith)
+	}
+}
+
+// linkBidirectional records a new mutual pair in the cache and on both
+// nodes. Lists are replaced rather than appended to in place, since clones
+// share them.
+func (g *Graph) linkBidirectional(aid1, aid2 string) {
+	key := pairKey(aid1, aid2)
+	if g.bidirectional[key] {
+		return
+	}
+	g.bidirectional[key] = true
+	link := func(aid, other string) {
+		if n := g.Nodes[aid]; n != nil {
+			i, _ := slices.BinarySearch(n.BidirectionalWith, other)
+			n.BidirectionalWith = slices.Insert(slices.Clip(n.BidirectionalWith), i, other)
+		}
+	}
+	link(aid1, aid2)
+	if aid1 != aid2 {
+		link(aid2, aid1)
+	}
+}
+
+// Clone returns a deep copy of the graph, so it can be updated while the
+// original is still being read.
+func (g *Graph) Clone() *Graph {
+	clone := &Graph{
+		Nodes:   make(map[string]*Node, len(g.Nodes)),
+		Edges:   make([]*Edge, len(g.Edges)),
+		OrgAID:  g.OrgAID,
+		Updated: g.Updated,
+	}
+	for aid, n := range g.Nodes {
+		node := *n
+		clone.Nodes[aid] = &node
+	}
+	for i, e := range g.Edges {
+		edge := *e
+		clone.Edges[i] = &edge
+	}
+	if g.bidirectional != nil {
+		clone.bidirectional = make(map[[2]string]bool, len(g.bidirectional))
+		for pair := range g.bidirectional {
+			clone.bidirectional[pair] = true
+		}
+	}
+	return clone
 }
 
 // NodeCount returns the number of nodes in the graph
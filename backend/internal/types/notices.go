@@ -82,6 +82,8 @@ func NoticeType() *TypeDefinition {
 				UIHints: &UIHints{Label: "Event End", Section: "time"}},
 			{Name: "timezone", Type: "string",
 				UIHints: &UIHints{Label: "Timezone", Section: "time"}},
+			{Name: "recurrence", Type: "object",
+				UIHints: &UIHints{Label: "Recurrence", Section: "time"}},
 
 			// Location (for events)
 			{Name: "locationMode", Type: "string",
@@ -111,6 +113,8 @@ func NoticeType() *TypeDefinition {
 			// Lifecycle
 			{Name: "pinned", Type: "boolean",
 				UIHints: &UIHints{Label: "Pinned", Section: "lifecycle"}},
+			{Name: "pinOrder", Type: "number", ReadOnly: true,
+				UIHints: &UIHints{Label: "Pin Order", Section: "lifecycle"}},
 			{Name: "state", Type: "string", Required: true,
 				Validation: &Validation{Enum: []string{"draft", "published", "archived"}},
 				UIHints:    &UIHints{DisplayFormat: "badge", Label: "State", Section: "lifecycle"}},
@@ -122,6 +126,8 @@ func NoticeType() *TypeDefinition {
 				UIHints: &UIHints{DisplayFormat: "relative-date", Label: "Published", Section: "lifecycle"}},
 			{Name: "archivedAt", Type: "datetime", ReadOnly: true,
 				UIHints: &UIHints{DisplayFormat: "relative-date", Label: "Archived", Section: "lifecycle"}},
+			{Name: "editedAt", Type: "datetime", ReadOnly: true,
+				UIHints: &UIHints{DisplayFormat: "relative-date", Label: "Edited", Section: "lifecycle"}},
 
 			// Amendment
 			{Name: "amendsNoticeId", Type: "string",
@@ -150,6 +156,8 @@ func NoticeAckType() *TypeDefinition {
 		Fields: []FieldDef{
 			{Name: "noticeId", Type: "string", Required: true,
 				UIHints: &UIHints{Label: "Notice ID", Section: "ack"}},
+			{Name: "occurrenceDate", Type: "datetime",
+				UIHints: &UIHints{Label: "Occurrence", Section: "ack"}},
 			{Name: "userId", Type: "string", Required: true,
 				UIHints: &UIHints{Label: "User ID", Section: "ack"}},
 			{Name: "ackAt", Type: "datetime", Required: true, ReadOnly: true,
@@ -166,7 +174,7 @@ func NoticeAckType() *TypeDefinition {
 }
 
 // NoticeRSVPType returns the NoticeRSVP type definition.
-// Stored in the community space. One RSVP per (noticeId, userId) — last-write-wins.
+// Stored in the community space. One RSVP per (noticeId, occurrenceDate, userId) — last-write-wins.
 func NoticeRSVPType() *TypeDefinition {
 	return &TypeDefinition{
 		Name:        "NoticeRSVP",
@@ -176,10 +184,12 @@ func NoticeRSVPType() *TypeDefinition {
 		Fields: []FieldDef{
 			{Name: "noticeId", Type: "string", Required: true,
 				UIHints: &UIHints{Label: "Notice ID", Section: "rsvp"}},
+			{Name: "occurrenceDate", Type: "datetime",
+				UIHints: &UIHints{Label: "Occurrence", Section: "rsvp"}},
 			{Name: "userId", Type: "string", Required: true,
 				UIHints: &UIHints{Label: "User ID", Section: "rsvp"}},
 			{Name: "status", Type: "string", Required: true,
-				Validation: &Validation{Enum: []string{"going", "maybe", "not_going"}},
+				Validation: &Validation{Enum: []string{"going", "maybe", "not_going", "waitlisted"}},
 				UIHints:    &UIHints{DisplayFormat: "badge", Label: "Status", Section: "rsvp"}},
 			{Name: "updatedAt", Type: "datetime", Required: true, ReadOnly: true,
 				UIHints: &UIHints{DisplayFormat: "relative-date", Label: "Updated At", Section: "rsvp"}},
@@ -239,6 +249,10 @@ func NoticeCommentType() *TypeDefinition {
 				UIHints:    &UIHints{InputType: "textarea", Label: "Text", Section: "comment"}},
 			{Name: "createdAt", Type: "datetime", Required: true, ReadOnly: true,
 				UIHints: &UIHints{DisplayFormat: "relative-date", Label: "Created At", Section: "comment"}},
+			{Name: "deletedAt", Type: "datetime", ReadOnly: true,
+				UIHints: &UIHints{DisplayFormat: "relative-date", Label: "Deleted At", Section: "comment"}},
+			{Name: "deletedBy", Type: "string", ReadOnly: true,
+				UIHints: &UIHints{Label: "Deleted By", Section: "comment"}},
 		},
 		Permissions: TypePermissions{
 			Read:  "community",
@@ -292,10 +306,18 @@ func IsValidEmoji(emoji string) bool {
 var ValidNoticeStates = []string{"draft", "published", "archived"}
 
 // ValidNoticeTransitions maps current state to allowed next states.
+// A published notice can be unpublished back to draft for rework, and an
+// archived one republished. Drafts can't be archived: they were never on the
+// board, so there is nothing to retire.
 var ValidNoticeTransitions = map[string][]string{
 	"draft":     {"published"},
-	"published": {"archived"},
-	"archived":  {}, // terminal state
+	"published": {"archived", "draft"},
+	"archived":  {"published"},
+}
+
+// NoticeTransitionsFrom returns the states a notice in state can move to.
+func NoticeTransitionsFrom(state string) []string {
+	return append([]string{}, ValidNoticeTransitions[state]...)
 }
 
 // IsValidNoticeTransition checks if a state transition is allowed.
@@ -111,8 +111,8 @@ func TestNoticeRSVPType(t *testing.T) {
 	// status field should have enum
 	for _, f := range def.Fields {
 		if f.Name == "status" {
-			if f.Validation == nil || len(f.Validation.Enum) != 3 {
-				t.Errorf("status field should have enum validation with 3 values (going, maybe, not_going)")
+			if f.Validation == nil || len(f.Validation.Enum) != 4 {
+				t.Errorf("status field should have enum validation with 4 values (going, maybe, not_going, waitlisted)")
 			}
 		}
 	}
@@ -139,10 +139,12 @@ func TestIsValidNoticeTransition(t *testing.T) {
 	}{
 		{"draft", "published", true},
 		{"published", "archived", true},
-		{"draft", "archived", false},   // skip state not allowed
-		{"published", "draft", false},  // no backward transitions
-		{"archived", "published", false}, // terminal state
+		{"published", "draft", true},   // unpublish
+		{"archived", "published", true}, // republish
+		{"draft", "archived", false},   // drafts were never on the board
 		{"archived", "draft", false},
+		{"draft", "draft", false},
+		{"published", "published", false},
 		{"invalid", "published", false},
 	}
 
@@ -257,3 +259,17 @@ func TestRegistryIncludesNoticeTypes(t *testing.T) {
 		}
 	}
 }
+
+func TestNoticeTransitionsFrom(t *testing.T) {
+	got := NoticeTransitionsFrom("published")
+	if len(got) != 2 || got[0] != "archived" || got[1] != "draft" {
+		t.Errorf("NoticeTransitionsFrom(published) = %v", got)
+	}
+	got[0] = "mutated"
+	if ValidNoticeTransitions["published"][0] != "archived" {
+		t.Error("expected a copy of the transition list")
+	}
+	if got := NoticeTransitionsFrom("unknown"); got == nil || len(got) != 0 {
+		t.Errorf("expected an empty, non-nil list for an unknown state, got %#v", got)
+	}
+}
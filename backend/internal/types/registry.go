@@ -3,10 +3,16 @@ package types
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
 )
 
+// ErrUnknownType is returned by CheckObject for a type that is neither
+// registered nor a system type.
+var ErrUnknownType = errors.New("unknown object type")
+
 // ObjectReader is the interface needed by the registry to load type definitions
 // from an any-sync space. This matches ObjectTreeManager.ReadObjectsByType.
 type ObjectReader interface {
@@ -25,14 +31,16 @@ type ObjectEntry struct {
 // It is populated at startup from the community space tree and can be
 // queried by profile handlers and frontend clients.
 type Registry struct {
-	mu    sync.RWMutex
-	types map[string]*TypeDefinition
+	mu     sync.RWMutex
+	types  map[string]*TypeDefinition
+	system map[string]bool // known types without a definition (see SystemTypeNames)
 }
 
 // NewRegistry creates a new empty type registry.
 func NewRegistry() *Registry {
 	return &Registry{
-		types: make(map[string]*TypeDefinition),
+		types:  make(map[string]*TypeDefinition),
+		system: make(map[string]bool),
 	}
 }
 
@@ -49,6 +57,17 @@ func (r *Registry) Bootstrap() {
 	for _, def := range ChatTypeDefinitions() {
 		r.Register(def)
 	}
+	r.RegisterSystemTypes(SystemTypeNames()...)
+}
+
+// RegisterSystemTypes marks object types as known without a definition.
+// They pass CheckObject but are not listed by All or seeded into spaces.
+func (r *Registry) RegisterSystemTypes(names ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, name := range names {
+		r.system[name] = true
+	}
 }
 
 // Register adds or replaces a type definition in the registry.
@@ -86,6 +105,30 @@ func (r *Registry) Validate(typeName string, data json.RawMessage) ([]string, er
 	return ValidateData(def, data), nil
 }
 
+// CheckObject reports whether an object of typeName may be written. Unknown
+// types fail with ErrUnknownType; with validateData, data must also satisfy
+// the type's field definitions. System types are never shape-checked.
+func (r *Registry) CheckObject(typeName string, data json.RawMessage, validateData bool) error {
+	r.mu.RLock()
+	def, ok := r.types[typeName]
+	system := r.system[typeName]
+	r.mu.RUnlock()
+
+	if !ok {
+		if system {
+			return nil
+		}
+		return fmt.Errorf("%w %q", ErrUnknownType, typeName)
+	}
+	if !validateData {
+		return nil
+	}
+	if problems := ValidateData(def, data); len(problems) > 0 {
+		return fmt.Errorf("invalid %s data: %s", typeName, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
 // LoadFromSpace reads type_definition objects from a space and registers them.
 // This is called on backend startup to hydrate the registry from persisted data.
 func (r *Registry) LoadFromSpace(ctx context.Context, reader ObjectReader, spaceID string) error {
@@ -0,0 +1,42 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestRegistry_CheckObject(t *testing.T) {
+	r := NewRegistry()
+	r.Bootstrap()
+	message := json.RawMessage(`{"channelId":"ch-1","senderAid":"EALICE","senderName":"Alice","content":"kia ora"}`)
+
+	if err := r.CheckObject("ChatMessage", message, true); err != nil {
+		t.Errorf("registered type: unexpected error %v", err)
+	}
+	if err := r.CheckObject("ChatMesage", message, false); !errors.Is(err, ErrUnknownType) {
+		t.Errorf("unregistered type: expected ErrUnknownType, got %v", err)
+	}
+	if err := r.CheckObject("ChatDraft", json.RawMessage(`{}`), true); err != nil {
+		t.Errorf("system type: unexpected error %v", err)
+	}
+
+	// Data shape is only checked when asked for
+	missingChannel := json.RawMessage(`{"senderAid":"EALICE","senderName":"Alice","content":"kia ora"}`)
+	if err := r.CheckObject("ChatMessage", missingChannel, false); err != nil {
+		t.Errorf("shape not validated: unexpected error %v", err)
+	}
+	if err := r.CheckObject("ChatMessage", missingChannel, true); err == nil {
+		t.Error("expected an error for a message without channelId")
+	}
+}
+
+func TestRegistry_SystemTypesNotListed(t *testing.T) {
+	r := NewRegistry()
+	r.Bootstrap()
+	for _, def := range r.All() {
+		if def.Name == "ChatDraft" {
+			t.Fatal("system types must not be listed as type definitions")
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package types
+
+// SystemTypeNames lists the object types the backend writes for its own
+// bookkeeping. They have no TypeDefinition (nothing renders or edits them),
+// but the registry knows them so that writes of any other unregistered type
+// can be rejected as typos.
+func SystemTypeNames() []string {
+	return []string{
+		// Chat
+		"ChannelMembership", "ChatChannelMerge", "ChatDraft", "ReadCursors",
+		// Notices
+		"CommentCursors",
+		// Admin space
+		"AdminSettings", "InviteAudit", "ModerationEvent",
+		// Files
+		"file_meta",
+		// Contributions (see the anysync Type* constants)
+		"proposal", "proposal_history", "proposal_comment", "endorsement",
+		"project", "project_comment", "decision_plan", "governance_action",
+		"implementation_plan", "milestone", "contribution", "contribution_comment",
+		"contribution_registration", "notification", "tombstone",
+	}
+}
@@ -0,0 +1,252 @@
+// Package client is a Go client for the MATOU backend HTTP API. The request
+// and response types are the ones the server's handlers use, so integrators
+// don't have to keep their own copies in step with the API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// maxErrorBody caps how much of a non-JSON error response is kept.
+const maxErrorBody = 4 << 10
+
+// Client calls the backend HTTP API at a base URL such as
+// "http://localhost:8080". It is safe for concurrent use once configured.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	token      string
+	userAID    string
+	origin     string
+}
+
+// New creates a client for the backend at baseURL.
+func New(baseURL string) *Client {
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+	}
+}
+
+// SetHTTPClient replaces the HTTP client requests are sent with.
+func (c *Client) SetHTTPClient(httpClient *http.Client) {
+	c.httpClient = httpClient
+}
+
+// SetToken sends token as "Authorization: Bearer", for backends running
+// with session token auth.
+func (c *Client) SetToken(token string) {
+	c.token = token
+}
+
+// SetUserAID sends aid as X-User-AID, the caller identity used for
+// role checks when token auth is off.
+func (c *Client) SetUserAID(aid string) {
+	c.userAID = aid
+}
+
+// SetOrigin sends origin as the Origin header, for backends that only
+// answer allowlisted origins.
+func (c *Client) SetOrigin(origin string) {
+	c.origin = origin
+}
+
+// Error is a non-2xx response from the API.
+type Error struct {
+	StatusCode int
+	Message    string // the response's "error" field, or its body
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("api error %d: %s", e.StatusCode, e.Message)
+}
+
+// CreateChannel creates a chat channel.
+func (c *Client) CreateChannel(ctx context.Context, req CreateChannelRequest) (*CreateChannelResponse, error) {
+	var resp CreateChannelResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/chat/channels", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListChannelsOptions filters and pages ListChannels. Zero values use the
+// server defaults.
+type ListChannelsOptions struct {
+	Membership      string // "joined" or "available"
+	IncludeArchived bool
+	Limit           int
+	Offset          int
+}
+
+// ListChannels lists the chat channels visible to the caller, oldest first.
+func (c *Client) ListChannels(ctx context.Context, opts ListChannelsOptions) (*ChannelsPage, error) {
+	query := url.Values{}
+	setQuery(query, "membership", opts.Membership)
+	if opts.IncludeArchived {
+		query.Set("includeArchived", "true")
+	}
+	setQueryInt(query, "limit", opts.Limit)
+	setQueryInt(query, "offset", opts.Offset)
+
+	var page ChannelsPage
+	if err := c.do(ctx, http.MethodGet, "/api/v1/chat/channels", query, nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// SendMessage posts a message to a channel.
+func (c *Client) SendMessage(ctx context.Context, channelID string, req SendMessageRequest) (*SendMessageResponse, error) {
+	var resp SendMessageResponse
+	path := "/api/v1/chat/channels/" + url.PathEscape(channelID) + "/messages"
+	if err := c.do(ctx, http.MethodPost, path, nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListMessagesOptions pages ListMessages. Cursor is a previous page's
+// NextCursor.
+type ListMessagesOptions struct {
+	Limit  int
+	Cursor string
+}
+
+// ListMessages lists a channel's messages, newest first.
+func (c *Client) ListMessages(ctx context.Context, channelID string, opts ListMessagesOptions) (*MessagesPage, error) {
+	query := url.Values{}
+	setQueryInt(query, "limit", opts.Limit)
+	setQuery(query, "cursor", opts.Cursor)
+
+	var page MessagesPage
+	path := "/api/v1/chat/channels/" + url.PathEscape(channelID) + "/messages"
+	if err := c.do(ctx, http.MethodGet, path, query, nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// GetMessage fetches one message by ID from any channel the caller can read.
+// A deleted message comes back as its tombstone, with DeletedAt set.
+func (c *Client) GetMessage(ctx context.Context, messageID string) (*MessageResponse, error) {
+	var msg MessageResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/chat/messages/"+url.PathEscape(messageID), nil, nil, &msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// ListNoticesOptions filters and pages ListNotices. Cursor is a previous
+// page's NextCursor.
+type ListNoticesOptions struct {
+	View   string // "upcoming", "current", "past" or "mine"
+	Type   string // "event", "update" or "announcement"
+	Limit  int
+	Cursor string
+}
+
+// ListNotices lists notice-board entries.
+func (c *Client) ListNotices(ctx context.Context, opts ListNoticesOptions) (*NoticesPage, error) {
+	query := url.Values{}
+	setQuery(query, "view", opts.View)
+	setQuery(query, "type", opts.Type)
+	setQueryInt(query, "limit", opts.Limit)
+	setQuery(query, "cursor", opts.Cursor)
+
+	var page NoticesPage
+	if err := c.do(ctx, http.MethodGet, "/api/v1/notices", query, nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// do sends a request with body encoded as JSON, when not nil, and decodes a
+// successful response into out. Other responses are returned as *Error.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encoding request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.userAID != "" {
+		req.Header.Set("X-User-AID", c.userAID)
+	}
+	if c.origin != "" {
+		req.Header.Set("Origin", c.origin)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return decodeError(resp)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding %s %s response: %w", method, path, err)
+	}
+	return nil
+}
+
+// decodeError turns a failed response into an *Error, using its JSON
+// "error" field when there is one.
+func decodeError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	apiErr := &Error{StatusCode: resp.StatusCode}
+	var body struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(data, &body) == nil && body.Error != "" {
+		apiErr.Message = body.Error
+	} else if msg := strings.TrimSpace(string(data)); msg != "" {
+		apiErr.Message = msg
+	} else {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+	return apiErr
+}
+
+func setQuery(query url.Values, key, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
+
+func setQueryInt(query url.Values, key string, value int) {
+	if value > 0 {
+		query.Set(key, strconv.Itoa(value))
+	}
+}
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_RequestHeadersAndQuery(t *testing.T) {
+	var got *http.Request
+	var body SendMessageRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"success":true,"messageId":"msg-1","headId":"h","sentAt":"2026-01-19T00:00:00Z"}`))
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL)
+	c.SetToken("tok")
+	c.SetUserAID("EUSER1")
+	c.SetOrigin("http://localhost:9000")
+	resp, err := c.SendMessage(context.Background(), "chan/1", SendMessageRequest{Content: "hi"})
+	if err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	if resp.MessageID != "msg-1" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if got.Method != http.MethodPost || got.URL.EscapedPath() != "/api/v1/chat/channels/chan%2F1/messages" {
+		t.Errorf("unexpected request %s %s", got.Method, got.URL.EscapedPath())
+	}
+	for header, want := range map[string]string{
+		"Authorization": "Bearer tok",
+		"X-User-AID":    "EUSER1",
+		"Origin":        "http://localhost:9000",
+		"Content-Type":  "application/json",
+	} {
+		if v := got.Header.Get(header); v != want {
+			t.Errorf("%s = %q, want %q", header, v, want)
+		}
+	}
+	if body.Content != "hi" {
+		t.Errorf("unexpected request body %+v", body)
+	}
+
+	if _, err := c.ListNotices(context.Background(), ListNoticesOptions{View: "upcoming", Limit: 5}); err != nil {
+		t.Fatalf("ListNotices: %v", err)
+	}
+	if q := got.URL.Query(); q.Get("view") != "upcoming" || q.Get("limit") != "5" || q.Has("type") || q.Has("cursor") {
+		t.Errorf("unexpected query %q", got.URL.RawQuery)
+	}
+}
+
+func TestClient_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantMsg string
+	}{
+		{"json error", http.StatusForbidden, `{"error":"insufficient permissions"}`, "insufficient permissions"},
+		{"plain text", http.StatusBadGateway, "upstream down\n", "upstream down"},
+		{"empty body", http.StatusServiceUnavailable, "", "Service Unavailable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			_, err := New(srv.URL).ListChannels(context.Background(), ListChannelsOptions{})
+			var apiErr *Error
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected *Error, got %v", err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Message != tt.wantMsg {
+				t.Errorf("got %d %q, want %d %q", apiErr.StatusCode, apiErr.Message, tt.status, tt.wantMsg)
+			}
+		})
+	}
+}
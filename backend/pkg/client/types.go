@@ -0,0 +1,211 @@
+package client
+
+import "encoding/json"
+
+// --- Shared ---
+
+// ListPage is the pagination envelope list endpoints merge into their
+// responses. The total is also sent as X-Total-Count.
+type ListPage struct {
+	Total      int    `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset,omitempty"`     // offset-paginated endpoints
+	NextCursor string `json:"nextCursor,omitempty"` // cursor-paginated endpoints
+	HasMore    bool   `json:"hasMore"`
+}
+
+// --- Chat ---
+
+// AttachmentRef represents a file attachment reference.
+type AttachmentRef struct {
+	FileRef      string `json:"fileRef"`
+	FileName     string `json:"fileName"`
+	ContentType  string `json:"contentType"`
+	Size         int64  `json:"size"`
+	ThumbnailRef string `json:"thumbnailRef,omitempty"`
+}
+
+// CreateChannelRequest is the request body for creating a channel.
+type CreateChannelRequest struct {
+	Name          string   `json:"name"`
+	Description   string   `json:"description,omitempty"`
+	Icon          string   `json:"icon,omitempty"`
+	Photo         string   `json:"photo,omitempty"`
+	AllowedRoles  []string `json:"allowedRoles,omitempty"`
+	RetentionDays int      `json:"retentionDays,omitempty"`
+}
+
+// UpdateChannelRequest is the request body for updating a channel.
+type UpdateChannelRequest struct {
+	Name          *string   `json:"name,omitempty"`
+	Description   *string   `json:"description,omitempty"`
+	Icon          *string   `json:"icon,omitempty"`
+	Photo         *string   `json:"photo,omitempty"`
+	AllowedRoles  *[]string `json:"allowedRoles,omitempty"`
+	RetentionDays *int      `json:"retentionDays,omitempty"`
+	// Version is the channel version the edit was made against. When set,
+	// the update is rejected with 409 if the channel has changed since.
+	Version *int `json:"version,omitempty"`
+}
+
+// SendMessageRequest is the request body for sending a message.
+type SendMessageRequest struct {
+	Content     string          `json:"content"`
+	Attachments []AttachmentRef `json:"attachments,omitempty"`
+	ReplyTo     string          `json:"replyTo,omitempty"`
+}
+
+// EditMessageRequest is the request body for editing a message.
+type EditMessageRequest struct {
+	Content string `json:"content"`
+}
+
+// AddReactionRequest is the request body for adding a reaction.
+type AddReactionRequest struct {
+	Emoji string `json:"emoji"`
+}
+
+// UpdateReadCursorRequest is the request body for updating a read cursor.
+type UpdateReadCursorRequest struct {
+	ChannelID  string `json:"channelId"`
+	LastReadAt string `json:"lastReadAt"`
+}
+
+// ChannelResponse is the response for a single channel.
+type ChannelResponse struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	Description   string   `json:"description,omitempty"`
+	Icon          string   `json:"icon,omitempty"`
+	Photo         string   `json:"photo,omitempty"`
+	CreatedAt     string   `json:"createdAt"`
+	CreatedBy     string   `json:"createdBy"`
+	IsArchived    bool     `json:"isArchived,omitempty"`
+	AllowedRoles  []string `json:"allowedRoles,omitempty"`
+	RetentionDays int      `json:"retentionDays,omitempty"`
+	MergedInto    string   `json:"mergedInto,omitempty"`
+	Joined        bool     `json:"joined"`
+	Version       int      `json:"version,omitempty"`
+}
+
+// MessageResponse is the response for a single message.
+type MessageResponse struct {
+	ID          string              `json:"id"`
+	ChannelID   string              `json:"channelId"`
+	SenderAID   string              `json:"senderAid"`
+	SenderName  string              `json:"senderName"`
+	Content     string              `json:"content"`
+	Attachments []AttachmentRef     `json:"attachments,omitempty"`
+	ReplyTo     string              `json:"replyTo,omitempty"`
+	SentAt      string              `json:"sentAt"`
+	EditedAt    string              `json:"editedAt,omitempty"`
+	DeletedAt   string              `json:"deletedAt,omitempty"`
+	Kind        string              `json:"kind,omitempty"`
+	Payload     json.RawMessage     `json:"payload,omitempty"`
+	Reactions   []ReactionAggregate `json:"reactions,omitempty"`
+	Version     int                 `json:"version"`
+}
+
+// ReactionAggregate is an aggregated view of reactions for a message.
+type ReactionAggregate struct {
+	Emoji          string   `json:"emoji"`
+	Count          int      `json:"count"`
+	ReactorAIDs    []string `json:"reactorAids"`
+	HasReacted     bool     `json:"hasReacted"`
+	FirstReactedAt string   `json:"firstReactedAt,omitempty"`
+}
+
+// CreateChannelResponse is the response for creating a channel.
+type CreateChannelResponse struct {
+	Success   bool   `json:"success"`
+	ChannelID string `json:"channelId"`
+	HeadID    string `json:"headId"`
+}
+
+// SendMessageResponse is the response for sending a message.
+type SendMessageResponse struct {
+	Success   bool   `json:"success"`
+	MessageID string `json:"messageId"`
+	HeadID    string `json:"headId"`
+	SentAt    string `json:"sentAt"`
+}
+
+// ChannelsPage is a page of GET /api/v1/chat/channels.
+type ChannelsPage struct {
+	ListPage
+	Channels []ChannelResponse `json:"channels"`
+	Count    int               `json:"count"`
+}
+
+// MessagesPage is a page of GET /api/v1/chat/channels/{id}/messages, newest
+// first. NextCursor fetches the next older page.
+type MessagesPage struct {
+	Messages   []MessageResponse `json:"messages"`
+	Count      int               `json:"count"`
+	NextCursor string            `json:"nextCursor,omitempty"`
+	HasMore    bool              `json:"hasMore"`
+}
+
+// --- Notices ---
+
+// Notice is a notice-board entry as returned by the notices endpoints.
+type Notice struct {
+	ID              string            `json:"id"`
+	Type            string            `json:"type"` // "event", "update", or "announcement"
+	Subtype         string            `json:"subtype,omitempty"`
+	Title           string            `json:"title"`
+	Summary         string            `json:"summary"`
+	Body            string            `json:"body,omitempty"`
+	Links           json.RawMessage   `json:"links,omitempty"`
+	Images          json.RawMessage   `json:"images,omitempty"`
+	Attachments     json.RawMessage   `json:"attachments,omitempty"`
+	IssuerType      string            `json:"issuerType"`
+	IssuerID        string            `json:"issuerId"`
+	IssuerName      string            `json:"issuerDisplayName,omitempty"`
+	AudienceMode    string            `json:"audienceMode,omitempty"`
+	AudienceRoleIDs json.RawMessage   `json:"audienceRoleIds,omitempty"`
+	PublishAt       string            `json:"publishAt,omitempty"`
+	ActiveFrom      string            `json:"activeFrom,omitempty"`
+	ActiveUntil     string            `json:"activeUntil,omitempty"`
+	EventStart      string            `json:"eventStart,omitempty"`
+	EventEnd        string            `json:"eventEnd,omitempty"`
+	Timezone        string            `json:"timezone,omitempty"`
+	LocationMode    string            `json:"locationMode,omitempty"`
+	LocationText    string            `json:"locationText,omitempty"`
+	LocationURL     string            `json:"locationUrl,omitempty"`
+	RSVPEnabled     bool              `json:"rsvpEnabled,omitempty"`
+	RSVPRequired    bool              `json:"rsvpRequired,omitempty"`
+	RSVPCapacity    int               `json:"rsvpCapacity,omitempty"`
+	AckRequired     bool              `json:"ackRequired,omitempty"`
+	AckDueAt        string            `json:"ackDueAt,omitempty"`
+	Recurrence      *NoticeRecurrence `json:"recurrence,omitempty"`
+	OccurrenceDate  string            `json:"occurrenceDate,omitempty"` // set on expanded occurrences of a recurring event
+	Pinned          bool              `json:"pinned,omitempty"`
+	PinOrder        int               `json:"pinOrder,omitempty"`
+	State           string            `json:"state"` // "draft", "published", "archived"
+	CreatedAt       string            `json:"createdAt"`
+	CreatedBy       string            `json:"createdBy"`
+	PublishedAt     string            `json:"publishedAt,omitempty"`
+	ArchivedAt      string            `json:"archivedAt,omitempty"`
+	EditedAt        string            `json:"editedAt,omitempty"`
+	AmendsNoticeID  string            `json:"amendsNoticeId,omitempty"`
+	Version         int               `json:"version"`
+	TreeID          string            `json:"treeId,omitempty"`
+}
+
+// NoticeRecurrence describes how a recurring event repeats.
+// Either Count or Until bounds the series; neither means open-ended.
+type NoticeRecurrence struct {
+	Frequency string `json:"frequency"`          // "weekly" or "monthly"
+	Interval  int    `json:"interval,omitempty"` // repeat every N periods, defaults to 1
+	Count     int    `json:"count,omitempty"`    // total occurrences, including the first
+	Until     string `json:"until,omitempty"`    // RFC3339, last possible occurrence start
+}
+
+// NoticesPage is a page of GET /api/v1/notices.
+type NoticesPage struct {
+	ListPage
+	Notices []Notice `json:"notices"`
+	Count   int      `json:"count"`
+	View    string   `json:"view,omitempty"`
+}
@@ -140,7 +140,7 @@ function getBackendPath(): string {
 }
 
 /**
- * Spawn the Go backend and wait until /health returns 200.
+ * Spawn the Go backend and wait until /health/live returns 200.
  */
 async function startBackend(): Promise<void> {
   backendPort = await findFreePort();
@@ -184,11 +184,12 @@ async function startBackend(): Promise<void> {
     backendProcess = null;
   });
 
-  // Poll /health until ready (max 30 seconds)
+  // Poll the liveness probe until the server is up (max 30 seconds). /health
+  // is readiness and stays 503 while offline, which mustn't block startup.
   const maxAttempts = 60;
   for (let i = 0; i < maxAttempts; i++) {
     try {
-      const res = await fetch(`http://127.0.0.1:${backendPort}/health`);
+      const res = await fetch(`http://127.0.0.1:${backendPort}/health/live`);
       if (res.ok) {
         console.log(`[Electron] Backend healthy after ${i + 1} attempts`);
         return;
@@ -32,6 +32,7 @@ export interface AdminInfo {
   aid: string;
   name: string;
   oobi?: string;  // Optional OOBI for direct contact
+  signingKey?: string;  // Key the admin's invites are verified against, filled in by their backend
 }
 
 export interface OrgConfig {
@@ -190,6 +191,9 @@ export async function saveOrgConfig(config: OrgConfig): Promise<void> {
       const error = await backendResponse.json().catch(() => ({ message: 'Unknown error' }));
       errors.push(`Backend: ${error.message || backendResponse.status}`);
     } else {
+      // The backend lists this admin's invite signing key; publish that copy
+      const saved = await backendResponse.json().catch(() => null) as { config?: OrgConfig } | null;
+      if (saved?.config) config = saved.config;
       console.log('[Config] Saved config to backend');
     }
   } catch (err) {
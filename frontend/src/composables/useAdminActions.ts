@@ -249,6 +249,7 @@ export function useAdminActions() {
             inviteKey?: string;
             readOnlyInviteKey?: string;
             readOnlySpaceId?: string;
+            invite?: string;
           };
           console.log('[AdminActions] Invite generated:', inviteResult);
 
@@ -260,6 +261,7 @@ export function useAdminActions() {
               inviteKey: inviteResult.inviteKey,
               readOnlyInviteKey: inviteResult.readOnlyInviteKey,
               readOnlySpaceId: inviteResult.readOnlySpaceId,
+              invite: inviteResult.invite,
             });
           }
         } else {
@@ -9,7 +9,7 @@
 import { ref } from 'vue';
 import { Notify } from 'quasar';
 import { useRouter } from 'vue-router';
-import { BACKEND_URL } from 'src/lib/api/client';
+import { BACKEND_URL, getSessionToken } from 'src/lib/api/client';
 import { maybeNotify } from 'src/lib/notifications';
 import { useIdentityStore } from 'stores/identity';
 import { useProfilesStore } from 'stores/profiles';
@@ -18,12 +18,18 @@ import { useChatStore } from 'stores/chat';
 export type BackendEventType =
   | 'credential:new'
   | 'credential:community'
+  | 'credential:verified'
   | 'space:joined'
   | 'identity:configured'
   | 'notice_created'
   | 'notice_published'
+  | 'notice_unpublished'
   | 'notice_archived'
+  | 'notice_pinned'
+  | 'notice_unpinned'
   | 'notice_comment'
+  | 'notice_comment_reply'
+  | 'notice_comment_mention'
   | 'notice_reaction'
   | 'profile:updated'
   | 'chat:message:new'
@@ -78,6 +84,9 @@ const connected = ref(false);
 const lastEvent = ref<BackendEvent | null>(null);
 let eventSource: EventSource | null = null;
 let reconnectTimeout: ReturnType<typeof setTimeout> | null = null;
+// ID of the last event received, sent on reconnect so the backend can
+// replay anything missed while disconnected.
+let lastEventId = '';
 
 // Debounce profile reloads — any-sync can fire many profile:updated events in quick succession
 let profileDebounceTimer: ReturnType<typeof setTimeout> | null = null;
@@ -96,6 +105,7 @@ function debouncedProfileReload() {
 
 /** Safely parse SSE event data. Returns null on failure. */
 function safeParse(event: MessageEvent): Record<string, string> | null {
+  if (event.lastEventId) lastEventId = event.lastEventId;
   try {
     return JSON.parse(event.data);
   } catch {
@@ -107,7 +117,14 @@ function safeParse(event: MessageEvent): Record<string, string> | null {
 function connect() {
   if (eventSource) return;
 
-  const url = `${BACKEND_URL}/api/v1/events`;
+  // Authenticate the stream so the backend delivers events targeted at us
+  // (EventSource cannot send the Authorization header).
+  const params = new URLSearchParams();
+  const token = getSessionToken();
+  if (token) params.set('access_token', token);
+  if (lastEventId) params.set('lastEventId', lastEventId);
+  const query = params.toString();
+  const url = query ? `${BACKEND_URL}/api/v1/events?${query}` : `${BACKEND_URL}/api/v1/events`;
   eventSource = new EventSource(url);
 
   eventSource.addEventListener('connected', () => {
@@ -115,6 +132,18 @@ function connect() {
     console.log('[BackendEvents] Connected to SSE stream');
   });
 
+  // Disconnected longer than the backend's replay buffer: some events were lost.
+  eventSource.addEventListener('events:gap', () => {
+    console.warn('[BackendEvents] Missed events while disconnected; refreshing');
+    debouncedProfileReload();
+  });
+
+  // The backend dropped events because this client fell behind.
+  eventSource.addEventListener('events:resync', () => {
+    console.warn('[BackendEvents] Backend dropped events for a slow stream; refreshing');
+    debouncedProfileReload();
+  });
+
   eventSource.addEventListener('credential:new', (event) => {
     const data = safeParse(event);
     if (!data) return;
@@ -133,6 +162,13 @@ function connect() {
     identityStore.fetchUserSpaces().catch(() => {});
   });
 
+  eventSource.addEventListener('credential:verified', (event) => {
+    const data = safeParse(event);
+    if (!data) return;
+    lastEvent.value = { type: 'credential:verified', data };
+    console.log('[BackendEvents] Credential verified:', data.said);
+  });
+
   eventSource.addEventListener('space:joined', (event) => {
     const data = safeParse(event);
     if (!data) return;
@@ -171,6 +207,27 @@ function connect() {
     console.log('[BackendEvents] Notice archived:', data.noticeId);
   });
 
+  eventSource.addEventListener('notice_unpublished', (event) => {
+    const data = safeParse(event);
+    if (!data) return;
+    lastEvent.value = { type: 'notice_unpublished', data };
+    console.log('[BackendEvents] Notice unpublished:', data.noticeId);
+  });
+
+  eventSource.addEventListener('notice_pinned', (event) => {
+    const data = safeParse(event);
+    if (!data) return;
+    lastEvent.value = { type: 'notice_pinned', data };
+    console.log('[BackendEvents] Notice pinned:', data.noticeId);
+  });
+
+  eventSource.addEventListener('notice_unpinned', (event) => {
+    const data = safeParse(event);
+    if (!data) return;
+    lastEvent.value = { type: 'notice_unpinned', data };
+    console.log('[BackendEvents] Notice unpinned:', data.noticeId);
+  });
+
   eventSource.addEventListener('notice_comment', (event) => {
     const data = safeParse(event);
     if (!data) return;
@@ -178,6 +235,20 @@ function connect() {
     console.log('[BackendEvents] Notice comment:', data.noticeId);
   });
 
+  eventSource.addEventListener('notice_comment_reply', (event) => {
+    const data = safeParse(event);
+    if (!data) return;
+    lastEvent.value = { type: 'notice_comment_reply', data };
+    console.log('[BackendEvents] Notice comment reply:', data.noticeId);
+  });
+
+  eventSource.addEventListener('notice_comment_mention', (event) => {
+    const data = safeParse(event);
+    if (!data) return;
+    lastEvent.value = { type: 'notice_comment_mention', data };
+    console.log('[BackendEvents] Notice comment mention:', data.noticeId);
+  });
+
   eventSource.addEventListener('notice_reaction', (event) => {
     const data = safeParse(event);
     if (!data) return;
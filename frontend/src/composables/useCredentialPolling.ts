@@ -62,6 +62,7 @@ export function useCredentialPolling(options: CredentialPollingOptions = {}) {
   const spaceId = ref<string | null>(null);
   const readOnlyInviteKey = ref<string | null>(null);
   const readOnlySpaceId = ref<string | null>(null);
+  const signedInvite = ref<string | null>(null);
 
   // Rejection and message state
   const rejectionReceived = ref(false);
@@ -396,6 +397,7 @@ export function useCredentialPolling(options: CredentialPollingOptions = {}) {
                 spaceId.value = inviteData.spaceId || null;
                 readOnlyInviteKey.value = inviteData.readOnlyInviteKey || null;
                 readOnlySpaceId.value = inviteData.readOnlySpaceId || null;
+                signedInvite.value = inviteData.invite || null;
               }
             } catch {
               // Not JSON or not invite data — ignore
@@ -488,6 +490,7 @@ export function useCredentialPolling(options: CredentialPollingOptions = {}) {
           spaceId.value = payload.spaceId as string;
           readOnlyInviteKey.value = (payload.readOnlyInviteKey as string) || null;
           readOnlySpaceId.value = (payload.readOnlySpaceId as string) || null;
+          signedInvite.value = (payload.invite as string) || null;
           await client.notifications().mark(spaceInvites[0].i);
           console.log('[CredentialPolling] Space invite received');
         } catch (inviteErr) {
@@ -776,6 +779,7 @@ export function useCredentialPolling(options: CredentialPollingOptions = {}) {
     spaceId,
     readOnlyInviteKey,
     readOnlySpaceId,
+    signedInvite,
     rejectionReceived,
     rejectionInfo,
     adminMessages,
@@ -14,7 +14,7 @@
 import { ref, watch, onUnmounted } from 'vue';
 import { useKERIClient } from 'src/lib/keri/client';
 import { useKERINotificationService } from './useKERINotificationService';
-import { createOrUpdateProfile, getProfiles, uploadFile } from 'src/lib/api/client';
+import { createOrUpdateProfile, getProfiles, uploadAvatar } from 'src/lib/api/client';
 import { useProfilesStore } from 'stores/profiles';
 
 export interface PendingRegistration {
@@ -542,7 +542,7 @@ export function useRegistrationPolling(options: RegistrationPollingOptions = {})
           }
           const blob = new Blob([byteArray], { type: reg.profile.avatarMimeType });
           const avatarFile = new File([blob], 'avatar', { type: reg.profile.avatarMimeType });
-          const uploadResult = await uploadFile(avatarFile);
+          const uploadResult = await uploadAvatar(avatarFile);
           if (uploadResult.fileRef) {
             avatarRef = uploadResult.fileRef;
           }
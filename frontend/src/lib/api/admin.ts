@@ -0,0 +1,106 @@
+/**
+ * Admin API Client
+ * Org-level settings and the invite and moderation audit logs, stored in the
+ * admin space (community admins only).
+ */
+import { BACKEND_URL, authHeaders } from './client';
+
+export type ModerationPolicy = 'open' | 'review' | 'restricted';
+
+export interface AdminSettings {
+  defaultChannelRoles: string[];
+  moderationPolicy: ModerationPolicy;
+  featureFlags: Record<string, boolean>;
+  updatedAt: string;
+  updatedBy: string;
+}
+
+/** Omitted fields are left unchanged; featureFlags are merged into the existing flags. */
+export interface UpdateAdminSettingsRequest {
+  defaultChannelRoles?: string[];
+  moderationPolicy?: ModerationPolicy;
+  featureFlags?: Record<string, boolean>;
+}
+
+export async function getAdminSettings(): Promise<AdminSettings> {
+  const response = await fetch(`${BACKEND_URL}/api/v1/admin/settings`, {
+    headers: authHeaders(),
+  });
+  if (!response.ok) {
+    const err = await response.json().catch(() => ({ error: response.statusText }));
+    throw new Error(err.error || 'Failed to fetch admin settings');
+  }
+  return response.json();
+}
+
+export async function updateAdminSettings(req: UpdateAdminSettingsRequest): Promise<AdminSettings> {
+  const response = await fetch(`${BACKEND_URL}/api/v1/admin/settings`, {
+    method: 'POST',
+    headers: authHeaders(),
+    body: JSON.stringify(req),
+  });
+  if (!response.ok) {
+    const err = await response.json().catch(() => ({ error: response.statusText }));
+    throw new Error(err.error || 'Failed to update admin settings');
+  }
+  return response.json();
+}
+
+/** A record of an invite key minted by an admin. */
+export interface InviteAuditEntry {
+  inviteId: string;
+  inviterAid: string;
+  spaceId: string;
+  permission: 'writer' | 'reader';
+  createdAt: string;
+}
+
+/** List the invite audit log, newest first. */
+export async function getInviteAudit(): Promise<InviteAuditEntry[]> {
+  const response = await fetch(`${BACKEND_URL}/api/v1/admin/invites`, {
+    headers: authHeaders(),
+  });
+  if (!response.ok) {
+    const err = await response.json().catch(() => ({ error: response.statusText }));
+    throw new Error(err.error || 'Failed to fetch invite audit');
+  }
+  const data = await response.json();
+  return data.invites ?? [];
+}
+
+/** A record of a moderator action on chat or notice content. */
+export interface ModerationEvent {
+  id: string;
+  actorAid: string;
+  action: 'delete' | 'edit' | 'archive' | 'pin' | 'unpin';
+  targetType: string;
+  targetId: string;
+  reason?: string;
+  timestamp: string;
+}
+
+/** Filters for the moderation log; from/to are RFC3339 timestamps. */
+export interface ModerationLogFilter {
+  actor?: string;
+  target?: string;
+  from?: string;
+  to?: string;
+}
+
+/** List the moderation log, newest first. */
+export async function getModerationLog(filter: ModerationLogFilter = {}): Promise<ModerationEvent[]> {
+  const params = new URLSearchParams();
+  for (const [key, value] of Object.entries(filter)) {
+    if (value) params.set(key, value);
+  }
+  const query = params.toString();
+  const response = await fetch(`${BACKEND_URL}/api/v1/admin/moderation-log${query ? `?${query}` : ''}`, {
+    headers: authHeaders(),
+  });
+  if (!response.ok) {
+    const err = await response.json().catch(() => ({ error: response.statusText }));
+    throw new Error(err.error || 'Failed to fetch moderation log');
+  }
+  const data = await response.json();
+  return data.events ?? [];
+}
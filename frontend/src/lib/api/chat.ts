@@ -3,7 +3,7 @@
  * Handles chat channels and messages API calls
  */
 
-import { BACKEND_URL } from './client';
+import { BACKEND_URL, authHeaders } from './client';
 
 // --- Types ---
 
@@ -17,6 +17,12 @@ export interface Channel {
   createdBy: string;
   isArchived?: boolean;
   allowedRoles?: string[];
+  /** Messages older than this many days are hidden; unset keeps them forever */
+  retentionDays?: number;
+  /** Set when the channel was archived by merging it into another */
+  mergedInto?: string;
+  joined?: boolean;
+  version?: number;
   unreadCount?: number;
   lastMessage?: ChatMessage;
 }
@@ -32,6 +38,9 @@ export interface ChatMessage {
   sentAt: string;
   editedAt?: string;
   deletedAt?: string;
+  /** Set by slash commands, e.g. 'action' for /me */
+  kind?: string;
+  payload?: unknown;
   reactions?: MessageReaction[];
   version: number;
 }
@@ -41,6 +50,7 @@ export interface AttachmentRef {
   fileName: string;
   contentType: string;
   size: number;
+  thumbnailRef?: string;
 }
 
 export interface MessageReaction {
@@ -48,6 +58,7 @@ export interface MessageReaction {
   count: number;
   reactorAids: string[];
   hasReacted: boolean;
+  firstReactedAt?: string;
 }
 
 export interface CreateChannelRequest {
@@ -56,6 +67,7 @@ export interface CreateChannelRequest {
   icon?: string;
   photo?: string;
   allowedRoles?: string[];
+  retentionDays?: number;
 }
 
 export interface UpdateChannelRequest {
@@ -64,6 +76,10 @@ export interface UpdateChannelRequest {
   icon?: string;
   photo?: string;
   allowedRoles?: string[];
+  /** 0 removes the retention window */
+  retentionDays?: number;
+  /** Channel version the edit was made against; a stale version gets a 409 */
+  version?: number;
 }
 
 export interface SendMessageRequest {
@@ -79,8 +95,25 @@ export interface ListMessagesResponse {
   hasMore: boolean;
 }
 
+export interface RoleInfo {
+  name: string;
+  permissions: string[];
+}
+
 // --- Channel API ---
 
+/**
+ * Get the roles the org issues, for channel allowedRoles pickers
+ */
+export async function getRoles(): Promise<RoleInfo[]> {
+  const response = await fetch(`${BACKEND_URL}/api/v1/roles`);
+  if (!response.ok) {
+    throw new Error(`Failed to fetch roles: ${response.statusText}`);
+  }
+  const data = await response.json();
+  return data.roles ?? [];
+}
+
 /**
  * List all chat channels
  */
@@ -156,6 +189,78 @@ export async function archiveChannel(channelId: string): Promise<{ success: bool
   }
 }
 
+/**
+ * Merge a duplicate channel into another (admin only). The source's messages
+ * and members move to the target and the source is archived.
+ */
+export async function mergeChannels(
+  sourceId: string,
+  targetId: string,
+): Promise<{ success: boolean; messagesMoved?: number; membersAdded?: number; mergeId?: string; error?: string }> {
+  try {
+    const response = await fetch(`${BACKEND_URL}/api/v1/chat/channels/merge`, {
+      method: 'POST',
+      headers: authHeaders(),
+      body: JSON.stringify({ sourceId, targetId }),
+    });
+    return response.json();
+  } catch {
+    return { success: false, error: 'Network error' };
+  }
+}
+
+/**
+ * Export every message in a channel as NDJSON, oldest first (admin only).
+ * Deleted messages are included as tombstones. from/to are RFC3339 bounds.
+ */
+export async function exportChannel(
+  channelId: string,
+  options?: { from?: string; to?: string }
+): Promise<Blob> {
+  const url = new URL(`${BACKEND_URL}/api/v1/chat/channels/${encodeURIComponent(channelId)}/export`);
+  if (options?.from) {
+    url.searchParams.set('from', options.from);
+  }
+  if (options?.to) {
+    url.searchParams.set('to', options.to);
+  }
+
+  const response = await fetch(url.toString(), { headers: authHeaders() });
+  if (!response.ok) {
+    throw new Error(`Failed to export channel: ${response.statusText}`);
+  }
+
+  return response.blob();
+}
+
+/**
+ * Join a channel, to receive live events for its new messages
+ */
+export async function joinChannel(channelId: string): Promise<{ success: boolean; error?: string }> {
+  try {
+    const response = await fetch(`${BACKEND_URL}/api/v1/chat/channels/${encodeURIComponent(channelId)}/membership`, {
+      method: 'POST',
+    });
+    return response.json();
+  } catch {
+    return { success: false, error: 'Network error' };
+  }
+}
+
+/**
+ * Leave a channel
+ */
+export async function leaveChannel(channelId: string): Promise<{ success: boolean; error?: string }> {
+  try {
+    const response = await fetch(`${BACKEND_URL}/api/v1/chat/channels/${encodeURIComponent(channelId)}/membership`, {
+      method: 'DELETE',
+    });
+    return response.json();
+  } catch {
+    return { success: false, error: 'Network error' };
+  }
+}
+
 // --- Message API ---
 
 /**
@@ -182,12 +287,13 @@ export async function getMessages(
 }
 
 /**
- * Send a message to a channel
+ * Send a message to a channel. queued is set when the write failed and the
+ * backend will retry it; the chat:message:new event arrives once it lands.
  */
 export async function sendMessage(
   channelId: string,
   request: SendMessageRequest
-): Promise<{ success: boolean; messageId?: string; sentAt?: string; error?: string }> {
+): Promise<{ success: boolean; messageId?: string; sentAt?: string; queued?: boolean; error?: string }> {
   try {
     const response = await fetch(`${BACKEND_URL}/api/v1/chat/channels/${encodeURIComponent(channelId)}/messages`, {
       method: 'POST',
@@ -244,6 +350,24 @@ export async function getThread(messageId: string): Promise<{ replies: ChatMessa
   return response.json();
 }
 
+/**
+ * Get messages by ID (e.g. the messages quoted by replies).
+ * Deleted messages come back with deletedAt set and no content.
+ */
+export async function getMessagesByIds(
+  messageIds: string[]
+): Promise<{ messages: ChatMessage[]; missing: string[]; count: number }> {
+  const response = await fetch(`${BACKEND_URL}/api/v1/chat/messages/batch`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ messageIds }),
+  });
+  if (!response.ok) {
+    throw new Error(`Failed to fetch messages: ${response.statusText}`);
+  }
+  return response.json();
+}
+
 // --- Reaction API ---
 
 /**
@@ -283,6 +407,40 @@ export async function removeReaction(
   }
 }
 
+// --- Drafts API ---
+
+/**
+ * Get the unsent draft for a channel (empty content if there is none)
+ */
+export async function getDraft(channelId: string): Promise<{ channelId: string; content: string; updatedAt: string }> {
+  try {
+    const response = await fetch(`${BACKEND_URL}/api/v1/chat/channels/${encodeURIComponent(channelId)}/draft`);
+    if (!response.ok) {
+      return { channelId, content: '', updatedAt: '' };
+    }
+    return response.json();
+  } catch {
+    return { channelId, content: '', updatedAt: '' };
+  }
+}
+
+/**
+ * Save the unsent draft for a channel (empty content clears it).
+ * Drafts are kept in the private space and cleared when a message is sent.
+ */
+export async function saveDraft(channelId: string, content: string): Promise<{ success: boolean; error?: string }> {
+  try {
+    const response = await fetch(`${BACKEND_URL}/api/v1/chat/channels/${encodeURIComponent(channelId)}/draft`, {
+      method: 'PUT',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ content }),
+    });
+    return response.json();
+  } catch {
+    return { success: false, error: 'Network error' };
+  }
+}
+
 // --- Read Cursors API ---
 
 /**
@@ -20,15 +20,46 @@ export async function initBackendUrl(): Promise<void> {
   BACKEND_URL = await getBackendUrl();
 }
 
+/** localStorage key for the backend session token */
+const SESSION_TOKEN_KEY = 'matou:sessionToken';
+
+/**
+ * Remember the session token issued by the backend, or forget it (null).
+ */
+export function setSessionToken(token: string | null): void {
+  try {
+    if (token) {
+      localStorage.setItem(SESSION_TOKEN_KEY, token);
+    } else {
+      localStorage.removeItem(SESSION_TOKEN_KEY);
+    }
+  } catch {
+    // Storage unavailable — requests go without a token
+  }
+}
+
+export function getSessionToken(): string | null {
+  try {
+    return localStorage.getItem(SESSION_TOKEN_KEY);
+  } catch {
+    return null;
+  }
+}
+
 /**
- * Build request headers with JSON content type and the current user's AID
- * for RBAC-protected endpoints. Falls back gracefully if no identity is set.
+ * Build request headers with JSON content type, the session token and the
+ * current user's AID for RBAC-protected endpoints. Falls back gracefully if
+ * no identity is set.
  */
 export function authHeaders(extra: Record<string, string> = {}): Record<string, string> {
   const headers: Record<string, string> = {
     'Content-Type': 'application/json',
     ...extra,
   };
+  const token = getSessionToken();
+  if (token) {
+    headers['Authorization'] = `Bearer ${token}`;
+  }
   try {
     const identity = useIdentityStore();
     if (identity.aidPrefix) {
@@ -86,6 +117,51 @@ export async function syncCredentials(
   return response.json();
 }
 
+export interface SyncBatchRequest {
+  userAid?: string;
+  kel?: unknown[];
+  credentials?: unknown[];
+  oobis?: Record<string, string>;
+  profiles?: { type: string; id?: string; data: unknown; spaceId?: string }[];
+}
+
+export interface SyncBatchItemResult {
+  kind: 'kel' | 'credential' | 'profile';
+  /** Position in the request's list of that kind */
+  index: number;
+  id?: string;
+  success: boolean;
+  error?: string;
+  warning?: string;
+}
+
+export interface SyncBatchResponse {
+  success: boolean;
+  succeeded: number;
+  failed: number;
+  privateSpace?: string;
+  results: SyncBatchItemResult[];
+  error?: string;
+}
+
+/**
+ * Sync KEL events, credentials and profile updates in one request. KEL events
+ * are processed first so credentials anchored in them verify; each item
+ * reports its own result.
+ */
+export async function syncBatch(request: SyncBatchRequest): Promise<SyncBatchResponse> {
+  const response = await fetch(`${BACKEND_URL}/api/v1/sync/batch`, {
+    method: 'POST',
+    headers: authHeaders(),
+    body: JSON.stringify(request),
+  });
+  const data = await response.json().catch(() => null);
+  if (!data) {
+    throw new Error(`Batch sync failed: ${response.statusText}`);
+  }
+  return data;
+}
+
 /**
  * Get community members from the backend
  */
@@ -106,11 +182,11 @@ export async function getOrgInfo(): Promise<OrgInfo> {
 }
 
 /**
- * Check backend health
+ * Check the backend is up (liveness; /health reports subsystem readiness)
  */
 export async function healthCheck(): Promise<boolean> {
   try {
-    const response = await fetch(`${BACKEND_URL}/health`);
+    const response = await fetch(`${BACKEND_URL}/health/live`);
     return response.ok;
   } catch {
     return false;
@@ -118,13 +194,20 @@ export async function healthCheck(): Promise<boolean> {
 }
 
 /**
- * Get all credentials from the backend
+ * Get all credentials from the backend, following nextCursor across pages
  */
 export async function getCredentials(): Promise<unknown[]> {
-  const response = await fetch(`${BACKEND_URL}/api/v1/credentials`);
-  if (!response.ok) return [];
-  const data = await response.json();
-  return data.credentials ?? [];
+  const credentials: unknown[] = [];
+  let cursor = '';
+  do {
+    const query = cursor ? `?cursor=${encodeURIComponent(cursor)}` : '';
+    const response = await fetch(`${BACKEND_URL}/api/v1/credentials${query}`);
+    if (!response.ok) return credentials;
+    const data = await response.json();
+    credentials.push(...(data.credentials ?? []));
+    cursor = data.nextCursor ?? '';
+  } while (cursor);
+  return credentials;
 }
 
 /**
@@ -196,12 +279,20 @@ export interface JoinCommunityRequest {
   spaceId?: string;
   readOnlyInviteKey?: string;
   readOnlySpaceId?: string;
+  /** Signed invite envelope from the admin; the node verifies it before joining */
+  invite?: string;
 }
 
 /**
  * Join the community space using an invite key
  */
-export async function joinCommunity(req: JoinCommunityRequest): Promise<{ success: boolean; spaceId?: string; error?: string }> {
+export async function joinCommunity(req: JoinCommunityRequest): Promise<{
+  success: boolean;
+  spaceId?: string;
+  /** 'pending' when joined but still syncing — poll getSyncStatus() */
+  syncStatus?: 'synced' | 'pending';
+  error?: string;
+}> {
   try {
     const response = await fetch(`${BACKEND_URL}/api/v1/spaces/community/join`, {
       method: 'POST',
@@ -231,6 +322,8 @@ export interface SetBackendIdentityResponse {
   success: boolean;
   peerId?: string;
   privateSpaceId?: string;
+  sessionToken?: string;
+  sessionExpiresAt?: string;
   error?: string;
 }
 
@@ -258,7 +351,11 @@ export async function setBackendIdentity(
       body: JSON.stringify(request),
       signal: AbortSignal.timeout(30000),
     });
-    return response.json();
+    const result: SetBackendIdentityResponse = await response.json();
+    if (result.success && result.sessionToken) {
+      setSessionToken(result.sessionToken);
+    }
+    return result;
   } catch {
     return { success: false, error: 'Network error' };
   }
@@ -417,6 +514,73 @@ export async function getMyProfiles(): Promise<Record<string, ObjectPayload[]>>
   }
 }
 
+export interface EffectiveProfile {
+  aid: string;
+  fields: Record<string, unknown>;
+  /** Profile type each field was taken from */
+  sources: Record<string, string>;
+  /** Version of each merged profile, by type */
+  versions: Record<string, number>;
+}
+
+/**
+ * Get a member's profile merged across spaces: display fields from their own
+ * SharedProfile, role and permissions from the org-issued CommunityProfile.
+ */
+export async function getEffectiveProfile(aid: string): Promise<EffectiveProfile | null> {
+  try {
+    const response = await fetch(
+      `${BACKEND_URL}/api/v1/profile/${encodeURIComponent(aid)}/effective`,
+      { headers: authHeaders() },
+    );
+    if (!response.ok) return null;
+    return await response.json() as EffectiveProfile;
+  } catch {
+    return null;
+  }
+}
+
+/**
+ * Update fields of the current user's SharedProfile. Only the fields given
+ * change; the backend writes the merged profile as the next version.
+ */
+export async function updateOwnProfile(
+  fields: Record<string, unknown>,
+): Promise<{ success: boolean; version?: number; error?: string }> {
+  try {
+    const response = await fetch(`${BACKEND_URL}/api/v1/profile/me`, {
+      method: 'PUT',
+      headers: authHeaders(),
+      body: JSON.stringify(fields),
+    });
+    const data = await response.json();
+    return response.ok ? data : { success: false, error: data.error };
+  } catch {
+    return { success: false, error: 'Network error' };
+  }
+}
+
+export interface PresenceEntry {
+  aid: string;
+  onlineSince: string;
+  lastSeenAt: string;
+}
+
+/**
+ * List the members currently online. Changes arrive on the event stream as
+ * presence:online and presence:offline events.
+ */
+export async function getPresence(): Promise<PresenceEntry[]> {
+  try {
+    const response = await fetch(`${BACKEND_URL}/api/v1/presence`);
+    if (!response.ok) return [];
+    const data = await response.json();
+    return data.online ?? [];
+  } catch {
+    return [];
+  }
+}
+
 /**
  * Initialize member profiles (admin action after credential issuance)
  */
@@ -472,11 +636,23 @@ export async function updateMemberRole(
 /**
  * Upload a file and return a content-addressed fileRef
  */
-export async function uploadFile(file: File): Promise<{ fileRef?: string; error?: string }> {
+export async function uploadFile(file: File): Promise<{ fileRef?: string; thumbnailRef?: string; error?: string }> {
+  return postFile('/api/v1/files/upload', file);
+}
+
+/**
+ * Upload an avatar or logo image and return its fileRef. The backend only
+ * accepts images within its avatar size limit.
+ */
+export async function uploadAvatar(file: File): Promise<{ fileRef?: string; thumbnailRef?: string; error?: string }> {
+  return postFile('/api/v1/files/avatar', file);
+}
+
+async function postFile(path: string, file: File): Promise<{ fileRef?: string; thumbnailRef?: string; error?: string }> {
   try {
     const formData = new FormData();
     formData.append('file', file);
-    const response = await fetch(`${BACKEND_URL}/api/v1/files/upload`, {
+    const response = await fetch(`${BACKEND_URL}${path}`, {
       method: 'POST',
       body: formData,
     });
@@ -484,7 +660,7 @@ export async function uploadFile(file: File): Promise<{ fileRef?: string; error?
     if (!response.ok) {
       return { error: result.error || `Upload failed (${response.status})` };
     }
-    return { fileRef: result.fileRef };
+    return { fileRef: result.fileRef, thumbnailRef: result.thumbnailRef };
   } catch {
     return { error: 'Upload failed' };
   }
@@ -493,8 +669,8 @@ export async function uploadFile(file: File): Promise<{ fileRef?: string; error?
 /**
  * Get the URL for a file by its fileRef
  */
-export function getFileUrl(fileRef: string): string {
-  return `${BACKEND_URL}/api/v1/files/${fileRef}`;
+export function getFileUrl(fileRef: string, thumbnail = false): string {
+  return `${BACKEND_URL}/api/v1/files/${fileRef}${thumbnail ? '?thumb=1' : ''}`;
 }
 
 // --- Sync Status ---
@@ -504,6 +680,8 @@ export interface SpaceSyncStatusItem {
   hasObjectTree: boolean;
   objectCount: number;
   profileCount: number;
+  /** Sync has made no progress while local changes wait for a peer to acknowledge them */
+  stalled?: boolean;
 }
 
 export interface SyncStatusResponse {
@@ -696,6 +874,12 @@ export interface Notice {
   archivedAt?: string;
   amendsNoticeId?: string;
   treeId?: string;
+  // States the notice can move to next; set by getNotice
+  allowedTransitions?: Array<'draft' | 'published' | 'archived'>;
+  // Set on notices returned by getNotices({ view: 'mine' })
+  myRsvpStatus?: string;
+  myRsvpOccurrences?: string[];
+  saved?: boolean;
 }
 
 export interface NoticeRSVP {
@@ -733,10 +917,14 @@ export interface NoticeAttachment {
 export interface NoticeComment {
   id: string;
   noticeId: string;
+  parentCommentId?: string;
   userId: string;
   userDisplayName: string;
   text: string;
+  mentions?: string[];
   createdAt: string;
+  deletedAt?: string;
+  replyCount?: number;
 }
 
 export interface NoticeReaction {
@@ -773,6 +961,8 @@ export interface CreateNoticeRequest {
   ackDueAt?: string;
   activeFrom?: string;
   activeUntil?: string;
+  // Publishes a draft automatically once this time arrives
+  publishAt?: string;
   images?: string[];
   attachments?: { name: string; fileRef: string; mimeType: string; size: number }[];
   links?: { label: string; url: string }[];
@@ -838,6 +1028,17 @@ export async function archiveNotice(id: string): Promise<{ success: boolean; err
   }
 }
 
+export async function unpublishNotice(id: string): Promise<{ success: boolean; error?: string }> {
+  try {
+    const response = await fetch(`${BACKEND_URL}/api/v1/notices/${encodeURIComponent(id)}/unpublish`, {
+      method: 'POST',
+    });
+    return response.json();
+  } catch {
+    return { success: false, error: 'Network error' };
+  }
+}
+
 export async function submitRsvp(noticeId: string, status: 'going' | 'maybe' | 'not_going'): Promise<{ success: boolean; error?: string }> {
   try {
     const response = await fetch(`${BACKEND_URL}/api/v1/notices/${encodeURIComponent(noticeId)}/rsvp`, {
@@ -861,6 +1062,17 @@ export async function getRsvps(noticeId: string): Promise<{ rsvps: NoticeRSVP[];
   }
 }
 
+/** RSVP counts by status, without the RSVPs themselves. */
+export async function getRsvpCounts(noticeId: string): Promise<{ count: number; counts: Record<string, number> }> {
+  try {
+    const response = await fetch(`${BACKEND_URL}/api/v1/notices/${encodeURIComponent(noticeId)}/rsvp?countsOnly=true`);
+    if (!response.ok) return { count: 0, counts: { going: 0, maybe: 0, not_going: 0 } };
+    return response.json();
+  } catch {
+    return { count: 0, counts: { going: 0, maybe: 0, not_going: 0 } };
+  }
+}
+
 export async function submitAck(noticeId: string): Promise<{ success: boolean; error?: string }> {
   try {
     const response = await fetch(`${BACKEND_URL}/api/v1/notices/${encodeURIComponent(noticeId)}/ack`, {
@@ -914,12 +1126,12 @@ export async function getComments(noticeId: string): Promise<{ comments: NoticeC
   }
 }
 
-export async function createComment(noticeId: string, text: string): Promise<{ success: boolean; error?: string }> {
+export async function createComment(noticeId: string, text: string, parentCommentId?: string, mentions?: string[]): Promise<{ success: boolean; error?: string }> {
   try {
     const response = await fetch(`${BACKEND_URL}/api/v1/notices/${encodeURIComponent(noticeId)}/comments`, {
       method: 'POST',
       headers: { 'Content-Type': 'application/json' },
-      body: JSON.stringify({ text }),
+      body: JSON.stringify({ text, parentCommentId, mentions }),
     });
     return response.json();
   } catch {
@@ -937,6 +1149,18 @@ export async function getReactions(noticeId: string): Promise<{ reactions: Notic
   }
 }
 
+/** Reaction counts by emoji, without the reactions themselves. */
+export async function getReactionCounts(noticeId: string): Promise<Record<string, number>> {
+  try {
+    const response = await fetch(`${BACKEND_URL}/api/v1/notices/${encodeURIComponent(noticeId)}/reactions?countsOnly=true`);
+    if (!response.ok) return {};
+    const data = await response.json();
+    return data.counts ?? {};
+  } catch {
+    return {};
+  }
+}
+
 export async function toggleReaction(noticeId: string, emoji: string): Promise<{ success: boolean; error?: string }> {
   try {
     const response = await fetch(`${BACKEND_URL}/api/v1/notices/${encodeURIComponent(noticeId)}/reactions`, {
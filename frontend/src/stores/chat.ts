@@ -11,6 +11,8 @@ import {
   createChannel as apiCreateChannel,
   updateChannel as apiUpdateChannel,
   archiveChannel as apiArchiveChannel,
+  joinChannel as apiJoinChannel,
+  leaveChannel as apiLeaveChannel,
   getThread,
   getReadCursors,
   updateReadCursor as apiUpdateReadCursor,
@@ -112,6 +114,11 @@ export const useChatStore = defineStore('chat', () => {
       localStorage.removeItem('matou:lastChannelId');
     }
     if (channelId) {
+      // Opening a channel joins it, so its new messages arrive live
+      const channel = channels.value.find((c) => c.id === channelId);
+      if (channel && !channel.joined) {
+        await joinChannel(channelId);
+      }
       // Snapshot the cursor BEFORE loading/marking so the "new messages" divider persists
       channelEntryReadAt.value = readCursors.value[channelId] ?? undefined;
       await loadMessages(channelId);
@@ -368,6 +375,29 @@ export const useChatStore = defineStore('chat', () => {
     }
   }
 
+  async function joinChannel(channelId: string): Promise<boolean> {
+    const result = await apiJoinChannel(channelId);
+    if (!result.success) {
+      console.warn('[ChatStore] Failed to join channel:', result.error);
+      return false;
+    }
+    const channel = channels.value.find((c) => c.id === channelId);
+    if (channel) channel.joined = true;
+    return true;
+  }
+
+  async function leaveChannel(channelId: string): Promise<boolean> {
+    error.value = null;
+    const result = await apiLeaveChannel(channelId);
+    if (!result.success) {
+      error.value = result.error ?? 'Failed to leave channel';
+      return false;
+    }
+    const channel = channels.value.find((c) => c.id === channelId);
+    if (channel) channel.joined = false;
+    return true;
+  }
+
   async function loadReadCursors(): Promise<void> {
     try {
       readCursors.value = await getReadCursors();
@@ -536,6 +566,8 @@ export const useChatStore = defineStore('chat', () => {
     createChannel,
     updateChannel,
     archiveChannel,
+    joinChannel,
+    leaveChannel,
     clearError,
     loadReadCursors,
     loadAllChannelMessages,
@@ -325,6 +325,7 @@ export const useIdentityStore = defineStore('identity', () => {
     spaceId?: string;
     readOnlyInviteKey?: string;
     readOnlySpaceId?: string;
+    invite?: string;
   }): Promise<boolean> {
     if (!currentAID.value?.prefix) return false;
     try {
@@ -334,6 +335,7 @@ export const useIdentityStore = defineStore('identity', () => {
         spaceId: params.spaceId,
         readOnlyInviteKey: params.readOnlyInviteKey,
         readOnlySpaceId: params.readOnlySpaceId,
+        invite: params.invite,
       });
       if (result.success) {
         communityAccessVerified.value = true;